@@ -13,6 +13,10 @@ ifneq ($(HAS_SERVER),)
 	mockgen -destination server/incident/mocks/mock_job_once_scheduler.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident JobOnceScheduler
 	mockgen -destination server/playbook/mocks/mock_service.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook Service
 	mockgen -destination server/playbook/mocks/mock_store.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook Store
+	mockgen -destination server/oncall/mocks/mock_service.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall Service
+	mockgen -destination server/oncall/mocks/mock_store.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall Store
+	mockgen -destination server/audit/mocks/mock_service.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit Service
+	mockgen -destination server/audit/mocks/mock_store.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit Store
 	mockgen -destination server/sqlstore/mocks/mock_kvapi.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore KVAPI
 	mockgen -destination server/sqlstore/mocks/mock_storeapi.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore StoreAPI
 	mockgen -destination server/sqlstore/mocks/mock_configurationapi.go github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore ConfigurationAPI
@@ -13,6 +13,8 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/google/go-querystring/query"
 	"github.com/pkg/errors"
@@ -22,6 +24,21 @@ const (
 	apiVersion = "v0"
 	manifestID = "com.mattermost.plugin-incident-management"
 	userAgent  = "go-client/" + apiVersion
+
+	defaultRetryMax     = 3
+	defaultRetryWaitMin = 250 * time.Millisecond
+	defaultRetryWaitMax = 5 * time.Second
+)
+
+var (
+	// ErrNotFound is matched by the errors of requests answered with 404 Not Found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is matched by the errors of requests answered with 403 Forbidden.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrConflict is matched by the errors of requests answered with 409 Conflict.
+	ErrConflict = errors.New("conflict")
 )
 
 // Client manages communication with the workflows API.
@@ -32,37 +49,71 @@ type Client struct {
 	// User agent used when communicating with the workflows API. Defaults to go-client.
 	UserAgent string
 
+	// RetryMax is how many times a request that is safe to repeat is retried after a network
+	// error, or a 429, 502, 503 or 504 response. Zero disables the retries. Defaults to 3.
+	RetryMax int
+
+	// RetryWaitMin and RetryWaitMax bound the wait before a retry, which doubles after every
+	// attempt unless the response asks for a specific delay with Retry-After.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+
 	Incidents *IncidentsService
 	Playbooks *PlaybooksService
 }
 
-// ErrorResponse reports an error caused by an API request.
+// ErrorResponse reports an error caused by an API request. Use errors.Is with ErrNotFound,
+// ErrForbidden or ErrConflict to check for the common failures.
 type ErrorResponse struct {
 	Response *http.Response // HTTP response that caused this error
-	Error    string         `json:"error"` // error message
+	Message  string         `json:"error"` // error message
+
+	// Errors and Warnings list the problems of an invalid playbook.
+	Errors   []ValidationIssue `json:"errors,omitempty"`
+	Warnings []ValidationIssue `json:"warnings,omitempty"`
 }
 
 func (r *ErrorResponse) Error() string {
 	return fmt.Sprintf("%v %v: %d %v",
 		r.Response.Request.Method, r.Response.Request.URL,
-		r.Response.StatusCode, r.Error)
+		r.Response.StatusCode, r.Message)
+}
+
+// Is matches the sentinel error corresponding to the status code of the response.
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return r.Response.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return r.Response.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return r.Response.StatusCode == http.StatusConflict
+	default:
+		return false
+	}
 }
 
 // ListOptions specifies the optional parameters to various List methods that
-// support offset pagination.
+// support offset or cursor pagination.
 type ListOptions struct {
 	// For paginated result sets, page of results to retrieve. 0 based index.
 	Page int `url:"page,omitempty"`
 
 	// For paginated result sets, the number of results to include per page.
 	PerPage int `url:"per_page,omitempty"`
+
+	// For paginated result sets, the NextCursor of the previous page, to retrieve the page right
+	// after it instead of using Page. The other options must be the same as for the previous page.
+	Cursor string `url:"cursor,omitempty"`
 }
 
-// ListResult contains pagination data for List methods.
+// ListResult contains pagination data for List methods. TotalCount and PageCount are 0 when
+// paginating with a cursor.
 type ListResult struct {
-	TotalCount int  `json:"total_count"`
-	PageCount  int  `json:"page_count"`
-	HasMore    bool `json:"has_more"`
+	TotalCount int    `json:"total_count"`
+	PageCount  int    `json:"page_count"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
 }
 
 // SortDirection is the type used to specify the ascending or descending order of returned results.
@@ -90,7 +141,14 @@ func NewClient(mattermostSiteURL string, httpClient *http.Client) (*Client, erro
 		return nil, err
 	}
 
-	c := &Client{client: httpClient, BaseURL: siteURL, UserAgent: userAgent}
+	c := &Client{
+		client:       httpClient,
+		BaseURL:      siteURL,
+		UserAgent:    userAgent,
+		RetryMax:     defaultRetryMax,
+		RetryWaitMin: defaultRetryWaitMin,
+		RetryWaitMax: defaultRetryWaitMax,
+	}
 	c.Incidents = &IncidentsService{c}
 	c.Playbooks = &PlaybooksService{c}
 	return c, nil
@@ -137,40 +195,84 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 // interface, the raw response body will be written to v, without attempting to
 // first decode it.
 //
+// GET and HEAD requests are retried on transient failures, see Client.RetryMax.
+//
 // The provided ctx must be non-nil, if it is nil an error is returned. If it is canceled or times out,
 // ctx.Err() will be returned.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	return c.do(ctx, req, v, req.Method == http.MethodGet || req.Method == http.MethodHead)
+}
+
+// doIdempotent is like Do, but retries the request whatever its method. It must only be used for
+// requests that have the same effect when repeated.
+func (c *Client) doIdempotent(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	return c.do(ctx, req, v, true)
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}, retry bool) (*http.Response, error) {
 	if ctx == nil {
 		return nil, errors.New("context must be non-nil")
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.client.Do(req)
-	if err != nil {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to rewind the request body")
+			}
+			req.Body = body
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrapf(ctx.Err(), "client err=%s", err.Error())
+			default:
+			}
+		}
+
+		if !retry || attempt >= c.RetryMax || !shouldRetry(resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			return resp, c.handleResponse(resp, v)
+		}
+
+		wait := c.retryWait(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
-			return nil, errors.Wrapf(ctx.Err(), "client err=%s", err.Error())
-		default:
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
 		}
-
-		return nil, err
 	}
+}
+
+// handleResponse checks the response for errors and decodes its body into v.
+func (c *Client) handleResponse(resp *http.Response, v interface{}) error {
 	defer resp.Body.Close()
 
-	err = CheckResponse(resp)
+	err := CheckResponse(resp)
 	if err != nil {
-		return resp, err
+		return err
 	}
 
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
 			if _, err = io.Copy(w, resp.Body); err != nil {
-				return nil, err
+				return err
 			}
 		} else {
 			decErr := json.NewDecoder(resp.Body).Decode(v)
 			if decErr == io.EOF {
-				// TODO: Confirm if this happens only on empty bodies. If so, check that first before decoding.
 				decErr = nil // ignore EOF errors caused by empty response body
 			}
 			if decErr != nil {
@@ -179,14 +281,43 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*htt
 		}
 	}
 
-	return resp, err
+	return err
+}
+
+// shouldRetry returns true if the request failed in a way that may not happen again.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryWait returns how long to wait before retrying after the given attempt.
+func (c *Client) retryWait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	wait := c.RetryWaitMin << uint(attempt)
+	if wait > c.RetryWaitMax || wait <= 0 {
+		wait = c.RetryWaitMax
+	}
+	return wait
 }
 
 // CheckResponse checks the API response for errors, and returns them if
 // present. A response is considered an error if it has a status code outside
 // the 200 range.
-// API error responses are expected to have response
-// body, and a JSON response body that maps to ErrorResponse.
+// API error responses are expected to have a JSON response body that maps to
+// ErrorResponse. Other bodies are used as the error message as is.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; http.StatusOK <= c && c <= 299 {
 		return nil
@@ -195,7 +326,7 @@ func CheckResponse(r *http.Response) error {
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && data != nil {
 		if err := json.Unmarshal(data, errorResponse); err != nil {
-			return err
+			errorResponse.Message = string(bytes.TrimSpace(data))
 		}
 	}
 	r.Body = ioutil.NopCloser(bytes.NewBuffer(data))
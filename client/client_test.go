@@ -1,10 +1,15 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -54,3 +59,156 @@ func testFormValues(t *testing.T, r *http.Request, values values) {
 	got := r.Form
 	require.Equal(t, want, got, "request parameters: %v, want %v", got, want)
 }
+
+func TestClient_TypedErrors(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	for path, status := range map[string]int{"missing": http.StatusNotFound, "forbidden": http.StatusForbidden, "conflict": http.StatusConflict} {
+		status := status
+		mux.HandleFunc("/"+buildAPIURL("incidents/"+path), func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"error": "something went wrong"}`)
+		})
+	}
+
+	_, err := client.Incidents.Get(context.Background(), "missing")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.False(t, errors.Is(err, ErrForbidden))
+
+	var errorResponse *ErrorResponse
+	require.True(t, errors.As(err, &errorResponse))
+	require.Equal(t, "something went wrong", errorResponse.Message)
+
+	_, err = client.Incidents.Get(context.Background(), "forbidden")
+	require.True(t, errors.Is(err, ErrForbidden))
+
+	_, err = client.Incidents.Get(context.Background(), "conflict")
+	require.True(t, errors.Is(err, ErrConflict))
+}
+
+func TestClient_PlainTextError(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents/1"), func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	})
+
+	_, err := client.Incidents.Get(context.Background(), "1")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	var errorResponse *ErrorResponse
+	require.True(t, errors.As(err, &errorResponse))
+	require.Equal(t, "404 page not found", errorResponse.Message)
+}
+
+func TestClient_Retries(t *testing.T) {
+	setupRetries := func(t *testing.T, failures int, status int) (*Client, *http.ServeMux, *int) {
+		client, mux, _ := setup(t)
+		client.RetryWaitMin = time.Millisecond
+		client.RetryWaitMax = 2 * time.Millisecond
+
+		calls := new(int)
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			*calls++
+			if *calls <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			fmt.Fprint(w, `{"id": "1"}`)
+		}
+		mux.HandleFunc("/"+buildAPIURL("incidents/1"), handler)
+		mux.HandleFunc("/"+buildAPIURL("incidents"), handler)
+		mux.HandleFunc("/"+buildAPIURL("playbooks/1"), handler)
+
+		return client, mux, calls
+	}
+
+	t.Run("GET is retried on transient failures", func(t *testing.T) {
+		client, _, calls := setupRetries(t, 2, http.StatusServiceUnavailable)
+
+		i, err := client.Incidents.Get(context.Background(), "1")
+		require.NoError(t, err)
+		require.Equal(t, "1", i.ID)
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("GET gives up after RetryMax retries", func(t *testing.T) {
+		client, _, calls := setupRetries(t, 10, http.StatusBadGateway)
+
+		_, err := client.Incidents.Get(context.Background(), "1")
+		require.Error(t, err)
+		require.Equal(t, 4, *calls)
+	})
+
+	t.Run("GET is not retried on client errors", func(t *testing.T) {
+		client, _, calls := setupRetries(t, 10, http.StatusBadRequest)
+
+		_, err := client.Incidents.Get(context.Background(), "1")
+		require.Error(t, err)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("POST is not retried", func(t *testing.T) {
+		client, _, calls := setupRetries(t, 1, http.StatusServiceUnavailable)
+
+		_, err := client.Incidents.Create(context.Background(), IncidentCreateOptions{Name: "Incident", TeamID: "team1"})
+		require.Error(t, err)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("idempotent PUT is retried with its body", func(t *testing.T) {
+		client, mux, _ := setup(t)
+		client.RetryWaitMin = time.Millisecond
+
+		var bodies []string
+		mux.HandleFunc("/"+buildAPIURL("playbooks/1"), func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, "PUT")
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+		err := client.Playbooks.Update(context.Background(), Playbook{ID: "1", Title: "Playbook"})
+		require.NoError(t, err)
+		require.Len(t, bodies, 2)
+		require.Equal(t, bodies[0], bodies[1])
+		require.Contains(t, bodies[0], `"title":"Playbook"`)
+	})
+
+	t.Run("canceling the context stops the retries", func(t *testing.T) {
+		client, _, calls := setupRetries(t, 10, http.StatusServiceUnavailable)
+		client.RetryWaitMin = time.Hour
+		client.RetryWaitMax = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Incidents.Get(ctx, "1")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("Retry-After is honored", func(t *testing.T) {
+		client, mux, _ := setup(t)
+		client.RetryWaitMin = time.Hour
+		client.RetryWaitMax = time.Hour
+
+		var calls int
+		mux.HandleFunc("/"+buildAPIURL("incidents/1"), func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(w, `{"id": "1"}`)
+		})
+
+		_, err := client.Incidents.Get(context.Background(), "1")
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Config holds the servers incidentctl knows about, as named profiles.
+type Config struct {
+	DefaultProfile string              `json:"default_profile"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// Profile is a Mattermost server and the personal access token to use with it.
+type Profile struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// defaultConfigPath returns the path of the configuration file when neither --config nor
+// INCIDENTCTL_CONFIG is given.
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find the configuration directory")
+	}
+
+	return filepath.Join(dir, "incidentctl", "config.json"), nil
+}
+
+// loadConfig reads the configuration file at path. A missing file is an empty configuration.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the configuration file %s", path)
+	}
+
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the configuration file %s", path)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+
+	return config, nil
+}
+
+// save writes the configuration to path. The file is only readable by the user since it holds
+// tokens.
+func (c *Config) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the configuration")
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", path)
+	}
+	if err = ioutil.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the configuration file %s", path)
+	}
+
+	return nil
+}
+
+// profileNames returns the names of the profiles, sorted.
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// resolve returns the server to use given the name of a profile, which defaults to the default
+// profile, and the URL and token overriding the ones of the profile, if any.
+func (c *Config) resolve(name, url, token string) (Profile, error) {
+	var profile Profile
+
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name != "" {
+		p, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, errors.Errorf("unknown profile %q", name)
+		}
+		profile = *p
+	}
+
+	if url != "" {
+		profile.URL = url
+	}
+	if token != "" {
+		profile.Token = token
+	}
+
+	if profile.URL == "" {
+		return Profile{}, errors.New("no server configured: add a profile with `incidentctl profile add` or pass --url")
+	}
+	profile.URL = strings.TrimSuffix(profile.URL, "/")
+
+	return profile, nil
+}
+
+// profileCommand handles the profile subcommands, managing the configuration file.
+func profileCommand(e *env, args []string) error {
+	if len(args) == 0 {
+		return usageError(profileUsage)
+	}
+
+	config, err := loadConfig(e.configPath)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		return profileAdd(e, config, args[1:])
+	case "list":
+		return profileList(e, config)
+	case "use":
+		if len(args) != 2 {
+			return usageError("usage: incidentctl profile use NAME")
+		}
+		if _, ok := config.Profiles[args[1]]; !ok {
+			return errors.Errorf("unknown profile %q", args[1])
+		}
+		config.DefaultProfile = args[1]
+		return config.save(e.configPath)
+	case "remove":
+		if len(args) != 2 {
+			return usageError("usage: incidentctl profile remove NAME")
+		}
+		if _, ok := config.Profiles[args[1]]; !ok {
+			return errors.Errorf("unknown profile %q", args[1])
+		}
+		delete(config.Profiles, args[1])
+		if config.DefaultProfile == args[1] {
+			config.DefaultProfile = ""
+		}
+		return config.save(e.configPath)
+	default:
+		return usageError(profileUsage)
+	}
+}
+
+const profileUsage = `usage: incidentctl profile COMMAND
+
+Commands:
+  add [--default] --url URL --token TOKEN NAME   Add or replace a profile
+  list                                           List the profiles
+  use NAME                                       Make a profile the default one
+  remove NAME                                    Remove a profile`
+
+func profileAdd(e *env, config *Config, args []string) error {
+	flags := newFlagSet(e, "profile add")
+	url := flags.String("url", "", "URL of the Mattermost server")
+	token := flags.String("token", "", "personal access token")
+	makeDefault := flags.Bool("default", false, "make it the default profile")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *url == "" {
+		return usageError("usage: incidentctl profile add [--default] --url URL --token TOKEN NAME")
+	}
+
+	name := flags.Arg(0)
+	config.Profiles[name] = &Profile{URL: strings.TrimSuffix(*url, "/"), Token: *token}
+	if *makeDefault || len(config.Profiles) == 1 {
+		config.DefaultProfile = name
+	}
+
+	return config.save(e.configPath)
+}
+
+func profileList(e *env, config *Config) error {
+	type profileRow struct {
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+		Default bool   `json:"default"`
+	}
+
+	rows := []profileRow{}
+	for _, name := range config.profileNames() {
+		rows = append(rows, profileRow{name, config.Profiles[name].URL, name == config.DefaultProfile})
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(rows)
+	}
+
+	table := e.newTable("NAME", "URL", "DEFAULT")
+	for _, row := range rows {
+		isDefault := ""
+		if row.Default {
+			isDefault = "*"
+		}
+		table.row(row.Name, row.URL, isDefault)
+	}
+	return table.flush()
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/pkg/errors"
+)
+
+var incidentCommands = map[string]func(*env, []string) error{
+	"list":   incidentsList,
+	"show":   incidentsShow,
+	"status": incidentsStatus,
+	"check":  incidentsCheck,
+	"assign": incidentsAssign,
+}
+
+// stringsFlag is a flag that can be repeated, or given a comma separated list.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f = append(*f, v)
+		}
+	}
+	return nil
+}
+
+func incidentsList(e *env, args []string) error {
+	var opts ir.IncidentListOptions
+	var statuses stringsFlag
+
+	flags := newFlagSet(e, "incidents list")
+	flags.StringVar(&opts.TeamID, "team", "", "only list the incidents of this team ID")
+	flags.Var(&statuses, "status", "only list the incidents with these statuses: Reported, Active, Resolved or Archived")
+	flags.StringVar(&opts.CommanderUserID, "commander", "", "only list the incidents commanded by this user ID")
+	flags.StringVar(&opts.PlaybookID, "playbook", "", "only list the incidents started from this playbook ID")
+	flags.StringVar(&opts.SearchTerm, "search", "", "only list the incidents whose name contains this term")
+	sort := flags.String("sort", string(ir.CreateAt), "sort by create_at, end_at, name, status, commander_user_id or team_id")
+	direction := flags.String("direction", string(ir.Desc), "sort direction: asc or desc")
+	limit := flags.Int("limit", 50, "maximum number of incidents to list, 0 for all of them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError("usage: incidentctl incidents list [flags]")
+	}
+
+	opts.Statuses = statuses
+	opts.Sort = ir.IncidentSort(*sort)
+	opts.Direction = ir.SortDirection(*direction)
+	opts.PerPage = 100
+	if *limit > 0 && *limit < opts.PerPage {
+		opts.PerPage = *limit
+	}
+	if e.output != outputJSON {
+		// The table only shows what the summaries have.
+		opts.Fields = ir.SummaryFields
+	}
+
+	incidents := []*ir.Incident{}
+	it := e.client.Incidents.Iterate(opts)
+	for (*limit == 0 || len(incidents) < *limit) && it.Next(e.ctx) {
+		incidents = append(incidents, it.Incident())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(incidents)
+	}
+
+	table := e.newTable("ID", "NAME", "STATUS", "COMMANDER", "CREATED", "ENDED")
+	for _, i := range incidents {
+		table.row(i.ID, i.Name, i.CurrentStatus(), i.CommanderUserID, formatTime(i.CreateAt), formatTime(i.EndAt))
+	}
+	return table.flush()
+}
+
+func incidentsShow(e *env, args []string) error {
+	flags := newFlagSet(e, "incidents show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("usage: incidentctl incidents show INCIDENT_ID")
+	}
+
+	return e.showIncident(flags.Arg(0))
+}
+
+// showIncident prints an incident with its checklists. The checklists and items are numbered as
+// expected by the check and assign commands.
+func (e *env) showIncident(incidentID string) error {
+	i, err := e.client.Incidents.Get(e.ctx, incidentID)
+	if err != nil {
+		return err
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(i)
+	}
+
+	fmt.Fprintf(e.stdout, "%s\n\n", i.Name)
+	details := e.newTable("ID", i.ID)
+	details.row("Status", i.CurrentStatus())
+	details.row("Commander", i.CommanderUserID)
+	details.row("Channel", i.ChannelID)
+	details.row("Playbook", i.PlaybookID)
+	details.row("Created", formatTime(i.CreateAt))
+	if i.EndAt != 0 {
+		details.row("Ended", formatTime(i.EndAt))
+	}
+	if err = details.flush(); err != nil {
+		return err
+	}
+
+	for c, checklist := range i.Checklists {
+		fmt.Fprintf(e.stdout, "\n%s\n", checklist.Title)
+		items := e.newTable("#", "STATE", "ITEM", "ASSIGNEE")
+		for n, item := range checklist.Items {
+			items.row(fmt.Sprintf("%d %d", c, n), stateLabel(item.State), item.Title, item.AssigneeID)
+		}
+		if err = items.flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func incidentsStatus(e *env, args []string) error {
+	flags := newFlagSet(e, "incidents status")
+	status := flags.String("status", "", "new status: Reported, Active, Resolved or Archived, defaults to the current one")
+	message := flags.String("message", "", "the status update, or - to read it from the standard input")
+	reminder := flags.Duration("reminder", 0, "when to remind the commander to post the next update, e.g. 30m")
+	override := flags.Bool("override-exit-criteria", false, "resolve the incident even if its exit criteria are not met")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *message == "" {
+		return usageError("usage: incidentctl incidents status [--status STATUS] [--reminder DURATION] --message MESSAGE INCIDENT_ID")
+	}
+	incidentID := flags.Arg(0)
+
+	if *message == "-" {
+		data, err := e.readInput("-")
+		if err != nil {
+			return err
+		}
+		*message = strings.TrimSpace(string(data))
+	}
+
+	if *status == "" {
+		i, err := e.client.Incidents.Get(e.ctx, incidentID)
+		if err != nil {
+			return err
+		}
+		*status = i.CurrentStatus()
+	}
+
+	err := e.client.Incidents.UpdateStatus(e.ctx, incidentID, ir.StatusUpdateOptions{
+		Status:               *status,
+		Message:              *message,
+		Reminder:             *reminder,
+		OverrideExitCriteria: *override,
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.done(incidentID, "Status update posted.")
+}
+
+// checkStates maps the states given to the check command to the states of the checklist items,
+// with the same names as in the /incident task command.
+var checkStates = map[string]string{
+	"done":  ir.ChecklistItemStateClosed,
+	"start": ir.ChecklistItemStateInProgress,
+	"skip":  ir.ChecklistItemStateSkipped,
+	"open":  ir.ChecklistItemStateOpen,
+}
+
+func incidentsCheck(e *env, args []string) error {
+	flags := newFlagSet(e, "incidents check")
+	state := flags.String("state", "done", "new state of the item: done, start, skip or open")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	newState, ok := checkStates[*state]
+	if flags.NArg() != 3 || !ok {
+		return usageError("usage: incidentctl incidents check [--state done|start|skip|open] INCIDENT_ID CHECKLIST ITEM")
+	}
+
+	incidentID := flags.Arg(0)
+	checklistNum, itemNum, err := parseItem(flags.Arg(1), flags.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	if err = e.client.Incidents.SetChecklistItemState(e.ctx, incidentID, checklistNum, itemNum, newState); err != nil {
+		return err
+	}
+
+	return e.done(incidentID, fmt.Sprintf("Item %d %d marked as %s.", checklistNum, itemNum, *state))
+}
+
+func incidentsAssign(e *env, args []string) error {
+	flags := newFlagSet(e, "incidents assign")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 3 && flags.NArg() != 4 {
+		return usageError("usage: incidentctl incidents assign INCIDENT_ID CHECKLIST ITEM [@USERNAME|USER_ID]\n\n" +
+			"Without a user, the item is unassigned.")
+	}
+
+	incidentID := flags.Arg(0)
+	checklistNum, itemNum, err := parseItem(flags.Arg(1), flags.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	assigneeID := flags.Arg(3)
+	if strings.HasPrefix(assigneeID, "@") {
+		if assigneeID, err = e.userIDForUsername(strings.TrimPrefix(assigneeID, "@")); err != nil {
+			return err
+		}
+	}
+
+	if err = e.client.Incidents.SetChecklistItemAssignee(e.ctx, incidentID, checklistNum, itemNum, assigneeID); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Item %d %d assigned to %s.", checklistNum, itemNum, flags.Arg(3))
+	if assigneeID == "" {
+		message = fmt.Sprintf("Item %d %d unassigned.", checklistNum, itemNum)
+	}
+	return e.done(incidentID, message)
+}
+
+// done reports the success of a command modifying an incident: the modified incident is printed
+// in JSON mode, the message otherwise.
+func (e *env) done(incidentID, message string) error {
+	if e.output == outputJSON {
+		return e.showIncident(incidentID)
+	}
+
+	fmt.Fprintln(e.stdout, message)
+	return nil
+}
+
+// parseItem parses the numbers of a checklist and of one of its items, as shown by the show
+// command.
+func parseItem(checklist, item string) (checklistNum, itemNum int, err error) {
+	if checklistNum, err = strconv.Atoi(checklist); err != nil || checklistNum < 0 {
+		return 0, 0, usageError(fmt.Sprintf("invalid checklist number %q", checklist))
+	}
+	if itemNum, err = strconv.Atoi(item); err != nil || itemNum < 0 {
+		return 0, 0, usageError(fmt.Sprintf("invalid item number %q", item))
+	}
+
+	return checklistNum, itemNum, nil
+}
+
+// stateLabel returns how the state of a checklist item is shown.
+func stateLabel(state string) string {
+	switch state {
+	case ir.ChecklistItemStateClosed:
+		return "[x]"
+	case ir.ChecklistItemStateInProgress:
+		return "[~]"
+	case ir.ChecklistItemStateSkipped:
+		return "[-]"
+	default:
+		return "[ ]"
+	}
+}
+
+// userIDForUsername looks a user up with the Mattermost API.
+func (e *env) userIDForUsername(username string) (string, error) {
+	u := fmt.Sprintf("%s/api/v4/users/username/%s", e.profile.URL, url.PathEscape(username))
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the request")
+	}
+
+	ctx, cancel := context.WithTimeout(e.ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := e.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to look up @%s", username)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.Errorf("unknown user @%s", username)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to look up @%s: %s", username, resp.Status)
+	}
+
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", errors.Wrapf(err, "failed to decode user @%s", username)
+	}
+
+	return user.ID, nil
+}
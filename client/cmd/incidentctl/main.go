@@ -0,0 +1,228 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Command incidentctl manages incidents and playbooks from the command line, for scripts, CI
+// jobs and runbooks.
+//
+// It authenticates with a personal access token, and keeps the servers it talks to as profiles in
+// a configuration file:
+//
+//	incidentctl profile add --url https://chat.example.com --token TOKEN prod
+//	incidentctl incidents list --team TEAM_ID --status Active
+//	incidentctl -o json incidents show INCIDENT_ID
+//
+// Run incidentctl without arguments for the list of commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: incidentctl [flags] COMMAND [args]
+
+Commands:
+  incidents list      List and filter incidents
+  incidents show      Show an incident and its checklists
+  incidents status    Post a status update
+  incidents check     Change the state of a checklist item
+  incidents assign    Assign a checklist item
+  playbooks list      List playbooks
+  playbooks export    Export a playbook as JSON
+  playbooks import    Create or replace a playbook from JSON
+  playbooks sync      Plan and apply the sync of a team's playbooks with a directory of files
+  profile             Manage the server profiles
+
+Flags:
+  --profile NAME      Profile to use, defaults to INCIDENTCTL_PROFILE or the default profile
+  --url URL           Server URL, overriding the profile, defaults to INCIDENTCTL_URL
+  --token TOKEN       Personal access token, overriding the profile, defaults to INCIDENTCTL_TOKEN
+  --config PATH       Configuration file, defaults to INCIDENTCTL_CONFIG or the user configuration directory
+  -o, --output FORMAT Output format: table or json (default table)`
+
+// The output formats.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// env is what the commands run with.
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	output     string
+	configPath string
+
+	// profile is the server the commands talk to, set once the global flags are parsed.
+	profile    Profile
+	httpClient *http.Client
+	client     *ir.Client
+}
+
+// usageError is an error reporting the wrong use of a command.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr, http.DefaultTransport))
+}
+
+// run runs incidentctl with the given arguments, sending the requests through transport, and
+// returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, transport http.RoundTripper) int {
+	e := &env{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	err := e.run(args, transport)
+	if err == nil {
+		return 0
+	}
+
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintln(stderr, uerr)
+		return 2
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+
+	fmt.Fprintf(stderr, "error: %s\n", describeError(err))
+	return 1
+}
+
+func (e *env) run(args []string, transport http.RoundTripper) error {
+	flags := newFlagSet(e, "incidentctl")
+	flags.Usage = func() { fmt.Fprintln(e.stderr, usage) }
+	profileName := flags.String("profile", os.Getenv("INCIDENTCTL_PROFILE"), "")
+	url := flags.String("url", os.Getenv("INCIDENTCTL_URL"), "")
+	token := flags.String("token", os.Getenv("INCIDENTCTL_TOKEN"), "")
+	flags.StringVar(&e.configPath, "config", os.Getenv("INCIDENTCTL_CONFIG"), "")
+	flags.StringVar(&e.output, "output", outputTable, "")
+	flags.StringVar(&e.output, "o", outputTable, "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if e.output != outputTable && e.output != outputJSON {
+		return usageError(fmt.Sprintf("unknown output format %q, use table or json", e.output))
+	}
+
+	if e.configPath == "" {
+		path, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		e.configPath = path
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError(usage)
+	}
+
+	if args[0] == "profile" {
+		return profileCommand(e, args[1:])
+	}
+
+	var commands map[string]func(*env, []string) error
+	switch args[0] {
+	case "incidents":
+		commands = incidentCommands
+	case "playbooks":
+		commands = playbookCommands
+	default:
+		return usageError(usage)
+	}
+
+	if len(args) < 2 || commands[args[1]] == nil {
+		return usageError(usage)
+	}
+
+	config, err := loadConfig(e.configPath)
+	if err != nil {
+		return err
+	}
+	if e.profile, err = config.resolve(*profileName, *url, *token); err != nil {
+		return err
+	}
+
+	e.httpClient = &http.Client{Transport: &tokenTransport{token: e.profile.Token, base: transport}}
+	if e.client, err = ir.NewClient(e.profile.URL+"/", e.httpClient); err != nil {
+		return errors.Wrapf(err, "invalid server URL %s", e.profile.URL)
+	}
+
+	return commands[args[1]](e, args[2:])
+}
+
+// tokenTransport authenticates the requests with a personal access token.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// newFlagSet returns a flag set reporting its errors instead of exiting.
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	return flags
+}
+
+// describeError returns the message of an error for the user, including the validation errors
+// of the server, if any.
+func describeError(err error) string {
+	var errorResponse *ir.ErrorResponse
+	if !errors.As(err, &errorResponse) {
+		return err.Error()
+	}
+
+	message := errorResponse.Message
+	switch {
+	case errors.Is(err, ir.ErrNotFound):
+		message = "not found: " + message
+	case errors.Is(err, ir.ErrForbidden):
+		message = "permission denied: " + message
+	case errorResponse.Response.StatusCode == http.StatusUnauthorized:
+		message = "not authenticated, check the token: " + message
+	}
+
+	for _, issue := range errorResponse.Errors {
+		message += fmt.Sprintf("\n  %s: %s", issue.Field, issue.Message)
+	}
+
+	return message
+}
+
+// readInput reads the content of a file, or of the standard input if path is "-".
+func (e *env) readInput(path string) ([]byte, error) {
+	if path == "-" {
+		data, err := ioutil.ReadAll(e.stdin)
+		return data, errors.Wrap(err, "failed to read the standard input")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	return data, errors.Wrapf(err, "failed to read %s", path)
+}
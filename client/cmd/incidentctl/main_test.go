@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/stretchr/testify/require"
+)
+
+const apiPrefix = "/plugins/com.mattermost.plugin-incident-management/api/v0/"
+
+const testIncident = `{
+	"id": "incident1",
+	"name": "Database outage",
+	"commander_user_id": "user1",
+	"channel_id": "channel1",
+	"create_at": 1600000000000,
+	"status_posts": [{"id": "post1", "status": "Active", "create_at": 1600000001000}],
+	"checklists": [{"title": "Triage", "items": [
+		{"title": "Page the team", "state": "closed"},
+		{"title": "Fail over", "assignee_id": "user2"}
+	]}]
+}`
+
+// testServer is a Mattermost server checking the token of the requests.
+type testServer struct {
+	mux    *http.ServeMux
+	server *httptest.Server
+}
+
+func newTestServer(t *testing.T) *testServer {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "invalid token"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return &testServer{mux: mux, server: server}
+}
+
+func (s *testServer) handle(path string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(path, handler)
+}
+
+// runCommand runs incidentctl against the test server with a fresh configuration file.
+func runCommand(t *testing.T, s *testServer, stdin string, args ...string) (code int, stdout, stderr string) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	args = append([]string{"--config", configPath, "--url", s.server.URL, "--token", "token1"}, args...)
+
+	return runWithConfig(t, stdin, args...)
+}
+
+func runWithConfig(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut, http.DefaultTransport)
+
+	return code, out.String(), errOut.String()
+}
+
+func TestIncidentsList(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "team1", r.URL.Query().Get("team_id"))
+		require.Equal(t, []string{"Reported", "Active"}, r.URL.Query()["status"])
+		if r.URL.Query().Get("fields") == "summary" {
+			fmt.Fprint(w, `{"has_more": false, "items": [{"id": "incident1", "name": "Database outage",
+				"commander_user_id": "user1", "create_at": 1600000000000,
+				"summary": {"status": "Active", "tasks_total": 2, "tasks_done": 1}}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"has_more": false, "items": [%s]}`, testIncident)
+	})
+
+	code, stdout, stderr := runCommand(t, s, "", "incidents", "list", "--team", "team1", "--status", "Reported,Active")
+	require.Equal(t, 0, code, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	require.Regexp(t, `^ID\s+NAME\s+STATUS\s+COMMANDER\s+CREATED\s+ENDED$`, lines[0])
+	require.Regexp(t, `^incident1\s+Database outage\s+Active\s+user1\s+2020-09-`, lines[1])
+
+	code, stdout, stderr = runCommand(t, s, "", "-o", "json", "incidents", "list", "--team", "team1", "--status", "Reported", "--status", "Active")
+	require.Equal(t, 0, code, stderr)
+	var incidents []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &incidents))
+	require.Len(t, incidents, 1)
+	require.Equal(t, "incident1", incidents[0]["id"])
+}
+
+func TestIncidentsListLimit(t *testing.T) {
+	s := newTestServer(t)
+
+	var calls int
+	s.handle(apiPrefix+"incidents", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "2", r.URL.Query().Get("per_page"))
+		fmt.Fprint(w, `{"has_more": true, "next_cursor": "c1", "items": [{"id": "1"}, {"id": "2"}]}`)
+	})
+
+	code, stdout, stderr := runCommand(t, s, "", "-o", "json", "incidents", "list", "--limit", "2")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, 1, calls)
+
+	var incidents []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &incidents))
+	require.Len(t, incidents, 2)
+}
+
+func TestIncidentsShow(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents/incident1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testIncident)
+	})
+
+	code, stdout, stderr := runCommand(t, s, "", "incidents", "show", "incident1")
+	require.Equal(t, 0, code, stderr)
+	require.Contains(t, stdout, "Database outage")
+	require.Regexp(t, `Status\s+Active`, stdout)
+	require.Regexp(t, `0 0\s+\[x\]\s+Page the team`, stdout)
+	require.Regexp(t, `0 1\s+\[ \]\s+Fail over\s+user2`, stdout)
+}
+
+func TestIncidentsShowNotFound(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "Not found"}`)
+	})
+
+	code, _, stderr := runCommand(t, s, "", "incidents", "show", "missing")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: not found: Not found\n", stderr)
+}
+
+func TestIncidentsStatus(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents/incident1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testIncident)
+	})
+
+	var submission map[string]interface{}
+	s.handle(apiPrefix+"incidents/incident1/update-status-dialog", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		var request struct {
+			Submission map[string]interface{} `json:"submission"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		submission = request.Submission
+	})
+
+	code, stdout, stderr := runCommand(t, s, "Replica promoted.\n", "incidents", "status", "--message", "-", "--reminder", "30m", "incident1")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "Status update posted.\n", stdout)
+	require.Equal(t, map[string]interface{}{
+		"status":                 "Active",
+		"message":                "Replica promoted.",
+		"reminder":               "1800",
+		"override_exit_criteria": false,
+	}, submission)
+}
+
+func TestIncidentsStatusUnmetExitCriteria(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents/incident1/update-status-dialog", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors": {"status": "Severity must be set."}}`)
+	})
+
+	code, _, stderr := runCommand(t, s, "", "incidents", "status", "--status", "Resolved", "--message", "Fixed", "incident1")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: status: Severity must be set.\n", stderr)
+}
+
+func TestIncidentsCheckAndAssign(t *testing.T) {
+	s := newTestServer(t)
+
+	var requests []string
+	s.handle(apiPrefix+"incidents/incident1/checklists/0/item/1/state", func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r.Method+" state "+strings.TrimSpace(string(body)))
+	})
+	s.handle(apiPrefix+"incidents/incident1/checklists/0/item/1/assignee", func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r.Method+" assignee "+strings.TrimSpace(string(body)))
+	})
+	s.handle("/api/v4/users/username/alice", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "alice_id", "username": "alice"}`)
+	})
+
+	code, stdout, stderr := runCommand(t, s, "", "incidents", "check", "--state", "start", "incident1", "0", "1")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "Item 0 1 marked as start.\n", stdout)
+
+	code, stdout, stderr = runCommand(t, s, "", "incidents", "assign", "incident1", "0", "1", "@alice")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "Item 0 1 assigned to @alice.\n", stdout)
+
+	code, stdout, stderr = runCommand(t, s, "", "incidents", "assign", "incident1", "0", "1")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "Item 0 1 unassigned.\n", stdout)
+
+	require.Equal(t, []string{
+		`PUT state {"new_state":"in_progress"}`,
+		`PUT assignee {"assignee_id":"alice_id"}`,
+		`PUT assignee {"assignee_id":""}`,
+	}, requests)
+
+	code, _, _ = runCommand(t, s, "", "incidents", "check", "--state", "finished", "incident1", "0", "1")
+	require.Equal(t, 2, code)
+}
+
+func TestPlaybooksExportImport(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"playbooks/playbook1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"id": "playbook1", "title": "Outage", "team_id": "team1", "version": 4, "create_at": 10,
+				"checklists": [{"title": "Triage", "items": [{"title": "Page the team"}]}]}`)
+		case http.MethodPut:
+			var pbook map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pbook))
+			require.Equal(t, "playbook1", pbook["id"])
+			require.Equal(t, "Outage v2", pbook["title"])
+			require.Equal(t, "team1", pbook["team_id"])
+			require.Equal(t, float64(4), pbook["version"])
+		}
+	})
+
+	var created map[string]interface{}
+	s.handle(apiPrefix+"playbooks", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "playbook2"}`)
+	})
+
+	exportPath := filepath.Join(t.TempDir(), "outage.json")
+	code, _, stderr := runCommand(t, s, "", "playbooks", "export", "--file", exportPath, "playbook1")
+	require.Equal(t, 0, code, stderr)
+
+	exported, err := ioutil.ReadFile(exportPath)
+	require.NoError(t, err)
+	require.Contains(t, string(exported), `"title": "Page the team"`)
+
+	code, stdout, stderr := runCommand(t, s, string(exported), "playbooks", "import", "--team", "team2", "-")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "playbook2\n", stdout)
+	require.Equal(t, "", created["id"])
+	require.Equal(t, "team2", created["team_id"])
+	require.Equal(t, float64(0), created["version"])
+	require.Equal(t, "Outage", created["title"])
+
+	changed := strings.Replace(string(exported), `"title": "Outage"`, `"title": "Outage v2"`, 1)
+	code, stdout, stderr = runCommand(t, s, changed, "playbooks", "import", "--replace", "playbook1", "-")
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, "playbook1\n", stdout)
+}
+
+func TestPlaybooksImportInvalid(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"playbooks", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": "invalid playbook", "errors": [{"field": "title", "message": "The title is required."}]}`)
+	})
+
+	code, _, stderr := runCommand(t, s, `{"team_id": "team1"}`, "playbooks", "import", "-")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: invalid playbook\n  title: The title is required.\n", stderr)
+}
+
+// syncServer serves the playbooks of team1 for the sync tests, and records the changes made to
+// them.
+func syncServer(t *testing.T, version *int) (s *testServer, changes *[]string) {
+	s = newTestServer(t)
+	changes = &[]string{}
+
+	s.handle(apiPrefix+"playbooks", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			require.Equal(t, "team1", r.URL.Query().Get("team_id"))
+			fmt.Fprintf(w, `{"has_more": false, "items": [
+				{"id": "playbook1", "title": "SEV1", "team_id": "team1", "external_id": "sev1", "version": %d},
+				{"id": "playbook2", "title": "Old runbook", "team_id": "team1", "external_id": "old", "version": 1},
+				{"id": "playbook3", "title": "Not synced", "team_id": "team1"},
+				{"id": "playbook4", "title": "Global", "team_id": "team2", "external_id": "global", "is_global": true}
+			]}`, *version)
+		case http.MethodPost:
+			var pbook map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pbook))
+			require.Equal(t, "team1", pbook["team_id"])
+			*changes = append(*changes, fmt.Sprintf("create %s %s", pbook["external_id"], pbook["title"]))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": "playbook5"}`)
+		}
+	})
+	s.handle(apiPrefix+"playbooks/playbook1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprintf(w, `{"id": "playbook1", "title": "SEV1", "description": "Kept", "team_id": "team1",
+				"external_id": "sev1", "version": %d,
+				"checklists": [{"id": "checklist1", "title": "Triage", "items": [{"id": "item1", "title": "Page the team"}]}]}`, *version)
+		case http.MethodPut:
+			var pbook ir.Playbook
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pbook))
+			require.Equal(t, "Kept", pbook.Description)
+			require.Equal(t, "checklist1", pbook.Checklists[0].ID)
+			require.Equal(t, "item1", pbook.Checklists[0].Items[0].ID)
+			*changes = append(*changes, fmt.Sprintf("update %s %s", pbook.ExternalID, pbook.Title))
+		}
+	})
+	s.handle(apiPrefix+"playbooks/playbook2", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"id": "playbook2", "title": "Old runbook", "team_id": "team1", "external_id": "old", "version": 1}`)
+		case http.MethodDelete:
+			*changes = append(*changes, "delete playbook2")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	return s, changes
+}
+
+// writeSyncFiles writes the playbook files of the sync tests.
+func writeSyncFiles(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sev1.json": `{"external_id": "sev1", "title": "SEV1 response", "checklists": [
+			{"title": "Triage", "items": [{"title": "Page the team"}, {"title": "Page the DBA", "command": "/page dba"}]}
+		]}`,
+		"sev2.json": `{"external_id": "sev2", "title": "SEV2 response"}`,
+		"README.md": "Not a playbook",
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	return dir
+}
+
+func TestPlaybooksSync(t *testing.T) {
+	version := 3
+	s, changes := syncServer(t, &version)
+	dir := writeSyncFiles(t)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+
+	code, stdout, stderr := runCommand(t, s, "", "playbooks", "sync", "plan", "--team", "team1", "--out", planPath, dir)
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, fmt.Sprintf(`~ update sev1 "SEV1 response", playbook playbook1 version 3
+    checklists:
+      + item "Page the DBA" in "Triage"
+    title: "SEV1" -> "SEV1 response"
++ create sev2 "SEV2 response" from %s
+- delete old "Old runbook", playbook playbook2 version 1
+
+Plan: 1 to create, 1 to update, 1 to delete.
+`, filepath.Join(dir, "sev2.json")), stdout)
+	require.Empty(t, *changes)
+
+	code, stdout, stderr = runCommand(t, s, "", "playbooks", "sync", "apply", planPath)
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, []string{"update sev1 SEV1 response", "create sev2 SEV2 response", "delete playbook2"}, *changes)
+	require.Contains(t, stdout, "created sev2, playbook playbook5\n")
+	require.Contains(t, stdout, "Applied 3 changes.\n")
+}
+
+func TestPlaybooksSyncDrift(t *testing.T) {
+	version := 3
+	s, changes := syncServer(t, &version)
+	dir := writeSyncFiles(t)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+
+	code, _, stderr := runCommand(t, s, "", "playbooks", "sync", "plan", "--team", "team1", "--out", planPath, dir)
+	require.Equal(t, 0, code, stderr)
+
+	// Someone edits the playbook in the meantime.
+	version = 4
+
+	code, _, stderr = runCommand(t, s, "", "playbooks", "sync", "apply", planPath)
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: playbook sev1 changed since the plan was made, from version 3 to 4: make a new plan\n", stderr)
+	require.Empty(t, *changes)
+
+	// A new plan shows the playbook still differs from its file.
+	code, stdout, stderr := runCommand(t, s, "", "playbooks", "sync", "plan", "--team", "team1", dir)
+	require.Equal(t, 0, code, stderr)
+	require.Contains(t, stdout, "~ update sev1 \"SEV1 response\", playbook playbook1 version 4\n")
+}
+
+func TestPlaybooksSyncInvalidFiles(t *testing.T) {
+	version := 3
+	s, _ := syncServer(t, &version)
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"title": "No external ID"}`), 0600))
+	code, _, stderr := runCommand(t, s, "", "playbooks", "sync", "plan", "--team", "team1", dir)
+	require.Equal(t, 1, code)
+	require.Equal(t, fmt.Sprintf("error: %s has no external_id\n", filepath.Join(dir, "a.json")), stderr)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"external_id": "a", "titel": "Typo"}`), 0600))
+	code, _, stderr = runCommand(t, s, "", "playbooks", "sync", "plan", "--team", "team1", dir)
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr, `unknown field "titel"`)
+
+	code, _, _ = runCommand(t, s, "", "playbooks", "sync", "plan", dir)
+	require.Equal(t, 2, code)
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	code, _, stderr := runWithConfig(t, "", "--config", configPath, "--url", s.server.URL, "--token", "wrong", "incidents", "show", "incident1")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: not authenticated, check the token: invalid token\n", stderr)
+}
+
+func TestProfiles(t *testing.T) {
+	s := newTestServer(t)
+	s.handle(apiPrefix+"incidents/incident1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testIncident)
+	})
+
+	configPath := filepath.Join(t.TempDir(), "incidentctl", "config.json")
+
+	code, _, stderr := runWithConfig(t, "", "--config", configPath, "profile", "add", "--url", s.server.URL+"/", "--token", "token1", "test")
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = runWithConfig(t, "", "--config", configPath, "profile", "add", "--url", "http://staging.invalid", "--token", "token2", "staging")
+	require.Equal(t, 0, code, stderr)
+
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	code, stdout, stderr := runWithConfig(t, "", "--config", configPath, "profile", "list")
+	require.Equal(t, 0, code, stderr)
+	require.Regexp(t, `staging\s+http://staging.invalid\s*\n`, stdout)
+	require.Regexp(t, `test\s+`+s.server.URL+`\s+\*`, stdout)
+
+	// The first profile is the default one.
+	code, stdout, stderr = runWithConfig(t, "", "--config", configPath, "-o", "json", "incidents", "show", "incident1")
+	require.Equal(t, 0, code, stderr)
+	require.Contains(t, stdout, `"name": "Database outage"`)
+
+	code, _, stderr = runWithConfig(t, "", "--config", configPath, "profile", "use", "staging")
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = runWithConfig(t, "", "--config", configPath, "--profile", "test", "incidents", "show", "incident1")
+	require.Equal(t, 0, code, stderr)
+
+	code, _, stderr = runWithConfig(t, "", "--config", configPath, "--profile", "missing", "incidents", "show", "incident1")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: unknown profile \"missing\"\n", stderr)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printJSON writes v as indented JSON.
+func (e *env) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+// table writes rows as aligned columns.
+type table struct {
+	writer *tabwriter.Writer
+}
+
+// newTable starts a table with the given column headers.
+func (e *env) newTable(headers ...string) *table {
+	t := &table{writer: tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)}
+	t.row(headers...)
+	return t
+}
+
+// row adds a row to the table. Tabs and new lines in the cells are replaced by spaces to keep
+// the columns aligned.
+func (t *table) row(cells ...string) {
+	for i, cell := range cells {
+		cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+	}
+	fmt.Fprintln(t.writer, strings.Join(cells, "\t"))
+}
+
+// flush writes the table.
+func (t *table) flush() error {
+	return t.writer.Flush()
+}
+
+// formatTime formats a time in milliseconds since the epoch in the local time zone, or returns
+// an empty string for 0.
+func formatTime(millis int64) string {
+	if millis == 0 {
+		return ""
+	}
+
+	return time.Unix(0, millis*int64(time.Millisecond)).Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/pkg/errors"
+)
+
+var playbookCommands = map[string]func(*env, []string) error{
+	"list":   playbooksList,
+	"export": playbooksExport,
+	"import": playbooksImport,
+	"sync":   playbooksSync,
+}
+
+func playbooksList(e *env, args []string) error {
+	var opts ir.PlaybookListOptions
+
+	flags := newFlagSet(e, "playbooks list")
+	flags.StringVar(&opts.TeamID, "team", "", "only list the playbooks of this team ID")
+	flags.BoolVar(&opts.MemberOnly, "member-only", false, "only list the playbooks you are a member of")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError("usage: incidentctl playbooks list [--team TEAM_ID] [--member-only]")
+	}
+
+	playbooks, err := e.listPlaybooks(opts)
+	if err != nil {
+		return err
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(playbooks)
+	}
+
+	table := e.newTable("ID", "TITLE", "TEAM", "CHECKLISTS", "ITEMS", "VERSION")
+	for _, p := range playbooks {
+		table.row(p.ID, p.Title, p.TeamID, strconv.FormatInt(p.NumStages, 10), strconv.FormatInt(p.NumSteps, 10),
+			strconv.FormatInt(p.Version, 10))
+	}
+	return table.flush()
+}
+
+// listPlaybooks returns all the playbooks matching the options, going through the pages.
+func (e *env) listPlaybooks(opts ir.PlaybookListOptions) ([]*ir.Playbook, error) {
+	opts.PerPage = 100
+	playbooks := []*ir.Playbook{}
+	for {
+		list, err := e.client.Playbooks.List(e.ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		playbooks = append(playbooks, list.Items...)
+		if !list.HasMore || list.NextCursor == "" {
+			return playbooks, nil
+		}
+		opts.Cursor = list.NextCursor
+	}
+}
+
+// playbooksExport writes a playbook as JSON, whatever the output format, so that it can be kept
+// in version control and imported again.
+func playbooksExport(e *env, args []string) error {
+	flags := newFlagSet(e, "playbooks export")
+	file := flags.String("file", "", "write the playbook to this file instead of the standard output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("usage: incidentctl playbooks export [--file PATH] PLAYBOOK_ID")
+	}
+
+	pbook, err := e.client.Playbooks.Get(e.ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(pbook, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the playbook")
+	}
+	data = append(data, '\n')
+
+	if *file == "" {
+		_, err = e.stdout.Write(data)
+		return err
+	}
+
+	return errors.Wrapf(ioutil.WriteFile(*file, data, 0644), "failed to write %s", *file)
+}
+
+// playbooksImport creates a playbook from an exported one, or replaces an existing playbook
+// with it.
+func playbooksImport(e *env, args []string) error {
+	flags := newFlagSet(e, "playbooks import")
+	teamID := flags.String("team", "", "team ID of the new playbook, defaults to the one of the exported playbook")
+	replaceID := flags.String("replace", "", "replace the playbook with this ID instead of creating a new one")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("usage: incidentctl playbooks import [--team TEAM_ID] [--replace PLAYBOOK_ID] FILE\n\n" +
+			"Use - as FILE to read the playbook from the standard input.")
+	}
+
+	data, err := e.readInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var pbook ir.Playbook
+	if err = json.Unmarshal(data, &pbook); err != nil {
+		return errors.Wrapf(err, "failed to parse the playbook in %s", flags.Arg(0))
+	}
+
+	if *teamID != "" {
+		pbook.TeamID = *teamID
+	}
+
+	if *replaceID != "" {
+		var existing *ir.Playbook
+		if existing, err = e.client.Playbooks.Get(e.ctx, *replaceID); err != nil {
+			return err
+		}
+
+		// Keep what identifies the playbook, the rest is replaced.
+		pbook.ID = existing.ID
+		pbook.CreateAt = existing.CreateAt
+		pbook.Version = existing.Version
+		pbook.ExternalID = existing.ExternalID
+		if *teamID == "" {
+			pbook.TeamID = existing.TeamID
+		}
+
+		if err = e.client.Playbooks.Update(e.ctx, pbook); err != nil {
+			return err
+		}
+	} else {
+		pbook.ID = ""
+		pbook.CreateAt = 0
+		pbook.DeleteAt = 0
+		pbook.Version = 0
+		pbook.ExternalID = ""
+		if pbook.ID, err = e.client.Playbooks.Create(e.ctx, pbook); err != nil {
+			return err
+		}
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(map[string]string{"id": pbook.ID})
+	}
+
+	fmt.Fprintln(e.stdout, pbook.ID)
+	return nil
+}
@@ -0,0 +1,641 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/pkg/errors"
+)
+
+// The sync commands keep the playbooks of a team in line with a directory of playbook files, e.g.
+// kept in version control. Each file holds a playbook as written by the export command, with an
+// external_id identifying it: renaming a playbook in its file updates it instead of creating a
+// new one.
+//
+// Only the fields present in a file are managed, the others keep their value on the server. The
+// playbooks of the team with an external ID missing from the directory are deleted, the ones
+// without an external ID are left alone.
+
+var syncCommands = map[string]func(*env, []string) error{
+	"plan":  playbooksSyncPlan,
+	"apply": playbooksSyncApply,
+}
+
+func playbooksSync(e *env, args []string) error {
+	if len(args) == 0 || syncCommands[args[0]] == nil {
+		return usageError("usage: incidentctl playbooks sync plan|apply [args]")
+	}
+
+	return syncCommands[args[0]](e, args[1:])
+}
+
+// The actions of a sync plan.
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionDelete = "delete"
+)
+
+// serverFields are the fields of the playbook files that are ignored, because the server sets
+// them, or, for the external ID, because it is what the files are matched on.
+var serverFields = map[string]bool{
+	"id":                  true,
+	"team_id":             true,
+	"create_at":           true,
+	"delete_at":           true,
+	"num_stages":          true,
+	"num_steps":           true,
+	"version":             true,
+	"forked_from_id":      true,
+	"forked_from_version": true,
+	"external_id":         true,
+}
+
+// syncPlan lists the changes making the playbooks of a team match the files.
+type syncPlan struct {
+	TeamID  string       `json:"team_id"`
+	Changes []syncChange `json:"changes"`
+}
+
+// syncChange is the creation, update or deletion of a playbook.
+type syncChange struct {
+	Action     string `json:"action"`
+	ExternalID string `json:"external_id"`
+	Title      string `json:"title"`
+	File       string `json:"file,omitempty"`
+
+	// PlaybookID and Version identify the playbook to update or delete, and the version the plan
+	// was made from. The plan can't be applied once the playbook changed.
+	PlaybookID string `json:"playbook_id,omitempty"`
+	Version    int64  `json:"version,omitempty"`
+
+	// Fields are the fields changed by an update.
+	Fields []fieldChange `json:"fields,omitempty"`
+
+	// Playbook is the playbook to create, or the updated playbook.
+	Playbook *ir.Playbook `json:"playbook,omitempty"`
+}
+
+// fieldChange is a field changed by an update. Changes details the changes to the checklists,
+// properties and exit criteria.
+type fieldChange struct {
+	Field   string              `json:"field"`
+	Old     json.RawMessage     `json:"old"`
+	New     json.RawMessage     `json:"new"`
+	Changes []ir.PlaybookChange `json:"changes,omitempty"`
+}
+
+// playbookFile is a playbook definition file, with the fields it manages.
+type playbookFile struct {
+	path       string
+	externalID string
+	fields     map[string]json.RawMessage
+}
+
+func playbooksSyncPlan(e *env, args []string) error {
+	flags := newFlagSet(e, "playbooks sync plan")
+	teamID := flags.String("team", "", "team ID of the playbooks")
+	out := flags.String("out", "", "save the plan to this file, for sync apply")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *teamID == "" {
+		return usageError("usage: incidentctl playbooks sync plan --team TEAM_ID [--out PLAN_FILE] DIR")
+	}
+
+	files, err := readPlaybookFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	current, err := e.syncedPlaybooks(*teamID)
+	if err != nil {
+		return err
+	}
+
+	plan, err := makeSyncPlan(*teamID, files, current)
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		var data []byte
+		if data, err = json.MarshalIndent(plan, "", "  "); err != nil {
+			return errors.Wrap(err, "failed to encode the plan")
+		}
+		if err = ioutil.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s", *out)
+		}
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(plan)
+	}
+
+	e.printPlan(plan)
+	return nil
+}
+
+// readPlaybookFiles reads the playbooks of the JSON files of a directory.
+func readPlaybookFiles(dir string) ([]playbookFile, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+
+	var files []playbookFile
+	paths := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", path)
+		}
+
+		// Catch the misspelled fields, which would otherwise be silently ignored.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		var pbook ir.Playbook
+		if err = decoder.Decode(&pbook); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the playbook in %s", path)
+		}
+
+		file := playbookFile{path: path, externalID: pbook.ExternalID}
+		if err = json.Unmarshal(data, &file.fields); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the playbook in %s", path)
+		}
+
+		if file.externalID == "" {
+			return nil, errors.Errorf("%s has no external_id", path)
+		}
+		if other, ok := paths[file.externalID]; ok {
+			return nil, errors.Errorf("%s and %s have the same external_id '%s'", other, path, file.externalID)
+		}
+		paths[file.externalID] = path
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// syncedPlaybooks returns the playbooks of the team that have an external ID, by external ID.
+func (e *env) syncedPlaybooks(teamID string) (map[string]*ir.Playbook, error) {
+	playbooks, err := e.listPlaybooks(ir.PlaybookListOptions{TeamID: teamID})
+	if err != nil {
+		return nil, err
+	}
+
+	synced := map[string]*ir.Playbook{}
+	for _, listed := range playbooks {
+		// The list includes the playbooks published to the team.
+		if listed.TeamID != teamID || listed.ExternalID == "" {
+			continue
+		}
+
+		// The list doesn't include the checklists.
+		pbook, err := e.client.Playbooks.Get(e.ctx, listed.ID)
+		if err != nil {
+			return nil, err
+		}
+		synced[pbook.ExternalID] = pbook
+	}
+
+	return synced, nil
+}
+
+// makeSyncPlan compares the playbook files to the playbooks of the team with an external ID.
+func makeSyncPlan(teamID string, files []playbookFile, current map[string]*ir.Playbook) (*syncPlan, error) {
+	plan := &syncPlan{TeamID: teamID, Changes: []syncChange{}}
+
+	for _, file := range files {
+		existing := current[file.externalID]
+
+		pbook, err := file.playbook(existing)
+		if err != nil {
+			return nil, err
+		}
+		pbook.TeamID = teamID
+		pbook.ExternalID = file.externalID
+
+		if existing == nil {
+			plan.Changes = append(plan.Changes, syncChange{
+				Action:     actionCreate,
+				ExternalID: file.externalID,
+				Title:      pbook.Title,
+				File:       file.path,
+				Playbook:   pbook,
+			})
+			continue
+		}
+
+		fields, err := diffPlaybooks(existing, pbook, file.fields)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		plan.Changes = append(plan.Changes, syncChange{
+			Action:     actionUpdate,
+			ExternalID: file.externalID,
+			Title:      pbook.Title,
+			File:       file.path,
+			PlaybookID: existing.ID,
+			Version:    existing.Version,
+			Fields:     fields,
+			Playbook:   pbook,
+		})
+	}
+
+	inFiles := map[string]bool{}
+	for _, file := range files {
+		inFiles[file.externalID] = true
+	}
+
+	var deleted []string
+	for externalID := range current {
+		if !inFiles[externalID] {
+			deleted = append(deleted, externalID)
+		}
+	}
+	sort.Strings(deleted)
+
+	for _, externalID := range deleted {
+		existing := current[externalID]
+		plan.Changes = append(plan.Changes, syncChange{
+			Action:     actionDelete,
+			ExternalID: externalID,
+			Title:      existing.Title,
+			PlaybookID: existing.ID,
+			Version:    existing.Version,
+		})
+	}
+
+	return plan, nil
+}
+
+// playbook returns the playbook of the file. Given the playbook on the server, the fields that
+// are not in the file keep their value, and the checklists and properties keep their IDs.
+func (f playbookFile) playbook(existing *ir.Playbook) (*ir.Playbook, error) {
+	fields := map[string]json.RawMessage{}
+	if existing != nil {
+		var err error
+		if fields, err = playbookFields(existing); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, value := range f.fields {
+		if !serverFields[name] {
+			fields[name] = value
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode the playbook in %s", f.path)
+	}
+
+	pbook := new(ir.Playbook)
+	if err = json.Unmarshal(data, pbook); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the playbook in %s", f.path)
+	}
+
+	if existing != nil {
+		adoptIDs(pbook, existing)
+	}
+
+	return pbook, nil
+}
+
+// playbookFields returns the JSON fields of a playbook.
+func playbookFields(pbook *ir.Playbook) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(pbook)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode playbook %s", pbook.ID)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode playbook %s", pbook.ID)
+	}
+
+	return fields, nil
+}
+
+// adoptIDs gives the checklists, items, properties and options of a playbook without IDs the ones
+// they have in the existing playbook, matching them by title as the diff does. The files don't
+// need to hold IDs, and the IDs don't change when a playbook is synced.
+func adoptIDs(pbook, existing *ir.Playbook) {
+	for i := range pbook.Checklists {
+		checklist := &pbook.Checklists[i]
+		old := findChecklist(existing.Checklists, checklist.Title)
+		if old == nil {
+			continue
+		}
+		if checklist.ID == "" {
+			checklist.ID = old.ID
+		}
+
+		for j := range checklist.Items {
+			item := &checklist.Items[j]
+			for _, oldItem := range old.Items {
+				if item.ID == "" && sameTitle(item.Title, oldItem.Title) {
+					item.ID = oldItem.ID
+				}
+			}
+		}
+	}
+
+	if pbook.Propertylist.ID == "" {
+		pbook.Propertylist.ID = existing.Propertylist.ID
+	}
+	for i := range pbook.Propertylist.Items {
+		property := &pbook.Propertylist.Items[i]
+		old := playbook.FindProperty(existing.Propertylist, property.Title)
+		if old == nil {
+			continue
+		}
+		if property.ID == "" {
+			property.ID = old.ID
+		}
+
+		for j := range property.Selection.Items {
+			option := &property.Selection.Items[j]
+			for _, oldOption := range old.Selection.Items {
+				if option.ID == "" && sameTitle(option.Value, oldOption.Value) {
+					option.ID = oldOption.ID
+				}
+			}
+		}
+	}
+}
+
+func findChecklist(checklists []ir.Checklist, title string) *ir.Checklist {
+	for i := range checklists {
+		if sameTitle(checklists[i].Title, title) {
+			return &checklists[i]
+		}
+	}
+	return nil
+}
+
+func sameTitle(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// diffPlaybooks returns the changes to the managed fields going from the old to the new playbook.
+func diffPlaybooks(oldPlaybook, newPlaybook *ir.Playbook, managed map[string]json.RawMessage) ([]fieldChange, error) {
+	oldFields, err := playbookFields(oldPlaybook)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := playbookFields(newPlaybook)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range managed {
+		if !serverFields[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var details []ir.PlaybookChange
+	var fields []fieldChange
+	for _, name := range names {
+		if bytes.Equal(oldFields[name], newFields[name]) {
+			continue
+		}
+
+		field := fieldChange{Field: name, Old: oldFields[name], New: newFields[name]}
+		if name == "checklists" || name == "propertylist" || name == "exit_criteria" {
+			if details == nil {
+				if details, err = diffContent(oldPlaybook, newPlaybook); err != nil {
+					return nil, err
+				}
+			}
+			field.Changes = changesOf(details, name)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// diffContent returns the changes to the checklists, properties and exit criteria, as the server
+// describes them.
+func diffContent(oldPlaybook, newPlaybook *ir.Playbook) ([]ir.PlaybookChange, error) {
+	var old, updated playbook.Playbook
+	if err := convert(oldPlaybook, &old); err != nil {
+		return nil, err
+	}
+	if err := convert(newPlaybook, &updated); err != nil {
+		return nil, err
+	}
+
+	return append([]ir.PlaybookChange{}, playbook.Diff(old, updated)...), nil
+}
+
+// convert converts a playbook of the client to one of the server, through JSON.
+func convert(pbook *ir.Playbook, out *playbook.Playbook) error {
+	data, err := json.Marshal(pbook)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the playbook")
+	}
+
+	return errors.Wrap(json.Unmarshal(data, out), "failed to decode the playbook")
+}
+
+// changesOf returns the changes to a field.
+func changesOf(changes []ir.PlaybookChange, field string) []ir.PlaybookChange {
+	var of []ir.PlaybookChange
+	for _, change := range changes {
+		switch {
+		case field == "checklists" && (change.Target == playbook.ChangeTargetChecklist || change.Target == playbook.ChangeTargetChecklistItem),
+			field == "propertylist" && change.Target == playbook.ChangeTargetProperty,
+			field == "exit_criteria" && change.Target == playbook.ChangeTargetExitCriteria:
+			of = append(of, change)
+		}
+	}
+	return of
+}
+
+// printPlan describes the changes of a plan.
+func (e *env) printPlan(plan *syncPlan) {
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(e.stdout, "No changes, the playbooks of the team match the files.")
+		return
+	}
+
+	counts := map[string]int{}
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+
+		switch change.Action {
+		case actionCreate:
+			fmt.Fprintf(e.stdout, "+ create %s %q from %s\n", change.ExternalID, change.Title, change.File)
+		case actionUpdate:
+			fmt.Fprintf(e.stdout, "~ update %s %q, playbook %s version %d\n", change.ExternalID, change.Title, change.PlaybookID, change.Version)
+			for _, field := range change.Fields {
+				if len(field.Changes) == 0 {
+					fmt.Fprintf(e.stdout, "    %s: %s -> %s\n", field.Field, shorten(field.Old), shorten(field.New))
+					continue
+				}
+
+				fmt.Fprintf(e.stdout, "    %s:\n", field.Field)
+				for _, c := range field.Changes {
+					fmt.Fprintf(e.stdout, "      %s\n", describeChange(c))
+				}
+			}
+		case actionDelete:
+			fmt.Fprintf(e.stdout, "- delete %s %q, playbook %s version %d\n", change.ExternalID, change.Title, change.PlaybookID, change.Version)
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		counts[actionCreate], counts[actionUpdate], counts[actionDelete])
+}
+
+// describeChange describes a change to the checklists, properties or exit criteria on one line.
+func describeChange(change ir.PlaybookChange) string {
+	symbols := map[playbook.ChangeType]string{
+		playbook.ChangeAdded:    "+",
+		playbook.ChangeRemoved:  "-",
+		playbook.ChangeModified: "~",
+	}
+	targets := map[string]string{
+		playbook.ChangeTargetChecklist:     "checklist",
+		playbook.ChangeTargetChecklistItem: "item",
+		playbook.ChangeTargetProperty:      "property",
+		playbook.ChangeTargetExitCriteria:  "exit criterion",
+	}
+
+	description := fmt.Sprintf("%s %s %q", symbols[change.Type], targets[change.Target], change.Title)
+	if change.Checklist != "" {
+		description += fmt.Sprintf(" in %q", change.Checklist)
+	}
+	if len(change.Details) > 0 {
+		description += ": " + strings.Join(change.Details, ", ")
+	}
+
+	return description
+}
+
+// shorten returns a JSON value on one line, cut if it is long.
+func shorten(value json.RawMessage) string {
+	if len(value) == 0 {
+		return "null"
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return string(value)
+	}
+
+	const maxLength = 60
+	if s := compact.String(); len(s) > maxLength {
+		return s[:maxLength-3] + "..."
+	}
+	return compact.String()
+}
+
+func playbooksSyncApply(e *env, args []string) error {
+	flags := newFlagSet(e, "playbooks sync apply")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("usage: incidentctl playbooks sync apply PLAN_FILE\n\n" +
+			"Use - as PLAN_FILE to read the plan from the standard input.")
+	}
+
+	data, err := e.readInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var plan syncPlan
+	if err = json.Unmarshal(data, &plan); err != nil {
+		return errors.Wrapf(err, "failed to parse the plan in %s", flags.Arg(0))
+	}
+	if plan.TeamID == "" {
+		return errors.Errorf("%s is not a sync plan", flags.Arg(0))
+	}
+
+	// Nothing is applied if a playbook changed since the plan was made: applying the plan would
+	// silently undo the changes, which the plan doesn't show.
+	for _, change := range plan.Changes {
+		if change.Action != actionCreate && change.Action != actionUpdate && change.Action != actionDelete {
+			return errors.Errorf("unknown action '%s' in the plan", change.Action)
+		}
+		if change.Action != actionDelete && change.Playbook == nil {
+			return errors.Errorf("the plan has no playbook to %s %s", change.Action, change.ExternalID)
+		}
+		if change.Action == actionCreate {
+			continue
+		}
+
+		current, err := e.client.Playbooks.Get(e.ctx, change.PlaybookID)
+		if err != nil {
+			return err
+		}
+		if current.Version != change.Version || current.DeleteAt != 0 {
+			return errors.Errorf("playbook %s changed since the plan was made, from version %d to %d: make a new plan",
+				change.ExternalID, change.Version, current.Version)
+		}
+	}
+
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+
+		switch change.Action {
+		case actionCreate:
+			change.Playbook.ID = ""
+			change.Playbook.TeamID = plan.TeamID
+			change.Playbook.ExternalID = change.ExternalID
+			change.PlaybookID, err = e.client.Playbooks.Create(e.ctx, *change.Playbook)
+		case actionUpdate:
+			change.Playbook.ID = change.PlaybookID
+			change.Playbook.TeamID = plan.TeamID
+			change.Playbook.ExternalID = change.ExternalID
+			err = e.client.Playbooks.Update(e.ctx, *change.Playbook)
+		case actionDelete:
+			err = e.client.Playbooks.Delete(e.ctx, change.PlaybookID)
+		}
+
+		if err != nil {
+			fmt.Fprintf(e.stderr, "failed to %s playbook %s, the previous changes were applied\n", change.Action, change.ExternalID)
+			return err
+		}
+
+		if e.output == outputTable {
+			fmt.Fprintf(e.stdout, "%sd %s, playbook %s\n", change.Action, change.ExternalID, change.PlaybookID)
+		}
+	}
+
+	if e.output == outputJSON {
+		return e.printJSON(plan)
+	}
+
+	fmt.Fprintf(e.stdout, "Applied %d changes.\n", len(plan.Changes))
+	return nil
+}
@@ -5,6 +5,7 @@
 Package client provides a client for using the Workflows API.
 
 Usage:
+
 	import ir "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
 
 Construct a new Workflows client, then use the various services on the client to
@@ -25,9 +26,12 @@ Some API methods have optional parameters that can be passed. For example:
 		log.Fatal(err)
 	}
 
-	// list incidents by status
+	// list the active incidents of a team, the newest first
 	list, err := client.Incidents.List(context.Background(), ir.IncidentListOptions{
-		Sort: ir.ByIsActive,
+		TeamID:    teamID,
+		Statuses:  []string{ir.StatusReported, ir.StatusActive},
+		Sort:      ir.CreateAt,
+		Direction: ir.Desc,
 	})
 
 Using the https://godoc.org/context package, one can easily
@@ -35,7 +39,7 @@ pass cancelation signals and deadlines to various services of the client for
 handling a request. In case there is no context available, then context.Background()
 can be used as a starting point.
 
-Authentication
+# Authentication
 
 The workflows client does not directly handle authentication. Instead, when
 creating a new client, pass an http.Client that can handle authentication for
@@ -71,7 +75,7 @@ almost never be shared between different users.
 
 See the oauth2 docs for complete instructions on using that library.
 
-Pagination
+# Pagination
 
 All requests for resource collections (incidents, playbooks, etc.)
 support pagination. Pagination options are described in the
@@ -100,5 +104,53 @@ ir.ListResult struct.
 		}
 	}
 
+Pages can also be retrieved with a cursor, by passing the NextCursor of the
+previous page in ListOptions.Cursor instead of a page number. This is faster on
+large lists, and incidents created while paging do not shift the pages. The
+incidents iterator follows the cursors for you:
+
+	it := client.Incidents.Iterate(ir.IncidentListOptions{
+		TeamID:      teamID,
+		ListOptions: ir.ListOptions{PerPage: 100},
+	})
+	for it.Next(context.Background()) {
+		allIncidents = append(allIncidents, it.Incident())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+Errors
+
+Failed requests return an *ir.ErrorResponse carrying the response and the
+message of the server. The common failures can be checked with errors.Is:
+
+	_, err := client.Incidents.Get(ctx, incidentID)
+	if errors.Is(err, ir.ErrNotFound) {
+		...
+	}
+
+ir.ErrForbidden and ir.ErrConflict match 403 and 409 responses the same way.
+Invalid playbooks are rejected with the validation errors and warnings in the
+Errors and Warnings fields of the *ir.ErrorResponse. Status updates that do not
+meet the exit criteria of an incident fail with an *ir.DialogError.
+
+Retries
+
+Requests that can safely be repeated, such as GET requests, replacing a
+playbook or setting the value of a property, are retried after network errors
+and 429, 502, 503 and 504 responses, waiting longer after each attempt or as
+long as asked by a Retry-After header. Requests that could be applied twice,
+such as creating an incident or adding a checklist item, are never retried.
+The number of retries and the waits are configured with Client.RetryMax,
+Client.RetryWaitMin and Client.RetryWaitMax, and canceling the context stops
+the retries.
+
+Coverage
+
+The client covers every incident and playbook endpoint of the API, except the
+ones only used by the buttons and dialogs of the Mattermost UI: the incident
+creation dialog, the status update reminder buttons and telemetry.
+
 */
 package client
@@ -7,34 +7,191 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	"github.com/mattermost/mattermost-server/v5/model"
-	"github.com/pkg/errors"
+)
+
+// The statuses of an incident.
+const (
+	StatusReported = "Reported"
+	StatusActive   = "Active"
+	StatusResolved = "Resolved"
+	StatusArchived = "Archived"
 )
 
 // Incident represents an incident.
 type Incident struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	IsActive        bool   `json:"is_active"`
-	CommanderUserID string `json:"commander_user_id"`
-	TeamID          string `json:"team_id"`
-	ChannelID       string `json:"channel_id"`
-	CreateAt        int64  `json:"create_at"`
-	EndAt           int64  `json:"end_at"`
+	ID                      string            `json:"id"`
+	Name                    string            `json:"name"`
+	Description             string            `json:"description"`
+	CommanderUserID         string            `json:"commander_user_id"`
+	TeamID                  string            `json:"team_id"`
+	ChannelID               string            `json:"channel_id"`
+	CreateAt                int64             `json:"create_at"`
+	EndAt                   int64             `json:"end_at"`
+	DeleteAt                int64             `json:"delete_at"`
+	PostID                  string            `json:"post_id"`
+	PlaybookID              string            `json:"playbook_id"`
+	PlaybookVersion         int64             `json:"playbook_version"`
+	Checklists              []Checklist       `json:"checklists"`
+	Propertylist            Propertylist      `json:"propertylist"`
+	ExitCriteria            ExitCriteria      `json:"exit_criteria"`
+	StatusPosts             []StatusPost      `json:"status_posts"`
+	ReminderPostID          string            `json:"reminder_post_id"`
+	PreviousReminder        time.Duration     `json:"previous_reminder"`
+	BroadcastChannelID      string            `json:"broadcast_channel_id"`
+	ReminderMessageTemplate string            `json:"reminder_message_template"`
+	TimelineEvents          []TimelineEvent   `json:"timeline_events"`
+	CommanderHandoff        *CommanderHandoff `json:"commander_handoff,omitempty"`
+	Version                 int64             `json:"version"`
+
+	// Team is only set when listing the incidents of several teams at once.
+	Team *Team `json:"team,omitempty"`
+
+	// Summary is only set when listing incidents with SummaryFields, in place of the
+	// checklists, properties, status posts and timeline events.
+	Summary *IncidentSummary `json:"summary,omitempty"`
+}
+
+// IncidentSummary is what list views show of an incident besides its name, commander and
+// dates.
+type IncidentSummary struct {
+	Status string `json:"status"`
+
+	// TasksTotal counts the checklist items of the incident, and TasksDone those closed or
+	// skipped.
+	TasksTotal int `json:"tasks_total"`
+	TasksDone  int `json:"tasks_done"`
+}
+
+// CurrentStatus returns the status of the latest status update of the incident, or
+// StatusReported if there is none.
+func (i *Incident) CurrentStatus() string {
+	if i.Summary != nil && len(i.StatusPosts) == 0 {
+		return i.Summary.Status
+	}
+
+	var newest *StatusPost
+	for j, p := range i.StatusPosts {
+		if p.DeleteAt == 0 && (newest == nil || p.CreateAt > newest.CreateAt) {
+			newest = &i.StatusPosts[j]
+		}
+	}
+
+	switch {
+	case newest == nil:
+		return StatusReported
+	case newest.Status != "":
+		return newest.Status
+	case i.EndAt != 0:
+		// Incidents from before the statuses were introduced.
+		return StatusResolved
+	default:
+		return StatusActive
+	}
+}
+
+// IsActive returns true if the incident is neither resolved nor archived.
+func (i *Incident) IsActive() bool {
+	status := i.CurrentStatus()
+	return status != StatusResolved && status != StatusArchived
+}
+
+// StatusPost is a status update posted in an incident.
+type StatusPost struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	CreateAt int64  `json:"create_at"`
+	DeleteAt int64  `json:"delete_at"`
+}
+
+// TimelineEvent is an event in the timeline of an incident.
+type TimelineEvent struct {
+	ID            string `json:"id"`
+	IncidentID    string `json:"incident_id"`
+	CreateAt      int64  `json:"create_at"`
+	DeleteAt      int64  `json:"delete_at"`
+	EventAt       int64  `json:"event_at"`
+	EventType     string `json:"event_type"`
+	Summary       string `json:"summary"`
+	Details       string `json:"details"`
+	PostID        string `json:"post_id"`
+	SubjectUserID string `json:"subject_user_id"`
+	CreatorUserID string `json:"creator_user_id"`
+}
+
+// CommanderHandoff is a pending request of the commander to hand command over to another user.
+type CommanderHandoff struct {
+	FromUserID  string `json:"from_user_id"`
+	ToUserID    string `json:"to_user_id"`
+	Notes       string `json:"notes"`
+	RequestedAt int64  `json:"requested_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+	PostID      string `json:"post_id"`
+}
+
+// Team identifies the team an incident belongs to.
+type Team struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// IncidentMetadata tracks ancillary metadata about an incident.
+type IncidentMetadata struct {
+	ChannelName        string `json:"channel_name"`
+	ChannelDisplayName string `json:"channel_display_name"`
+	TeamName           string `json:"team_name"`
+	NumMembers         int64  `json:"num_members"`
+	TotalPosts         int64  `json:"total_posts"`
+}
+
+// Commander is a user commanding some incidents.
+type Commander struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
 }
 
 // IncidentCreateOptions specifies the parameters for IncidentsService.Create method.
 type IncidentCreateOptions struct {
-	Name            string `json:"name"`
-	CommanderUserID string `json:"commander_user_id"`
-	TeamID          string `json:"team_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	TeamID      string `json:"team_id"`
+
+	// CommanderUserID defaults to whoever is on call for the playbook, if any.
+	CommanderUserID string `json:"commander_user_id,omitempty"`
+
+	// PlaybookID is the playbook the incident is started from, if any.
+	PlaybookID string `json:"playbook_id,omitempty"`
+
+	// PostID is the post the incident is started from, if any.
+	PostID string `json:"post_id,omitempty"`
 }
 
 // IncidentUpdateOptions specifies the parameters for IncidentsService.Update method.
 type IncidentUpdateOptions struct {
-	CommanderUserID *string `json:"commander_user_id"`
+	CommanderUserID *string `json:"-"`
+}
+
+// StatusUpdateOptions specifies the parameters for IncidentsService.UpdateStatus method.
+type StatusUpdateOptions struct {
+	// Status is one of StatusReported, StatusActive, StatusResolved or StatusArchived.
+	Status  string
+	Message string
+
+	// Reminder is when to remind the commander to post the next status update. Zero disables
+	// the reminder.
+	Reminder time.Duration
+
+	// OverrideExitCriteria resolves the incident even if its exit criteria are not met. Only the
+	// commander and system admins can override the exit criteria.
+	OverrideExitCriteria bool
 }
 
 // IncidentListOptions specifies the optional parameters to the
@@ -42,10 +199,62 @@ type IncidentUpdateOptions struct {
 type IncidentListOptions struct {
 	ListOptions
 
-	TeamID string `url:"team_id,omitempty"`
+	// TeamID and TeamIDs restrict the list to the given teams. When both are empty, the
+	// incidents of all the teams the user can view are listed.
+	TeamID  string   `url:"team_id,omitempty"`
+	TeamIDs []string `url:"team_id,omitempty"`
 
 	Sort      IncidentSort  `url:"sort,omitempty"`
 	Direction SortDirection `url:"direction,omitempty"`
+
+	// SortProperty is the title of the property to sort by when Sort is Property.
+	SortProperty string `url:"sort_property,omitempty"`
+
+	// Statuses filters by any of the given statuses.
+	Statuses []string `url:"status,omitempty"`
+
+	PlaybookID      string `url:"playbook_id,omitempty"`
+	CommanderUserID string `url:"commander_user_id,omitempty"`
+
+	// MemberID restricts the list to the incidents whose channel the user is a member of.
+	MemberID string `url:"member_id,omitempty"`
+
+	// SearchTerm filters by name.
+	SearchTerm string `url:"search_term,omitempty"`
+
+	// CreatedAfter, CreatedBefore, EndedAfter and EndedBefore are inclusive bounds in
+	// milliseconds since the epoch.
+	CreatedAfter  int64 `url:"created_after,omitempty"`
+	CreatedBefore int64 `url:"created_before,omitempty"`
+	EndedAfter    int64 `url:"ended_after,omitempty"`
+	EndedBefore   int64 `url:"ended_before,omitempty"`
+
+	// PropertyValues filters by property values.
+	PropertyValues PropertyValues `url:"property,omitempty"`
+
+	// Fields selects what is returned of each incident: everything by default.
+	Fields IncidentFields `url:"fields,omitempty"`
+}
+
+// IncidentFields enumerates the selections of fields returned when listing incidents.
+type IncidentFields string
+
+// SummaryFields returns only what list views show of each incident, leaving out the
+// checklists, properties, status posts and timeline events, and sets the Summary.
+const SummaryFields IncidentFields = "summary"
+
+// PropertyValues maps property titles to the values to filter by: an incident matches a
+// title if its property has any of the values.
+type PropertyValues map[string][]string
+
+// EncodeValues encodes the property values as property[Title]=Value query parameters.
+func (p PropertyValues) EncodeValues(key string, v *url.Values) error {
+	for title, values := range p {
+		for _, value := range values {
+			v.Add(fmt.Sprintf("%s[%s]", key, title), value)
+		}
+	}
+	return nil
 }
 
 // IncidentSort enumerates the available fields we can sort on.
@@ -69,6 +278,12 @@ const (
 
 	// EndAt sorts by the "end_at" field.
 	EndAt IncidentSort = "end_at"
+
+	// Status sorts by the "status" field.
+	Status IncidentSort = "status"
+
+	// Property sorts by the value of the property named by IncidentListOptions.SortProperty.
+	Property IncidentSort = "property"
 )
 
 // IncidentList contains the paginated result.
@@ -77,6 +292,57 @@ type IncidentList struct {
 	Items []*Incident
 }
 
+// IncidentSearchOptions specifies the parameters to the IncidentsService.Search method.
+type IncidentSearchOptions struct {
+	ListOptions
+
+	// TeamID is the team to search in. Required.
+	TeamID string `url:"team_id"`
+
+	// Terms are the words to look for.
+	Terms string `url:"terms"`
+}
+
+// IncidentSearchResults is a page of search results, sorted by decreasing relevance.
+type IncidentSearchResults struct {
+	HasMore bool                    `json:"has_more"`
+	Items   []*IncidentSearchResult `json:"items"`
+}
+
+// IncidentSearchResult is an incident matched by a search.
+type IncidentSearchResult struct {
+	Incident Incident  `json:"incident"`
+	Score    float64   `json:"score"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Snippet is an excerpt of a field of an incident containing some of the search terms.
+type Snippet struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+	HTML  string `json:"html"`
+}
+
+// DialogError reports the errors of the fields of a dialog submitted by the client, such as the
+// unmet exit criteria of an incident being resolved.
+type DialogError struct {
+	Errors map[string]string
+}
+
+func (e *DialogError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, e.Errors[field]))
+	}
+	return strings.Join(messages, "; ")
+}
+
 // IncidentsService handles communication with the incident related
 // methods of the workflows API.
 type IncidentsService struct {
@@ -85,17 +351,7 @@ type IncidentsService struct {
 
 // Create an incident.
 func (s *IncidentsService) Create(ctx context.Context, opts IncidentCreateOptions) (*Incident, error) {
-	// Just a proof of concept, this will be switched over to POST /incidents
-	// TODO: switch to using /incidents
-	u := "incidents/create-dialog"
-	dialogRequest := model.SubmitDialogRequest{
-		TeamId:     opts.TeamID,
-		UserId:     opts.CommanderUserID,
-		State:      "{}",
-		Submission: map[string]interface{}{incident.DialogFieldNameKey: opts.Name},
-	}
-
-	req, err := s.client.NewRequest(http.MethodPost, u, dialogRequest)
+	req, err := s.client.NewRequest(http.MethodPost, "incidents", opts)
 	if err != nil {
 		return nil, err
 	}
@@ -130,12 +386,168 @@ func (s *IncidentsService) Get(ctx context.Context, incidentID string) (*Inciden
 
 // GetByChannelID gets an incident by ChannelID.
 func (s *IncidentsService) GetByChannelID(ctx context.Context, channelID string) (*Incident, error) {
-	return nil, errors.New("not implemented")
+	u := fmt.Sprintf("incidents/channel/%s", channelID)
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	i := new(Incident)
+	resp, err := s.client.Do(ctx, req, i)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return i, nil
+}
+
+// GetMetadata gets the metadata of an incident.
+func (s *IncidentsService) GetMetadata(ctx context.Context, incidentID string) (*IncidentMetadata, error) {
+	u := fmt.Sprintf("incidents/%s/metadata", incidentID)
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := new(IncidentMetadata)
+	resp, err := s.client.Do(ctx, req, metadata)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return metadata, nil
 }
 
 // Update an incident.
 func (s *IncidentsService) Update(ctx context.Context, incidentID string, opts IncidentUpdateOptions) (*Incident, error) {
-	return nil, errors.New("not implemented")
+	if opts.CommanderUserID != nil {
+		if err := s.ChangeCommander(ctx, incidentID, *opts.CommanderUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	u := fmt.Sprintf("incidents/%s", incidentID)
+	req, err := s.client.NewRequest(http.MethodPatch, u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	i := new(Incident)
+	resp, err := s.client.Do(ctx, req, i)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return i, nil
+}
+
+// UpdateStatus posts a status update in an incident, changing its status.
+func (s *IncidentsService) UpdateStatus(ctx context.Context, incidentID string, opts StatusUpdateOptions) error {
+	u := fmt.Sprintf("incidents/%s/update-status-dialog", incidentID)
+	dialogRequest := model.SubmitDialogRequest{
+		Submission: map[string]interface{}{
+			incident.DialogFieldStatusKey:               opts.Status,
+			incident.DialogFieldMessageKey:              opts.Message,
+			incident.DialogFieldReminderInSecondsKey:    strconv.Itoa(int(opts.Reminder.Seconds())),
+			incident.DialogFieldOverrideExitCriteriaKey: opts.OverrideExitCriteria,
+		},
+	}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, dialogRequest)
+	if err != nil {
+		return err
+	}
+
+	dialogResponse := new(model.SubmitDialogResponse)
+	resp, err := s.client.Do(ctx, req, dialogResponse)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if len(dialogResponse.Errors) > 0 {
+		return &DialogError{Errors: dialogResponse.Errors}
+	}
+
+	return nil
+}
+
+// End resolves an incident with the given message.
+func (s *IncidentsService) End(ctx context.Context, incidentID, message string) error {
+	return s.UpdateStatus(ctx, incidentID, StatusUpdateOptions{Status: StatusResolved, Message: message})
+}
+
+// ChangeCommander makes another user the commander of an incident, immediately.
+func (s *IncidentsService) ChangeCommander(ctx context.Context, incidentID, commanderID string) error {
+	u := fmt.Sprintf("incidents/%s/commander", incidentID)
+	body := struct {
+		CommanderID string `json:"commander_id"`
+	}{commanderID}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// RequestCommanderHandoff asks another user to take command of an incident, with handover notes.
+// Only the commander can request a handoff.
+func (s *IncidentsService) RequestCommanderHandoff(ctx context.Context, incidentID, commanderID, notes string) error {
+	u := fmt.Sprintf("incidents/%s/commander/handoff", incidentID)
+	body := struct {
+		CommanderID string `json:"commander_id"`
+		Notes       string `json:"notes"`
+	}{commanderID, notes}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// AcceptCommanderHandoff takes command of an incident, as asked by its commander.
+func (s *IncidentsService) AcceptCommanderHandoff(ctx context.Context, incidentID string) error {
+	return s.answerCommanderHandoff(ctx, incidentID, "accept")
+}
+
+// DeclineCommanderHandoff refuses to take command of an incident, as asked by its commander.
+func (s *IncidentsService) DeclineCommanderHandoff(ctx context.Context, incidentID string) error {
+	return s.answerCommanderHandoff(ctx, incidentID, "decline")
+}
+
+func (s *IncidentsService) answerCommanderHandoff(ctx context.Context, incidentID, answer string) error {
+	u := fmt.Sprintf("incidents/%s/commander/handoff/%s", incidentID, answer)
+	req, err := s.client.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
 // List the incidents.
@@ -161,12 +573,208 @@ func (s *IncidentsService) List(ctx context.Context, opts IncidentListOptions) (
 	return result, nil
 }
 
-// Delete an incident.
-func (s *IncidentsService) Delete(ctx context.Context, incidentID string) (*Incident, error) {
-	return nil, errors.New("not implemented")
+// ListCommanders lists the commanders of the incidents matching opts. The pagination options are
+// ignored.
+func (s *IncidentsService) ListCommanders(ctx context.Context, opts IncidentListOptions) ([]Commander, error) {
+	u, err := addOptions("incidents/commanders", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var commanders []Commander
+	resp, err := s.client.Do(ctx, req, &commanders)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return commanders, nil
+}
+
+// ListChannels lists the IDs of the channels of the incidents matching opts.
+func (s *IncidentsService) ListChannels(ctx context.Context, opts IncidentListOptions) ([]string, error) {
+	u, err := addOptions("incidents/channels", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var channelIDs []string
+	resp, err := s.client.Do(ctx, req, &channelIDs)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return channelIDs, nil
+}
+
+// Search the incidents of a team, sorted by decreasing relevance.
+func (s *IncidentsService) Search(ctx context.Context, opts IncidentSearchOptions) (*IncidentSearchResults, error) {
+	u, err := addOptions("incidents/search", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := new(IncidentSearchResults)
+	resp, err := s.client.Do(ctx, req, results)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return results, nil
+}
+
+// Iterate returns an iterator over all the incidents matching opts, starting at opts.Page and
+// fetching the following pages with a cursor as needed.
+func (s *IncidentsService) Iterate(opts IncidentListOptions) *IncidentIterator {
+	return &IncidentIterator{service: s, opts: opts}
+}
+
+// IncidentIterator iterates over the incidents of a list. For example:
+//
+//	it := client.Incidents.Iterate(ir.IncidentListOptions{TeamID: teamID})
+//	for it.Next(ctx) {
+//		incident := it.Incident()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type IncidentIterator struct {
+	service *IncidentsService
+	opts    IncidentListOptions
+
+	items    []*Incident
+	current  *Incident
+	fetched  bool
+	lastPage bool
+	err      error
+}
+
+// Next advances the iterator to the next incident, fetching the next page if needed. It returns
+// false when there are no more incidents, or when an error occurred.
+func (it *IncidentIterator) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.err != nil || it.lastPage {
+			it.current = nil
+			return false
+		}
+
+		if it.fetched {
+			// The cursor replaces the page once the first page was fetched.
+			it.opts.Page = 0
+		}
+
+		list, err := it.service.List(ctx, it.opts)
+		if err != nil {
+			it.err = err
+			continue
+		}
+
+		it.fetched = true
+		it.items = list.Items
+		it.opts.Cursor = list.NextCursor
+		it.lastPage = !list.HasMore || list.NextCursor == ""
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+// Incident returns the current incident, after a call to Next returned true.
+func (it *IncidentIterator) Incident() *Incident {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *IncidentIterator) Err() error {
+	return it.err
 }
 
-// End an incident.
-func (s *IncidentsService) End(ctx context.Context, incidentID string) (*Incident, error) {
-	return nil, errors.New("not implemented")
+// GetPlaybookDiff previews the changes that UpdateSourcePlaybook would make to the playbook the
+// incident was started from.
+func (s *IncidentsService) GetPlaybookDiff(ctx context.Context, incidentID string) (*PlaybookDiff, error) {
+	u := fmt.Sprintf("incidents/%s/playbook-diff", incidentID)
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := new(PlaybookDiff)
+	resp, err := s.client.Do(ctx, req, diff)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return diff, nil
+}
+
+// UpdateSourcePlaybook saves the checklists and properties of an incident into the playbook it was
+// started from. A non-zero version must be the one previewed with GetPlaybookDiff, otherwise the
+// error matches ErrConflict if the playbook was changed since.
+func (s *IncidentsService) UpdateSourcePlaybook(ctx context.Context, incidentID string, version int64) ([]PlaybookChange, error) {
+	u := fmt.Sprintf("incidents/%s/playbook", incidentID)
+	body := struct {
+		Version int64 `json:"version"`
+	}{version}
+
+	req, err := s.client.NewRequest(http.MethodPut, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Changes []PlaybookChange `json:"changes"`
+	}
+	resp, err := s.client.Do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result.Changes, nil
+}
+
+// SaveAsPlaybook creates a playbook from the checklists and properties of an incident, returning
+// its ID. The team defaults to the one of the incident, and the title to its name.
+func (s *IncidentsService) SaveAsPlaybook(ctx context.Context, incidentID, teamID, title string) (string, error) {
+	u := fmt.Sprintf("incidents/%s/playbook", incidentID)
+	body := struct {
+		TeamID string `json:"team_id,omitempty"`
+		Title  string `json:"title,omitempty"`
+	}{teamID, title}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	resp, err := s.client.Do(ctx, req, &result)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return result.ID, nil
 }
@@ -5,53 +5,78 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+)
 
-	"github.com/pkg/errors"
+// The states of a checklist item.
+const (
+	ChecklistItemStateOpen       = ""
+	ChecklistItemStateInProgress = "in_progress"
+	ChecklistItemStateClosed     = "closed"
+	ChecklistItemStateSkipped    = "skipped"
 )
 
-// ChecklistItemCreateOptions specifies the parameters for IncidentsService.CreateChecklistItem method.
-type ChecklistItemCreateOptions struct {
-	Title string `json:"title"`
-}
+// RenameChecklistItem changes the title and the slash command of a checklist item.
+func (s *IncidentsService) RenameChecklistItem(ctx context.Context, incidentID string, checklistNum, itemNum int, title, command string) error {
+	body := struct {
+		Title   string `json:"title"`
+		Command string `json:"command"`
+	}{title, command}
 
-// ChecklistItemUpdateOptions specifies the parameters for IncidentsService.UpdateChecklistItem method.
-type ChecklistItemUpdateOptions struct {
-	Title *string `json:"title"`
+	return s.doChecklistItem(ctx, http.MethodPut, incidentID, checklistNum, itemNum, "", body, nil)
 }
 
-// ChecklistItemListOptions specifies the optional parameters to the
-// IncidentsService.ListChecklistItems method.
-type ChecklistItemListOptions struct {
-	ListOptions
-}
+// SetChecklistItemState sets the state of a checklist item to one of the ChecklistItemState
+// constants.
+func (s *IncidentsService) SetChecklistItemState(ctx context.Context, incidentID string, checklistNum, itemNum int, newState string) error {
+	body := struct {
+		NewState string `json:"new_state"`
+	}{newState}
 
-// ChecklistItemList contains the paginated result.
-type ChecklistItemList struct {
-	ListResult
-	Items []*ChecklistItem
+	return s.doChecklistItem(ctx, http.MethodPut, incidentID, checklistNum, itemNum, "/state", body, nil)
 }
 
-// CreateChecklistItem creates a checklist item for this incident.
-func (s *IncidentsService) CreateChecklistItem(ctx context.Context, incidentID, checklistIndex string, opts ChecklistCreateOptions) (*ChecklistItem, error) {
-	return nil, errors.New("not implemented")
-}
+// SetChecklistItemAssignee assigns a checklist item to a user, or unassigns it if assigneeID is
+// empty.
+func (s *IncidentsService) SetChecklistItemAssignee(ctx context.Context, incidentID string, checklistNum, itemNum int, assigneeID string) error {
+	body := struct {
+		AssigneeID string `json:"assignee_id"`
+	}{assigneeID}
 
-// GetChecklistItem gets a checklist item for this incident.
-func (s *IncidentsService) GetChecklistItem(ctx context.Context, incidentID, checklistIndex, checklistItemIndex string) (*ChecklistItem, error) {
-	return nil, errors.New("not implemented")
+	return s.doChecklistItem(ctx, http.MethodPut, incidentID, checklistNum, itemNum, "/assignee", body, nil)
 }
 
-// UpdateChecklistItem updates a checklist item for this incident.
-func (s *IncidentsService) UpdateChecklistItem(ctx context.Context, incidentID, checklistIndex, checklistItemIndex string, opts ChecklistUpdateOptions) (*ChecklistItem, error) {
-	return nil, errors.New("not implemented")
+// RunChecklistItemCommand runs the slash command of a checklist item, returning the trigger ID
+// of the command.
+func (s *IncidentsService) RunChecklistItemCommand(ctx context.Context, incidentID string, checklistNum, itemNum int) (string, error) {
+	var result struct {
+		TriggerID string `json:"trigger_id"`
+	}
+	if err := s.doChecklistItem(ctx, http.MethodPost, incidentID, checklistNum, itemNum, "/run", nil, &result); err != nil {
+		return "", err
+	}
+
+	return result.TriggerID, nil
 }
 
-// ListChecklistItems returns checklist items for this incident.
-func (s *IncidentsService) ListChecklistItems(ctx context.Context, incidentID, checklistIndex string, opts ChecklistListOptions) (*ChecklistItemList, error) {
-	return nil, errors.New("not implemented")
+// RemoveChecklistItem removes an item from a checklist. The following items move up by one.
+func (s *IncidentsService) RemoveChecklistItem(ctx context.Context, incidentID string, checklistNum, itemNum int) error {
+	return s.doChecklistItem(ctx, http.MethodDelete, incidentID, checklistNum, itemNum, "", nil, nil)
 }
 
-// DeleteChecklistItem deletes a checklist item for this incident.
-func (s *IncidentsService) DeleteChecklistItem(ctx context.Context, incidentID, checklistIndex, checklistItemIndex string) (*ChecklistItem, error) {
-	return nil, errors.New("not implemented")
+func (s *IncidentsService) doChecklistItem(ctx context.Context, method, incidentID string, checklistNum, itemNum int, suffix string, body, v interface{}) error {
+	u := fmt.Sprintf("incidents/%s/checklists/%d/item/%d%s", incidentID, checklistNum, itemNum, suffix)
+	req, err := s.client.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, v)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
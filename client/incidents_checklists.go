@@ -5,53 +5,144 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
-	"github.com/pkg/errors"
+	"github.com/mattermost/mattermost-server/v5/model"
 )
 
-// ChecklistCreateOptions specifies the parameters for IncidentsService.CreateChecklist method.
-type ChecklistCreateOptions struct {
-	Title string `json:"title"`
-}
+// ChecklistOperationType is the kind of a ChecklistOperation.
+type ChecklistOperationType string
 
-// ChecklistUpdateOptions specifies the parameters for IncidentsService.UpdateChecklist method.
-type ChecklistUpdateOptions struct {
-	Title *string `json:"title"`
-}
+// The kinds of operations on checklist items.
+const (
+	// ChecklistOperationSetState sets the state of an item to NewState.
+	ChecklistOperationSetState ChecklistOperationType = "set_state"
+
+	// ChecklistOperationSetAssignee assigns an item to AssigneeID, or unassigns it if empty.
+	ChecklistOperationSetAssignee ChecklistOperationType = "set_assignee"
+
+	// ChecklistOperationRename changes the Title and Command of an item.
+	ChecklistOperationRename ChecklistOperationType = "rename"
+
+	// ChecklistOperationMove moves an item to NewLocation in its checklist.
+	ChecklistOperationMove ChecklistOperationType = "move"
 
-// ChecklistListOptions specifies the optional parameters to the
-// IncidentsService.ListChecklists method.
-type ChecklistListOptions struct {
-	ListOptions
+	// ChecklistOperationAdd adds ChecklistItem at the end of a checklist.
+	ChecklistOperationAdd ChecklistOperationType = "add"
+
+	// ChecklistOperationRemove removes an item.
+	ChecklistOperationRemove ChecklistOperationType = "remove"
+)
+
+// ChecklistOperation is an operation on a checklist item, applied with
+// IncidentsService.BatchChecklistOperations. Checklist and Item are the 0 based indexes of the
+// checklist and of the item in the checklist, before any operation of the batch is applied.
+type ChecklistOperation struct {
+	Type          ChecklistOperationType `json:"type"`
+	Checklist     int                    `json:"checklist"`
+	Item          int                    `json:"item"`
+	NewState      string                 `json:"new_state,omitempty"`
+	AssigneeID    string                 `json:"assignee_id,omitempty"`
+	Title         string                 `json:"title,omitempty"`
+	Command       string                 `json:"command,omitempty"`
+	NewLocation   int                    `json:"new_location,omitempty"`
+	ChecklistItem *ChecklistItem         `json:"checklist_item,omitempty"`
 }
 
-// ChecklistList contains the paginated result.
-type ChecklistList struct {
-	ListResult
-	Items []*Checklist
+// BatchChecklistOperations applies several operations to the checklists of an incident at once.
+// If any operation is invalid, none is applied.
+func (s *IncidentsService) BatchChecklistOperations(ctx context.Context, incidentID string, operations []ChecklistOperation) error {
+	u := fmt.Sprintf("incidents/%s/checklists/batch", incidentID)
+	body := struct {
+		Operations []ChecklistOperation `json:"operations"`
+	}{operations}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
-// CreateChecklist creates a checklist for this incident.
-func (s *IncidentsService) CreateChecklist(ctx context.Context, incidentID string, opts ChecklistCreateOptions) (*Checklist, error) {
-	return nil, errors.New("not implemented")
+// AddChecklistItem adds an item at the end of a checklist of an incident.
+func (s *IncidentsService) AddChecklistItem(ctx context.Context, incidentID string, checklistNum int, item ChecklistItem) error {
+	u := fmt.Sprintf("incidents/%s/checklists/%d/add", incidentID, checklistNum)
+	req, err := s.client.NewRequest(http.MethodPut, u, item)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
-// GetChecklist gets a checklist for this incident.
-func (s *IncidentsService) GetChecklist(ctx context.Context, incidentID, checklistIndex string) (*Checklist, error) {
-	return nil, errors.New("not implemented")
+// MoveChecklistItem moves an item of a checklist of an incident to newLocation in the same
+// checklist.
+func (s *IncidentsService) MoveChecklistItem(ctx context.Context, incidentID string, checklistNum, itemNum, newLocation int) error {
+	u := fmt.Sprintf("incidents/%s/checklists/%d/reorder", incidentID, checklistNum)
+	body := struct {
+		ItemNum     int `json:"item_num"`
+		NewLocation int `json:"new_location"`
+	}{itemNum, newLocation}
+
+	req, err := s.client.NewRequest(http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
-// UpdateChecklist updates a checklist for this incident.
-func (s *IncidentsService) UpdateChecklist(ctx context.Context, incidentID, checklistIndex string, opts ChecklistUpdateOptions) (*Checklist, error) {
-	return nil, errors.New("not implemented")
+// ChecklistAutocomplete lists the checklists of the incident of a channel, in the format of the
+// slash command autocompletion.
+func (s *IncidentsService) ChecklistAutocomplete(ctx context.Context, channelID string) ([]model.AutocompleteListItem, error) {
+	return s.autocomplete(ctx, "incidents/checklists-autocomplete", channelID)
 }
 
-// ListChecklists returns checklists for this incident.
-func (s *IncidentsService) ListChecklists(ctx context.Context, incidentID string, opts ChecklistListOptions) (*ChecklistList, error) {
-	return nil, errors.New("not implemented")
+// ChecklistItemAutocomplete lists the checklist items of the incident of a channel, in the format
+// of the slash command autocompletion.
+func (s *IncidentsService) ChecklistItemAutocomplete(ctx context.Context, channelID string) ([]model.AutocompleteListItem, error) {
+	return s.autocomplete(ctx, "incidents/checklist-autocomplete", channelID)
 }
 
-// DeleteChecklist deletes a checklist for this incident.
-func (s *IncidentsService) DeleteChecklist(ctx context.Context, incidentID, checklistIndex string) (*Checklist, error) {
-	return nil, errors.New("not implemented")
+func (s *IncidentsService) autocomplete(ctx context.Context, path, channelID string) ([]model.AutocompleteListItem, error) {
+	u, err := addOptions(path, struct {
+		ChannelID string `url:"channel_id"`
+	}{channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []model.AutocompleteListItem
+	resp, err := s.client.Do(ctx, req, &items)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return items, nil
 }
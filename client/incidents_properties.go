@@ -0,0 +1,202 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// SetPropertySelectionValue selects the options with the given IDs of a selection property of an
+// incident, replacing the previous selection.
+func (s *IncidentsService) SetPropertySelectionValue(ctx context.Context, incidentID, propertyID string, selectionIDs []string) error {
+	body := struct {
+		PropertyID   string   `json:"property_id"`
+		SelectionIDs []string `json:"selection_ids"`
+	}{propertyID, selectionIDs}
+
+	return s.setPropertyValue(ctx, incidentID, "selection", body)
+}
+
+// SetPropertyFreetextValue sets the value of a single value freetext property of an incident.
+func (s *IncidentsService) SetPropertyFreetextValue(ctx context.Context, incidentID, propertyID, value string) error {
+	body := struct {
+		PropertyID string `json:"property_id"`
+		Value      string `json:"value"`
+	}{propertyID, value}
+
+	return s.setPropertyValue(ctx, incidentID, "freetext", body)
+}
+
+// SetPropertyFreetextValues sets the values of a multiselect freetext property of an incident.
+func (s *IncidentsService) SetPropertyFreetextValues(ctx context.Context, incidentID, propertyID string, values []string) error {
+	body := struct {
+		PropertyID string   `json:"property_id"`
+		Values     []string `json:"values"`
+	}{propertyID, values}
+
+	return s.setPropertyValue(ctx, incidentID, "freetext", body)
+}
+
+// SetPropertyNumberValue sets the value of a number property of an incident, or clears it if
+// value is nil.
+func (s *IncidentsService) SetPropertyNumberValue(ctx context.Context, incidentID, propertyID string, value *float64) error {
+	body := struct {
+		PropertyID string   `json:"property_id"`
+		Value      *float64 `json:"value"`
+	}{propertyID, value}
+
+	return s.setPropertyValue(ctx, incidentID, "number", body)
+}
+
+// SetPropertyDatetimeValue sets the value of a date and time property of an incident, or clears
+// it if value is the zero time.
+func (s *IncidentsService) SetPropertyDatetimeValue(ctx context.Context, incidentID, propertyID string, value time.Time) error {
+	var millis int64
+	if !value.IsZero() {
+		millis = model.GetMillisForTime(value)
+	}
+
+	body := struct {
+		PropertyID string `json:"property_id"`
+		Value      int64  `json:"value"`
+	}{propertyID, millis}
+
+	return s.setPropertyValue(ctx, incidentID, "datetime", body)
+}
+
+// SetPropertyUserValue sets the users of a user property of an incident.
+func (s *IncidentsService) SetPropertyUserValue(ctx context.Context, incidentID, propertyID string, userIDs []string) error {
+	body := struct {
+		PropertyID string   `json:"property_id"`
+		UserIDs    []string `json:"user_ids"`
+	}{propertyID, userIDs}
+
+	return s.setPropertyValue(ctx, incidentID, "user", body)
+}
+
+// SetPropertyChannelValue sets the channel of a channel property of an incident, or clears it if
+// channelID is empty.
+func (s *IncidentsService) SetPropertyChannelValue(ctx context.Context, incidentID, propertyID, channelID string) error {
+	body := struct {
+		PropertyID string `json:"property_id"`
+		ChannelID  string `json:"channel_id"`
+	}{propertyID, channelID}
+
+	return s.setPropertyValue(ctx, incidentID, "channel", body)
+}
+
+// SetPropertyURLValue sets the value of a URL property of an incident, or clears it if value is
+// empty.
+func (s *IncidentsService) SetPropertyURLValue(ctx context.Context, incidentID, propertyID, value string) error {
+	body := struct {
+		PropertyID string `json:"property_id"`
+		Value      string `json:"value"`
+	}{propertyID, value}
+
+	return s.setPropertyValue(ctx, incidentID, "url", body)
+}
+
+// setPropertyValue replaces the value of a property. Setting the same value twice has no further
+// effect, so the request is retried.
+func (s *IncidentsService) setPropertyValue(ctx context.Context, incidentID, propertyType string, body interface{}) error {
+	u := fmt.Sprintf("incidents/%s/property-%s-value", incidentID, propertyType)
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.doIdempotent(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// AddProperty adds a property at the end of the properties of an incident.
+func (s *IncidentsService) AddProperty(ctx context.Context, incidentID string, property PropertylistItem) error {
+	u := fmt.Sprintf("incidents/%s/propertylist/add", incidentID)
+	req, err := s.client.NewRequest(http.MethodPut, u, property)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// UpdateProperty replaces the property at index itemNum in the properties of an incident.
+func (s *IncidentsService) UpdateProperty(ctx context.Context, incidentID string, itemNum int, property PropertylistItem) error {
+	u := fmt.Sprintf("incidents/%s/propertylist/%d", incidentID, itemNum)
+	req, err := s.client.NewRequest(http.MethodPut, u, property)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.doIdempotent(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// MoveProperty moves the property at index itemNum in the properties of an incident to
+// newLocation.
+func (s *IncidentsService) MoveProperty(ctx context.Context, incidentID string, itemNum, newLocation int) error {
+	u := fmt.Sprintf("incidents/%s/propertylist/reorder", incidentID)
+	body := struct {
+		ItemNum     int `json:"item_num"`
+		NewLocation int `json:"new_location"`
+	}{itemNum, newLocation}
+
+	req, err := s.client.NewRequest(http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// RemoveProperty removes the property at index itemNum from the properties of an incident. The
+// following properties move up by one.
+func (s *IncidentsService) RemoveProperty(ctx context.Context, incidentID string, itemNum int) error {
+	u := fmt.Sprintf("incidents/%s/propertylist/%d", incidentID, itemNum)
+	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// PropertyAutocomplete lists the properties of the incident of a channel, in the format of the
+// slash command autocompletion.
+func (s *IncidentsService) PropertyAutocomplete(ctx context.Context, channelID string) ([]model.AutocompleteListItem, error) {
+	return s.autocomplete(ctx, "incidents/propertylist-autocomplete", channelID)
+}
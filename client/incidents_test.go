@@ -2,9 +2,12 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,3 +46,248 @@ func TestIncidentsService_List(t *testing.T) {
 	require.Equal(t, 0, list.PageCount)
 	require.False(t, list.HasMore)
 }
+
+func TestIncidentsService_Iterate(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	var calls int
+	mux.HandleFunc("/"+buildAPIURL("incidents"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		calls++
+		switch calls {
+		case 1:
+			testFormValues(t, r, values{"per_page": "2", "team_id": "team1"})
+			fmt.Fprint(w, `{"has_more": true, "next_cursor": "c1", "items": [{"id": "1"}, {"id": "2"}]}`)
+		case 2:
+			testFormValues(t, r, values{"per_page": "2", "team_id": "team1", "cursor": "c1"})
+			fmt.Fprint(w, `{"has_more": true, "next_cursor": "c2", "items": [{"id": "3"}, {"id": "4"}]}`)
+		default:
+			testFormValues(t, r, values{"per_page": "2", "team_id": "team1", "cursor": "c2"})
+			fmt.Fprint(w, `{"has_more": false, "items": [{"id": "5"}]}`)
+		}
+	})
+
+	it := client.Incidents.Iterate(IncidentListOptions{TeamID: "team1", ListOptions: ListOptions{PerPage: 2}})
+
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Incident().ID)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []string{"1", "2", "3", "4", "5"}, ids)
+	require.Equal(t, 3, calls)
+	require.False(t, it.Next(context.Background()))
+}
+
+func TestIncidentsService_IterateError(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents"), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": "Bad parameter"}`)
+	})
+
+	it := client.Incidents.Iterate(IncidentListOptions{TeamID: "team1"})
+	require.False(t, it.Next(context.Background()))
+	require.Error(t, it.Err())
+}
+
+func TestIncidentsService_Create(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]interface{}{"name": "Incident", "team_id": "team1", "playbook_id": "playbook1"}, body)
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "1", "name": "Incident", "commander_user_id": "oncall"}`)
+	})
+
+	i, err := client.Incidents.Create(context.Background(), IncidentCreateOptions{
+		Name:       "Incident",
+		TeamID:     "team1",
+		PlaybookID: "playbook1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &Incident{ID: "1", Name: "Incident", CommanderUserID: "oncall"}, i)
+}
+
+func TestIncident_CurrentStatus(t *testing.T) {
+	i := &Incident{}
+	require.Equal(t, StatusReported, i.CurrentStatus())
+	require.True(t, i.IsActive())
+
+	i.StatusPosts = []StatusPost{
+		{Status: StatusActive, CreateAt: 1},
+		{Status: StatusResolved, CreateAt: 3},
+		{Status: StatusArchived, CreateAt: 4, DeleteAt: 5},
+		{Status: StatusActive, CreateAt: 2},
+	}
+	require.Equal(t, StatusResolved, i.CurrentStatus())
+	require.False(t, i.IsActive())
+
+	i.StatusPosts = []StatusPost{{CreateAt: 1}}
+	require.Equal(t, StatusActive, i.CurrentStatus())
+	i.EndAt = 2
+	require.Equal(t, StatusResolved, i.CurrentStatus())
+}
+
+func TestIncidentsService_UpdateStatus(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	var submission map[string]interface{}
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/update-status-dialog"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var request struct {
+			Submission map[string]interface{} `json:"submission"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		submission = request.Submission
+
+		if submission["override_exit_criteria"] == true {
+			return
+		}
+		fmt.Fprint(w, `{"errors": {"status": "The exit criteria are not met."}}`)
+	})
+
+	err := client.Incidents.UpdateStatus(context.Background(), "1", StatusUpdateOptions{
+		Status:   StatusResolved,
+		Message:  "Fixed",
+		Reminder: 15 * time.Minute,
+	})
+	var dialogErr *DialogError
+	require.True(t, errors.As(err, &dialogErr))
+	require.Equal(t, "status: The exit criteria are not met.", dialogErr.Error())
+	require.Equal(t, map[string]interface{}{
+		"status":                 "Resolved",
+		"message":                "Fixed",
+		"reminder":               "900",
+		"override_exit_criteria": false,
+	}, submission)
+
+	err = client.Incidents.UpdateStatus(context.Background(), "1", StatusUpdateOptions{
+		Status:               StatusResolved,
+		Message:              "Fixed",
+		OverrideExitCriteria: true,
+	})
+	require.NoError(t, err)
+}
+
+func TestIncidentsService_Update(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	var calls []string
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/commander"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		calls = append(calls, "commander")
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{"commander_id": "user2"}, body)
+	})
+	mux.HandleFunc("/"+buildAPIURL("incidents/1"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		calls = append(calls, "patch")
+		fmt.Fprint(w, `{"id": "1", "commander_user_id": "user2"}`)
+	})
+
+	i, err := client.Incidents.Update(context.Background(), "1", IncidentUpdateOptions{CommanderUserID: String("user2")})
+	require.NoError(t, err)
+	require.Equal(t, "user2", i.CommanderUserID)
+	require.Equal(t, []string{"commander", "patch"}, calls)
+}
+
+func TestIncidentsService_Search(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents/search"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"team_id": "team1", "terms": "database", "per_page": "5"})
+		fmt.Fprint(w, `{"has_more": false, "items": [{"incident": {"id": "1"}, "score": 2.5,
+			"snippets": [{"field": "name", "text": "database down", "html": "<mark>database</mark> down"}]}]}`)
+	})
+
+	results, err := client.Incidents.Search(context.Background(), IncidentSearchOptions{
+		TeamID:      "team1",
+		Terms:       "database",
+		ListOptions: ListOptions{PerPage: 5},
+	})
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	require.Equal(t, "1", results.Items[0].Incident.ID)
+	require.Equal(t, "name", results.Items[0].Snippets[0].Field)
+}
+
+func TestIncidentsService_Checklists(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/checklists/0/item/2/state"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{"new_state": ChecklistItemStateClosed}, body)
+	})
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/checklists/0/item/2/run"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"trigger_id": "trigger1"}`)
+	})
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/checklists/batch"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body struct {
+			Operations []ChecklistOperation `json:"operations"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, []ChecklistOperation{
+			{Type: ChecklistOperationSetAssignee, Checklist: 1, Item: 0, AssigneeID: "user1"},
+			{Type: ChecklistOperationRemove, Checklist: 1, Item: 1},
+		}, body.Operations)
+	})
+
+	err := client.Incidents.SetChecklistItemState(context.Background(), "1", 0, 2, ChecklistItemStateClosed)
+	require.NoError(t, err)
+
+	triggerID, err := client.Incidents.RunChecklistItemCommand(context.Background(), "1", 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, "trigger1", triggerID)
+
+	err = client.Incidents.BatchChecklistOperations(context.Background(), "1", []ChecklistOperation{
+		{Type: ChecklistOperationSetAssignee, Checklist: 1, Item: 0, AssigneeID: "user1"},
+		{Type: ChecklistOperationRemove, Checklist: 1, Item: 1},
+	})
+	require.NoError(t, err)
+}
+
+func TestIncidentsService_Properties(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/property-datetime-value"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]interface{}{"property_id": "prop1", "value": float64(1600000000000)}, body)
+	})
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/propertylist/3"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := client.Incidents.SetPropertyDatetimeValue(context.Background(), "1", "prop1", time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	err = client.Incidents.RemoveProperty(context.Background(), "1", 3)
+	require.NoError(t, err)
+}
+
+func TestIncidentsService_UpdateSourcePlaybook(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("incidents/1/playbook"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"error": "the playbook was modified since the preview"}`)
+	})
+
+	_, err := client.Incidents.UpdateSourcePlaybook(context.Background(), "1", 3)
+	require.True(t, errors.Is(err, ErrConflict))
+}
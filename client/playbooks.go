@@ -5,15 +5,64 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
-	"github.com/pkg/errors"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// The deeper structures of playbooks and incidents are shared with the server.
+type (
+	// Propertylist is the list of properties of a playbook or an incident.
+	Propertylist = playbook.Propertylist
+
+	// PropertylistItem is a property of a playbook or an incident.
+	PropertylistItem = playbook.PropertylistItem
+
+	// ExitCriteria are the conditions an incident must meet before it is resolved.
+	ExitCriteria = playbook.ExitCriteria
+
+	// Member gives the role of a user in a playbook.
+	Member = playbook.Member
+
+	// GroupMember gives the role of the users of a group in a playbook.
+	GroupMember = playbook.GroupMember
+
+	// ValidationIssue is an error or a warning about a field of a playbook.
+	ValidationIssue = playbook.ValidationIssue
+
+	// PlaybookChange is a difference between two versions of a playbook.
+	PlaybookChange = playbook.Change
 )
 
 // Playbook represents a playbook.
 type Playbook struct {
-	ID         string      `json:"id"`
-	Title      string      `json:"title"`
-	Checklists []Checklist `json:"checklists"`
+	ID                          string        `json:"id"`
+	Title                       string        `json:"title"`
+	Description                 string        `json:"description"`
+	TeamID                      string        `json:"team_id"`
+	CreatePublicIncident        bool          `json:"create_public_incident"`
+	CreateAt                    int64         `json:"create_at"`
+	DeleteAt                    int64         `json:"delete_at"`
+	NumStages                   int64         `json:"num_stages"`
+	NumSteps                    int64         `json:"num_steps"`
+	Checklists                  []Checklist   `json:"checklists"`
+	Propertylist                Propertylist  `json:"propertylist"`
+	ExitCriteria                ExitCriteria  `json:"exit_criteria"`
+	MemberIDs                   []string      `json:"member_ids"`
+	Members                     []Member      `json:"members,omitempty"`
+	Groups                      []GroupMember `json:"groups,omitempty"`
+	BroadcastChannelID          string        `json:"broadcast_channel_id"`
+	ReminderMessageTemplate     string        `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds int64         `json:"reminder_timer_default_seconds"`
+	IsGlobal                    bool          `json:"is_global"`
+	SharedTeamIDs               []string      `json:"shared_team_ids,omitempty"`
+	Version                     int64         `json:"version"`
+	ForkedFromID                string        `json:"forked_from_id"`
+	ForkedFromVersion           int64         `json:"forked_from_version"`
+	OnCallScheduleID            string        `json:"oncall_schedule_id"`
+	ExternalID                  string        `json:"external_id"`
 }
 
 // Checklist represents a playbook's checklist.
@@ -25,31 +74,76 @@ type Checklist struct {
 
 // ChecklistItem represents an item in a checklist.
 type ChecklistItem struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
-	State string `json:"state"`
-}
-
-// PlaybookCreateOptions specifies the parameters for PlaybooksService.Create method.
-type PlaybookCreateOptions struct {
-	Name string `json:"name"`
-}
-
-// PlaybookUpdateOptions specifies the parameters for PlaybooksService.Update method.
-type PlaybookUpdateOptions struct {
-	Name *string `json:"name"`
+	ID                     string `json:"id"`
+	Title                  string `json:"title"`
+	State                  string `json:"state"`
+	StateModified          int64  `json:"state_modified"`
+	StateModifiedPostID    string `json:"state_modified_post_id"`
+	AssigneeID             string `json:"assignee_id"`
+	AssigneeModified       int64  `json:"assignee_modified"`
+	AssigneeModifiedPostID string `json:"assignee_modified_post_id"`
+	Command                string `json:"command"`
+	CommandLastRun         int64  `json:"command_last_run"`
+	Description            string `json:"description"`
 }
 
 // PlaybookListOptions specifies the optional parameters to the
 // PlaybooksService.List method.
 type PlaybookListOptions struct {
 	ListOptions
+
+	// TeamID restricts the list to the playbooks of a team. When empty, the playbooks of all
+	// the teams the user can view are listed.
+	TeamID string `url:"team_id,omitempty"`
+
+	// MemberOnly restricts the list to the playbooks the user is a member of.
+	MemberOnly bool `url:"member_only,omitempty"`
+
+	Sort      PlaybookSort  `url:"sort,omitempty"`
+	Direction SortDirection `url:"direction,omitempty"`
 }
 
+// PlaybookSort enumerates the available fields we can sort playbooks on.
+type PlaybookSort string
+
+const (
+	// SortByTitle sorts by the title of the playbooks. It is the default.
+	SortByTitle PlaybookSort = "title"
+
+	// SortByStages sorts by the number of checklists of the playbooks.
+	SortByStages PlaybookSort = "stages"
+
+	// SortBySteps sorts by the number of checklist items of the playbooks.
+	SortBySteps PlaybookSort = "steps"
+)
+
 // PlaybookList contains the paginated result.
 type PlaybookList struct {
 	ListResult
-	Playbooks []*Playbook
+	Items []*Playbook `json:"items"`
+}
+
+// PlaybookValidation lists the problems of a playbook. The playbook cannot be saved if there is
+// any error.
+type PlaybookValidation struct {
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// PlaybookTemplate is a starter playbook that can be installed in a team.
+type PlaybookTemplate struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Playbook    Playbook `json:"playbook"`
+}
+
+// PlaybookDiff previews the changes saving the checklists and properties of an incident would
+// make to the playbook it was started from.
+type PlaybookDiff struct {
+	PlaybookID      string           `json:"playbook_id"`
+	PlaybookVersion int64            `json:"playbook_version"`
+	Changes         []PlaybookChange `json:"changes"`
 }
 
 // PlaybooksService handles communication with the playbook related
@@ -58,27 +152,209 @@ type PlaybooksService struct {
 	client *Client
 }
 
-// Create an playbook.
-func (s *PlaybooksService) Create(ctx context.Context, opts PlaybookCreateOptions) (*Playbook, error) {
-	return nil, errors.New("not implemented")
+// Create a playbook, returning its ID. An invalid playbook is rejected with an *ErrorResponse
+// listing its errors.
+func (s *PlaybooksService) Create(ctx context.Context, pbook Playbook) (string, error) {
+	req, err := s.client.NewRequest(http.MethodPost, "playbooks", pbook)
+	if err != nil {
+		return "", err
+	}
+
+	return s.doCreate(ctx, req)
 }
 
 // Get a playbook.
 func (s *PlaybooksService) Get(ctx context.Context, playbookID string) (*Playbook, error) {
-	return nil, errors.New("not implemented")
+	u := fmt.Sprintf("playbooks/%s", playbookID)
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pbook := new(Playbook)
+	resp, err := s.client.Do(ctx, req, pbook)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return pbook, nil
+}
+
+// Update replaces a playbook with the given one, which must have the ID of an existing playbook.
+func (s *PlaybooksService) Update(ctx context.Context, pbook Playbook) error {
+	u := fmt.Sprintf("playbooks/%s", pbook.ID)
+	req, err := s.client.NewRequest(http.MethodPut, u, pbook)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.doIdempotent(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
-// Update a playbook.
-func (s *PlaybooksService) Update(ctx context.Context, opts PlaybookUpdateOptions) (*Playbook, error) {
-	return nil, errors.New("not implemented")
+// Delete a playbook.
+func (s *PlaybooksService) Delete(ctx context.Context, playbookID string) error {
+	u := fmt.Sprintf("playbooks/%s", playbookID)
+	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.doIdempotent(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
 }
 
 // List the playbooks.
-func (s *PlaybooksService) List(ctx context.Context, opt PlaybookListOptions) (*PlaybookList, error) {
-	return nil, errors.New("not implemented")
+func (s *PlaybooksService) List(ctx context.Context, opts PlaybookListOptions) (*PlaybookList, error) {
+	u, err := addOptions("playbooks", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlaybookList{}
+	resp, err := s.client.Do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result, nil
 }
 
-// Delete a playbook.
-func (s *PlaybooksService) Delete(ctx context.Context, playbookID string) (*Playbook, error) {
-	return nil, errors.New("not implemented")
+// Autocomplete lists the playbooks of a team the user can start an incident from, in the format
+// of the slash command autocompletion.
+func (s *PlaybooksService) Autocomplete(ctx context.Context, teamID string) ([]model.AutocompleteListItem, error) {
+	u, err := addOptions("playbooks/autocomplete", struct {
+		TeamID string `url:"team_id"`
+	}{teamID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []model.AutocompleteListItem
+	resp, err := s.client.Do(ctx, req, &items)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return items, nil
+}
+
+// Validate lists the problems of a playbook without saving it.
+func (s *PlaybooksService) Validate(ctx context.Context, pbook Playbook) (*PlaybookValidation, error) {
+	req, err := s.client.NewRequest(http.MethodPost, "playbooks/validate", pbook)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := new(PlaybookValidation)
+	resp, err := s.client.doIdempotent(ctx, req, validation)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return validation, nil
+}
+
+// Fork copies a playbook into a team, returning the ID of the copy. The copy remembers the
+// playbook and version it was forked from.
+func (s *PlaybooksService) Fork(ctx context.Context, playbookID, teamID string) (string, error) {
+	u := fmt.Sprintf("playbooks/%s/fork", playbookID)
+	body := struct {
+		TeamID string `json:"team_id"`
+	}{teamID}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+
+	return s.doCreate(ctx, req)
+}
+
+// Duplicate copies a playbook into a team under a new title, returning the ID of the copy. The
+// team defaults to the one of the playbook, and the title to "Copy of" its title.
+func (s *PlaybooksService) Duplicate(ctx context.Context, playbookID, teamID, title string) (string, error) {
+	u := fmt.Sprintf("playbooks/%s/duplicate", playbookID)
+	body := struct {
+		TeamID string `json:"team_id,omitempty"`
+		Title  string `json:"title,omitempty"`
+	}{teamID, title}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+
+	return s.doCreate(ctx, req)
+}
+
+// ListTemplates lists the starter playbooks.
+func (s *PlaybooksService) ListTemplates(ctx context.Context) ([]PlaybookTemplate, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "playbooks/templates", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []PlaybookTemplate
+	resp, err := s.client.Do(ctx, req, &templates)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return templates, nil
+}
+
+// InstallTemplate creates a playbook in a team from the starter playbook with the given name,
+// returning its ID.
+func (s *PlaybooksService) InstallTemplate(ctx context.Context, name, teamID string) (string, error) {
+	u := fmt.Sprintf("playbooks/templates/%s/install", name)
+	body := struct {
+		TeamID string `json:"team_id"`
+	}{teamID}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+
+	return s.doCreate(ctx, req)
+}
+
+// doCreate sends a request creating a playbook and returns its ID.
+func (s *PlaybooksService) doCreate(ctx context.Context, req *http.Request) (string, error) {
+	var result struct {
+		ID string `json:"id"`
+	}
+	resp, err := s.client.Do(ctx, req, &result)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return result.ID, nil
 }
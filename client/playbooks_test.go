@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybooksService_Create(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var pbook Playbook
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pbook))
+		require.Equal(t, "Playbook", pbook.Title)
+		require.Equal(t, "team1", pbook.TeamID)
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "playbook1"}`)
+	})
+
+	id, err := client.Playbooks.Create(context.Background(), Playbook{Title: "Playbook", TeamID: "team1"})
+	require.NoError(t, err)
+	require.Equal(t, "playbook1", id)
+}
+
+func TestPlaybooksService_CreateInvalid(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks"), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": "invalid playbook",
+			"errors": [{"field": "title", "message": "The title is required."}],
+			"warnings": [{"field": "checklists", "message": "The playbook has no checklist."}]}`)
+	})
+
+	_, err := client.Playbooks.Create(context.Background(), Playbook{TeamID: "team1"})
+
+	var errorResponse *ErrorResponse
+	require.True(t, errors.As(err, &errorResponse))
+	require.Equal(t, http.StatusBadRequest, errorResponse.Response.StatusCode)
+	require.Equal(t, []ValidationIssue{{Field: "title", Message: "The title is required."}}, errorResponse.Errors)
+	require.Len(t, errorResponse.Warnings, 1)
+}
+
+func TestPlaybooksService_Get(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks/playbook1"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id": "playbook1", "title": "Playbook", "version": 3,
+			"checklists": [{"title": "Triage", "items": [{"title": "Page the team", "command": "/page"}]}]}`)
+	})
+
+	pbook, err := client.Playbooks.Get(context.Background(), "playbook1")
+	require.NoError(t, err)
+	require.Equal(t, &Playbook{
+		ID:      "playbook1",
+		Title:   "Playbook",
+		Version: 3,
+		Checklists: []Checklist{{
+			Title: "Triage",
+			Items: []ChecklistItem{{Title: "Page the team", Command: "/page"}},
+		}},
+	}, pbook)
+}
+
+func TestPlaybooksService_List(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"team_id":     "team1",
+			"member_only": "true",
+			"sort":        "steps",
+			"direction":   "desc",
+			"per_page":    "10",
+		})
+		fmt.Fprint(w, `{"total_count": 1, "page_count": 1, "has_more": false, "items": [{"id": "playbook1"}]}`)
+	})
+
+	list, err := client.Playbooks.List(context.Background(), PlaybookListOptions{
+		TeamID:      "team1",
+		MemberOnly:  true,
+		Sort:        SortBySteps,
+		Direction:   Desc,
+		ListOptions: ListOptions{PerPage: 10},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, list.TotalCount)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, "playbook1", list.Items[0].ID)
+}
+
+func TestPlaybooksService_Delete(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks/playbook1"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	require.NoError(t, client.Playbooks.Delete(context.Background(), "playbook1"))
+}
+
+func TestPlaybooksService_Templates(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/"+buildAPIURL("playbooks/templates"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"name": "outage", "title": "Service outage", "playbook": {"title": "Service outage"}}]`)
+	})
+	mux.HandleFunc("/"+buildAPIURL("playbooks/templates/outage/install"), func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{"team_id": "team1"}, body)
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "playbook1"}`)
+	})
+
+	templates, err := client.Playbooks.ListTemplates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, "outage", templates[0].Name)
+
+	id, err := client.Playbooks.InstallTemplate(context.Background(), templates[0].Name, "team1")
+	require.NoError(t, err)
+	require.Equal(t, "playbook1", id)
+}
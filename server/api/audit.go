@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// AuditHandler is the API handler for the audit log.
+type AuditHandler struct {
+	auditService audit.Service
+	pluginAPI    *pluginapi.Client
+	log          bot.Logger
+}
+
+// NewAuditHandler returns a new audit api handler
+func NewAuditHandler(router *mux.Router, auditService audit.Service, api *pluginapi.Client, log bot.Logger) *AuditHandler {
+	handler := &AuditHandler{
+		auditService: auditService,
+		pluginAPI:    api,
+		log:          log,
+	}
+
+	auditRouter := router.PathPrefix("/audit").Subrouter()
+	auditRouter.HandleFunc("", handler.getRecords).Methods(http.MethodGet)
+
+	return handler
+}
+
+// getRecords handles the GET /audit endpoint, for system admins only.
+func (h *AuditHandler) getRecords(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if !permissions.IsAdmin(userID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to read the audit log", userID))
+		return
+	}
+
+	options, err := parseAuditFilterOptions(r.URL.Query())
+	if err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	results, err := h.auditService.GetRecords(options)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, results, http.StatusOK)
+}
+
+func parseAuditFilterOptions(query url.Values) (audit.FilterOptions, error) {
+	options := audit.FilterOptions{
+		ActorUserID: query.Get("actor_user_id"),
+		Source:      audit.Source(query.Get("source")),
+		TargetType:  audit.TargetType(query.Get("target_type")),
+		TargetID:    query.Get("target_id"),
+		TeamID:      query.Get("team_id"),
+		Action:      query.Get("action"),
+		Cursor:      query.Get("cursor"),
+	}
+
+	var err error
+	if param := query.Get("denied"); param != "" {
+		if options.DeniedOnly, err = strconv.ParseBool(param); err != nil {
+			return audit.FilterOptions{}, errors.Wrap(err, "bad parameter 'denied'")
+		}
+	}
+	if param := query.Get("since"); param != "" {
+		if options.Since, err = strconv.ParseInt(param, 10, 64); err != nil {
+			return audit.FilterOptions{}, errors.Wrap(err, "bad parameter 'since'")
+		}
+	}
+	if param := query.Get("until"); param != "" {
+		if options.Until, err = strconv.ParseInt(param, 10, 64); err != nil {
+			return audit.FilterOptions{}, errors.Wrap(err, "bad parameter 'until'")
+		}
+	}
+	if param := query.Get("per_page"); param != "" {
+		if options.PerPage, err = strconv.Atoi(param); err != nil {
+			return audit.FilterOptions{}, errors.Wrap(err, "bad parameter 'per_page'")
+		}
+	}
+
+	return options.Validate()
+}
+
+// auditSource returns where the changes requested by r come from: another plugin, or a client of
+// the REST API.
+func auditSource(r *http.Request) audit.Source {
+	if pluginID, _ := r.Context().Value(contextKey(PluginIDContextKey)).(string); pluginID != "" {
+		return audit.SourcePlugin
+	}
+
+	return audit.SourceAPI
+}
+
+// recordDenied records in the audit log that the user of r was not allowed to make a change.
+func recordDenied(auditor audit.Service, r *http.Request, targetType audit.TargetType, targetID, teamID, action string) {
+	auditor.Record(audit.Record{
+		ActorUserID: r.Header.Get("Mattermost-User-ID"),
+		Source:      auditSource(r),
+		TargetType:  targetType,
+		TargetID:    targetID,
+		TeamID:      teamID,
+		Action:      action,
+		Denied:      true,
+	})
+}
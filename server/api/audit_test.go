@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
+	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+)
+
+func TestAudit(t *testing.T) {
+	var mockCtrl *gomock.Controller
+	var handler *Handler
+	var logger *mock_poster.MockLogger
+	var auditService *mock_audit.MockService
+	var pluginAPI *plugintest.API
+	var client *pluginapi.Client
+
+	reset := func() {
+		mockCtrl = gomock.NewController(t)
+		handler = NewHandler()
+		auditService = mock_audit.NewMockService(mockCtrl)
+		pluginAPI = &plugintest.API{}
+		client = pluginapi.NewClient(pluginAPI)
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		NewAuditHandler(handler.APIRouter, auditService, client, logger)
+	}
+
+	t.Run("get records as admin", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		incidentID := model.NewId()
+		expected := audit.GetRecordsResults{
+			Items: []audit.Record{{
+				ID:          "recordid",
+				CreateAt:    1000,
+				ActorUserID: "otheruserid",
+				Source:      audit.SourceDialog,
+				TargetType:  audit.TargetIncident,
+				TargetID:    incidentID,
+				Action:      "update_status",
+				Changes:     []audit.Change{},
+			}},
+		}
+		auditService.EXPECT().
+			GetRecords(audit.FilterOptions{
+				TargetID: incidentID,
+				Source:   audit.SourceDialog,
+				Since:    500,
+				PerPage:  10,
+			}).
+			Return(expected, nil).
+			Times(1)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/audit?target_id="+incidentID+"&source=dialog&since=500&per_page=10", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var results audit.GetRecordsResults
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+		assert.Equal(t, expected, results)
+	})
+
+	t.Run("get records with a bad parameter", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/audit?since=yesterday", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("get records as non-admin", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/audit", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -15,8 +15,11 @@ import (
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 )
@@ -25,22 +28,27 @@ import (
 type IncidentHandler struct {
 	incidentService incident.Service
 	playbookService playbook.Service
+	onCallService   oncall.Service
 	pluginAPI       *pluginapi.Client
 	poster          bot.Poster
 	log             bot.Logger
 	telemetry       incident.Telemetry
+	auditService    audit.Service
 }
 
 // NewIncidentHandler Creates a new Plugin API handler.
 func NewIncidentHandler(router *mux.Router, incidentService incident.Service, playbookService playbook.Service,
-	api *pluginapi.Client, poster bot.Poster, log bot.Logger, telemetry incident.Telemetry) *IncidentHandler {
+	onCallService oncall.Service, api *pluginapi.Client, poster bot.Poster, log bot.Logger, telemetry incident.Telemetry,
+	auditService audit.Service) *IncidentHandler {
 	handler := &IncidentHandler{
 		incidentService: incidentService,
 		playbookService: playbookService,
+		onCallService:   onCallService,
 		pluginAPI:       api,
 		poster:          poster,
 		log:             log,
 		telemetry:       telemetry,
+		auditService:    auditService,
 	}
 
 	incidentsRouter := router.PathPrefix("/incidents").Subrouter()
@@ -50,27 +58,41 @@ func NewIncidentHandler(router *mux.Router, incidentService incident.Service, pl
 	incidentsRouter.HandleFunc("/dialog", handler.createIncidentFromDialog).Methods(http.MethodPost)
 	incidentsRouter.HandleFunc("/commanders", handler.getCommanders).Methods(http.MethodGet)
 	incidentsRouter.HandleFunc("/channels", handler.getChannels).Methods(http.MethodGet)
+	incidentsRouter.HandleFunc("/search", handler.searchIncidents).Methods(http.MethodGet)
 	incidentsRouter.HandleFunc("/checklist-autocomplete", handler.getChecklistAutocomplete).Methods(http.MethodGet)
+	incidentsRouter.HandleFunc("/checklists-autocomplete", handler.getChecklistsAutocomplete).Methods(http.MethodGet)
 	incidentsRouter.HandleFunc("/propertylist-autocomplete", handler.getPropertylistAutocomplete).Methods(http.MethodGet)
 
 	incidentRouter := incidentsRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
 	incidentRouter.HandleFunc("", handler.getIncident).Methods(http.MethodGet)
 	incidentRouter.HandleFunc("/metadata", handler.getIncidentMetadata).Methods(http.MethodGet)
+	incidentRouter.HandleFunc("/commander/handoff/accept", handler.acceptCommanderHandoff).Methods(http.MethodPost)
+	incidentRouter.HandleFunc("/commander/handoff/decline", handler.declineCommanderHandoff).Methods(http.MethodPost)
 
 	incidentRouterAuthorized := incidentRouter.PathPrefix("").Subrouter()
 	incidentRouterAuthorized.Use(handler.checkEditPermissions)
 	incidentRouterAuthorized.HandleFunc("", handler.updateIncident).Methods(http.MethodPatch)
 	incidentRouterAuthorized.HandleFunc("/commander", handler.changeCommander).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/commander/handoff", handler.requestCommanderHandoff).Methods(http.MethodPost)
 	incidentRouterAuthorized.HandleFunc("/property-selection-value", handler.changePropertySelectionValue).Methods(http.MethodPost)
 	incidentRouterAuthorized.HandleFunc("/property-freetext-value", handler.changePropertyFreetextValue).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/property-number-value", handler.changePropertyNumberValue).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/property-datetime-value", handler.changePropertyDatetimeValue).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/property-user-value", handler.changePropertyUserValue).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/property-channel-value", handler.changePropertyChannelValue).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/property-url-value", handler.changePropertyURLValue).Methods(http.MethodPost)
 	incidentRouterAuthorized.HandleFunc("/update-status-dialog", handler.updateStatusDialog).Methods(http.MethodPost)
 	incidentRouterAuthorized.HandleFunc("/reminder/button-update", handler.reminderButtonUpdate).Methods(http.MethodPost)
 	incidentRouterAuthorized.HandleFunc("/reminder/button-dismiss", handler.reminderButtonDismiss).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/playbook-diff", handler.getPlaybookDiff).Methods(http.MethodGet)
+	incidentRouterAuthorized.HandleFunc("/playbook", handler.saveAsPlaybook).Methods(http.MethodPost)
+	incidentRouterAuthorized.HandleFunc("/playbook", handler.updateSourcePlaybook).Methods(http.MethodPut)
 
 	channelRouter := incidentsRouter.PathPrefix("/channel").Subrouter()
 	channelRouter.HandleFunc("/{channel_id:[A-Za-z0-9]+}", handler.getIncidentByChannel).Methods(http.MethodGet)
 
 	checklistsRouter := incidentRouterAuthorized.PathPrefix("/checklists").Subrouter()
+	checklistsRouter.HandleFunc("/batch", handler.checklistsBatch).Methods(http.MethodPost)
 
 	checklistRouter := checklistsRouter.PathPrefix("/{checklist:[0-9]+}").Subrouter()
 	checklistRouter.HandleFunc("/add", handler.addChecklistItem).Methods(http.MethodPut)
@@ -85,10 +107,10 @@ func NewIncidentHandler(router *mux.Router, incidentService incident.Service, pl
 
 	propertyRouter := incidentRouterAuthorized.PathPrefix("/propertylist").Subrouter()
 
-	propertyRouter.HandleFunc("", handler.propertylistItemDelete).Methods(http.MethodDelete)
 	propertyRouter.HandleFunc("/add", handler.addPropertylistItem).Methods(http.MethodPut)
 	propertyRouter.HandleFunc("/reorder", handler.reorderPropertylistItem).Methods(http.MethodPut)
-	propertyRouter.HandleFunc("/update", handler.updatePropertylistItem).Methods(http.MethodPut)
+	propertyRouter.HandleFunc("/{item:[0-9]+}", handler.propertylistItemDelete).Methods(http.MethodDelete)
+	propertyRouter.HandleFunc("/{item:[0-9]+}", handler.updatePropertylistItem).Methods(http.MethodPut)
 
 	telemetryRouterAuthorized := router.PathPrefix("/telemetry").Subrouter()
 	telemetryRouterAuthorized.Use(handler.checkViewPermissions)
@@ -97,6 +119,16 @@ func NewIncidentHandler(router *mux.Router, incidentService incident.Service, pl
 	return handler
 }
 
+// incidents returns the incident service recording the changes requested by r in the audit log.
+func (h *IncidentHandler) incidents(r *http.Request) incident.Service {
+	return h.incidentService.WithAuditSource(auditSource(r))
+}
+
+// playbooks returns the playbook service recording the changes requested by r in the audit log.
+func (h *IncidentHandler) playbooks(r *http.Request) playbook.Service {
+	return h.playbookService.WithAuditSource(auditSource(r))
+}
+
 func (h *IncidentHandler) checkEditPermissions(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -104,6 +136,7 @@ func (h *IncidentHandler) checkEditPermissions(next http.Handler) http.Handler {
 
 		if err := permissions.EditIncident(userID, vars["id"], h.pluginAPI, h.incidentService); err != nil {
 			if errors.Is(err, permissions.ErrNoPermissions) {
+				recordDenied(h.auditService, r, audit.TargetIncident, vars["id"], "", "edit_incident")
 				HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
 				return
 			}
@@ -143,7 +176,7 @@ func (h *IncidentHandler) createIncidentFromPost(w http.ResponseWriter, r *http.
 		return
 	}
 
-	newIncident, err := h.createIncident(payloadIncident, userID)
+	newIncident, err := h.createIncident(payloadIncident, userID, "", auditSource(r))
 
 	if errors.Is(err, incident.ErrPermission) {
 		HandleErrorWithCode(w, http.StatusForbidden, "unable to create incident", err)
@@ -225,16 +258,16 @@ func (h *IncidentHandler) createIncidentFromDialog(w http.ResponseWriter, r *htt
 		description = rawDescription
 	}
 
+	// The commander is whoever is on call for the playbook, or else the user starting the incident.
 	payloadIncident := incident.Incident{
-		CommanderUserID: request.UserId,
-		TeamID:          request.TeamId,
-		Name:            name,
-		Description:     description,
-		PostID:          state.PostID,
-		PlaybookID:      playbookID,
+		TeamID:      request.TeamId,
+		Name:        name,
+		Description: description,
+		PostID:      state.PostID,
+		PlaybookID:  playbookID,
 	}
 
-	newIncident, err := h.createIncident(payloadIncident, request.UserId)
+	newIncident, err := h.createIncident(payloadIncident, request.UserId, request.UserId, audit.SourceDialog)
 	if err != nil {
 		if errors.Is(err, incident.ErrMalformedIncident) {
 			HandleErrorWithCode(w, http.StatusBadRequest, "unable to create incident", err)
@@ -277,72 +310,51 @@ func (h *IncidentHandler) createIncidentFromDialog(w http.ResponseWriter, r *htt
 	w.WriteHeader(http.StatusCreated)
 }
 
-func (h *IncidentHandler) createIncident(newIncident incident.Incident, userID string) (*incident.Incident, error) {
-	if newIncident.ID != "" {
-		return nil, errors.Wrap(incident.ErrMalformedIncident, "incident already has an id")
-	}
-
-	if newIncident.ChannelID != "" {
-		return nil, errors.Wrap(incident.ErrMalformedIncident, "incident channel already has an id")
-	}
-
-	if newIncident.CreateAt != 0 {
-		return nil, errors.Wrap(incident.ErrMalformedIncident, "incident channel already has created at date")
-	}
-
-	if newIncident.TeamID == "" {
-		return nil, errors.Wrap(incident.ErrMalformedIncident, "missing team id of incident")
-	}
-
-	if newIncident.CommanderUserID == "" {
-		return nil, errors.Wrap(incident.ErrMalformedIncident, "missing commander user id of incident")
-	}
-
-	// Commander should have permission to the team
-	if !permissions.CanViewTeam(newIncident.CommanderUserID, newIncident.TeamID, h.pluginAPI) {
-		return nil, errors.Wrap(incident.ErrPermission, "commander user does not have permissions for the team")
-	}
-
-	public := true
+// createIncident creates an incident after checking it and the permissions of userID. When the
+// commander is not given, it defaults to whoever is on call in the schedule of the playbook if
+// they are in the team, and then to defaultCommanderID, which may be empty. source is where the request comes from, for the
+// audit log.
+func (h *IncidentHandler) createIncident(newIncident incident.Incident, userID, defaultCommanderID string, source audit.Source) (*incident.Incident, error) {
+	var pb *playbook.Playbook
 	if newIncident.PlaybookID != "" {
-		pb, err := h.playbookService.Get(newIncident.PlaybookID)
+		thePlaybook, err := h.playbookService.Get(newIncident.PlaybookID)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to get playbook")
 		}
+		pb = &thePlaybook
+	}
 
-		if !sliceContains(pb.MemberIDs, userID) {
-			return nil, errors.New("userID is not a member of playbook")
+	if newIncident.CommanderUserID == "" {
+		scheduleID := ""
+		if pb != nil {
+			scheduleID = pb.OnCallScheduleID
 		}
 
-		newIncident.Checklists = pb.Checklists
-		newIncident.Propertylist = pb.Propertylist
-		public = pb.CreatePublicIncident
-
-		newIncident.BroadcastChannelID = pb.BroadcastChannelID
-		newIncident.ReminderMessageTemplate = pb.ReminderMessageTemplate
-		newIncident.PreviousReminder = time.Duration(pb.ReminderTimerDefaultSeconds) * time.Second
+		canCommand := func(commanderID string) bool {
+			return permissions.CanViewTeam(commanderID, newIncident.TeamID, h.pluginAPI)
+		}
+		commanderID, err := oncall.DefaultCommander(h.onCallService, scheduleID, time.Now(), defaultCommanderID, canCommand)
+		if err != nil {
+			h.log.Warnf("failed to get the default commander of the incident: %v", err)
+		}
+		newIncident.CommanderUserID = commanderID
 	}
 
-	permission := model.PERMISSION_CREATE_PRIVATE_CHANNEL
-	permissionMessage := "You are not able to create a private channel"
-	if public {
-		permission = model.PERMISSION_CREATE_PUBLIC_CHANNEL
-		permissionMessage = "You are not able to create a public channel"
+	if err := incident.ValidateNewIncident(newIncident); err != nil {
+		return nil, err
 	}
-	if !h.pluginAPI.User.HasPermissionToTeam(userID, newIncident.TeamID, permission) {
-		return nil, errors.Wrap(incident.ErrPermission, permissionMessage)
+
+	if err := permissions.StartIncident(userID, newIncident, pb, h.pluginAPI, h.log); err != nil {
+		return nil, err
 	}
 
-	if newIncident.PostID != "" {
-		post, err := h.pluginAPI.Post.GetPost(newIncident.PostID)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get incident original post")
-		}
-		if !permissions.MemberOfChannelID(userID, post.ChannelId, h.pluginAPI) {
-			return nil, errors.New("user is not a member of the channel containing the incident's original post")
-		}
+	public := true
+	if pb != nil {
+		newIncident.ApplyPlaybook(*pb)
+		public = pb.CreatePublicIncident
 	}
-	return h.incidentService.CreateIncident(&newIncident, userID, public)
+
+	return h.incidentService.WithAuditSource(source).CreateIncident(&newIncident, userID, public)
 }
 
 // getIncidents handles the GET /incidents endpoint.
@@ -354,9 +366,9 @@ func (h *IncidentHandler) getIncidents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userID := r.Header.Get("Mattermost-User-ID")
-	if !permissions.CanViewTeam(userID, filterOptions.TeamID, h.pluginAPI) {
+	if teamID, ok := canViewTeams(userID, filterOptions, h.pluginAPI); !ok {
 		HandleErrorWithCode(w, http.StatusForbidden, "permissions error", errors.Errorf(
-			"userID %s does not have view permission for teamID %s", userID, filterOptions.TeamID))
+			"userID %s does not have view permission for teamID %s", userID, teamID))
 		return
 	}
 
@@ -366,7 +378,10 @@ func (h *IncidentHandler) getIncidents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	results, err := h.incidentService.GetIncidents(requesterInfo, *filterOptions)
-	if err != nil {
+	if errors.Is(err, cursor.ErrInvalid) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	} else if err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -451,13 +466,14 @@ func (h *IncidentHandler) getIncidentByChannel(w http.ResponseWriter, r *http.Re
 
 // getCommanders handles the /incidents/commanders api endpoint.
 func (h *IncidentHandler) getCommanders(w http.ResponseWriter, r *http.Request) {
-	teamID := r.URL.Query().Get("team_id")
-	if teamID == "" {
-		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter: team_id", errors.New("team_id required"))
+	options, err := parseIncidentsFilterOptions(r.URL)
+	if err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
 	}
 
 	userID := r.Header.Get("Mattermost-User-ID")
-	if !permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+	if teamID, ok := canViewTeams(userID, options, h.pluginAPI); !ok {
 		HandleErrorWithCode(w, http.StatusForbidden, "permissions error", errors.Errorf(
 			"userID %s does not have view permission for teamID %s",
 			userID,
@@ -466,16 +482,12 @@ func (h *IncidentHandler) getCommanders(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	options := incident.FilterOptions{
-		TeamID: teamID,
-	}
-
 	requesterInfo := incident.RequesterInfo{
 		UserID:          userID,
 		UserIDtoIsAdmin: map[string]bool{userID: permissions.IsAdmin(userID, h.pluginAPI)},
 	}
 
-	commanders, err := h.incidentService.GetCommanders(requesterInfo, options)
+	commanders, err := h.incidentService.GetCommanders(requesterInfo, *options)
 	if err != nil {
 		HandleError(w, errors.Wrapf(err, "failed to get commanders"))
 		return
@@ -488,6 +500,62 @@ func (h *IncidentHandler) getCommanders(w http.ResponseWriter, r *http.Request)
 	ReturnJSON(w, commanders, http.StatusOK)
 }
 
+// searchIncidents handles the GET /incidents/search endpoint.
+func (h *IncidentHandler) searchIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	pageParam := query.Get("page")
+	if pageParam == "" {
+		pageParam = "0"
+	}
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", errors.Wrapf(err, "bad parameter 'page'"))
+		return
+	}
+
+	perPageParam := query.Get("per_page")
+	if perPageParam == "" {
+		perPageParam = "0"
+	}
+	perPage, err := strconv.Atoi(perPageParam)
+	if err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", errors.Wrapf(err, "bad parameter 'per_page'"))
+		return
+	}
+
+	options := incident.SearchOptions{
+		TeamID:  query.Get("team_id"),
+		Terms:   query.Get("terms"),
+		Page:    page,
+		PerPage: perPage,
+	}
+	if err = incident.ValidateSearchOptions(&options); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	userID := r.Header.Get("Mattermost-User-ID")
+	if !permissions.CanViewTeam(userID, options.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "permissions error", errors.Errorf(
+			"userID %s does not have view permission for teamID %s", userID, options.TeamID))
+		return
+	}
+
+	requesterInfo := incident.RequesterInfo{
+		UserID:          userID,
+		UserIDtoIsAdmin: map[string]bool{userID: permissions.IsAdmin(userID, h.pluginAPI)},
+	}
+
+	results, err := h.incidentService.SearchIncidents(requesterInfo, options)
+	if err != nil {
+		HandleError(w, errors.Wrap(err, "failed to search incidents"))
+		return
+	}
+
+	ReturnJSON(w, results, http.StatusOK)
+}
+
 func (h *IncidentHandler) getChannels(w http.ResponseWriter, r *http.Request) {
 	filterOptions, err := parseIncidentsFilterOptions(r.URL)
 	if err != nil {
@@ -496,11 +564,11 @@ func (h *IncidentHandler) getChannels(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userID := r.Header.Get("Mattermost-User-ID")
-	if !permissions.CanViewTeam(userID, filterOptions.TeamID, h.pluginAPI) {
+	if teamID, ok := canViewTeams(userID, filterOptions, h.pluginAPI); !ok {
 		HandleErrorWithCode(w, http.StatusForbidden, "permissions error", errors.Errorf(
 			"userID %s does not have view permission for teamID %s",
 			userID,
-			filterOptions.TeamID,
+			teamID,
 		))
 		return
 	}
@@ -548,7 +616,7 @@ func (h *IncidentHandler) changeCommander(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.incidentService.ChangeCommander(vars["id"], userID, params.CommanderID); err != nil {
+	if err := h.incidents(r).ChangeCommander(vars["id"], userID, params.CommanderID); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -556,20 +624,41 @@ func (h *IncidentHandler) changeCommander(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusOK)
 }
 
-// changePropertySelectionValue handles the /incidents/{id}/property-selection-value api endpoint.
-func (h *IncidentHandler) changePropertySelectionValue(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
+// requestCommanderHandoff handles the POST /incidents/{id}/commander/handoff endpoint, asking
+// another user to take command of the incident.
+func (h *IncidentHandler) requestCommanderHandoff(w http.ResponseWriter, r *http.Request) {
+	incidentID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
+
 	var params struct {
-		PropertyListItemID string `json:"property_id"`
-		SelectionID        string `json:"selection_id"`
+		CommanderID string `json:"commander_id"`
+		Notes       string `json:"notes"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
 		return
 	}
 
-	if err := h.incidentService.ChangePropertySelectionValue(vars["id"], userID, params.PropertyListItemID, params.SelectionID); err != nil {
+	incdnt, err := h.incidentService.GetIncident(incidentID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if !permissions.CanViewTeam(params.CommanderID, incdnt.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "The new commander must be a member of the team",
+			errors.Errorf("userid: %s does not have permissions to team %s; cannot be made commander", params.CommanderID, incdnt.TeamID))
+		return
+	}
+
+	err = h.incidents(r).RequestCommanderHandoff(incidentID, userID, params.CommanderID, params.Notes)
+	if errors.Is(err, incident.ErrPermission) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Only the commander can hand command over", err)
+		return
+	} else if errors.Is(err, incident.ErrMalformedIncident) || errors.Is(err, incident.ErrIncidentNotActive) {
+		HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -577,28 +666,192 @@ func (h *IncidentHandler) changePropertySelectionValue(w http.ResponseWriter, r
 	w.WriteHeader(http.StatusOK)
 }
 
-// changePropertySelectionValue handles the /incidents/{id}/property-freetext-value api endpoint.
+// acceptCommanderHandoff handles the POST /incidents/{id}/commander/handoff/accept endpoint,
+// called by the proposed commander, usually with the button of the handoff request.
+func (h *IncidentHandler) acceptCommanderHandoff(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	err := h.incidents(r).AcceptCommanderHandoff(mux.Vars(r)["id"], userID)
+	h.answerCommanderHandoff(w, err)
+}
+
+// declineCommanderHandoff handles the POST /incidents/{id}/commander/handoff/decline endpoint,
+// called by the proposed commander, usually with the button of the handoff request.
+func (h *IncidentHandler) declineCommanderHandoff(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	err := h.incidents(r).DeclineCommanderHandoff(mux.Vars(r)["id"], userID)
+	h.answerCommanderHandoff(w, err)
+}
+
+func (h *IncidentHandler) answerCommanderHandoff(w http.ResponseWriter, err error) {
+	if errors.Is(err, incident.ErrNoCommanderHandoff) || errors.Is(err, incident.ErrNotFound) {
+		HandleErrorWithCode(w, http.StatusNotFound, "There is no pending commander handoff for you", err)
+		return
+	} else if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	// Answers interactive buttons too: the request post is updated by the service.
+	ReturnJSON(w, &model.PostActionIntegrationResponse{}, http.StatusOK)
+}
+
+// changePropertySelectionValue handles the /incidents/{id}/property-selection-value api endpoint.
+func (h *IncidentHandler) changePropertySelectionValue(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PropertyListItemID string   `json:"property_id"`
+		SelectionID        string   `json:"selection_id"`
+		SelectionIDs       []string `json:"selection_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	// selection_id is the legacy, comma-joined, form of selection_ids.
+	selectionIDs := params.SelectionIDs
+	if selectionIDs == nil {
+		selectionIDs = strings.Split(params.SelectionID, ",")
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, selectionIDs)
+}
+
+// changePropertyFreetextValue handles the /incidents/{id}/property-freetext-value api endpoint.
 func (h *IncidentHandler) changePropertyFreetextValue(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := r.Header.Get("Mattermost-User-ID")
 
+	var params struct {
+		PropertyListItemID string   `json:"property_id"`
+		FreetextValue      string   `json:"value"`
+		FreetextValues     []string `json:"values"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	if params.FreetextValues != nil {
+		h.changePropertyValues(w, r, params.PropertyListItemID, params.FreetextValues)
+		return
+	}
+
+	if err := h.incidents(r).ChangePropertyFreetextValue(vars["id"], userID, params.PropertyListItemID, params.FreetextValue); err != nil {
+		h.handlePropertyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// changePropertyNumberValue handles the /incidents/{id}/property-number-value api endpoint. A null
+// value clears the property.
+func (h *IncidentHandler) changePropertyNumberValue(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PropertyListItemID string   `json:"property_id"`
+		Value              *float64 `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	var values []string
+	if params.Value != nil {
+		values = []string{strconv.FormatFloat(*params.Value, 'f', -1, 64)}
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, values)
+}
+
+// changePropertyDatetimeValue handles the /incidents/{id}/property-datetime-value api endpoint. The
+// value is in milliseconds since the epoch; zero clears the property.
+func (h *IncidentHandler) changePropertyDatetimeValue(w http.ResponseWriter, r *http.Request) {
 	var params struct {
 		PropertyListItemID string `json:"property_id"`
-		FreetextValue      string `json:"value"`
+		Value              int64  `json:"value"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
 		return
 	}
 
-	if err := h.incidentService.ChangePropertyFreetextValue(vars["id"], userID, params.PropertyListItemID, params.FreetextValue); err != nil {
-		HandleError(w, err)
+	var values []string
+	if params.Value != 0 {
+		values = []string{strconv.FormatInt(params.Value, 10)}
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, values)
+}
+
+// changePropertyUserValue handles the /incidents/{id}/property-user-value api endpoint.
+func (h *IncidentHandler) changePropertyUserValue(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PropertyListItemID string   `json:"property_id"`
+		UserIDs            []string `json:"user_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, params.UserIDs)
+}
+
+// changePropertyChannelValue handles the /incidents/{id}/property-channel-value api endpoint.
+func (h *IncidentHandler) changePropertyChannelValue(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PropertyListItemID string `json:"property_id"`
+		ChannelID          string `json:"channel_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, []string{params.ChannelID})
+}
+
+// changePropertyURLValue handles the /incidents/{id}/property-url-value api endpoint.
+func (h *IncidentHandler) changePropertyURLValue(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PropertyListItemID string `json:"property_id"`
+		Value              string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	h.changePropertyValues(w, r, params.PropertyListItemID, []string{params.Value})
+}
+
+func (h *IncidentHandler) changePropertyValues(w http.ResponseWriter, r *http.Request, propertyID string, values []string) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if err := h.incidents(r).ChangePropertyValues(vars["id"], userID, propertyID, values); err != nil {
+		h.handlePropertyError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *IncidentHandler) handlePropertyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, playbook.ErrInvalidPropertyValue) {
+		HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, incident.ErrNotFound) {
+		HandleErrorWithCode(w, http.StatusNotFound, "Not found", err)
+		return
+	}
+	HandleError(w, err)
+}
+
 // updateStatusDialog handles the POST /incidents/{id}/update-status-dialog endpoint, called when a
 // user submits the Update Status dialog.
 func (h *IncidentHandler) updateStatusDialog(w http.ResponseWriter, r *http.Request) {
@@ -637,18 +890,35 @@ func (h *IncidentHandler) updateStatusDialog(w http.ResponseWriter, r *http.Requ
 		options.Status = status.(string)
 	}
 
-	switch options.Status {
-	case incident.StatusActive:
-	case incident.StatusArchived:
-	case incident.StatusReported:
-	case incident.StatusResolved:
-		break
-	default:
-		HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", nil)
+	if override, ok := request.Submission[incident.DialogFieldOverrideExitCriteriaKey].(bool); ok {
+		options.OverrideExitCriteria = override
+	}
+
+	if err = incident.ValidateStatusUpdateOptions(&options); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "invalid status update", err)
 		return
 	}
 
-	err = h.incidentService.UpdateStatus(incidentID, userID, options)
+	err = h.incidentService.WithAuditSource(audit.SourceDialog).UpdateStatus(incidentID, userID, options)
+	var exitCriteriaErr *incident.ExitCriteriaError
+	if errors.As(err, &exitCriteriaErr) {
+		resp := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				incident.DialogFieldStatusKey: fmt.Sprintf("The exit criteria are not met: %s.", strings.Join(exitCriteriaErr.Unmet, "; ")),
+			},
+		}
+		_, _ = w.Write(resp.ToJson())
+		return
+	}
+	if errors.Is(err, incident.ErrPermission) {
+		resp := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				incident.DialogFieldOverrideExitCriteriaKey: "Only the commander or a system admin can override the exit criteria.",
+			},
+		}
+		_, _ = w.Write(resp.ToJson())
+		return
+	}
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -715,7 +985,7 @@ func (h *IncidentHandler) reminderButtonDismiss(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err = h.incidentService.RemoveReminderPost(incidentID); err != nil {
+	if err = h.incidents(r).RemoveReminderPost(incidentID); err != nil {
 		h.log.Errorf("reminderButtonDismiss: error removing reminder for channelID: %s; error: %s", requestData.ChannelId, err.Error())
 		HandleErrorWithCode(w, http.StatusBadRequest, "error removing reminder", err)
 		return
@@ -724,7 +994,7 @@ func (h *IncidentHandler) reminderButtonDismiss(w http.ResponseWriter, r *http.R
 	ReturnJSON(w, nil, http.StatusOK)
 }
 
-// getChecklistAutocomplete handles the GET /incidents/checklists-autocomplete api endpoint
+// getChecklistAutocomplete handles the GET /incidents/checklist-autocomplete api endpoint
 func (h *IncidentHandler) getChecklistAutocomplete(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	channelID := query.Get("channel_id")
@@ -750,6 +1020,32 @@ func (h *IncidentHandler) getChecklistAutocomplete(w http.ResponseWriter, r *htt
 	ReturnJSON(w, data, http.StatusOK)
 }
 
+// getChecklistsAutocomplete handles the GET /incidents/checklists-autocomplete api endpoint
+func (h *IncidentHandler) getChecklistsAutocomplete(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	channelID := query.Get("channel_id")
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	incidentID, err := h.incidentService.GetIncidentIDForChannel(channelID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err = permissions.ViewIncident(userID, incidentID, h.pluginAPI, h.incidentService); err != nil {
+		HandleErrorWithCode(w, http.StatusForbidden, "user does not have permissions", nil)
+		return
+	}
+
+	data, err := h.incidentService.GetChecklistsAutocomplete(incidentID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, data, http.StatusOK)
+}
+
 // getPropertylistAutocomplete handles the GET /incidents/propertylist-autocomplete api endpoint
 func (h *IncidentHandler) getPropertylistAutocomplete(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
@@ -804,7 +1100,7 @@ func (h *IncidentHandler) itemSetState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.incidentService.ModifyCheckedState(id, userID, params.NewState, checklistNum, itemNum); err != nil {
+	if err := h.incidents(r).ModifyCheckedState(id, userID, params.NewState, checklistNum, itemNum); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -835,7 +1131,7 @@ func (h *IncidentHandler) itemSetAssignee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.incidentService.SetAssignee(id, userID, params.AssigneeID, checklistNum, itemNum); err != nil {
+	if err := h.incidents(r).SetAssignee(id, userID, params.AssigneeID, checklistNum, itemNum); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -858,7 +1154,7 @@ func (h *IncidentHandler) itemRun(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	triggerID, err := h.incidentService.RunChecklistItemSlashCommand(incidentID, userID, checklistNum, itemNum)
+	triggerID, err := h.incidents(r).RunChecklistItemSlashCommand(incidentID, userID, checklistNum, itemNum)
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -890,7 +1186,7 @@ func (h *IncidentHandler) addChecklistItem(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.incidentService.AddChecklistItem(id, userID, checklistNum, checklistItem); err != nil {
+	if err := h.incidents(r).AddChecklistItem(id, userID, checklistNum, checklistItem); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -913,7 +1209,7 @@ func (h *IncidentHandler) itemDelete(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := h.incidentService.RemoveChecklistItem(id, userID, checklistNum, itemNum); err != nil {
+	if err := h.incidents(r).RemoveChecklistItem(id, userID, checklistNum, itemNum); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -945,7 +1241,7 @@ func (h *IncidentHandler) itemRename(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.incidentService.RenameChecklistItem(id, userID, checklistNum, itemNum, params.Title, params.Command); err != nil {
+	if err := h.incidents(r).RenameChecklistItem(id, userID, checklistNum, itemNum, params.Title, params.Command); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -972,7 +1268,7 @@ func (h *IncidentHandler) reorderChecklist(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.incidentService.MoveChecklistItem(id, userID, checklistNum, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
+	if err := h.incidents(r).MoveChecklistItem(id, userID, checklistNum, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -980,6 +1276,37 @@ func (h *IncidentHandler) reorderChecklist(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *IncidentHandler) checklistsBatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Operations []incident.ChecklistOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal batch operations", err)
+		return
+	}
+
+	if len(params.Operations) == 0 {
+		HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter: operations",
+			errors.New("at least one operation is required"))
+		return
+	}
+
+	if err := h.incidents(r).BatchChecklistOperations(id, userID, params.Operations); err != nil {
+		if errors.Is(err, incident.ErrInvalidChecklistOperation) {
+			HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
 func (h *IncidentHandler) propertylistItemDelete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -991,7 +1318,7 @@ func (h *IncidentHandler) propertylistItemDelete(w http.ResponseWriter, r *http.
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := h.incidentService.RemovePropertylistItem(id, userID, itemNum); err != nil {
+	if err := h.incidents(r).RemovePropertylistItem(id, userID, itemNum); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -1018,7 +1345,7 @@ func (h *IncidentHandler) addPropertylistItem(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.incidentService.AddPropertylistItem(id, userID, propertylistItem); err != nil {
+	if err := h.incidents(r).AddPropertylistItem(id, userID, propertylistItem); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -1041,7 +1368,7 @@ func (h *IncidentHandler) reorderPropertylistItem(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := h.incidentService.MovePropertylistItem(id, userID, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
+	if err := h.incidents(r).MovePropertylistItem(id, userID, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -1073,7 +1400,7 @@ func (h *IncidentHandler) updatePropertylistItem(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if err := h.incidentService.UpdatePropertylistItem(id, userID, itemNum, propertylistItem); err != nil {
+	if err := h.incidents(r).UpdatePropertylistItem(id, userID, itemNum, propertylistItem); err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -1113,6 +1440,226 @@ func (h *IncidentHandler) telemetryForIncident(w http.ResponseWriter, r *http.Re
 	_, _ = w.Write([]byte(`{"status":"OK"}`))
 }
 
+// getPlaybookDiff handles the GET /incidents/{id}/playbook-diff endpoint, previewing the changes
+// that saving the incident's checklists and properties would make to its source playbook. The
+// changes are relative to an empty playbook if the incident has no source playbook the user can
+// view.
+func (h *IncidentHandler) getPlaybookDiff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	theIncident, err := h.incidentService.GetIncident(vars["id"])
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var source playbook.Playbook
+	if sourcePlaybook, err := h.getSourcePlaybook(theIncident, userID); err != nil {
+		HandleError(w, err)
+		return
+	} else if sourcePlaybook != nil {
+		source = *sourcePlaybook
+	}
+
+	changes := playbook.Diff(source, playbookFromIncident(source, theIncident))
+	if changes == nil {
+		changes = []playbook.Change{}
+	}
+
+	result := struct {
+		PlaybookID      string            `json:"playbook_id"`
+		PlaybookVersion int64             `json:"playbook_version"`
+		Changes         []playbook.Change `json:"changes"`
+	}{
+		PlaybookID:      source.ID,
+		PlaybookVersion: source.Version,
+		Changes:         changes,
+	}
+	ReturnJSON(w, &result, http.StatusOK)
+}
+
+// saveAsPlaybook handles the POST /incidents/{id}/playbook endpoint, creating a new playbook from
+// the incident's checklists and properties. The settings of the source playbook, if the user can
+// view it, carry over.
+func (h *IncidentHandler) saveAsPlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		TeamID string `json:"team_id"`
+		Title  string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode playbook parameters", err)
+		return
+	}
+
+	theIncident, err := h.incidentService.GetIncident(vars["id"])
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	teamID := params.TeamID
+	if teamID == "" {
+		teamID = theIncident.TeamID
+	}
+	if !permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to create playbook on teamID %s",
+			userID,
+			teamID,
+		))
+		return
+	}
+
+	base := playbook.Playbook{
+		BroadcastChannelID:          theIncident.BroadcastChannelID,
+		ReminderMessageTemplate:     theIncident.ReminderMessageTemplate,
+		ReminderTimerDefaultSeconds: int64(theIncident.PreviousReminder.Seconds()),
+	}
+	source, err := h.getSourcePlaybook(theIncident, userID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+	if source != nil {
+		base.Description = source.Description
+		base.CreatePublicIncident = source.CreatePublicIncident
+	}
+	if teamID != theIncident.TeamID {
+		base.BroadcastChannelID = ""
+	}
+
+	newPlaybook := playbookFromIncident(base, theIncident)
+	newPlaybook.TeamID = teamID
+	newPlaybook.Title = strings.TrimSpace(params.Title)
+	if newPlaybook.Title == "" {
+		newPlaybook.Title = theIncident.Name
+	}
+	newPlaybook.RegenerateIDs()
+
+	id, err := h.playbooks(r).Create(newPlaybook, userID)
+	if err != nil {
+		handlePlaybookError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// updateSourcePlaybook handles the PUT /incidents/{id}/playbook endpoint, saving the incident's
+// checklists and properties into the playbook it was started from. When given, the version must
+// be the one previewed, so that later changes to the playbook are not overwritten unseen.
+func (h *IncidentHandler) updateSourcePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode playbook parameters", err)
+		return
+	}
+
+	theIncident, err := h.incidentService.GetIncident(vars["id"])
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if theIncident.PlaybookID == "" {
+		HandleErrorWithCode(w, http.StatusBadRequest, "The incident was not started from a playbook", nil)
+		return
+	}
+
+	source, err := h.playbookService.Get(theIncident.PlaybookID)
+	if errors.Is(err, playbook.ErrNotFound) {
+		HandleErrorWithCode(w, http.StatusNotFound, "The playbook of the incident no longer exists", err)
+		return
+	} else if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if !hasPlaybookRole(source, userID, playbook.RoleEditor, h.pluginAPI, h.log) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to edit playbook %s",
+			userID,
+			source.ID,
+		))
+		return
+	}
+
+	if params.Version != 0 && params.Version != source.Version {
+		HandleErrorWithCode(w, http.StatusConflict, "The playbook was changed since the preview", errors.Errorf(
+			"previewed version %d of playbook %s, but it is at version %d",
+			params.Version,
+			source.ID,
+			source.Version,
+		))
+		return
+	}
+
+	updated := playbookFromIncident(source, theIncident)
+	changes := playbook.Diff(source, updated)
+	if changes == nil {
+		changes = []playbook.Change{}
+	}
+
+	if err := h.playbooks(r).Update(updated, userID); err != nil {
+		handlePlaybookError(w, err)
+		return
+	}
+
+	result := struct {
+		Changes []playbook.Change `json:"changes"`
+	}{
+		Changes: changes,
+	}
+	ReturnJSON(w, &result, http.StatusOK)
+}
+
+// getSourcePlaybook returns the playbook the incident was started from, or nil if there is none
+// or the user can't view it.
+func (h *IncidentHandler) getSourcePlaybook(theIncident *incident.Incident, userID string) (*playbook.Playbook, error) {
+	if theIncident.PlaybookID == "" {
+		return nil, nil
+	}
+
+	source, err := h.playbookService.Get(theIncident.PlaybookID)
+	if errors.Is(err, playbook.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get playbook %s", theIncident.PlaybookID)
+	}
+
+	if !hasPlaybookRole(source, userID, playbook.RoleViewer, h.pluginAPI, h.log) && !source.IsSharedWith(theIncident.TeamID) {
+		return nil, nil
+	}
+
+	return &source, nil
+}
+
+// playbookFromIncident returns a copy of the base playbook with the incident's checklists and
+// properties, stripped of their states, assignees and values. The exit criteria of the incident
+// carry over, without the ones referencing removed items.
+func playbookFromIncident(base playbook.Playbook, theIncident *incident.Incident) playbook.Playbook {
+	pbook := base.Clone()
+	pbook.Checklists = playbook.StripChecklists(theIncident.Checklists)
+	pbook.Propertylist = playbook.StripPropertylist(theIncident.Propertylist)
+	pbook.ExitCriteria = theIncident.ExitCriteria.Prune(pbook.Checklists, pbook.Propertylist)
+	return pbook
+}
+
 func (h *IncidentHandler) postIncidentCreatedMessage(incdnt *incident.Incident, channelID string) error {
 	channel, err := h.pluginAPI.Channel.Get(incdnt.ChannelID)
 	if err != nil {
@@ -1129,9 +1676,14 @@ func (h *IncidentHandler) postIncidentCreatedMessage(incdnt *incident.Incident,
 
 // parseIncidentsFilterOptions is only for parsing. Put validation logic in incident.validateOptions.
 func parseIncidentsFilterOptions(u *url.URL) (*incident.FilterOptions, error) {
-	teamID := u.Query().Get("team_id")
-	if teamID == "" {
-		return nil, errors.New("bad parameter 'team_id'; 'team_id' is required")
+	// team_id may be repeated to get the incidents of several teams, or left out to get the
+	// incidents of all the teams
+	var teamID string
+	var teamIDs []string
+	if values := u.Query()["team_id"]; len(values) == 1 {
+		teamID = values[0]
+	} else {
+		teamIDs = values
 	}
 
 	pageParam := u.Query().Get("page")
@@ -1152,29 +1704,112 @@ func parseIncidentsFilterOptions(u *url.URL) (*incident.FilterOptions, error) {
 		return nil, errors.Wrapf(err, "bad parameter 'per_page'")
 	}
 
+	cursorParam := u.Query().Get("cursor")
+
 	sort := u.Query().Get("sort")
 	direction := u.Query().Get("direction")
 
-	status := u.Query().Get("status")
+	sortProperty := u.Query().Get("sort_property")
+
+	// status may be repeated to filter by any of several statuses
+	statuses := u.Query()["status"]
+
+	playbookID := u.Query().Get("playbook_id")
 
 	commanderID := u.Query().Get("commander_user_id")
 	searchTerm := u.Query().Get("search_term")
 
 	memberID := u.Query().Get("member_id")
 
+	fields := u.Query().Get("fields")
+
+	createdAfter, err := parseTimestampParam(u, "created_after")
+	if err != nil {
+		return nil, err
+	}
+	createdBefore, err := parseTimestampParam(u, "created_before")
+	if err != nil {
+		return nil, err
+	}
+	endedAfter, err := parseTimestampParam(u, "ended_after")
+	if err != nil {
+		return nil, err
+	}
+	endedBefore, err := parseTimestampParam(u, "ended_before")
+	if err != nil {
+		return nil, err
+	}
+
+	// property values are given as property[Title]=value, possibly repeated
+	var propertyValues map[string][]string
+	for key, values := range u.Query() {
+		if !strings.HasPrefix(key, "property[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if propertyValues == nil {
+			propertyValues = make(map[string][]string)
+		}
+		title := strings.TrimSuffix(strings.TrimPrefix(key, "property["), "]")
+		propertyValues[title] = append(propertyValues[title], values...)
+	}
+
 	return &incident.FilterOptions{
-		TeamID:      teamID,
-		Page:        page,
-		PerPage:     perPage,
-		Sort:        sort,
-		Direction:   direction,
-		Status:      status,
-		CommanderID: commanderID,
-		SearchTerm:  searchTerm,
-		MemberID:    memberID,
+		TeamID:         teamID,
+		TeamIDs:        teamIDs,
+		Page:           page,
+		PerPage:        perPage,
+		Cursor:         cursorParam,
+		Sort:           sort,
+		Direction:      direction,
+		SortProperty:   sortProperty,
+		Statuses:       statuses,
+		PlaybookID:     playbookID,
+		CreatedAfter:   createdAfter,
+		CreatedBefore:  createdBefore,
+		EndedAfter:     endedAfter,
+		EndedBefore:    endedBefore,
+		PropertyValues: propertyValues,
+		CommanderID:    commanderID,
+		SearchTerm:     searchTerm,
+		MemberID:       memberID,
+		Fields:         fields,
 	}, nil
 }
 
+// canViewTeams checks that the user can view all the teams the options are limited to, returning
+// the first team the user cannot view otherwise. Options that are not limited to some teams are
+// limited to the teams of the user by the store.
+func canViewTeams(userID string, options *incident.FilterOptions, pluginAPI *pluginapi.Client) (string, bool) {
+	teamIDs := options.TeamIDs
+	if options.TeamID != "" {
+		teamIDs = append([]string{options.TeamID}, teamIDs...)
+	}
+
+	for _, teamID := range teamIDs {
+		if !permissions.CanViewTeam(userID, teamID, pluginAPI) {
+			return teamID, false
+		}
+	}
+
+	return "", true
+}
+
+// parseTimestampParam parses the given query parameter as milliseconds since the epoch,
+// returning 0 if it is missing.
+func parseTimestampParam(u *url.URL, param string) (int64, error) {
+	value := u.Query().Get(param)
+	if value == "" {
+		return 0, nil
+	}
+
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "bad parameter '%s'", param)
+	}
+
+	return timestamp, nil
+}
+
 func sliceContains(strs []string, target string) bool {
 	for _, s := range strs {
 		if s == target {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -17,9 +18,13 @@ import (
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
 	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	mock_oncall "github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall/mocks"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	mock_playbook "github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook/mocks"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
@@ -32,6 +37,8 @@ func TestIncidents(t *testing.T) {
 	var logger *mock_poster.MockLogger
 	var playbookService *mock_playbook.MockService
 	var incidentService *mock_incident.MockService
+	var onCallService *mock_oncall.MockService
+	var auditService *mock_audit.MockService
 	var pluginAPI *plugintest.API
 	var client *pluginapi.Client
 	telemetryService := &telemetry.NoopTelemetry{}
@@ -43,10 +50,15 @@ func TestIncidents(t *testing.T) {
 		logger = mock_poster.NewMockLogger(mockCtrl)
 		playbookService = mock_playbook.NewMockService(mockCtrl)
 		incidentService = mock_incident.NewMockService(mockCtrl)
+		onCallService = mock_oncall.NewMockService(mockCtrl)
+		auditService = mock_audit.NewMockService(mockCtrl)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+		incidentService.EXPECT().WithAuditSource(gomock.Any()).Return(incidentService).AnyTimes()
+		playbookService.EXPECT().WithAuditSource(gomock.Any()).Return(playbookService).AnyTimes()
 		pluginAPI = &plugintest.API{}
 		client = pluginapi.NewClient(pluginAPI)
 		telemetryService = &telemetry.NoopTelemetry{}
-		NewIncidentHandler(handler.APIRouter, incidentService, playbookService, client, poster, logger, telemetryService)
+		NewIncidentHandler(handler.APIRouter, incidentService, playbookService, onCallService, client, poster, logger, telemetryService, auditService)
 	}
 
 	t.Run("create valid incident from dialog", func(t *testing.T) {
@@ -101,6 +113,123 @@ func TestIncidents(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	})
 
+	t.Run("create incident from dialog with the commander on call", func(t *testing.T) {
+		reset()
+
+		withSchedule := playbook.Playbook{
+			ID:                   "playbookid1",
+			Title:                "My Playbook",
+			TeamID:               "testTeamID",
+			CreatePublicIncident: true,
+			MemberIDs:            []string{"testUserID"},
+			OnCallScheduleID:     "scheduleID",
+		}
+
+		dialogRequest := model.SubmitDialogRequest{
+			TeamId: "testTeamID",
+			UserId: "testUserID",
+			State:  "{}",
+			Submission: map[string]interface{}{
+				incident.DialogFieldPlaybookIDKey: "playbookid1",
+				incident.DialogFieldNameKey:       "incidentName",
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(withSchedule, nil).
+			Times(1)
+
+		onCallService.EXPECT().
+			OnCall("scheduleID", gomock.Any()).
+			Return(oncall.OnCall{UserID: "onCallUserID"}, nil).
+			Times(1)
+
+		i := incident.Incident{
+			CommanderUserID: "onCallUserID",
+			TeamID:          dialogRequest.TeamId,
+			Name:            "incidentName",
+			PlaybookID:      "playbookid1",
+		}
+		retI := i
+		retI.ChannelID = "channelID"
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_CREATE_PUBLIC_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "onCallUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		poster.EXPECT().PublishWebsocketEventToUser(gomock.Any(), gomock.Any(), gomock.Any())
+		poster.EXPECT().EphemeralPost(gomock.Any(), gomock.Any(), gomock.Any())
+		incidentService.EXPECT().CreateIncident(&i, "testUserID", true).Return(&retI, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/dialog", bytes.NewBuffer(dialogRequest.ToJson()))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("create incident from dialog with the commander on call outside the team", func(t *testing.T) {
+		reset()
+
+		withSchedule := playbook.Playbook{
+			ID:                   "playbookid1",
+			Title:                "My Playbook",
+			TeamID:               "testTeamID",
+			CreatePublicIncident: true,
+			MemberIDs:            []string{"testUserID"},
+			OnCallScheduleID:     "scheduleID",
+		}
+
+		dialogRequest := model.SubmitDialogRequest{
+			TeamId: "testTeamID",
+			UserId: "testUserID",
+			State:  "{}",
+			Submission: map[string]interface{}{
+				incident.DialogFieldPlaybookIDKey: "playbookid1",
+				incident.DialogFieldNameKey:       "incidentName",
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(withSchedule, nil).
+			Times(1)
+
+		onCallService.EXPECT().
+			OnCall("scheduleID", gomock.Any()).
+			Return(oncall.OnCall{UserID: "onCallUserID"}, nil).
+			Times(1)
+
+		i := incident.Incident{
+			CommanderUserID: dialogRequest.UserId,
+			TeamID:          dialogRequest.TeamId,
+			Name:            "incidentName",
+			PlaybookID:      "playbookid1",
+		}
+		retI := i
+		retI.ChannelID = "channelID"
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_CREATE_PUBLIC_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "onCallUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+		poster.EXPECT().PublishWebsocketEventToUser(gomock.Any(), gomock.Any(), gomock.Any())
+		poster.EXPECT().EphemeralPost(gomock.Any(), gomock.Any(), gomock.Any())
+		incidentService.EXPECT().CreateIncident(&i, "testUserID", true).Return(&retI, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/dialog", bytes.NewBuffer(dialogRequest.ToJson()))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
 	t.Run("create valid incident from dialog with description", func(t *testing.T) {
 		reset()
 
@@ -440,6 +569,7 @@ func TestIncidents(t *testing.T) {
 		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
 		pluginAPI.On("GetPost", "privatePostID").Return(&model.Post{ChannelId: "privateChannelId"}, nil)
 		pluginAPI.On("HasPermissionToChannel", "testUserID", "privateChannelId", model.PERMISSION_READ_CHANNEL).Return(false)
+		pluginAPI.On("HasPermissionTo", "testUserID", model.PERMISSION_MANAGE_SYSTEM).Return(false)
 
 		testrecorder := httptest.NewRecorder()
 		testreq, err := http.NewRequest("POST", "/api/v0/incidents/dialog", bytes.NewBuffer(dialogRequest.ToJson()))
@@ -449,7 +579,11 @@ func TestIncidents(t *testing.T) {
 
 		resp := testrecorder.Result()
 		defer resp.Body.Close()
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		dialogResp := model.SubmitDialogResponseFromJson(resp.Body)
+		require.NotNil(t, dialogResp)
+		require.Contains(t, dialogResp.Errors[incident.DialogFieldNameKey], "userID cannot start incidents from the playbook")
 	})
 
 	t.Run("create valid incident", func(t *testing.T) {
@@ -633,6 +767,7 @@ func TestIncidents(t *testing.T) {
 			Name:            "incidentName",
 			ChannelID:       "channelID",
 			Checklists:      []playbook.Checklist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 			StatusPosts:     []incident.StatusPost{},
 			TimelineEvents:  []incident.TimelineEvent{},
 		}
@@ -732,6 +867,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      nil,
+			Propertylist:    playbook.Propertylist{},
 		}
 
 		pluginAPI.On("GetChannel", testIncident.ChannelID).
@@ -769,6 +905,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 			StatusPosts:     []incident.StatusPost{},
 			TimelineEvents:  []incident.TimelineEvent{},
 		}
@@ -813,7 +950,6 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      nil,
-			Propertylist:    playbook.Propertylist{},
 		}
 
 		pluginAPI.On("GetChannel", testIncident.ChannelID).
@@ -853,7 +989,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
-			Propertylist:    playbook.Propertylist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 			StatusPosts:     []incident.StatusPost{},
 			TimelineEvents:  []incident.TimelineEvent{},
 		}
@@ -900,7 +1036,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
-			Propertylist:    playbook.Propertylist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 			StatusPosts:     []incident.StatusPost{},
 			TimelineEvents:  []incident.TimelineEvent{},
 		}
@@ -945,7 +1081,6 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      nil,
-			Propertylist:    playbook.Propertylist{},
 		}
 
 		pluginAPI.On("GetChannel", testIncident.ChannelID).
@@ -983,7 +1118,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
-			Propertylist:    playbook.Propertylist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 		}
 
 		testIncidentMetadata := incident.Metadata{
@@ -1038,7 +1173,6 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      nil,
-			Propertylist:    playbook.Propertylist{},
 		}
 
 		pluginAPI.On("GetChannel", testIncident.ChannelID).
@@ -1078,7 +1212,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
-			Propertylist:    playbook.Propertylist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 		}
 
 		testIncidentMetadata := incident.Metadata{
@@ -1135,7 +1269,7 @@ func TestIncidents(t *testing.T) {
 			PostID:          "",
 			PlaybookID:      "",
 			Checklists:      []playbook.Checklist{},
-			Propertylist:    playbook.Propertylist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 		}
 
 		testIncidentMetadata := incident.Metadata{
@@ -1188,9 +1322,9 @@ func TestIncidents(t *testing.T) {
 			Name:            "incidentName1",
 			ChannelID:       "channelID1",
 			Checklists:      []playbook.Checklist{},
+			Propertylist:    playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 			StatusPosts:     []incident.StatusPost{},
 			TimelineEvents:  []incident.TimelineEvent{},
-			Propertylist:    playbook.Propertylist{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
@@ -1226,41 +1360,129 @@ func TestIncidents(t *testing.T) {
 		assert.Equal(t, expectedList, actualList)
 	})
 
-	t.Run("get empty list of incidents", func(t *testing.T) {
+	t.Run("get incidents with filters", func(t *testing.T) {
 		reset()
 
-		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		expectedOptions := incident.FilterOptions{
+			TeamID:        "testTeamID1",
+			Sort:          "property",
+			Direction:     "desc",
+			SortProperty:  "Impact",
+			Statuses:      []string{incident.StatusActive, incident.StatusReported},
+			PlaybookID:    "playbookID1",
+			CreatedAfter:  1000,
+			CreatedBefore: 2000,
+			EndedAfter:    3000,
+			PropertyValues: map[string][]string{
+				"Impact":   {"High", "Critical"},
+				"Severity": {"1"},
+			},
+			Fields: incident.FieldsSummary,
+		}
+		incidentService.EXPECT().
+			GetIncidents(gomock.Any(), expectedOptions).
+			Return(&incident.GetIncidentsResults{Items: []incident.Incident{}}, nil)
+
+		query := url.Values{}
+		query.Set("team_id", "testTeamID1")
+		query.Set("sort", "property")
+		query.Set("direction", "desc")
+		query.Set("sort_property", "Impact")
+		query.Add("status", incident.StatusActive)
+		query.Add("status", incident.StatusReported)
+		query.Set("playbook_id", "playbookID1")
+		query.Set("created_after", "1000")
+		query.Set("created_before", "2000")
+		query.Set("ended_after", "3000")
+		query.Add("property[Impact]", "High")
+		query.Add("property[Impact]", "Critical")
+		query.Set("property[Severity]", "1")
+		query.Set("fields", incident.FieldsSummary)
 
 		testrecorder := httptest.NewRecorder()
-		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=non-existent", nil)
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?"+query.Encode(), nil)
 		testreq.Header.Add("Mattermost-User-ID", "testUserID")
 		require.NoError(t, err)
 		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
 
 		resp := testrecorder.Result()
 		defer resp.Body.Close()
-		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 
-	t.Run("checklist autocomplete for a channel without permission to view", func(t *testing.T) {
+	t.Run("get incidents with an invalid date", func(t *testing.T) {
 		reset()
 
-		testIncident := incident.Incident{
-			ID:              "incidentID",
-			CommanderUserID: "testUserID",
-			TeamID:          "testTeamID",
-			Name:            "incidentName",
-			ChannelID:       "channelID",
-		}
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=testTeamID1&created_after=yesterday", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("get incidents of all teams", func(t *testing.T) {
+		reset()
 
-		incidentService.EXPECT().GetIncidentIDForChannel(testIncident.ChannelID).Return(testIncident.ID, nil)
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
-		incidentService.EXPECT().GetIncident(testIncident.ID).Return(&testIncident, nil)
-		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(false)
-		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Type: model.CHANNEL_PRIVATE}, nil)
+		team := &incident.TeamInfo{ID: "testTeamID1", Name: "team-1", DisplayName: "Team 1"}
+		incidentService.EXPECT().
+			GetIncidents(gomock.Any(), incident.FilterOptions{}).
+			Return(&incident.GetIncidentsResults{Items: []incident.Incident{{ID: "incidentID1", Team: team}}}, nil)
 
 		testrecorder := httptest.NewRecorder()
-		testreq, err := http.NewRequest("GET", "/api/v0/incidents/checklist-autocomplete?channel_id="+testIncident.ChannelID, nil)
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var actualList incident.GetIncidentsResults
+		err = json.NewDecoder(resp.Body).Decode(&actualList)
+		require.NoError(t, err)
+		require.Len(t, actualList.Items, 1)
+		assert.Equal(t, team, actualList.Items[0].Team)
+	})
+
+	t.Run("get incidents of several teams", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID1", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID2", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().
+			GetIncidents(gomock.Any(), incident.FilterOptions{TeamIDs: []string{"testTeamID1", "testTeamID2"}}).
+			Return(&incident.GetIncidentsResults{Items: []incident.Incident{}}, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=testTeamID1&team_id=testTeamID2", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("get incidents of several teams, one not viewable", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID1", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID2", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=testTeamID1&team_id=testTeamID2", nil)
 		testreq.Header.Add("Mattermost-User-ID", "testUserID")
 		require.NoError(t, err)
 		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
@@ -1269,4 +1491,587 @@ func TestIncidents(t *testing.T) {
 		defer resp.Body.Close()
 		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 	})
+
+	t.Run("get incidents with a cursor", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		pageCursor := cursor.Cursor{Sort: "CreateAt", Direction: "desc", Value: "1000", ID: "incidentID1"}.Encode()
+		nextCursor := cursor.Cursor{Sort: "CreateAt", Direction: "desc", Value: "900", ID: "incidentID2"}.Encode()
+		incidentService.EXPECT().
+			GetIncidents(gomock.Any(), incident.FilterOptions{
+				TeamID:    "testTeamID1",
+				PerPage:   10,
+				Cursor:    pageCursor,
+				Sort:      "create_at",
+				Direction: "desc",
+			}).
+			Return(&incident.GetIncidentsResults{HasMore: true, NextCursor: nextCursor, Items: []incident.Incident{}}, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=testTeamID1&per_page=10&sort=create_at&direction=desc&cursor="+pageCursor, nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var actualList incident.GetIncidentsResults
+		err = json.NewDecoder(resp.Body).Decode(&actualList)
+		require.NoError(t, err)
+		assert.Equal(t, nextCursor, actualList.NextCursor)
+	})
+
+	t.Run("get incidents with an invalid cursor", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().
+			GetIncidents(gomock.Any(), gomock.Any()).
+			Return(nil, errors.Wrap(cursor.ErrInvalid, "bad parameter 'cursor'"))
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=testTeamID1&cursor=garbage", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("search incidents", func(t *testing.T) {
+		reset()
+
+		teamID := model.NewId()
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		results := &incident.SearchResults{
+			Items: []incident.SearchResult{{
+				Incident: incident.Incident{ID: "incidentID1", Name: "Database outage"},
+				Score:    0.5,
+				Snippets: []incident.Snippet{{Field: incident.SearchFieldName, Text: "Database outage", HTML: "<mark>Database</mark> outage"}},
+			}},
+		}
+		incidentService.EXPECT().
+			SearchIncidents(gomock.Any(), incident.SearchOptions{TeamID: teamID, Terms: "database 100%", PerPage: incident.SearchPerPageDefault}).
+			Return(results, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents/search?team_id="+teamID+"&terms=database+100%25", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var actual struct {
+			Items []struct {
+				Snippets []incident.Snippet `json:"snippets"`
+			} `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		require.Len(t, actual.Items, 1)
+		require.Equal(t, results.Items[0].Snippets, actual.Items[0].Snippets)
+	})
+
+	t.Run("search incidents without terms", func(t *testing.T) {
+		reset()
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents/search?team_id="+model.NewId()+"&terms=%25%25", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("get empty list of incidents", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents?team_id=non-existent", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("checklist autocomplete for a channel without permission to view", func(t *testing.T) {
+		reset()
+
+		testIncident := incident.Incident{
+			ID:              "incidentID",
+			CommanderUserID: "testUserID",
+			TeamID:          "testTeamID",
+			Name:            "incidentName",
+			ChannelID:       "channelID",
+		}
+
+		incidentService.EXPECT().GetIncidentIDForChannel(testIncident.ChannelID).Return(testIncident.ID, nil)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		incidentService.EXPECT().GetIncident(testIncident.ID).Return(&testIncident, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(false)
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Type: model.CHANNEL_PRIVATE}, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents/checklist-autocomplete?channel_id="+testIncident.ChannelID, nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("checklists autocomplete", func(t *testing.T) {
+		reset()
+
+		incidentService.EXPECT().GetIncidentIDForChannel("channelID").Return("incidentID", nil)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().GetChecklistsAutocomplete("incidentID").Return([]model.AutocompleteListItem{
+			{Item: "0", Hint: `"Triage"`, HelpText: "4 items"},
+		}, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents/checklists-autocomplete?channel_id=channelID", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var items []model.AutocompleteListItem
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&items))
+		require.Equal(t, "0", items[0].Item)
+	})
+
+	t.Run("batch checklist operations", func(t *testing.T) {
+		reset()
+
+		operations := []incident.ChecklistOperation{
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 0, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationRemove, ChecklistNumber: 0, ItemNumber: 1},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().BatchChecklistOperations("incidentID", "testUserID", operations).Return(nil)
+
+		body, err := json.Marshal(map[string]interface{}{"operations": operations})
+		require.NoError(t, err)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/checklists/batch", bytes.NewBuffer(body))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("batch checklist operations with an invalid operation", func(t *testing.T) {
+		reset()
+
+		operations := []incident.ChecklistOperation{
+			{Type: "unknown", ChecklistNumber: 0, ItemNumber: 0},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().BatchChecklistOperations("incidentID", "testUserID", operations).
+			Return(errors.Wrap(incident.ErrInvalidChecklistOperation, "unknown operation type"))
+
+		body, err := json.Marshal(map[string]interface{}{"operations": operations})
+		require.NoError(t, err)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/checklists/batch", bytes.NewBuffer(body))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("change number property value", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().ChangePropertyValues("incidentID", "testUserID", "propertyID", []string{"42.5"}).Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/property-number-value",
+			bytes.NewBufferString(`{"property_id": "propertyID", "value": 42.5}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("change number property value out of range", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().ChangePropertyValues("incidentID", "testUserID", "propertyID", []string{"1000"}).
+			Return(errors.Wrap(playbook.ErrInvalidPropertyValue, "1000 is above the maximum of 100"))
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/property-number-value",
+			bytes.NewBufferString(`{"property_id": "propertyID", "value": 1000}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("change selection property value with legacy comma-joined ids", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().ChangePropertyValues("incidentID", "testUserID", "propertyID", []string{"1", "2"}).Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/property-selection-value",
+			bytes.NewBufferString(`{"property_id": "propertyID", "selection_id": "1,2"}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("remove property", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().RemovePropertylistItem("incidentID", "testUserID", 2).Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("DELETE", "/api/v0/incidents/incidentID/propertylist/2", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("update property", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		incidentService.EXPECT().UpdatePropertylistItem("incidentID", "testUserID", 1,
+			playbook.PropertylistItem{Title: "Severity", Type: playbook.PropertyTypeNumber}).Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/incidents/incidentID/propertylist/1",
+			bytes.NewBufferString(`{"title": " Severity ", "type": "Number"}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("update status dialog with unmet exit criteria", func(t *testing.T) {
+		reset()
+
+		testIncident := incident.Incident{ID: "incidentID", ChannelID: "channelID"}
+		unmet := []string{"property 'Root cause' must be filled in"}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PERMISSION_CREATE_POST).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&testIncident, nil)
+		incidentService.EXPECT().UpdateStatus("incidentID", "testUserID", incident.StatusUpdateOptions{
+			Status:               incident.StatusResolved,
+			Message:              "all done",
+			OverrideExitCriteria: false,
+		}).Return(&incident.ExitCriteriaError{Unmet: unmet})
+
+		dialogRequest := model.SubmitDialogRequest{
+			UserId: "testUserID",
+			Submission: map[string]interface{}{
+				incident.DialogFieldStatusKey:               incident.StatusResolved,
+				incident.DialogFieldMessageKey:              "all done",
+				incident.DialogFieldOverrideExitCriteriaKey: false,
+			},
+		}
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/update-status-dialog", bytes.NewBuffer(dialogRequest.ToJson()))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		dialogResponse := model.SubmitDialogResponseFromJson(resp.Body)
+		require.NotNil(t, dialogResponse)
+		assert.Equal(t, "The exit criteria are not met: property 'Root cause' must be filled in.",
+			dialogResponse.Errors[incident.DialogFieldStatusKey])
+	})
+
+	sourcePlaybook := playbook.Playbook{
+		ID:      "playbookID",
+		Title:   "Playbook",
+		TeamID:  "teamID",
+		Version: 3,
+		Checklists: []playbook.Checklist{{
+			Title: "Triage",
+			Items: []playbook.ChecklistItem{{Title: "Acknowledge"}, {Title: "Page"}},
+		}},
+	}
+	finishedIncident := incident.Incident{
+		ID:         "incidentID",
+		Name:       "Outage",
+		TeamID:     "teamID",
+		ChannelID:  "channelID",
+		PlaybookID: "playbookID",
+		Checklists: []playbook.Checklist{{
+			Title: "Triage",
+			Items: []playbook.ChecklistItem{
+				{Title: "Acknowledge", State: playbook.ChecklistItemStateClosed, AssigneeID: "testUserID"},
+				{Title: "Check the dashboards", State: playbook.ChecklistItemStateClosed},
+			},
+		}},
+	}
+
+	t.Run("preview playbook diff from incident", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&finishedIncident, nil)
+		playbookService.EXPECT().Get("playbookID").Return(sourcePlaybook, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/incidents/incidentID/playbook-diff", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result struct {
+			PlaybookID      string            `json:"playbook_id"`
+			PlaybookVersion int64             `json:"playbook_version"`
+			Changes         []playbook.Change `json:"changes"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, "playbookID", result.PlaybookID)
+		assert.Equal(t, int64(3), result.PlaybookVersion)
+		assert.Equal(t, []playbook.Change{
+			{Type: playbook.ChangeAdded, Target: playbook.ChangeTargetChecklistItem, Checklist: "Triage", Title: "Check the dashboards"},
+			{Type: playbook.ChangeRemoved, Target: playbook.ChangeTargetChecklistItem, Checklist: "Triage", Title: "Page"},
+		}, result.Changes)
+	})
+
+	t.Run("update source playbook from incident", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&finishedIncident, nil)
+		playbookService.EXPECT().Get("playbookID").Return(sourcePlaybook, nil)
+		playbookService.EXPECT().Update(gomock.Any(), "testUserID").DoAndReturn(func(updated playbook.Playbook, userID string) error {
+			assert.Equal(t, "playbookID", updated.ID)
+			assert.Equal(t, []playbook.ChecklistItem{
+				{Title: "Acknowledge"},
+				{Title: "Check the dashboards"},
+			}, updated.Checklists[0].Items)
+			return nil
+		})
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/incidents/incidentID/playbook", bytes.NewBufferString(`{"version": 3}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("update source playbook changed since the preview", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&finishedIncident, nil)
+		playbookService.EXPECT().Get("playbookID").Return(sourcePlaybook, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/incidents/incidentID/playbook", bytes.NewBufferString(`{"version": 2}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("update source playbook without editor role", func(t *testing.T) {
+		reset()
+
+		viewed := sourcePlaybook
+		viewed.Members = []playbook.Member{{UserID: "testUserID", Role: playbook.RoleViewer}}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&finishedIncident, nil).Times(2)
+		playbookService.EXPECT().Get("playbookID").Return(viewed, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/incidents/incidentID/playbook", bytes.NewBufferString(`{}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("save incident as playbook", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&finishedIncident, nil)
+		playbookService.EXPECT().Get("playbookID").Return(sourcePlaybook, nil)
+		playbookService.EXPECT().Create(gomock.Any(), "testUserID").DoAndReturn(func(created playbook.Playbook, userID string) (string, error) {
+			assert.Empty(t, created.ID)
+			assert.Equal(t, "Outage playbook", created.Title)
+			assert.Equal(t, "teamID", created.TeamID)
+			assert.Len(t, created.Checklists[0].Items, 2)
+			assert.Equal(t, playbook.ChecklistItemStateOpen, created.Checklists[0].Items[0].State)
+			assert.Empty(t, created.Checklists[0].Items[0].AssigneeID)
+			return "newPlaybookID", nil
+		})
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/playbook", bytes.NewBufferString(`{"title": "Outage playbook"}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v0/playbooks/newPlaybookID", resp.Header.Get("Location"))
+	})
+
+	t.Run("request commander handoff", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "newCommanderID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&incident.Incident{ID: "incidentID", TeamID: "teamID"}, nil)
+		incidentService.EXPECT().RequestCommanderHandoff("incidentID", "testUserID", "newCommanderID", "Replica is catching up.").Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/commander/handoff",
+			bytes.NewBufferString(`{"commander_id": "newCommanderID", "notes": "Replica is catching up."}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("request commander handoff as someone else than the commander", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "newCommanderID", "teamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		incidentService.EXPECT().GetIncident("incidentID").Return(&incident.Incident{ID: "incidentID", TeamID: "teamID"}, nil)
+		incidentService.EXPECT().RequestCommanderHandoff("incidentID", "testUserID", "newCommanderID", "").
+			Return(errors.Wrap(incident.ErrPermission, "only the commander can hand command over"))
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/commander/handoff",
+			bytes.NewBufferString(`{"commander_id": "newCommanderID"}`))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("accept commander handoff", func(t *testing.T) {
+		reset()
+
+		incidentService.EXPECT().AcceptCommanderHandoff("incidentID", "testUserID").Return(nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/commander/handoff/accept", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("decline commander handoff not addressed to the user", func(t *testing.T) {
+		reset()
+
+		incidentService.EXPECT().DeclineCommanderHandoff("incidentID", "testUserID").Return(incident.ErrNoCommanderHandoff)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/incidents/incidentID/commander/handoff/decline", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
 }
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/metrics"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// MetricsHandler is the API handler for the metrics of the plugin.
+type MetricsHandler struct {
+	metrics   *metrics.Metrics
+	pluginAPI *pluginapi.Client
+	log       bot.Logger
+}
+
+// NewMetricsHandler returns a new metrics api handler
+func NewMetricsHandler(router *mux.Router, m *metrics.Metrics, api *pluginapi.Client, log bot.Logger) *MetricsHandler {
+	handler := &MetricsHandler{
+		metrics:   m,
+		pluginAPI: api,
+		log:       log,
+	}
+
+	router.HandleFunc("/metrics", handler.getMetrics).Methods(http.MethodGet)
+
+	return handler
+}
+
+// getMetrics handles the GET /metrics endpoint, for system admins only.
+func (h *MetricsHandler) getMetrics(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if !permissions.IsAdmin(userID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to read the metrics", userID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	// The metrics that could be read are still worth returning.
+	if err := h.metrics.Write(w); err != nil {
+		h.log.Warnf("failed to write some of the metrics: %v", err)
+	}
+}
+
+// ObserveRequests records the route, method, status and duration of every request matching a
+// route of the REST API, authorized or not, in m.
+func (h *Handler) ObserveRequests(m *metrics.Metrics) {
+	h.root.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+			m.ObserveAPIRequest(route, r.Method, recorder.status, time.Since(start))
+		})
+	})
+}
+
+// statusRecorder remembers the status code of a response. It flushes and hijacks the connection
+// when the ResponseWriter it wraps can.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		s.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+	s.wroteHeader = true
+	return hijacker.Hijack()
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/metrics"
+)
+
+// parseMetrics returns the values of the samples in the text exposition format, keyed by their
+// name and labels as written.
+func parseMetrics(t *testing.T, text string) map[string]float64 {
+	t.Helper()
+
+	samples := make(map[string]float64)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		separator := strings.LastIndex(line, " ")
+		require.True(t, separator > 0, "malformed sample %q", line)
+
+		value, err := strconv.ParseFloat(line[separator+1:], 64)
+		require.NoError(t, err, line)
+		samples[line[:separator]] = value
+	}
+	require.NoError(t, scanner.Err())
+
+	return samples
+}
+
+func TestMetrics(t *testing.T) {
+	var handler *Handler
+	var pluginAPI *plugintest.API
+
+	reset := func() {
+		mockCtrl := gomock.NewController(t)
+		pluginMetrics := metrics.New()
+		handler = NewHandler()
+		handler.ObserveRequests(pluginMetrics)
+		pluginAPI = &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		logger := mock_poster.NewMockLogger(mockCtrl)
+		NewMetricsHandler(handler.APIRouter, pluginMetrics, client, logger)
+	}
+
+	get := func(path, userID string) *http.Response {
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		if userID != "" {
+			testreq.Header.Add("Mattermost-User-ID", userID)
+		}
+		handler.ServeHTTP(testrecorder, testreq, "")
+
+		return testrecorder.Result()
+	}
+
+	t.Run("get metrics as admin", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		get("/api/v0/metrics", "").Body.Close()
+
+		resp := get("/api/v0/metrics", "testuserid")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		var body strings.Builder
+		_, err := bufio.NewReader(resp.Body).WriteTo(&body)
+		require.NoError(t, err)
+		samples := parseMetrics(t, body.String())
+
+		require.Equal(t, float64(1), samples[`incident_collaboration_api_requests_total{route="/api/v0/metrics",method="GET",status="401"}`])
+		require.Equal(t, float64(1), samples[`incident_collaboration_api_request_duration_seconds_count{route="/api/v0/metrics",method="GET"}`])
+		require.Contains(t, samples, "incident_collaboration_reminders_fired_total")
+	})
+
+	t.Run("get metrics as non-admin", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		resp := get("/api/v0/metrics", "testuserid")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// The rejected request is itself recorded.
+		pluginAPI.ExpectedCalls = nil
+		pluginAPI.On("HasPermissionTo", "adminid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		resp = get("/api/v0/metrics", "adminid")
+		defer resp.Body.Close()
+		var body strings.Builder
+		_, err := bufio.NewReader(resp.Body).WriteTo(&body)
+		require.NoError(t, err)
+		require.Equal(t, float64(1), parseMetrics(t, body.String())[`incident_collaboration_api_requests_total{route="/api/v0/metrics",method="GET",status="403"}`])
+	})
+}
+
+// hijackableRecorder is a ResponseWriter whose connection can be hijacked.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestStatusRecorder(t *testing.T) {
+	t.Run("flushes the wrapped writer", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		var recorder http.ResponseWriter = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		flusher, ok := recorder.(http.Flusher)
+		require.True(t, ok)
+		flusher.Flush()
+		require.True(t, w.Flushed)
+
+		_, _, err := recorder.(http.Hijacker).Hijack()
+		require.Error(t, err)
+	})
+
+	t.Run("hijacks the wrapped writer", func(t *testing.T) {
+		w := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+		var recorder http.ResponseWriter = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		hijacker, ok := recorder.(http.Hijacker)
+		require.True(t, ok)
+		_, _, err := hijacker.Hijack()
+		require.NoError(t, err)
+		require.True(t, w.hijacked)
+	})
+}
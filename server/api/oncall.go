@@ -0,0 +1,360 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// OnCallHandler is the API handler for on-call schedules.
+type OnCallHandler struct {
+	onCallService oncall.Service
+	pluginAPI     *pluginapi.Client
+	log           bot.Logger
+}
+
+// NewOnCallHandler returns a new on-call api handler
+func NewOnCallHandler(router *mux.Router, onCallService oncall.Service, api *pluginapi.Client, log bot.Logger) *OnCallHandler {
+	handler := &OnCallHandler{
+		onCallService: onCallService,
+		pluginAPI:     api,
+		log:           log,
+	}
+
+	schedulesRouter := router.PathPrefix("/oncall/schedules").Subrouter()
+	schedulesRouter.HandleFunc("", handler.getSchedules).Methods(http.MethodGet)
+	schedulesRouter.HandleFunc("", handler.createSchedule).Methods(http.MethodPost)
+	schedulesRouter.HandleFunc("/autocomplete", handler.getSchedulesAutocomplete).Methods(http.MethodGet)
+
+	scheduleRouter := schedulesRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
+	scheduleRouter.HandleFunc("", handler.getSchedule).Methods(http.MethodGet)
+	scheduleRouter.HandleFunc("", handler.updateSchedule).Methods(http.MethodPut)
+	scheduleRouter.HandleFunc("", handler.deleteSchedule).Methods(http.MethodDelete)
+	scheduleRouter.HandleFunc("/oncall", handler.getOnCall).Methods(http.MethodGet)
+	scheduleRouter.HandleFunc("/overrides", handler.addOverride).Methods(http.MethodPost)
+	scheduleRouter.HandleFunc("/overrides/{override_id:[A-Za-z0-9]+}", handler.removeOverride).Methods(http.MethodDelete)
+
+	return handler
+}
+
+// canManageSchedules returns true if the user can create, change and delete the schedules of the
+// team, and override them for anyone: team and system admins.
+func (h *OnCallHandler) canManageSchedules(userID, teamID string) bool {
+	return h.pluginAPI.User.HasPermissionToTeam(userID, teamID, model.PERMISSION_MANAGE_TEAM)
+}
+
+// getViewableSchedule returns the schedule of the request, writing the error response if it
+// doesn't exist or the user can't view its team.
+func (h *OnCallHandler) getViewableSchedule(w http.ResponseWriter, r *http.Request) (oncall.Schedule, bool) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	schedule, err := h.onCallService.GetSchedule(mux.Vars(r)["id"])
+	if errors.Is(err, oncall.ErrNotFound) {
+		HandleErrorWithCode(w, http.StatusNotFound, "Schedule not found", err)
+		return oncall.Schedule{}, false
+	} else if err != nil {
+		HandleError(w, err)
+		return oncall.Schedule{}, false
+	}
+
+	if !permissions.CanViewTeam(userID, schedule.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to view the schedules of teamID %s", userID, schedule.TeamID))
+		return oncall.Schedule{}, false
+	}
+
+	return schedule, true
+}
+
+// checkLayerUsers writes an error and returns false if a user of the layers of the schedule is not
+// a member of its team, as they could otherwise take command of incidents they can't see.
+func (h *OnCallHandler) checkLayerUsers(w http.ResponseWriter, schedule oncall.Schedule) bool {
+	// A schedule without a team is rejected as malformed by the service.
+	if schedule.TeamID == "" {
+		return true
+	}
+
+	for i, layer := range schedule.Layers {
+		for _, layerUserID := range layer.UserIDs {
+			if !permissions.CanViewTeam(layerUserID, schedule.TeamID, h.pluginAPI) {
+				HandleErrorWithCode(w, http.StatusBadRequest, fmt.Sprintf("The users of layer %d must be members of the team", i), nil)
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func handleScheduleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, oncall.ErrMalformedSchedule) {
+		HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, oncall.ErrNotFound) {
+		HandleErrorWithCode(w, http.StatusNotFound, "Not found", err)
+		return
+	}
+	HandleError(w, err)
+}
+
+// getSchedules handles the GET /oncall/schedules?team_id=... endpoint.
+func (h *OnCallHandler) getSchedules(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	teamID := r.URL.Query().Get("team_id")
+
+	if !permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to view the schedules of teamID %s", userID, teamID))
+		return
+	}
+
+	schedules, err := h.onCallService.GetSchedulesForTeam(teamID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, schedules, http.StatusOK)
+}
+
+// getSchedulesAutocomplete handles the GET /oncall/schedules/autocomplete?team_id=... endpoint,
+// listing the schedules of the team for the slash command.
+func (h *OnCallHandler) getSchedulesAutocomplete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	teamID := r.URL.Query().Get("team_id")
+
+	if !permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "user does not have permissions to view team", nil)
+		return
+	}
+
+	schedules, err := h.onCallService.GetSchedulesForTeam(teamID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	list := make([]model.AutocompleteListItem, 0, len(schedules))
+	for _, schedule := range schedules {
+		list = append(list, model.AutocompleteListItem{
+			Item:     schedule.ID,
+			HelpText: schedule.Name,
+		})
+	}
+
+	ReturnJSON(w, list, http.StatusOK)
+}
+
+// createSchedule handles the POST /oncall/schedules endpoint.
+func (h *OnCallHandler) createSchedule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var schedule oncall.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode schedule", err)
+		return
+	}
+
+	if !h.canManageSchedules(userID, schedule.TeamID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to manage the schedules of teamID %s", userID, schedule.TeamID))
+		return
+	}
+
+	if !h.checkLayerUsers(w, schedule) {
+		return
+	}
+
+	id, err := h.onCallService.CreateSchedule(schedule)
+	if err != nil {
+		handleScheduleError(w, err)
+		return
+	}
+
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/oncall/schedules/%s", id))
+	ReturnJSON(w, &struct {
+		ID string `json:"id"`
+	}{ID: id}, http.StatusCreated)
+}
+
+// getSchedule handles the GET /oncall/schedules/{id} endpoint.
+func (h *OnCallHandler) getSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, schedule, http.StatusOK)
+}
+
+// updateSchedule handles the PUT /oncall/schedules/{id} endpoint.
+func (h *OnCallHandler) updateSchedule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.canManageSchedules(userID, schedule.TeamID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to manage the schedules of teamID %s", userID, schedule.TeamID))
+		return
+	}
+
+	var updated oncall.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode schedule", err)
+		return
+	}
+	updated.ID = schedule.ID
+
+	if !h.checkLayerUsers(w, updated) {
+		return
+	}
+
+	if err := h.onCallService.UpdateSchedule(updated); err != nil {
+		handleScheduleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteSchedule handles the DELETE /oncall/schedules/{id} endpoint.
+func (h *OnCallHandler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.canManageSchedules(userID, schedule.TeamID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to manage the schedules of teamID %s", userID, schedule.TeamID))
+		return
+	}
+
+	if err := h.onCallService.DeleteSchedule(schedule.ID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getOnCall handles the GET /oncall/schedules/{id}/oncall endpoint, telling who is on call now,
+// or at the time given in milliseconds by the at parameter.
+func (h *OnCallHandler) getOnCall(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	at := time.Now()
+	if param := r.URL.Query().Get("at"); param != "" {
+		millis, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter 'at': must be a time in milliseconds", err)
+			return
+		}
+		at = time.Unix(0, millis*int64(time.Millisecond))
+	}
+
+	onCall, err := schedule.OnCallAt(at)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, onCall, http.StatusOK)
+}
+
+// addOverride handles the POST /oncall/schedules/{id}/overrides endpoint. Everyone in the team
+// can put themselves on call, only admins can put others.
+func (h *OnCallHandler) addOverride(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	var override oncall.Override
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode override", err)
+		return
+	}
+	override.ScheduleID = schedule.ID
+	override.CreatorUserID = userID
+
+	if override.UserID != userID && !h.canManageSchedules(userID, schedule.TeamID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to put other users on call in teamID %s", userID, schedule.TeamID))
+		return
+	}
+
+	if !permissions.CanViewTeam(override.UserID, schedule.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "The user on call must be a member of the team", nil)
+		return
+	}
+
+	id, err := h.onCallService.AddOverride(override)
+	if err != nil {
+		handleScheduleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &struct {
+		ID string `json:"id"`
+	}{ID: id}, http.StatusCreated)
+}
+
+// removeOverride handles the DELETE /oncall/schedules/{id}/overrides/{override_id} endpoint. The
+// creator of an override and the user it puts on call can remove it, as well as admins.
+func (h *OnCallHandler) removeOverride(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	overrideID := mux.Vars(r)["override_id"]
+
+	schedule, ok := h.getViewableSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	var override *oncall.Override
+	for i := range schedule.Overrides {
+		if schedule.Overrides[i].ID == overrideID {
+			override = &schedule.Overrides[i]
+		}
+	}
+	if override == nil {
+		HandleErrorWithCode(w, http.StatusNotFound, "Override not found", nil)
+		return
+	}
+
+	if override.CreatorUserID != userID && override.UserID != userID && !h.canManageSchedules(userID, schedule.TeamID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to remove override %s", userID, overrideID))
+		return
+	}
+
+	if err := h.onCallService.RemoveOverride(schedule.ID, overrideID); err != nil {
+		handleScheduleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
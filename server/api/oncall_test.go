@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	mock_oncall "github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall/mocks"
+)
+
+func TestOnCall(t *testing.T) {
+	var mockCtrl *gomock.Controller
+	var handler *Handler
+	var logger *mock_poster.MockLogger
+	var onCallService *mock_oncall.MockService
+	var pluginAPI *plugintest.API
+	var client *pluginapi.Client
+
+	schedule := oncall.Schedule{
+		ID:       "scheduleID",
+		TeamID:   "testTeamID",
+		Name:     "Primary",
+		TimeZone: "UTC",
+		Layers: []oncall.Layer{{
+			UserIDs:     []string{"testUserID"},
+			Start:       "2020-11-02",
+			HandoffTime: "09:00",
+			ShiftDays:   7,
+		}},
+		Overrides: []oncall.Override{{
+			ID:            "overrideID",
+			ScheduleID:    "scheduleID",
+			UserID:        "otherUserID",
+			CreatorUserID: "otherUserID",
+			StartAt:       1,
+			EndAt:         2,
+		}},
+	}
+
+	reset := func() {
+		mockCtrl = gomock.NewController(t)
+		handler = NewHandler()
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		onCallService = mock_oncall.NewMockService(mockCtrl)
+		pluginAPI = &plugintest.API{}
+		client = pluginapi.NewClient(pluginAPI)
+		NewOnCallHandler(handler.APIRouter, onCallService, client, logger)
+	}
+
+	serve := func(method, url string, body interface{}) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+		require.NoError(t, err)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		return testrecorder.Result()
+	}
+
+	t.Run("get schedules of a team", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		onCallService.EXPECT().GetSchedulesForTeam("testTeamID").Return([]oncall.Schedule{schedule}, nil)
+
+		resp := serve(http.MethodGet, "/api/v0/oncall/schedules?team_id=testTeamID", nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var schedules []oncall.Schedule
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schedules))
+		assert.Equal(t, []oncall.Schedule{schedule}, schedules)
+	})
+
+	t.Run("get schedules of a team without permissions", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		resp := serve(http.MethodGet, "/api/v0/oncall/schedules?team_id=testTeamID", nil)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("create schedule as team admin", func(t *testing.T) {
+		reset()
+
+		newSchedule := schedule
+		newSchedule.ID = ""
+		newSchedule.Overrides = nil
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		onCallService.EXPECT().CreateSchedule(newSchedule).Return("scheduleID", nil)
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules", newSchedule)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v0/oncall/schedules/scheduleID", resp.Header.Get("Location"))
+	})
+
+	t.Run("create invalid schedule", func(t *testing.T) {
+		reset()
+
+		newSchedule := schedule
+		newSchedule.ID = ""
+		newSchedule.Overrides = nil
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		onCallService.EXPECT().CreateSchedule(newSchedule).Return("", errors.Wrap(oncall.ErrMalformedSchedule, "unknown time zone"))
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules", newSchedule)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("create schedule with a user outside the team", func(t *testing.T) {
+		reset()
+
+		newSchedule := schedule
+		newSchedule.ID = ""
+		newSchedule.Overrides = nil
+		newSchedule.Layers = []oncall.Layer{schedule.Layers[0]}
+		newSchedule.Layers[0].UserIDs = []string{"testUserID", "outsiderID"}
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "outsiderID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules", newSchedule)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("update schedule with a user outside the team", func(t *testing.T) {
+		reset()
+
+		updated := schedule
+		updated.Layers = []oncall.Layer{schedule.Layers[0]}
+		updated.Layers[0].UserIDs = []string{"outsiderID"}
+
+		onCallService.EXPECT().GetSchedule("scheduleID").Return(schedule, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "outsiderID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		resp := serve(http.MethodPut, "/api/v0/oncall/schedules/scheduleID", updated)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("create schedule as team member", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(false)
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules", schedule)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("get missing schedule", func(t *testing.T) {
+		reset()
+
+		onCallService.EXPECT().GetSchedule("missingID").Return(oncall.Schedule{}, oncall.ErrNotFound)
+
+		resp := serve(http.MethodGet, "/api/v0/oncall/schedules/missingID", nil)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("override themselves as team member", func(t *testing.T) {
+		reset()
+
+		override := oncall.Override{UserID: "testUserID", StartAt: 1000, EndAt: 2000}
+		expected := override
+		expected.ScheduleID = "scheduleID"
+		expected.CreatorUserID = "testUserID"
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		onCallService.EXPECT().GetSchedule("scheduleID").Return(schedule, nil)
+		onCallService.EXPECT().AddOverride(expected).Return("newOverrideID", nil)
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules/scheduleID/overrides", override)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("override someone else as team member", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(false)
+		onCallService.EXPECT().GetSchedule("scheduleID").Return(schedule, nil)
+
+		resp := serve(http.MethodPost, "/api/v0/oncall/schedules/scheduleID/overrides",
+			oncall.Override{UserID: "otherUserID", StartAt: 1000, EndAt: 2000})
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("remove the override of someone else as team member", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(false)
+		onCallService.EXPECT().GetSchedule("scheduleID").Return(schedule, nil)
+
+		resp := serve(http.MethodDelete, "/api/v0/oncall/schedules/scheduleID/overrides/overrideID", nil)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("remove the override of someone else as team admin", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", "testTeamID", model.PERMISSION_MANAGE_TEAM).Return(true)
+		onCallService.EXPECT().GetSchedule("scheduleID").Return(schedule, nil)
+		onCallService.EXPECT().RemoveOverride("scheduleID", "overrideID").Return(nil)
+
+		resp := serve(http.MethodDelete, "/api/v0/oncall/schedules/scheduleID/overrides/overrideID", nil)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
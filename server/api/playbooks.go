@@ -3,17 +3,22 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
@@ -21,14 +26,18 @@ import (
 // PlaybookHandler is the API handler.
 type PlaybookHandler struct {
 	playbookService playbook.Service
+	onCallService   oncall.Service
+	auditService    audit.Service
 	pluginAPI       *pluginapi.Client
 	log             bot.Logger
 }
 
 // NewPlaybookHandler returns a new playbook api handler
-func NewPlaybookHandler(router *mux.Router, playbookService playbook.Service, api *pluginapi.Client, log bot.Logger) *PlaybookHandler {
+func NewPlaybookHandler(router *mux.Router, playbookService playbook.Service, onCallService oncall.Service, auditService audit.Service, api *pluginapi.Client, log bot.Logger) *PlaybookHandler {
 	handler := &PlaybookHandler{
 		playbookService: playbookService,
+		onCallService:   onCallService,
+		auditService:    auditService,
 		pluginAPI:       api,
 		log:             log,
 	}
@@ -37,15 +46,25 @@ func NewPlaybookHandler(router *mux.Router, playbookService playbook.Service, ap
 	playbooksRouter.HandleFunc("", handler.createPlaybook).Methods(http.MethodPost)
 	playbooksRouter.HandleFunc("", handler.getPlaybooks).Methods(http.MethodGet)
 	playbooksRouter.HandleFunc("/autocomplete", handler.getPlaybooksAutoComplete).Methods(http.MethodGet)
+	playbooksRouter.HandleFunc("/validate", handler.validatePlaybook).Methods(http.MethodPost)
+	playbooksRouter.HandleFunc("/templates", handler.getTemplates).Methods(http.MethodGet)
+	playbooksRouter.HandleFunc("/templates/{name:[a-z0-9-]+}/install", handler.installTemplate).Methods(http.MethodPost)
 
 	playbookRouter := playbooksRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
 	playbookRouter.HandleFunc("", handler.getPlaybook).Methods(http.MethodGet)
 	playbookRouter.HandleFunc("", handler.updatePlaybook).Methods(http.MethodPut)
 	playbookRouter.HandleFunc("", handler.deletePlaybook).Methods(http.MethodDelete)
+	playbookRouter.HandleFunc("/fork", handler.forkPlaybook).Methods(http.MethodPost)
+	playbookRouter.HandleFunc("/duplicate", handler.duplicatePlaybook).Methods(http.MethodPost)
 
 	return handler
 }
 
+// playbooks returns the playbook service recording the changes requested by r in the audit log.
+func (h *PlaybookHandler) playbooks(r *http.Request) playbook.Service {
+	return h.playbookService.WithAuditSource(auditSource(r))
+}
+
 func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request) {
 	userID := r.Header.Get("Mattermost-User-ID")
 
@@ -63,6 +82,16 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := pbook.ExitCriteria.Validate(pbook.Checklists, pbook.Propertylist); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "invalid exit criteria", err)
+		return
+	}
+
+	if err := pbook.ValidateRoles(); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "invalid roles", err)
+		return
+	}
+
 	if pbook.BroadcastChannelID != "" &&
 		!h.pluginAPI.User.HasPermissionToChannel(userID, pbook.BroadcastChannelID, model.PERMISSION_CREATE_POST) {
 		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
@@ -82,9 +111,17 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	id, err := h.playbookService.Create(pbook, userID)
+	if pbook.IsShared() && !h.canPublish(w, pbook, userID) {
+		return
+	}
+
+	if !h.checkOnCallSchedule(w, pbook) {
+		return
+	}
+
+	id, err := h.playbooks(r).Create(pbook, userID)
 	if err != nil {
-		HandleError(w, err)
+		handlePlaybookError(w, err)
 		return
 	}
 
@@ -97,6 +134,25 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 	ReturnJSON(w, &result, http.StatusCreated)
 }
 
+// checkOnCallSchedule writes an error and returns false if the on-call schedule of the playbook,
+// if any, is not one of the team of the playbook.
+func (h *PlaybookHandler) checkOnCallSchedule(w http.ResponseWriter, pbook playbook.Playbook) bool {
+	if pbook.OnCallScheduleID == "" {
+		return true
+	}
+
+	schedule, err := h.onCallService.GetSchedule(pbook.OnCallScheduleID)
+	if errors.Is(err, oncall.ErrNotFound) || (err == nil && schedule.TeamID != pbook.TeamID) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "the on-call schedule must be one of the team of the playbook", err)
+		return false
+	} else if err != nil {
+		HandleError(w, err)
+		return false
+	}
+
+	return true
+}
+
 func (h *PlaybookHandler) getPlaybook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := r.Header.Get("Mattermost-User-ID")
@@ -107,7 +163,7 @@ func (h *PlaybookHandler) getPlaybook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.hasPermissionsToPlaybook(pbook, userID) {
+	if !h.canUsePlaybook(pbook, userID) {
 		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
 			"userID %s does not have permission to get playbook on teamID %s",
 			userID,
@@ -131,13 +187,24 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 	// Force parsed playbook id to be URL parameter id
 	pbook.ID = vars["id"]
 
+	if err := pbook.ExitCriteria.Validate(pbook.Checklists, pbook.Propertylist); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "invalid exit criteria", err)
+		return
+	}
+
+	if err := pbook.ValidateRoles(); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "invalid roles", err)
+		return
+	}
+
 	oldPlaybook, err := h.playbookService.Get(vars["id"])
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
 
-	if !h.hasPermissionsToPlaybook(oldPlaybook, userID) {
+	if !h.hasPermissionsToPlaybook(oldPlaybook, userID, playbook.RoleEditor) {
+		recordDenied(h.auditService, r, audit.TargetPlaybook, oldPlaybook.ID, oldPlaybook.TeamID, "update_playbook")
 		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
 			"userID %s does not have permission to update playbook on teamID %s",
 			userID,
@@ -146,6 +213,27 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if len(playbook.RoleChanges(oldPlaybook, pbook)) > 0 {
+		if !h.canManagePlaybook(oldPlaybook, userID) {
+			recordDenied(h.auditService, r, audit.TargetPlaybook, oldPlaybook.ID, oldPlaybook.TeamID, "update_playbook")
+			HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+				"userID %s does not have permission to change the members of playbook %s",
+				userID,
+				oldPlaybook.ID,
+			))
+			return
+		}
+
+		if oldPlaybook.HasOwner() && !pbook.HasOwner() {
+			HandleErrorWithCode(w, http.StatusBadRequest, "a playbook must keep at least one owner", nil)
+			return
+		}
+	}
+
+	if isPublishingChanged(oldPlaybook, pbook) && !h.canPublish(w, pbook, userID) {
+		return
+	}
+
 	if pbook.BroadcastChannelID != "" &&
 		pbook.BroadcastChannelID != oldPlaybook.BroadcastChannelID &&
 		!h.pluginAPI.User.HasPermissionToChannel(userID, pbook.BroadcastChannelID, model.PERMISSION_CREATE_POST) {
@@ -157,9 +245,13 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.playbookService.Update(pbook, userID)
+	if !h.checkOnCallSchedule(w, pbook) {
+		return
+	}
+
+	err = h.playbooks(r).Update(pbook, userID)
 	if err != nil {
-		HandleError(w, err)
+		handlePlaybookError(w, err)
 		return
 	}
 
@@ -176,7 +268,8 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !h.hasPermissionsToPlaybook(playbookToDelete, userID) {
+	if !h.canManagePlaybook(playbookToDelete, userID) {
+		recordDenied(h.auditService, r, audit.TargetPlaybook, playbookToDelete.ID, playbookToDelete.TeamID, "delete_playbook")
 		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
 			"userID %s does not have permission to delete playbook on teamID %s",
 			userID,
@@ -185,7 +278,7 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.playbookService.Delete(playbookToDelete, userID)
+	err = h.playbooks(r).Delete(playbookToDelete, userID)
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -194,6 +287,209 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// forkPlaybook handles the POST /playbooks/{id}/fork endpoint, copying a playbook the user can
+// use into one of their teams.
+func (h *PlaybookHandler) forkPlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		TeamID string `json:"team_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode fork parameters", err)
+		return
+	}
+
+	if !model.IsValidId(params.TeamID) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Provide a valid team ID", nil)
+		return
+	}
+
+	pbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if !h.canUsePlaybook(pbook, userID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to fork playbook %s",
+			userID,
+			pbook.ID,
+		))
+		return
+	}
+
+	if !permissions.CanViewTeam(userID, params.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to create playbook on teamID %s",
+			userID,
+			params.TeamID,
+		))
+		return
+	}
+
+	id, err := h.playbooks(r).Fork(pbook, params.TeamID, userID)
+	if err != nil {
+		handlePlaybookError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// validatePlaybook handles the POST /playbooks/validate endpoint, checking a playbook without
+// saving it. The errors and warnings are listed in the response, whether the playbook is valid or
+// not.
+func (h *PlaybookHandler) validatePlaybook(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var pbook playbook.Playbook
+	if err := json.NewDecoder(r.Body).Decode(&pbook); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode playbook", err)
+		return
+	}
+
+	if pbook.TeamID != "" && !permissions.CanViewTeam(userID, pbook.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to view teamID %s",
+			userID,
+			pbook.TeamID,
+		))
+		return
+	}
+
+	ReturnJSON(w, h.playbookService.Validate(pbook), http.StatusOK)
+}
+
+// duplicatePlaybook handles the POST /playbooks/{id}/duplicate endpoint, copying a playbook the
+// user can use into its own team, or another one, under a new title.
+func (h *PlaybookHandler) duplicatePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		TeamID string `json:"team_id"`
+		Title  string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode duplicate parameters", err)
+		return
+	}
+
+	if params.TeamID != "" && !model.IsValidId(params.TeamID) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Provide a valid team ID", nil)
+		return
+	}
+
+	pbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if !h.canUsePlaybook(pbook, userID) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to duplicate playbook %s",
+			userID,
+			pbook.ID,
+		))
+		return
+	}
+
+	teamID := params.TeamID
+	if teamID == "" {
+		teamID = pbook.TeamID
+	}
+	if !permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to create playbook on teamID %s",
+			userID,
+			teamID,
+		))
+		return
+	}
+
+	title := strings.TrimSpace(params.Title)
+	if title == "" {
+		title = "Copy of " + pbook.Title
+	}
+
+	id, err := h.playbooks(r).Duplicate(pbook, teamID, title, userID)
+	if err != nil {
+		handlePlaybookError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// getTemplates handles the GET /playbooks/templates endpoint, listing the starter playbooks.
+func (h *PlaybookHandler) getTemplates(w http.ResponseWriter, r *http.Request) {
+	ReturnJSON(w, playbook.Templates(), http.StatusOK)
+}
+
+// installTemplate handles the POST /playbooks/templates/{name}/install endpoint, creating a
+// playbook from a starter template in one of the user's teams.
+func (h *PlaybookHandler) installTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		TeamID string `json:"team_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode install parameters", err)
+		return
+	}
+
+	if !model.IsValidId(params.TeamID) {
+		HandleErrorWithCode(w, http.StatusBadRequest, "Provide a valid team ID", nil)
+		return
+	}
+
+	if _, err := playbook.GetTemplate(vars["name"]); err != nil {
+		HandleErrorWithCode(w, http.StatusNotFound, "Template not found", err)
+		return
+	}
+
+	if !permissions.CanViewTeam(userID, params.TeamID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to create playbook on teamID %s",
+			userID,
+			params.TeamID,
+		))
+		return
+	}
+
+	id, err := h.playbooks(r).InstallTemplate(vars["name"], params.TeamID, userID)
+	if err != nil {
+		handlePlaybookError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
 func (h *PlaybookHandler) getPlaybooks(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	teamID := params.Get("team_id")
@@ -227,7 +523,10 @@ func (h *PlaybookHandler) getPlaybooks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	playbookResults, err := h.playbookService.GetPlaybooksForTeam(requesterInfo, teamID, opts)
-	if err != nil {
+	if errors.Is(err, cursor.ErrInvalid) {
+		HandleErrorWithCode(w, http.StatusBadRequest, fmt.Sprintf("failed to get playbooks: %s", err.Error()), nil)
+		return
+	} else if err != nil {
 		HandleError(w, err)
 		return
 	}
@@ -250,6 +549,7 @@ func (h *PlaybookHandler) getPlaybooksAutoComplete(w http.ResponseWriter, r *htt
 		TeamID:          teamID,
 		UserIDtoIsAdmin: map[string]bool{userID: permissions.IsAdmin(userID, h.pluginAPI)},
 		MemberOnly:      true,
+		MinRole:         playbook.RoleRunner,
 	}
 
 	playbooksResult, err := h.playbookService.GetPlaybooksForTeam(requesterInfo, teamID, playbook.Options{})
@@ -270,19 +570,121 @@ func (h *PlaybookHandler) getPlaybooksAutoComplete(w http.ResponseWriter, r *htt
 	ReturnJSON(w, list, http.StatusOK)
 }
 
-func (h *PlaybookHandler) hasPermissionsToPlaybook(thePlaybook playbook.Playbook, userID string) bool {
-	if !permissions.CanViewTeam(userID, thePlaybook.TeamID, h.pluginAPI) {
+// hasPermissionsToPlaybook returns true if the user can view the playbook's team and has at least
+// the given role in the playbook. Admins have every role in every playbook.
+func (h *PlaybookHandler) hasPermissionsToPlaybook(thePlaybook playbook.Playbook, userID string, minRole playbook.Role) bool {
+	return hasPlaybookRole(thePlaybook, userID, minRole, h.pluginAPI, h.log)
+}
+
+// canManagePlaybook returns true if the user can delete the playbook and change its members. The
+// editors of a playbook without owner, created before roles existed, manage it.
+func (h *PlaybookHandler) canManagePlaybook(thePlaybook playbook.Playbook, userID string) bool {
+	if thePlaybook.HasOwner() {
+		return h.hasPermissionsToPlaybook(thePlaybook, userID, playbook.RoleOwner)
+	}
+	return h.hasPermissionsToPlaybook(thePlaybook, userID, playbook.RoleEditor)
+}
+
+// canUsePlaybook returns true if the user has permissions to the playbook, or if the playbook is
+// published to one of the user's teams.
+func (h *PlaybookHandler) canUsePlaybook(thePlaybook playbook.Playbook, userID string) bool {
+	if h.hasPermissionsToPlaybook(thePlaybook, userID, playbook.RoleViewer) {
+		return true
+	}
+
+	if thePlaybook.IsGlobal {
+		return true
+	}
+
+	for _, teamID := range thePlaybook.SharedTeamIDs {
+		if permissions.CanViewTeam(userID, teamID, h.pluginAPI) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canPublish checks that the user can publish the playbook to other teams, writing the error
+// response if not.
+func (h *PlaybookHandler) canPublish(w http.ResponseWriter, thePlaybook playbook.Playbook, userID string) bool {
+	if !permissions.IsAdmin(userID, h.pluginAPI) {
+		HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to publish playbooks to other teams",
+			userID,
+		))
 		return false
 	}
 
-	for _, memberID := range thePlaybook.MemberIDs {
-		if memberID == userID {
+	for _, teamID := range thePlaybook.SharedTeamIDs {
+		if !model.IsValidId(teamID) {
+			HandleErrorWithCode(w, http.StatusBadRequest, fmt.Sprintf("invalid shared team ID '%s'", teamID), nil)
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPublishingChanged returns true if the teams the playbook is published to are changed.
+func isPublishingChanged(oldPlaybook, newPlaybook playbook.Playbook) bool {
+	if oldPlaybook.IsGlobal != newPlaybook.IsGlobal || len(oldPlaybook.SharedTeamIDs) != len(newPlaybook.SharedTeamIDs) {
+		return true
+	}
+
+	for _, teamID := range newPlaybook.SharedTeamIDs {
+		if !sliceContains(oldPlaybook.SharedTeamIDs, teamID) {
 			return true
 		}
 	}
 
+	return false
+}
+
+// handlePlaybookError writes the errors found validating a playbook as a bad request, if err
+// holds them, a conflict if the external ID of the playbook is taken, or an internal error
+// otherwise.
+func handlePlaybookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, playbook.ErrDuplicateExternalID) {
+		HandleErrorWithCode(w, http.StatusConflict, "Another playbook of the team has the same external ID", err)
+		return
+	}
+
+	var validationErr *playbook.ValidationError
+	if !errors.As(err, &validationErr) {
+		HandleError(w, err)
+		return
+	}
+
+	logrus.Warn(err.Error())
+	result := struct {
+		Error    string                     `json:"error"`
+		Errors   []playbook.ValidationIssue `json:"errors"`
+		Warnings []playbook.ValidationIssue `json:"warnings"`
+	}{
+		Error:    "The playbook is invalid",
+		Errors:   validationErr.Result.Errors,
+		Warnings: validationErr.Result.Warnings,
+	}
+	if result.Warnings == nil {
+		result.Warnings = []playbook.ValidationIssue{}
+	}
+	ReturnJSON(w, &result, http.StatusBadRequest)
+}
+
+// hasPlaybookRole returns true if the user can view the playbook's team and has at least the
+// given role in the playbook, or is an admin.
+func hasPlaybookRole(thePlaybook playbook.Playbook, userID string, minRole playbook.Role, pluginAPI *pluginapi.Client, log bot.Logger) bool {
+	if !permissions.CanViewTeam(userID, thePlaybook.TeamID, pluginAPI) {
+		return false
+	}
+
+	if playbook.RoleOfUser(thePlaybook, userID, pluginAPI, log).AtLeast(minRole) {
+		return true
+	}
+
 	// Fallback to admin role that have access to all playbooks.
-	return h.pluginAPI.User.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM)
+	return pluginAPI.User.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM)
 }
 
 func parseGetPlaybooksOptions(u *url.URL) (playbook.Options, error) {
@@ -341,5 +743,6 @@ func parseGetPlaybooksOptions(u *url.URL) (playbook.Options, error) {
 		Direction: sortDirection,
 		Page:      page,
 		PerPage:   perPage,
+		Cursor:    params.Get("cursor"),
 	}, nil
 }
@@ -11,7 +11,11 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
 	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	mock_oncall "github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall/mocks"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	mock_playbook "github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook/mocks"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -45,7 +49,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs: []string{},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{},
 	}
 	withid := playbook.Playbook{
 		ID:     "testplaybookid",
@@ -61,7 +66,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs: []string{},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{},
 	}
 	withidBytes, err := json.Marshal(&withid)
 	require.NoError(t, err)
@@ -80,7 +86,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs: []string{"testuserid"},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{"testuserid"},
 	}
 	withMemberBytes, err := json.Marshal(&withMember)
 	require.NoError(t, err)
@@ -98,6 +105,7 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Propertylist:       playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 		MemberIDs:          []string{},
 		BroadcastChannelID: "nonemptychannelid",
 	}
@@ -114,6 +122,7 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Propertylist:       playbook.Propertylist{Items: []playbook.PropertylistItem{}},
 		MemberIDs:          []string{},
 		BroadcastChannelID: "nonemptychannelid",
 	}
@@ -122,6 +131,8 @@ func TestPlaybooks(t *testing.T) {
 	var handler *Handler
 	var logger *mock_poster.MockLogger
 	var playbookService *mock_playbook.MockService
+	var onCallService *mock_oncall.MockService
+	var auditService *mock_audit.MockService
 	var pluginAPI *plugintest.API
 	var client *pluginapi.Client
 
@@ -129,10 +140,14 @@ func TestPlaybooks(t *testing.T) {
 		mockCtrl = gomock.NewController(t)
 		handler = NewHandler()
 		playbookService = mock_playbook.NewMockService(mockCtrl)
+		playbookService.EXPECT().WithAuditSource(gomock.Any()).Return(playbookService).AnyTimes()
+		onCallService = mock_oncall.NewMockService(mockCtrl)
+		auditService = mock_audit.NewMockService(mockCtrl)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 		pluginAPI = &plugintest.API{}
 		client = pluginapi.NewClient(pluginAPI)
 		logger = mock_poster.NewMockLogger(mockCtrl)
-		NewPlaybookHandler(handler.APIRouter, playbookService, client, logger)
+		NewPlaybookHandler(handler.APIRouter, playbookService, onCallService, auditService, client, logger)
 	}
 
 	t.Run("create playbook", func(t *testing.T) {
@@ -156,6 +171,126 @@ func TestPlaybooks(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	})
 
+	t.Run("create playbook with the on-call schedule of another team", func(t *testing.T) {
+		reset()
+
+		withSchedule := playbooktest
+		withSchedule.OnCallScheduleID = "scheduleid"
+
+		onCallService.EXPECT().
+			GetSchedule("scheduleid").
+			Return(oncall.Schedule{ID: "scheduleid", TeamID: "otherteamid"}, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(withSchedule))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("create invalid playbook", func(t *testing.T) {
+		reset()
+
+		issues := playbook.ValidationResult{
+			Errors:   []playbook.ValidationIssue{{Field: "checklists[0].items[0].command", Message: "there is no slash command /genie in the team"}},
+			Warnings: []playbook.ValidationIssue{{Field: "members[1]", Message: "@bob is deactivated"}},
+		}
+
+		playbookService.EXPECT().
+			Create(playbooktest, "testuserid").
+			Return("", &playbook.ValidationError{Result: issues}).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(playbooktest))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var result playbook.ValidationResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, issues, result)
+	})
+
+	t.Run("create playbook with a duplicate external ID", func(t *testing.T) {
+		reset()
+
+		playbookService.EXPECT().
+			Create(playbooktest, "testuserid").
+			Return("", errors.Wrap(playbook.ErrDuplicateExternalID, "playbook abc of the team already has the external ID 'sev1'")).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(playbooktest))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("validate playbook", func(t *testing.T) {
+		reset()
+
+		issues := playbook.ValidationResult{
+			Warnings: []playbook.ValidationIssue{{Field: "members[1]", Message: "@bob is deactivated"}},
+		}
+
+		playbookService.EXPECT().
+			Validate(playbooktest).
+			Return(issues).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/validate", jsonPlaybookReader(playbooktest))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		result, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"errors":[],"warnings":[{"field":"members[1]","message":"@bob is deactivated"}]}`, string(result))
+	})
+
+	t.Run("validate playbook of another team", func(t *testing.T) {
+		reset()
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/validate", jsonPlaybookReader(playbooktest))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
 	t.Run("create playbook, no premissions to broadcast channel", func(t *testing.T) {
 		reset()
 
@@ -211,6 +346,7 @@ func TestPlaybooks(t *testing.T) {
 			TotalCount int                 `json:"total_count"`
 			PageCount  int                 `json:"page_count"`
 			HasMore    bool                `json:"has_more"`
+			NextCursor string              `json:"next_cursor,omitempty"`
 			Items      []playbook.Playbook `json:"items"`
 		}{
 			TotalCount: 2,
@@ -259,6 +395,7 @@ func TestPlaybooks(t *testing.T) {
 			TotalCount int                 `json:"total_count"`
 			PageCount  int                 `json:"page_count"`
 			HasMore    bool                `json:"has_more"`
+			NextCursor string              `json:"next_cursor,omitempty"`
 			Items      []playbook.Playbook `json:"items"`
 		}{
 			TotalCount: 2,
@@ -328,6 +465,71 @@ func TestPlaybooks(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 
+	t.Run("update playbook with the on-call schedule of its team", func(t *testing.T) {
+		reset()
+
+		withSchedule := withid
+		withSchedule.OnCallScheduleID = "scheduleid"
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/testplaybookid", jsonPlaybookReader(withSchedule))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(playbooktest, nil).
+			Times(1)
+
+		onCallService.EXPECT().
+			GetSchedule("scheduleid").
+			Return(oncall.Schedule{ID: "scheduleid", TeamID: "testteamid"}, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Update(withSchedule, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("update playbook with an unknown on-call schedule", func(t *testing.T) {
+		reset()
+
+		withSchedule := withid
+		withSchedule.OnCallScheduleID = "scheduleid"
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/testplaybookid", jsonPlaybookReader(withSchedule))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(playbooktest, nil).
+			Times(1)
+
+		onCallService.EXPECT().
+			GetSchedule("scheduleid").
+			Return(oncall.Schedule{}, oncall.ErrNotFound).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("update playbook but no premissions in broadcast channel", func(t *testing.T) {
 		reset()
 
@@ -489,6 +691,28 @@ func TestPlaybooks(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 	})
 
+	t.Run("get playbooks with an invalid cursor", func(t *testing.T) {
+		reset()
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/playbooks?team_id=testteamid&cursor=garbage", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+		playbookService.EXPECT().
+			GetPlaybooksForTeam(gomock.Any(), "testteamid", gomock.Any()).
+			Return(playbook.GetPlaybooksResults{}, cursor.ErrInvalid).
+			Times(1)
+
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("update playbooks no team permission", func(t *testing.T) {
 		reset()
 
@@ -685,6 +909,443 @@ func TestPlaybooks(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 	})
 
+	t.Run("create shared playbook by non-admin", func(t *testing.T) {
+		reset()
+
+		shared := playbooktest
+		shared.IsGlobal = true
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(shared))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("create shared playbook by admin", func(t *testing.T) {
+		reset()
+
+		shared := playbooktest
+		shared.SharedTeamIDs = []string{model.NewId()}
+
+		playbookService.EXPECT().
+			Create(shared, "testuserid").
+			Return(model.NewId(), nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(shared))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("update playbook sharing by non-admin member", func(t *testing.T) {
+		reset()
+
+		shared := withMember
+		shared.IsGlobal = true
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/playbookwithmember", jsonPlaybookReader(shared))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("get shared playbook by non-member of another team", func(t *testing.T) {
+		reset()
+
+		shared := withMember
+		shared.SharedTeamIDs = []string{"otherteamid"}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(shared, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "otherteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/playbooks/playbookwithmember", nil)
+		testreq.Header.Add("Mattermost-User-ID", "unknownMember")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("fork shared playbook", func(t *testing.T) {
+		reset()
+
+		shared := withMember
+		shared.IsGlobal = true
+		teamID := model.NewId()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(shared, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Fork(shared, teamID, "unknownMember").
+			Return("forkedplaybookid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", teamID, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/playbookwithmember/fork", bytes.NewBufferString(fmt.Sprintf(`{"team_id": "%s"}`, teamID)))
+		testreq.Header.Add("Mattermost-User-ID", "unknownMember")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v0/playbooks/forkedplaybookid", resp.Header.Get("Location"))
+	})
+
+	t.Run("fork playbook no team permission", func(t *testing.T) {
+		reset()
+
+		teamID := model.NewId()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", teamID, model.PERMISSION_LIST_TEAM_CHANNELS).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/playbookwithmember/fork", bytes.NewBufferString(fmt.Sprintf(`{"team_id": "%s"}`, teamID)))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("duplicate playbook", func(t *testing.T) {
+		reset()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Duplicate(withMember, "testteamid", "Copy of "+withMember.Title, "testuserid").
+			Return("duplicatedplaybookid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/playbookwithmember/duplicate", bytes.NewBufferString("{}"))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v0/playbooks/duplicatedplaybookid", resp.Header.Get("Location"))
+	})
+
+	t.Run("duplicate playbook into another team with a title", func(t *testing.T) {
+		reset()
+
+		teamID := model.NewId()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Duplicate(withMember, teamID, "Our playbook", "testuserid").
+			Return("duplicatedplaybookid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", teamID, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/playbookwithmember/duplicate", bytes.NewBufferString(fmt.Sprintf(`{"team_id": "%s", "title": "Our playbook"}`, teamID)))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("duplicate playbook by non-member", func(t *testing.T) {
+		reset()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "unknownMember", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/playbookwithmember/duplicate", bytes.NewBufferString("{}"))
+		testreq.Header.Add("Mattermost-User-ID", "unknownMember")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("get templates", func(t *testing.T) {
+		reset()
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/playbooks/templates", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var templates []playbook.Template
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&templates))
+		assert.Len(t, templates, len(playbook.Templates()))
+	})
+
+	t.Run("install template", func(t *testing.T) {
+		reset()
+
+		teamID := model.NewId()
+
+		playbookService.EXPECT().
+			InstallTemplate("outage", teamID, "testuserid").
+			Return("installedplaybookid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", teamID, model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/templates/outage/install", bytes.NewBufferString(fmt.Sprintf(`{"team_id": "%s"}`, teamID)))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v0/playbooks/installedplaybookid", resp.Header.Get("Location"))
+	})
+
+	t.Run("install unknown template", func(t *testing.T) {
+		reset()
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks/templates/unknown/install", bytes.NewBufferString(fmt.Sprintf(`{"team_id": "%s"}`, model.NewId())))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("update playbook by viewer", func(t *testing.T) {
+		reset()
+
+		viewed := withMember
+		viewed.Members = []playbook.Member{{UserID: "testuserid", Role: playbook.RoleViewer}}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(viewed, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/playbookwithmember", jsonPlaybookReader(viewed))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("update playbook members by editor of an owned playbook", func(t *testing.T) {
+		reset()
+
+		owned := withMember
+		owned.MemberIDs = nil
+		owned.Members = []playbook.Member{
+			{UserID: "testuserid", Role: playbook.RoleEditor},
+			{UserID: "owneruserid", Role: playbook.RoleOwner},
+		}
+		updated := owned
+		updated.Members = append([]playbook.Member{}, owned.Members...)
+		updated.Members[0].Role = playbook.RoleOwner
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(owned, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/playbookwithmember", jsonPlaybookReader(updated))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("update playbook members by owner, removing the last owner", func(t *testing.T) {
+		reset()
+
+		owned := withMember
+		owned.MemberIDs = nil
+		owned.Members = []playbook.Member{{UserID: "testuserid", Role: playbook.RoleOwner}}
+		updated := owned
+		updated.Members = []playbook.Member{{UserID: "testuserid", Role: playbook.RoleEditor}}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(owned, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/playbookwithmember", jsonPlaybookReader(updated))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("update playbook with an invalid role", func(t *testing.T) {
+		reset()
+
+		updated := withMember
+		updated.Members = []playbook.Member{{UserID: "testuserid", Role: "admin"}}
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("PUT", "/api/v0/playbooks/playbookwithmember", jsonPlaybookReader(updated))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("delete playbook by editor of an owned playbook", func(t *testing.T) {
+		reset()
+
+		owned := withMember
+		owned.Members = []playbook.Member{{UserID: "owneruserid", Role: playbook.RoleOwner}}
+		owned.MemberIDs = []string{"testuserid", "owneruserid"}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(owned, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("DELETE", "/api/v0/playbooks/playbookwithmember", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("get playbook by group member", func(t *testing.T) {
+		reset()
+
+		withGroup := withMember
+		withGroup.Groups = []playbook.GroupMember{{GroupID: "testgroupid", Role: playbook.RoleViewer}}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withGroup, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_LIST_TEAM_CHANNELS).Return(true)
+		pluginAPI.On("GetGroupsForUser", "unknownMember").Return([]*model.Group{{Id: "testgroupid"}}, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/playbooks/playbookwithmember", nil)
+		testreq.Header.Add("Mattermost-User-ID", "unknownMember")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq, "testpluginid")
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
 	t.Run("get playbooks with members", func(t *testing.T) {
 		reset()
 
@@ -692,6 +1353,7 @@ func TestPlaybooks(t *testing.T) {
 			TotalCount int                 `json:"total_count"`
 			PageCount  int                 `json:"page_count"`
 			HasMore    bool                `json:"has_more"`
+			NextCursor string              `json:"next_cursor,omitempty"`
 			Items      []playbook.Playbook `json:"items"`
 		}{
 			TotalCount: 1,
@@ -830,6 +1492,8 @@ func TestSortingPlaybooks(t *testing.T) {
 	var handler *Handler
 	var logger *mock_poster.MockLogger
 	var playbookService *mock_playbook.MockService
+	var onCallService *mock_oncall.MockService
+	var auditService *mock_audit.MockService
 	var pluginAPI *plugintest.API
 	var client *pluginapi.Client
 
@@ -837,10 +1501,14 @@ func TestSortingPlaybooks(t *testing.T) {
 		mockCtrl = gomock.NewController(t)
 		handler = NewHandler()
 		playbookService = mock_playbook.NewMockService(mockCtrl)
+		playbookService.EXPECT().WithAuditSource(gomock.Any()).Return(playbookService).AnyTimes()
+		onCallService = mock_oncall.NewMockService(mockCtrl)
+		auditService = mock_audit.NewMockService(mockCtrl)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 		pluginAPI = &plugintest.API{}
 		client = pluginapi.NewClient(pluginAPI)
 		logger = mock_poster.NewMockLogger(mockCtrl)
-		NewPlaybookHandler(handler.APIRouter, playbookService, client, logger)
+		NewPlaybookHandler(handler.APIRouter, playbookService, onCallService, auditService, client, logger)
 	}
 
 	testData := []struct {
@@ -933,6 +1601,7 @@ func TestSortingPlaybooks(t *testing.T) {
 				TotalCount int                 `json:"total_count"`
 				PageCount  int                 `json:"page_count"`
 				HasMore    bool                `json:"has_more"`
+				NextCursor string              `json:"next_cursor,omitempty"`
 				Items      []playbook.Playbook `json:"items"`
 			}{
 				TotalCount: 3,
@@ -991,28 +1660,33 @@ func TestSortingPlaybooks(t *testing.T) {
 
 func TestPagingPlaybooks(t *testing.T) {
 	playbooktest1 := playbook.Playbook{
-		Title:      "A",
-		TeamID:     "testteamid",
-		Checklists: []playbook.Checklist{},
-		MemberIDs:  []string{},
+		Title:        "A",
+		TeamID:       "testteamid",
+		Checklists:   []playbook.Checklist{},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{},
 	}
 	playbooktest2 := playbook.Playbook{
-		Title:      "B",
-		TeamID:     "testteamid",
-		Checklists: []playbook.Checklist{},
-		MemberIDs:  []string{},
+		Title:        "B",
+		TeamID:       "testteamid",
+		Checklists:   []playbook.Checklist{},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{},
 	}
 	playbooktest3 := playbook.Playbook{
-		Title:      "C",
-		TeamID:     "testteamid",
-		Checklists: []playbook.Checklist{},
-		MemberIDs:  []string{},
+		Title:        "C",
+		TeamID:       "testteamid",
+		Checklists:   []playbook.Checklist{},
+		Propertylist: playbook.Propertylist{Items: []playbook.PropertylistItem{}},
+		MemberIDs:    []string{},
 	}
 
 	var mockCtrl *gomock.Controller
 	var handler *Handler
 	var logger *mock_poster.MockLogger
 	var playbookService *mock_playbook.MockService
+	var onCallService *mock_oncall.MockService
+	var auditService *mock_audit.MockService
 	var pluginAPI *plugintest.API
 	var client *pluginapi.Client
 
@@ -1020,10 +1694,14 @@ func TestPagingPlaybooks(t *testing.T) {
 		mockCtrl = gomock.NewController(t)
 		handler = NewHandler()
 		playbookService = mock_playbook.NewMockService(mockCtrl)
+		playbookService.EXPECT().WithAuditSource(gomock.Any()).Return(playbookService).AnyTimes()
+		onCallService = mock_oncall.NewMockService(mockCtrl)
+		auditService = mock_audit.NewMockService(mockCtrl)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 		pluginAPI = &plugintest.API{}
 		client = pluginapi.NewClient(pluginAPI)
 		logger = mock_poster.NewMockLogger(mockCtrl)
-		NewPlaybookHandler(handler.APIRouter, playbookService, client, logger)
+		NewPlaybookHandler(handler.APIRouter, playbookService, onCallService, auditService, client, logger)
 	}
 
 	testData := []struct {
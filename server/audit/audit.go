@@ -0,0 +1,232 @@
+// Package audit records who changed what in incidents and playbooks, where the change came from,
+// and the fields it changed, in an append-only log.
+package audit
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// Source is where a change comes from.
+type Source string
+
+// The sources of changes.
+const (
+	// SourceAPI is a request to the REST API, e.g. from the webapp or the command-line tool.
+	SourceAPI Source = "api"
+
+	// SourceSlashCommand is a /incident command.
+	SourceSlashCommand Source = "slash_command"
+
+	// SourceDialog is the submission of an interactive dialog.
+	SourceDialog Source = "dialog"
+
+	// SourcePlugin is a request to the REST API from another plugin.
+	SourcePlugin Source = "plugin"
+
+	// SourceSystem is the plugin itself, e.g. a reminder or the expiration of a handoff.
+	SourceSystem Source = "system"
+)
+
+// TargetType is the kind of entity a record is about.
+type TargetType string
+
+// The kinds of entities whose changes are recorded.
+const (
+	TargetIncident TargetType = "incident"
+	TargetPlaybook TargetType = "playbook"
+)
+
+// Record is an entry of the audit log: a change made to an incident or a playbook, or an attempt
+// to make one that was denied.
+type Record struct {
+	ID       string `json:"id"`
+	CreateAt int64  `json:"create_at"`
+
+	// ActorUserID is the user who made the change. The plugin acts as its bot.
+	ActorUserID string `json:"actor_user_id"`
+	Source      Source `json:"source"`
+
+	TargetType TargetType `json:"target_type"`
+	TargetID   string     `json:"target_id"`
+	TeamID     string     `json:"team_id"`
+
+	// Action names the operation, e.g. "update_status" or "set_assignee".
+	Action string `json:"action"`
+
+	// Changes are the fields changed by the operation. They are empty for denied attempts.
+	Changes []Change `json:"changes"`
+
+	// Denied is true if the actor was not allowed to make the change, which was not made.
+	Denied bool `json:"denied"`
+}
+
+// MarshalJSON customizes the JSON marshalling for Record by rendering nil Changes as an empty
+// slice instead.
+func (r Record) MarshalJSON() ([]byte, error) {
+	type Alias Record
+
+	if r.Changes == nil {
+		r.Changes = []Change{}
+	}
+
+	return json.Marshal(Alias(r))
+}
+
+// Change is the old and new values of a field changed by an operation. Field is the path of the
+// field in the JSON representation of the target, e.g. checklists[0].items[2].state. Old is
+// omitted when the field was added, and New when it was removed.
+type Change struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// PerPageDefault is the number of records in a page when none is given.
+const PerPageDefault = 100
+
+// PerPageMax is the maximum number of records in a page.
+const PerPageMax = 1000
+
+// FilterOptions selects the records to get. The records are sorted from the most recent to the
+// oldest.
+type FilterOptions struct {
+	ActorUserID string
+	Source      Source
+	TargetType  TargetType
+	TargetID    string
+	TeamID      string
+	Action      string
+
+	// DeniedOnly only gets the denied attempts.
+	DeniedOnly bool
+
+	// Since and Until filter by creation time, in milliseconds since the epoch. Both bounds are
+	// inclusive; 0 means no bound.
+	Since int64
+	Until int64
+
+	// Cursor is the next_cursor of the previous page, to get the page right after it.
+	Cursor  string
+	PerPage int
+}
+
+// The sort of the records, as recorded in the cursors.
+const (
+	cursorSort      = "CreateAt"
+	cursorDirection = "desc"
+)
+
+// Validate returns a copy of the options with the defaults filled in, or an error if they are
+// not valid.
+func (o FilterOptions) Validate() (FilterOptions, error) {
+	options := o
+
+	if options.PerPage == 0 {
+		options.PerPage = PerPageDefault
+	} else if options.PerPage < 0 || options.PerPage > PerPageMax {
+		return FilterOptions{}, errors.Errorf("bad parameter 'per_page': must be between 1 and %d", PerPageMax)
+	}
+
+	if options.ActorUserID != "" && !model.IsValidId(options.ActorUserID) {
+		return FilterOptions{}, errors.New("bad parameter 'actor_user_id': must be 26 characters or blank")
+	}
+	if options.TargetID != "" && !model.IsValidId(options.TargetID) {
+		return FilterOptions{}, errors.New("bad parameter 'target_id': must be 26 characters or blank")
+	}
+	if options.TeamID != "" && !model.IsValidId(options.TeamID) {
+		return FilterOptions{}, errors.New("bad parameter 'team_id': must be 26 characters or blank")
+	}
+
+	switch options.Source {
+	case "", SourceAPI, SourceSlashCommand, SourceDialog, SourcePlugin, SourceSystem:
+	default:
+		return FilterOptions{}, errors.Errorf("bad parameter 'source': unknown source '%s'", options.Source)
+	}
+
+	switch options.TargetType {
+	case "", TargetIncident, TargetPlaybook:
+	default:
+		return FilterOptions{}, errors.Errorf("bad parameter 'target_type': unknown target type '%s'", options.TargetType)
+	}
+
+	options.Action = strings.TrimSpace(options.Action)
+
+	if options.Since < 0 || options.Until < 0 || (options.Until != 0 && options.Since > options.Until) {
+		return FilterOptions{}, errors.New("bad parameters 'since' and 'until': must be a valid range")
+	}
+
+	if options.Cursor != "" {
+		c, err := cursor.Decode(options.Cursor)
+		if err != nil {
+			return FilterOptions{}, errors.Wrap(err, "bad parameter 'cursor'")
+		}
+		if err := c.Check(cursorSort, cursorDirection); err != nil {
+			return FilterOptions{}, errors.Wrap(err, "bad parameter 'cursor'")
+		}
+	}
+
+	return options, nil
+}
+
+// NextCursor returns the cursor of the page after the given last record of a page.
+func NextCursor(last Record) string {
+	return cursor.Cursor{
+		Sort:      cursorSort,
+		Direction: cursorDirection,
+		Value:     strconv.FormatInt(last.CreateAt, 10),
+		ID:        last.ID,
+	}.Encode()
+}
+
+// GetRecordsResults is a page of records.
+type GetRecordsResults struct {
+	Items   []Record `json:"items"`
+	HasMore bool     `json:"has_more"`
+
+	// NextCursor gets the next page when HasMore is true.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// MarshalJSON customizes the JSON marshalling for GetRecordsResults by rendering a nil Items as
+// an empty slice instead.
+func (r GetRecordsResults) MarshalJSON() ([]byte, error) {
+	type Alias GetRecordsResults
+
+	if r.Items == nil {
+		r.Items = []Record{}
+	}
+
+	return json.Marshal(Alias(r))
+}
+
+// Service records changes and gets the audit log.
+type Service interface {
+	// Record adds a record to the audit log, filling in its ID and creation time. The audit log
+	// must never prevent a change, so failures are logged rather than returned.
+	Record(record Record)
+
+	// GetRecords gets a page of the records matching the options.
+	GetRecords(options FilterOptions) (GetRecordsResults, error)
+
+	// DeleteExpired deletes the records older than the retention period of the configuration,
+	// if any, and returns how many were deleted.
+	DeleteExpired() (int64, error)
+}
+
+// Store is the append-only storage of the audit log.
+type Store interface {
+	// Create stores a new record.
+	Create(record Record) error
+
+	// GetRecords gets a page of the records matching the validated options.
+	GetRecords(options FilterOptions) (GetRecordsResults, error)
+
+	// DeleteBefore deletes the records created before createAt, and returns how many were deleted.
+	DeleteBefore(createAt int64) (int64, error)
+}
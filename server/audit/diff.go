@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Snapshot returns the JSON representation of v, to compare its state before and after a change
+// with Diff. It returns nil if v cannot be represented in JSON.
+func Snapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+// Diff returns the fields that differ between two snapshots, sorted by path. Objects are compared
+// field by field and arrays of the same length item by item; otherwise a value is changed as a
+// whole. A nil snapshot stands for a target that does not exist, before its creation or after its
+// deletion.
+func Diff(before, after json.RawMessage) []Change {
+	var oldValue, newValue interface{} = map[string]interface{}{}, map[string]interface{}{}
+	if before != nil {
+		if err := decode(before, &oldValue); err != nil {
+			return []Change{{Field: "", Old: before, New: after}}
+		}
+	}
+	if after != nil {
+		if err := decode(after, &newValue); err != nil {
+			return []Change{{Field: "", Old: before, New: after}}
+		}
+	}
+
+	var changes []Change
+	diffValues("", oldValue, newValue, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// decode unmarshals JSON keeping numbers as they are written.
+func decode(data json.RawMessage, v *interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func diffValues(path string, oldValue, newValue interface{}, changes *[]Change) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newValue.(map[string]interface{}); ok {
+			diffObjects(path, oldTyped, newTyped, changes)
+			return
+		}
+	case []interface{}:
+		if newTyped, ok := newValue.([]interface{}); ok && len(oldTyped) == len(newTyped) {
+			for i := range oldTyped {
+				diffValues(fmt.Sprintf("%s[%d]", path, i), oldTyped[i], newTyped[i], changes)
+			}
+			return
+		}
+	}
+
+	oldJSON, newJSON := Snapshot(oldValue), Snapshot(newValue)
+	if !bytes.Equal(oldJSON, newJSON) {
+		*changes = append(*changes, Change{Field: path, Old: oldJSON, New: newJSON})
+	}
+}
+
+func diffObjects(path string, oldObject, newObject map[string]interface{}, changes *[]Change) {
+	for key, oldField := range oldObject {
+		newField, ok := newObject[key]
+		if !ok {
+			*changes = append(*changes, Change{Field: fieldPath(path, key), Old: Snapshot(oldField)})
+			continue
+		}
+		diffValues(fieldPath(path, key), oldField, newField, changes)
+	}
+
+	for key, newField := range newObject {
+		if _, ok := oldObject[key]; !ok {
+			*changes = append(*changes, Change{Field: fieldPath(path, key), New: Snapshot(newField)})
+		}
+	}
+}
+
+func fieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
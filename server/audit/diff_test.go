@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	change := func(field, oldValue, newValue string) Change {
+		c := Change{Field: field}
+		if oldValue != "" {
+			c.Old = json.RawMessage(oldValue)
+		}
+		if newValue != "" {
+			c.New = json.RawMessage(newValue)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []Change
+	}{
+		{
+			name:     "no change",
+			before:   `{"name":"incident","checklists":[{"items":[{"state":""}]}]}`,
+			after:    `{"checklists":[{"items":[{"state":""}]}],"name":"incident"}`,
+			expected: nil,
+		},
+		{
+			name:   "fields of nested objects and arrays of the same length",
+			before: `{"name":"incident","checklists":[{"items":[{"state":"","title":"a"},{"state":""}]}]}`,
+			after:  `{"name":"renamed","checklists":[{"items":[{"state":"","title":"a"},{"state":"closed"}]}]}`,
+			expected: []Change{
+				change("checklists[0].items[1].state", `""`, `"closed"`),
+				change("name", `"incident"`, `"renamed"`),
+			},
+		},
+		{
+			name:   "arrays of different lengths change as a whole",
+			before: `{"member_ids":["a"]}`,
+			after:  `{"member_ids":["a","b"]}`,
+			expected: []Change{
+				change("member_ids", `["a"]`, `["a","b"]`),
+			},
+		},
+		{
+			name:   "added and removed fields",
+			before: `{"handoff":{"to":"a"},"old":1}`,
+			after:  `{"handoff":null,"new":2}`,
+			expected: []Change{
+				change("handoff", `{"to":"a"}`, `null`),
+				change("new", "", `2`),
+				change("old", `1`, ""),
+			},
+		},
+		{
+			name:   "numbers are kept as written",
+			before: `{"create_at":1612345678901234567}`,
+			after:  `{"create_at":1612345678901234568}`,
+			expected: []Change{
+				change("create_at", `1612345678901234567`, `1612345678901234568`),
+			},
+		},
+		{
+			name:   "creation",
+			before: "",
+			after:  `{"id":"a","title":"b"}`,
+			expected: []Change{
+				change("id", "", `"a"`),
+				change("title", "", `"b"`),
+			},
+		},
+		{
+			name:   "deletion",
+			before: `{"id":"a"}`,
+			after:  "",
+			expected: []Change{
+				change("id", `"a"`, ""),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after json.RawMessage
+			if tt.before != "" {
+				before = json.RawMessage(tt.before)
+			}
+			if tt.after != "" {
+				after = json.RawMessage(tt.after)
+			}
+
+			require.Equal(t, tt.expected, Diff(before, after))
+		})
+	}
+}
+
+func TestFilterOptionsValidate(t *testing.T) {
+	options, err := FilterOptions{}.Validate()
+	require.NoError(t, err)
+	require.Equal(t, PerPageDefault, options.PerPage)
+
+	_, err = FilterOptions{PerPage: PerPageMax + 1}.Validate()
+	require.Error(t, err)
+
+	_, err = FilterOptions{Source: "email"}.Validate()
+	require.Error(t, err)
+
+	_, err = FilterOptions{Since: 20, Until: 10}.Validate()
+	require.Error(t, err)
+
+	_, err = FilterOptions{Cursor: "not a cursor"}.Validate()
+	require.Error(t, err)
+
+	last := Record{ID: "abcdefghijklmnopqrstuvwxyz", CreateAt: 1000}
+	options, err = FilterOptions{Cursor: NextCursor(last), Source: SourceDialog}.Validate()
+	require.NoError(t, err)
+	require.Equal(t, SourceDialog, options.Source)
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
+)
+
+type service struct {
+	store         Store
+	log           bot.Logger
+	configService config.Service
+}
+
+// NewService returns a new audit service, keeping the records for the retention period of the
+// configuration.
+func NewService(store Store, log bot.Logger, configService config.Service) Service {
+	return &service{
+		store:         store,
+		log:           log,
+		configService: configService,
+	}
+}
+
+func (s *service) Record(record Record) {
+	record.ID = model.NewId()
+	record.CreateAt = model.GetMillis()
+
+	if err := s.store.Create(record); err != nil {
+		s.log.Errorf("failed to record action %s of user %s on %s %s in the audit log: %v",
+			record.Action, record.ActorUserID, record.TargetType, record.TargetID, err)
+	}
+}
+
+func (s *service) GetRecords(options FilterOptions) (GetRecordsResults, error) {
+	options, err := options.Validate()
+	if err != nil {
+		return GetRecordsResults{}, err
+	}
+
+	return s.store.GetRecords(options)
+}
+
+func (s *service) DeleteExpired() (int64, error) {
+	days := s.configService.GetConfiguration().AuditRetentionDays
+	if days <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	return s.store.DeleteBefore(model.GetMillisForTime(before))
+}
@@ -40,7 +40,7 @@ type Poster interface {
 
 	// DMWithAttachments posts a Direct Message that contains Slack attachments.
 	// Often used to include post actions.
-	DMWithAttachments(userID string, attachments ...*model.SlackAttachment) error
+	DMWithAttachments(userID string, attachments ...*model.SlackAttachment) (*model.Post, error)
 
 	// Ephemeral sends an ephemeral message to a user.
 	EphemeralPost(userID, channelID string, post *model.Post)
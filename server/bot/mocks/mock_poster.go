@@ -53,15 +53,16 @@ func (mr *MockPosterMockRecorder) DM(arg0, arg1 interface{}, arg2 ...interface{}
 }
 
 // DMWithAttachments mocks base method
-func (m *MockPoster) DMWithAttachments(arg0 string, arg1 ...*model.SlackAttachment) error {
+func (m *MockPoster) DMWithAttachments(arg0 string, arg1 ...*model.SlackAttachment) (*model.Post, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0}
 	for _, a := range arg1 {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "DMWithAttachments", varargs...)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DMWithAttachments indicates an expected call of DMWithAttachments
@@ -44,10 +44,13 @@ func (b *Bot) DM(userID, format string, args ...interface{}) error {
 
 // DMWithAttachments posts a Direct Message that contains Slack attachments.
 // Often used to include post actions.
-func (b *Bot) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) error {
-	post := model.Post{}
-	model.ParseSlackAttachment(&post, attachments)
-	return b.dm(userID, &post)
+func (b *Bot) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) (*model.Post, error) {
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, attachments)
+	if err := b.dm(userID, post); err != nil {
+		return nil, err
+	}
+	return post, nil
 }
 
 // Ephemeral sends an ephemeral message to a user
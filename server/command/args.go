@@ -0,0 +1,164 @@
+package command
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// argument is an argument of a command line, with the byte offset where it starts in the line.
+type argument struct {
+	value string
+	start int
+}
+
+// splitArgs splits a command line into its arguments. Arguments are separated by spaces, unless
+// they are quoted with double quotes, e.g. `/incident end "All good"`. A double quote only starts
+// a quoted argument at the beginning of a word or right after the = of an option, e.g.
+// `--playbook="Major Outage"`: apostrophes, backslashes and quotes within words are kept as typed.
+func splitArgs(line string) ([]argument, error) {
+	var args []argument
+	var current strings.Builder
+	inArg := false
+	quoted := false
+	start := 0
+
+	for i, c := range line {
+		switch {
+		case quoted:
+			if c == '"' {
+				quoted = false
+			} else {
+				current.WriteRune(c)
+			}
+		case unicode.IsSpace(c):
+			if inArg {
+				args = append(args, argument{value: current.String(), start: start})
+				current.Reset()
+				inArg = false
+			}
+		case !inArg:
+			inArg = true
+			start = i
+			if c == '"' {
+				quoted = true
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' && isOptionName(current.String()):
+			quoted = true
+		default:
+			current.WriteRune(c)
+		}
+	}
+
+	if quoted {
+		return nil, errors.New(`missing closing quote "`)
+	}
+	if inArg {
+		args = append(args, argument{value: current.String(), start: start})
+	}
+
+	return args, nil
+}
+
+// isOptionName returns true if arg is the name part of an option given as --name=value.
+func isOptionName(arg string) bool {
+	return strings.HasPrefix(arg, "--") && strings.HasSuffix(arg, "=") && strings.Count(arg, "=") == 1
+}
+
+// argValues returns the values of the arguments.
+func argValues(args []argument) []string {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.value)
+	}
+	return values
+}
+
+// remainder returns the text of the line from the argument at index i to its end, as typed, so
+// that free text keeps its spacing, newlines and quotes. A single quoted argument is returned
+// without its quotes.
+func remainder(line string, args []argument, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	if i == len(args)-1 {
+		return args[i].value
+	}
+	return strings.TrimSpace(line[args[i].start:])
+}
+
+// parsedArgs are the options and the free text following them in a command.
+type parsedArgs struct {
+	options map[string]string
+	text    string
+}
+
+// option returns the value of an option, and whether it was given.
+func (a parsedArgs) option(name string) (string, bool) {
+	value, ok := a.options[name]
+	return value, ok
+}
+
+// parseArgs parses the arguments of a command line given the names of the options it accepts,
+// e.g. "playbook" for --playbook. Options take a value, given as `--name value` or
+// `--name=value`, and come first: the rest of the line after them, or after `--`, is the free
+// text of the command, e.g. the title of an incident.
+func parseArgs(line string, args []argument, names ...string) (parsedArgs, error) {
+	parsed := parsedArgs{options: map[string]string{}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i].value
+		if arg == "--" {
+			parsed.text = remainder(line, args, i+1)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			parsed.text = remainder(line, args, i)
+			break
+		}
+
+		name := strings.TrimPrefix(arg, "--")
+		value, hasValue := "", false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		if !hasName(names, name) {
+			return parsedArgs{}, errors.Errorf("unknown option --%s, expected one of %s", name, formatNames(names))
+		}
+		if _, ok := parsed.options[name]; ok {
+			return parsedArgs{}, errors.Errorf("option --%s is given more than once", name)
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return parsedArgs{}, errors.Errorf("option --%s needs a value", name)
+			}
+			i++
+			value = args[i].value
+		}
+		parsed.options[name] = value
+	}
+
+	return parsed, nil
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNames(names []string) string {
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		formatted = append(formatted, "--"+name)
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`/incident end "All good, thanks" We're done C:\temp say"hi"`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/incident", "end", "All good, thanks", "We're", "done", `C:\temp`, `say"hi"`}, argValues(args))
+
+	args, err = splitArgs(`  /incident   update  "" `)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/incident", "update", ""}, argValues(args))
+	require.Equal(t, []int{2, 14, 22}, []int{args[0].start, args[1].start, args[2].start})
+
+	args, err = splitArgs("/incident update\nFirst line\nSecond line")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/incident", "update", "First", "line", "Second", "line"}, argValues(args))
+
+	args, err = splitArgs(`/incident start --playbook="Major Outage" --commander=@alice a=" b`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/incident", "start", "--playbook=Major Outage", "--commander=@alice", `a="`, "b"}, argValues(args))
+
+	_, err = splitArgs(`/incident end "All good`)
+	require.EqualError(t, err, `missing closing quote "`)
+}
+
+func TestParseArgs(t *testing.T) {
+	parse := func(line string, names ...string) (parsedArgs, error) {
+		args, err := splitArgs(line)
+		require.NoError(t, err)
+		return parseArgs(line, args, names...)
+	}
+
+	t.Run("options and text", func(t *testing.T) {
+		parsed, err := parse(`--playbook "Big outage" --commander=@alice Database  isn't responding`, "playbook", "commander")
+		require.NoError(t, err)
+
+		playbook, ok := parsed.option("playbook")
+		require.True(t, ok)
+		require.Equal(t, "Big outage", playbook)
+
+		commander, ok := parsed.option("commander")
+		require.True(t, ok)
+		require.Equal(t, "@alice", commander)
+
+		_, ok = parsed.option("description")
+		require.False(t, ok)
+
+		require.Equal(t, "Database  isn't responding", parsed.text)
+	})
+
+	t.Run("quoted option value after equals", func(t *testing.T) {
+		parsed, err := parse(`--playbook="two words" Database down`, "playbook")
+		require.NoError(t, err)
+
+		playbook, ok := parsed.option("playbook")
+		require.True(t, ok)
+		require.Equal(t, "two words", playbook)
+		require.Equal(t, "Database down", parsed.text)
+	})
+
+	t.Run("text is kept as typed", func(t *testing.T) {
+		parsed, err := parse("--status Active We've found the cause:\n- a bad deploy\n- \"cache\" misses\n", "status")
+		require.NoError(t, err)
+		require.Equal(t, "We've found the cause:\n- a bad deploy\n- \"cache\" misses", parsed.text)
+
+		parsed, err = parse(`"All good, thanks"`, "status")
+		require.NoError(t, err)
+		require.Equal(t, "All good, thanks", parsed.text)
+
+		parsed, err = parse(`Database down --status Resolved`, "status")
+		require.NoError(t, err)
+		require.Empty(t, parsed.options)
+		require.Equal(t, "Database down --status Resolved", parsed.text)
+	})
+
+	t.Run("text after double dash", func(t *testing.T) {
+		parsed, err := parse("-- --status is unknown", "status")
+		require.NoError(t, err)
+		require.Empty(t, parsed.options)
+		require.Equal(t, "--status is unknown", parsed.text)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := parse("--unknown value", "status")
+		require.EqualError(t, err, "unknown option --unknown, expected one of --status")
+
+		_, err = parse("--status", "status")
+		require.EqualError(t, err, "option --status needs a value")
+
+		_, err = parse("--status Active --status=Resolved", "status")
+		require.EqualError(t, err, "option --status is given more than once")
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/timeutils"
@@ -23,14 +24,22 @@ import (
 
 const helpText = "###### Mattermost Incident Collaboration Plugin - Slash Command Help\n" +
 	"* `/incident start` - Start a new incident. \n" +
-	"* `/incident end` - Close the incident of that channel. \n" +
+	"* `/incident start [--playbook playbook] [--commander @username] [name]` - Start a new incident without the dialog. \n" +
+	"* `/incident end [\"message\"]` - Close the incident of that channel, without the dialog if a message is given. \n" +
 	"* `/incident update` - Update the incident's status and (if enabled) post the status update to the broadcast channel. \n" +
+	"* `/incident update [--status status] [--reminder 30m] [message]` - Post a status update without the dialog. \n" +
+	"* `/incident restart [\"message\"]` - Restart the incident of that channel, without the dialog if a message is given. \n" +
 	"* `/incident check [checklist #] [item #]` - check/uncheck the checklist item. \n" +
-	"* `/incident property [checklist name] [item value]` - Change propertylist item value. \n" +
+	"* `/incident task [add|assign|start|done|skip|remove|move|list]` - Manage the checklist items, see `/incident task` for details. \n" +
+	"* `/incident property [property name] [value]` - Change the value of an incident property. \n" +
 	"* `/incident commander [@username]` - Show or change the current commander. \n" +
+	"* `/incident handoff [@username] [notes]` - Ask a user to take command of the incident, with handover notes. \n" +
 	"* `/incident announce ~[channels]` - Announce the current incident in other channels. \n" +
-	"* `/incident list` - List all your incidents. \n" +
+	"* `/incident list [--all-teams]` - List all your incidents, in this team or in all your teams. \n" +
 	"* `/incident info` - Show a summary of the current incident. \n" +
+	"* `/incident oncall [show|override|cancel]` - Show and override the on-call schedules of this team, see `/incident oncall help` for details. \n" +
+	"* `/incident search [terms]` - Search the incidents of this team. \n" +
+	"* `/incident template [list|install] [name]` - List the starter playbooks, or install one in this team (admins only). \n" +
 	"\n" +
 	"Learn more [in our documentation](https://mattermost.com/pl/default-incident-response-app-documentation). \n" +
 	""
@@ -51,7 +60,7 @@ func getCommand(addTestCommands bool) *model.Command {
 		DisplayName:      "Incident",
 		Description:      "Incident Collaboration Plugin",
 		AutoComplete:     true,
-		AutoCompleteDesc: "Available commands: start, end, update, restart, check, announce, list, commander, info",
+		AutoCompleteDesc: "Available commands: start, end, update, restart, check, task, announce, list, commander, info, oncall",
 		AutoCompleteHint: "[command]",
 		AutocompleteData: getAutocompleteData(addTestCommands),
 	}
@@ -59,21 +68,40 @@ func getCommand(addTestCommands bool) *model.Command {
 
 func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	slashIncident := model.NewAutocompleteData("incident", "[command]",
-		"Available commands: start, end, update, restart, check, announce, list, commander, info")
-
-	start := model.NewAutocompleteData("start", "", "Starts a new incident")
+		"Available commands: start, end, update, restart, check, task, announce, list, commander, info, oncall")
+
+	start := model.NewAutocompleteData("start", "[--playbook playbook] [--commander @username] [name]",
+		"Starts a new incident, without the dialog if a name is given")
+	start.AddNamedDynamicListArgument("playbook", "The playbook to start the incident from",
+		"api/v0/playbooks/autocomplete", false)
+	start.AddNamedTextArgument("commander", "The commander of the incident, whoever is on call for the playbook or you by default", "@username", "", false)
+	start.AddNamedTextArgument("description", "The description of the incident", "\"description\"", "", false)
+	start.AddTextArgument("The name of the incident", "[name]", "")
 	slashIncident.AddCommand(start)
 
-	end := model.NewAutocompleteData("end", "",
-		"Ends the incident associated with the current channel")
+	end := model.NewAutocompleteData("end", "[\"message\"]",
+		"Ends the incident associated with the current channel, without the dialog if a message is given")
+	end.AddNamedTextArgument("reminder", "The time until the next update reminder, e.g. 30m", "30m", "", false)
+	end.AddNamedStaticListArgument("override-exit-criteria", "Ends the incident even if its exit criteria are not met", false, booleanItems())
+	end.AddTextArgument("The message of the final status update", "[\"message\"]", "")
 	slashIncident.AddCommand(end)
 
-	update := model.NewAutocompleteData("update", "",
-		"Update the current incident's status.")
+	update := model.NewAutocompleteData("update", "[--status status] [--reminder duration] [message]",
+		"Update the current incident's status, without the dialog if a message is given.")
+	var statusItems []model.AutocompleteListItem
+	for _, status := range incident.Statuses {
+		statusItems = append(statusItems, model.AutocompleteListItem{Item: status, HelpText: "Sets the status to " + status})
+	}
+	update.AddNamedStaticListArgument("status", "The new status, the current one by default", false, statusItems)
+	update.AddNamedTextArgument("reminder", "The time until the next update reminder, e.g. 30m", "30m", "", false)
+	update.AddNamedStaticListArgument("override-exit-criteria", "Resolves the incident even if its exit criteria are not met", false, booleanItems())
+	update.AddTextArgument("The message of the status update", "[message]", "")
 	slashIncident.AddCommand(update)
 
-	restart := model.NewAutocompleteData("restart", "",
-		"Restarts the incident associated with the current channel")
+	restart := model.NewAutocompleteData("restart", "[\"message\"]",
+		"Restarts the incident associated with the current channel, without the dialog if a message is given")
+	restart.AddNamedTextArgument("reminder", "The time until the next update reminder, e.g. 30m", "30m", "", false)
+	restart.AddTextArgument("The message of the status update", "[\"message\"]", "")
 	slashIncident.AddCommand(restart)
 
 	checklist := model.NewAutocompleteData("check", "[checklist item]",
@@ -83,8 +111,11 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 		"api/v0/incidents/checklist-autocomplete", true)
 	slashIncident.AddCommand(checklist)
 
-	propertylist := model.NewAutocompleteData("property", "[propertylist item]",
-		"Checks or unchecks a propertylist item.")
+	slashIncident.AddCommand(getTaskAutocompleteData())
+	slashIncident.AddCommand(getOnCallAutocompleteData())
+
+	propertylist := model.NewAutocompleteData("property", "[property name] [value]",
+		"Changes the value of an incident property.")
 	propertylist.AddDynamicListArgument(
 		"List of propertlist items is downloading from your Incident Collaboration plugin",
 		"api/v0/incidents/propertylist-autocomplete", true)
@@ -96,7 +127,10 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 		"Channel to announce incident in", "~[channel]", "", true)
 	slashIncident.AddCommand(announce)
 
-	list := model.NewAutocompleteData("list", "", "Lists all your incidents")
+	list := model.NewAutocompleteData("list", "[--all-teams]", "Lists all your incidents")
+	list.AddStaticListArgument("Lists your incidents in all your teams", false, []model.AutocompleteListItem{
+		{Item: "--all-teams", HelpText: "Lists your incidents in all your teams"},
+	})
 	slashIncident.AddCommand(list)
 
 	commander := model.NewAutocompleteData("commander", "[@username]",
@@ -104,9 +138,34 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	commander.AddTextArgument("The desired new commander.", "[@username]", "")
 	slashIncident.AddCommand(commander)
 
+	handoff := model.NewAutocompleteData("handoff", "[@username] [notes]",
+		"Ask a user to take command of the incident")
+	handoff.AddTextArgument("The proposed commander.", "[@username]", "")
+	handoff.AddTextArgument("Handover notes for the new commander.", "[notes]", "")
+	slashIncident.AddCommand(handoff)
+
 	info := model.NewAutocompleteData("info", "", "Shows a summary of the current incident")
 	slashIncident.AddCommand(info)
 
+	search := model.NewAutocompleteData("search", "[terms]",
+		"Searches the incidents of this team")
+	search.AddTextArgument("The words to search for.", "[terms]", "")
+	slashIncident.AddCommand(search)
+
+	template := model.NewAutocompleteData("template", "[list|install] [name]",
+		"Lists the starter playbooks, or installs one in this team")
+	templateList := model.NewAutocompleteData("list", "", "Lists the starter playbooks")
+	template.AddCommand(templateList)
+	templateInstall := model.NewAutocompleteData("install", "[name]",
+		"Installs a starter playbook in this team (admins only)")
+	var templateItems []model.AutocompleteListItem
+	for _, t := range playbook.Templates() {
+		templateItems = append(templateItems, model.AutocompleteListItem{Item: t.Name, HelpText: t.Title})
+	}
+	templateInstall.AddStaticListArgument("The starter playbook to install", true, templateItems)
+	template.AddCommand(templateInstall)
+	slashIncident.AddCommand(template)
+
 	if addTestCommands {
 		test := model.NewAutocompleteData("test", "", "Commands for testing and debugging.")
 
@@ -133,6 +192,13 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	return slashIncident
 }
 
+func booleanItems() []model.AutocompleteListItem {
+	return []model.AutocompleteListItem{
+		{Item: "true", HelpText: "Yes"},
+		{Item: "false", HelpText: "No"},
+	}
+}
+
 // Runner handles commands.
 type Runner struct {
 	context         *plugin.Context
@@ -142,11 +208,13 @@ type Runner struct {
 	poster          bot.Poster
 	incidentService incident.Service
 	playbookService playbook.Service
+	onCallService   oncall.Service
 }
 
 // NewCommandRunner creates a command runner.
 func NewCommandRunner(ctx *plugin.Context, args *model.CommandArgs, api *pluginapi.Client,
-	logger bot.Logger, poster bot.Poster, incidentService incident.Service, playbookService playbook.Service) *Runner {
+	logger bot.Logger, poster bot.Poster, incidentService incident.Service, playbookService playbook.Service,
+	onCallService oncall.Service) *Runner {
 	return &Runner{
 		context:         ctx,
 		args:            args,
@@ -155,6 +223,7 @@ func NewCommandRunner(ctx *plugin.Context, args *model.CommandArgs, api *plugina
 		poster:          poster,
 		incidentService: incidentService,
 		playbookService: playbookService,
+		onCallService:   onCallService,
 	}
 }
 
@@ -180,6 +249,11 @@ func (r *Runner) warnUserAndLogErrorf(format string, args ...interface{}) {
 }
 
 func (r *Runner) actionStart(args []string) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		r.actionStartWithArgs()
+		return
+	}
+
 	clientID := ""
 	if len(args) > 0 {
 		clientID = args[0]
@@ -200,6 +274,7 @@ func (r *Runner) actionStart(args []string) {
 		TeamID:          r.args.TeamId,
 		UserIDtoIsAdmin: map[string]bool{r.args.UserId: permissions.IsAdmin(r.args.UserId, r.pluginAPI)},
 		MemberOnly:      true,
+		MinRole:         playbook.RoleRunner,
 	}
 
 	playbooksResults, err := r.playbookService.GetPlaybooksForTeam(requesterInfo, r.args.TeamId,
@@ -224,6 +299,141 @@ func (r *Runner) actionStart(args []string) {
 	}
 }
 
+// actionStartWithArgs starts an incident without opening the dialog, given its name and options:
+// /incident start --playbook <title> --commander @username <name>
+func (r *Runner) actionStartWithArgs() {
+	parsed, err := r.parsedParameters("playbook", "commander", "description")
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to start the incident: %v.", err))
+		return
+	}
+
+	if !permissions.CanViewTeam(r.args.UserId, r.args.TeamId, r.pluginAPI) {
+		r.postCommandResponse("Must be a member of the team to start incidents.")
+		return
+	}
+
+	description, _ := parsed.option("description")
+	newIncident := incident.Incident{
+		Name:        parsed.text,
+		Description: description,
+		TeamID:      r.args.TeamId,
+	}
+
+	if commander, ok := parsed.option("commander"); ok {
+		username := strings.TrimLeft(commander, "@")
+		user, err := r.pluginAPI.User.GetByUsername(username)
+		if errors.Is(err, pluginapi.ErrNotFound) {
+			r.postCommandResponse(fmt.Sprintf("Unable to find user @%s", username))
+			return
+		} else if err != nil {
+			r.warnUserAndLogErrorf("Error finding user @%s: %v", username, err)
+			return
+		}
+		newIncident.CommanderUserID = user.Id
+	}
+
+	var thePlaybook *playbook.Playbook
+	if title, ok := parsed.option("playbook"); ok {
+		thePlaybook, err = r.findRunnablePlaybook(title)
+		if errors.Is(err, playbook.ErrNotFound) {
+			r.postCommandResponse(fmt.Sprintf("There is no playbook '%s' that you can run in this team.", title))
+			return
+		} else if err != nil {
+			r.warnUserAndLogErrorf("Error retrieving playbook '%s': %v", title, err)
+			return
+		}
+		newIncident.PlaybookID = thePlaybook.ID
+	}
+
+	// Without a commander, whoever is on call for the playbook takes command, or else the user
+	// starting the incident.
+	if newIncident.CommanderUserID == "" {
+		scheduleID := ""
+		if thePlaybook != nil {
+			scheduleID = thePlaybook.OnCallScheduleID
+		}
+		canCommand := func(commanderID string) bool {
+			return permissions.CanViewTeam(commanderID, newIncident.TeamID, r.pluginAPI)
+		}
+		newIncident.CommanderUserID, err = oncall.DefaultCommander(r.onCallService, scheduleID, time.Now(), r.args.UserId, canCommand)
+		if err != nil {
+			r.logger.Warnf("Unable to find who is on call for playbook '%s': %v", newIncident.PlaybookID, err)
+		}
+	}
+
+	if err = incident.ValidateNewIncident(newIncident); err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to start the incident: %v.", err))
+		return
+	}
+
+	if err = permissions.StartIncident(r.args.UserId, newIncident, thePlaybook, r.pluginAPI, r.logger); errors.Is(err, incident.ErrPermission) {
+		r.postCommandResponse(fmt.Sprintf("Unable to start the incident: %v.", err))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking the permissions to start the incident: %v", err)
+		return
+	}
+
+	public := true
+	if thePlaybook != nil {
+		newIncident.ApplyPlaybook(*thePlaybook)
+		public = thePlaybook.CreatePublicIncident
+	}
+
+	createdIncident, err := r.incidentService.CreateIncident(&newIncident, r.args.UserId, public)
+	if errors.Is(err, incident.ErrChannelDisplayNameInvalid) {
+		r.postCommandResponse("The incident name is invalid or too long. Please use a valid name with fewer than 64 characters.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error creating the incident: %v", err)
+		return
+	}
+
+	channel, err := r.pluginAPI.Channel.Get(createdIncident.ChannelID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving the incident channel: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("Incident %s started in ~%s", createdIncident.Name, channel.Name))
+}
+
+// findRunnablePlaybook returns the playbook with the given ID or title, ignoring case, among the
+// ones the user can start incidents from in the current team. Returns playbook.ErrNotFound if
+// there is none.
+func (r *Runner) findRunnablePlaybook(idOrTitle string) (*playbook.Playbook, error) {
+	requesterInfo := playbook.RequesterInfo{
+		UserID:          r.args.UserId,
+		TeamID:          r.args.TeamId,
+		UserIDtoIsAdmin: map[string]bool{r.args.UserId: permissions.IsAdmin(r.args.UserId, r.pluginAPI)},
+		MemberOnly:      true,
+		MinRole:         playbook.RoleRunner,
+	}
+
+	results, err := r.playbookService.GetPlaybooksForTeam(requesterInfo, r.args.TeamId,
+		playbook.Options{
+			Sort:      playbook.SortByTitle,
+			Direction: playbook.DirectionAsc,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pb := range results.Items {
+		if pb.ID == idOrTitle || strings.EqualFold(strings.TrimSpace(pb.Title), strings.TrimSpace(idOrTitle)) {
+			// The list leaves out the checklists, get the whole playbook.
+			thePlaybook, err := r.playbookService.Get(pb.ID)
+			if err != nil {
+				return nil, err
+			}
+			return &thePlaybook, nil
+		}
+	}
+
+	return nil, playbook.ErrNotFound
+}
+
 func (r *Runner) actionCheck(args []string) {
 	if len(args) != 2 {
 		r.postCommandResponse(helpText)
@@ -258,28 +468,54 @@ func (r *Runner) actionCheck(args []string) {
 	}
 }
 
-func (r *Runner) propertyActionCheck(args []string) {
-	if len(args) != 2 {
-		r.postCommandResponse(helpText)
+func (r *Runner) actionProperty(args []string) {
+	if len(args) < 2 {
+		r.postCommandResponse("/incident property expects a property name and a value.")
 		return
 	}
 
-	propertyTitle := args[0]
-	propertyValue := args[1]
-
 	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
 	if err != nil {
 		if errors.Is(err, incident.ErrNotFound) {
-			r.postCommandResponse("You can only check/uncheck an item from within the incident's channel.")
+			r.postCommandResponse("You can only change a property from within the incident's channel.")
 			return
 		}
 		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
 		return
 	}
 
+	currentIncident, err := r.incidentService.GetIncident(incidentID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	// Property titles may contain spaces: use the longest title matching the first arguments.
+	propertyTitle := ""
+	propertyValue := ""
+	for _, item := range currentIncident.Propertylist.Items {
+		numWords := len(strings.Fields(item.Title))
+		if numWords == 0 || numWords >= len(args) || len(item.Title) <= len(propertyTitle) {
+			continue
+		}
+		if strings.EqualFold(strings.Join(args[:numWords], " "), strings.Join(strings.Fields(item.Title), " ")) {
+			propertyTitle = item.Title
+			propertyValue = strings.Join(args[numWords:], " ")
+		}
+	}
+
+	if propertyTitle == "" {
+		r.postCommandResponse(fmt.Sprintf("This incident has no property named **%s**.", args[0]))
+		return
+	}
+
 	err = r.incidentService.ChangePropertyValue(incidentID, r.args.UserId, propertyTitle, propertyValue)
+	if errors.Is(err, playbook.ErrInvalidPropertyValue) {
+		r.postCommandResponse(fmt.Sprintf("Unable to change the property: %v.", err))
+		return
+	}
 	if err != nil {
-		r.warnUserAndLogErrorf("Error checking/unchecking item: %v", err)
+		r.warnUserAndLogErrorf("Error changing property value: %v", err)
 	}
 }
 
@@ -367,6 +603,57 @@ func (r *Runner) actionChangeCommander(args []string) {
 	}
 }
 
+func (r *Runner) actionHandoff(args []string) {
+	if len(args) == 0 {
+		r.postCommandResponse("/incident handoff expects the user to hand command over to, followed by optional handover notes.")
+		return
+	}
+
+	targetCommanderUsername := strings.TrimLeft(args[0], "@")
+	notes := strings.Join(args[1:], " ")
+
+	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
+	if errors.Is(err, incident.ErrNotFound) {
+		r.postCommandResponse("You can only hand command over from within the incident's channel.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident for channel %s: %v", r.args.ChannelId, err)
+		return
+	}
+
+	currentIncident, err := r.incidentService.GetIncident(incidentID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	targetCommanderUser, err := r.pluginAPI.User.GetByUsername(targetCommanderUsername)
+	if errors.Is(err, pluginapi.ErrNotFound) {
+		r.postCommandResponse(fmt.Sprintf("Unable to find user @%s", targetCommanderUsername))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error finding user @%s: %v", targetCommanderUsername, err)
+		return
+	}
+
+	if !permissions.CanViewTeam(targetCommanderUser.Id, currentIncident.TeamID, r.pluginAPI) {
+		r.postCommandResponse(fmt.Sprintf("User @%s must be a member of this team to take command of the incident.", targetCommanderUsername))
+		return
+	}
+
+	err = r.incidentService.RequestCommanderHandoff(currentIncident.ID, r.args.UserId, targetCommanderUser.Id, notes)
+	if errors.Is(err, incident.ErrPermission) {
+		r.postCommandResponse("Only the commander can hand command over.")
+		return
+	} else if errors.Is(err, incident.ErrMalformedIncident) || errors.Is(err, incident.ErrIncidentNotActive) {
+		r.postCommandResponse(fmt.Sprintf("Unable to hand command over: %v.", err))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Failed to hand command over to @%s: %v", targetCommanderUsername, err)
+		return
+	}
+}
+
 func (r *Runner) actionAnnounce(args []string) {
 	if len(args) < 1 {
 		r.postCommandResponse(helpText)
@@ -418,7 +705,13 @@ func (r *Runner) actionAnnounce(args []string) {
 	}
 }
 
-func (r *Runner) actionList() {
+func (r *Runner) actionList(args []string) {
+	allTeams := len(args) > 0 && args[0] == "--all-teams"
+	if len(args) > 0 && !allTeams {
+		r.postCommandResponse("Command expects no arguments, or `--all-teams`.")
+		return
+	}
+
 	team, err := r.pluginAPI.Team.Get(r.args.TeamId)
 	if err != nil {
 		r.warnUserAndLogErrorf("Error retrieving current team: %v", err)
@@ -431,7 +724,7 @@ func (r *Runner) actionList() {
 		return
 	}
 
-	if !session.IsMobileApp() {
+	if !session.IsMobileApp() && !allTeams {
 		// The RHS was opened by the webapp, so inform the user
 		r.postCommandResponse("The list of your incidents is open in the right hand side of the channel.")
 		return
@@ -450,6 +743,9 @@ func (r *Runner) actionList() {
 		Direction: incident.DirectionDesc,
 		Status:    incident.StatusActive,
 	}
+	if allTeams {
+		options.TeamID = ""
+	}
 
 	result, err := r.incidentService.GetIncidents(requesterInfo, options)
 	if err != nil {
@@ -461,6 +757,12 @@ func (r *Runner) actionList() {
 	if len(result.Items) == 0 {
 		message = "There are no ongoing incidents in **" + team.DisplayName + "** team."
 	}
+	if allTeams {
+		message = "Ongoing Incidents in all your teams:\n"
+		if len(result.Items) == 0 {
+			message = "There are no ongoing incidents in any of your teams."
+		}
+	}
 
 	now := time.Now()
 	attachments := make([]*model.SlackAttachment, len(result.Items))
@@ -477,12 +779,17 @@ func (r *Runner) actionList() {
 			return
 		}
 
+		fields := []*model.SlackAttachmentField{
+			{Title: "Duration:", Value: timeutils.DurationString(timeutils.GetTimeForMillis(theIncident.CreateAt), now)},
+			{Title: "Commander:", Value: fmt.Sprintf("@%s", commander.Username)},
+		}
+		if theIncident.Team != nil {
+			fields = append(fields, &model.SlackAttachmentField{Title: "Team:", Value: theIncident.Team.DisplayName})
+		}
+
 		attachments[i] = &model.SlackAttachment{
 			Pretext: fmt.Sprintf("### ~%s", channel.Name),
-			Fields: []*model.SlackAttachmentField{
-				{Title: "Duration:", Value: timeutils.DurationString(timeutils.GetTimeForMillis(theIncident.CreateAt), now)},
-				{Title: "Commander:", Value: fmt.Sprintf("@%s", commander.Username)},
-			},
+			Fields:  fields,
 		}
 	}
 
@@ -495,6 +802,94 @@ func (r *Runner) actionList() {
 	r.poster.EphemeralPost(r.args.UserId, r.args.ChannelId, post)
 }
 
+func (r *Runner) actionSearch(args []string) {
+	if len(args) == 0 {
+		r.postCommandResponse("Please specify what to search for: `/incident search [terms]`")
+		return
+	}
+
+	requesterInfo := incident.RequesterInfo{
+		UserID:          r.args.UserId,
+		UserIDtoIsAdmin: map[string]bool{r.args.UserId: permissions.IsAdmin(r.args.UserId, r.pluginAPI)},
+	}
+
+	options := incident.SearchOptions{
+		TeamID:  r.args.TeamId,
+		Terms:   strings.Join(args, " "),
+		PerPage: 10,
+	}
+	if err := incident.ValidateSearchOptions(&options); err != nil {
+		r.postCommandResponse("Please specify at least one word to search for: `/incident search [terms]`")
+		return
+	}
+
+	results, err := r.incidentService.SearchIncidents(requesterInfo, options)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error searching the incidents: %v", err)
+		return
+	}
+
+	if len(results.Items) == 0 {
+		r.postCommandResponse("No incidents match your search.")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString("Incidents matching your search:\n")
+	for _, result := range results.Items {
+		channel, err := r.pluginAPI.Channel.Get(result.Incident.ChannelID)
+		if err != nil {
+			r.warnUserAndLogErrorf("Error retrieving channel of incident '%s': %v", result.Incident.Name, err)
+			return
+		}
+
+		message.WriteString(fmt.Sprintf("* ~%s (%s)\n", channel.Name, result.Incident.CurrentStatus()))
+		for _, snippet := range result.Snippets {
+			message.WriteString(fmt.Sprintf("  * %s\n", snippet.Markdown()))
+		}
+	}
+	if results.HasMore {
+		message.WriteString("\nOnly the most relevant incidents are listed: refine your search to find others.")
+	}
+
+	r.postCommandResponse(message.String())
+}
+
+func (r *Runner) actionTemplate(args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		var message strings.Builder
+		message.WriteString("Starter playbooks, to install with `/incident template install [name]`:\n")
+		for _, t := range playbook.Templates() {
+			message.WriteString(fmt.Sprintf("* `%s` - **%s**: %s\n", t.Name, t.Title, t.Description))
+		}
+		r.postCommandResponse(message.String())
+		return
+	}
+
+	if args[0] != "install" || len(args) != 2 {
+		r.postCommandResponse("Command expects `list`, or `install` and the name of a starter playbook.")
+		return
+	}
+
+	if !permissions.IsAdmin(r.args.UserId, r.pluginAPI) {
+		r.postCommandResponse("Installing starter playbooks is restricted to system administrators.")
+		return
+	}
+
+	template, err := playbook.GetTemplate(args[1])
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("There is no starter playbook named `%s`. Run `/incident template list` to see them.", args[1]))
+		return
+	}
+
+	if _, err := r.playbookService.InstallTemplate(template.Name, r.args.TeamId, r.args.UserId); err != nil {
+		r.warnUserAndLogErrorf("Error installing the starter playbook: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("The playbook **%s** has been installed in this team. You own it and can adapt it to your needs.", template.Title))
+}
+
 func (r *Runner) actionInfo() {
 	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
 	if errors.Is(err, incident.ErrNotFound) {
@@ -567,11 +962,21 @@ func (r *Runner) announceChannel(targetChannelID, commanderUsername, incidentCha
 	return nil
 }
 
-func (r *Runner) actionEnd() {
-	r.actionUpdate()
+func (r *Runner) actionEnd(args []string) {
+	if len(args) > 0 {
+		r.actionUpdateWithArgs(incident.StatusResolved)
+		return
+	}
+
+	r.actionUpdate(nil)
 }
 
-func (r *Runner) actionUpdate() {
+func (r *Runner) actionUpdate(args []string) {
+	if len(args) > 0 {
+		r.actionUpdateWithArgs("")
+		return
+	}
+
 	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
 	if err != nil {
 		if errors.Is(err, incident.ErrNotFound) {
@@ -602,8 +1007,110 @@ func (r *Runner) actionUpdate() {
 	}
 }
 
-func (r *Runner) actionRestart() {
-	r.actionUpdate()
+// actionUpdateWithArgs posts a status update without opening the dialog, given its message and
+// options: /incident update --status <status> --reminder <duration> <message>. The status
+// defaults to the given one, or to the current status of the incident if empty.
+func (r *Runner) actionUpdateWithArgs(defaultStatus string) {
+	parsed, err := r.parsedParameters("status", "reminder", "override-exit-criteria")
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to update the incident: %v.", err))
+		return
+	}
+
+	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
+	if errors.Is(err, incident.ErrNotFound) {
+		r.postCommandResponse("You can only update an incident from within the incident's channel.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	if !permissions.CanPostToChannel(r.args.UserId, r.args.ChannelId, r.pluginAPI) {
+		r.postCommandResponse("You are not able to post in the incident's channel.")
+		return
+	}
+
+	currentIncident, err := r.incidentService.GetIncident(incidentID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	options := incident.StatusUpdateOptions{
+		Status:   defaultStatus,
+		Message:  parsed.text,
+		Reminder: currentIncident.PreviousReminder,
+	}
+	if options.Status == "" {
+		options.Status = currentIncident.CurrentStatus()
+	}
+	if status, ok := parsed.option("status"); ok {
+		options.Status = status
+	}
+	if reminder, ok := parsed.option("reminder"); ok {
+		options.Reminder, err = time.ParseDuration(reminder)
+		if err != nil {
+			r.postCommandResponse(fmt.Sprintf("Unable to update the incident: invalid reminder '%s', it should be a duration such as 30m or 1h.", reminder))
+			return
+		}
+	}
+	if override, ok := parsed.option("override-exit-criteria"); ok {
+		options.OverrideExitCriteria, err = strconv.ParseBool(override)
+		if err != nil {
+			r.postCommandResponse(fmt.Sprintf("Unable to update the incident: invalid --override-exit-criteria '%s', it should be true or false.", override))
+			return
+		}
+	}
+
+	if err = incident.ValidateStatusUpdateOptions(&options); err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to update the incident: %v.", err))
+		return
+	}
+
+	err = r.incidentService.UpdateStatus(incidentID, r.args.UserId, options)
+	var exitCriteriaErr *incident.ExitCriteriaError
+	if errors.As(err, &exitCriteriaErr) {
+		r.postCommandResponse(fmt.Sprintf("The exit criteria are not met: %s. Use --override-exit-criteria true to %s the incident anyway.",
+			strings.Join(exitCriteriaErr.Unmet, "; "), strings.ToLower(options.Status)))
+		return
+	} else if errors.Is(err, incident.ErrPermission) {
+		r.postCommandResponse("Only the commander or a system admin can override the exit criteria.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error updating the incident status: %v", err)
+		return
+	}
+}
+
+func (r *Runner) actionRestart(args []string) {
+	if len(args) > 0 {
+		r.actionUpdateWithArgs(incident.StatusActive)
+		return
+	}
+
+	r.actionUpdate(nil)
+}
+
+// quotedParameters returns the parameters of the command, after the action, honoring quotes.
+func (r *Runner) quotedParameters() ([]argument, error) {
+	split, err := splitArgs(r.args.Command)
+	if err != nil {
+		return nil, err
+	}
+	if len(split) <= 2 {
+		return nil, nil
+	}
+	return split[2:], nil
+}
+
+// parsedParameters returns the options given to the command, after the action, and its free text.
+func (r *Runner) parsedParameters(names ...string) (parsedArgs, error) {
+	args, err := r.quotedParameters()
+	if err != nil {
+		return parsedArgs{}, err
+	}
+	return parseArgs(r.args.Command, args, names...)
 }
 
 func (r *Runner) actionTestSelf(args []string) {
@@ -1088,6 +1595,7 @@ func (r *Runner) generateTestData(numActiveIncidents, numEndedIncidents int, beg
 		TeamID:          r.args.TeamId,
 		UserIDtoIsAdmin: map[string]bool{r.args.UserId: permissions.IsAdmin(r.args.UserId, r.pluginAPI)},
 		MemberOnly:      true,
+		MinRole:         playbook.RoleRunner,
 	}
 
 	playbooksResult, err := r.playbookService.GetPlaybooksForTeam(requesterInfo, r.args.TeamId, playbook.Options{})
@@ -1224,23 +1732,33 @@ func (r *Runner) Execute() error {
 	case "start":
 		r.actionStart(parameters)
 	case "end":
-		r.actionEnd()
+		r.actionEnd(parameters)
 	case "update":
-		r.actionUpdate()
+		r.actionUpdate(parameters)
 	case "check":
 		r.actionCheck(parameters)
+	case "task":
+		r.actionTask(parameters)
+	case "oncall":
+		r.actionOnCall()
 	case "property":
-		r.propertyActionCheck(parameters)
+		r.actionProperty(parameters)
 	case "restart":
-		r.actionRestart()
+		r.actionRestart(parameters)
 	case "commander":
 		r.actionCommander(parameters)
+	case "handoff":
+		r.actionHandoff(parameters)
 	case "announce":
 		r.actionAnnounce(parameters)
 	case "list":
-		r.actionList()
+		r.actionList(parameters)
 	case "info":
 		r.actionInfo()
+	case "search":
+		r.actionSearch(parameters)
+	case "template":
+		r.actionTemplate(parameters)
 	case "nuke-db":
 		r.actionNukeDB(parameters)
 	case "test":
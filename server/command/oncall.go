@@ -0,0 +1,283 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/timeutils"
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+const onCallHelpText = "###### /incident oncall - Show and override the on-call schedules of the team\n" +
+	"Schedules are given by their name or ID. Names with spaces must be quoted.\n" +
+	"* `/incident oncall` - Show who is on call in every schedule of this team. \n" +
+	"* `/incident oncall show [schedule]` - Show who is on call in a schedule, and its upcoming overrides. \n" +
+	"* `/incident oncall override [schedule] [@username] [duration]` - Put a user on call from now on, e.g. for `8h`. Only team admins can put someone else than themselves on call. \n" +
+	"* `/incident oncall cancel [schedule] [override #]` - Cancel an override, numbered as listed by `/incident oncall show`. \n"
+
+func getOnCallAutocompleteData() *model.AutocompleteData {
+	onCall := model.NewAutocompleteData("oncall", "[command]",
+		"Available commands: show, override, cancel")
+
+	show := model.NewAutocompleteData("show", "[schedule]", "Shows who is on call in a schedule")
+	addScheduleArgument(show)
+	onCall.AddCommand(show)
+
+	override := model.NewAutocompleteData("override", "[schedule] [@username] [duration]",
+		"Puts a user on call from now on, for the given duration")
+	addScheduleArgument(override)
+	override.AddTextArgument("The user to put on call", "[@username]", "")
+	override.AddTextArgument("How long the user is on call, e.g. 8h", "[duration]", "")
+	onCall.AddCommand(override)
+
+	cancel := model.NewAutocompleteData("cancel", "[schedule] [override #]", "Cancels an override")
+	addScheduleArgument(cancel)
+	cancel.AddTextArgument("The number of the override, as listed by /incident oncall show", "[override #]", "[0-9]+")
+	onCall.AddCommand(cancel)
+
+	return onCall
+}
+
+func addScheduleArgument(data *model.AutocompleteData) {
+	data.AddDynamicListArgument("List of schedules is downloading from your Incident Collaboration plugin",
+		"api/v0/oncall/schedules/autocomplete", true)
+}
+
+func (r *Runner) actionOnCall() {
+	parameters, err := r.quotedParameters()
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to parse the command: %v.", err))
+		return
+	}
+	args := argValues(parameters)
+
+	if !permissions.CanViewTeam(r.args.UserId, r.args.TeamId, r.pluginAPI) {
+		r.postCommandResponse("Must be a member of the team to see its on-call schedules.")
+		return
+	}
+
+	if len(args) == 0 {
+		r.actionOnCallList()
+		return
+	}
+
+	switch args[0] {
+	case "show":
+		r.actionOnCallShow(args[1:])
+	case "override":
+		r.actionOnCallOverride(args[1:])
+	case "cancel":
+		r.actionOnCallCancel(args[1:])
+	default:
+		r.postCommandResponse(onCallHelpText)
+	}
+}
+
+// findSchedule returns the schedule of the current team with the given ID or name, ignoring case,
+// posting the error to the user if there is none.
+func (r *Runner) findSchedule(idOrName string) (oncall.Schedule, bool) {
+	schedules, err := r.onCallService.GetSchedulesForTeam(r.args.TeamId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving the on-call schedules: %v", err)
+		return oncall.Schedule{}, false
+	}
+
+	for _, schedule := range schedules {
+		if schedule.ID == idOrName || strings.EqualFold(schedule.Name, strings.TrimSpace(idOrName)) {
+			return schedule, true
+		}
+	}
+
+	r.postCommandResponse(fmt.Sprintf("There is no on-call schedule '%s' in this team.", idOrName))
+	return oncall.Schedule{}, false
+}
+
+// usernameOf returns the username of a user, or their ID if it can't be found.
+func (r *Runner) usernameOf(userID string) string {
+	user, err := r.pluginAPI.User.Get(userID)
+	if err != nil {
+		r.logger.Warnf("Unable to find user %s: %v", userID, err)
+		return userID
+	}
+	return user.Username
+}
+
+// onCallLine describes who is on call in the schedule now.
+func (r *Runner) onCallLine(schedule oncall.Schedule, now time.Time) (string, error) {
+	onCall, err := schedule.OnCallAt(now)
+	if err != nil {
+		return "", err
+	}
+
+	if onCall.UserID == "" {
+		return fmt.Sprintf("Nobody is on call in **%s**.", schedule.Name), nil
+	}
+
+	line := fmt.Sprintf("**@%s** is on call in **%s**", r.usernameOf(onCall.UserID), schedule.Name)
+	switch {
+	case onCall.OverrideID != "":
+		line += " (override)"
+	case onCall.Layer != "":
+		line += fmt.Sprintf(" (%s)", onCall.Layer)
+	}
+	if onCall.Until != 0 {
+		line += fmt.Sprintf(" for the next %s", timeutils.DurationString(now, timeutils.GetTimeForMillis(onCall.Until)))
+	}
+
+	return line + ".", nil
+}
+
+func (r *Runner) actionOnCallList() {
+	schedules, err := r.onCallService.GetSchedulesForTeam(r.args.TeamId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving the on-call schedules: %v", err)
+		return
+	}
+
+	if len(schedules) == 0 {
+		r.postCommandResponse("There are no on-call schedules in this team.")
+		return
+	}
+
+	now := time.Now()
+	lines := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		line, err := r.onCallLine(schedule, now)
+		if err != nil {
+			r.warnUserAndLogErrorf("Error finding who is on call in schedule %s: %v", schedule.ID, err)
+			return
+		}
+		lines = append(lines, "- "+line)
+	}
+
+	r.postCommandResponse(strings.Join(lines, "\n"))
+}
+
+func (r *Runner) actionOnCallShow(args []string) {
+	if len(args) != 1 {
+		r.postCommandResponse("/incident oncall show expects the name of a schedule.")
+		return
+	}
+
+	schedule, ok := r.findSchedule(args[0])
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	line, err := r.onCallLine(schedule, now)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error finding who is on call in schedule %s: %v", schedule.ID, err)
+		return
+	}
+
+	message := line
+	if len(schedule.Overrides) > 0 {
+		message += "\n\nOverrides:"
+		for i, override := range schedule.Overrides {
+			message += fmt.Sprintf("\n%d. **@%s** from %s to %s (%s)", i, r.usernameOf(override.UserID),
+				timeutils.GetTimeForMillis(override.StartAt).UTC().Format(time.RFC822),
+				timeutils.GetTimeForMillis(override.EndAt).UTC().Format(time.RFC822),
+				timeutils.DurationString(timeutils.GetTimeForMillis(override.StartAt), timeutils.GetTimeForMillis(override.EndAt)))
+		}
+	}
+
+	r.postCommandResponse(message)
+}
+
+func (r *Runner) actionOnCallOverride(args []string) {
+	if len(args) != 3 {
+		r.postCommandResponse("/incident oncall override expects a schedule, a user and a duration.")
+		return
+	}
+
+	schedule, ok := r.findSchedule(args[0])
+	if !ok {
+		return
+	}
+
+	username := strings.TrimLeft(args[1], "@")
+	user, err := r.pluginAPI.User.GetByUsername(username)
+	if errors.Is(err, pluginapi.ErrNotFound) {
+		r.postCommandResponse(fmt.Sprintf("Unable to find user @%s", username))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error finding user @%s: %v", username, err)
+		return
+	}
+
+	duration, err := time.ParseDuration(args[2])
+	if err != nil || duration <= 0 {
+		r.postCommandResponse(fmt.Sprintf("Invalid duration '%s', use e.g. 30m or 8h.", args[2]))
+		return
+	}
+
+	if user.Id != r.args.UserId && !r.pluginAPI.User.HasPermissionToTeam(r.args.UserId, schedule.TeamID, model.PERMISSION_MANAGE_TEAM) {
+		r.postCommandResponse("Only team admins can put someone else than themselves on call.")
+		return
+	}
+
+	if !permissions.CanViewTeam(user.Id, schedule.TeamID, r.pluginAPI) {
+		r.postCommandResponse(fmt.Sprintf("@%s is not a member of this team.", username))
+		return
+	}
+
+	now := model.GetMillis()
+	_, err = r.onCallService.AddOverride(oncall.Override{
+		ScheduleID:    schedule.ID,
+		UserID:        user.Id,
+		StartAt:       now,
+		EndAt:         now + duration.Milliseconds(),
+		CreatorUserID: r.args.UserId,
+	})
+	if errors.Is(err, oncall.ErrMalformedSchedule) {
+		r.postCommandResponse(fmt.Sprintf("Unable to add the override: %v.", err))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error adding the override: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("**@%s** is on call in **%s** for the next %s.", username, schedule.Name,
+		timeutils.DurationString(time.Now(), time.Now().Add(duration))))
+}
+
+func (r *Runner) actionOnCallCancel(args []string) {
+	if len(args) != 2 {
+		r.postCommandResponse("/incident oncall cancel expects a schedule and the number of an override.")
+		return
+	}
+
+	schedule, ok := r.findSchedule(args[0])
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(args[1])
+	if err != nil || number < 0 || number >= len(schedule.Overrides) {
+		r.postCommandResponse(fmt.Sprintf("There is no override %s in **%s**, see `/incident oncall show`.", args[1], schedule.Name))
+		return
+	}
+	override := schedule.Overrides[number]
+
+	if override.CreatorUserID != r.args.UserId && override.UserID != r.args.UserId &&
+		!r.pluginAPI.User.HasPermissionToTeam(r.args.UserId, schedule.TeamID, model.PERMISSION_MANAGE_TEAM) {
+		r.postCommandResponse("Only team admins can cancel the overrides of others.")
+		return
+	}
+
+	if err = r.onCallService.RemoveOverride(schedule.ID, override.ID); err != nil {
+		r.warnUserAndLogErrorf("Error cancelling the override: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("The override of **@%s** in **%s** is cancelled.", r.usernameOf(override.UserID), schedule.Name))
+}
@@ -0,0 +1,352 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/permissions"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+const taskHelpText = "###### /incident task - Manage the checklists of the incident\n" +
+	"Checklist items are given by the number of their checklist and their number in it, e.g. `0 2`, as listed by `/incident task list`.\n" +
+	"* `/incident task add [checklist #] [title]` - Add an item at the end of a checklist. \n" +
+	"* `/incident task assign [checklist #] [item #] [@username]` - Assign an item, or unassign it if no user is given. \n" +
+	"* `/incident task start [checklist #] [item #]` - Mark an item as in progress. \n" +
+	"* `/incident task done [checklist #] [item #]` - Check off an item. \n" +
+	"* `/incident task skip [checklist #] [item #]` - Skip an item. \n" +
+	"* `/incident task remove [checklist #] [item #]` - Remove an item. \n" +
+	"* `/incident task move [checklist #] [item #] [new item #]` - Move an item within its checklist. \n" +
+	"* `/incident task list [--mine] [--open]` - List the items, only the ones assigned to you or not done yet. \n"
+
+func getTaskAutocompleteData() *model.AutocompleteData {
+	task := model.NewAutocompleteData("task", "[command]",
+		"Available commands: add, assign, start, done, skip, remove, move, list")
+
+	add := model.NewAutocompleteData("add", "[checklist #] [title]", "Adds an item at the end of a checklist")
+	add.AddDynamicListArgument("List of checklists is downloading from your Incident Collaboration plugin",
+		"api/v0/incidents/checklists-autocomplete", true)
+	add.AddTextArgument("The title of the new item", "[title]", "")
+	task.AddCommand(add)
+
+	assign := model.NewAutocompleteData("assign", "[checklist item] [@username]",
+		"Assigns a checklist item, or unassigns it if no user is given")
+	addChecklistItemArgument(assign)
+	assign.AddTextArgument("The new assignee", "[@username]", "")
+	task.AddCommand(assign)
+
+	for _, command := range []struct{ name, helpText string }{
+		{"start", "Marks a checklist item as in progress"},
+		{"done", "Checks off a checklist item"},
+		{"skip", "Skips a checklist item"},
+		{"remove", "Removes a checklist item"},
+	} {
+		data := model.NewAutocompleteData(command.name, "[checklist item]", command.helpText)
+		addChecklistItemArgument(data)
+		task.AddCommand(data)
+	}
+
+	move := model.NewAutocompleteData("move", "[checklist item] [new item #]",
+		"Moves a checklist item within its checklist")
+	addChecklistItemArgument(move)
+	move.AddTextArgument("The new position of the item in its checklist", "[new item #]", "[0-9]+")
+	task.AddCommand(move)
+
+	list := model.NewAutocompleteData("list", "[--mine] [--open]", "Lists the checklist items")
+	list.AddStaticListArgument("Only lists some of the items", false, []model.AutocompleteListItem{
+		{Item: "--mine", HelpText: "Only lists the items assigned to you"},
+		{Item: "--open", HelpText: "Only lists the items not done or skipped yet"},
+	})
+	task.AddCommand(list)
+
+	return task
+}
+
+func addChecklistItemArgument(data *model.AutocompleteData) {
+	data.AddDynamicListArgument("List of checklist items is downloading from your Incident Collaboration plugin",
+		"api/v0/incidents/checklist-autocomplete", true)
+}
+
+func (r *Runner) actionTask(args []string) {
+	if len(args) == 0 {
+		r.postCommandResponse(taskHelpText)
+		return
+	}
+
+	incidentID, err := r.incidentService.GetIncidentIDForChannel(r.args.ChannelId)
+	if errors.Is(err, incident.ErrNotFound) {
+		r.postCommandResponse("You can only manage the checklists from within the incident's channel.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident for channel %s: %v", r.args.ChannelId, err)
+		return
+	}
+
+	if args[0] == "list" {
+		r.actionTaskList(incidentID, args[1:])
+		return
+	}
+
+	if err = permissions.EditIncident(r.args.UserId, incidentID, r.pluginAPI, r.incidentService); errors.Is(err, permissions.ErrNoPermissions) {
+		r.postCommandResponse("You do not have permissions to modify the checklists of this incident.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to incident %s: %v", incidentID, err)
+		return
+	}
+
+	currentIncident, err := r.incidentService.GetIncident(incidentID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		r.actionTaskAdd(currentIncident)
+	case "assign":
+		r.actionTaskAssign(currentIncident, args[1:])
+	case "start":
+		r.actionTaskSetState(currentIncident, args[1:], playbook.ChecklistItemStateInProgress)
+	case "done":
+		r.actionTaskSetState(currentIncident, args[1:], playbook.ChecklistItemStateClosed)
+	case "skip":
+		r.actionTaskSetState(currentIncident, args[1:], playbook.ChecklistItemStateSkipped)
+	case "remove":
+		r.actionTaskRemove(currentIncident, args[1:])
+	case "move":
+		r.actionTaskMove(currentIncident, args[1:])
+	default:
+		r.postCommandResponse(taskHelpText)
+	}
+}
+
+// parseChecklistItem parses the checklist and item numbers at the beginning of args, as given
+// by the checklist autocomplete, and checks that the item exists.
+func parseChecklistItem(theIncident *incident.Incident, args []string) (checklistNumber, itemNumber int, err error) {
+	if len(args) < 2 {
+		return 0, 0, errors.New("please give the number of the checklist and the number of the item, e.g. `0 2`")
+	}
+
+	checklistNumber, err = strconv.Atoi(args[0])
+	if err != nil || checklistNumber < 0 || checklistNumber >= len(theIncident.Checklists) {
+		return 0, 0, errors.Errorf("there is no checklist %s", args[0])
+	}
+
+	itemNumber, err = strconv.Atoi(args[1])
+	if err != nil || itemNumber < 0 || itemNumber >= len(theIncident.Checklists[checklistNumber].Items) {
+		return 0, 0, errors.Errorf("there is no item %s in checklist '%s'", args[1], theIncident.Checklists[checklistNumber].Title)
+	}
+
+	return checklistNumber, itemNumber, nil
+}
+
+func (r *Runner) actionTaskAdd(theIncident *incident.Incident) {
+	// The title is the rest of the line as typed, or a quoted title without its quotes, e.g.
+	// /incident task add 0 "Call the vendor".
+	args, err := r.quotedParameters()
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to add the item: %v.", err))
+		return
+	}
+	args = args[1:]
+
+	if len(args) < 2 {
+		r.postCommandResponse("Please give the number of the checklist and the title of the item, e.g. `/incident task add 0 Call the vendor`.")
+		return
+	}
+
+	checklistNumber, err := strconv.Atoi(args[0].value)
+	if err != nil || checklistNumber < 0 || checklistNumber >= len(theIncident.Checklists) {
+		r.postCommandResponse(fmt.Sprintf("Unable to add the item: there is no checklist %s.", args[0].value))
+		return
+	}
+
+	title := strings.TrimSpace(remainder(r.args.Command, args, 1))
+	if title == "" {
+		r.postCommandResponse("Unable to add the item: the title must not be empty.")
+		return
+	}
+
+	err = r.incidentService.AddChecklistItem(theIncident.ID, r.args.UserId, checklistNumber, playbook.ChecklistItem{
+		ID:    model.NewId(),
+		Title: title,
+		State: playbook.ChecklistItemStateOpen,
+	})
+	if err != nil {
+		r.warnUserAndLogErrorf("Error adding checklist item: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("Added **%s** to checklist '%s'.", title, theIncident.Checklists[checklistNumber].Title))
+}
+
+func (r *Runner) actionTaskAssign(theIncident *incident.Incident, args []string) {
+	checklistNumber, itemNumber, err := parseChecklistItem(theIncident, args)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to assign the item: %v.", err))
+		return
+	}
+
+	assigneeID := ""
+	if len(args) > 2 {
+		username := strings.TrimLeft(args[2], "@")
+		user, userErr := r.pluginAPI.User.GetByUsername(username)
+		if errors.Is(userErr, pluginapi.ErrNotFound) {
+			r.postCommandResponse(fmt.Sprintf("Unable to find user @%s", username))
+			return
+		} else if userErr != nil {
+			r.warnUserAndLogErrorf("Error finding user @%s: %v", username, userErr)
+			return
+		}
+		assigneeID = user.Id
+	}
+
+	if err = r.incidentService.SetAssignee(theIncident.ID, r.args.UserId, assigneeID, checklistNumber, itemNumber); err != nil {
+		r.warnUserAndLogErrorf("Error assigning checklist item: %v", err)
+		return
+	}
+}
+
+func (r *Runner) actionTaskSetState(theIncident *incident.Incident, args []string, newState string) {
+	checklistNumber, itemNumber, err := parseChecklistItem(theIncident, args)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to update the item: %v.", err))
+		return
+	}
+
+	if err = r.incidentService.ModifyCheckedState(theIncident.ID, r.args.UserId, newState, checklistNumber, itemNumber); err != nil {
+		r.warnUserAndLogErrorf("Error updating the state of checklist item: %v", err)
+		return
+	}
+}
+
+func (r *Runner) actionTaskRemove(theIncident *incident.Incident, args []string) {
+	checklistNumber, itemNumber, err := parseChecklistItem(theIncident, args)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to remove the item: %v.", err))
+		return
+	}
+
+	if err = r.incidentService.RemoveChecklistItem(theIncident.ID, r.args.UserId, checklistNumber, itemNumber); err != nil {
+		r.warnUserAndLogErrorf("Error removing checklist item: %v", err)
+		return
+	}
+
+	r.postCommandResponse(fmt.Sprintf("Removed **%s** from checklist '%s'.",
+		theIncident.Checklists[checklistNumber].Items[itemNumber].Title, theIncident.Checklists[checklistNumber].Title))
+}
+
+func (r *Runner) actionTaskMove(theIncident *incident.Incident, args []string) {
+	checklistNumber, itemNumber, err := parseChecklistItem(theIncident, args)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to move the item: %v.", err))
+		return
+	}
+
+	if len(args) < 3 {
+		r.postCommandResponse("Unable to move the item: please give its new position in the checklist.")
+		return
+	}
+
+	items := theIncident.Checklists[checklistNumber].Items
+	newLocation, err := strconv.Atoi(args[2])
+	if err != nil || newLocation < 0 || newLocation >= len(items) {
+		r.postCommandResponse(fmt.Sprintf("Unable to move the item: the new position must be between 0 and %d.", len(items)-1))
+		return
+	}
+
+	if err = r.incidentService.MoveChecklistItem(theIncident.ID, r.args.UserId, checklistNumber, itemNumber, newLocation); err != nil {
+		r.warnUserAndLogErrorf("Error moving checklist item: %v", err)
+		return
+	}
+}
+
+func (r *Runner) actionTaskList(incidentID string, args []string) {
+	mine, open := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--mine":
+			mine = true
+		case "--open":
+			open = true
+		default:
+			r.postCommandResponse(fmt.Sprintf("Unknown option %s, expected --mine or --open.", arg))
+			return
+		}
+	}
+
+	if err := permissions.ViewIncident(r.args.UserId, incidentID, r.pluginAPI, r.incidentService); errors.Is(err, permissions.ErrNoPermissions) {
+		r.postCommandResponse("You do not have permissions to view this incident.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to incident %s: %v", incidentID, err)
+		return
+	}
+
+	theIncident, err := r.incidentService.GetIncident(incidentID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving incident: %v", err)
+		return
+	}
+
+	usernames := map[string]string{}
+	var sb strings.Builder
+	for i, checklist := range theIncident.Checklists {
+		var lines []string
+		for j, item := range checklist.Items {
+			if mine && item.AssigneeID != r.args.UserId {
+				continue
+			}
+			if open && (item.State == playbook.ChecklistItemStateClosed || item.State == playbook.ChecklistItemStateSkipped) {
+				continue
+			}
+
+			line := fmt.Sprintf("- `%d %d` %s **%s**", i, j, taskStateEmoji(item.State), item.Title)
+			if item.AssigneeID != "" {
+				username, ok := usernames[item.AssigneeID]
+				if !ok {
+					if user, userErr := r.pluginAPI.User.Get(item.AssigneeID); userErr == nil {
+						username = user.Username
+					}
+					usernames[item.AssigneeID] = username
+				}
+				if username != "" {
+					line += " @" + username
+				}
+			}
+			lines = append(lines, line)
+		}
+
+		if len(lines) > 0 {
+			sb.WriteString(fmt.Sprintf("#### %s\n%s\n", checklist.Title, strings.Join(lines, "\n")))
+		}
+	}
+
+	if sb.Len() == 0 {
+		r.postCommandResponse("There are no checklist items to list.")
+		return
+	}
+
+	r.postCommandResponse(sb.String())
+}
+
+func taskStateEmoji(state string) string {
+	switch state {
+	case playbook.ChecklistItemStateInProgress:
+		return ":arrow_forward:"
+	case playbook.ChecklistItemStateClosed:
+		return ":white_check_mark:"
+	case playbook.ChecklistItemStateSkipped:
+		return ":fast_forward:"
+	default:
+		return ":white_large_square:"
+	}
+}
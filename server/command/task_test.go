@@ -0,0 +1,77 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestParseChecklistItem(t *testing.T) {
+	theIncident := &incident.Incident{
+		Checklists: []playbook.Checklist{
+			{Title: "Triage", Items: []playbook.ChecklistItem{{Title: "Acknowledge"}}},
+			{Title: "Mitigation", Items: []playbook.ChecklistItem{{Title: "Roll back"}, {Title: "Fail over"}}},
+		},
+	}
+
+	checklistNumber, itemNumber, err := parseChecklistItem(theIncident, []string{"1", "1", "@alice"})
+	require.NoError(t, err)
+	require.Equal(t, 1, checklistNumber)
+	require.Equal(t, 1, itemNumber)
+
+	_, _, err = parseChecklistItem(theIncident, []string{"1"})
+	require.Error(t, err)
+
+	_, _, err = parseChecklistItem(theIncident, []string{"2", "0"})
+	require.EqualError(t, err, "there is no checklist 2")
+
+	_, _, err = parseChecklistItem(theIncident, []string{"0", "one"})
+	require.EqualError(t, err, "there is no item one in checklist 'Triage'")
+}
+
+func TestActionTaskAdd(t *testing.T) {
+	theIncident := &incident.Incident{
+		ID:         "incident_id",
+		Checklists: []playbook.Checklist{{Title: "Triage"}},
+	}
+
+	for name, tc := range map[string]struct {
+		command string
+		title   string
+	}{
+		"apostrophe":   {command: "/incident task add 0 Call the vendor's support", title: "Call the vendor's support"},
+		"quoted title": {command: `/incident task add 0 "Page the DBA"`, title: "Page the DBA"},
+		"as typed":     {command: `/incident task add 0 Check C:\logs  for "errors"`, title: `Check C:\logs  for "errors"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			incidentService := mock_incident.NewMockService(controller)
+			poster := mock_bot.NewMockPoster(controller)
+
+			args := &model.CommandArgs{Command: tc.command, UserId: "user_id", ChannelId: "channel_id"}
+			r := NewCommandRunner(&plugin.Context{}, args, pluginapi.NewClient(&plugintest.API{}), nil, poster, incidentService, nil, nil)
+
+			incidentService.EXPECT().
+				AddChecklistItem("incident_id", "user_id", 0, gomock.Any()).
+				DoAndReturn(func(_, _ string, _ int, item playbook.ChecklistItem) error {
+					require.Equal(t, tc.title, item.Title)
+					return nil
+				})
+			poster.EXPECT().EphemeralPost("user_id", "channel_id", gomock.Any())
+
+			r.actionTaskAdd(theIncident)
+		})
+	}
+}
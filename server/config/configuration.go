@@ -25,6 +25,15 @@ type Configuration struct {
 
 	// AdminLogVerbose: set to include full context with admin log messages.
 	AdminLogVerbose bool
+
+	// ** The following are stored on the server
+
+	// AuditRetentionDays is how many days the records of the audit log are kept. 0 keeps them
+	// forever.
+	AuditRetentionDays int
+
+	// EnableIncidentCache keeps the incidents in memory between reads.
+	EnableIncidentCache bool
 }
 
 // Clone shallow copies the configuration. Your implementation may require a deep copy if
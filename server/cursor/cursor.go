@@ -0,0 +1,56 @@
+// Package cursor implements the opaque cursors used for keyset pagination: a cursor records the
+// sort of a list and the position of the last item of a page, so that the next page starts right
+// after it even if items were created or deleted in the meantime.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalid is returned when decoding a cursor that was not returned by Encode.
+var ErrInvalid = errors.New("invalid cursor")
+
+// Cursor is the position of an item in a sorted list.
+type Cursor struct {
+	// Sort and Direction are the sort of the list the cursor was returned for. A cursor is only
+	// valid for the same sort.
+	Sort      string `json:"s"`
+	Direction string `json:"d"`
+
+	// Value is the value of the sort column of the item, and ID its ID, which breaks ties.
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+// Encode returns the cursor as an opaque string, safe to use in URLs.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor returned by Encode.
+func Decode(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort == "" || c.Direction == "" || c.ID == "" {
+		return Cursor{}, ErrInvalid
+	}
+
+	return c, nil
+}
+
+// Check returns an error if the cursor was returned for a different sort than the given one.
+func (c Cursor) Check(sort, direction string) error {
+	if c.Sort != sort || c.Direction != direction {
+		return errors.Wrapf(ErrInvalid, "cursor is for sort '%s %s', not '%s %s'", c.Sort, c.Direction, sort, direction)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	c := Cursor{Sort: "CreateAt", Direction: "desc", Value: "1600000000000", ID: "abcdefghijklmnopqrstuvwxyz"}
+
+	decoded, err := Decode(c.Encode())
+	require.NoError(t, err)
+	require.Equal(t, c, decoded)
+	require.NoError(t, decoded.Check("CreateAt", "desc"))
+	require.True(t, errors.Is(decoded.Check("CreateAt", "asc"), ErrInvalid))
+
+	for _, invalid := range []string{"", "not base64!", "bm90IGpzb24", Cursor{Sort: "CreateAt"}.Encode()} {
+		_, err = Decode(invalid)
+		require.Equal(t, ErrInvalid, err, invalid)
+	}
+}
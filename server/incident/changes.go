@@ -0,0 +1,84 @@
+package incident
+
+import (
+	"fmt"
+)
+
+// incidentChangedWSEvent is sent instead of incidentUpdatedWSEvent for the most frequent changes,
+// with only what changed rather than the whole incident.
+const incidentChangedWSEvent = "incident_changed"
+
+// Paths of the changed values sent in a ChangeEvent, named after the JSON fields of the incident.
+const (
+	CommanderPath        = "commander_user_id"
+	StatusPostsPath      = "status_posts"
+	EndAtPath            = "end_at"
+	PreviousReminderPath = "previous_reminder"
+	ReminderPostIDPath   = "reminder_post_id"
+	CommanderHandoffPath = "commander_handoff"
+
+	// PropertylistItemsPath is the path of all the properties, for changes moving them around.
+	PropertylistItemsPath = "propertylist.items"
+)
+
+// ChecklistItemPath is the path of the item itemNumber of the checklist checklistNumber.
+func ChecklistItemPath(checklistNumber, itemNumber int) string {
+	return fmt.Sprintf("checklists[%d].items[%d]", checklistNumber, itemNumber)
+}
+
+// ChecklistItemsPath is the path of all the items of the checklist checklistNumber, for changes
+// moving them around.
+func ChecklistItemsPath(checklistNumber int) string {
+	return fmt.Sprintf("checklists[%d].items", checklistNumber)
+}
+
+// PropertyPath is the path of the property itemNumber of the propertylist.
+func PropertyPath(itemNumber int) string {
+	return fmt.Sprintf("propertylist.items[%d]", itemNumber)
+}
+
+// Change is a value of an incident replaced as a whole.
+type Change struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// ChangeEvent describes the changes made to an incident between two of its versions. A client
+// holding the incident at PreviousVersion applies the changes and appends the timeline events to
+// get the incident at Version; any other client has missed a change and must get the whole
+// incident again.
+type ChangeEvent struct {
+	IncidentID      string          `json:"incident_id"`
+	PreviousVersion int64           `json:"previous_version"`
+	Version         int64           `json:"version"`
+	Changes         []Change        `json:"changes"`
+	TimelineEvents  []TimelineEvent `json:"timeline_events"`
+}
+
+// NewChangeEvent returns the event describing the changes made to theIncident since
+// previousVersion, along with the timeline events created meanwhile.
+func NewChangeEvent(theIncident *Incident, previousVersion int64, events []*TimelineEvent, changes ...Change) ChangeEvent {
+	timelineEvents := make([]TimelineEvent, 0, len(events))
+	for _, event := range events {
+		timelineEvents = append(timelineEvents, *event)
+	}
+
+	if changes == nil {
+		changes = []Change{}
+	}
+
+	return ChangeEvent{
+		IncidentID:      theIncident.ID,
+		PreviousVersion: previousVersion,
+		Version:         theIncident.Version,
+		Changes:         changes,
+		TimelineEvents:  timelineEvents,
+	}
+}
+
+// sendChangesToClient sends to the incident channel the changes made to theIncident since
+// previousVersion, once theIncident has been updated in the store.
+func (s *ServiceImpl) sendChangesToClient(theIncident *Incident, previousVersion int64, events []*TimelineEvent, changes ...Change) {
+	s.poster.PublishWebsocketEventToChannel(incidentChangedWSEvent,
+		NewChangeEvent(theIncident, previousVersion, events, changes...), theIncident.ChannelID)
+}
@@ -0,0 +1,123 @@
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+)
+
+func TestNewChangeEvent(t *testing.T) {
+	theIncident := &Incident{ID: "incident_id", Version: 8}
+	event := &TimelineEvent{ID: "event_id", EventType: TaskStateModified}
+
+	changeEvent := NewChangeEvent(theIncident, 7, []*TimelineEvent{event},
+		Change{Path: ChecklistItemPath(1, 2), Value: playbook.ChecklistItem{State: playbook.ChecklistItemStateClosed}})
+
+	data, err := json.Marshal(changeEvent)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, "incident_id", decoded["incident_id"])
+	require.Equal(t, 7.0, decoded["previous_version"])
+	require.Equal(t, 8.0, decoded["version"])
+	require.Equal(t, "checklists[1].items[2]", decoded["changes"].([]interface{})[0].(map[string]interface{})["path"])
+	require.Len(t, decoded["timeline_events"], 1)
+
+	data, err = json.Marshal(NewChangeEvent(theIncident, 7, nil))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"changes":[],"timeline_events":[]`)
+}
+
+// BenchmarkPayloadFullIncident and BenchmarkPayloadChangeEvent measure the websocket payloads sent
+// when checking off an item of an incident of the given size, in bytes/payload.
+func BenchmarkPayloadFullIncident(b *testing.B) {
+	for _, size := range []int{1, 10, 50} {
+		theIncident := benchmarkIncident(size)
+
+		b.Run(fmt.Sprintf("checklists=%d", size), func(b *testing.B) {
+			var data []byte
+			for n := 0; n < b.N; n++ {
+				data, _ = json.Marshal(theIncident)
+			}
+			b.ReportMetric(float64(len(data)), "bytes/payload")
+		})
+	}
+}
+
+func BenchmarkPayloadChangeEvent(b *testing.B) {
+	for _, size := range []int{1, 10, 50} {
+		theIncident := benchmarkIncident(size)
+		item := theIncident.Checklists[0].Items[0]
+		event := theIncident.TimelineEvents[0]
+
+		b.Run(fmt.Sprintf("checklists=%d", size), func(b *testing.B) {
+			var data []byte
+			for n := 0; n < b.N; n++ {
+				data, _ = json.Marshal(NewChangeEvent(theIncident, theIncident.Version-1, []*TimelineEvent{&event},
+					Change{Path: ChecklistItemPath(0, 0), Value: item}))
+			}
+			b.ReportMetric(float64(len(data)), "bytes/payload")
+		})
+	}
+}
+
+// benchmarkIncident returns an incident with numChecklists checklists of 20 items, each item
+// having been checked off once, and one status update per checklist.
+func benchmarkIncident(numChecklists int) *Incident {
+	theIncident := &Incident{
+		ID:              model.NewId(),
+		Name:            "Benchmark incident",
+		CommanderUserID: model.NewId(),
+		TeamID:          model.NewId(),
+		ChannelID:       model.NewId(),
+		Version:         int64(numChecklists * 21),
+	}
+
+	for i := 0; i < numChecklists; i++ {
+		checklist := playbook.Checklist{ID: model.NewId(), Title: fmt.Sprintf("Checklist %d", i)}
+		for j := 0; j < 20; j++ {
+			postID := model.NewId()
+			checklist.Items = append(checklist.Items, playbook.ChecklistItem{
+				ID:                  model.NewId(),
+				Title:               fmt.Sprintf("Check the dashboards of service %d", j),
+				State:               playbook.ChecklistItemStateClosed,
+				StateModified:       model.GetMillis(),
+				StateModifiedPostID: postID,
+				AssigneeID:          model.NewId(),
+				Command:             "/echo done",
+				Description:         "Look for errors and latency spikes since the start of the incident.",
+			})
+			theIncident.TimelineEvents = append(theIncident.TimelineEvents, TimelineEvent{
+				ID:            model.NewId(),
+				IncidentID:    theIncident.ID,
+				CreateAt:      model.GetMillis(),
+				EventAt:       model.GetMillis(),
+				EventType:     TaskStateModified,
+				Summary:       fmt.Sprintf("checked off checklist item **Check the dashboards of service %d**", j),
+				PostID:        postID,
+				SubjectUserID: theIncident.CommanderUserID,
+			})
+		}
+		theIncident.Checklists = append(theIncident.Checklists, checklist)
+
+		statusPost := StatusPost{ID: model.NewId(), Status: StatusActive, CreateAt: model.GetMillis()}
+		theIncident.StatusPosts = append(theIncident.StatusPosts, statusPost)
+		theIncident.TimelineEvents = append(theIncident.TimelineEvents, TimelineEvent{
+			ID:            model.NewId(),
+			IncidentID:    theIncident.ID,
+			CreateAt:      statusPost.CreateAt,
+			EventAt:       statusPost.CreateAt,
+			EventType:     StatusUpdated,
+			PostID:        statusPost.ID,
+			SubjectUserID: theIncident.CommanderUserID,
+		})
+	}
+
+	return theIncident
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package incident
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+)
+
+// ChecklistOperationType identifies the kind of change made by a ChecklistOperation.
+type ChecklistOperationType string
+
+const (
+	// ChecklistOperationSetState changes the state of a checklist item.
+	ChecklistOperationSetState ChecklistOperationType = "set_state"
+
+	// ChecklistOperationSetAssignee changes the assignee of a checklist item.
+	ChecklistOperationSetAssignee ChecklistOperationType = "set_assignee"
+
+	// ChecklistOperationRename changes the title and command of a checklist item.
+	ChecklistOperationRename ChecklistOperationType = "rename"
+
+	// ChecklistOperationMove moves a checklist item to a new location in its checklist.
+	ChecklistOperationMove ChecklistOperationType = "move"
+
+	// ChecklistOperationAdd appends a new item to a checklist.
+	ChecklistOperationAdd ChecklistOperationType = "add"
+
+	// ChecklistOperationRemove removes an item from a checklist.
+	ChecklistOperationRemove ChecklistOperationType = "remove"
+)
+
+// ChecklistOperation is a single change in a batch of checklist changes. Operations are applied
+// in order, so the indices of each operation refer to the checklists as left by the previous ones.
+type ChecklistOperation struct {
+	Type            ChecklistOperationType  `json:"type"`
+	ChecklistNumber int                     `json:"checklist"`
+	ItemNumber      int                     `json:"item"`
+	NewState        string                  `json:"new_state,omitempty"`
+	AssigneeID      string                  `json:"assignee_id,omitempty"`
+	Title           string                  `json:"title,omitempty"`
+	Command         string                  `json:"command,omitempty"`
+	NewLocation     int                     `json:"new_location,omitempty"`
+	ChecklistItem   *playbook.ChecklistItem `json:"checklist_item,omitempty"`
+}
+
+// checklistChange is the outcome of applying a single ChecklistOperation.
+type checklistChange struct {
+	operationType ChecklistOperationType
+	message       string
+	newState      string
+	wasAssignee   bool
+}
+
+// BatchChecklistOperations applies all the given operations to the incident's checklists at once.
+// The operations are validated before anything is modified: if any of them is invalid, none is
+// applied. A single summary message is posted, the incident and its timeline events are saved in
+// one transaction, and a single websocket event is sent.
+func (s *ServiceImpl) BatchChecklistOperations(incidentID, userID string, operations []ChecklistOperation) error {
+	incidentToModify, err := s.store.GetIncident(incidentID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve incident")
+	}
+
+	if !s.hasPermissionToModifyIncident(incidentToModify, userID) {
+		return errors.New("user does not have permission to modify incident")
+	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	usernames := map[string]string{}
+	getUsername := func(id string) (string, error) {
+		if username, ok := usernames[id]; ok {
+			return username, nil
+		}
+		user, userErr := s.pluginAPI.User.Get(id)
+		if userErr != nil {
+			return "", errors.Wrapf(userErr, "failed to to resolve user %s", id)
+		}
+		usernames[id] = user.Username
+		return user.Username, nil
+	}
+
+	// Dry run on a copy, so that we neither post a message nor modify anything unless every
+	// operation in the batch can be applied.
+	changes, err := applyChecklistOperations(incidentToModify.Clone(), userID, operations, "", 0, getUsername)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(changes))
+	for _, change := range changes {
+		messages = append(messages, change.message)
+	}
+
+	summary := messages[0]
+	if len(messages) > 1 {
+		summary = "made the following checklist changes:\n* " + strings.Join(messages, "\n* ")
+	}
+
+	// Send modification message before the actual modification because we need the postID
+	// from the notification message.
+	post, err := s.modificationMessage(userID, incidentToModify.ChannelID, summary)
+	if err != nil {
+		return err
+	}
+
+	now := model.GetMillis()
+	if _, err = applyChecklistOperations(incidentToModify, userID, operations, post.Id, now, getUsername); err != nil {
+		return err
+	}
+
+	events := groupChecklistTimelineEvents(incidentID, userID, post.Id, now, changes)
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: events,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			for _, change := range changes {
+				o.telemetry(checklistChangeTelemetry(incidentToModify, userID, change))
+			}
+			o.publishChanges(incidentToModify, previousVersion, events, checklistOperationChanges(incidentToModify, operations)...)
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
+	}
+	s.recordChange(userID, "batch_checklist_operations", before, incidentToModify)
+
+	return nil
+}
+
+// checklistOperationChanges returns the changes made to theIncident by operations: the items
+// changed in place, and the items of the checklists where items were added, removed or moved.
+func checklistOperationChanges(theIncident *Incident, operations []ChecklistOperation) []Change {
+	reordered := make(map[int]bool)
+	for _, operation := range operations {
+		switch operation.Type {
+		case ChecklistOperationMove, ChecklistOperationAdd, ChecklistOperationRemove:
+			reordered[operation.ChecklistNumber] = true
+		}
+	}
+
+	var changes []Change
+	changed := make(map[string]bool)
+	for _, operation := range operations {
+		checklistNumber, itemNumber := operation.ChecklistNumber, operation.ItemNumber
+
+		var change Change
+		if reordered[checklistNumber] {
+			change = Change{Path: ChecklistItemsPath(checklistNumber), Value: theIncident.Checklists[checklistNumber].Items}
+		} else {
+			change = Change{Path: ChecklistItemPath(checklistNumber, itemNumber), Value: theIncident.Checklists[checklistNumber].Items[itemNumber]}
+		}
+
+		if !changed[change.Path] {
+			changed[change.Path] = true
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// checklistChangeTelemetry returns the telemetry event tracking change, made by userID.
+func checklistChangeTelemetry(theIncident *Incident, userID string, change checklistChange) telemetryPayload {
+	payload := telemetryPayload{IncidentID: theIncident.ID, UserID: userID}
+
+	switch change.operationType {
+	case ChecklistOperationSetState:
+		payload.Event = telemetryModifyCheckedState
+		payload.NewState = change.newState
+		payload.WasCommander = theIncident.CommanderUserID == userID
+		payload.WasAssignee = change.wasAssignee
+	case ChecklistOperationSetAssignee:
+		payload.Event = telemetrySetAssignee
+	case ChecklistOperationRename:
+		payload.Event = telemetryRenameTask
+	case ChecklistOperationMove:
+		payload.Event = telemetryMoveTask
+	case ChecklistOperationAdd:
+		payload.Event = telemetryAddTask
+	case ChecklistOperationRemove:
+		payload.Event = telemetryRemoveTask
+	}
+
+	return payload
+}
+
+// applyChecklistOperations applies operations, in order, to the checklists of incdnt, returning
+// the changes made. Operations that would leave an item untouched produce no change.
+func applyChecklistOperations(incdnt *Incident, userID string, operations []ChecklistOperation, postID string, now int64, getUsername func(string) (string, error)) ([]checklistChange, error) {
+	var changes []checklistChange
+
+	for i, op := range operations {
+		invalid := func(reason string) error {
+			return errors.Wrapf(ErrInvalidChecklistOperation, "operation %d (%s): %s", i, op.Type, reason)
+		}
+
+		if op.ChecklistNumber < 0 || op.ChecklistNumber >= len(incdnt.Checklists) {
+			return nil, invalid("invalid checklist number")
+		}
+		checklist := &incdnt.Checklists[op.ChecklistNumber]
+
+		if op.Type != ChecklistOperationAdd &&
+			!playbook.IsValidChecklistItemIndex(incdnt.Checklists, op.ChecklistNumber, op.ItemNumber) {
+			return nil, invalid("invalid item number")
+		}
+
+		switch op.Type {
+		case ChecklistOperationSetState:
+			if !playbook.IsValidChecklistItemState(op.NewState) {
+				return nil, invalid("invalid new state")
+			}
+
+			item := &checklist.Items[op.ItemNumber]
+			if item.State == op.NewState {
+				continue
+			}
+
+			message := checkedStateMessage(item.Title, op.NewState)
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message:       message,
+				newState:      op.NewState,
+				wasAssignee:   item.AssigneeID == userID,
+			})
+
+			item.State = op.NewState
+			item.StateModified = now
+			item.StateModifiedPostID = postID
+
+		case ChecklistOperationSetAssignee:
+			item := &checklist.Items[op.ItemNumber]
+			if item.AssigneeID == op.AssigneeID {
+				continue
+			}
+
+			newAssigneeUsername := noAssigneeName
+			if op.AssigneeID != "" {
+				username, err := getUsername(op.AssigneeID)
+				if err != nil {
+					return nil, err
+				}
+				newAssigneeUsername = "@" + username
+			}
+
+			oldAssigneeUsername := noAssigneeName
+			if item.AssigneeID != "" {
+				username, err := getUsername(item.AssigneeID)
+				if err != nil {
+					return nil, err
+				}
+				oldAssigneeUsername = username
+			}
+
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message: fmt.Sprintf("changed assignee of checklist item **%s** from **%s** to **%s**",
+					stripmd.Strip(item.Title), oldAssigneeUsername, newAssigneeUsername),
+			})
+
+			item.AssigneeID = op.AssigneeID
+			item.AssigneeModified = now
+			item.AssigneeModifiedPostID = postID
+
+		case ChecklistOperationRename:
+			newTitle := strings.TrimSpace(op.Title)
+			if newTitle == "" {
+				return nil, invalid("checklist item title must not be blank")
+			}
+
+			item := &checklist.Items[op.ItemNumber]
+			if item.Title == newTitle && item.Command == op.Command {
+				continue
+			}
+
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message: fmt.Sprintf("renamed checklist item **%s** to **%s**",
+					stripmd.Strip(item.Title), stripmd.Strip(newTitle)),
+			})
+
+			item.Title = newTitle
+			item.Command = op.Command
+
+		case ChecklistOperationMove:
+			if op.NewLocation < 0 || op.NewLocation >= len(checklist.Items) {
+				return nil, invalid("invalid new location")
+			}
+			if op.NewLocation == op.ItemNumber {
+				continue
+			}
+
+			itemMoved := checklist.Items[op.ItemNumber]
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message:       fmt.Sprintf("moved checklist item **%s**", stripmd.Strip(itemMoved.Title)),
+			})
+
+			// Delete item to move
+			items := append(checklist.Items[:op.ItemNumber], checklist.Items[op.ItemNumber+1:]...)
+			// Insert item in new location
+			items = append(items, playbook.ChecklistItem{})
+			copy(items[op.NewLocation+1:], items[op.NewLocation:])
+			items[op.NewLocation] = itemMoved
+			checklist.Items = items
+
+		case ChecklistOperationAdd:
+			if op.ChecklistItem == nil {
+				return nil, invalid("missing checklist item")
+			}
+
+			newItem := *op.ChecklistItem
+			newItem.Title = strings.TrimSpace(newItem.Title)
+			if newItem.Title == "" {
+				return nil, invalid("checklist item title must not be blank")
+			}
+
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message:       fmt.Sprintf("added checklist item **%s**", stripmd.Strip(newItem.Title)),
+			})
+
+			checklist.Items = append(checklist.Items, newItem)
+
+		case ChecklistOperationRemove:
+			changes = append(changes, checklistChange{
+				operationType: op.Type,
+				message:       fmt.Sprintf("removed checklist item **%s**", stripmd.Strip(checklist.Items[op.ItemNumber].Title)),
+			})
+
+			checklist.Items = append(checklist.Items[:op.ItemNumber], checklist.Items[op.ItemNumber+1:]...)
+
+		default:
+			return nil, invalid("unknown operation type")
+		}
+	}
+
+	return changes, nil
+}
+
+// groupChecklistTimelineEvents builds one timeline event per kind of change recorded in the
+// timeline, summarizing all the changes of that kind made in a batch.
+func groupChecklistTimelineEvents(incidentID, userID, postID string, now int64, changes []checklistChange) []*TimelineEvent {
+	groups := []struct {
+		operationType ChecklistOperationType
+		eventType     timelineEventType
+		summary       string
+	}{
+		{ChecklistOperationSetState, TaskStateModified, "modified the state of %d checklist items"},
+		{ChecklistOperationSetAssignee, AssigneeChanged, "changed the assignee of %d checklist items"},
+	}
+
+	var events []*TimelineEvent
+	for _, group := range groups {
+		var messages []string
+		for _, change := range changes {
+			if change.operationType == group.operationType {
+				messages = append(messages, change.message)
+			}
+		}
+
+		if len(messages) == 0 {
+			continue
+		}
+
+		event := &TimelineEvent{
+			IncidentID:    incidentID,
+			CreateAt:      now,
+			EventAt:       now,
+			EventType:     group.eventType,
+			Summary:       messages[0],
+			PostID:        postID,
+			SubjectUserID: userID,
+		}
+		if len(messages) > 1 {
+			event.Summary = fmt.Sprintf(group.summary, len(messages))
+			event.Details = strings.Join(messages, "\n")
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
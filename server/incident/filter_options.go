@@ -3,6 +3,7 @@ package incident
 import (
 	"strings"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
 )
@@ -11,13 +12,22 @@ const PerPageDefault = 1000
 
 // FilterOptions specifies the optional parameters when getting headers.
 type FilterOptions struct {
-	// Gets all the headers with this TeamID.
+	// Gets all the headers with this TeamID. Optional: without TeamID and TeamIDs, the incidents
+	// of all the teams the requester can see are returned.
 	TeamID string
 
+	// TeamIDs gets all the headers of any of these teams, on top of TeamID.
+	TeamIDs []string
+
 	// Pagination options.
 	Page    int
 	PerPage int
 
+	// Cursor is the next_cursor of the previous page, to get the page right after it instead of
+	// using Page. It must be used with the same sort and filters as the previous page, and cannot
+	// be used when sorting by property.
+	Cursor string
+
 	// Sort sorts by this header field in json format (eg, "create_at", "end_at", "name", etc.);
 	// defaults to "create_at".
 	Sort string
@@ -28,6 +38,31 @@ type FilterOptions struct {
 	// Status filters by current status
 	Status string
 
+	// Statuses filters by any of these current statuses, on top of Status.
+	Statuses []string
+
+	// PlaybookID filters by the playbook the incidents were created from. Defaults to blank (no filter).
+	PlaybookID string
+
+	// CreatedAfter and CreatedBefore filter by creation time, in milliseconds since the epoch.
+	// Both bounds are inclusive; 0 means no bound.
+	CreatedAfter  int64
+	CreatedBefore int64
+
+	// EndedAfter and EndedBefore filter by end time, in milliseconds since the epoch. Both
+	// bounds are inclusive; 0 means no bound. Incidents that have not ended never match a bound.
+	EndedAfter  int64
+	EndedBefore int64
+
+	// PropertyValues filters by property values: the keys are property titles, matched ignoring
+	// case, and an incident matches a key if its property has any of the given values. Selection
+	// properties match the names of their options, not their IDs.
+	PropertyValues map[string][]string
+
+	// SortProperty is the title of the property to sort by when Sort is "property". Incidents
+	// without a value for the property are sorted last.
+	SortProperty string
+
 	// CommanderID filters by commander's Mattermost user ID. Defaults to blank (no filter).
 	CommanderID string
 
@@ -38,6 +73,10 @@ type FilterOptions struct {
 	// The search term acts as a filter and respects the Sort and Direction fields (i.e., results are
 	// not returned in relevance order).
 	SearchTerm string
+
+	// Fields selects what is returned of each incident: all of it by default, or only the fields
+	// shown by list views with FieldsSummary.
+	Fields string
 }
 
 const (
@@ -48,9 +87,14 @@ const (
 	SortByTeamID          = "team_id"
 	SortByEndAt           = "end_at"
 	SortByStatus          = "status"
+	SortByProperty        = "property"
 
 	DirectionAsc  = "asc"
 	DirectionDesc = "desc"
+
+	// FieldsSummary leaves out the checklists, properties, status posts and timeline events of
+	// the incidents, and sets their Summary instead.
+	FieldsSummary = "summary"
 )
 
 func IsValidSortBy(sortBy string) bool {
@@ -60,7 +104,9 @@ func IsValidSortBy(sortBy string) bool {
 		SortByName,
 		SortByCommanderUserID,
 		SortByTeamID,
-		SortByEndAt:
+		SortByEndAt,
+		SortByStatus,
+		SortByProperty:
 		return true
 	}
 
@@ -76,8 +122,14 @@ func ValidateOptions(options *FilterOptions) error {
 		options.PerPage = PerPageDefault
 	}
 
-	if !model.IsValidId(options.TeamID) {
-		return errors.New("bad parameter 'team_id': must be 26 characters")
+	if options.TeamID != "" {
+		options.TeamIDs = append(options.TeamIDs, options.TeamID)
+		options.TeamID = ""
+	}
+	for _, teamID := range options.TeamIDs {
+		if !model.IsValidId(teamID) {
+			return errors.New("bad parameter 'team_id': must be 26 characters")
+		}
 	}
 
 	sort := strings.ToLower(options.Sort)
@@ -96,6 +148,11 @@ func ValidateOptions(options *FilterOptions) error {
 		options.Sort = "EndAt"
 	case SortByStatus:
 		options.Sort = "CurrentStatus"
+	case SortByProperty:
+		if strings.TrimSpace(options.SortProperty) == "" {
+			return errors.New("bad parameter 'sort_property': required when sorting by property")
+		}
+		options.Sort = SortByProperty
 	default:
 		return errors.New("bad parameter 'sort'")
 	}
@@ -118,5 +175,66 @@ func ValidateOptions(options *FilterOptions) error {
 		return errors.New("bad parameter 'member_id': must be 26 characters or blank")
 	}
 
+	if options.PlaybookID != "" && !model.IsValidId(options.PlaybookID) {
+		return errors.New("bad parameter 'playbook_id': must be 26 characters or blank")
+	}
+
+	if options.Status != "" {
+		options.Statuses = append(options.Statuses, options.Status)
+		options.Status = ""
+	}
+	for _, status := range options.Statuses {
+		if !isValidStatus(status) {
+			return errors.Errorf("bad parameter 'status': unknown status '%s'", status)
+		}
+	}
+
+	if options.CreatedAfter < 0 || options.CreatedBefore < 0 ||
+		(options.CreatedBefore != 0 && options.CreatedAfter > options.CreatedBefore) {
+		return errors.New("bad parameters 'created_after' and 'created_before': must be a valid range")
+	}
+
+	if options.EndedAfter < 0 || options.EndedBefore < 0 ||
+		(options.EndedBefore != 0 && options.EndedAfter > options.EndedBefore) {
+		return errors.New("bad parameters 'ended_after' and 'ended_before': must be a valid range")
+	}
+
+	for title, values := range options.PropertyValues {
+		if strings.TrimSpace(title) == "" {
+			return errors.New("bad parameter 'property': the property name must not be blank")
+		}
+		if len(values) == 0 {
+			return errors.Errorf("bad parameter 'property[%s]': at least one value is required", title)
+		}
+	}
+
+	if options.Fields != "" && options.Fields != FieldsSummary {
+		return errors.Errorf("bad parameter 'fields': unknown fields '%s'", options.Fields)
+	}
+
+	if options.Cursor != "" {
+		if options.Page != 0 {
+			return errors.Wrap(cursor.ErrInvalid, "bad parameter 'cursor': cannot be used with 'page'")
+		}
+		if options.Sort == SortByProperty {
+			return errors.Wrap(cursor.ErrInvalid, "bad parameter 'cursor': cannot be used when sorting by property")
+		}
+		c, err := cursor.Decode(options.Cursor)
+		if err != nil {
+			return errors.Wrap(err, "bad parameter 'cursor'")
+		}
+		if err := c.Check(options.Sort, options.Direction); err != nil {
+			return errors.Wrap(err, "bad parameter 'cursor'")
+		}
+	}
+
 	return nil
 }
+
+func isValidStatus(status string) bool {
+	switch status {
+	case StatusReported, StatusActive, StatusResolved, StatusArchived:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package incident
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOptions(t *testing.T) {
+	teamID := model.NewId()
+
+	t.Run("status is folded into statuses", func(t *testing.T) {
+		options := FilterOptions{TeamID: teamID, Status: StatusActive, Statuses: []string{StatusReported}}
+		require.NoError(t, ValidateOptions(&options))
+		require.Empty(t, options.Status)
+		require.Equal(t, []string{StatusReported, StatusActive}, options.Statuses)
+	})
+
+	t.Run("team is folded into teams", func(t *testing.T) {
+		otherTeamID := model.NewId()
+		options := FilterOptions{TeamID: teamID, TeamIDs: []string{otherTeamID}}
+		require.NoError(t, ValidateOptions(&options))
+		require.Empty(t, options.TeamID)
+		require.Equal(t, []string{otherTeamID, teamID}, options.TeamIDs)
+	})
+
+	t.Run("all teams", func(t *testing.T) {
+		options := FilterOptions{}
+		require.NoError(t, ValidateOptions(&options))
+		require.Empty(t, options.TeamIDs)
+	})
+
+	t.Run("sort by property", func(t *testing.T) {
+		options := FilterOptions{TeamID: teamID, Sort: SortByProperty, SortProperty: "Impact", Direction: "DESC"}
+		require.NoError(t, ValidateOptions(&options))
+		require.Equal(t, SortByProperty, options.Sort)
+		require.Equal(t, DirectionDesc, options.Direction)
+
+		options = FilterOptions{TeamID: teamID, Sort: SortByProperty}
+		require.Error(t, ValidateOptions(&options))
+	})
+
+	testCases := map[string]FilterOptions{
+		"invalid team id":        {TeamIDs: []string{teamID, "team"}},
+		"unknown status":         {TeamID: teamID, Statuses: []string{"Sleeping"}},
+		"invalid playbook id":    {TeamID: teamID, PlaybookID: "playbook"},
+		"inverted created range": {TeamID: teamID, CreatedAfter: 2000, CreatedBefore: 1000},
+		"negative ended bound":   {TeamID: teamID, EndedAfter: -1},
+		"blank property title":   {TeamID: teamID, PropertyValues: map[string][]string{" ": {"High"}}},
+		"property without value": {TeamID: teamID, PropertyValues: map[string][]string{"Impact": {}}},
+		"malformed cursor":       {TeamID: teamID, Cursor: "garbage"},
+		"unknown fields":         {TeamID: teamID, Fields: "name"},
+		"cursor for another sort": {TeamID: teamID, Sort: SortByName,
+			Cursor: cursor.Cursor{Sort: "CreateAt", Direction: DirectionAsc, ID: model.NewId()}.Encode()},
+		"cursor with a page": {TeamID: teamID, Page: 2,
+			Cursor: cursor.Cursor{Sort: "CreateAt", Direction: DirectionAsc, ID: model.NewId()}.Encode()},
+		"cursor with property sort": {TeamID: teamID, Sort: SortByProperty, SortProperty: "Impact",
+			Cursor: cursor.Cursor{Sort: SortByProperty, Direction: DirectionAsc, ID: model.NewId()}.Encode()},
+	}
+	for name, options := range testCases {
+		options := options
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, ValidateOptions(&options))
+		})
+	}
+
+	t.Run("cursor", func(t *testing.T) {
+		options := FilterOptions{
+			TeamID:    teamID,
+			Sort:      SortByEndAt,
+			Direction: DirectionDesc,
+			Cursor:    cursor.Cursor{Sort: "EndAt", Direction: DirectionDesc, Value: "1000", ID: model.NewId()}.Encode(),
+		}
+		require.NoError(t, ValidateOptions(&options))
+	})
+
+	t.Run("valid filters", func(t *testing.T) {
+		options := FilterOptions{
+			TeamID:         teamID,
+			PlaybookID:     model.NewId(),
+			CreatedAfter:   1000,
+			EndedBefore:    2000,
+			PropertyValues: map[string][]string{"Impact": {"High"}},
+			Fields:         FieldsSummary,
+		}
+		require.NoError(t, ValidateOptions(&options))
+	})
+}
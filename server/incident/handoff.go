@@ -0,0 +1,369 @@
+package incident
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+)
+
+// CommanderHandoffTimeout is how long the proposed commander has to answer a handoff request.
+const CommanderHandoffTimeout = time.Hour
+
+// handoffKeyPrefix prefixes the incident ID in the key of the job expiring a commander handoff,
+// to tell it apart from the reminders.
+const handoffKeyPrefix = "handoff_"
+
+// CommanderHandoff is a request from the commander of an incident to hand command over to
+// another user, waiting for their answer.
+type CommanderHandoff struct {
+	FromUserID  string `json:"from_user_id"`
+	ToUserID    string `json:"to_user_id"`
+	Notes       string `json:"notes"`
+	RequestedAt int64  `json:"requested_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+
+	// PostID is the direct message asking ToUserID to accept or decline.
+	PostID string `json:"post_id"`
+}
+
+// RequestCommanderHandoff processes a request from userID, the commander of incidentID, to hand
+// command over to commanderID with the given notes.
+func (s *ServiceImpl) RequestCommanderHandoff(incidentID, userID, commanderID, notes string) error {
+	incidentToModify, err := s.store.GetIncident(incidentID)
+	if err != nil {
+		return err
+	}
+
+	if incidentToModify.CommanderUserID != userID {
+		return errors.Wrap(ErrPermission, "only the commander can hand command over")
+	}
+	if commanderID == userID {
+		return errors.Wrap(ErrMalformedIncident, "the commander cannot hand command over to themselves")
+	}
+	if !incidentToModify.IsActive() {
+		return ErrIncidentNotActive
+	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	fromUser, err := s.pluginAPI.User.Get(userID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve user %s", userID)
+	}
+	toUser, err := s.pluginAPI.User.Get(commanderID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve user %s", commanderID)
+	}
+	if toUser.IsBot || toUser.DeleteAt != 0 {
+		return errors.Wrapf(ErrMalformedIncident, "@%s cannot take command of an incident", toUser.Username)
+	}
+
+	previous := incidentToModify.CommanderHandoff
+
+	now := time.Now()
+	handoff := &CommanderHandoff{
+		FromUserID:  userID,
+		ToUserID:    commanderID,
+		Notes:       strings.TrimSpace(notes),
+		RequestedAt: model.GetMillisForTime(now),
+		ExpiresAt:   model.GetMillisForTime(now.Add(CommanderHandoffTimeout)),
+	}
+
+	post, err := s.poster.DMWithAttachments(commanderID, s.handoffAttachment(incidentToModify, handoff, fromUser.Username))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send the handoff request to @%s", toUser.Username)
+	}
+	handoff.PostID = post.Id
+
+	channelPost, err := s.modificationMessage(userID, incidentToModify.ChannelID,
+		fmt.Sprintf("asked **@%s** to take command of the incident.", toUser.Username))
+	if err != nil {
+		s.deletePosts(post.Id)
+		return err
+	}
+
+	incidentToModify.CommanderHandoff = handoff
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.publishChanges(incidentToModify, previousVersion, nil, Change{Path: CommanderHandoffPath, Value: handoff})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id, channelPost.Id)
+		return errors.Wrapf(err, "failed to update incident")
+	}
+	s.recordChange(userID, "request_commander_handoff", before, incidentToModify)
+
+	if previous != nil {
+		s.withdrawHandoff(incidentID, previous, "This handoff request was replaced by a new one.")
+	}
+
+	if _, err = s.scheduler.ScheduleOnce(handoffKeyPrefix+incidentID, now.Add(CommanderHandoffTimeout)); err != nil {
+		return errors.Wrap(err, "unable to schedule the expiration of the handoff")
+	}
+
+	return nil
+}
+
+// AcceptCommanderHandoff processes the acceptance by userID of the pending commander handoff of
+// incidentID: userID becomes the commander and channel admin, and the handover notes are pinned
+// in the channel and added to the timeline.
+func (s *ServiceImpl) AcceptCommanderHandoff(incidentID, userID string) error {
+	incidentToModify, handoff, err := s.pendingHandoff(incidentID, userID)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	s.scheduler.Cancel(handoffKeyPrefix + incidentID)
+
+	oldCommander, err := s.pluginAPI.User.Get(incidentToModify.CommanderUserID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve user %s", incidentToModify.CommanderUserID)
+	}
+	newCommander, err := s.pluginAPI.User.Get(userID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve user %s", userID)
+	}
+
+	channelID := incidentToModify.ChannelID
+	if _, err = s.pluginAPI.Channel.AddUser(channelID, userID, s.configService.GetConfiguration().BotUserID); err != nil {
+		return errors.Wrapf(err, "failed to add user to channel")
+	}
+
+	incidentToModify.CommanderUserID = userID
+	incidentToModify.CommanderHandoff = nil
+
+	message := fmt.Sprintf("**@%s** handed command of the incident over to **@%s**.", oldCommander.Username, newCommander.Username)
+	if handoff.Notes != "" {
+		message += "\n#### Handover notes\n" + handoff.Notes
+	}
+	post, err := s.poster.PostMessage(channelID, "%s", message)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post handoff message")
+	}
+
+	if handoff.Notes != "" {
+		post.IsPinned = true
+		if err = s.pluginAPI.Post.UpdatePost(post); err != nil {
+			s.logger.Warnf("failed to pin the handover notes of incident %s: %v", incidentID, err)
+		}
+	}
+
+	event := &TimelineEvent{
+		IncidentID:    incidentID,
+		CreateAt:      post.CreateAt,
+		EventAt:       post.CreateAt,
+		EventType:     CommanderChanged,
+		Summary:       fmt.Sprintf("@%s to @%s", oldCommander.Username, newCommander.Username),
+		Details:       handoff.Notes,
+		PostID:        post.Id,
+		SubjectUserID: handoff.FromUserID,
+		CreatorUserID: userID,
+	}
+
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryChangeCommander, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, []*TimelineEvent{event},
+				Change{Path: CommanderPath, Value: userID},
+				Change{Path: CommanderHandoffPath, Value: nil})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
+	}
+	s.recordChange(userID, "accept_commander_handoff", before, incidentToModify)
+
+	adminRoles := fmt.Sprintf("%s %s", model.CHANNEL_ADMIN_ROLE_ID, model.CHANNEL_USER_ROLE_ID)
+	if _, err = s.pluginAPI.Channel.UpdateChannelMemberRoles(channelID, userID, adminRoles); err != nil {
+		s.logger.Warnf("failed to promote commander %s to channel admin of %s: %v", userID, channelID, err)
+	}
+	if _, err = s.pluginAPI.Channel.UpdateChannelMemberRoles(channelID, oldCommander.Id, model.CHANNEL_USER_ROLE_ID); err != nil {
+		s.logger.Warnf("failed to demote former commander %s in channel %s: %v", oldCommander.Id, channelID, err)
+	}
+
+	s.closeHandoffPost(handoff, "You accepted to take command of the incident.")
+
+	return nil
+}
+
+// DeclineCommanderHandoff processes the refusal by userID of the pending commander handoff of
+// incidentID. The commander stays in command.
+func (s *ServiceImpl) DeclineCommanderHandoff(incidentID, userID string) error {
+	incidentToModify, handoff, err := s.pendingHandoff(incidentID, userID)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	post, err := s.modificationMessage(userID, incidentToModify.ChannelID, "declined to take command of the incident.")
+	if err != nil {
+		return err
+	}
+
+	incidentToModify.CommanderHandoff = nil
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.publishChanges(incidentToModify, previousVersion, nil, Change{Path: CommanderHandoffPath, Value: nil})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
+	}
+	s.recordChange(userID, "decline_commander_handoff", before, incidentToModify)
+
+	s.withdrawHandoff(incidentID, handoff, "You declined to take command of the incident.")
+
+	return nil
+}
+
+// handleHandoffExpiration withdraws the commander handoff of the incident if it is still pending
+// and has expired.
+func (s *ServiceImpl) handleHandoffExpiration(incidentID string) {
+	incidentToModify, err := s.store.GetIncident(incidentID)
+	if err != nil {
+		s.logger.Errorf("failed to get incident %s to expire its commander handoff: %v", incidentID, err)
+		return
+	}
+
+	handoff := incidentToModify.CommanderHandoff
+	if handoff == nil || handoff.ExpiresAt > model.GetMillis() {
+		return
+	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	toUser, err := s.pluginAPI.User.Get(handoff.ToUserID)
+	if err != nil {
+		s.logger.Errorf("failed to resolve user %s: %v", handoff.ToUserID, err)
+		return
+	}
+	post, err := s.poster.PostMessage(incidentToModify.ChannelID,
+		"**@%s** did not answer the request to take command of the incident in time.", toUser.Username)
+	if err != nil {
+		s.logger.Errorf("failed to post the expiration of the commander handoff of incident %s: %v", incidentID, err)
+		return
+	}
+
+	incidentToModify.CommanderHandoff = nil
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.publishChanges(incidentToModify, previousVersion, nil, Change{Path: CommanderHandoffPath, Value: nil})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		s.logger.Errorf("failed to expire the commander handoff of incident %s: %v", incidentID, err)
+		return
+	}
+	s.recordSystemChange("expire_commander_handoff", before, incidentToModify)
+
+	s.closeHandoffPost(handoff, "This handoff request expired.")
+}
+
+// pendingHandoff returns the incident and its pending handoff if it is addressed to userID. A
+// handoff no longer holds once its requester isn't the commander or the incident isn't active,
+// even if it wasn't withdrawn.
+func (s *ServiceImpl) pendingHandoff(incidentID, userID string) (*Incident, *CommanderHandoff, error) {
+	incidentToModify, err := s.store.GetIncident(incidentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handoff := incidentToModify.CommanderHandoff
+	if handoff == nil || handoff.ToUserID != userID || handoff.ExpiresAt <= model.GetMillis() ||
+		handoff.FromUserID != incidentToModify.CommanderUserID || !incidentToModify.IsActive() {
+		return nil, nil, ErrNoCommanderHandoff
+	}
+
+	return incidentToModify, handoff, nil
+}
+
+// withdrawHandoff cancels the expiration of a handoff removed from its incident, and closes its
+// direct message with the given outcome.
+func (s *ServiceImpl) withdrawHandoff(incidentID string, handoff *CommanderHandoff, outcome string) {
+	s.scheduler.Cancel(handoffKeyPrefix + incidentID)
+	s.closeHandoffPost(handoff, outcome)
+}
+
+// handoffAttachment builds the message asking the proposed commander to accept or decline.
+func (s *ServiceImpl) handoffAttachment(theIncident *Incident, handoff *CommanderHandoff, fromUsername string) *model.SlackAttachment {
+	pluginURL := fmt.Sprintf("/plugins/%s/api/v0/incidents/%s/commander/handoff",
+		s.configService.GetManifest().Id, theIncident.ID)
+
+	text := fmt.Sprintf("**@%s** asks you to take command of the incident **%s**. The request expires in %s.",
+		fromUsername, theIncident.Name, CommanderHandoffTimeout)
+	if handoff.Notes != "" {
+		text += "\n#### Handover notes\n" + handoff.Notes
+	}
+
+	return &model.SlackAttachment{
+		Title: "Commander handoff",
+		Text:  text,
+		Actions: []*model.PostAction{
+			{
+				Type:  "button",
+				Name:  "Accept",
+				Style: "primary",
+				Integration: &model.PostActionIntegration{
+					URL: pluginURL + "/accept",
+				},
+			},
+			{
+				Type: "button",
+				Name: "Decline",
+				Integration: &model.PostActionIntegration{
+					URL: pluginURL + "/decline",
+				},
+			},
+		},
+	}
+}
+
+// closeHandoffPost removes the buttons from the direct message of the handoff, with a message
+// telling what happened to it.
+func (s *ServiceImpl) closeHandoffPost(handoff *CommanderHandoff, outcome string) {
+	if handoff.PostID == "" {
+		return
+	}
+
+	post, err := s.pluginAPI.Post.GetPost(handoff.PostID)
+	if err != nil {
+		s.logger.Warnf("failed to get the handoff request post %s: %v", handoff.PostID, err)
+		return
+	}
+
+	attachments := post.Attachments()
+	for _, attachment := range attachments {
+		attachment.Actions = nil
+	}
+	model.ParseSlackAttachment(post, attachments)
+	post.Message = outcome
+
+	if err = s.pluginAPI.Post.UpdatePost(post); err != nil {
+		s.logger.Warnf("failed to update the handoff request post %s: %v", handoff.PostID, err)
+	}
+}
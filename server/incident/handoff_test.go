@@ -0,0 +1,292 @@
+package incident_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/metrics"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestCommanderHandoff(t *testing.T) {
+	newIncident := func(handoff *incident.CommanderHandoff) *incident.Incident {
+		return &incident.Incident{
+			ID:               "incident_id",
+			Name:             "Incident",
+			ChannelID:        "channel_id",
+			CommanderUserID:  "user_id",
+			CommanderHandoff: handoff,
+		}
+	}
+
+	pendingHandoff := func() *incident.CommanderHandoff {
+		return &incident.CommanderHandoff{
+			FromUserID: "user_id",
+			ToUserID:   "new_id",
+			Notes:      "The database is failing over.",
+			ExpiresAt:  model.GetMillisForTime(time.Now().Add(time.Hour)),
+			PostID:     "dm_post_id",
+		}
+	}
+
+	setup := func(t *testing.T) (incident.Service, *mock_incident.MockStore, *mock_bot.MockPoster, *mock_incident.MockJobOnceScheduler, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		pluginAPI.On("GetUser", "new_id").Return(&model.User{Id: "new_id", Username: "newname"}, nil)
+		pluginAPI.On("GetPost", "dm_post_id").Return(&model.Post{Id: "dm_post_id"}, nil)
+		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "pluginid"}).AnyTimes()
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: "bot_id"}).AnyTimes()
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, auditService, metrics.New())
+
+		return s, store, poster, scheduler, pluginAPI
+	}
+
+	t.Run("only the commander can hand command over", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(nil), nil)
+
+		err := s.RequestCommanderHandoff("incident_id", "other_id", "new_id", "")
+		require.True(t, errors.Is(err, incident.ErrPermission))
+	})
+
+	t.Run("request asks the new commander and schedules the expiration", func(t *testing.T) {
+		s, store, poster, scheduler, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(nil), nil)
+		poster.EXPECT().DMWithAttachments("new_id", gomock.Any()).Return(&model.Post{Id: "dm_post_id"}, nil)
+
+		work := expectCommit(store)
+		scheduler.EXPECT().ScheduleOnce("handoff_incident_id", gomock.Any()).Return(nil, nil)
+		poster.EXPECT().PostMessage("channel_id", "username asked **@newname** to take command of the incident.").
+			Return(&model.Post{}, nil)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		err := s.RequestCommanderHandoff("incident_id", "user_id", "new_id", " The database is failing over. ")
+		require.NoError(t, err)
+		updated := work.Incident
+		require.Equal(t, "user_id", updated.CommanderUserID)
+		require.NotNil(t, updated.CommanderHandoff)
+		require.Equal(t, "new_id", updated.CommanderHandoff.ToUserID)
+		require.Equal(t, "The database is failing over.", updated.CommanderHandoff.Notes)
+		require.Equal(t, "dm_post_id", updated.CommanderHandoff.PostID)
+		require.Len(t, changeEvent.Changes, 1)
+		require.Equal(t, incident.CommanderHandoffPath, changeEvent.Changes[0].Path)
+		require.Equal(t, "new_id", changeEvent.Changes[0].Value.(map[string]interface{})["to_user_id"])
+	})
+
+	t.Run("a request that cannot be written deletes its posts", func(t *testing.T) {
+		s, store, poster, _, pluginAPI := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(nil), nil)
+		poster.EXPECT().DMWithAttachments("new_id", gomock.Any()).Return(&model.Post{Id: "dm_post_id"}, nil)
+		poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "channel_post_id"}, nil)
+		store.EXPECT().Commit(gomock.Any()).Return(nil, errors.New("conflict"))
+		pluginAPI.On("DeletePost", "dm_post_id").Return(nil).Once()
+		pluginAPI.On("DeletePost", "channel_post_id").Return(nil).Once()
+
+		err := s.RequestCommanderHandoff("incident_id", "user_id", "new_id", "")
+		require.Error(t, err)
+		pluginAPI.AssertNumberOfCalls(t, "DeletePost", 2)
+	})
+
+	t.Run("only the proposed commander can answer", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(pendingHandoff()), nil).Times(2)
+
+		require.True(t, errors.Is(s.AcceptCommanderHandoff("incident_id", "other_id"), incident.ErrNoCommanderHandoff))
+		require.True(t, errors.Is(s.DeclineCommanderHandoff("incident_id", "user_id"), incident.ErrNoCommanderHandoff))
+	})
+
+	t.Run("an expired handoff cannot be accepted", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		handoff := pendingHandoff()
+		handoff.ExpiresAt = model.GetMillis() - 1
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(handoff), nil)
+
+		require.True(t, errors.Is(s.AcceptCommanderHandoff("incident_id", "new_id"), incident.ErrNoCommanderHandoff))
+	})
+
+	t.Run("accepting moves command and pins the notes", func(t *testing.T) {
+		s, store, poster, scheduler, pluginAPI := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(pendingHandoff()), nil)
+		scheduler.EXPECT().Cancel("handoff_incident_id")
+		pluginAPI.On("AddUserToChannel", "channel_id", "new_id", "bot_id").Return(&model.ChannelMember{}, nil)
+		poster.EXPECT().PostMessage("channel_id", "%s",
+			"**@username** handed command of the incident over to **@newname**.\n#### Handover notes\nThe database is failing over.").
+			Return(&model.Post{Id: "handoff_post_id", CreateAt: 1234}, nil)
+		pluginAPI.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "handoff_post_id" && post.IsPinned
+		})).Return(&model.Post{Id: "handoff_post_id", IsPinned: true}, nil).Once()
+
+		work := expectCommit(store)
+		pluginAPI.On("UpdateChannelMemberRoles", "channel_id", "new_id", "channel_admin channel_user").Return(&model.ChannelMember{}, nil).Once()
+		pluginAPI.On("UpdateChannelMemberRoles", "channel_id", "user_id", "channel_user").Return(&model.ChannelMember{}, nil).Once()
+		pluginAPI.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "dm_post_id"
+		})).Return(&model.Post{Id: "dm_post_id"}, nil).Once()
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(nil), nil)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		err := s.AcceptCommanderHandoff("incident_id", "new_id")
+		require.NoError(t, err)
+		require.Equal(t, []incident.Change{
+			{Path: incident.CommanderPath, Value: "new_id"},
+			{Path: incident.CommanderHandoffPath, Value: nil},
+		}, changeEvent.Changes)
+		require.Len(t, changeEvent.TimelineEvents, 1)
+		updated, events := work.Incident, work.TimelineEvents
+		require.Equal(t, "new_id", updated.CommanderUserID)
+		require.Nil(t, updated.CommanderHandoff)
+		require.Len(t, events, 1)
+		require.Equal(t, incident.CommanderChanged, events[0].EventType)
+		require.Equal(t, "The database is failing over.", events[0].Details)
+		require.Equal(t, "handoff_post_id", events[0].PostID)
+		pluginAPI.AssertExpectations(t)
+	})
+
+	t.Run("declining keeps the commander", func(t *testing.T) {
+		s, store, poster, scheduler, pluginAPI := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(pendingHandoff()), nil)
+		scheduler.EXPECT().Cancel("handoff_incident_id")
+
+		work := expectCommit(store)
+		pluginAPI.On("UpdatePost", mock.Anything).Return(&model.Post{Id: "dm_post_id"}, nil)
+		poster.EXPECT().PostMessage("channel_id", "newname declined to take command of the incident.").Return(&model.Post{}, nil)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		err := s.DeclineCommanderHandoff("incident_id", "new_id")
+		require.NoError(t, err)
+		updated := work.Incident
+		require.Equal(t, "user_id", updated.CommanderUserID)
+		require.Nil(t, updated.CommanderHandoff)
+		require.Equal(t, []incident.Change{{Path: incident.CommanderHandoffPath, Value: nil}}, changeEvent.Changes)
+	})
+
+	t.Run("changing the commander withdraws the handoff", func(t *testing.T) {
+		s, store, poster, scheduler, pluginAPI := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(pendingHandoff()), nil)
+		poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "commander_post_id"}, nil)
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(nil), nil)
+		work := expectCommit(store)
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+		scheduler.EXPECT().Cancel("handoff_incident_id")
+		pluginAPI.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "dm_post_id"
+		})).Return(&model.Post{Id: "dm_post_id"}, nil).Once()
+
+		err := s.ChangeCommander("incident_id", "user_id", "new_id")
+		require.NoError(t, err)
+		require.Equal(t, "new_id", work.Incident.CommanderUserID)
+		require.Nil(t, work.Incident.CommanderHandoff)
+		pluginAPI.AssertNumberOfCalls(t, "UpdatePost", 1)
+	})
+
+	t.Run("a handoff of a former commander cannot be accepted", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		stale := newIncident(pendingHandoff())
+		stale.CommanderUserID = "other_id"
+		store.EXPECT().GetIncident("incident_id").Return(stale, nil)
+
+		require.True(t, errors.Is(s.AcceptCommanderHandoff("incident_id", "new_id"), incident.ErrNoCommanderHandoff))
+	})
+
+	t.Run("resolving withdraws the handoff", func(t *testing.T) {
+		s, store, poster, scheduler, pluginAPI := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").DoAndReturn(func(string) (*incident.Incident, error) {
+			return newIncident(pendingHandoff()), nil
+		}).AnyTimes()
+		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "status_post_id"}, nil)
+		work := expectCommit(store)
+		scheduler.EXPECT().Cancel("incident_id")
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+		scheduler.EXPECT().Cancel("handoff_incident_id")
+		pluginAPI.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "dm_post_id"
+		})).Return(&model.Post{Id: "dm_post_id"}, nil).Once()
+
+		err := s.UpdateStatus("incident_id", "user_id", incident.StatusUpdateOptions{Status: incident.StatusResolved})
+		require.NoError(t, err)
+		require.Nil(t, work.Incident.CommanderHandoff)
+		pluginAPI.AssertNumberOfCalls(t, "UpdatePost", 1)
+	})
+
+	t.Run("a handoff of a resolved incident cannot be accepted", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		resolved := newIncident(pendingHandoff())
+		resolved.StatusPosts = []incident.StatusPost{{ID: "status_post_id", Status: incident.StatusResolved}}
+		store.EXPECT().GetIncident("incident_id").Return(resolved, nil)
+
+		require.True(t, errors.Is(s.AcceptCommanderHandoff("incident_id", "new_id"), incident.ErrNoCommanderHandoff))
+	})
+
+	t.Run("the expiration withdraws the handoff", func(t *testing.T) {
+		s, store, poster, _, pluginAPI := setup(t)
+
+		handoff := pendingHandoff()
+		handoff.ExpiresAt = model.GetMillis() - 1
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(handoff), nil)
+
+		work := expectCommit(store)
+		pluginAPI.On("UpdatePost", mock.Anything).Return(&model.Post{Id: "dm_post_id"}, nil)
+		poster.EXPECT().PostMessage("channel_id", "**@%s** did not answer the request to take command of the incident in time.", "newname").
+			Return(&model.Post{}, nil)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		s.HandleReminder("handoff_incident_id")
+		require.NotNil(t, work.Incident)
+		require.Nil(t, work.Incident.CommanderHandoff)
+		require.Equal(t, []incident.Change{{Path: incident.CommanderHandoffPath, Value: nil}}, changeEvent.Changes)
+	})
+}
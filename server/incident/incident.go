@@ -2,11 +2,13 @@ package incident
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 
 	"github.com/mattermost/mattermost-plugin-api/cluster"
@@ -37,14 +39,38 @@ type Incident struct {
 	ActiveStageTitle        string                `json:"active_stage_title"`
 	PostID                  string                `json:"post_id"`
 	PlaybookID              string                `json:"playbook_id"`
+	PlaybookVersion         int64                 `json:"playbook_version"`
 	Checklists              []playbook.Checklist  `json:"checklists"`
 	Propertylist            playbook.Propertylist `json:"propertylist"`
+	ExitCriteria            playbook.ExitCriteria `json:"exit_criteria"`
 	StatusPosts             []StatusPost          `json:"status_posts"`
 	ReminderPostID          string                `json:"reminder_post_id"`
 	PreviousReminder        time.Duration         `json:"previous_reminder"`
 	BroadcastChannelID      string                `json:"broadcast_channel_id"`
 	ReminderMessageTemplate string                `json:"reminder_message_template"`
 	TimelineEvents          []TimelineEvent       `json:"timeline_events"`
+
+	// Version is incremented every time the incident is updated, for clients to detect the
+	// changes they missed.
+	Version int64 `json:"version"`
+
+	// CommanderHandoff is the pending request to hand command over to another user, if any.
+	CommanderHandoff *CommanderHandoff `json:"commander_handoff,omitempty"`
+
+	// Team is only set when listing the incidents of several teams at once.
+	Team *TeamInfo `json:"team,omitempty"`
+
+	// Summary is only set when listing incidents with FieldsSummary, in place of the checklists,
+	// properties, status posts and timeline events, which are then left empty.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// Summary is what list views show of an incident besides its name, commander and dates. The
+// tasks done are the checklist items closed or skipped.
+type Summary struct {
+	Status     string `json:"status"`
+	TasksTotal int    `json:"tasks_total"`
+	TasksDone  int    `json:"tasks_done"`
 }
 
 func (i *Incident) Clone() *Incident {
@@ -57,10 +83,26 @@ func (i *Incident) Clone() *Incident {
 
 	var newPropertylist = i.Propertylist.Clone()
 	newIncident.Propertylist = newPropertylist
+	newIncident.ExitCriteria = i.ExitCriteria.Clone()
 
 	newIncident.StatusPosts = append([]StatusPost(nil), i.StatusPosts...)
 	newIncident.TimelineEvents = append([]TimelineEvent(nil), i.TimelineEvents...)
 
+	if i.Team != nil {
+		team := *i.Team
+		newIncident.Team = &team
+	}
+
+	if i.CommanderHandoff != nil {
+		handoff := *i.CommanderHandoff
+		newIncident.CommanderHandoff = &handoff
+	}
+
+	if i.Summary != nil {
+		summary := *i.Summary
+		newIncident.Summary = &summary
+	}
+
 	return &newIncident
 }
 
@@ -78,11 +120,8 @@ func (i *Incident) MarshalJSON() ([]byte, error) {
 		}
 	}
 
-	if old.Checklists == nil {
-		old.Propertylist = playbook.Propertylist{}
-		if old.Propertylist.Items == nil {
-			old.Propertylist.Items = []playbook.PropertylistItem{}
-		}
+	if old.Propertylist.Items == nil {
+		old.Propertylist.Items = []playbook.PropertylistItem{}
 	}
 
 	if old.StatusPosts == nil {
@@ -96,6 +135,10 @@ func (i *Incident) MarshalJSON() ([]byte, error) {
 }
 
 func (i *Incident) CurrentStatus() string {
+	if i.Summary != nil && len(i.StatusPosts) == 0 {
+		return i.Summary.Status
+	}
+
 	post := findNewestNonDeletedStatusPost(i.StatusPosts)
 	if post == nil {
 		return StatusReported
@@ -135,6 +178,18 @@ func (i *Incident) IsActive() bool {
 	return currentStatus != StatusResolved && currentStatus != StatusArchived
 }
 
+// UnmetExitCriteria returns a description of each exit criterion the incident does not meet.
+func (i *Incident) UnmetExitCriteria() []string {
+	numStatusUpdates := 0
+	for _, p := range i.StatusPosts {
+		if p.DeleteAt == 0 {
+			numStatusUpdates++
+		}
+	}
+
+	return i.ExitCriteria.Unmet(i.Checklists, i.Propertylist, numStatusUpdates)
+}
+
 func (i *Incident) ResolvedAt() int64 {
 	// Backwards compatibility for incidents with old status updates
 	if len(i.StatusPosts) > 0 && i.StatusPosts[len(i.StatusPosts)-1].Status == "" {
@@ -175,6 +230,10 @@ type StatusUpdateOptions struct {
 	Status   string
 	Message  string
 	Reminder time.Duration
+
+	// OverrideExitCriteria resolves the incident even if its exit criteria are not met. Only the
+	// commander and system admins can override the exit criteria.
+	OverrideExitCriteria bool
 }
 
 // Metadata tracks ancillary metadata about an incident.
@@ -189,13 +248,14 @@ type Metadata struct {
 type timelineEventType string
 
 const (
-	IncidentCreated      timelineEventType = "incident_created"
-	TaskStateModified    timelineEventType = "task_state_modified"
-	StatusUpdated        timelineEventType = "status_updated"
-	CommanderChanged     timelineEventType = "commander_changed"
-	AssigneeChanged      timelineEventType = "assignee_changed"
-	RanSlashCommand      timelineEventType = "ran_slash_command"
-	PropertyValueChanged timelineEventType = "property_value_changed"
+	IncidentCreated        timelineEventType = "incident_created"
+	TaskStateModified      timelineEventType = "task_state_modified"
+	StatusUpdated          timelineEventType = "status_updated"
+	CommanderChanged       timelineEventType = "commander_changed"
+	AssigneeChanged        timelineEventType = "assignee_changed"
+	RanSlashCommand        timelineEventType = "ran_slash_command"
+	PropertyValueChanged   timelineEventType = "property_value_changed"
+	ExitCriteriaOverridden timelineEventType = "exit_criteria_overridden"
 )
 
 type TimelineEvent struct {
@@ -214,10 +274,12 @@ type TimelineEvent struct {
 
 // GetIncidentsResults collects the results of the GetIncidents call: the list of Incidents matching
 // the HeaderFilterOptions, and the TotalCount of the matching incidents before paging was applied.
+// TotalCount and PageCount are not computed when paging with a cursor, and are then 0.
 type GetIncidentsResults struct {
 	TotalCount int        `json:"total_count"`
 	PageCount  int        `json:"page_count"`
 	HasMore    bool       `json:"has_more"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 	Items      []Incident `json:"items"`
 }
 
@@ -226,6 +288,9 @@ type SQLStatusPost struct {
 	PostID     string
 	Status     string
 	EndAt      int64
+
+	// Message is the text of the status update, indexed for searching.
+	Message string
 }
 
 func (r GetIncidentsResults) Clone() GetIncidentsResults {
@@ -252,6 +317,13 @@ func (r GetIncidentsResults) MarshalJSON() ([]byte, error) {
 	return json.Marshal(old)
 }
 
+// TeamInfo holds the summary information of the team of an incident.
+type TeamInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
 // CommanderInfo holds the summary information of a commander.
 type CommanderInfo struct {
 	UserID   string `json:"user_id"`
@@ -288,23 +360,55 @@ var ErrIncidentNotActive = errors.New("incident not active")
 var ErrIncidentActive = errors.New("incident active")
 
 // ErrMalformedIncident is used to indicate an incident is not valid
-var ErrMalformedIncident = errors.New("incident active")
+var ErrMalformedIncident = errors.New("malformed incident")
+
+// ErrNoCommanderHandoff is used to indicate there is no pending commander handoff to answer, or
+// that it is not addressed to the user answering it.
+var ErrNoCommanderHandoff = errors.New("no pending commander handoff")
+
+// ErrInvalidChecklistOperation is used to indicate a batch contains an operation that cannot be applied.
+var ErrInvalidChecklistOperation = errors.New("invalid checklist operation")
+
+// ErrExitCriteriaNotMet is used to indicate an incident cannot be resolved before meeting its exit criteria.
+var ErrExitCriteriaNotMet = errors.New("exit criteria not met")
+
+// ExitCriteriaError lists the exit criteria that prevent an incident from being resolved.
+type ExitCriteriaError struct {
+	Unmet []string
+}
+
+func (e *ExitCriteriaError) Error() string {
+	return ErrExitCriteriaNotMet.Error() + ": " + strings.Join(e.Unmet, "; ")
+}
+
+// Unwrap allows errors.Is to match ErrExitCriteriaNotMet.
+func (e *ExitCriteriaError) Unwrap() error {
+	return ErrExitCriteriaNotMet
+}
 
 // Service is the incident/service interface.
 type Service interface {
 	// GetIncidents returns filtered incidents and the total count before paging.
 	GetIncidents(requesterInfo RequesterInfo, options FilterOptions) (*GetIncidentsResults, error)
 
+	// SearchIncidents returns the incidents matching the search terms, sorted by relevance and
+	// with snippets of the text they matched on.
+	SearchIncidents(requesterInfo RequesterInfo, options SearchOptions) (*SearchResults, error)
+
 	// CreateIncident creates a new incident. userID is the user who initiated the CreateIncident.
 	CreateIncident(incdnt *Incident, userID string, public bool) (*Incident, error)
 
-	// OpenCreateIncidentDialog opens an interactive dialog to start a new incident.
+	// OpenCreateIncidentDialog opens an interactive dialog to start a new incident. playbooks are
+	// the ones to choose from, which the commander must be able to start incidents from, e.g.
+	// as listed by the playbook service with a MinRole of playbook.RoleRunner. The role is
+	// checked again when the dialog is submitted.
 	OpenCreateIncidentDialog(teamID, commanderID, triggerID, postID, clientID string, playbooks []playbook.Playbook, isMobileApp bool) error
 
 	// OpenUpdateStatusDialog opens an interactive dialog so the user can update the incident's status.
 	OpenUpdateStatusDialog(incidentID, triggerID string) error
 
-	// UpdateStatus updates an incident's status.
+	// UpdateStatus updates an incident's status. Resolving or archiving an active incident
+	// returns an *ExitCriteriaError if its exit criteria are not met, unless they are overridden.
 	UpdateStatus(incidentID, userID string, options StatusUpdateOptions) error
 
 	// GetIncident gets an incident by ID. Returns error if it could not be found.
@@ -327,14 +431,31 @@ type Service interface {
 	// to commanderID. Changing to the same commanderID is a no-op.
 	ChangeCommander(incidentID string, userID string, commanderID string) error
 
+	// RequestCommanderHandoff processes a request from userID, the commander of incidentID, to hand
+	// command over to commanderID with the given notes. commanderID is asked to accept or decline
+	// the handoff, which expires after CommanderHandoffTimeout. A new request replaces the pending one.
+	RequestCommanderHandoff(incidentID, userID, commanderID, notes string) error
+
+	// AcceptCommanderHandoff processes the acceptance by userID of the pending commander handoff
+	// of incidentID. Returns ErrNoCommanderHandoff if there is none for userID.
+	AcceptCommanderHandoff(incidentID, userID string) error
+
+	// DeclineCommanderHandoff processes the refusal by userID of the pending commander handoff
+	// of incidentID. Returns ErrNoCommanderHandoff if there is none for userID.
+	DeclineCommanderHandoff(incidentID, userID string) error
+
 	// ChangePropertySelectionValue processes a request from userID to change the property value of type selection
-	// with id propertyID for incidentID to selectionID.
+	// with id propertyID for incidentID to selectionID, which may hold several comma-separated IDs.
 	ChangePropertySelectionValue(incidentID string, userID string, propertyID string, selectionID string) error
 
 	// ChangePropertyFreetextValue processes a request from userID to change the property value of type freetext
 	// with id propertyID for incidentID to freetextValue.
 	ChangePropertyFreetextValue(incidentID string, userID string, propertyID string, freetextValue string) error
 
+	// ChangePropertyValues processes a request from userID to change the value of the property with
+	// id propertyID for incidentID. The values are validated according to the type of the property.
+	ChangePropertyValues(incidentID, userID, propertyID string, values []string) error
+
 	// ModifyCheckedState modifies the state of the specified checklist item
 	// Idempotent, will not perform any actions if the checklist item is already in the specified state
 	ModifyCheckedState(incidentID, userID, newState string, checklistNumber int, itemNumber int) error
@@ -342,7 +463,8 @@ type Service interface {
 	// ToggleCheckedState checks or unchecks the specified checklist item
 	ToggleCheckedState(incidentID, userID string, checklistNumber, itemNumber int) error
 
-	// ChangePropertyValue checks or unchecks the specified checklist item
+	// ChangePropertyValue changes the value of the property titled propertyTitle, parsing
+	// propertyValue as a user would type it.
 	ChangePropertyValue(incidentID, userID string, propertyTitle, propertyValue string) error
 
 	// SetAssignee sets the assignee for the specified checklist item
@@ -364,9 +486,16 @@ type Service interface {
 	// MoveChecklistItem moves a checklist item from one position to another
 	MoveChecklistItem(incidentID, userID string, checklistNumber int, itemNumber int, newLocation int) error
 
+	// BatchChecklistOperations applies all the given operations to the incident's checklists
+	// at once, or none of them if any is invalid.
+	BatchChecklistOperations(incidentID, userID string, operations []ChecklistOperation) error
+
 	// GetChecklistAutocomplete returns the list of checklist items for incidentID to be used in autocomplete
 	GetChecklistAutocomplete(incidentID string) ([]model.AutocompleteListItem, error)
 
+	// GetChecklistsAutocomplete returns the list of checklists for incidentID to be used in autocomplete
+	GetChecklistsAutocomplete(incidentID string) ([]model.AutocompleteListItem, error)
+
 	// AddChecklistItem adds an item to the specified checklist
 	AddPropertylistItem(incidentID, userID string, checklistItem playbook.PropertylistItem) error
 
@@ -392,7 +521,8 @@ type Service interface {
 	// RemoveReminder removes the pending reminder for incidentID (if any).
 	RemoveReminder(incidentID string)
 
-	// HandleReminder is the handler for all reminder events.
+	// HandleReminder is the handler for all reminder events, including the expiration of
+	// commander handoffs.
 	HandleReminder(key string)
 
 	// RemoveReminderPost will remove the reminder in the incident channel (if any).
@@ -400,6 +530,14 @@ type Service interface {
 
 	// ChangeCreationDate changes the creation date of the specified incident.
 	ChangeCreationDate(incidentID string, creationTimestamp time.Time) error
+
+	// ProcessOutbox retries the side effects of the changes to incidents that could not be run
+	// once the changes were committed.
+	ProcessOutbox() error
+
+	// WithAuditSource returns a copy of the service recording its changes in the audit log as
+	// coming from source.
+	WithAuditSource(source audit.Source) Service
 }
 
 // Store defines the methods the ServiceImpl needs from the interfaceStore.
@@ -407,12 +545,31 @@ type Store interface {
 	// GetIncidents returns filtered incidents and the total count before paging.
 	GetIncidents(requesterInfo RequesterInfo, options FilterOptions) (*GetIncidentsResults, error)
 
+	// SearchIncidents returns the incidents matching the search terms, sorted by relevance,
+	// along with the content they were matched on.
+	SearchIncidents(requesterInfo RequesterInfo, options SearchOptions) (*SearchResults, error)
+
 	// CreateIncident creates a new incident.
 	CreateIncident(incdnt *Incident) (*Incident, error)
 
-	// UpdateIncident updates an incident.
+	// UpdateIncident updates an incident, and sets incdnt.Version to its new version.
 	UpdateIncident(incdnt *Incident) error
 
+	// Commit writes the unit of work in a single transaction, and returns the outbox messages
+	// written with it, to be processed now that it is committed.
+	Commit(work *UnitOfWork) ([]*OutboxMessage, error)
+
+	// GetDueOutboxMessages returns, oldest first, at most limit outbox messages whose next
+	// attempt is due at now, in milliseconds.
+	GetDueOutboxMessages(now int64, limit int) ([]*OutboxMessage, error)
+
+	// UpdateOutboxMessage records the failed attempt to process an outbox message: its Attempts,
+	// NextAttemptAt and LastError.
+	UpdateOutboxMessage(message *OutboxMessage) error
+
+	// DeleteOutboxMessages deletes the given outbox messages, once processed.
+	DeleteOutboxMessages(messageIDs []string) error
+
 	// UpdateStatus updates the status of an incident.
 	UpdateStatus(statusPost *SQLStatusPost) error
 
@@ -433,6 +590,10 @@ type Store interface {
 	// incident, excluding bots.
 	GetAllIncidentMembersCount(channelID string) (int64, error)
 
+	// GetIncidentCountsByStatus returns the number of incidents in each status, across all
+	// teams. Statuses without incidents are left out.
+	GetIncidentCountsByStatus() (map[string]int64, error)
+
 	// GetCommanders returns the commanders of the incidents selected by options
 	GetCommanders(requesterInfo RequesterInfo, options FilterOptions) ([]CommanderInfo, error)
 
@@ -443,6 +604,18 @@ type Store interface {
 	ChangeCreationDate(incidentID string, creationTimestamp time.Time) error
 }
 
+// Metrics defines the methods that the ServiceImpl needs from the metrics of the plugin.
+type Metrics interface {
+	// IncrementRemindersScheduled records that a status update reminder was scheduled.
+	IncrementRemindersScheduled()
+
+	// IncrementRemindersFired records that a status update reminder was posted.
+	IncrementRemindersFired()
+
+	// IncrementBroadcastFailures records that an attempt to broadcast a status update failed.
+	IncrementBroadcastFailures()
+}
+
 // Telemetry defines the methods that the ServiceImpl needs from the RudderTelemetry.
 // Unless otherwise noted, userID is the user initiating the event.
 type Telemetry interface {
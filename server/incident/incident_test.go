@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 )
 
 func TestIncident_MarshalJSON(t *testing.T) {
@@ -238,3 +240,10 @@ func TestIncident_LastResovedAt(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckedStateMessage(t *testing.T) {
+	require.Equal(t, "checked off checklist item **Roll back**", checkedStateMessage("Roll back", playbook.ChecklistItemStateClosed))
+	require.Equal(t, "unchecked checklist item **Roll back**", checkedStateMessage("Roll back", playbook.ChecklistItemStateOpen))
+	require.Equal(t, "started checklist item **Roll back**", checkedStateMessage("Roll back", playbook.ChecklistItemStateInProgress))
+	require.Equal(t, "skipped checklist item **Roll back**", checkedStateMessage("*Roll back*", playbook.ChecklistItemStateSkipped))
+}
@@ -6,6 +6,7 @@ package mock_incident
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	playbook "github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	model "github.com/mattermost/mattermost-server/v5/model"
@@ -36,6 +37,20 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AcceptCommanderHandoff mocks base method
+func (m *MockService) AcceptCommanderHandoff(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptCommanderHandoff", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptCommanderHandoff indicates an expected call of AcceptCommanderHandoff
+func (mr *MockServiceMockRecorder) AcceptCommanderHandoff(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptCommanderHandoff", reflect.TypeOf((*MockService)(nil).AcceptCommanderHandoff), arg0, arg1)
+}
+
 // AddChecklistItem mocks base method
 func (m *MockService) AddChecklistItem(arg0, arg1 string, arg2 int, arg3 playbook.ChecklistItem) error {
 	m.ctrl.T.Helper()
@@ -44,6 +59,12 @@ func (m *MockService) AddChecklistItem(arg0, arg1 string, arg2 int, arg3 playboo
 	return ret0
 }
 
+// AddChecklistItem indicates an expected call of AddChecklistItem
+func (mr *MockServiceMockRecorder) AddChecklistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistItem", reflect.TypeOf((*MockService)(nil).AddChecklistItem), arg0, arg1, arg2, arg3)
+}
+
 // AddPropertylistItem mocks base method
 func (m *MockService) AddPropertylistItem(arg0, arg1 string, arg2 playbook.PropertylistItem) error {
 	m.ctrl.T.Helper()
@@ -52,24 +73,24 @@ func (m *MockService) AddPropertylistItem(arg0, arg1 string, arg2 playbook.Prope
 	return ret0
 }
 
-func (m *MockService) ChangePropertySelectionValue(arg0 string, arg1 string, arg2 string, arg3 string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangePropertySelectionValue", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].(error)
-	return ret0
+// AddPropertylistItem indicates an expected call of AddPropertylistItem
+func (mr *MockServiceMockRecorder) AddPropertylistItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPropertylistItem", reflect.TypeOf((*MockService)(nil).AddPropertylistItem), arg0, arg1, arg2)
 }
 
-func (m *MockService) ChangePropertyFreetextValue(arg0 string, arg1 string, arg2 string, arg3 string) error {
+// BatchChecklistOperations mocks base method
+func (m *MockService) BatchChecklistOperations(arg0, arg1 string, arg2 []incident.ChecklistOperation) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangePropertyFreetextValue", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "BatchChecklistOperations", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddChecklistItem indicates an expected call of AddChecklistItem
-func (mr *MockServiceMockRecorder) AddChecklistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// BatchChecklistOperations indicates an expected call of BatchChecklistOperations
+func (mr *MockServiceMockRecorder) BatchChecklistOperations(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistItem", reflect.TypeOf((*MockService)(nil).AddChecklistItem), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchChecklistOperations", reflect.TypeOf((*MockService)(nil).BatchChecklistOperations), arg0, arg1, arg2)
 }
 
 // ChangeCommander mocks base method
@@ -100,6 +121,62 @@ func (mr *MockServiceMockRecorder) ChangeCreationDate(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeCreationDate", reflect.TypeOf((*MockService)(nil).ChangeCreationDate), arg0, arg1)
 }
 
+// ChangePropertyFreetextValue mocks base method
+func (m *MockService) ChangePropertyFreetextValue(arg0, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePropertyFreetextValue", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePropertyFreetextValue indicates an expected call of ChangePropertyFreetextValue
+func (mr *MockServiceMockRecorder) ChangePropertyFreetextValue(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePropertyFreetextValue", reflect.TypeOf((*MockService)(nil).ChangePropertyFreetextValue), arg0, arg1, arg2, arg3)
+}
+
+// ChangePropertySelectionValue mocks base method
+func (m *MockService) ChangePropertySelectionValue(arg0, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePropertySelectionValue", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePropertySelectionValue indicates an expected call of ChangePropertySelectionValue
+func (mr *MockServiceMockRecorder) ChangePropertySelectionValue(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePropertySelectionValue", reflect.TypeOf((*MockService)(nil).ChangePropertySelectionValue), arg0, arg1, arg2, arg3)
+}
+
+// ChangePropertyValue mocks base method
+func (m *MockService) ChangePropertyValue(arg0, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePropertyValue", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePropertyValue indicates an expected call of ChangePropertyValue
+func (mr *MockServiceMockRecorder) ChangePropertyValue(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePropertyValue", reflect.TypeOf((*MockService)(nil).ChangePropertyValue), arg0, arg1, arg2, arg3)
+}
+
+// ChangePropertyValues mocks base method
+func (m *MockService) ChangePropertyValues(arg0, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePropertyValues", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePropertyValues indicates an expected call of ChangePropertyValues
+func (mr *MockServiceMockRecorder) ChangePropertyValues(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePropertyValues", reflect.TypeOf((*MockService)(nil).ChangePropertyValues), arg0, arg1, arg2, arg3)
+}
+
 // CreateIncident mocks base method
 func (m *MockService) CreateIncident(arg0 *incident.Incident, arg1 string, arg2 bool) (*incident.Incident, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +192,20 @@ func (mr *MockServiceMockRecorder) CreateIncident(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIncident", reflect.TypeOf((*MockService)(nil).CreateIncident), arg0, arg1, arg2)
 }
 
+// DeclineCommanderHandoff mocks base method
+func (m *MockService) DeclineCommanderHandoff(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineCommanderHandoff", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineCommanderHandoff indicates an expected call of DeclineCommanderHandoff
+func (mr *MockServiceMockRecorder) DeclineCommanderHandoff(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineCommanderHandoff", reflect.TypeOf((*MockService)(nil).DeclineCommanderHandoff), arg0, arg1)
+}
+
 // GetChecklistAutocomplete mocks base method
 func (m *MockService) GetChecklistAutocomplete(arg0 string) ([]model.AutocompleteListItem, error) {
 	m.ctrl.T.Helper()
@@ -124,19 +215,25 @@ func (m *MockService) GetChecklistAutocomplete(arg0 string) ([]model.Autocomplet
 	return ret0, ret1
 }
 
-// GetPropertylistAutocomplete mocks base method
-func (m *MockService) GetPropertylistAutocomplete(arg0 string) ([]model.AutocompleteListItem, error) {
+// GetChecklistAutocomplete indicates an expected call of GetChecklistAutocomplete
+func (mr *MockServiceMockRecorder) GetChecklistAutocomplete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistAutocomplete", reflect.TypeOf((*MockService)(nil).GetChecklistAutocomplete), arg0)
+}
+
+// GetChecklistsAutocomplete mocks base method
+func (m *MockService) GetChecklistsAutocomplete(arg0 string) ([]model.AutocompleteListItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPropertylistAutocomplete", arg0)
+	ret := m.ctrl.Call(m, "GetChecklistsAutocomplete", arg0)
 	ret0, _ := ret[0].([]model.AutocompleteListItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetChecklistAutocomplete indicates an expected call of GetChecklistAutocomplete
-func (mr *MockServiceMockRecorder) GetChecklistAutocomplete(arg0 interface{}) *gomock.Call {
+// GetChecklistsAutocomplete indicates an expected call of GetChecklistsAutocomplete
+func (mr *MockServiceMockRecorder) GetChecklistsAutocomplete(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistAutocomplete", reflect.TypeOf((*MockService)(nil).GetChecklistAutocomplete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistsAutocomplete", reflect.TypeOf((*MockService)(nil).GetChecklistsAutocomplete), arg0)
 }
 
 // GetCommanders mocks base method
@@ -214,6 +311,21 @@ func (mr *MockServiceMockRecorder) GetIncidents(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidents", reflect.TypeOf((*MockService)(nil).GetIncidents), arg0, arg1)
 }
 
+// GetPropertylistAutocomplete mocks base method
+func (m *MockService) GetPropertylistAutocomplete(arg0 string) ([]model.AutocompleteListItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPropertylistAutocomplete", arg0)
+	ret0, _ := ret[0].([]model.AutocompleteListItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPropertylistAutocomplete indicates an expected call of GetPropertylistAutocomplete
+func (mr *MockServiceMockRecorder) GetPropertylistAutocomplete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPropertylistAutocomplete", reflect.TypeOf((*MockService)(nil).GetPropertylistAutocomplete), arg0)
+}
+
 // HandleReminder mocks base method
 func (m *MockService) HandleReminder(arg0 string) {
 	m.ctrl.T.Helper()
@@ -262,6 +374,12 @@ func (m *MockService) MoveChecklistItem(arg0, arg1 string, arg2, arg3, arg4 int)
 	return ret0
 }
 
+// MoveChecklistItem indicates an expected call of MoveChecklistItem
+func (mr *MockServiceMockRecorder) MoveChecklistItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveChecklistItem", reflect.TypeOf((*MockService)(nil).MoveChecklistItem), arg0, arg1, arg2, arg3, arg4)
+}
+
 // MovePropertylistItem mocks base method
 func (m *MockService) MovePropertylistItem(arg0, arg1 string, arg2, arg3 int) error {
 	m.ctrl.T.Helper()
@@ -270,10 +388,10 @@ func (m *MockService) MovePropertylistItem(arg0, arg1 string, arg2, arg3 int) er
 	return ret0
 }
 
-// MoveChecklistItem indicates an expected call of MoveChecklistItem
-func (mr *MockServiceMockRecorder) MoveChecklistItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+// MovePropertylistItem indicates an expected call of MovePropertylistItem
+func (mr *MockServiceMockRecorder) MovePropertylistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveChecklistItem", reflect.TypeOf((*MockService)(nil).MoveChecklistItem), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MovePropertylistItem", reflect.TypeOf((*MockService)(nil).MovePropertylistItem), arg0, arg1, arg2, arg3)
 }
 
 // NukeDB mocks base method
@@ -318,21 +436,34 @@ func (mr *MockServiceMockRecorder) OpenUpdateStatusDialog(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUpdateStatusDialog", reflect.TypeOf((*MockService)(nil).OpenUpdateStatusDialog), arg0, arg1)
 }
 
-// RemoveChecklistItem mocks base method
-func (m *MockService) RemoveChecklistItem(arg0, arg1 string, arg2, arg3 int) error {
+// ProcessOutbox mocks base method
+func (m *MockService) ProcessOutbox() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveChecklistItem", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "ProcessOutbox")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-func (m *MockService) UpdatePropertylistItem(arg0, arg1 string, arg2 int, arg3 playbook.PropertylistItem) error {
+// ProcessOutbox indicates an expected call of ProcessOutbox
+func (mr *MockServiceMockRecorder) ProcessOutbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOutbox", reflect.TypeOf((*MockService)(nil).ProcessOutbox))
+}
+
+// RemoveChecklistItem mocks base method
+func (m *MockService) RemoveChecklistItem(arg0, arg1 string, arg2, arg3 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePropertylistItem", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "RemoveChecklistItem", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// RemoveChecklistItem indicates an expected call of RemoveChecklistItem
+func (mr *MockServiceMockRecorder) RemoveChecklistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChecklistItem", reflect.TypeOf((*MockService)(nil).RemoveChecklistItem), arg0, arg1, arg2, arg3)
+}
+
 // RemovePropertylistItem mocks base method
 func (m *MockService) RemovePropertylistItem(arg0, arg1 string, arg2 int) error {
 	m.ctrl.T.Helper()
@@ -341,10 +472,10 @@ func (m *MockService) RemovePropertylistItem(arg0, arg1 string, arg2 int) error
 	return ret0
 }
 
-// RemoveChecklistItem indicates an expected call of RemoveChecklistItem
-func (mr *MockServiceMockRecorder) RemoveChecklistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// RemovePropertylistItem indicates an expected call of RemovePropertylistItem
+func (mr *MockServiceMockRecorder) RemovePropertylistItem(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChecklistItem", reflect.TypeOf((*MockService)(nil).RemoveChecklistItem), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePropertylistItem", reflect.TypeOf((*MockService)(nil).RemovePropertylistItem), arg0, arg1, arg2)
 }
 
 // RemoveReminder mocks base method
@@ -387,6 +518,20 @@ func (mr *MockServiceMockRecorder) RenameChecklistItem(arg0, arg1, arg2, arg3, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameChecklistItem", reflect.TypeOf((*MockService)(nil).RenameChecklistItem), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// RequestCommanderHandoff mocks base method
+func (m *MockService) RequestCommanderHandoff(arg0, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCommanderHandoff", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestCommanderHandoff indicates an expected call of RequestCommanderHandoff
+func (mr *MockServiceMockRecorder) RequestCommanderHandoff(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCommanderHandoff", reflect.TypeOf((*MockService)(nil).RequestCommanderHandoff), arg0, arg1, arg2, arg3)
+}
+
 // RunChecklistItemSlashCommand mocks base method
 func (m *MockService) RunChecklistItemSlashCommand(arg0, arg1 string, arg2, arg3 int) (string, error) {
 	m.ctrl.T.Helper()
@@ -402,6 +547,21 @@ func (mr *MockServiceMockRecorder) RunChecklistItemSlashCommand(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunChecklistItemSlashCommand", reflect.TypeOf((*MockService)(nil).RunChecklistItemSlashCommand), arg0, arg1, arg2, arg3)
 }
 
+// SearchIncidents mocks base method
+func (m *MockService) SearchIncidents(arg0 incident.RequesterInfo, arg1 incident.SearchOptions) (*incident.SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchIncidents", arg0, arg1)
+	ret0, _ := ret[0].(*incident.SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchIncidents indicates an expected call of SearchIncidents
+func (mr *MockServiceMockRecorder) SearchIncidents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchIncidents", reflect.TypeOf((*MockService)(nil).SearchIncidents), arg0, arg1)
+}
+
 // SetAssignee mocks base method
 func (m *MockService) SetAssignee(arg0, arg1, arg2 string, arg3, arg4 int) error {
 	m.ctrl.T.Helper()
@@ -444,6 +604,20 @@ func (mr *MockServiceMockRecorder) ToggleCheckedState(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleCheckedState", reflect.TypeOf((*MockService)(nil).ToggleCheckedState), arg0, arg1, arg2, arg3)
 }
 
+// UpdatePropertylistItem mocks base method
+func (m *MockService) UpdatePropertylistItem(arg0, arg1 string, arg2 int, arg3 playbook.PropertylistItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePropertylistItem", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePropertylistItem indicates an expected call of UpdatePropertylistItem
+func (mr *MockServiceMockRecorder) UpdatePropertylistItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePropertylistItem", reflect.TypeOf((*MockService)(nil).UpdatePropertylistItem), arg0, arg1, arg2, arg3)
+}
+
 // UpdateStatus mocks base method
 func (m *MockService) UpdateStatus(arg0, arg1 string, arg2 incident.StatusUpdateOptions) error {
 	m.ctrl.T.Helper()
@@ -457,3 +631,17 @@ func (mr *MockServiceMockRecorder) UpdateStatus(arg0, arg1, arg2 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockService)(nil).UpdateStatus), arg0, arg1, arg2)
 }
+
+// WithAuditSource mocks base method
+func (m *MockService) WithAuditSource(arg0 audit.Source) incident.Service {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithAuditSource", arg0)
+	ret0, _ := ret[0].(incident.Service)
+	return ret0
+}
+
+// WithAuditSource indicates an expected call of WithAuditSource
+func (mr *MockServiceMockRecorder) WithAuditSource(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAuditSource", reflect.TypeOf((*MockService)(nil).WithAuditSource), arg0)
+}
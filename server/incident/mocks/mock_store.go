@@ -48,6 +48,21 @@ func (mr *MockStoreMockRecorder) ChangeCreationDate(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeCreationDate", reflect.TypeOf((*MockStore)(nil).ChangeCreationDate), arg0, arg1)
 }
 
+// Commit mocks base method
+func (m *MockStore) Commit(arg0 *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", arg0)
+	ret0, _ := ret[0].([]*incident.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Commit indicates an expected call of Commit
+func (mr *MockStoreMockRecorder) Commit(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockStore)(nil).Commit), arg0)
+}
+
 // CreateIncident mocks base method
 func (m *MockStore) CreateIncident(arg0 *incident.Incident) (*incident.Incident, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +93,20 @@ func (mr *MockStoreMockRecorder) CreateTimelineEvent(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTimelineEvent", reflect.TypeOf((*MockStore)(nil).CreateTimelineEvent), arg0)
 }
 
+// DeleteOutboxMessages mocks base method
+func (m *MockStore) DeleteOutboxMessages(arg0 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxMessages", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxMessages indicates an expected call of DeleteOutboxMessages
+func (mr *MockStoreMockRecorder) DeleteOutboxMessages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxMessages", reflect.TypeOf((*MockStore)(nil).DeleteOutboxMessages), arg0)
+}
+
 // GetAllIncidentMembersCount mocks base method
 func (m *MockStore) GetAllIncidentMembersCount(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +137,21 @@ func (mr *MockStoreMockRecorder) GetCommanders(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommanders", reflect.TypeOf((*MockStore)(nil).GetCommanders), arg0, arg1)
 }
 
+// GetDueOutboxMessages mocks base method
+func (m *MockStore) GetDueOutboxMessages(arg0 int64, arg1 int) ([]*incident.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueOutboxMessages", arg0, arg1)
+	ret0, _ := ret[0].([]*incident.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueOutboxMessages indicates an expected call of GetDueOutboxMessages
+func (mr *MockStoreMockRecorder) GetDueOutboxMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueOutboxMessages", reflect.TypeOf((*MockStore)(nil).GetDueOutboxMessages), arg0, arg1)
+}
+
 // GetIncident mocks base method
 func (m *MockStore) GetIncident(arg0 string) (*incident.Incident, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +167,21 @@ func (mr *MockStoreMockRecorder) GetIncident(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncident", reflect.TypeOf((*MockStore)(nil).GetIncident), arg0)
 }
 
+// GetIncidentCountsByStatus mocks base method
+func (m *MockStore) GetIncidentCountsByStatus() (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncidentCountsByStatus")
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncidentCountsByStatus indicates an expected call of GetIncidentCountsByStatus
+func (mr *MockStoreMockRecorder) GetIncidentCountsByStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidentCountsByStatus", reflect.TypeOf((*MockStore)(nil).GetIncidentCountsByStatus))
+}
+
 // GetIncidentIDForChannel mocks base method
 func (m *MockStore) GetIncidentIDForChannel(arg0 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -167,6 +226,21 @@ func (mr *MockStoreMockRecorder) NukeDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NukeDB", reflect.TypeOf((*MockStore)(nil).NukeDB))
 }
 
+// SearchIncidents mocks base method
+func (m *MockStore) SearchIncidents(arg0 incident.RequesterInfo, arg1 incident.SearchOptions) (*incident.SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchIncidents", arg0, arg1)
+	ret0, _ := ret[0].(*incident.SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchIncidents indicates an expected call of SearchIncidents
+func (mr *MockStoreMockRecorder) SearchIncidents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchIncidents", reflect.TypeOf((*MockStore)(nil).SearchIncidents), arg0, arg1)
+}
+
 // UpdateIncident mocks base method
 func (m *MockStore) UpdateIncident(arg0 *incident.Incident) error {
 	m.ctrl.T.Helper()
@@ -181,6 +255,20 @@ func (mr *MockStoreMockRecorder) UpdateIncident(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIncident", reflect.TypeOf((*MockStore)(nil).UpdateIncident), arg0)
 }
 
+// UpdateOutboxMessage mocks base method
+func (m *MockStore) UpdateOutboxMessage(arg0 *incident.OutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutboxMessage", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOutboxMessage indicates an expected call of UpdateOutboxMessage
+func (mr *MockStoreMockRecorder) UpdateOutboxMessage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutboxMessage", reflect.TypeOf((*MockStore)(nil).UpdateOutboxMessage), arg0)
+}
+
 // UpdateStatus mocks base method
 func (m *MockStore) UpdateStatus(arg0 *incident.SQLStatusPost) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,413 @@
+package incident
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// UnitOfWork is a set of writes to incidents committed atomically by the store, along with the
+// side effects to run once they are committed.
+type UnitOfWork struct {
+	// Incident, if not nil, is written over the stored incident. Its Version is then set to the
+	// new version.
+	Incident *Incident
+
+	// StatusPost, if not nil, is added to the status posts of its incident.
+	StatusPost *SQLStatusPost
+
+	// TimelineEvents are added to the timelines of their incidents.
+	TimelineEvents []*TimelineEvent
+
+	// Outbox, if not nil, returns the side effects of the writes. It is called in the
+	// transaction once Incident is written, for the side effects to refer to its new Version.
+	Outbox func() ([]*OutboxMessage, error)
+}
+
+// OutboxMessage is a side effect of a unit of work, such as a post or a websocket event, written
+// along with it and run once it is committed. A message that fails is retried later, so it may
+// run more than once.
+type OutboxMessage struct {
+	ID            string
+	CreateAt      int64
+	Type          string
+	Payload       json.RawMessage
+	Attempts      int
+	NextAttemptAt int64
+	LastError     string
+}
+
+const (
+	// outboxLease is how long the messages of a unit of work are left to the node that committed
+	// it, before ProcessOutbox takes them over.
+	outboxLease = time.Minute
+
+	// outboxRetryDelay is the delay before the first retry of a failed message, doubled after
+	// every attempt up to outboxMaxRetryDelay.
+	outboxRetryDelay    = time.Minute
+	outboxMaxRetryDelay = time.Hour
+
+	// outboxMaxAttempts is the number of attempts after which a failing message is dropped.
+	outboxMaxAttempts = 10
+
+	// outboxBatchSize is the number of due messages read at once by ProcessOutbox.
+	outboxBatchSize = 100
+)
+
+// Types of the outbox messages, stored in the database: never rename them.
+const (
+	outboxPublishChanges        = "publish_changes"
+	outboxPublishIncident       = "publish_incident"
+	outboxBroadcastStatusUpdate = "broadcast_status_update"
+	outboxUpdateReminder        = "update_reminder"
+	outboxDeletePost            = "delete_post"
+	outboxTelemetry             = "telemetry"
+)
+
+// Telemetry events sent through the outbox, stored in the database: never rename them.
+const (
+	telemetryUpdateStatus         = "update_status"
+	telemetryChangeCommander      = "change_commander"
+	telemetryPropertyValueChanged = "property_value_changed"
+	telemetryModifyCheckedState   = "modify_checked_state"
+	telemetrySetAssignee          = "set_assignee"
+	telemetryRunTaskSlashCommand  = "run_task_slash_command"
+	telemetryAddTask              = "add_task"
+	telemetryRemoveTask           = "remove_task"
+	telemetryRenameTask           = "rename_task"
+	telemetryMoveTask             = "move_task"
+)
+
+type publishChangesPayload struct {
+	ChannelID string          `json:"channel_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+type incidentPayload struct {
+	IncidentID string `json:"incident_id"`
+}
+
+type broadcastStatusUpdatePayload struct {
+	IncidentID string `json:"incident_id"`
+	AuthorID   string `json:"author_id"`
+	PostID     string `json:"post_id"`
+	Message    string `json:"message"`
+}
+
+// updateReminderPayload schedules the reminder of an incident after the status update PostID. The
+// reminder itself is read when the message runs, as it may be retried long after.
+type updateReminderPayload struct {
+	IncidentID string `json:"incident_id"`
+	PostID     string `json:"post_id"`
+}
+
+type deletePostPayload struct {
+	PostID string `json:"post_id"`
+}
+
+type telemetryPayload struct {
+	Event        string `json:"event"`
+	IncidentID   string `json:"incident_id"`
+	UserID       string `json:"user_id"`
+	NewState     string `json:"new_state,omitempty"`
+	WasCommander bool   `json:"was_commander,omitempty"`
+	WasAssignee  bool   `json:"was_assignee,omitempty"`
+}
+
+// outbox collects the messages of a unit of work, keeping the first error met.
+type outbox struct {
+	messages []*OutboxMessage
+	err      error
+}
+
+func (o *outbox) add(messageType string, payload interface{}) {
+	if o.err != nil {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		o.err = errors.Wrapf(err, "failed to marshal %s outbox message", messageType)
+		return
+	}
+
+	now := model.GetMillis()
+	o.messages = append(o.messages, &OutboxMessage{
+		ID:            model.NewId(),
+		CreateAt:      now,
+		Type:          messageType,
+		Payload:       data,
+		NextAttemptAt: now + outboxLease.Milliseconds(),
+	})
+}
+
+// publishChanges sends to the incident channel the changes made to theIncident since
+// previousVersion.
+func (o *outbox) publishChanges(theIncident *Incident, previousVersion int64, events []*TimelineEvent, changes ...Change) {
+	event, err := json.Marshal(NewChangeEvent(theIncident, previousVersion, events, changes...))
+	if err != nil {
+		o.err = errors.Wrap(err, "failed to marshal change event")
+		return
+	}
+
+	o.add(outboxPublishChanges, publishChangesPayload{ChannelID: theIncident.ChannelID, Event: event})
+}
+
+func (o *outbox) telemetry(payload telemetryPayload) {
+	o.add(outboxTelemetry, payload)
+}
+
+func (o *outbox) result() ([]*OutboxMessage, error) {
+	return o.messages, o.err
+}
+
+// commit writes work atomically, then runs its side effects. Once the writes are committed, the
+// side effects that fail are left to ProcessOutbox, so commit only fails if the writes do.
+func (s *ServiceImpl) commit(work *UnitOfWork) error {
+	messages, err := s.store.Commit(work)
+	if err != nil {
+		return err
+	}
+
+	if err = s.processOutboxMessages(messages); err != nil {
+		s.logger.Warnf("failed to record the outcome of outbox messages: %v", err)
+	}
+
+	return nil
+}
+
+// deletePosts deletes the posts made for a change that could not be written.
+func (s *ServiceImpl) deletePosts(postIDs ...string) {
+	for _, postID := range postIDs {
+		if err := s.pluginAPI.Post.DeletePost(postID); err != nil {
+			s.logger.Warnf("failed to delete post %s of a change that was not written: %v", postID, err)
+		}
+	}
+}
+
+// ProcessOutbox runs the side effects that are due: those that failed and are to be retried,
+// and those left behind by a node that stopped before running them.
+func (s *ServiceImpl) ProcessOutbox() error {
+	for {
+		messages, err := s.store.GetDueOutboxMessages(model.GetMillis(), outboxBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the due outbox messages")
+		}
+
+		if err = s.processOutboxMessages(messages); err != nil {
+			return err
+		}
+
+		if len(messages) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// processOutboxMessages runs messages in order, deleting those that succeed and rescheduling
+// those that fail, until they have failed outboxMaxAttempts times.
+func (s *ServiceImpl) processOutboxMessages(messages []*OutboxMessage) error {
+	var done []string
+	var storeErr error
+
+	for _, message := range messages {
+		err := s.processOutboxMessage(message)
+		if err == nil {
+			done = append(done, message.ID)
+			continue
+		}
+
+		message.Attempts++
+		message.LastError = err.Error()
+		if message.Attempts >= outboxMaxAttempts {
+			s.logger.Errorf("dropping outbox message %s of type %s after %d attempts: %v", message.ID, message.Type, message.Attempts, err)
+			done = append(done, message.ID)
+			continue
+		}
+
+		s.logger.Warnf("failed to process outbox message %s of type %s, will retry: %v", message.ID, message.Type, err)
+		message.NextAttemptAt = model.GetMillis() + outboxRetryDelayAfter(message.Attempts).Milliseconds()
+		if updateErr := s.store.UpdateOutboxMessage(message); updateErr != nil && storeErr == nil {
+			storeErr = errors.Wrapf(updateErr, "failed to reschedule outbox message %s", message.ID)
+		}
+	}
+
+	if len(done) > 0 {
+		if err := s.store.DeleteOutboxMessages(done); err != nil && storeErr == nil {
+			storeErr = errors.Wrap(err, "failed to delete processed outbox messages")
+		}
+	}
+
+	return storeErr
+}
+
+// outboxRetryDelayAfter returns the delay before retrying a message that failed attempts times.
+func outboxRetryDelayAfter(attempts int) time.Duration {
+	delay := outboxRetryDelay << uint(attempts-1)
+	if delay > outboxMaxRetryDelay || delay <= 0 {
+		delay = outboxMaxRetryDelay
+	}
+
+	return delay
+}
+
+func (s *ServiceImpl) processOutboxMessage(message *OutboxMessage) error {
+	switch message.Type {
+	case outboxPublishChanges:
+		var payload publishChangesPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+		s.poster.PublishWebsocketEventToChannel(incidentChangedWSEvent, payload.Event, payload.ChannelID)
+		return nil
+
+	case outboxPublishIncident:
+		// No longer queued, but run for the messages still waiting in the outbox.
+		var payload incidentPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+		return s.sendIncidentToClient(payload.IncidentID)
+
+	case outboxBroadcastStatusUpdate:
+		var payload broadcastStatusUpdatePayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+		return s.runBroadcastStatusUpdate(payload)
+
+	case outboxUpdateReminder:
+		var payload updateReminderPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+
+		return s.runUpdateReminder(payload)
+
+	case outboxDeletePost:
+		var payload deletePostPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+		return s.deletePostOnce(payload.PostID)
+
+	case outboxTelemetry:
+		var payload telemetryPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return errors.Wrap(err, "failed to unmarshal payload")
+		}
+		return s.trackTelemetry(payload)
+	}
+
+	return errors.Errorf("unknown outbox message type %s", message.Type)
+}
+
+func (s *ServiceImpl) runBroadcastStatusUpdate(payload broadcastStatusUpdatePayload) error {
+	theIncident, err := s.store.GetIncident(payload.IncidentID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve incident")
+	}
+
+	if err = s.broadcastStatusUpdate(payload.Message, theIncident, payload.AuthorID, payload.PostID); err != nil {
+		s.metrics.IncrementBroadcastFailures()
+		return errors.Wrapf(err, "failed to broadcast the status update to channel %s", theIncident.BroadcastChannelID)
+	}
+
+	return nil
+}
+
+// deletePostOnce deletes postID, unless it is already deleted.
+func (s *ServiceImpl) deletePostOnce(postID string) error {
+	post, err := s.pluginAPI.Post.GetPost(postID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve post %s", postID)
+	}
+
+	if post.DeleteAt != 0 {
+		return nil
+	}
+
+	if err = s.pluginAPI.Post.DeletePost(postID); err != nil {
+		return errors.Wrapf(err, "failed to delete post %s", postID)
+	}
+
+	return nil
+}
+
+// runUpdateReminder schedules the reminder of the incident as of its status update, unless a later
+// status update took over.
+func (s *ServiceImpl) runUpdateReminder(payload updateReminderPayload) error {
+	theIncident, err := s.store.GetIncident(payload.IncidentID)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve incident")
+	}
+
+	if isStatusPostSuperseded(theIncident.StatusPosts, payload.PostID) {
+		return nil
+	}
+
+	// Remove pending reminder (if any), even if current reminder was set to "none" (0 minutes)
+	s.RemoveReminder(payload.IncidentID)
+	if theIncident.PreviousReminder != 0 && theIncident.CurrentStatus() != StatusArchived {
+		return s.SetReminder(payload.IncidentID, theIncident.PreviousReminder)
+	}
+	return nil
+}
+
+// isStatusPostSuperseded returns true if another status post was made after postID.
+func isStatusPostSuperseded(posts []StatusPost, postID string) bool {
+	var createAt int64
+	for _, post := range posts {
+		if post.ID == postID {
+			createAt = post.CreateAt
+		}
+	}
+
+	for _, post := range posts {
+		if post.ID != postID && post.CreateAt > createAt {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ServiceImpl) trackTelemetry(payload telemetryPayload) error {
+	incidentID, userID := payload.IncidentID, payload.UserID
+
+	switch payload.Event {
+	case telemetryUpdateStatus, telemetryChangeCommander, telemetryPropertyValueChanged:
+		theIncident, err := s.store.GetIncident(incidentID)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve incident")
+		}
+
+		switch payload.Event {
+		case telemetryUpdateStatus:
+			s.telemetry.UpdateStatus(theIncident, userID)
+		case telemetryChangeCommander:
+			s.telemetry.ChangeCommander(theIncident, userID)
+		default:
+			s.telemetry.PropertyValueChanged(theIncident, userID)
+		}
+	case telemetryModifyCheckedState:
+		s.telemetry.ModifyCheckedState(incidentID, userID, payload.NewState, payload.WasCommander, payload.WasAssignee)
+	case telemetrySetAssignee:
+		s.telemetry.SetAssignee(incidentID, userID)
+	case telemetryRunTaskSlashCommand:
+		s.telemetry.RunTaskSlashCommand(incidentID, userID)
+	case telemetryAddTask:
+		s.telemetry.AddTask(incidentID, userID)
+	case telemetryRemoveTask:
+		s.telemetry.RemoveTask(incidentID, userID)
+	case telemetryRenameTask:
+		s.telemetry.RenameTask(incidentID, userID)
+	case telemetryMoveTask:
+		s.telemetry.MoveTask(incidentID, userID)
+	default:
+		return errors.Errorf("unknown telemetry event %s", payload.Event)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package incident_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestOutbox(t *testing.T) {
+	type mocks struct {
+		pluginAPI *plugintest.API
+		store     *mock_incident.MockStore
+		poster    *mock_bot.MockPoster
+		logger    *mock_bot.MockLogger
+		scheduler *mock_incident.MockJobOnceScheduler
+		metrics   *countingMetrics
+	}
+
+	setup := func(t *testing.T) (incident.Service, mocks) {
+		controller := gomock.NewController(t)
+		m := mocks{
+			pluginAPI: &plugintest.API{},
+			store:     mock_incident.NewMockStore(controller),
+			poster:    mock_bot.NewMockPoster(controller),
+			logger:    mock_bot.NewMockLogger(controller),
+			scheduler: mock_incident.NewMockJobOnceScheduler(controller),
+			metrics:   &countingMetrics{},
+		}
+		configService := mock_config.NewMockService(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		m.pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		m.pluginAPI.On("GetUser", "new_id").Return(&model.User{Id: "new_id", Username: "newname"}, nil)
+
+		s := incident.NewService(pluginapi.NewClient(m.pluginAPI), m.store, m.poster, m.logger, configService,
+			m.scheduler, &telemetry.NoopTelemetry{}, auditService, m.metrics)
+
+		return s, m
+	}
+
+	newIncident := func() *incident.Incident {
+		return &incident.Incident{
+			ID:                 "incident_id",
+			TeamID:             "team_id",
+			ChannelID:          "channel_id",
+			CommanderUserID:    "user_id",
+			BroadcastChannelID: "broadcast_id",
+			ReminderPostID:     "reminder_post_id",
+		}
+	}
+
+	t.Run("a status update clears the reminder post and broadcasts after the commit", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "status_post_id"}, nil)
+		m.pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", DisplayName: "Incident"}, nil)
+		m.pluginAPI.On("GetTeam", "team_id").Return(&model.Team{Id: "team_id", Name: "team"}, nil)
+		m.pluginAPI.On("GetPost", "reminder_post_id").Return(&model.Post{Id: "reminder_post_id"}, nil)
+		m.pluginAPI.On("DeletePost", "reminder_post_id").Return(nil)
+
+		m.store.EXPECT().GetIncident("incident_id").DoAndReturn(func(string) (*incident.Incident, error) {
+			return newIncident(), nil
+		}).Times(4)
+		work := expectCommit(m.store)
+		m.poster.EXPECT().PostMessage("broadcast_id", gomock.Any()).Return(&model.Post{}, nil)
+		m.scheduler.EXPECT().Cancel("incident_id")
+		m.poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+
+		err := s.UpdateStatus("incident_id", "user_id", incident.StatusUpdateOptions{
+			Status:  incident.StatusActive,
+			Message: "all good",
+		})
+		require.NoError(t, err)
+		require.Empty(t, work.Incident.ReminderPostID)
+		require.Equal(t, "status_post_id", work.StatusPost.PostID)
+		m.pluginAPI.AssertNumberOfCalls(t, "DeletePost", 1)
+	})
+
+	t.Run("failed side effects are rescheduled without failing the change", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "status_post_id"}, nil)
+		m.pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", DisplayName: "Incident"}, nil)
+		m.pluginAPI.On("GetTeam", "team_id").Return(&model.Team{Id: "team_id", Name: "team"}, nil)
+		m.pluginAPI.On("GetPost", "reminder_post_id").Return(&model.Post{Id: "reminder_post_id", DeleteAt: 1}, nil)
+
+		m.store.EXPECT().GetIncident("incident_id").DoAndReturn(func(string) (*incident.Incident, error) {
+			return newIncident(), nil
+		}).Times(4)
+		var messages []*incident.OutboxMessage
+		m.store.EXPECT().Commit(gomock.Any()).DoAndReturn(func(work *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+			var err error
+			messages, err = work.Outbox()
+			return messages, err
+		})
+		m.poster.EXPECT().PostMessage("broadcast_id", gomock.Any()).Return(nil, errors.New("channel archived"))
+		m.logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+		var rescheduled *incident.OutboxMessage
+		m.store.EXPECT().UpdateOutboxMessage(gomock.Any()).DoAndReturn(func(message *incident.OutboxMessage) error {
+			rescheduled = message
+			return nil
+		})
+		m.scheduler.EXPECT().Cancel("incident_id")
+		m.poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+		var deleted []string
+		m.store.EXPECT().DeleteOutboxMessages(gomock.Any()).DoAndReturn(func(ids []string) error {
+			deleted = ids
+			return nil
+		})
+
+		err := s.UpdateStatus("incident_id", "user_id", incident.StatusUpdateOptions{Status: incident.StatusActive})
+		require.NoError(t, err)
+
+		require.Len(t, messages, 5)
+		require.Equal(t, messages[0], rescheduled)
+		require.Equal(t, 1, rescheduled.Attempts)
+		require.Contains(t, rescheduled.LastError, "channel archived")
+		require.Greater(t, rescheduled.NextAttemptAt, model.GetMillis())
+		require.Equal(t, []string{messages[1].ID, messages[2].ID, messages[3].ID, messages[4].ID}, deleted)
+		require.Equal(t, 1, m.metrics.broadcastFailures)
+	})
+
+	t.Run("the posts of a change that failed to commit are deleted", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.pluginAPI.On("DeletePost", "commander_post_id").Return(nil)
+
+		m.store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+		m.poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "commander_post_id"}, nil)
+		m.store.EXPECT().Commit(gomock.Any()).Return(nil, errors.New("deadlock"))
+
+		err := s.ChangeCommander("incident_id", "user_id", "new_id")
+		require.Error(t, err)
+		m.pluginAPI.AssertNumberOfCalls(t, "DeletePost", 1)
+	})
+
+	t.Run("process runs the due messages", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.pluginAPI.On("GetPost", "reminder_post_id").Return(&model.Post{Id: "reminder_post_id"}, nil)
+		m.pluginAPI.On("DeletePost", "reminder_post_id").Return(nil)
+
+		m.store.EXPECT().GetDueOutboxMessages(gomock.Any(), gomock.Any()).Return([]*incident.OutboxMessage{
+			{ID: "delete_id", Type: "delete_post", Payload: []byte(`{"post_id":"reminder_post_id"}`)},
+			{ID: "publish_id", Type: "publish_incident", Payload: []byte(`{"incident_id":"incident_id"}`)},
+		}, nil)
+		m.store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+		m.poster.EXPECT().PublishWebsocketEventToChannel("incident_updated", gomock.Any(), "channel_id")
+		m.store.EXPECT().DeleteOutboxMessages([]string{"delete_id", "publish_id"}).Return(nil)
+
+		require.NoError(t, s.ProcessOutbox())
+		m.pluginAPI.AssertNumberOfCalls(t, "DeletePost", 1)
+	})
+
+	t.Run("a retried reminder update uses the current reminder", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.store.EXPECT().GetDueOutboxMessages(gomock.Any(), gomock.Any()).Return([]*incident.OutboxMessage{
+			{ID: "reminder_id", Type: "update_reminder", Payload: []byte(`{"incident_id":"incident_id","post_id":"status_post_id"}`), Attempts: 3},
+		}, nil)
+		m.store.EXPECT().GetIncident("incident_id").DoAndReturn(func(string) (*incident.Incident, error) {
+			theIncident := newIncident()
+			theIncident.StatusPosts = []incident.StatusPost{{ID: "status_post_id", Status: incident.StatusActive, CreateAt: 1}}
+			theIncident.PreviousReminder = 30 * time.Minute
+			return theIncident, nil
+		})
+		m.scheduler.EXPECT().Cancel("incident_id")
+		m.scheduler.EXPECT().ScheduleOnce("incident_id", gomock.Any()).Return(nil, nil)
+		m.store.EXPECT().DeleteOutboxMessages([]string{"reminder_id"}).Return(nil)
+
+		require.NoError(t, s.ProcessOutbox())
+	})
+
+	t.Run("a retried reminder update is dropped after a later status update", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.store.EXPECT().GetDueOutboxMessages(gomock.Any(), gomock.Any()).Return([]*incident.OutboxMessage{
+			{ID: "reminder_id", Type: "update_reminder", Payload: []byte(`{"incident_id":"incident_id","post_id":"status_post_id"}`), Attempts: 3},
+		}, nil)
+		m.store.EXPECT().GetIncident("incident_id").DoAndReturn(func(string) (*incident.Incident, error) {
+			theIncident := newIncident()
+			theIncident.StatusPosts = []incident.StatusPost{
+				{ID: "status_post_id", Status: incident.StatusResolved, CreateAt: 1},
+				{ID: "archive_post_id", Status: incident.StatusArchived, CreateAt: 2},
+			}
+			theIncident.PreviousReminder = 30 * time.Minute
+			return theIncident, nil
+		})
+		m.store.EXPECT().DeleteOutboxMessages([]string{"reminder_id"}).Return(nil)
+
+		require.NoError(t, s.ProcessOutbox())
+	})
+
+	t.Run("messages failing too many times are dropped", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.store.EXPECT().GetDueOutboxMessages(gomock.Any(), gomock.Any()).Return([]*incident.OutboxMessage{
+			{ID: "unknown_id", Type: "unknown", Payload: []byte(`{}`), Attempts: 9},
+		}, nil)
+		m.logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+		m.store.EXPECT().DeleteOutboxMessages([]string{"unknown_id"}).Return(nil)
+
+		require.NoError(t, s.ProcessOutbox())
+	})
+
+	t.Run("process fails if the store does", func(t *testing.T) {
+		s, m := setup(t)
+
+		m.store.EXPECT().GetDueOutboxMessages(gomock.Any(), gomock.Any()).Return(nil, errors.New("no database"))
+
+		require.Error(t, s.ProcessOutbox())
+	})
+}
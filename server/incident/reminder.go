@@ -5,18 +5,27 @@ package incident
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 )
 
 type Reminder struct {
 	IncidentID string `json:"incident_id"`
 }
 
-// HandleReminder is the handler for all reminder events.
+// HandleReminder is the handler for all reminder events, including the expiration of commander
+// handoffs since the scheduler has a single callback.
 func (s *ServiceImpl) HandleReminder(key string) {
+	if strings.HasPrefix(key, handoffKeyPrefix) {
+		s.handleHandoffExpiration(strings.TrimPrefix(key, handoffKeyPrefix))
+		return
+	}
+
 	incidentToModify, err := s.GetIncident(key)
 	if err != nil {
 		s.logger.Errorf(errors.Wrapf(err, "HandleReminder failed to get incident id: %s", key).Error())
@@ -60,11 +69,19 @@ func (s *ServiceImpl) HandleReminder(key string) {
 		s.logger.Errorf(errors.Wrap(err, "HandleReminder error posting reminder message").Error())
 		return
 	}
+	s.metrics.IncrementRemindersFired()
 
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 	incidentToModify.ReminderPostID = post.Id
 	if err = s.store.UpdateIncident(incidentToModify); err != nil {
 		s.logger.Errorf(errors.Wrapf(err, "error updating with reminder post id, incident id: %s", incidentToModify.ID).Error())
+		return
 	}
+	s.recordSystemChange("post_reminder", before, incidentToModify)
+
+	s.sendChangesToClient(incidentToModify, previousVersion, nil,
+		Change{Path: ReminderPostIDPath, Value: post.Id})
 }
 
 // SetReminder sets a reminder. After timeInMinutes in the future, the commander will be
@@ -73,6 +90,7 @@ func (s *ServiceImpl) SetReminder(incidentID string, fromNow time.Duration) erro
 	if _, err := s.scheduler.ScheduleOnce(incidentID, time.Now().Add(fromNow)); err != nil {
 		return errors.Wrap(err, "unable to schedule reminder")
 	}
+	s.metrics.IncrementRemindersScheduled()
 
 	return nil
 }
@@ -88,8 +106,18 @@ func (s *ServiceImpl) RemoveReminderPost(incidentID string) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve incident")
 	}
+	previousVersion := incidentToModify.Version
 
-	return s.removeReminderPost(incidentToModify)
+	if err = s.removeReminderPost(incidentToModify); err != nil {
+		return err
+	}
+
+	if incidentToModify.Version != previousVersion {
+		s.sendChangesToClient(incidentToModify, previousVersion, nil,
+			Change{Path: ReminderPostIDPath, Value: incidentToModify.ReminderPostID})
+	}
+
+	return nil
 }
 
 // removeReminderPost will remove the reminder post in the incident channel (if any).
@@ -111,10 +139,12 @@ func (s *ServiceImpl) removeReminderPost(incidentToModify *Incident) error {
 		return errors.Wrapf(err, "failed to delete reminder post")
 	}
 
+	before := audit.Snapshot(incidentToModify)
 	incidentToModify.ReminderPostID = ""
 	if err = s.store.UpdateIncident(incidentToModify); err != nil {
 		return errors.Wrapf(err, "error updating incident removing reminder post id")
 	}
+	s.recordSystemChange("remove_reminder_post", before, incidentToModify)
 
 	return nil
 }
@@ -0,0 +1,287 @@
+package incident
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SearchPerPageDefault is the number of search results returned when no page size is given.
+	SearchPerPageDefault = 20
+
+	// SearchPerPageMax is the maximum number of search results returned in a page.
+	SearchPerPageMax = 200
+
+	// snippetLength is the number of characters, roughly, of the snippets shown in search results.
+	snippetLength = 160
+
+	// maxSnippets is the maximum number of snippets returned per search result.
+	maxSnippets = 3
+)
+
+// The fields of an incident a search looks into, in the order their snippets are returned.
+const (
+	SearchFieldName              = "name"
+	SearchFieldDescription       = "description"
+	SearchFieldStatusUpdates     = "status_updates"
+	SearchFieldTimelineSummaries = "timeline_summaries"
+	SearchFieldChecklistItems    = "checklist_items"
+	SearchFieldProperties        = "properties"
+)
+
+// SearchOptions specifies the parameters of a full-text search through the incidents.
+type SearchOptions struct {
+	// TeamID is the team to search in. Required.
+	TeamID string
+
+	// Terms are the words to look for, as typed by the user. Incidents are matched by words, so
+	// the terms are never interpreted as operators or wildcards.
+	Terms string
+
+	// Pagination options.
+	Page    int
+	PerPage int
+}
+
+// SearchContent is the text of an incident a search looks into. Status updates, timeline
+// summaries, checklist items and properties are one per line.
+type SearchContent struct {
+	Name              string
+	Description       string
+	StatusUpdates     string
+	TimelineSummaries string
+	ChecklistItems    string
+	Properties        string
+}
+
+// SearchResult is an incident matched by a search.
+type SearchResult struct {
+	Incident Incident  `json:"incident"`
+	Score    float64   `json:"score"`
+	Snippets []Snippet `json:"snippets"`
+
+	// Content is the text the incident was matched on, used to build the snippets.
+	Content SearchContent `json:"-"`
+}
+
+// SearchResults is a page of search results, sorted by decreasing relevance.
+type SearchResults struct {
+	HasMore bool           `json:"has_more"`
+	Items   []SearchResult `json:"items"`
+}
+
+// Snippet is an excerpt of a field of an incident containing some of the search terms.
+type Snippet struct {
+	// Field is the field the excerpt comes from, one of the SearchField constants.
+	Field string `json:"field"`
+
+	// Text is the excerpt, as plain text.
+	Text string `json:"text"`
+
+	// HTML is the excerpt escaped as HTML, with the search terms wrapped in <mark> elements.
+	HTML string `json:"html"`
+
+	// highlights are the rune offsets in Text of the search terms.
+	highlights []highlight
+}
+
+type highlight struct {
+	start, end int
+}
+
+// ValidateSearchOptions checks the search options and fills in the defaults.
+func ValidateSearchOptions(options *SearchOptions) error {
+	if !model.IsValidId(options.TeamID) {
+		return errors.New("bad parameter 'team_id': must be 26 characters")
+	}
+
+	if len(SearchTerms(options.Terms)) == 0 {
+		return errors.New("bad parameter 'terms': must contain at least one word")
+	}
+
+	if options.Page < 0 {
+		return errors.New("bad parameter 'page': must not be negative")
+	}
+
+	if options.PerPage <= 0 {
+		options.PerPage = SearchPerPageDefault
+	} else if options.PerPage > SearchPerPageMax {
+		options.PerPage = SearchPerPageMax
+	}
+
+	return nil
+}
+
+// SearchTerms splits the terms of a search into lowercase words, without duplicates.
+func SearchTerms(terms string) []string {
+	words := strings.FieldsFunc(strings.ToLower(terms), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	unique := words[:0]
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+
+	return unique
+}
+
+// BuildSnippets returns excerpts of the content around the words starting with one of the terms,
+// which the full-text search will have matched with some stemming.
+func BuildSnippets(content SearchContent, terms []string) []Snippet {
+	fields := []struct {
+		name string
+		text string
+	}{
+		{SearchFieldName, content.Name},
+		{SearchFieldDescription, content.Description},
+		{SearchFieldStatusUpdates, content.StatusUpdates},
+		{SearchFieldTimelineSummaries, content.TimelineSummaries},
+		{SearchFieldChecklistItems, content.ChecklistItems},
+		{SearchFieldProperties, content.Properties},
+	}
+
+	var snippets []Snippet
+	for _, field := range fields {
+		for _, line := range strings.Split(field.text, "\n") {
+			if snippet, ok := buildSnippet(field.name, line, terms); ok {
+				snippets = append(snippets, snippet)
+				if len(snippets) == maxSnippets {
+					return snippets
+				}
+			}
+		}
+	}
+
+	return snippets
+}
+
+func buildSnippet(field, text string, terms []string) (Snippet, bool) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+
+	matches := findTerms(runes, terms)
+	if len(matches) == 0 {
+		return Snippet{}, false
+	}
+
+	// Center the excerpt on the first match, extending it to whole words.
+	start, end := 0, len(runes)
+	if len(runes) > snippetLength {
+		start = matches[0].start - snippetLength/3
+		if start < 0 {
+			start = 0
+		}
+		end = start + snippetLength
+		if end > len(runes) {
+			end = len(runes)
+			start = end - snippetLength
+		}
+		for start > 0 && runes[start-1] != ' ' {
+			start--
+		}
+		for end < len(runes) && runes[end] != ' ' {
+			end++
+		}
+	}
+
+	snippet := Snippet{Field: field}
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	excerpt := []rune(prefix + string(runes[start:end]))
+	if end < len(runes) {
+		excerpt = append(excerpt, '…')
+	}
+	snippet.Text = string(excerpt)
+
+	offset := len([]rune(prefix)) - start
+	for _, match := range matches {
+		if match.start >= start && match.end <= end {
+			snippet.highlights = append(snippet.highlights, highlight{match.start + offset, match.end + offset})
+		}
+	}
+	snippet.HTML = snippet.render(html.EscapeString, "<mark>", "</mark>")
+
+	return snippet, true
+}
+
+// findTerms returns the positions of the words of text starting with one of the terms, ignoring
+// case, with the end of each match at the end of the term.
+func findTerms(text []rune, terms []string) []highlight {
+	var matches []highlight
+	for i := 0; i < len(text); i++ {
+		if !isWordRune(text[i]) || (i > 0 && isWordRune(text[i-1])) {
+			continue
+		}
+
+		longest := 0
+		for _, term := range terms {
+			termRunes := []rune(term)
+			if len(termRunes) > longest && hasPrefixFold(text[i:], termRunes) {
+				longest = len(termRunes)
+			}
+		}
+		if longest > 0 {
+			matches = append(matches, highlight{i, i + longest})
+			i += longest - 1
+		}
+	}
+
+	return matches
+}
+
+func hasPrefixFold(text, prefix []rune) bool {
+	if len(prefix) > len(text) {
+		return false
+	}
+	for i := range prefix {
+		if unicode.ToLower(text[i]) != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Markdown renders the snippet as markdown, escaping the text and showing the search terms in bold.
+func (s Snippet) Markdown() string {
+	return s.render(escapeMarkdown, "**", "**")
+}
+
+func (s Snippet) render(escape func(string) string, open, close string) string {
+	runes := []rune(s.Text)
+
+	var b strings.Builder
+	last := 0
+	for _, h := range s.highlights {
+		b.WriteString(escape(string(runes[last:h.start])))
+		b.WriteString(open)
+		b.WriteString(escape(string(runes[h.start:h.end])))
+		b.WriteString(close)
+		last = h.end
+	}
+	b.WriteString(escape(string(runes[last:])))
+
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `~`, `\~`, `[`, `\[`, `]`, `\]`,
+	`(`, `\(`, `)`, `\)`, `#`, `\#`, `>`, `\>`, `<`, `\<`, `|`, `\|`, `@`, `\@`,
+)
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,70 @@
+package incident
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTerms(t *testing.T) {
+	require.Equal(t, []string{"database", "down", "50", "of", "users"}, SearchTerms("Database DOWN: 50% of users, database!"))
+	require.Empty(t, SearchTerms(` %_*"+- `))
+}
+
+func TestValidateSearchOptions(t *testing.T) {
+	options := SearchOptions{TeamID: model.NewId(), Terms: "outage"}
+	require.NoError(t, ValidateSearchOptions(&options))
+	require.Equal(t, SearchPerPageDefault, options.PerPage)
+
+	options = SearchOptions{TeamID: model.NewId(), Terms: "outage", PerPage: 1000}
+	require.NoError(t, ValidateSearchOptions(&options))
+	require.Equal(t, SearchPerPageMax, options.PerPage)
+
+	require.Error(t, ValidateSearchOptions(&SearchOptions{TeamID: model.NewId(), Terms: "%%"}))
+	require.Error(t, ValidateSearchOptions(&SearchOptions{TeamID: "team", Terms: "outage"}))
+}
+
+func TestBuildSnippets(t *testing.T) {
+	t.Run("fields and lines", func(t *testing.T) {
+		snippets := BuildSnippets(SearchContent{
+			Name:          "Database outage",
+			Description:   "Nothing relevant",
+			StatusUpdates: "Investigating\nThe <primary> database is back",
+		}, []string{"database"})
+
+		require.Len(t, snippets, 2)
+		require.Equal(t, SearchFieldName, snippets[0].Field)
+		require.Equal(t, "<mark>Database</mark> outage", snippets[0].HTML)
+		require.Equal(t, SearchFieldStatusUpdates, snippets[1].Field)
+		require.Equal(t, "The <primary> database is back", snippets[1].Text)
+		require.Equal(t, "The &lt;primary&gt; <mark>database</mark> is back", snippets[1].HTML)
+		require.Equal(t, `The \<primary\> **database** is back`, snippets[1].Markdown())
+	})
+
+	t.Run("prefix matches", func(t *testing.T) {
+		snippets := BuildSnippets(SearchContent{Description: "Deploying the rollback, predeploy checks"}, []string{"deploy"})
+		require.Len(t, snippets, 1)
+		require.Equal(t, "<mark>Deploy</mark>ing the rollback, predeploy checks", snippets[0].HTML)
+	})
+
+	t.Run("long text is cut around the first match", func(t *testing.T) {
+		text := strings.Repeat("lorem ipsum ", 30) + "failover " + strings.Repeat("dolor sit ", 30)
+		snippets := BuildSnippets(SearchContent{Description: text}, []string{"failover"})
+		require.Len(t, snippets, 1)
+		require.True(t, strings.HasPrefix(snippets[0].Text, "…"))
+		require.True(t, strings.HasSuffix(snippets[0].Text, "…"))
+		require.Contains(t, snippets[0].HTML, "<mark>failover</mark>")
+		require.Less(t, len([]rune(snippets[0].Text)), snippetLength+20)
+	})
+
+	t.Run("at most a few snippets", func(t *testing.T) {
+		snippets := BuildSnippets(SearchContent{ChecklistItems: "fix a\nfix b\nfix c\nfix d"}, []string{"fix"})
+		require.Len(t, snippets, maxSnippets)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		require.Empty(t, BuildSnippets(SearchContent{Name: "Outage"}, []string{"database"}))
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	stripmd "github.com/writeas/go-strip-markdown"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
@@ -36,6 +37,11 @@ type ServiceImpl struct {
 	logger        bot.Logger
 	scheduler     JobOnceScheduler
 	telemetry     Telemetry
+	auditor       audit.Service
+	metrics       Metrics
+
+	// source is where the changes recorded in the audit log come from.
+	source audit.Source
 }
 
 var allNonSpaceNonWordRegex = regexp.MustCompile(`[^\w\s]`)
@@ -58,9 +64,13 @@ const DialogFieldReminderInSecondsKey = "reminder"
 // DialogFieldStatusKey is the key for the status select field used in UpdateIncidentDialog
 const DialogFieldStatusKey = "status"
 
+// DialogFieldOverrideExitCriteriaKey is the key for the override checkbox used in UpdateIncidentDialog
+const DialogFieldOverrideExitCriteriaKey = "override_exit_criteria"
+
 // NewService creates a new incident ServiceImpl.
 func NewService(pluginAPI *pluginapi.Client, store Store, poster bot.Poster, logger bot.Logger,
-	configService config.Service, scheduler JobOnceScheduler, telemetry Telemetry) *ServiceImpl {
+	configService config.Service, scheduler JobOnceScheduler, telemetry Telemetry, auditor audit.Service,
+	metrics Metrics) *ServiceImpl {
 	return &ServiceImpl{
 		pluginAPI:     pluginAPI,
 		store:         store,
@@ -69,12 +79,91 @@ func NewService(pluginAPI *pluginapi.Client, store Store, poster bot.Poster, log
 		configService: configService,
 		scheduler:     scheduler,
 		telemetry:     telemetry,
+		auditor:       auditor,
+		metrics:       metrics,
+		source:        audit.SourceSystem,
 	}
 }
 
-// GetIncidents returns filtered incidents and the total count before paging.
+// WithAuditSource returns a copy of the service recording its changes in the audit log as coming
+// from source.
+func (s *ServiceImpl) WithAuditSource(source audit.Source) Service {
+	scoped := *s
+	scoped.source = source
+	return &scoped
+}
+
+// recordChange records in the audit log the change made by userID to theIncident, given the
+// snapshot of the incident before the change, nil if it was just created.
+func (s *ServiceImpl) recordChange(userID, action string, before json.RawMessage, theIncident *Incident) {
+	s.auditor.Record(audit.Record{
+		ActorUserID: userID,
+		Source:      s.source,
+		TargetType:  audit.TargetIncident,
+		TargetID:    theIncident.ID,
+		TeamID:      theIncident.TeamID,
+		Action:      action,
+		Changes:     audit.Diff(before, audit.Snapshot(theIncident)),
+	})
+}
+
+// recordSystemChange records in the audit log a change made by the plugin itself, as its bot.
+func (s *ServiceImpl) recordSystemChange(action string, before json.RawMessage, theIncident *Incident) {
+	s.recordChange(s.configService.GetConfiguration().BotUserID, action, before, theIncident)
+}
+
+// GetIncidents returns filtered incidents and the total count before paging. When the incidents
+// may come from several teams, each one comes with its team.
 func (s *ServiceImpl) GetIncidents(requesterInfo RequesterInfo, options FilterOptions) (*GetIncidentsResults, error) {
-	return s.store.GetIncidents(requesterInfo, options)
+	results, err := s.store.GetIncidents(requesterInfo, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TeamID == "" || len(options.TeamIDs) > 0 {
+		if err := s.addTeamInfo(results.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// addTeamInfo sets the team of each incident.
+func (s *ServiceImpl) addTeamInfo(incidents []Incident) error {
+	teams := make(map[string]*TeamInfo)
+	for i := range incidents {
+		teamID := incidents[i].TeamID
+		if _, ok := teams[teamID]; !ok {
+			team, err := s.pluginAPI.Team.Get(teamID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get team with id '%s'", teamID)
+			}
+			teams[teamID] = &TeamInfo{ID: team.Id, Name: team.Name, DisplayName: team.DisplayName}
+		}
+		incidents[i].Team = teams[teamID]
+	}
+
+	return nil
+}
+
+// SearchIncidents returns the incidents matching the search terms, sorted by relevance and with
+// snippets of the text they matched on.
+func (s *ServiceImpl) SearchIncidents(requesterInfo RequesterInfo, options SearchOptions) (*SearchResults, error) {
+	results, err := s.store.SearchIncidents(requesterInfo, options)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := SearchTerms(options.Terms)
+	for i := range results.Items {
+		results.Items[i].Snippets = BuildSnippets(results.Items[i].Content, terms)
+		if results.Items[i].Snippets == nil {
+			results.Items[i].Snippets = []Snippet{}
+		}
+	}
+
+	return results, nil
 }
 
 // CreateIncident creates a new incident. userID is the user who initiated the CreateIncident.
@@ -107,6 +196,7 @@ func (s *ServiceImpl) CreateIncident(incdnt *Incident, userID string, public boo
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create incident")
 	}
+	s.recordChange(userID, "create_incident", nil, incdnt)
 
 	s.telemetry.CreateIncident(incdnt, userID, public)
 
@@ -199,7 +289,7 @@ func (s *ServiceImpl) OpenUpdateStatusDialog(incidentID string, triggerID string
 		message = currentIncident.ReminderMessageTemplate
 	}
 
-	dialog, err := s.newUpdateIncidentDialog(message, currentIncident.BroadcastChannelID, currentIncident.CurrentStatus(), currentIncident.PreviousReminder)
+	dialog, err := s.newUpdateIncidentDialog(message, currentIncident)
 	if err != nil {
 		return errors.Wrap(err, "failed to create update status dialog")
 	}
@@ -255,9 +345,24 @@ func (s *ServiceImpl) UpdateStatus(incidentID, userID string, options StatusUpda
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve incident")
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	previousStatus := incidentToModify.CurrentStatus()
 
+	var overriddenCriteria []string
+	if incidentToModify.IsActive() && (options.Status == StatusResolved || options.Status == StatusArchived) {
+		if unmet := incidentToModify.UnmetExitCriteria(); len(unmet) > 0 {
+			if !options.OverrideExitCriteria {
+				return &ExitCriteriaError{Unmet: unmet}
+			}
+			if !s.canOverrideExitCriteria(incidentToModify, userID) {
+				return errors.Wrap(ErrPermission, "only the commander or a system admin can override the exit criteria")
+			}
+			overriddenCriteria = unmet
+		}
+	}
+
 	post := model.Post{
 		Message:   options.Message,
 		UserId:    userID,
@@ -277,41 +382,14 @@ func (s *ServiceImpl) UpdateStatus(incidentID, userID string, options StatusUpda
 		})
 
 	incidentToModify.PreviousReminder = options.Reminder
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrap(err, "failed to update incident")
-	}
-
-	if err = s.store.UpdateStatus(&SQLStatusPost{
-		IncidentID: incidentToModify.ID,
-		PostID:     post.Id,
-		Status:     options.Status,
-		EndAt:      incidentToModify.ResolvedAt(),
-	}); err != nil {
-		return errors.Wrap(err, "failed to write status post to store. There is now inconsistent state.")
-	}
-
-	if err2 := s.broadcastStatusUpdate(options.Message, incidentToModify, userID, post.Id); err2 != nil {
-		s.pluginAPI.Log.Warn("failed to broadcast the status update to channel", "ChannelID", incidentToModify.BroadcastChannelID)
-	}
-
-	// Remove pending reminder (if any), even if current reminder was set to "none" (0 minutes)
-	s.RemoveReminder(incidentID)
-
-	if options.Reminder != 0 && options.Status != StatusArchived {
-		if err = s.SetReminder(incidentID, options.Reminder); err != nil {
-			return errors.Wrap(err, "failed to set the reminder for incident")
-		}
-	}
-
-	if err = s.removeReminderPost(incidentToModify); err != nil {
-		return errors.Wrap(err, "failed to remove reminder post")
-	}
+	reminderPostID := incidentToModify.ReminderPostID
+	incidentToModify.ReminderPostID = ""
 
 	summary := ""
 	if previousStatus != options.Status {
 		summary = fmt.Sprintf("%s to %s", previousStatus, options.Status)
 	}
-	event := &TimelineEvent{
+	events := []*TimelineEvent{{
 		IncidentID:    incidentID,
 		CreateAt:      post.CreateAt,
 		EventAt:       post.CreateAt,
@@ -319,21 +397,106 @@ func (s *ServiceImpl) UpdateStatus(incidentID, userID string, options StatusUpda
 		Summary:       summary,
 		PostID:        post.Id,
 		SubjectUserID: userID,
+	}}
+	postIDs := []string{post.Id}
+
+	if len(overriddenCriteria) > 0 {
+		overrideEvent, overrideErr := s.exitCriteriaOverrideEvent(incidentToModify, userID, options.Status, overriddenCriteria)
+		if overrideErr != nil {
+			s.deletePosts(postIDs...)
+			return overrideErr
+		}
+		events = append(events, overrideEvent)
+		postIDs = append(postIDs, overrideEvent.PostID)
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
+	changes := []Change{
+		{Path: StatusPostsPath, Value: incidentToModify.StatusPosts},
+		{Path: EndAtPath, Value: incidentToModify.ResolvedAt()},
+		{Path: PreviousReminderPath, Value: incidentToModify.PreviousReminder},
+		{Path: ReminderPostIDPath, Value: incidentToModify.ReminderPostID},
 	}
 
-	s.telemetry.UpdateStatus(incidentToModify, userID)
+	// Command can't be handed over once the incident is resolved or archived.
+	var handoff *CommanderHandoff
+	if !incidentToModify.IsActive() && incidentToModify.CommanderHandoff != nil {
+		handoff = incidentToModify.CommanderHandoff
+		incidentToModify.CommanderHandoff = nil
+		changes = append(changes, Change{Path: CommanderHandoffPath, Value: nil})
+	}
 
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return err
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		StatusPost: &SQLStatusPost{
+			IncidentID: incidentToModify.ID,
+			PostID:     post.Id,
+			Status:     options.Status,
+			EndAt:      incidentToModify.ResolvedAt(),
+			Message:    options.Message,
+		},
+		TimelineEvents: events,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			if incidentToModify.BroadcastChannelID != "" {
+				o.add(outboxBroadcastStatusUpdate, broadcastStatusUpdatePayload{
+					IncidentID: incidentID,
+					AuthorID:   userID,
+					PostID:     post.Id,
+					Message:    options.Message,
+				})
+			}
+			o.add(outboxUpdateReminder, updateReminderPayload{IncidentID: incidentID, PostID: post.Id})
+			if reminderPostID != "" {
+				o.add(outboxDeletePost, deletePostPayload{PostID: reminderPostID})
+			}
+			o.telemetry(telemetryPayload{Event: telemetryUpdateStatus, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, events, changes...)
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(postIDs...)
+		return errors.Wrap(err, "failed to update the status of the incident")
+	}
+	s.recordChange(userID, "update_status", before, incidentToModify)
+
+	if handoff != nil {
+		s.withdrawHandoff(incidentID, handoff,
+			fmt.Sprintf("This handoff request was withdrawn: the incident is %s.", strings.ToLower(options.Status)))
 	}
 
 	return nil
 }
 
+// canOverrideExitCriteria returns true if userID is the commander of the incident or a system admin.
+func (s *ServiceImpl) canOverrideExitCriteria(theIncident *Incident, userID string) bool {
+	return theIncident.CommanderUserID == userID ||
+		s.pluginAPI.User.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM)
+}
+
+// exitCriteriaOverrideEvent leaves a trace in the channel of the exit criteria that were not met
+// when userID resolved the incident anyway, and returns the timeline event to record it.
+func (s *ServiceImpl) exitCriteriaOverrideEvent(theIncident *Incident, userID, status string, unmet []string) (*TimelineEvent, error) {
+	post, err := s.modificationMessage(userID, theIncident.ChannelID,
+		fmt.Sprintf("changed the status to **%s** without meeting the exit criteria:\n* %s", status, strings.Join(unmet, "\n* ")))
+	if err != nil {
+		return nil, err
+	}
+
+	event := &TimelineEvent{
+		IncidentID:    theIncident.ID,
+		CreateAt:      post.CreateAt,
+		EventAt:       post.CreateAt,
+		EventType:     ExitCriteriaOverridden,
+		Summary:       fmt.Sprintf("changed the status to %s without meeting the exit criteria", status),
+		Details:       strings.Join(unmet, "\n"),
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+
+	return event, nil
+}
+
 // GetIncident gets an incident by ID. Returns error if it could not be found.
 func (s *ServiceImpl) GetIncident(incidentID string) (*Incident, error) {
 	return s.store.GetIncident(incidentID)
@@ -405,6 +568,8 @@ func (s *ServiceImpl) ChangeCommander(incidentID, userID, commanderID string) er
 	if incidentToModify.CommanderUserID == commanderID {
 		return nil
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	oldCommander, err := s.pluginAPI.User.Get(incidentToModify.CommanderUserID)
 	if err != nil {
@@ -415,11 +580,6 @@ func (s *ServiceImpl) ChangeCommander(incidentID, userID, commanderID string) er
 		return errors.Wrapf(err, "failed to to resolve user %s", commanderID)
 	}
 
-	incidentToModify.CommanderUserID = commanderID
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrapf(err, "failed to update incident")
-	}
-
 	mainChannelID := incidentToModify.ChannelID
 	modifyMessage := fmt.Sprintf("changed the incident commander from **@%s** to **@%s**.",
 		oldCommander.Username, newCommander.Username)
@@ -428,6 +588,16 @@ func (s *ServiceImpl) ChangeCommander(incidentID, userID, commanderID string) er
 		return err
 	}
 
+	incidentToModify.CommanderUserID = commanderID
+	changes := []Change{{Path: CommanderPath, Value: commanderID}}
+
+	// A pending handoff was requested by the former commander.
+	handoff := incidentToModify.CommanderHandoff
+	if handoff != nil {
+		incidentToModify.CommanderHandoff = nil
+		changes = append(changes, Change{Path: CommanderHandoffPath, Value: nil})
+	}
+
 	event := &TimelineEvent{
 		IncidentID:    incidentID,
 		CreateAt:      post.CreateAt,
@@ -438,191 +608,179 @@ func (s *ServiceImpl) ChangeCommander(incidentID, userID, commanderID string) er
 		SubjectUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryChangeCommander, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, []*TimelineEvent{event}, changes...)
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
 	}
+	s.recordChange(userID, "change_commander", before, incidentToModify)
 
-	s.telemetry.ChangeCommander(incidentToModify, userID)
-
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return err
+	if handoff != nil {
+		s.withdrawHandoff(incidentID, handoff,
+			fmt.Sprintf("This handoff request was withdrawn: **@%s** is now the commander.", newCommander.Username))
 	}
 
 	return nil
 }
 
-func getPropertyListItem(incident *Incident, propertyID string) (*playbook.PropertylistItem, error) {
-	for _, v := range incident.Propertylist.Items {
+func getPropertyListItemIndex(incident *Incident, propertyID string) (int, error) {
+	for i, v := range incident.Propertylist.Items {
 		if v.ID == propertyID {
-			return &v, nil
+			return i, nil
 		}
 	}
 
-	return nil, errors.New("failed to find property")
-}
-
-func getSelectionListItem(property *playbook.PropertylistItem, selectionID string) (*playbook.SelectionlistItem, error) {
-	for _, v := range property.Selection.Items {
-		if v.ID == selectionID {
-			return &v, nil
-		}
-	}
-
-	return nil, errors.New("failed to find property")
+	return -1, errors.Wrapf(ErrNotFound, "failed to find property %s", propertyID)
 }
 
 // ChangePropertySelectionValue processes a request from userID to change the property value of type selection
-// with id propertyID for incidentID to selectionID.
+// with id propertyID for incidentID to selectionID. Multiselect properties accept several comma-separated IDs.
 func (s *ServiceImpl) ChangePropertySelectionValue(incidentID string, userID string, propertyID string, selectionID string) error {
-	incidentToModify, err := s.store.GetIncident(incidentID)
+	return s.ChangePropertyValues(incidentID, userID, propertyID, strings.Split(selectionID, ","))
+}
+
+// ChangePropertyFreetextValue processes a request from userID to change the property value of type freetext
+// with id propertyID for incidentID to freetextValue. Multiselect properties accept several comma-separated values.
+func (s *ServiceImpl) ChangePropertyFreetextValue(incidentID string, userID string, propertyID string, freetextValue string) error {
+	incidentToModify, err := s.propertylistParamsVerify(incidentID, userID)
 	if err != nil {
 		return err
 	}
 
-	property, err := getPropertyListItem(incidentToModify, propertyID)
+	index, err := getPropertyListItemIndex(incidentToModify, propertyID)
 	if err != nil {
 		return err
 	}
 
-	var propertyTitle = property.Title
-	var oldValueID = property.Selection.SelectedId
-	for i, v := range incidentToModify.Propertylist.Items {
-		if v.ID == propertyID {
-			incidentToModify.Propertylist.Items[i].Selection.SelectedId = selectionID
-		}
-	}
-
-	var oldValue = ""
-	if oldValueID != "" {
-		if property.Selection.IsMultiselect {
-			ids := strings.Split(oldValueID, ",")
-			oldValues := []string{}
-			for _, v := range ids {
-				oldSelection, err := getSelectionListItem(property, v)
-				if err == nil {
-					oldValues = append(oldValues, oldSelection.Value)
-				}
-			}
-			oldValue = strings.Join(oldValues, ",")
-		} else {
-			oldSelection, err := getSelectionListItem(property, oldValueID)
-			if err == nil {
-				oldValue = oldSelection.Value
-			}
-		}
-	}
-
-	var newValue = ""
-	if property.Selection.IsMultiselect {
-		ids := strings.Split(selectionID, ",")
-		var newValues []string
-		for _, v := range ids {
-			newSelection, err := getSelectionListItem(property, v)
-			if err == nil {
-				newValues = append(newValues, newSelection.Value)
-			}
-		}
-		newValue = strings.Join(newValues, ",")
-	} else {
-		newSelection, err := getSelectionListItem(property, selectionID)
-		if err == nil {
-			newValue = newSelection.Value
-		}
+	values := []string{freetextValue}
+	if incidentToModify.Propertylist.Items[index].Treetext.IsMultiselect {
+		values = strings.Split(freetextValue, ",")
 	}
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrapf(err, "failed to update incident")
-	}
+	return s.changePropertyValues(incidentToModify, userID, index, values)
+}
 
-	mainChannelID := incidentToModify.ChannelID
-	modifyMessage := fmt.Sprintf("changed the incident property '**%s**' from **%s** to **%s**.",
-		propertyTitle, oldValue, newValue)
-	post, err := s.modificationMessage(userID, mainChannelID, modifyMessage)
+// ChangePropertyValues processes a request from userID to change the value of the property with id
+// propertyID for incidentID. The values are parsed and validated according to the type of the property.
+func (s *ServiceImpl) ChangePropertyValues(incidentID, userID, propertyID string, values []string) error {
+	incidentToModify, err := s.propertylistParamsVerify(incidentID, userID)
 	if err != nil {
 		return err
 	}
 
-	event := &TimelineEvent{
-		IncidentID:    incidentID,
-		CreateAt:      post.CreateAt,
-		EventAt:       post.CreateAt,
-		EventType:     PropertyValueChanged,
-		Summary:       fmt.Sprintf("'%s' changed to %s", propertyTitle, newValue),
-		PostID:        post.Id,
-		SubjectUserID: userID,
-	}
-
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
-	}
-
-	s.telemetry.PropertyValueChanged(incidentToModify, userID)
-
-	if err = s.sendIncidentToClient(incidentID); err != nil {
+	index, err := getPropertyListItemIndex(incidentToModify, propertyID)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.changePropertyValues(incidentToModify, userID, index, values)
 }
 
-// ChangePropertyFreetextValue processes a request from userID to change the property value of type freetext
-// with id propertyID for incidentID to freetextValue.
-func (s *ServiceImpl) ChangePropertyFreetextValue(incidentID string, userID string, propertyID string, freetextValue string) error {
-	incidentToModify, err := s.store.GetIncident(incidentID)
-	if err != nil {
-		return err
-	}
+func (s *ServiceImpl) changePropertyValues(incidentToModify *Incident, userID string, index int, values []string) error {
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+	property := &incidentToModify.Propertylist.Items[index]
+	oldValues := property.Values()
+	oldValue := property.FormatValue(s.propertyDisplayName)
 
-	property, err := getPropertyListItem(incidentToModify, propertyID)
-	if err != nil {
+	if err := property.SetValues(values); err != nil {
 		return err
 	}
 
-	var propertyTitle = property.Title
-	var oldValue = property.Treetext.Value
-	var newValue = freetextValue
-
-	for i, v := range incidentToModify.Propertylist.Items {
-		if v.ID == propertyID {
-			incidentToModify.Propertylist.Items[i].Treetext.Value = freetextValue
-		}
+	if err := s.checkPropertyReferences(*property, userID); err != nil {
+		return err
 	}
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrapf(err, "failed to update incident")
+	if strings.Join(oldValues, "\x00") == strings.Join(property.Values(), "\x00") {
+		return nil
 	}
+	newValue := property.FormatValue(s.propertyDisplayName)
 
 	mainChannelID := incidentToModify.ChannelID
 	modifyMessage := fmt.Sprintf("changed the incident property '**%s**' from **%s** to **%s**.",
-		propertyTitle, oldValue, newValue)
+		property.Title, oldValue, newValue)
 	post, err := s.modificationMessage(userID, mainChannelID, modifyMessage)
 	if err != nil {
 		return err
 	}
 
 	event := &TimelineEvent{
-		IncidentID:    incidentID,
+		IncidentID:    incidentToModify.ID,
 		CreateAt:      post.CreateAt,
 		EventAt:       post.CreateAt,
 		EventType:     PropertyValueChanged,
-		Summary:       fmt.Sprintf("'%s' changed to %s", propertyTitle, newValue),
+		Summary:       fmt.Sprintf("'%s' changed to %s", property.Title, newValue),
 		PostID:        post.Id,
 		SubjectUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryPropertyValueChanged, IncidentID: incidentToModify.ID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, []*TimelineEvent{event},
+				Change{Path: PropertyPath(index), Value: *property})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
 	}
+	s.recordChange(userID, "change_property_value", before, incidentToModify)
 
-	s.telemetry.PropertyValueChanged(incidentToModify, userID)
+	return nil
+}
 
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return err
+// checkPropertyReferences verifies that the users and channels referenced by a property exist, and
+// that userID can see the referenced channel.
+func (s *ServiceImpl) checkPropertyReferences(property playbook.PropertylistItem, userID string) error {
+	switch property.Type {
+	case playbook.PropertyTypeUser:
+		for _, id := range property.Values() {
+			if _, err := s.pluginAPI.User.Get(id); err != nil {
+				return errors.Wrapf(playbook.ErrInvalidPropertyValue, "property '%s': unknown user '%s'", property.Title, id)
+			}
+		}
+	case playbook.PropertyTypeChannel:
+		for _, id := range property.Values() {
+			if _, err := s.pluginAPI.Channel.Get(id); err != nil ||
+				!s.pluginAPI.User.HasPermissionToChannel(userID, id, model.PERMISSION_READ_CHANNEL) {
+				return errors.Wrapf(playbook.ErrInvalidPropertyValue, "property '%s': unknown channel '%s'", property.Title, id)
+			}
+		}
 	}
 
 	return nil
 }
 
+// propertyDisplayName renders the ID of a user or channel referenced by a property.
+func (s *ServiceImpl) propertyDisplayName(propertyType, id string) string {
+	switch propertyType {
+	case playbook.PropertyTypeUser:
+		if user, err := s.pluginAPI.User.Get(id); err == nil {
+			return "@" + user.Username
+		}
+	case playbook.PropertyTypeChannel:
+		if channel, err := s.pluginAPI.Channel.Get(id); err == nil {
+			return "~" + channel.Name
+		}
+	}
+
+	return id
+}
+
 // ModifyCheckedState checks or unchecks the specified checklist item. Idempotent, will not perform
 // any action if the checklist item is already in the given checked state
 func (s *ServiceImpl) ModifyCheckedState(incidentID, userID, newState string, checklistNumber, itemNumber int) error {
@@ -639,16 +797,15 @@ func (s *ServiceImpl) ModifyCheckedState(incidentID, userID, newState string, ch
 	if newState == itemToCheck.State {
 		return nil
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
+
+	wasAssignee := itemToCheck.AssigneeID == userID
 
 	// Send modification message before the actual modification because we need the postID
 	// from the notification message.
-	s.telemetry.ModifyCheckedState(incidentID, userID, newState, incidentToModify.CommanderUserID == userID, itemToCheck.AssigneeID == userID)
-
 	mainChannelID := incidentToModify.ChannelID
-	modifyMessage := fmt.Sprintf("checked off checklist item **%v**", stripmd.Strip(itemToCheck.Title))
-	if newState == playbook.ChecklistItemStateOpen {
-		modifyMessage = fmt.Sprintf("unchecked checklist item **%v**", stripmd.Strip(itemToCheck.Title))
-	}
+	modifyMessage := checkedStateMessage(itemToCheck.Title, newState)
 	post, err := s.modificationMessage(userID, mainChannelID, modifyMessage)
 	if err != nil {
 		return err
@@ -659,10 +816,6 @@ func (s *ServiceImpl) ModifyCheckedState(incidentID, userID, newState string, ch
 	itemToCheck.StateModifiedPostID = post.Id
 	incidentToModify.Checklists[checklistNumber].Items[itemNumber] = itemToCheck
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrapf(err, "failed to update incident, is now in inconsistent state")
-	}
-
 	event := &TimelineEvent{
 		IncidentID:    incidentID,
 		CreateAt:      itemToCheck.StateModified,
@@ -673,50 +826,101 @@ func (s *ServiceImpl) ModifyCheckedState(incidentID, userID, newState string, ch
 		SubjectUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
-	}
-
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return err
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{
+				Event:        telemetryModifyCheckedState,
+				IncidentID:   incidentID,
+				UserID:       userID,
+				NewState:     newState,
+				WasCommander: incidentToModify.CommanderUserID == userID,
+				WasAssignee:  wasAssignee,
+			})
+			o.publishChanges(incidentToModify, previousVersion, []*TimelineEvent{event},
+				Change{Path: ChecklistItemPath(checklistNumber, itemNumber), Value: itemToCheck})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
 	}
+	s.recordChange(userID, "set_checklist_item_state", before, incidentToModify)
 
 	return nil
 }
 
-// ChangePropertyValue changes the propertylist value
+// checkedStateMessage describes the change of the state of a checklist item to newState.
+func checkedStateMessage(title, newState string) string {
+	switch newState {
+	case playbook.ChecklistItemStateOpen:
+		return fmt.Sprintf("unchecked checklist item **%v**", stripmd.Strip(title))
+	case playbook.ChecklistItemStateInProgress:
+		return fmt.Sprintf("started checklist item **%v**", stripmd.Strip(title))
+	case playbook.ChecklistItemStateSkipped:
+		return fmt.Sprintf("skipped checklist item **%v**", stripmd.Strip(title))
+	default:
+		return fmt.Sprintf("checked off checklist item **%v**", stripmd.Strip(title))
+	}
+}
+
+// ChangePropertyValue changes the value of the property titled propertyTitle. propertyValue is given
+// as a user would type it: option names for selections, @usernames for users, ~channel-names for
+// channels, and comma-separated values for multiselect properties.
 func (s *ServiceImpl) ChangePropertyValue(incidentID, userID string, propertyTitle, propertyValue string) error {
 	incidentToModify, err := s.propertylistParamsVerify(incidentID, userID)
 	if err != nil {
 		return err
 	}
 
-	if !playbook.IsValidPropertylistItemParams(incidentToModify.Propertylist, propertyTitle, propertyValue) {
-		return errors.New("invalid propertylist item values")
+	index := -1
+	for i, item := range incidentToModify.Propertylist.Items {
+		if strings.EqualFold(item.Title, propertyTitle) {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return errors.Wrapf(ErrNotFound, "failed to find property '%s'", propertyTitle)
+	}
+	property := incidentToModify.Propertylist.Items[index]
+
+	values := []string{propertyValue}
+	switch property.Type {
+	case playbook.PropertyTypeSelection, playbook.PropertyTypeUser:
+		values = strings.Split(propertyValue, ",")
+	case playbook.PropertyTypeFreetext, "":
+		if property.Treetext.IsMultiselect {
+			values = strings.Split(propertyValue, ",")
+		}
 	}
 
-	for i, item := range incidentToModify.Propertylist.Items {
-		if item.Title == propertyTitle {
-			if item.Type == "Freetext" {
-				if err := s.ChangePropertyFreetextValue(incidentID, userID, strconv.Itoa(i), propertyValue); err != nil {
-					return err
+	for i, value := range values {
+		value = strings.TrimSpace(value)
+		switch property.Type {
+		case playbook.PropertyTypeSelection:
+			for _, option := range property.Selection.Items {
+				if strings.EqualFold(option.Value, value) {
+					value = option.ID
+					break
 				}
 			}
-			// if selection
-			for j, selection := range item.Selection.Items {
-				if selection.Value == propertyValue {
-					if err := s.ChangePropertySelectionValue(incidentID, userID, strconv.Itoa(i), strconv.Itoa(j)); err != nil {
-						return err
-					}
-				}
+		case playbook.PropertyTypeUser:
+			if user, userErr := s.pluginAPI.User.GetByUsername(strings.TrimPrefix(value, "@")); userErr == nil {
+				value = user.Id
+			}
+		case playbook.PropertyTypeChannel:
+			if channel, channelErr := s.pluginAPI.Channel.GetByName(incidentToModify.TeamID, strings.TrimPrefix(value, "~"), false); channelErr == nil {
+				value = channel.Id
 			}
 		}
+		values[i] = value
 	}
 
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.PropertyValueChanged(incidentToModify, userID)
-
-	return nil
+	return s.changePropertyValues(incidentToModify, userID, index, values)
 }
 
 // ToggleCheckedState checks or unchecks the specified checklist item
@@ -755,6 +959,8 @@ func (s *ServiceImpl) SetAssignee(incidentID, userID, assigneeID string, checkli
 	if assigneeID == itemToCheck.AssigneeID {
 		return nil
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	newAssigneeUsername := noAssigneeName
 	if assigneeID != "" {
@@ -790,10 +996,6 @@ func (s *ServiceImpl) SetAssignee(incidentID, userID, assigneeID string, checkli
 	itemToCheck.AssigneeModifiedPostID = post.Id
 	incidentToModify.Checklists[checklistNumber].Items[itemNumber] = itemToCheck
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
-		return errors.Wrapf(err, "failed to update incident; it is now in an inconsistent state")
-	}
-
 	event := &TimelineEvent{
 		IncidentID:    incidentID,
 		CreateAt:      itemToCheck.AssigneeModified,
@@ -804,15 +1006,22 @@ func (s *ServiceImpl) SetAssignee(incidentID, userID, assigneeID string, checkli
 		SubjectUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return errors.Wrap(err, "failed to create timeline event")
-	}
-
-	s.telemetry.SetAssignee(incidentID, userID)
-
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return err
+	err = s.commit(&UnitOfWork{
+		Incident:       incidentToModify,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetrySetAssignee, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, []*TimelineEvent{event},
+				Change{Path: ChecklistItemPath(checklistNumber, itemNumber), Value: itemToCheck})
+			return o.result()
+		},
+	})
+	if err != nil {
+		s.deletePosts(post.Id)
+		return errors.Wrapf(err, "failed to update incident")
 	}
+	s.recordChange(userID, "set_assignee", before, incidentToModify)
 
 	return nil
 }
@@ -852,10 +1061,9 @@ func (s *ServiceImpl) RunChecklistItemSlashCommand(incidentID, userID string, ch
 	}
 
 	// Record the last (successful) run time.
+	before := audit.Snapshot(incident)
+	previousVersion := incident.Version
 	incident.Checklists[checklistNumber].Items[itemNumber].CommandLastRun = model.GetMillis()
-	if err = s.store.UpdateIncident(incident); err != nil {
-		return "", errors.Wrapf(err, "failed to update incident recording run of slash command")
-	}
 
 	eventTime := model.GetMillis()
 	event := &TimelineEvent{
@@ -867,15 +1075,21 @@ func (s *ServiceImpl) RunChecklistItemSlashCommand(incidentID, userID string, ch
 		SubjectUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
-		return "", errors.Wrap(err, "failed to create timeline event")
-	}
-
-	s.telemetry.RunTaskSlashCommand(incidentID, userID)
-
-	if err = s.sendIncidentToClient(incidentID); err != nil {
-		return "", err
+	err = s.commit(&UnitOfWork{
+		Incident:       incident,
+		TimelineEvents: []*TimelineEvent{event},
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryRunTaskSlashCommand, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incident, previousVersion, []*TimelineEvent{event},
+				Change{Path: ChecklistItemPath(checklistNumber, itemNumber), Value: incident.Checklists[checklistNumber].Items[itemNumber]})
+			return o.result()
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to update incident recording run of slash command")
 	}
+	s.recordChange(userID, "run_checklist_item_command", before, incident)
 
 	return cmdResponse.TriggerId, nil
 }
@@ -886,15 +1100,25 @@ func (s *ServiceImpl) AddPropertylistItem(incidentID, userID string, propertylis
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Propertylist.Items = append(incidentToModify.Propertylist.Items, propertylistItem)
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryAddTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: PropertyPath(len(incidentToModify.Propertylist.Items) - 1), Value: propertylistItem})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.AddTask(incidentID, userID)
+	s.recordChange(userID, "add_property", before, incidentToModify)
 
 	return nil
 }
@@ -905,18 +1129,28 @@ func (s *ServiceImpl) RemovePropertylistItem(incidentID, userID string, itemNumb
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Propertylist.Items = append(
 		incidentToModify.Propertylist.Items[:itemNumber],
 		incidentToModify.Propertylist.Items[itemNumber+1:]...,
 	)
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryRemoveTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: PropertylistItemsPath, Value: incidentToModify.Propertylist.Items})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.RemoveTask(incidentID, userID)
+	s.recordChange(userID, "remove_property", before, incidentToModify)
 
 	return nil
 }
@@ -927,15 +1161,25 @@ func (s *ServiceImpl) AddChecklistItem(incidentID, userID string, checklistNumbe
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Checklists[checklistNumber].Items = append(incidentToModify.Checklists[checklistNumber].Items, checklistItem)
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryAddTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: ChecklistItemPath(checklistNumber, len(incidentToModify.Checklists[checklistNumber].Items)-1), Value: checklistItem})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.AddTask(incidentID, userID)
+	s.recordChange(userID, "add_checklist_item", before, incidentToModify)
 
 	return nil
 }
@@ -946,18 +1190,28 @@ func (s *ServiceImpl) RemoveChecklistItem(incidentID, userID string, checklistNu
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Checklists[checklistNumber].Items = append(
 		incidentToModify.Checklists[checklistNumber].Items[:itemNumber],
 		incidentToModify.Checklists[checklistNumber].Items[itemNumber+1:]...,
 	)
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryRemoveTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: ChecklistItemsPath(checklistNumber), Value: incidentToModify.Checklists[checklistNumber].Items})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.RemoveTask(incidentID, userID)
+	s.recordChange(userID, "remove_checklist_item", before, incidentToModify)
 
 	return nil
 }
@@ -968,15 +1222,25 @@ func (s *ServiceImpl) UpdatePropertylistItem(incidentID, userID string, itemNumb
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Propertylist.Items[itemNumber] = newPropertylistItem
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryRenameTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: PropertyPath(itemNumber), Value: newPropertylistItem})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.RenameTask(incidentID, userID)
+	s.recordChange(userID, "update_property", before, incidentToModify)
 
 	return nil
 }
@@ -987,16 +1251,26 @@ func (s *ServiceImpl) RenameChecklistItem(incidentID, userID string, checklistNu
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	incidentToModify.Checklists[checklistNumber].Items[itemNumber].Title = newTitle
 	incidentToModify.Checklists[checklistNumber].Items[itemNumber].Command = newCommand
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryRenameTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: ChecklistItemPath(checklistNumber, itemNumber), Value: incidentToModify.Checklists[checklistNumber].Items[itemNumber]})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.RenameTask(incidentID, userID)
+	s.recordChange(userID, "rename_checklist_item", before, incidentToModify)
 
 	return nil
 }
@@ -1007,6 +1281,8 @@ func (s *ServiceImpl) MoveChecklistItem(incidentID, userID string, checklistNumb
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	if newLocation >= len(incidentToModify.Checklists[checklistNumber].Items) {
 		return errors.New("invalid targetNumber")
@@ -1023,12 +1299,20 @@ func (s *ServiceImpl) MoveChecklistItem(incidentID, userID string, checklistNumb
 	checklist[newLocation] = itemMoved
 	incidentToModify.Checklists[checklistNumber].Items = checklist
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryMoveTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: ChecklistItemsPath(checklistNumber), Value: incidentToModify.Checklists[checklistNumber].Items})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.MoveTask(incidentID, userID)
+	s.recordChange(userID, "move_checklist_item", before, incidentToModify)
 
 	return nil
 }
@@ -1039,6 +1323,8 @@ func (s *ServiceImpl) MovePropertylistItem(incidentID, userID string, itemNumber
 	if err != nil {
 		return err
 	}
+	before := audit.Snapshot(incidentToModify)
+	previousVersion := incidentToModify.Version
 
 	// Move item
 	propertylist := incidentToModify.Propertylist.Items
@@ -1051,12 +1337,20 @@ func (s *ServiceImpl) MovePropertylistItem(incidentID, userID string, itemNumber
 	propertylist[newLocation] = itemMoved
 	incidentToModify.Propertylist.Items = propertylist
 
-	if err = s.store.UpdateIncident(incidentToModify); err != nil {
+	err = s.commit(&UnitOfWork{
+		Incident: incidentToModify,
+		Outbox: func() ([]*OutboxMessage, error) {
+			var o outbox
+			o.telemetry(telemetryPayload{Event: telemetryMoveTask, IncidentID: incidentID, UserID: userID})
+			o.publishChanges(incidentToModify, previousVersion, nil,
+				Change{Path: PropertylistItemsPath, Value: incidentToModify.Propertylist.Items})
+			return o.result()
+		},
+	})
+	if err != nil {
 		return errors.Wrapf(err, "failed to update incident")
 	}
-
-	s.poster.PublishWebsocketEventToChannel(incidentUpdatedWSEvent, incidentToModify, incidentToModify.ChannelID)
-	s.telemetry.MoveTask(incidentID, userID)
+	s.recordChange(userID, "move_property", before, incidentToModify)
 
 	return nil
 }
@@ -1075,7 +1369,7 @@ func (s *ServiceImpl) GetChecklistAutocomplete(incidentID string) ([]model.Autoc
 			ret = append(ret, model.AutocompleteListItem{
 				Item:     fmt.Sprintf("%d %d", i, j),
 				Hint:     fmt.Sprintf("\"%s\"", stripmd.Strip(item.Title)),
-				HelpText: "Check/uncheck this item",
+				HelpText: fmt.Sprintf("%s, %s", stripmd.Strip(checklist.Title), checklistItemStateName(item.State)),
 			})
 		}
 	}
@@ -1083,6 +1377,39 @@ func (s *ServiceImpl) GetChecklistAutocomplete(incidentID string) ([]model.Autoc
 	return ret, nil
 }
 
+// GetChecklistsAutocomplete returns the list of checklists for incidentID to be used in autocomplete
+func (s *ServiceImpl) GetChecklistsAutocomplete(incidentID string) ([]model.AutocompleteListItem, error) {
+	theIncident, err := s.store.GetIncident(incidentID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve incident")
+	}
+
+	ret := make([]model.AutocompleteListItem, 0)
+
+	for i, checklist := range theIncident.Checklists {
+		ret = append(ret, model.AutocompleteListItem{
+			Item:     strconv.Itoa(i),
+			Hint:     fmt.Sprintf("\"%s\"", stripmd.Strip(checklist.Title)),
+			HelpText: fmt.Sprintf("%d items", len(checklist.Items)),
+		})
+	}
+
+	return ret, nil
+}
+
+func checklistItemStateName(state string) string {
+	switch state {
+	case playbook.ChecklistItemStateInProgress:
+		return "in progress"
+	case playbook.ChecklistItemStateClosed:
+		return "done"
+	case playbook.ChecklistItemStateSkipped:
+		return "skipped"
+	default:
+		return "open"
+	}
+}
+
 // GetPropertylistAutocomplete returns the list of checklist items for incidentID to be used in autocomplete
 func (s *ServiceImpl) GetPropertylistAutocomplete(incidentID string) ([]model.AutocompleteListItem, error) {
 	theIncident, err := s.store.GetIncident(incidentID)
@@ -1096,7 +1423,7 @@ func (s *ServiceImpl) GetPropertylistAutocomplete(incidentID string) ([]model.Au
 		ret = append(ret, model.AutocompleteListItem{
 			Item:     fmt.Sprintf("%s", item.Title),
 			Hint:     fmt.Sprintf("\"%s\"", stripmd.Strip(item.Title)),
-			HelpText: "Check/uncheck this item",
+			HelpText: fmt.Sprintf("Set the value of this %s property", strings.ToLower(item.Type)),
 		})
 	}
 
@@ -1263,8 +1590,12 @@ func (s *ServiceImpl) newIncidentDialog(teamID, commanderID, postID, clientID st
 
 	var options []*model.PostActionOptions
 	for _, playbook := range playbooks {
+		text := playbook.Title
+		if playbook.TeamID != teamID {
+			text += " (shared)"
+		}
 		options = append(options, &model.PostActionOptions{
-			Text:  playbook.Title,
+			Text:  text,
 			Value: playbook.ID,
 		})
 	}
@@ -1321,10 +1652,12 @@ func (s *ServiceImpl) newIncidentDialog(teamID, commanderID, postID, clientID st
 	}, nil
 }
 
-func (s *ServiceImpl) newUpdateIncidentDialog(message, broadcastChannelID, status string, reminderTimer time.Duration) (*model.Dialog, error) {
+func (s *ServiceImpl) newUpdateIncidentDialog(message string, theIncident *Incident) (*model.Dialog, error) {
 	introductionText := "Update your incident status."
+	status := theIncident.CurrentStatus()
+	reminderTimer := theIncident.PreviousReminder
 
-	broadcastChannel, err := s.pluginAPI.Channel.Get(broadcastChannelID)
+	broadcastChannel, err := s.pluginAPI.Channel.Get(theIncident.BroadcastChannelID)
 	if err == nil {
 		if broadcastChannel.Type == model.CHANNEL_OPEN {
 			team, err := s.pluginAPI.Team.Get(broadcastChannel.TeamId)
@@ -1394,38 +1727,54 @@ func (s *ServiceImpl) newUpdateIncidentDialog(message, broadcastChannelID, statu
 		}
 	}
 
+	elements := []model.DialogElement{
+		{
+			DisplayName: "Status",
+			Name:        DialogFieldStatusKey,
+			Type:        "select",
+			Options:     statusOptions,
+			Optional:    false,
+			Default:     status,
+		},
+		{
+			DisplayName: "Message",
+			Name:        DialogFieldMessageKey,
+			Type:        "textarea",
+			Default:     message,
+		},
+		{
+			DisplayName: "Reminder for next update",
+			Name:        DialogFieldReminderInSecondsKey,
+			Type:        "select",
+			Options:     reminderOptions,
+			Optional:    true,
+			Default:     fmt.Sprintf("%d", reminderTimer/time.Second),
+		},
+	}
+
+	if unmet := theIncident.UnmetExitCriteria(); theIncident.IsActive() && len(unmet) > 0 {
+		introductionText += "\n\nBefore resolving this incident:\n* " + strings.Join(unmet, "\n* ")
+		elements = append(elements, model.DialogElement{
+			DisplayName: "Override exit criteria",
+			Name:        DialogFieldOverrideExitCriteriaKey,
+			Type:        "bool",
+			Placeholder: "Resolve without meeting the exit criteria",
+			HelpText:    "Only the commander or a system admin can override the exit criteria. The override is recorded in the timeline.",
+			Optional:    true,
+		})
+	}
+
 	return &model.Dialog{
 		Title:            "Update Incident Status",
 		IntroductionText: introductionText,
-		Elements: []model.DialogElement{
-			{
-				DisplayName: "Status",
-				Name:        DialogFieldStatusKey,
-				Type:        "select",
-				Options:     statusOptions,
-				Optional:    false,
-				Default:     status,
-			},
-			{
-				DisplayName: "Message",
-				Name:        DialogFieldMessageKey,
-				Type:        "textarea",
-				Default:     message,
-			},
-			{
-				DisplayName: "Reminder for next update",
-				Name:        DialogFieldReminderInSecondsKey,
-				Type:        "select",
-				Options:     reminderOptions,
-				Optional:    true,
-				Default:     fmt.Sprintf("%d", reminderTimer/time.Second),
-			},
-		},
-		SubmitLabel:    "Update Status",
-		NotifyOnCancel: false,
+		Elements:         elements,
+		SubmitLabel:      "Update Status",
+		NotifyOnCancel:   false,
 	}, nil
 }
 
+// sendIncidentToClient sends the whole incident to its channel. Changes are sent as a ChangeEvent
+// instead, and clients that miss one get the whole incident back through the API.
 func (s *ServiceImpl) sendIncidentToClient(incidentID string) error {
 	incidentToSend, err := s.store.GetIncident(incidentID)
 	if err != nil {
@@ -1,20 +1,26 @@
 package incident_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/metrics"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
 	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
 	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
 	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
@@ -22,6 +28,22 @@ import (
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
 
+// expectCommit expects a unit of work to be committed and its outbox to be processed, and returns
+// the committed work once the service has committed it.
+func expectCommit(store *mock_incident.MockStore) *incident.UnitOfWork {
+	work := &incident.UnitOfWork{}
+	store.EXPECT().Commit(gomock.Any()).DoAndReturn(func(w *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+		*work = *w
+		if w.Outbox == nil {
+			return nil, nil
+		}
+		return w.Outbox()
+	})
+	store.EXPECT().DeleteOutboxMessages(gomock.Any()).Return(nil)
+
+	return work
+}
+
 func TestCreateIncident(t *testing.T) {
 	t.Run("invalid channel name has only invalid characters", func(t *testing.T) {
 		controller := gomock.NewController(t)
@@ -33,6 +55,8 @@ func TestCreateIncident(t *testing.T) {
 		configService := mock_config.NewMockService(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 
 		teamID := model.NewId()
 		incdnt := &incident.Incident{
@@ -43,7 +67,7 @@ func TestCreateIncident(t *testing.T) {
 		store.EXPECT().CreateIncident(gomock.Any()).Return(incdnt, nil)
 		pluginAPI.On("CreateChannel", mock.Anything).Return(nil, &model.AppError{Id: "model.channel.is_valid.display_name.app_error"})
 
-		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 		_, err := s.CreateIncident(incdnt, "testUserID", true)
 		require.Equal(t, err, incident.ErrChannelDisplayNameInvalid)
@@ -59,6 +83,8 @@ func TestCreateIncident(t *testing.T) {
 		configService := mock_config.NewMockService(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 
 		teamID := model.NewId()
 		incdnt := &incident.Incident{
@@ -69,7 +95,7 @@ func TestCreateIncident(t *testing.T) {
 		store.EXPECT().CreateIncident(gomock.Any()).Return(incdnt, nil)
 		pluginAPI.On("CreateChannel", mock.Anything).Return(nil, &model.AppError{Id: "model.channel.is_valid.2_or_more.app_error"})
 
-		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 		_, err := s.CreateIncident(incdnt, "testUserID", true)
 		require.Equal(t, err, incident.ErrChannelDisplayNameInvalid)
@@ -85,6 +111,8 @@ func TestCreateIncident(t *testing.T) {
 		configService := mock_config.NewMockService(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 
 		teamID := model.NewId()
 		incdnt := &incident.Incident{
@@ -115,7 +143,7 @@ func TestCreateIncident(t *testing.T) {
 		poster.EXPECT().PostMessage("channel_id", "This incident has been started by @%s", "username").
 			Return(&model.Post{Id: "testId"}, nil)
 
-		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 		_, err := s.CreateIncident(incdnt, "user_id", true)
 		require.NoError(t, err)
@@ -131,6 +159,8 @@ func TestCreateIncident(t *testing.T) {
 		configService := mock_config.NewMockService(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 
 		teamID := model.NewId()
 		incdnt := &incident.Incident{
@@ -142,7 +172,7 @@ func TestCreateIncident(t *testing.T) {
 		store.EXPECT().CreateIncident(gomock.Any()).Return(incdnt, nil)
 		pluginAPI.On("CreateChannel", mock.Anything).Return(nil, &model.AppError{Id: "store.sql_channel.save_channel.exists.app_error"})
 
-		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 		_, err := s.CreateIncident(incdnt, "user_id", true)
 		require.EqualError(t, err, "failed to create incident channel: : , ")
@@ -158,6 +188,8 @@ func TestCreateIncident(t *testing.T) {
 		configService := mock_config.NewMockService(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
 
 		teamID := model.NewId()
 		incdnt := &incident.Incident{
@@ -182,7 +214,7 @@ func TestCreateIncident(t *testing.T) {
 		poster.EXPECT().PostMessage("channel_id", "This incident has been started by @%s", "username").
 			Return(&model.Post{Id: "testid"}, nil)
 
-		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 		_, err := s.CreateIncident(incdnt, "user_id", true)
 		require.NoError(t, err)
@@ -305,7 +337,9 @@ func TestOpenCreateIncidentDialog(t *testing.T) {
 			configService := mock_config.NewMockService(controller)
 			telemetryService := &telemetry.NoopTelemetry{}
 			scheduler := mock_incident.NewMockJobOnceScheduler(controller)
-			service := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService)
+			auditService := mock_audit.NewMockService(controller)
+			auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+			service := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
 
 			tt.prepMocks(t, store, poster, api, configService)
 
@@ -317,3 +351,557 @@ func TestOpenCreateIncidentDialog(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchChecklistOperations(t *testing.T) {
+	newIncident := func() *incident.Incident {
+		return &incident.Incident{
+			ID:              "incident_id",
+			ChannelID:       "channel_id",
+			CommanderUserID: "user_id",
+			Checklists: []playbook.Checklist{
+				{
+					Title: "Checklist",
+					Items: []playbook.ChecklistItem{
+						{Title: "Item 1", State: playbook.ChecklistItemStateOpen},
+						{Title: "Item 2", State: playbook.ChecklistItemStateOpen, AssigneeID: "other_id"},
+						{Title: "Item 3", State: playbook.ChecklistItemStateClosed},
+					},
+				},
+			},
+		}
+	}
+
+	setup := func(t *testing.T) (incident.Service, *mock_incident.MockStore, *mock_bot.MockPoster, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		pluginAPI.On("GetUser", "other_id").Return(&model.User{Id: "other_id", Username: "other"}, nil)
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
+
+		return s, store, poster, pluginAPI
+	}
+
+	t.Run("applies all operations with one message, one update and one websocket event", func(t *testing.T) {
+		s, store, poster, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+		poster.EXPECT().PostMessage("channel_id", "username made the following checklist changes:\n"+
+			"* checked off checklist item **Item 1**\n"+
+			"* checked off checklist item **Item 2**\n"+
+			"* changed assignee of checklist item **Item 3** from **No Assignee** to **@other**\n"+
+			"* added checklist item **Item 4**\n"+
+			"* removed checklist item **Item 1**").
+			Return(&model.Post{Id: "post_id"}, nil)
+
+		work := expectCommit(store)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			}).Times(1)
+
+		err := s.BatchChecklistOperations("incident_id", "user_id", []incident.ChecklistOperation{
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 0, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 1, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 2, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationSetAssignee, ChecklistNumber: 0, ItemNumber: 2, AssigneeID: "other_id"},
+			{Type: incident.ChecklistOperationAdd, ChecklistNumber: 0, ChecklistItem: &playbook.ChecklistItem{Title: " Item 4 "}},
+			{Type: incident.ChecklistOperationRemove, ChecklistNumber: 0, ItemNumber: 0},
+		})
+		require.NoError(t, err)
+
+		updated, events := work.Incident, work.TimelineEvents
+		require.NotNil(t, updated)
+		items := updated.Checklists[0].Items
+		require.Len(t, items, 3)
+		assert.Equal(t, "Item 2", items[0].Title)
+		assert.Equal(t, playbook.ChecklistItemStateClosed, items[0].State)
+		assert.Equal(t, "post_id", items[0].StateModifiedPostID)
+		assert.Equal(t, "other_id", items[1].AssigneeID)
+		assert.Equal(t, "post_id", items[1].AssigneeModifiedPostID)
+		assert.Equal(t, "Item 4", items[2].Title)
+
+		require.Len(t, events, 2)
+		assert.Equal(t, incident.TaskStateModified, events[0].EventType)
+		assert.Equal(t, "modified the state of 2 checklist items", events[0].Summary)
+		assert.Equal(t, "checked off checklist item **Item 1**\nchecked off checklist item **Item 2**", events[0].Details)
+		assert.Equal(t, "post_id", events[0].PostID)
+		assert.Equal(t, incident.AssigneeChanged, events[1].EventType)
+		assert.Equal(t, "changed assignee of checklist item **Item 3** from **No Assignee** to **@other**", events[1].Summary)
+
+		// Items were added and removed, so the whole checklist is sent.
+		require.Len(t, changeEvent.Changes, 1)
+		assert.Equal(t, "checklists[0].items", changeEvent.Changes[0].Path)
+		assert.Len(t, changeEvent.TimelineEvents, 2)
+	})
+
+	t.Run("sends only the items changed in place", func(t *testing.T) {
+		s, store, poster, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+		poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "post_id"}, nil)
+		expectCommit(store)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		err := s.BatchChecklistOperations("incident_id", "user_id", []incident.ChecklistOperation{
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 0, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationSetAssignee, ChecklistNumber: 0, ItemNumber: 2, AssigneeID: "other_id"},
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 2, NewState: playbook.ChecklistItemStateOpen},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, changeEvent.Changes, 2)
+		assert.Equal(t, "checklists[0].items[0]", changeEvent.Changes[0].Path)
+		assert.Equal(t, "checklists[0].items[2]", changeEvent.Changes[1].Path)
+		assert.Equal(t, "other_id", changeEvent.Changes[1].Value.(map[string]interface{})["assignee_id"])
+	})
+
+	t.Run("invalid operation applies nothing", func(t *testing.T) {
+		s, store, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.BatchChecklistOperations("incident_id", "user_id", []incident.ChecklistOperation{
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 0, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationMove, ChecklistNumber: 0, ItemNumber: 0, NewLocation: 3},
+		})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, incident.ErrInvalidChecklistOperation))
+	})
+
+	t.Run("no-op operations post nothing", func(t *testing.T) {
+		s, store, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.BatchChecklistOperations("incident_id", "user_id", []incident.ChecklistOperation{
+			{Type: incident.ChecklistOperationSetState, ChecklistNumber: 0, ItemNumber: 2, NewState: playbook.ChecklistItemStateClosed},
+			{Type: incident.ChecklistOperationSetAssignee, ChecklistNumber: 0, ItemNumber: 1, AssigneeID: "other_id"},
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestChangePropertyValues(t *testing.T) {
+	min, max := 0.0, 100.0
+	newIncident := func() *incident.Incident {
+		return &incident.Incident{
+			ID:              "incident_id",
+			ChannelID:       "channel_id",
+			CommanderUserID: "user_id",
+			Propertylist: playbook.Propertylist{
+				Items: []playbook.PropertylistItem{
+					{ID: "impact", Title: "Impact", Type: playbook.PropertyTypeNumber, Number: &playbook.NumberOption{Unit: "%", Min: &min, Max: &max}},
+					{ID: "platforms", Title: "Platforms", Type: playbook.PropertyTypeSelection, Selection: playbook.Selectionlist{
+						IsMultiselect: true,
+						Items:         []playbook.SelectionlistItem{{ID: "1", Value: "Web"}, {ID: "2", Value: "Mobile"}},
+					}},
+				},
+			},
+		}
+	}
+
+	setup := func(t *testing.T) (incident.Service, *mock_incident.MockStore, *mock_bot.MockPoster) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
+
+		return s, store, poster
+	}
+
+	t.Run("sets a number within range", func(t *testing.T) {
+		s, store, poster := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil).Times(2)
+		poster.EXPECT().PostMessage("channel_id", "username changed the incident property '**Impact**' from **** to **42 %**.").
+			Return(&model.Post{Id: "post_id"}, nil)
+		work := expectCommit(store)
+		var changeEvent incident.ChangeEvent
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), &changeEvent))
+			})
+
+		err := s.ChangePropertyValues("incident_id", "user_id", "impact", []string{"42"})
+		require.NoError(t, err)
+		updated := work.Incident
+		require.Equal(t, 42.0, *updated.Propertylist.Items[0].Number.Value)
+		require.Len(t, changeEvent.Changes, 1)
+		require.Equal(t, "propertylist.items[0]", changeEvent.Changes[0].Path)
+		expected, err := json.Marshal(updated.Propertylist.Items[0])
+		require.NoError(t, err)
+		actual, err := json.Marshal(changeEvent.Changes[0].Value)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(actual))
+		require.Len(t, changeEvent.TimelineEvents, 1)
+		require.Equal(t, incident.PropertyValueChanged, changeEvent.TimelineEvents[0].EventType)
+	})
+
+	t.Run("stores multiselect values as a list", func(t *testing.T) {
+		s, store, poster := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil).Times(2)
+		poster.EXPECT().PostMessage("channel_id", "username changed the incident property '**Platforms**' from **** to **Web, Mobile**.").
+			Return(&model.Post{Id: "post_id"}, nil)
+		work := expectCommit(store)
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+
+		err := s.ChangePropertyValues("incident_id", "user_id", "platforms", []string{"1", "2"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"1", "2"}, work.Incident.Propertylist.Items[1].Selection.SelectedIDs)
+	})
+
+	t.Run("number out of range modifies nothing", func(t *testing.T) {
+		s, store, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.ChangePropertyValues("incident_id", "user_id", "impact", []string{"101"})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, playbook.ErrInvalidPropertyValue))
+	})
+
+	t.Run("unknown property", func(t *testing.T) {
+		s, store, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.ChangePropertyValues("incident_id", "user_id", "unknown", []string{"1"})
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateStatusExitCriteria(t *testing.T) {
+	newIncident := func() *incident.Incident {
+		return &incident.Incident{
+			ID:              "incident_id",
+			ChannelID:       "channel_id",
+			CommanderUserID: "user_id",
+			Checklists: []playbook.Checklist{
+				{Title: "Checklist", Items: []playbook.ChecklistItem{{Title: "Write postmortem"}}},
+			},
+			Propertylist: playbook.Propertylist{
+				Items: []playbook.PropertylistItem{{Title: "Root cause", Type: playbook.PropertyTypeFreetext}},
+			},
+			ExitCriteria: playbook.ExitCriteria{
+				RequiredProperties:     []string{"Root cause"},
+				RequiredChecklistItems: []playbook.ChecklistItemReference{{Checklist: "Checklist", Item: "Write postmortem"}},
+			},
+		}
+	}
+
+	setup := func(t *testing.T) (incident.Service, *mock_incident.MockStore, *mock_bot.MockPoster, *mock_incident.MockJobOnceScheduler, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		pluginAPI.On("HasPermissionTo", "other_id", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, telemetryService, auditService, metrics.New())
+
+		return s, store, poster, scheduler, pluginAPI
+	}
+
+	t.Run("unmet criteria prevent resolving", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.UpdateStatus("incident_id", "user_id", incident.StatusUpdateOptions{Status: incident.StatusResolved})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, incident.ErrExitCriteriaNotMet))
+
+		var exitCriteriaErr *incident.ExitCriteriaError
+		require.True(t, errors.As(err, &exitCriteriaErr))
+		require.Equal(t, []string{
+			"property 'Root cause' must be filled in",
+			"checklist item 'Write postmortem' in 'Checklist' must be checked off",
+		}, exitCriteriaErr.Unmet)
+	})
+
+	t.Run("only the commander or an admin can override", func(t *testing.T) {
+		s, store, _, _, _ := setup(t)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil)
+
+		err := s.UpdateStatus("incident_id", "other_id", incident.StatusUpdateOptions{
+			Status:               incident.StatusResolved,
+			OverrideExitCriteria: true,
+		})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, incident.ErrPermission))
+	})
+
+	t.Run("commander override is recorded", func(t *testing.T) {
+		s, store, poster, scheduler, pluginAPI := setup(t)
+
+		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "status_post_id"}, nil)
+
+		store.EXPECT().GetIncident("incident_id").Return(newIncident(), nil).Times(3)
+		scheduler.EXPECT().Cancel("incident_id")
+		poster.EXPECT().PostMessage("channel_id", "username changed the status to **Resolved** without meeting the exit criteria:\n"+
+			"* property 'Root cause' must be filled in\n"+
+			"* checklist item 'Write postmortem' in 'Checklist' must be checked off").
+			Return(&model.Post{Id: "override_post_id"}, nil)
+		work := expectCommit(store)
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+
+		err := s.UpdateStatus("incident_id", "user_id", incident.StatusUpdateOptions{
+			Status:               incident.StatusResolved,
+			OverrideExitCriteria: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, incident.StatusResolved, work.StatusPost.Status)
+		require.Equal(t, "status_post_id", work.StatusPost.PostID)
+		events := work.TimelineEvents
+		require.Len(t, events, 2)
+		require.Equal(t, incident.StatusUpdated, events[0].EventType)
+		require.Equal(t, incident.ExitCriteriaOverridden, events[1].EventType)
+		require.Equal(t, "override_post_id", events[1].PostID)
+	})
+}
+
+func TestGetIncidentsTeamInfo(t *testing.T) {
+	setup := func(t *testing.T) (*plugintest.API, *mock_incident.MockStore, *incident.ServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, auditService, metrics.New())
+		return pluginAPI, store, s
+	}
+
+	team1 := &model.Team{Id: model.NewId(), Name: "team-1", DisplayName: "Team 1"}
+	team2 := &model.Team{Id: model.NewId(), Name: "team-2", DisplayName: "Team 2"}
+	requesterInfo := incident.RequesterInfo{UserID: "testUserID"}
+
+	t.Run("all teams", func(t *testing.T) {
+		pluginAPI, store, s := setup(t)
+
+		store.EXPECT().
+			GetIncidents(requesterInfo, incident.FilterOptions{}).
+			Return(&incident.GetIncidentsResults{Items: []incident.Incident{
+				{ID: "1", TeamID: team1.Id},
+				{ID: "2", TeamID: team2.Id},
+				{ID: "3", TeamID: team1.Id},
+			}}, nil)
+		pluginAPI.On("GetTeam", team1.Id).Return(team1, nil).Once()
+		pluginAPI.On("GetTeam", team2.Id).Return(team2, nil).Once()
+
+		results, err := s.GetIncidents(requesterInfo, incident.FilterOptions{})
+		require.NoError(t, err)
+		require.Equal(t, &incident.TeamInfo{ID: team1.Id, Name: "team-1", DisplayName: "Team 1"}, results.Items[0].Team)
+		require.Equal(t, &incident.TeamInfo{ID: team2.Id, Name: "team-2", DisplayName: "Team 2"}, results.Items[1].Team)
+		require.Equal(t, results.Items[0].Team, results.Items[2].Team)
+		pluginAPI.AssertExpectations(t)
+	})
+
+	t.Run("single team", func(t *testing.T) {
+		_, store, s := setup(t)
+
+		options := incident.FilterOptions{TeamID: team1.Id}
+		store.EXPECT().
+			GetIncidents(requesterInfo, options).
+			Return(&incident.GetIncidentsResults{Items: []incident.Incident{{ID: "1", TeamID: team1.Id}}}, nil)
+
+		results, err := s.GetIncidents(requesterInfo, options)
+		require.NoError(t, err)
+		require.Nil(t, results.Items[0].Team)
+	})
+}
+
+func TestAuditRecords(t *testing.T) {
+	controller := gomock.NewController(t)
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI)
+	store := mock_incident.NewMockStore(controller)
+	poster := mock_bot.NewMockPoster(controller)
+	logger := mock_bot.NewMockLogger(controller)
+	configService := mock_config.NewMockService(controller)
+	scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+	auditService := mock_audit.NewMockService(controller)
+
+	pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+	store.EXPECT().GetIncident("incident_id").Return(&incident.Incident{
+		ID:        "incident_id",
+		TeamID:    "team_id",
+		ChannelID: "channel_id",
+		Checklists: []playbook.Checklist{
+			{Title: "Checklist", Items: []playbook.ChecklistItem{{Title: "Item 1"}}},
+		},
+	}, nil)
+	expectCommit(store)
+	poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+
+	var record audit.Record
+	auditService.EXPECT().Record(gomock.Any()).Do(func(r audit.Record) { record = r })
+
+	s := incident.NewService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, auditService, metrics.New()).
+		WithAuditSource(audit.SourceSlashCommand)
+
+	err := s.AddChecklistItem("incident_id", "user_id", 0, playbook.ChecklistItem{Title: "Item 2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user_id", record.ActorUserID)
+	assert.Equal(t, audit.SourceSlashCommand, record.Source)
+	assert.Equal(t, audit.TargetIncident, record.TargetType)
+	assert.Equal(t, "incident_id", record.TargetID)
+	assert.Equal(t, "team_id", record.TeamID)
+	assert.Equal(t, "add_checklist_item", record.Action)
+	require.Len(t, record.Changes, 1)
+	assert.Equal(t, "checklists[0].items", record.Changes[0].Field)
+	assert.Contains(t, string(record.Changes[0].New), `"title":"Item 2"`)
+}
+
+func TestChecklistChanges(t *testing.T) {
+	setup := func(t *testing.T) (incident.Service, *incident.ChangeEvent) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI)
+		store := mock_incident.NewMockStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+		auditService := mock_audit.NewMockService(controller)
+		auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		store.EXPECT().GetIncident("incident_id").Return(&incident.Incident{
+			ID:        "incident_id",
+			ChannelID: "channel_id",
+			Version:   3,
+			Checklists: []playbook.Checklist{
+				{Title: "Checklist", Items: []playbook.ChecklistItem{{Title: "Item 1"}, {Title: "Item 2"}}},
+			},
+		}, nil)
+		store.EXPECT().Commit(gomock.Any()).DoAndReturn(func(w *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+			w.Incident.Version++
+			return w.Outbox()
+		})
+		store.EXPECT().DeleteOutboxMessages(gomock.Any()).Return(nil)
+
+		changeEvent := &incident.ChangeEvent{}
+		poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id").
+			Do(func(_ string, payload interface{}, _ string) {
+				require.NoError(t, json.Unmarshal(payload.(json.RawMessage), changeEvent))
+			})
+
+		s := incident.NewService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, auditService, metrics.New())
+
+		return s, changeEvent
+	}
+
+	t.Run("renaming sends the item", func(t *testing.T) {
+		s, changeEvent := setup(t)
+
+		require.NoError(t, s.RenameChecklistItem("incident_id", "user_id", 0, 1, "Renamed", ""))
+		assert.Equal(t, int64(3), changeEvent.PreviousVersion)
+		assert.Equal(t, int64(4), changeEvent.Version)
+		require.Len(t, changeEvent.Changes, 1)
+		assert.Equal(t, "checklists[0].items[1]", changeEvent.Changes[0].Path)
+		assert.Equal(t, "Renamed", changeEvent.Changes[0].Value.(map[string]interface{})["title"])
+	})
+
+	t.Run("moving sends the items of the checklist", func(t *testing.T) {
+		s, changeEvent := setup(t)
+
+		require.NoError(t, s.MoveChecklistItem("incident_id", "user_id", 0, 1, 0))
+		require.Len(t, changeEvent.Changes, 1)
+		assert.Equal(t, "checklists[0].items", changeEvent.Changes[0].Path)
+		items := changeEvent.Changes[0].Value.([]interface{})
+		require.Len(t, items, 2)
+		assert.Equal(t, "Item 2", items[0].(map[string]interface{})["title"])
+	})
+}
+
+type countingMetrics struct {
+	remindersScheduled int
+	remindersFired     int
+	broadcastFailures  int
+}
+
+func (m *countingMetrics) IncrementRemindersScheduled() { m.remindersScheduled++ }
+func (m *countingMetrics) IncrementRemindersFired()     { m.remindersFired++ }
+func (m *countingMetrics) IncrementBroadcastFailures()  { m.broadcastFailures++ }
+
+func TestReminderMetrics(t *testing.T) {
+	controller := gomock.NewController(t)
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI)
+	store := mock_incident.NewMockStore(controller)
+	poster := mock_bot.NewMockPoster(controller)
+	logger := mock_bot.NewMockLogger(controller)
+	configService := mock_config.NewMockService(controller)
+	scheduler := mock_incident.NewMockJobOnceScheduler(controller)
+	auditService := mock_audit.NewMockService(controller)
+	auditService.EXPECT().Record(gomock.Any()).AnyTimes()
+	counts := &countingMetrics{}
+
+	s := incident.NewService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, auditService, counts)
+
+	scheduler.EXPECT().ScheduleOnce("incident_id", gomock.Any()).Return(nil, nil)
+	require.NoError(t, s.SetReminder("incident_id", time.Minute))
+
+	scheduler.EXPECT().ScheduleOnce("incident_id", gomock.Any()).Return(nil, errors.New("no cluster"))
+	require.Error(t, s.SetReminder("incident_id", time.Minute))
+
+	theIncident := &incident.Incident{ID: "incident_id", ChannelID: "channel_id", CommanderUserID: "user_id"}
+	store.EXPECT().GetIncident("incident_id").Return(theIncident, nil)
+	pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+	configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "pluginid"}).AnyTimes()
+	configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: "bot_user_id"})
+	poster.EXPECT().PostMessageWithAttachments("channel_id", gomock.Any(), gomock.Any(), "username").
+		Return(&model.Post{Id: "reminder_post_id"}, nil)
+	store.EXPECT().UpdateIncident(gomock.Any()).Return(nil)
+	poster.EXPECT().PublishWebsocketEventToChannel("incident_changed", gomock.Any(), "channel_id")
+
+	s.HandleReminder("incident_id")
+
+	require.Equal(t, &countingMetrics{remindersScheduled: 1, remindersFired: 1}, counts)
+}
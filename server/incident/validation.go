@@ -0,0 +1,92 @@
+package incident
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+)
+
+// ErrMalformedStatusUpdate is used to indicate a status update is not valid.
+var ErrMalformedStatusUpdate = errors.New("malformed status update")
+
+// Statuses lists the statuses of an incident, in the order they are usually reached.
+var Statuses = []string{StatusReported, StatusActive, StatusResolved, StatusArchived}
+
+// ParseStatus returns the status matching the given name, ignoring case.
+func ParseStatus(name string) (string, error) {
+	for _, status := range Statuses {
+		if strings.EqualFold(status, strings.TrimSpace(name)) {
+			return status, nil
+		}
+	}
+
+	return "", errors.Wrapf(ErrMalformedStatusUpdate, "invalid status '%s': it should be one of %s", name, strings.Join(Statuses, ", "))
+}
+
+// ValidateNewIncident checks the fields of an incident about to be created. The errors wrap
+// ErrMalformedIncident.
+func ValidateNewIncident(newIncident Incident) error {
+	if newIncident.ID != "" {
+		return errors.Wrap(ErrMalformedIncident, "incident already has an id")
+	}
+
+	if newIncident.ChannelID != "" {
+		return errors.Wrap(ErrMalformedIncident, "incident channel already has an id")
+	}
+
+	if newIncident.CreateAt != 0 {
+		return errors.Wrap(ErrMalformedIncident, "incident channel already has created at date")
+	}
+
+	if newIncident.TeamID == "" {
+		return errors.Wrap(ErrMalformedIncident, "missing team id of incident")
+	}
+
+	if newIncident.CommanderUserID == "" {
+		return errors.Wrap(ErrMalformedIncident, "missing commander user id of incident")
+	}
+
+	if strings.TrimSpace(newIncident.Name) == "" {
+		return errors.Wrap(ErrMalformedIncident, "missing name of incident")
+	}
+
+	return nil
+}
+
+// ApplyPlaybook copies the checklists, properties, exit criteria and reminder settings of the
+// playbook an incident is started from.
+func (i *Incident) ApplyPlaybook(pb playbook.Playbook) {
+	i.PlaybookID = pb.ID
+	i.PlaybookVersion = pb.Version
+
+	i.Checklists = pb.Checklists
+	i.Propertylist = pb.Propertylist
+	i.ExitCriteria = pb.ExitCriteria
+
+	i.BroadcastChannelID = pb.BroadcastChannelID
+	i.ReminderMessageTemplate = pb.ReminderMessageTemplate
+	i.PreviousReminder = time.Duration(pb.ReminderTimerDefaultSeconds) * time.Second
+}
+
+// ValidateStatusUpdateOptions checks the options of a status update and normalizes the case of
+// its status. The errors wrap ErrMalformedStatusUpdate.
+func ValidateStatusUpdateOptions(options *StatusUpdateOptions) error {
+	status, err := ParseStatus(options.Status)
+	if err != nil {
+		return err
+	}
+	options.Status = status
+
+	if strings.TrimSpace(options.Message) == "" {
+		return errors.Wrap(ErrMalformedStatusUpdate, "missing message of the status update")
+	}
+
+	if options.Reminder < 0 {
+		return errors.Wrap(ErrMalformedStatusUpdate, "the reminder must not be negative")
+	}
+
+	return nil
+}
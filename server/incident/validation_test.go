@@ -0,0 +1,73 @@
+package incident
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+)
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus(" resolved ")
+	require.NoError(t, err)
+	require.Equal(t, StatusResolved, status)
+
+	_, err = ParseStatus("closed")
+	require.True(t, errors.Is(err, ErrMalformedStatusUpdate))
+}
+
+func TestValidateNewIncident(t *testing.T) {
+	valid := Incident{Name: "Outage", TeamID: "team", CommanderUserID: "commander"}
+	require.NoError(t, ValidateNewIncident(valid))
+
+	for name, modify := range map[string]func(i *Incident){
+		"id":        func(i *Incident) { i.ID = "id" },
+		"channel":   func(i *Incident) { i.ChannelID = "channel" },
+		"create at": func(i *Incident) { i.CreateAt = 1 },
+		"team":      func(i *Incident) { i.TeamID = "" },
+		"commander": func(i *Incident) { i.CommanderUserID = "" },
+		"name":      func(i *Incident) { i.Name = "  " },
+	} {
+		t.Run(name, func(t *testing.T) {
+			invalid := valid
+			modify(&invalid)
+			require.True(t, errors.Is(ValidateNewIncident(invalid), ErrMalformedIncident))
+		})
+	}
+}
+
+func TestApplyPlaybook(t *testing.T) {
+	var i Incident
+	i.ApplyPlaybook(playbook.Playbook{
+		ID:                          "playbook",
+		Version:                     3,
+		Checklists:                  []playbook.Checklist{{Title: "Triage"}},
+		BroadcastChannelID:          "broadcast",
+		ReminderTimerDefaultSeconds: 60,
+	})
+
+	require.Equal(t, "playbook", i.PlaybookID)
+	require.Equal(t, int64(3), i.PlaybookVersion)
+	require.Equal(t, "Triage", i.Checklists[0].Title)
+	require.Equal(t, "broadcast", i.BroadcastChannelID)
+	require.Equal(t, time.Minute, i.PreviousReminder)
+}
+
+func TestValidateStatusUpdateOptions(t *testing.T) {
+	options := StatusUpdateOptions{Status: "active", Message: "Investigating", Reminder: 30 * time.Minute}
+	require.NoError(t, ValidateStatusUpdateOptions(&options))
+	require.Equal(t, StatusActive, options.Status)
+
+	for name, options := range map[string]StatusUpdateOptions{
+		"status":   {Status: "closed", Message: "Investigating"},
+		"message":  {Status: StatusActive, Message: " "},
+		"reminder": {Status: StatusActive, Message: "Investigating", Reminder: -time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.True(t, errors.Is(ValidateStatusUpdateOptions(&options), ErrMalformedStatusUpdate))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package metrics collects the metrics of the plugin and writes them in the Prometheus text
+// exposition format, for operators to scrape.
+package metrics
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// namespace prefixes the name of every metric of the plugin.
+const namespace = "incident_collaboration_"
+
+// durationBuckets are the upper bounds, in seconds, of the buckets of the duration histograms.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics holds the metrics of the plugin. It is safe for concurrent use.
+type Metrics struct {
+	apiRequests        *counterVec
+	apiRequestDuration *histogramVec
+	storeQueryDuration *histogramVec
+	remindersScheduled *counterVec
+	remindersFired     *counterVec
+	broadcastFailures  *counterVec
+
+	funcs *funcRegistry
+}
+
+// New creates the metrics of the plugin, all starting from zero.
+func New() *Metrics {
+	return &Metrics{
+		apiRequests: newCounterVec(namespace+"api_requests_total",
+			"Number of requests to the REST API, by route, method and status code.",
+			"route", "method", "status"),
+		apiRequestDuration: newHistogramVec(namespace+"api_request_duration_seconds",
+			"Time taken to serve requests to the REST API, by route and method.",
+			durationBuckets, "route", "method"),
+		storeQueryDuration: newHistogramVec(namespace+"store_query_duration_seconds",
+			"Time taken by the queries to the database, by kind of statement and table.",
+			durationBuckets, "query"),
+		remindersScheduled: newCounterVec(namespace+"reminders_scheduled_total",
+			"Number of status update reminders scheduled."),
+		remindersFired: newCounterVec(namespace+"reminders_fired_total",
+			"Number of status update reminders posted to incident channels."),
+		broadcastFailures: newCounterVec(namespace+"broadcast_failures_total",
+			"Number of failed attempts to broadcast a status update to the broadcast channel. Failed broadcasts are retried."),
+		funcs: &funcRegistry{},
+	}
+}
+
+// ObserveAPIRequest records a request to the REST API. The route is the path template the
+// request matched, not its path, to keep the number of series bounded.
+func (m *Metrics) ObserveAPIRequest(route, method string, status int, elapsed time.Duration) {
+	m.apiRequests.add(1, route, method, strconv.Itoa(status))
+	m.apiRequestDuration.observe(elapsed.Seconds(), route, method)
+}
+
+// ObserveStoreQuery records the duration of a query to the database.
+func (m *Metrics) ObserveStoreQuery(query string, elapsed time.Duration) {
+	m.storeQueryDuration.observe(elapsed.Seconds(), query)
+}
+
+// IncrementRemindersScheduled records that a status update reminder was scheduled.
+func (m *Metrics) IncrementRemindersScheduled() {
+	m.remindersScheduled.add(1)
+}
+
+// IncrementRemindersFired records that a status update reminder was posted.
+func (m *Metrics) IncrementRemindersFired() {
+	m.remindersFired.add(1)
+}
+
+// IncrementBroadcastFailures records that an attempt to broadcast a status update failed.
+func (m *Metrics) IncrementBroadcastFailures() {
+	m.broadcastFailures.add(1)
+}
+
+// AddGaugeFunc adds a gauge whose values are read from fn on every scrape, with one series per
+// key of the returned map, the key being the value of label. An empty label gives a single
+// series, read from the empty key. name is prefixed with the namespace of the plugin.
+func (m *Metrics) AddGaugeFunc(name, help, label string, fn func() (map[string]float64, error)) {
+	m.funcs.add(funcMetric{namespace + name, help, "gauge", label, fn})
+}
+
+// AddCounterFunc adds a counter whose values are read from fn on every scrape, the same way as
+// AddGaugeFunc.
+func (m *Metrics) AddCounterFunc(name, help, label string, fn func() (map[string]float64, error)) {
+	m.funcs.add(funcMetric{namespace + name, help, "counter", label, fn})
+}
+
+// Write writes every metric to w in the Prometheus text exposition format. The metrics read from
+// a function that fails are left out, and the first such error is returned once all the others
+// are written.
+func (m *Metrics) Write(w io.Writer) error {
+	var text strings.Builder
+
+	m.apiRequests.write(&text)
+	m.apiRequestDuration.write(&text)
+	m.storeQueryDuration.write(&text)
+	m.remindersScheduled.write(&text)
+	m.remindersFired.write(&text)
+	m.broadcastFailures.write(&text)
+	funcErr := m.funcs.write(&text)
+
+	if _, err := io.WriteString(w, text.String()); err != nil {
+		return errors.Wrap(err, "failed to write metrics")
+	}
+
+	return funcErr
+}
@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleRegexp = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{.*\})? (\S+)$`)
+var labelRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)",?`)
+
+// parse reads the text exposition format, returning the values of the samples keyed by their
+// name and labels as written, and the types of the metric families keyed by their name.
+func parse(t *testing.T, text string) (samples map[string]float64, types map[string]string) {
+	t.Helper()
+
+	samples = make(map[string]float64)
+	types = make(map[string]string)
+	helps := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "# HELP ") {
+			fields := strings.SplitN(strings.TrimPrefix(line, "# HELP "), " ", 2)
+			require.Len(t, fields, 2, line)
+			require.False(t, helps[fields[0]], "HELP repeated for %s", fields[0])
+			helps[fields[0]] = true
+			continue
+		}
+
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(strings.TrimPrefix(line, "# TYPE "))
+			require.Len(t, fields, 2, line)
+			require.Contains(t, []string{"counter", "gauge", "histogram"}, fields[1])
+			require.NotContains(t, types, fields[0], "TYPE repeated for %s", fields[0])
+			types[fields[0]] = fields[1]
+			continue
+		}
+
+		match := sampleRegexp.FindStringSubmatch(line)
+		require.NotNil(t, match, "malformed sample %q", line)
+
+		family := match[1]
+		if types[family] == "" {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				if types[strings.TrimSuffix(family, suffix)] == "histogram" {
+					family = strings.TrimSuffix(family, suffix)
+				}
+			}
+		}
+		require.Contains(t, types, family, "sample %q before the TYPE of its metric", line)
+
+		labels := strings.TrimSuffix(strings.TrimPrefix(match[2], "{"), "}")
+		for labels != "" {
+			label := labelRegexp.FindString(labels)
+			require.NotEmpty(t, label, "malformed labels in %q", line)
+			labels = labels[len(label):]
+		}
+
+		value, err := strconv.ParseFloat(match[3], 64)
+		require.NoError(t, err, line)
+		require.NotContains(t, samples, match[1]+match[2], "sample %q repeated", line)
+		samples[match[1]+match[2]] = value
+	}
+	require.NoError(t, scanner.Err())
+
+	return samples, types
+}
+
+func write(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	var text strings.Builder
+	require.NoError(t, m.Write(&text))
+
+	return text.String()
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("counters without labels start at zero", func(t *testing.T) {
+		samples, types := parse(t, write(t, New()))
+
+		require.Equal(t, "counter", types["incident_collaboration_reminders_scheduled_total"])
+		require.Equal(t, float64(0), samples["incident_collaboration_reminders_scheduled_total"])
+		require.Contains(t, samples, "incident_collaboration_reminders_fired_total")
+		require.Contains(t, samples, "incident_collaboration_broadcast_failures_total")
+		require.Equal(t, "histogram", types["incident_collaboration_api_request_duration_seconds"])
+	})
+
+	t.Run("records api requests by route, method and status", func(t *testing.T) {
+		m := New()
+		m.ObserveAPIRequest("/api/v0/incidents/{id}", "GET", 200, 20*time.Millisecond)
+		m.ObserveAPIRequest("/api/v0/incidents/{id}", "GET", 200, 2*time.Second)
+		m.ObserveAPIRequest("/api/v0/incidents/{id}", "GET", 404, time.Millisecond)
+		m.ObserveAPIRequest("/api/v0/incidents", "POST", 201, time.Millisecond)
+
+		samples, _ := parse(t, write(t, m))
+
+		require.Equal(t, float64(2), samples[`incident_collaboration_api_requests_total{route="/api/v0/incidents/{id}",method="GET",status="200"}`])
+		require.Equal(t, float64(1), samples[`incident_collaboration_api_requests_total{route="/api/v0/incidents/{id}",method="GET",status="404"}`])
+		require.Equal(t, float64(1), samples[`incident_collaboration_api_requests_total{route="/api/v0/incidents",method="POST",status="201"}`])
+
+		prefix := `incident_collaboration_api_request_duration_seconds`
+		labels := `route="/api/v0/incidents/{id}",method="GET"`
+		require.Equal(t, float64(1), samples[prefix+`_bucket{`+labels+`,le="0.005"}`])
+		require.Equal(t, float64(2), samples[prefix+`_bucket{`+labels+`,le="0.025"}`])
+		require.Equal(t, float64(3), samples[prefix+`_bucket{`+labels+`,le="2.5"}`])
+		require.Equal(t, float64(3), samples[prefix+`_bucket{`+labels+`,le="+Inf"}`])
+		require.Equal(t, float64(3), samples[prefix+`_count{`+labels+`}`])
+		require.InDelta(t, 2.021, samples[prefix+`_sum{`+labels+`}`], 1e-9)
+	})
+
+	t.Run("records store queries, reminders and broadcast failures", func(t *testing.T) {
+		m := New()
+		m.ObserveStoreQuery("select IR_Incident", 10*time.Millisecond)
+		m.IncrementRemindersScheduled()
+		m.IncrementRemindersScheduled()
+		m.IncrementRemindersFired()
+		m.IncrementBroadcastFailures()
+
+		samples, _ := parse(t, write(t, m))
+
+		require.Equal(t, float64(1), samples[`incident_collaboration_store_query_duration_seconds_count{query="select IR_Incident"}`])
+		require.Equal(t, float64(2), samples["incident_collaboration_reminders_scheduled_total"])
+		require.Equal(t, float64(1), samples["incident_collaboration_reminders_fired_total"])
+		require.Equal(t, float64(1), samples["incident_collaboration_broadcast_failures_total"])
+	})
+
+	t.Run("reads func metrics on every scrape", func(t *testing.T) {
+		m := New()
+		counts := map[string]float64{"Active": 3, "Resolved": 1}
+		m.AddGaugeFunc("incidents", "Incidents by status.", "status", func() (map[string]float64, error) {
+			return counts, nil
+		})
+		queueSize := 0.0
+		m.AddGaugeFunc("telemetry_queue_size", "Queued events.", "", func() (map[string]float64, error) {
+			return map[string]float64{"": queueSize}, nil
+		})
+		m.AddCounterFunc("cache_hits_total", "Cache hits.", "", func() (map[string]float64, error) {
+			return map[string]float64{"": 7}, nil
+		})
+
+		samples, types := parse(t, write(t, m))
+		require.Equal(t, "gauge", types["incident_collaboration_incidents"])
+		require.Equal(t, float64(3), samples[`incident_collaboration_incidents{status="Active"}`])
+		require.Equal(t, float64(1), samples[`incident_collaboration_incidents{status="Resolved"}`])
+		require.Equal(t, float64(0), samples["incident_collaboration_telemetry_queue_size"])
+		require.Equal(t, "counter", types["incident_collaboration_cache_hits_total"])
+		require.Equal(t, float64(7), samples["incident_collaboration_cache_hits_total"])
+
+		counts["Active"] = 2
+		queueSize = 5
+
+		samples, _ = parse(t, write(t, m))
+		require.Equal(t, float64(2), samples[`incident_collaboration_incidents{status="Active"}`])
+		require.Equal(t, float64(5), samples["incident_collaboration_telemetry_queue_size"])
+	})
+
+	t.Run("leaves out the func metrics that fail", func(t *testing.T) {
+		m := New()
+		m.AddGaugeFunc("broken", "Always fails.", "", func() (map[string]float64, error) {
+			return nil, errors.New("no database")
+		})
+		m.AddGaugeFunc("working", "Always works.", "", func() (map[string]float64, error) {
+			return map[string]float64{"": 1}, nil
+		})
+
+		var text strings.Builder
+		err := m.Write(&text)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no database")
+
+		samples, types := parse(t, text.String())
+		require.NotContains(t, types, "incident_collaboration_broken")
+		require.Equal(t, float64(1), samples["incident_collaboration_working"])
+	})
+
+	t.Run("escapes label values", func(t *testing.T) {
+		m := New()
+		m.ObserveStoreQuery("select \"quoted\" \\ table\n", time.Millisecond)
+
+		text := write(t, m)
+		samples, _ := parse(t, text)
+
+		require.Contains(t, text, `query="select \"quoted\" \\ table\n"`)
+		require.Equal(t, float64(1), samples[`incident_collaboration_store_query_duration_seconds_count{query="select \"quoted\" \\ table\n"}`])
+	})
+}
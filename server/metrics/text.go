@@ -0,0 +1,251 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// labelSeparator joins the values of the labels of a series into the key of its map. It cannot
+// appear in valid UTF-8 text.
+const labelSeparator = "\xff"
+
+// counterVec is a counter with one series per combination of values of its labels.
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	lock   sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+	}
+}
+
+// add adds delta to the series with the given label values, in the order of the labels.
+func (c *counterVec) add(delta float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.values[key] += delta
+}
+
+func (c *counterVec) write(w *strings.Builder) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+
+	// A counter without labels always has its single series, even before it is incremented.
+	if len(c.labels) == 0 {
+		writeSample(w, c.name, nil, nil, c.values[""])
+		return
+	}
+
+	for _, key := range sortedKeys(c.values) {
+		writeSample(w, c.name, c.labels, splitKey(key), c.values[key])
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// histogramVec is a histogram with one series per combination of values of its labels.
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	lock   sync.Mutex
+	values map[string]*histogram
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		values:  make(map[string]*histogram),
+	}
+}
+
+// observe adds value to the series with the given label values, in the order of the labels.
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	series, ok := h.values[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = series
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			series.counts[i]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+func (h *histogramVec) write(w *strings.Builder) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+
+	bucketLabels := append(append([]string{}, h.labels...), "le")
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		series := h.values[key]
+		labelValues := splitKey(key)
+		if len(h.labels) == 0 {
+			labelValues = nil
+		}
+
+		for i, upperBound := range h.buckets {
+			writeSample(w, h.name+"_bucket", bucketLabels,
+				append(labelValues, formatFloat(upperBound)), float64(series.counts[i]))
+		}
+		writeSample(w, h.name+"_bucket", bucketLabels,
+			append(labelValues, "+Inf"), float64(series.count))
+		writeSample(w, h.name+"_sum", h.labels, labelValues, series.sum)
+		writeSample(w, h.name+"_count", h.labels, labelValues, float64(series.count))
+	}
+}
+
+// funcMetric is a metric whose values are read from a function on every scrape.
+type funcMetric struct {
+	name   string
+	help   string
+	typ    string
+	label  string
+	values func() (map[string]float64, error)
+}
+
+type funcRegistry struct {
+	lock    sync.Mutex
+	metrics []funcMetric
+}
+
+func (r *funcRegistry) add(metric funcMetric) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.metrics = append(r.metrics, metric)
+}
+
+func (r *funcRegistry) write(w *strings.Builder) error {
+	r.lock.Lock()
+	metrics := append([]funcMetric{}, r.metrics...)
+	r.lock.Unlock()
+
+	var firstErr error
+	for _, metric := range metrics {
+		values, err := metric.values()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "failed to read metric %s", metric.name)
+			}
+			continue
+		}
+
+		writeHeader(w, metric.name, metric.help, metric.typ)
+		if metric.label == "" {
+			writeSample(w, metric.name, nil, nil, values[""])
+			continue
+		}
+
+		for _, key := range sortedKeys(values) {
+			writeSample(w, metric.name, []string{metric.label}, []string{key}, values[key])
+		}
+	}
+
+	return firstErr
+}
+
+func writeHeader(w *strings.Builder, name, help, typ string) {
+	w.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeSample(w *strings.Builder, name string, labels, labelValues []string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label + `="` + escapeLabelValue(labelValues[i]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, labelSeparator)
+}
+
+// sortedKeys returns the keys of m in order, for the output to be stable between scrapes.
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,152 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall (interfaces: Service)
+
+// Package mock_oncall is a generated GoMock package.
+package mock_oncall
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	oncall "github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	reflect "reflect"
+	time "time"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AddOverride mocks base method
+func (m *MockService) AddOverride(arg0 oncall.Override) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOverride", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOverride indicates an expected call of AddOverride
+func (mr *MockServiceMockRecorder) AddOverride(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOverride", reflect.TypeOf((*MockService)(nil).AddOverride), arg0)
+}
+
+// CreateSchedule mocks base method
+func (m *MockService) CreateSchedule(arg0 oncall.Schedule) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule
+func (mr *MockServiceMockRecorder) CreateSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockService)(nil).CreateSchedule), arg0)
+}
+
+// DeleteSchedule mocks base method
+func (m *MockService) DeleteSchedule(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule
+func (mr *MockServiceMockRecorder) DeleteSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockService)(nil).DeleteSchedule), arg0)
+}
+
+// GetSchedule mocks base method
+func (m *MockService) GetSchedule(arg0 string) (oncall.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", arg0)
+	ret0, _ := ret[0].(oncall.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule
+func (mr *MockServiceMockRecorder) GetSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockService)(nil).GetSchedule), arg0)
+}
+
+// GetSchedulesForTeam mocks base method
+func (m *MockService) GetSchedulesForTeam(arg0 string) ([]oncall.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedulesForTeam", arg0)
+	ret0, _ := ret[0].([]oncall.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedulesForTeam indicates an expected call of GetSchedulesForTeam
+func (mr *MockServiceMockRecorder) GetSchedulesForTeam(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulesForTeam", reflect.TypeOf((*MockService)(nil).GetSchedulesForTeam), arg0)
+}
+
+// OnCall mocks base method
+func (m *MockService) OnCall(arg0 string, arg1 time.Time) (oncall.OnCall, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OnCall", arg0, arg1)
+	ret0, _ := ret[0].(oncall.OnCall)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OnCall indicates an expected call of OnCall
+func (mr *MockServiceMockRecorder) OnCall(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCall", reflect.TypeOf((*MockService)(nil).OnCall), arg0, arg1)
+}
+
+// RemoveOverride mocks base method
+func (m *MockService) RemoveOverride(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOverride", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOverride indicates an expected call of RemoveOverride
+func (mr *MockServiceMockRecorder) RemoveOverride(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOverride", reflect.TypeOf((*MockService)(nil).RemoveOverride), arg0, arg1)
+}
+
+// UpdateSchedule mocks base method
+func (m *MockService) UpdateSchedule(arg0 oncall.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule
+func (mr *MockServiceMockRecorder) UpdateSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockService)(nil).UpdateSchedule), arg0)
+}
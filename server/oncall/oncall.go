@@ -0,0 +1,132 @@
+package oncall
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound used to indicate entity not found.
+var ErrNotFound = errors.New("not found")
+
+// ErrMalformedSchedule is used to indicate a schedule or an override is not valid.
+var ErrMalformedSchedule = errors.New("malformed schedule")
+
+// Schedule is the on-call rotation of a team. The layers are stacked: whoever is on call in a
+// layer takes precedence over the layers before it, and the overrides take precedence over all
+// the layers.
+type Schedule struct {
+	ID     string `json:"id"`
+	TeamID string `json:"team_id"`
+	Name   string `json:"name"`
+
+	// TimeZone is the IANA name of the time zone of the handoffs, e.g. Europe/Paris.
+	TimeZone string  `json:"time_zone"`
+	Layers   []Layer `json:"layers"`
+
+	// Overrides are the current and upcoming overrides. They are stored separately and can't be
+	// changed by updating the schedule.
+	Overrides []Override `json:"overrides"`
+
+	CreateAt int64 `json:"create_at"`
+	UpdateAt int64 `json:"update_at"`
+	DeleteAt int64 `json:"delete_at"`
+}
+
+// Layer is a rotation of users, each one on call for ShiftDays days in turn.
+type Layer struct {
+	Name    string   `json:"name"`
+	UserIDs []string `json:"user_ids"`
+
+	// Start is the date of the first shift, YYYY-MM-DD, and HandoffTime the time of day the
+	// shifts begin, HH:MM, both in the time zone of the schedule.
+	Start       string `json:"start"`
+	HandoffTime string `json:"handoff_time"`
+
+	// ShiftDays is the length of a shift in days, e.g. 7 for a weekly rotation.
+	ShiftDays int `json:"shift_days"`
+
+	// ActiveFrom and ActiveTo restrict the layer to a daily window, HH:MM in the time zone of the
+	// schedule, e.g. for business hours. The window may span midnight. The layer is active all
+	// day when they are empty.
+	ActiveFrom string `json:"active_from,omitempty"`
+	ActiveTo   string `json:"active_to,omitempty"`
+}
+
+// Override puts a user on call from StartAt to EndAt, in milliseconds, whatever the layers say.
+type Override struct {
+	ID            string `json:"id"`
+	ScheduleID    string `json:"schedule_id"`
+	UserID        string `json:"user_id"`
+	StartAt       int64  `json:"start_at"`
+	EndAt         int64  `json:"end_at"`
+	CreatorUserID string `json:"creator_user_id"`
+	CreateAt      int64  `json:"create_at"`
+}
+
+// OnCall tells who is on call at some point in time.
+type OnCall struct {
+	// UserID is empty if nobody is on call.
+	UserID string `json:"user_id"`
+
+	// Layer is the name of the layer the user is on call in, and OverrideID the override that
+	// put them on call instead, if any.
+	Layer      string `json:"layer,omitempty"`
+	OverrideID string `json:"override_id,omitempty"`
+
+	// Until is the time of the next handoff in milliseconds, 0 if there is none.
+	Until int64 `json:"until"`
+}
+
+// Service is the on-call service for managing schedules and finding who is on call.
+type Service interface {
+	// CreateSchedule creates a new schedule and returns its ID.
+	CreateSchedule(schedule Schedule) (string, error)
+
+	// GetSchedule retrieves a schedule. Returns ErrNotFound if not found.
+	GetSchedule(id string) (Schedule, error)
+
+	// GetSchedulesForTeam retrieves the schedules of a team, sorted by name.
+	GetSchedulesForTeam(teamID string) ([]Schedule, error)
+
+	// UpdateSchedule updates the name, time zone and layers of a schedule.
+	UpdateSchedule(schedule Schedule) error
+
+	// DeleteSchedule deletes a schedule.
+	DeleteSchedule(id string) error
+
+	// AddOverride adds an override to a schedule and returns its ID.
+	AddOverride(override Override) (string, error)
+
+	// RemoveOverride removes an override from a schedule.
+	RemoveOverride(scheduleID, overrideID string) error
+
+	// OnCall returns who is on call in a schedule at the given time.
+	OnCall(scheduleID string, at time.Time) (OnCall, error)
+}
+
+// Store is an interface for storing schedules.
+type Store interface {
+	// CreateSchedule creates a new schedule and returns its ID.
+	CreateSchedule(schedule Schedule) (string, error)
+
+	// GetSchedule retrieves a schedule, with the overrides that haven't ended yet. Returns
+	// ErrNotFound if not found or deleted.
+	GetSchedule(id string) (Schedule, error)
+
+	// GetSchedulesForTeam retrieves the schedules of a team that are not deleted, sorted by name,
+	// with the overrides that haven't ended yet.
+	GetSchedulesForTeam(teamID string) ([]Schedule, error)
+
+	// UpdateSchedule updates a schedule, leaving its overrides aside.
+	UpdateSchedule(schedule Schedule) error
+
+	// DeleteSchedule marks a schedule as deleted.
+	DeleteSchedule(id string) error
+
+	// CreateOverride creates a new override and returns its ID.
+	CreateOverride(override Override) (string, error)
+
+	// DeleteOverride deletes an override of a schedule. Returns ErrNotFound if not found.
+	DeleteOverride(scheduleID, overrideID string) error
+}
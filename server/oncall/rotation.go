@@ -0,0 +1,240 @@
+package oncall
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+// Validate checks a schedule. The errors wrap ErrMalformedSchedule.
+func (s Schedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.Wrap(ErrMalformedSchedule, "missing name of schedule")
+	}
+	if s.TeamID == "" {
+		return errors.Wrap(ErrMalformedSchedule, "missing team id of schedule")
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil || s.TimeZone == "" {
+		return errors.Wrapf(ErrMalformedSchedule, "unknown time zone '%s'", s.TimeZone)
+	}
+
+	for i, layer := range s.Layers {
+		if err := layer.validate(); err != nil {
+			return errors.Wrapf(err, "layer %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (l Layer) validate() error {
+	if len(l.UserIDs) == 0 {
+		return errors.Wrap(ErrMalformedSchedule, "a layer needs at least one user")
+	}
+	if _, err := time.Parse(dateLayout, l.Start); err != nil {
+		return errors.Wrapf(ErrMalformedSchedule, "invalid start date '%s', expected YYYY-MM-DD", l.Start)
+	}
+	if _, err := time.Parse(timeLayout, l.HandoffTime); err != nil {
+		return errors.Wrapf(ErrMalformedSchedule, "invalid handoff time '%s', expected HH:MM", l.HandoffTime)
+	}
+	if l.ShiftDays < 1 {
+		return errors.Wrap(ErrMalformedSchedule, "shifts must last at least one day")
+	}
+
+	if (l.ActiveFrom == "") != (l.ActiveTo == "") {
+		return errors.Wrap(ErrMalformedSchedule, "the active window needs both a start and an end")
+	}
+	if l.ActiveFrom != "" {
+		from, err := time.Parse(timeLayout, l.ActiveFrom)
+		if err != nil {
+			return errors.Wrapf(ErrMalformedSchedule, "invalid active window start '%s', expected HH:MM", l.ActiveFrom)
+		}
+		to, err := time.Parse(timeLayout, l.ActiveTo)
+		if err != nil {
+			return errors.Wrapf(ErrMalformedSchedule, "invalid active window end '%s', expected HH:MM", l.ActiveTo)
+		}
+		if from.Equal(to) {
+			return errors.Wrap(ErrMalformedSchedule, "the active window must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// Validate checks an override. The errors wrap ErrMalformedSchedule.
+func (o Override) Validate() error {
+	if o.UserID == "" {
+		return errors.Wrap(ErrMalformedSchedule, "missing user id of override")
+	}
+	if o.EndAt <= o.StartAt {
+		return errors.Wrap(ErrMalformedSchedule, "an override must end after it starts")
+	}
+	return nil
+}
+
+// OnCallAt returns who is on call at the given time: the user of the most recently created
+// override covering it, or else the user on call in the last layer that has someone.
+func (s Schedule) OnCallAt(at time.Time) (OnCall, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return OnCall{}, errors.Wrapf(err, "failed to load time zone '%s'", s.TimeZone)
+	}
+
+	result := OnCall{Until: s.nextChange(at, loc)}
+
+	millis := at.UnixNano() / int64(time.Millisecond)
+	var override *Override
+	for i := range s.Overrides {
+		o := &s.Overrides[i]
+		if o.StartAt <= millis && millis < o.EndAt && (override == nil || o.CreateAt >= override.CreateAt) {
+			override = o
+		}
+	}
+	if override != nil {
+		result.UserID = override.UserID
+		result.OverrideID = override.ID
+		return result, nil
+	}
+
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		if userID := s.Layers[i].onCallAt(at, loc); userID != "" {
+			result.UserID = userID
+			result.Layer = s.Layers[i].Name
+			return result, nil
+		}
+	}
+
+	return result, nil
+}
+
+// nextChange returns the time of the next handoff, window or override boundary after at, in
+// milliseconds, or 0 if there is none. The same user may stay on call past it.
+func (s Schedule) nextChange(at time.Time, loc *time.Location) int64 {
+	var next time.Time
+	consider := func(t time.Time) {
+		if t.After(at) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	for _, layer := range s.Layers {
+		first, err := layer.firstHandoff(loc)
+		if err != nil {
+			continue
+		}
+		if at.Before(first) {
+			consider(first)
+		} else {
+			shift := layer.shiftAt(at, first)
+			consider(first.AddDate(0, 0, (shift+1)*layer.ShiftDays))
+		}
+
+		if layer.ActiveFrom != "" {
+			local := at.In(loc)
+			for _, clock := range []string{layer.ActiveFrom, layer.ActiveTo} {
+				t, _ := time.Parse(timeLayout, clock)
+				today := time.Date(local.Year(), local.Month(), local.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+				consider(today)
+				consider(today.AddDate(0, 0, 1))
+			}
+		}
+	}
+
+	for _, o := range s.Overrides {
+		consider(time.Unix(0, o.StartAt*int64(time.Millisecond)))
+		consider(time.Unix(0, o.EndAt*int64(time.Millisecond)))
+	}
+
+	if next.IsZero() {
+		return 0
+	}
+	return next.UnixNano() / int64(time.Millisecond)
+}
+
+// firstHandoff returns the beginning of the first shift of the layer.
+func (l Layer) firstHandoff(loc *time.Location) (time.Time, error) {
+	date, err := time.Parse(dateLayout, l.Start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	clock, err := time.Parse(timeLayout, l.HandoffTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
+
+// shiftAt returns the number of the shift at the given time, counting from 0 for the one
+// beginning at first. Days are counted on the calendar so that handoffs stay at the same local
+// time across daylight saving time changes.
+func (l Layer) shiftAt(at, first time.Time) int {
+	local := at.In(first.Location())
+	days := calendarDays(first, local)
+	if days > 0 && clockBefore(local, first) {
+		days--
+	}
+	return days / l.ShiftDays
+}
+
+// onCallAt returns the user on call in the layer at the given time, or an empty string if the
+// layer hasn't started yet or is outside of its active window.
+func (l Layer) onCallAt(at time.Time, loc *time.Location) string {
+	if len(l.UserIDs) == 0 || l.ShiftDays < 1 {
+		return ""
+	}
+
+	first, err := l.firstHandoff(loc)
+	if err != nil || at.Before(first) {
+		return ""
+	}
+
+	if l.ActiveFrom != "" && !l.activeAt(at.In(loc)) {
+		return ""
+	}
+
+	return l.UserIDs[l.shiftAt(at, first)%len(l.UserIDs)]
+}
+
+// activeAt returns true if the local time is within the active window of the layer.
+func (l Layer) activeAt(local time.Time) bool {
+	from, err := time.Parse(timeLayout, l.ActiveFrom)
+	if err != nil {
+		return false
+	}
+	to, err := time.Parse(timeLayout, l.ActiveTo)
+	if err != nil {
+		return false
+	}
+
+	minutes := local.Hour()*60 + local.Minute()
+	fromMinutes := from.Hour()*60 + from.Minute()
+	toMinutes := to.Hour()*60 + to.Minute()
+	if fromMinutes < toMinutes {
+		return fromMinutes <= minutes && minutes < toMinutes
+	}
+	return minutes >= fromMinutes || minutes < toMinutes
+}
+
+// calendarDays returns the number of calendar days from the date of a to the date of b.
+func calendarDays(a, b time.Time) int {
+	dateA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dateB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dateB.Sub(dateA).Hours() / 24)
+}
+
+// clockBefore returns true if the time of day of a is before the time of day of b.
+func clockBefore(a, b time.Time) bool {
+	if a.Hour() != b.Hour() {
+		return a.Hour() < b.Hour()
+	}
+	if a.Minute() != b.Minute() {
+		return a.Minute() < b.Minute()
+	}
+	return a.Second() < b.Second()
+}
@@ -0,0 +1,174 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func TestScheduleValidate(t *testing.T) {
+	valid := Schedule{
+		TeamID:   "teamID",
+		Name:     "Primary",
+		TimeZone: "Europe/Paris",
+		Layers: []Layer{{
+			UserIDs:     []string{"user1"},
+			Start:       "2020-11-02",
+			HandoffTime: "09:00",
+			ShiftDays:   7,
+		}},
+	}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(s *Schedule)
+	}{
+		{"missing name", func(s *Schedule) { s.Name = " " }},
+		{"missing team", func(s *Schedule) { s.TeamID = "" }},
+		{"missing time zone", func(s *Schedule) { s.TimeZone = "" }},
+		{"unknown time zone", func(s *Schedule) { s.TimeZone = "Mars/Olympus" }},
+		{"no users", func(s *Schedule) { s.Layers[0].UserIDs = nil }},
+		{"bad start", func(s *Schedule) { s.Layers[0].Start = "02/11/2020" }},
+		{"bad handoff", func(s *Schedule) { s.Layers[0].HandoffTime = "9am" }},
+		{"no shift length", func(s *Schedule) { s.Layers[0].ShiftDays = 0 }},
+		{"half window", func(s *Schedule) { s.Layers[0].ActiveFrom = "09:00" }},
+		{"empty window", func(s *Schedule) { s.Layers[0].ActiveFrom, s.Layers[0].ActiveTo = "09:00", "09:00" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := valid
+			schedule.Layers = []Layer{valid.Layers[0]}
+			tt.modify(&schedule)
+			assert.True(t, errors.Is(schedule.Validate(), ErrMalformedSchedule))
+		})
+	}
+}
+
+func TestOnCallAt(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	weekly := Layer{
+		Name:        "Weekly",
+		UserIDs:     []string{"user1", "user2", "user3"},
+		Start:       "2020-11-02",
+		HandoffTime: "09:00",
+		ShiftDays:   7,
+	}
+	businessHours := Layer{
+		Name:        "Business hours",
+		UserIDs:     []string{"day1", "day2"},
+		Start:       "2020-11-02",
+		HandoffTime: "09:00",
+		ShiftDays:   1,
+		ActiveFrom:  "09:00",
+		ActiveTo:    "18:00",
+	}
+
+	t.Run("before the start", func(t *testing.T) {
+		schedule := Schedule{TimeZone: "Europe/Paris", Layers: []Layer{weekly}}
+		onCall, err := schedule.OnCallAt(time.Date(2020, 11, 1, 12, 0, 0, 0, paris))
+		require.NoError(t, err)
+		assert.Equal(t, "", onCall.UserID)
+		assert.Equal(t, millis(time.Date(2020, 11, 2, 9, 0, 0, 0, paris)), onCall.Until)
+	})
+
+	t.Run("weekly rotation", func(t *testing.T) {
+		schedule := Schedule{TimeZone: "Europe/Paris", Layers: []Layer{weekly}}
+		tests := []struct {
+			at       time.Time
+			expected string
+		}{
+			{time.Date(2020, 11, 2, 9, 0, 0, 0, paris), "user1"},
+			{time.Date(2020, 11, 9, 8, 59, 0, 0, paris), "user1"},
+			{time.Date(2020, 11, 9, 9, 0, 0, 0, paris), "user2"},
+			{time.Date(2020, 11, 16, 10, 0, 0, 0, paris), "user3"},
+			{time.Date(2020, 11, 23, 10, 0, 0, 0, paris), "user1"},
+		}
+		for _, tt := range tests {
+			onCall, err := schedule.OnCallAt(tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, onCall.UserID, tt.at.String())
+			assert.Equal(t, "Weekly", onCall.Layer)
+		}
+	})
+
+	t.Run("handoff stays at the same local time across daylight saving time", func(t *testing.T) {
+		daily := weekly
+		daily.Start = "2021-03-27"
+		daily.ShiftDays = 1
+		schedule := Schedule{TimeZone: "Europe/Paris", Layers: []Layer{daily}}
+
+		// Clocks go forward on 2021-03-28, that day is 23 hours long.
+		onCall, err := schedule.OnCallAt(time.Date(2021, 3, 29, 8, 30, 0, 0, paris))
+		require.NoError(t, err)
+		assert.Equal(t, "user2", onCall.UserID)
+		assert.Equal(t, millis(time.Date(2021, 3, 29, 9, 0, 0, 0, paris)), onCall.Until)
+
+		onCall, err = schedule.OnCallAt(time.Date(2021, 3, 29, 9, 0, 0, 0, paris))
+		require.NoError(t, err)
+		assert.Equal(t, "user3", onCall.UserID)
+	})
+
+	t.Run("upper layers take precedence within their window", func(t *testing.T) {
+		schedule := Schedule{TimeZone: "Europe/Paris", Layers: []Layer{weekly, businessHours}}
+
+		onCall, err := schedule.OnCallAt(time.Date(2020, 11, 3, 10, 0, 0, 0, paris))
+		require.NoError(t, err)
+		assert.Equal(t, "day2", onCall.UserID)
+		assert.Equal(t, "Business hours", onCall.Layer)
+		assert.Equal(t, millis(time.Date(2020, 11, 3, 18, 0, 0, 0, paris)), onCall.Until)
+
+		onCall, err = schedule.OnCallAt(time.Date(2020, 11, 3, 20, 0, 0, 0, paris))
+		require.NoError(t, err)
+		assert.Equal(t, "user1", onCall.UserID)
+		assert.Equal(t, "Weekly", onCall.Layer)
+	})
+
+	t.Run("window spanning midnight", func(t *testing.T) {
+		nights := businessHours
+		nights.ActiveFrom, nights.ActiveTo = "22:00", "06:00"
+		schedule := Schedule{TimeZone: "Europe/Paris", Layers: []Layer{nights}}
+
+		for hour, expected := range map[int]string{23: "day2", 3: "day1", 12: ""} {
+			onCall, err := schedule.OnCallAt(time.Date(2020, 11, 3, hour, 0, 0, 0, paris))
+			require.NoError(t, err)
+			assert.Equal(t, expected, onCall.UserID, hour)
+		}
+	})
+
+	t.Run("the newest override wins", func(t *testing.T) {
+		start := time.Date(2020, 11, 3, 10, 0, 0, 0, paris)
+		schedule := Schedule{
+			TimeZone: "Europe/Paris",
+			Layers:   []Layer{weekly},
+			Overrides: []Override{
+				{ID: "o1", UserID: "sub1", StartAt: millis(start), EndAt: millis(start.Add(4 * time.Hour)), CreateAt: 1},
+				{ID: "o2", UserID: "sub2", StartAt: millis(start.Add(time.Hour)), EndAt: millis(start.Add(2 * time.Hour)), CreateAt: 2},
+			},
+		}
+
+		onCall, err := schedule.OnCallAt(start.Add(30 * time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, "sub1", onCall.UserID)
+		assert.Equal(t, "o1", onCall.OverrideID)
+		assert.Equal(t, millis(start.Add(time.Hour)), onCall.Until)
+
+		onCall, err = schedule.OnCallAt(start.Add(90 * time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, "sub2", onCall.UserID)
+
+		onCall, err = schedule.OnCallAt(start.Add(5 * time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "user1", onCall.UserID)
+		assert.Equal(t, "", onCall.OverrideID)
+	})
+}
@@ -0,0 +1,113 @@
+package oncall
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+type service struct {
+	store Store
+}
+
+// NewService returns a new on-call service.
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+func (s *service) CreateSchedule(schedule Schedule) (string, error) {
+	if schedule.ID != "" {
+		return "", errors.Wrap(ErrMalformedSchedule, "schedule already has an id")
+	}
+	if err := schedule.Validate(); err != nil {
+		return "", err
+	}
+
+	schedule.CreateAt = model.GetMillis()
+	schedule.UpdateAt = schedule.CreateAt
+	schedule.Overrides = nil
+
+	return s.store.CreateSchedule(schedule)
+}
+
+func (s *service) GetSchedule(id string) (Schedule, error) {
+	return s.store.GetSchedule(id)
+}
+
+func (s *service) GetSchedulesForTeam(teamID string) ([]Schedule, error) {
+	return s.store.GetSchedulesForTeam(teamID)
+}
+
+func (s *service) UpdateSchedule(schedule Schedule) error {
+	oldSchedule, err := s.store.GetSchedule(schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	// A schedule stays in its team.
+	schedule.TeamID = oldSchedule.TeamID
+	if err = schedule.Validate(); err != nil {
+		return err
+	}
+
+	schedule.CreateAt = oldSchedule.CreateAt
+	schedule.UpdateAt = model.GetMillis()
+
+	return s.store.UpdateSchedule(schedule)
+}
+
+func (s *service) DeleteSchedule(id string) error {
+	return s.store.DeleteSchedule(id)
+}
+
+func (s *service) AddOverride(override Override) (string, error) {
+	if override.ID != "" {
+		return "", errors.Wrap(ErrMalformedSchedule, "override already has an id")
+	}
+	if err := override.Validate(); err != nil {
+		return "", err
+	}
+	if _, err := s.store.GetSchedule(override.ScheduleID); err != nil {
+		return "", err
+	}
+
+	override.CreateAt = model.GetMillis()
+
+	return s.store.CreateOverride(override)
+}
+
+func (s *service) RemoveOverride(scheduleID, overrideID string) error {
+	return s.store.DeleteOverride(scheduleID, overrideID)
+}
+
+func (s *service) OnCall(scheduleID string, at time.Time) (OnCall, error) {
+	schedule, err := s.store.GetSchedule(scheduleID)
+	if err != nil {
+		return OnCall{}, err
+	}
+
+	return schedule.OnCallAt(at)
+}
+
+// DefaultCommander returns the user on call in the schedule at the given time, or fallbackUserID
+// if there is no schedule, nobody is on call in it, or the user on call can't take command as
+// told by canCommand, e.g. because they aren't in the team of the incident. fallbackUserID is
+// also returned with the error if the schedule can't be read.
+func DefaultCommander(service Service, scheduleID string, at time.Time, fallbackUserID string, canCommand func(userID string) bool) (string, error) {
+	if scheduleID == "" {
+		return fallbackUserID, nil
+	}
+
+	onCall, err := service.OnCall(scheduleID, at)
+	if err != nil {
+		return fallbackUserID, errors.Wrapf(err, "failed to find who is on call in schedule '%s'", scheduleID)
+	}
+	if onCall.UserID == "" || !canCommand(onCall.UserID) {
+		return fallbackUserID, nil
+	}
+
+	return onCall.UserID, nil
+}
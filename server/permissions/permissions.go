@@ -5,7 +5,9 @@ import (
 
 	"github.com/mattermost/mattermost-server/v5/model"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
@@ -98,3 +100,46 @@ func MemberOfChannelID(userID, channelID string, pluginAPI *pluginapi.Client) bo
 func CanPostToChannel(userID, channelID string, pluginAPI *pluginapi.Client) bool {
 	return pluginAPI.User.HasPermissionToChannel(userID, channelID, model.PERMISSION_CREATE_POST)
 }
+
+// StartIncident returns nil if userID can start newIncident, from thePlaybook if not nil: the
+// commander must be in the team, the user must be able to run the playbook (system admins can
+// run any) and to create the incident channel, and must be a member of the channel of the
+// original post, if any.
+func StartIncident(userID string, newIncident incident.Incident, thePlaybook *playbook.Playbook, pluginAPI *pluginapi.Client, log bot.Logger) error {
+	if !CanViewTeam(newIncident.CommanderUserID, newIncident.TeamID, pluginAPI) {
+		return errors.Wrap(incident.ErrPermission, "commander user does not have permissions for the team")
+	}
+
+	public := true
+	if thePlaybook != nil {
+		canRun := playbook.RoleOfUser(*thePlaybook, userID, pluginAPI, log).AtLeast(playbook.RoleRunner) ||
+			thePlaybook.IsSharedWith(newIncident.TeamID) ||
+			IsAdmin(userID, pluginAPI)
+		if !canRun {
+			return errors.Wrap(incident.ErrPermission, "userID cannot start incidents from the playbook")
+		}
+		public = thePlaybook.CreatePublicIncident
+	}
+
+	permission := model.PERMISSION_CREATE_PRIVATE_CHANNEL
+	permissionMessage := "You are not able to create a private channel"
+	if public {
+		permission = model.PERMISSION_CREATE_PUBLIC_CHANNEL
+		permissionMessage = "You are not able to create a public channel"
+	}
+	if !pluginAPI.User.HasPermissionToTeam(userID, newIncident.TeamID, permission) {
+		return errors.Wrap(incident.ErrPermission, permissionMessage)
+	}
+
+	if newIncident.PostID != "" {
+		post, err := pluginAPI.Post.GetPost(newIncident.PostID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get incident original post")
+		}
+		if !MemberOfChannelID(userID, post.ChannelId, pluginAPI) {
+			return errors.New("user is not a member of the channel containing the incident's original post")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package playbook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeType is the kind of a Change.
+type ChangeType string
+
+// The kinds of changes between two versions of a playbook.
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// The parts of a playbook a Change applies to.
+const (
+	ChangeTargetChecklist     = "checklist"
+	ChangeTargetChecklistItem = "checklist_item"
+	ChangeTargetProperty      = "property"
+	ChangeTargetExitCriteria  = "exit_criteria"
+)
+
+// Change is a difference between two versions of the checklists, properties or exit criteria of
+// a playbook. Checklists, checklist items and properties are matched by title, ignoring case, so
+// a renamed one is removed and added again.
+type Change struct {
+	Type   ChangeType `json:"type"`
+	Target string     `json:"target"`
+
+	// Checklist is the title of the checklist of a checklist item.
+	Checklist string `json:"checklist,omitempty"`
+
+	// Title is the title of the checklist, checklist item or property, or a description of the
+	// exit criterion.
+	Title string `json:"title"`
+
+	// Details describe what was modified.
+	Details []string `json:"details,omitempty"`
+}
+
+// Diff returns the changes to the checklists, properties and exit criteria going from the old to
+// the new version of a playbook.
+func Diff(oldPlaybook, newPlaybook Playbook) []Change {
+	var changes []Change
+	changes = append(changes, diffChecklists(oldPlaybook.Checklists, newPlaybook.Checklists)...)
+	changes = append(changes, diffPropertylists(oldPlaybook.Propertylist, newPlaybook.Propertylist)...)
+	changes = append(changes, diffExitCriteria(oldPlaybook.ExitCriteria, newPlaybook.ExitCriteria)...)
+	return changes
+}
+
+// StripChecklists returns a copy of the checklists of an incident without their states,
+// assignees and run timestamps, ready to be used in a playbook.
+func StripChecklists(checklists []Checklist) []Checklist {
+	var stripped []Checklist
+	for _, c := range checklists {
+		c = c.Clone()
+		for i, item := range c.Items {
+			c.Items[i] = ChecklistItem{
+				ID:          item.ID,
+				Title:       item.Title,
+				State:       ChecklistItemStateOpen,
+				Command:     item.Command,
+				Description: item.Description,
+			}
+		}
+		stripped = append(stripped, c)
+	}
+	return stripped
+}
+
+// StripPropertylist returns a copy of the propertylist of an incident without the values of its
+// properties, ready to be used in a playbook.
+func StripPropertylist(propertylist Propertylist) Propertylist {
+	stripped := propertylist.Clone()
+	for i := range stripped.Items {
+		item := &stripped.Items[i]
+		item.Selection.SelectedIDs = nil
+		item.Treetext.Value = ""
+		item.Treetext.Values = nil
+		if item.Number != nil {
+			item.Number.Value = nil
+		}
+		if item.Datetime != nil {
+			item.Datetime.Value = 0
+		}
+		if item.User != nil {
+			item.User.UserIDs = nil
+		}
+		if item.Channel != nil {
+			item.Channel.ChannelID = ""
+		}
+		if item.URL != nil {
+			item.URL.Value = ""
+		}
+	}
+	return stripped
+}
+
+func sameTitle(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+func diffChecklists(oldChecklists, newChecklists []Checklist) []Change {
+	var changes []Change
+
+	for _, newChecklist := range newChecklists {
+		oldChecklist := findChecklist(oldChecklists, newChecklist.Title)
+		if oldChecklist == nil {
+			changes = append(changes, Change{
+				Type:    ChangeAdded,
+				Target:  ChangeTargetChecklist,
+				Title:   newChecklist.Title,
+				Details: []string{pluralize(len(newChecklist.Items), "item")},
+			})
+			continue
+		}
+
+		for _, newItem := range newChecklist.Items {
+			oldItem := findItem(oldChecklist.Items, newItem.Title)
+			if oldItem == nil {
+				changes = append(changes, Change{
+					Type:      ChangeAdded,
+					Target:    ChangeTargetChecklistItem,
+					Checklist: newChecklist.Title,
+					Title:     newItem.Title,
+				})
+				continue
+			}
+
+			var details []string
+			if oldItem.Command != newItem.Command {
+				details = append(details, fmt.Sprintf("command changed from '%s' to '%s'", oldItem.Command, newItem.Command))
+			}
+			if oldItem.Description != newItem.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				changes = append(changes, Change{
+					Type:      ChangeModified,
+					Target:    ChangeTargetChecklistItem,
+					Checklist: newChecklist.Title,
+					Title:     newItem.Title,
+					Details:   details,
+				})
+			}
+		}
+
+		for _, oldItem := range oldChecklist.Items {
+			if findItem(newChecklist.Items, oldItem.Title) == nil {
+				changes = append(changes, Change{
+					Type:      ChangeRemoved,
+					Target:    ChangeTargetChecklistItem,
+					Checklist: oldChecklist.Title,
+					Title:     oldItem.Title,
+				})
+			}
+		}
+	}
+
+	for _, oldChecklist := range oldChecklists {
+		if findChecklist(newChecklists, oldChecklist.Title) == nil {
+			changes = append(changes, Change{
+				Type:    ChangeRemoved,
+				Target:  ChangeTargetChecklist,
+				Title:   oldChecklist.Title,
+				Details: []string{pluralize(len(oldChecklist.Items), "item")},
+			})
+		}
+	}
+
+	return changes
+}
+
+func findChecklist(checklists []Checklist, title string) *Checklist {
+	for i := range checklists {
+		if sameTitle(checklists[i].Title, title) {
+			return &checklists[i]
+		}
+	}
+	return nil
+}
+
+func findItem(items []ChecklistItem, title string) *ChecklistItem {
+	for i := range items {
+		if sameTitle(items[i].Title, title) {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func diffPropertylists(oldPropertylist, newPropertylist Propertylist) []Change {
+	var changes []Change
+
+	for _, newProperty := range newPropertylist.Items {
+		oldProperty := FindProperty(oldPropertylist, newProperty.Title)
+		if oldProperty == nil {
+			changes = append(changes, Change{
+				Type:    ChangeAdded,
+				Target:  ChangeTargetProperty,
+				Title:   newProperty.Title,
+				Details: []string{fmt.Sprintf("of type %s", newProperty.Type)},
+			})
+			continue
+		}
+
+		if details := diffProperty(*oldProperty, newProperty); len(details) > 0 {
+			changes = append(changes, Change{
+				Type:    ChangeModified,
+				Target:  ChangeTargetProperty,
+				Title:   newProperty.Title,
+				Details: details,
+			})
+		}
+	}
+
+	for _, oldProperty := range oldPropertylist.Items {
+		if FindProperty(newPropertylist, oldProperty.Title) == nil {
+			changes = append(changes, Change{
+				Type:   ChangeRemoved,
+				Target: ChangeTargetProperty,
+				Title:  oldProperty.Title,
+			})
+		}
+	}
+
+	return changes
+}
+
+// diffProperty describes the changes to the definition of a property, leaving its value aside.
+func diffProperty(oldProperty, newProperty PropertylistItem) []string {
+	if oldProperty.Type != newProperty.Type {
+		return []string{fmt.Sprintf("type changed from %s to %s", oldProperty.Type, newProperty.Type)}
+	}
+
+	var details []string
+	switch newProperty.Type {
+	case PropertyTypeSelection:
+		if oldProperty.Selection.IsMultiselect != newProperty.Selection.IsMultiselect {
+			details = append(details, "multiselect changed")
+		}
+		for _, option := range newProperty.Selection.Items {
+			if !hasOption(oldProperty.Selection.Items, option.Value) {
+				details = append(details, fmt.Sprintf("option '%s' added", option.Value))
+			}
+		}
+		for _, option := range oldProperty.Selection.Items {
+			if !hasOption(newProperty.Selection.Items, option.Value) {
+				details = append(details, fmt.Sprintf("option '%s' removed", option.Value))
+			}
+		}
+	case PropertyTypeFreetext:
+		if oldProperty.Treetext.IsMultiselect != newProperty.Treetext.IsMultiselect {
+			details = append(details, "multiselect changed")
+		}
+	case PropertyTypeNumber:
+		var oldNumber, newNumber NumberOption
+		if oldProperty.Number != nil {
+			oldNumber = *oldProperty.Number
+		}
+		if newProperty.Number != nil {
+			newNumber = *newProperty.Number
+		}
+		if oldNumber.Unit != newNumber.Unit {
+			details = append(details, fmt.Sprintf("unit changed from '%s' to '%s'", oldNumber.Unit, newNumber.Unit))
+		}
+		if !sameBound(oldNumber.Min, newNumber.Min) || !sameBound(oldNumber.Max, newNumber.Max) {
+			details = append(details, "range changed")
+		}
+	case PropertyTypeUser:
+		if oldProperty.User != nil && newProperty.User != nil &&
+			oldProperty.User.IsMultiselect != newProperty.User.IsMultiselect {
+			details = append(details, "multiselect changed")
+		}
+	}
+
+	return details
+}
+
+func hasOption(options []SelectionlistItem, value string) bool {
+	for _, option := range options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sameBound(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func diffExitCriteria(oldCriteria, newCriteria ExitCriteria) []Change {
+	var changes []Change
+
+	for _, title := range newCriteria.RequiredProperties {
+		if !hasTitle(oldCriteria.RequiredProperties, title) {
+			changes = append(changes, exitCriterionChange(ChangeAdded, fmt.Sprintf("property '%s' required", title)))
+		}
+	}
+	for _, title := range oldCriteria.RequiredProperties {
+		if !hasTitle(newCriteria.RequiredProperties, title) {
+			changes = append(changes, exitCriterionChange(ChangeRemoved, fmt.Sprintf("property '%s' required", title)))
+		}
+	}
+
+	for _, ref := range newCriteria.RequiredChecklistItems {
+		if !hasReference(oldCriteria.RequiredChecklistItems, ref) {
+			changes = append(changes, exitCriterionChange(ChangeAdded, fmt.Sprintf("checklist item '%s' in '%s' required", ref.Item, ref.Checklist)))
+		}
+	}
+	for _, ref := range oldCriteria.RequiredChecklistItems {
+		if !hasReference(newCriteria.RequiredChecklistItems, ref) {
+			changes = append(changes, exitCriterionChange(ChangeRemoved, fmt.Sprintf("checklist item '%s' in '%s' required", ref.Item, ref.Checklist)))
+		}
+	}
+
+	if oldCriteria.MinStatusUpdates != newCriteria.MinStatusUpdates {
+		changes = append(changes, Change{
+			Type:    ChangeModified,
+			Target:  ChangeTargetExitCriteria,
+			Title:   "minimum status updates",
+			Details: []string{fmt.Sprintf("changed from %d to %d", oldCriteria.MinStatusUpdates, newCriteria.MinStatusUpdates)},
+		})
+	}
+
+	return changes
+}
+
+func exitCriterionChange(changeType ChangeType, title string) Change {
+	return Change{Type: changeType, Target: ChangeTargetExitCriteria, Title: title}
+}
+
+func hasTitle(titles []string, title string) bool {
+	for _, t := range titles {
+		if sameTitle(t, title) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReference(refs []ChecklistItemReference, ref ChecklistItemReference) bool {
+	for _, r := range refs {
+		if sameTitle(r.Checklist, ref.Checklist) && sameTitle(r.Item, ref.Item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	value := 3.0
+	incidentChecklists := []Checklist{
+		{
+			ID:    "checklist1",
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{ID: "item1", Title: "Page on-call", State: ChecklistItemStateClosed, AssigneeID: "user1", AssigneeModified: 10, Command: "/page"},
+				{ID: "item2", Title: "Check dashboards", State: ChecklistItemStateInProgress, CommandLastRun: 20},
+			},
+		},
+		{Title: "Follow-up", Items: []ChecklistItem{{Title: "Write postmortem"}}},
+	}
+	incidentPropertylist := Propertylist{
+		Items: []PropertylistItem{
+			{
+				Title: "Severity",
+				Type:  PropertyTypeSelection,
+				Selection: Selectionlist{
+					Items:       []SelectionlistItem{{ID: "1", Value: "SEV1"}, {ID: "3", Value: "SEV3"}},
+					SelectedIDs: []string{"1"},
+				},
+			},
+			{Title: "Impact", Type: PropertyTypeNumber, Number: &NumberOption{Value: &value, Unit: "customers"}},
+			{Title: "Root cause", Type: PropertyTypeFreetext, Treetext: TextOption{Value: "disk full"}},
+		},
+	}
+
+	t.Run("strip", func(t *testing.T) {
+		checklists := StripChecklists(incidentChecklists)
+		require.Equal(t, []ChecklistItem{
+			{ID: "item1", Title: "Page on-call", Command: "/page"},
+			{ID: "item2", Title: "Check dashboards"},
+		}, checklists[0].Items)
+		require.Equal(t, ChecklistItemStateClosed, incidentChecklists[0].Items[0].State)
+
+		propertylist := StripPropertylist(incidentPropertylist)
+		require.Nil(t, propertylist.Items[0].Selection.SelectedIDs)
+		require.Len(t, propertylist.Items[0].Selection.Items, 2)
+		require.Nil(t, propertylist.Items[1].Number.Value)
+		require.Equal(t, "customers", propertylist.Items[1].Number.Unit)
+		require.Empty(t, propertylist.Items[2].Treetext.Value)
+		require.Equal(t, "disk full", incidentPropertylist.Items[2].Treetext.Value)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		oldPlaybook := Playbook{
+			Checklists: []Checklist{
+				{Title: "triage", Items: []ChecklistItem{{Title: "Page on-call"}, {Title: "Announce"}}},
+				{Title: "Resolution", Items: []ChecklistItem{{Title: "Close alert"}}},
+			},
+			Propertylist: Propertylist{Items: []PropertylistItem{
+				{
+					Title: "Severity",
+					Type:  PropertyTypeSelection,
+					Selection: Selectionlist{Items: []SelectionlistItem{
+						{Value: "SEV1"}, {Value: "SEV2"},
+					}},
+				},
+				{Title: "Impact", Type: PropertyTypeFreetext},
+				{Title: "Customer", Type: PropertyTypeFreetext},
+			}},
+			ExitCriteria: ExitCriteria{RequiredProperties: []string{"Customer"}},
+		}
+		newPlaybook := oldPlaybook.Clone()
+		newPlaybook.Checklists = StripChecklists(incidentChecklists)
+		newPlaybook.Propertylist = StripPropertylist(incidentPropertylist)
+		newPlaybook.ExitCriteria = oldPlaybook.ExitCriteria.Prune(newPlaybook.Checklists, newPlaybook.Propertylist)
+
+		require.Empty(t, Diff(oldPlaybook, oldPlaybook))
+		require.Equal(t, []Change{
+			{Type: ChangeModified, Target: ChangeTargetChecklistItem, Checklist: "Triage", Title: "Page on-call", Details: []string{"command changed from '' to '/page'"}},
+			{Type: ChangeAdded, Target: ChangeTargetChecklistItem, Checklist: "Triage", Title: "Check dashboards"},
+			{Type: ChangeRemoved, Target: ChangeTargetChecklistItem, Checklist: "triage", Title: "Announce"},
+			{Type: ChangeAdded, Target: ChangeTargetChecklist, Title: "Follow-up", Details: []string{"1 item"}},
+			{Type: ChangeRemoved, Target: ChangeTargetChecklist, Title: "Resolution", Details: []string{"1 item"}},
+			{Type: ChangeModified, Target: ChangeTargetProperty, Title: "Severity", Details: []string{"option 'SEV3' added", "option 'SEV2' removed"}},
+			{Type: ChangeModified, Target: ChangeTargetProperty, Title: "Impact", Details: []string{"type changed from Freetext to Number"}},
+			{Type: ChangeAdded, Target: ChangeTargetProperty, Title: "Root cause", Details: []string{"of type Freetext"}},
+			{Type: ChangeRemoved, Target: ChangeTargetProperty, Title: "Customer"},
+			{Type: ChangeRemoved, Target: ChangeTargetExitCriteria, Title: "property 'Customer' required"},
+		}, Diff(oldPlaybook, newPlaybook))
+	})
+}
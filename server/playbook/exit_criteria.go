@@ -0,0 +1,141 @@
+package playbook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExitCriteria are the conditions an incident must meet before it can be resolved or archived.
+// Properties and checklist items are referenced by title, since their IDs are regenerated when an
+// incident is created from the playbook.
+type ExitCriteria struct {
+	// RequiredProperties are the titles of the properties that must have a value.
+	RequiredProperties []string `json:"required_properties,omitempty"`
+
+	// RequiredChecklistItems are the checklist items that must be closed.
+	RequiredChecklistItems []ChecklistItemReference `json:"required_checklist_items,omitempty"`
+
+	// MinStatusUpdates is the minimum number of status updates posted before resolving.
+	MinStatusUpdates int `json:"min_status_updates,omitempty"`
+}
+
+// ChecklistItemReference identifies a checklist item by the titles of its checklist and itself.
+type ChecklistItemReference struct {
+	Checklist string `json:"checklist"`
+	Item      string `json:"item"`
+}
+
+func (c ExitCriteria) Clone() ExitCriteria {
+	newExitCriteria := c
+	newExitCriteria.RequiredProperties = append([]string(nil), c.RequiredProperties...)
+	newExitCriteria.RequiredChecklistItems = append([]ChecklistItemReference(nil), c.RequiredChecklistItems...)
+	return newExitCriteria
+}
+
+// IsEmpty returns true if there is no criteria to meet.
+func (c ExitCriteria) IsEmpty() bool {
+	return len(c.RequiredProperties) == 0 && len(c.RequiredChecklistItems) == 0 && c.MinStatusUpdates <= 0
+}
+
+// Validate checks that the criteria reference properties and checklist items that exist in the
+// given propertylist and checklists.
+func (c ExitCriteria) Validate(checklists []Checklist, propertylist Propertylist) error {
+	if c.MinStatusUpdates < 0 {
+		return errors.New("exit criteria: the minimum number of status updates must not be negative")
+	}
+
+	for _, title := range c.RequiredProperties {
+		if FindProperty(propertylist, title) == nil {
+			return errors.Errorf("exit criteria: unknown property '%s'", title)
+		}
+	}
+
+	for _, ref := range c.RequiredChecklistItems {
+		if FindChecklistItem(checklists, ref) == nil {
+			return errors.Errorf("exit criteria: unknown checklist item '%s' in checklist '%s'", ref.Item, ref.Checklist)
+		}
+	}
+
+	return nil
+}
+
+// Prune returns the criteria without the references to properties and checklist items that
+// don't exist in the given propertylist and checklists.
+func (c ExitCriteria) Prune(checklists []Checklist, propertylist Propertylist) ExitCriteria {
+	pruned := ExitCriteria{MinStatusUpdates: c.MinStatusUpdates}
+
+	for _, title := range c.RequiredProperties {
+		if FindProperty(propertylist, title) != nil {
+			pruned.RequiredProperties = append(pruned.RequiredProperties, title)
+		}
+	}
+
+	for _, ref := range c.RequiredChecklistItems {
+		if FindChecklistItem(checklists, ref) != nil {
+			pruned.RequiredChecklistItems = append(pruned.RequiredChecklistItems, ref)
+		}
+	}
+
+	return pruned
+}
+
+// Unmet returns a human readable description of each criterion not met by the given checklists,
+// propertylist and number of status updates.
+func (c ExitCriteria) Unmet(checklists []Checklist, propertylist Propertylist, numStatusUpdates int) []string {
+	var unmet []string
+
+	for _, title := range c.RequiredProperties {
+		property := FindProperty(propertylist, title)
+		if property == nil || len(property.Values()) == 0 {
+			unmet = append(unmet, fmt.Sprintf("property '%s' must be filled in", title))
+		}
+	}
+
+	for _, ref := range c.RequiredChecklistItems {
+		item := FindChecklistItem(checklists, ref)
+		if item == nil || item.State != ChecklistItemStateClosed {
+			unmet = append(unmet, fmt.Sprintf("checklist item '%s' in '%s' must be checked off", ref.Item, ref.Checklist))
+		}
+	}
+
+	if numStatusUpdates < c.MinStatusUpdates {
+		unmet = append(unmet, fmt.Sprintf("at least %s must be posted first", pluralize(c.MinStatusUpdates, "status update")))
+	}
+
+	return unmet
+}
+
+// FindProperty returns the property with the given title, ignoring case, or nil if there is none.
+func FindProperty(propertylist Propertylist, title string) *PropertylistItem {
+	for i := range propertylist.Items {
+		if strings.EqualFold(strings.TrimSpace(propertylist.Items[i].Title), strings.TrimSpace(title)) {
+			return &propertylist.Items[i]
+		}
+	}
+	return nil
+}
+
+// FindChecklistItem returns the checklist item referenced by ref, ignoring case, or nil if there
+// is none.
+func FindChecklistItem(checklists []Checklist, ref ChecklistItemReference) *ChecklistItem {
+	for i := range checklists {
+		if !strings.EqualFold(strings.TrimSpace(checklists[i].Title), strings.TrimSpace(ref.Checklist)) {
+			continue
+		}
+		for j := range checklists[i].Items {
+			if strings.EqualFold(strings.TrimSpace(checklists[i].Items[j].Title), strings.TrimSpace(ref.Item)) {
+				return &checklists[i].Items[j]
+			}
+		}
+	}
+	return nil
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,86 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCriteria(t *testing.T) {
+	checklists := []Checklist{
+		{
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{Title: "Page on-call", State: ChecklistItemStateClosed},
+				{Title: "Write postmortem", State: ChecklistItemStateInProgress},
+			},
+		},
+	}
+	propertylist := Propertylist{
+		Items: []PropertylistItem{
+			{Title: "Root cause", Type: PropertyTypeFreetext, Treetext: TextOption{Value: "disk full"}},
+			{Title: "Impact", Type: PropertyTypeNumber},
+		},
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		valid := ExitCriteria{
+			RequiredProperties:     []string{"root cause"},
+			RequiredChecklistItems: []ChecklistItemReference{{Checklist: "triage", Item: "page on-call"}},
+			MinStatusUpdates:       1,
+		}
+		require.NoError(t, valid.Validate(checklists, propertylist))
+
+		unknownProperty := ExitCriteria{RequiredProperties: []string{"Severity"}}
+		require.Error(t, unknownProperty.Validate(checklists, propertylist))
+
+		unknownItem := ExitCriteria{RequiredChecklistItems: []ChecklistItemReference{{Checklist: "Triage", Item: "Deploy fix"}}}
+		require.Error(t, unknownItem.Validate(checklists, propertylist))
+
+		negative := ExitCriteria{MinStatusUpdates: -1}
+		require.Error(t, negative.Validate(checklists, propertylist))
+	})
+
+	t.Run("met", func(t *testing.T) {
+		criteria := ExitCriteria{
+			RequiredProperties:     []string{"Root cause"},
+			RequiredChecklistItems: []ChecklistItemReference{{Checklist: "Triage", Item: "Page on-call"}},
+			MinStatusUpdates:       1,
+		}
+		require.Empty(t, criteria.Unmet(checklists, propertylist, 1))
+	})
+
+	t.Run("unmet", func(t *testing.T) {
+		criteria := ExitCriteria{
+			RequiredProperties:     []string{"Impact"},
+			RequiredChecklistItems: []ChecklistItemReference{{Checklist: "Triage", Item: "Write postmortem"}},
+			MinStatusUpdates:       2,
+		}
+		require.Equal(t, []string{
+			"property 'Impact' must be filled in",
+			"checklist item 'Write postmortem' in 'Triage' must be checked off",
+			"at least 2 status updates must be posted first",
+		}, criteria.Unmet(checklists, propertylist, 1))
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		criteria := ExitCriteria{
+			RequiredProperties: []string{"Root cause", "Customer"},
+			RequiredChecklistItems: []ChecklistItemReference{
+				{Checklist: "Triage", Item: "Page on-call"},
+				{Checklist: "Resolution", Item: "Close alert"},
+			},
+			MinStatusUpdates: 1,
+		}
+		require.Equal(t, ExitCriteria{
+			RequiredProperties:     []string{"Root cause"},
+			RequiredChecklistItems: []ChecklistItemReference{{Checklist: "Triage", Item: "Page on-call"}},
+			MinStatusUpdates:       1,
+		}, criteria.Prune(checklists, propertylist))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.True(t, ExitCriteria{}.IsEmpty())
+		require.Empty(t, ExitCriteria{}.Unmet(nil, Propertylist{}, 0))
+	})
+}
@@ -33,6 +33,10 @@ type Options struct {
 	// Pagination options.
 	Page    int
 	PerPage int
+
+	// Cursor is the next_cursor of the previous page, to get the page right after it instead of
+	// using Page. It must be used with the same sort as the previous page.
+	Cursor string
 }
 
 func IsValidSort(sort SortField) bool {
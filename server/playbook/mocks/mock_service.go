@@ -6,6 +6,7 @@ package mock_playbook
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	playbook "github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	reflect "reflect"
 )
@@ -62,6 +63,36 @@ func (mr *MockServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), arg0, arg1)
 }
 
+// Duplicate mocks base method
+func (m *MockService) Duplicate(arg0 playbook.Playbook, arg1, arg2, arg3 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Duplicate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Duplicate indicates an expected call of Duplicate
+func (mr *MockServiceMockRecorder) Duplicate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Duplicate", reflect.TypeOf((*MockService)(nil).Duplicate), arg0, arg1, arg2, arg3)
+}
+
+// Fork mocks base method
+func (m *MockService) Fork(arg0 playbook.Playbook, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fork", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fork indicates an expected call of Fork
+func (mr *MockServiceMockRecorder) Fork(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fork", reflect.TypeOf((*MockService)(nil).Fork), arg0, arg1, arg2)
+}
+
 // Get mocks base method
 func (m *MockService) Get(arg0 string) (playbook.Playbook, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +138,21 @@ func (mr *MockServiceMockRecorder) GetPlaybooksForTeam(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybooksForTeam", reflect.TypeOf((*MockService)(nil).GetPlaybooksForTeam), arg0, arg1, arg2)
 }
 
+// InstallTemplate mocks base method
+func (m *MockService) InstallTemplate(arg0, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstallTemplate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InstallTemplate indicates an expected call of InstallTemplate
+func (mr *MockServiceMockRecorder) InstallTemplate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallTemplate", reflect.TypeOf((*MockService)(nil).InstallTemplate), arg0, arg1, arg2)
+}
+
 // Update mocks base method
 func (m *MockService) Update(arg0 playbook.Playbook, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -120,3 +166,31 @@ func (mr *MockServiceMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), arg0, arg1)
 }
+
+// Validate mocks base method
+func (m *MockService) Validate(arg0 playbook.Playbook) playbook.ValidationResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", arg0)
+	ret0, _ := ret[0].(playbook.ValidationResult)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockServiceMockRecorder) Validate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockService)(nil).Validate), arg0)
+}
+
+// WithAuditSource mocks base method
+func (m *MockService) WithAuditSource(arg0 audit.Source) playbook.Service {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithAuditSource", arg0)
+	ret0, _ := ret[0].(playbook.Service)
+	return ret0
+}
+
+// WithAuditSource indicates an expected call of WithAuditSource
+func (mr *MockServiceMockRecorder) WithAuditSource(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAuditSource", reflect.TypeOf((*MockService)(nil).WithAuditSource), arg0)
+}
@@ -77,6 +77,21 @@ func (mr *MockStoreMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStore)(nil).Get), arg0)
 }
 
+// GetPlaybookIDForExternalID mocks base method
+func (m *MockStore) GetPlaybookIDForExternalID(arg0, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaybookIDForExternalID", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaybookIDForExternalID indicates an expected call of GetPlaybookIDForExternalID
+func (mr *MockStoreMockRecorder) GetPlaybookIDForExternalID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybookIDForExternalID", reflect.TypeOf((*MockStore)(nil).GetPlaybookIDForExternalID), arg0, arg1)
+}
+
 // GetPlaybooks mocks base method
 func (m *MockStore) GetPlaybooks() ([]playbook.Playbook, error) {
 	m.ctrl.T.Helper()
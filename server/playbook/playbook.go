@@ -3,12 +3,18 @@ package playbook
 import (
 	"encoding/json"
 
+	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 )
 
 // ErrNotFound used to indicate entity not found.
 var ErrNotFound = errors.New("not found")
 
+// ErrDuplicateExternalID is used to indicate another playbook of the team has the same external ID.
+var ErrDuplicateExternalID = errors.New("duplicate external ID")
+
 // Playbook represents the planning before an incident type is initiated.
 type Playbook struct {
 	ID                          string       `json:"id"`
@@ -22,10 +28,56 @@ type Playbook struct {
 	NumSteps                    int64        `json:"num_steps"`
 	Checklists                  []Checklist  `json:"checklists"`
 	Propertylist                Propertylist `json:"propertylist"`
+	ExitCriteria                ExitCriteria `json:"exit_criteria"`
 	MemberIDs                   []string     `json:"member_ids"`
 	BroadcastChannelID          string       `json:"broadcast_channel_id"`
 	ReminderMessageTemplate     string       `json:"reminder_message_template"`
 	ReminderTimerDefaultSeconds int64        `json:"reminder_timer_default_seconds"`
+
+	// Members gives the roles of the members, and Groups the roles of all the users of some
+	// groups. See AllMembers for how Members and MemberIDs combine.
+	Members []Member      `json:"members,omitempty"`
+	Groups  []GroupMember `json:"groups,omitempty"`
+
+	// IsGlobal publishes the playbook to every team, and SharedTeamIDs to the given teams
+	// besides its own. Only admins can publish playbooks. Everyone in a team a playbook is
+	// published to can use it, whether they are members of the playbook or not.
+	IsGlobal      bool     `json:"is_global"`
+	SharedTeamIDs []string `json:"shared_team_ids,omitempty"`
+
+	// Version is incremented every time the playbook is updated.
+	Version int64 `json:"version"`
+
+	// ForkedFromID and ForkedFromVersion identify the playbook, and its version, this one was
+	// forked from.
+	ForkedFromID      string `json:"forked_from_id"`
+	ForkedFromVersion int64  `json:"forked_from_version"`
+
+	// OnCallScheduleID is the on-call schedule of the team giving the default commander of the
+	// incidents started from the playbook.
+	OnCallScheduleID string `json:"oncall_schedule_id"`
+
+	// ExternalID identifies the playbook in the files it is synced from, and is unique in its
+	// team. Unlike the title, it doesn't change when the playbook is renamed.
+	ExternalID string `json:"external_id"`
+}
+
+// IsShared returns true if the playbook is published to teams other than its own.
+func (p Playbook) IsShared() bool {
+	return p.IsGlobal || len(p.SharedTeamIDs) > 0
+}
+
+// IsSharedWith returns true if the playbook is published to the given team.
+func (p Playbook) IsSharedWith(teamID string) bool {
+	if p.IsGlobal {
+		return true
+	}
+	for _, sharedTeamID := range p.SharedTeamIDs {
+		if sharedTeamID == teamID {
+			return true
+		}
+	}
+	return false
 }
 
 func (p Playbook) Clone() Playbook {
@@ -40,10 +92,44 @@ func (p Playbook) Clone() Playbook {
 
 	newPlaybook.Propertylist = newPropertylist
 	newPlaybook.Checklists = newChecklists
+	newPlaybook.ExitCriteria = p.ExitCriteria.Clone()
 	newPlaybook.MemberIDs = append([]string(nil), p.MemberIDs...)
+	newPlaybook.Members = append([]Member(nil), p.Members...)
+	newPlaybook.Groups = append([]GroupMember(nil), p.Groups...)
+	newPlaybook.SharedTeamIDs = append([]string(nil), p.SharedTeamIDs...)
 	return newPlaybook
 }
 
+// RegenerateIDs gives new IDs to the checklists, the checklist items, the propertylist, its items
+// and their options, so that a copy of a playbook shares none of them with the original. The
+// selected options of selection properties stay selected.
+func (p *Playbook) RegenerateIDs() {
+	for i := range p.Checklists {
+		p.Checklists[i].ID = model.NewId()
+		for j := range p.Checklists[i].Items {
+			p.Checklists[i].Items[j].ID = model.NewId()
+		}
+	}
+
+	p.Propertylist.ID = model.NewId()
+	for i := range p.Propertylist.Items {
+		item := &p.Propertylist.Items[i]
+		item.ID = model.NewId()
+
+		newIDs := make(map[string]string, len(item.Selection.Items))
+		for j := range item.Selection.Items {
+			newID := model.NewId()
+			newIDs[item.Selection.Items[j].ID] = newID
+			item.Selection.Items[j].ID = newID
+		}
+		for j, selectedID := range item.Selection.SelectedIDs {
+			if newID, ok := newIDs[selectedID]; ok {
+				item.Selection.SelectedIDs[j] = newID
+			}
+		}
+	}
+}
+
 func (p Playbook) MarshalJSON() ([]byte, error) {
 	type Alias Playbook
 
@@ -90,21 +176,53 @@ func (c Checklist) Clone() Checklist {
 
 func (c Propertylist) Clone() Propertylist {
 	newPropertylist := c
+	if c.Items != nil {
+		newPropertylist.Items = make([]PropertylistItem, 0, len(c.Items))
+		for _, item := range c.Items {
+			newPropertylist.Items = append(newPropertylist.Items, item.Clone())
+		}
+	}
 	return newPropertylist
 }
 
 func (c PropertylistItem) Clone() PropertylistItem {
 	newPropertyItem := c
+	newPropertyItem.Selection = c.Selection.Clone()
+	newPropertyItem.Treetext = c.Treetext.Clone()
+	if c.Number != nil {
+		number := c.Number.Clone()
+		newPropertyItem.Number = &number
+	}
+	if c.Datetime != nil {
+		datetime := *c.Datetime
+		newPropertyItem.Datetime = &datetime
+	}
+	if c.User != nil {
+		user := *c.User
+		user.UserIDs = append([]string(nil), c.User.UserIDs...)
+		newPropertyItem.User = &user
+	}
+	if c.Channel != nil {
+		channel := *c.Channel
+		newPropertyItem.Channel = &channel
+	}
+	if c.URL != nil {
+		url := *c.URL
+		newPropertyItem.URL = &url
+	}
 	return newPropertyItem
 }
 
 func (c TextOption) Clone() TextOption {
 	newTextOption := c
+	newTextOption.Values = append([]string(nil), c.Values...)
 	return newTextOption
 }
 
 func (c Selectionlist) Clone() Selectionlist {
 	newSelectionlist := c
+	newSelectionlist.Items = append([]SelectionlistItem(nil), c.Items...)
+	newSelectionlist.SelectedIDs = append([]string(nil), c.SelectedIDs...)
 	return newSelectionlist
 }
 
@@ -128,32 +246,45 @@ type ChecklistItem struct {
 	Description            string `json:"description"`
 }
 
+// GetPlaybooksResults is a page of playbooks. TotalCount and PageCount are not computed when
+// paging with a cursor, and are then 0.
 type GetPlaybooksResults struct {
 	TotalCount int        `json:"total_count"`
 	PageCount  int        `json:"page_count"`
 	HasMore    bool       `json:"has_more"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 	Items      []Playbook `json:"items"`
 }
 
-// PropertylistItem represents an item in a checklist
+// PropertylistItem represents a property of an incident. Type is one of the PropertyType
+// constants, and only the option matching it is meaningful.
 type PropertylistItem struct {
-	ID        string        `json:"id"`
-	Title     string        `json:"title"`
-	Type      string        `json:"type"`
-	Selection Selectionlist `json:"selection"`
-	Treetext  TextOption    `json:"freetext"`
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Type      string          `json:"type"`
+	Selection Selectionlist   `json:"selection"`
+	Treetext  TextOption      `json:"freetext"`
+	Number    *NumberOption   `json:"number,omitempty"`
+	Datetime  *DatetimeOption `json:"datetime,omitempty"`
+	User      *UserOption     `json:"user,omitempty"`
+	Channel   *ChannelOption  `json:"channel,omitempty"`
+	URL       *URLOption      `json:"url,omitempty"`
 }
 
+// TextOption holds the value of a freetext property. Multiselect properties keep their
+// values in Values, single value ones in Value.
 type TextOption struct {
 	Value         string     `json:"value"`
+	Values        []string   `json:"values"`
 	IsMultiselect bool       `json:"is_multiselect"`
 	BadgeStyle    BadgeStyle `json:"badge_style"`
 }
 
+// Selectionlist holds the options of a selection property and the IDs of the selected ones.
 type Selectionlist struct {
 	Items         []SelectionlistItem `json:"items"`
 	IsMultiselect bool                `json:"is_multiselect"`
-	SelectedId    string              `json:"selected_id"`
+	SelectedIDs   []string            `json:"selected_ids"`
 }
 
 type SelectionlistItem struct {
@@ -193,6 +324,10 @@ type RequesterInfo struct {
 
 	// MemberOnly filters playbooks to those for which UserId is a member
 	MemberOnly bool
+
+	// MinRole, when set, only counts the memberships with at least this role, e.g. RoleRunner
+	// to get the playbooks the user can start incidents from.
+	MinRole Role
 }
 
 // Service is the playbook service for managing playbooks
@@ -204,12 +339,27 @@ type Service interface {
 	Create(playbook Playbook, userID string) (string, error)
 	// GetPlaybooks retrieves all playbooks
 	GetPlaybooks() ([]Playbook, error)
-	// GetPlaybooksForTeam retrieves all playbooks on the specified team given the provided options
+	// GetPlaybooksForTeam retrieves all playbooks on the specified team, and the ones published
+	// to it, given the provided options
 	GetPlaybooksForTeam(requesterInfo RequesterInfo, teamID string, opts Options) (GetPlaybooksResults, error)
-	// Update updates a playbook
+	// Update updates a playbook, incrementing its version
 	Update(playbook Playbook, userID string) error
+	// Fork copies a playbook into the given team, with userID as its only member
+	Fork(playbook Playbook, teamID, userID string) (string, error)
+	// Duplicate copies a playbook, with new IDs for its checklists and properties, into the
+	// given team under the given title, and returns the ID of the copy. userID owns the copy.
+	Duplicate(playbook Playbook, teamID, title, userID string) (string, error)
+	// InstallTemplate creates a playbook in the given team from the starter template with the
+	// given name, and returns its ID. userID owns the new playbook.
+	InstallTemplate(name, teamID, userID string) (string, error)
+	// Validate checks the playbook, both on its own and against the state of the server. Create
+	// and Update return a *ValidationError when there are errors.
+	Validate(playbook Playbook) ValidationResult
 	// Delete deletes a playbook
 	Delete(playbook Playbook, userID string) error
+	// WithAuditSource returns a copy of the service recording its changes in the audit log as
+	// coming from source.
+	WithAuditSource(source audit.Source) Service
 }
 
 // Store is an interface for storing playbooks
@@ -220,12 +370,15 @@ type Store interface {
 	Create(playbook Playbook) (string, error)
 	// GetPlaybooks retrieves all playbooks
 	GetPlaybooks() ([]Playbook, error)
-	// GetPlaybooksForTeam retrieves all playbooks on the specified team
+	// GetPlaybooksForTeam retrieves all playbooks on the specified team, and the ones published to it
 	GetPlaybooksForTeam(requesterInfo RequesterInfo, teamID string, opts Options) (GetPlaybooksResults, error)
 	// Update updates a playbook
 	Update(playbook Playbook) error
 	// Delete deletes a playbook
 	Delete(id string) error
+	// GetPlaybookIDForExternalID returns the ID of the playbook of the team with the given
+	// external ID, or ErrNotFound
+	GetPlaybookIDForExternalID(teamID, externalID string) (string, error)
 }
 
 // Telemetry defines the methods that the Playbook service needs from the RudderTelemetry.
@@ -245,12 +398,14 @@ const (
 	ChecklistItemStateOpen       = ""
 	ChecklistItemStateInProgress = "in_progress"
 	ChecklistItemStateClosed     = "closed"
+	ChecklistItemStateSkipped    = "skipped"
 )
 
 func IsValidChecklistItemState(state string) bool {
 	return state == ChecklistItemStateClosed ||
 		state == ChecklistItemStateInProgress ||
-		state == ChecklistItemStateOpen
+		state == ChecklistItemStateOpen ||
+		state == ChecklistItemStateSkipped
 }
 
 func IsValidChecklistItemIndex(checklists []Checklist, checklistNum, itemNum int) bool {
@@ -261,7 +416,7 @@ func IsValidPropertylistItemParams(propertylist Propertylist, propertyTitle, pro
 	var result = true
 	for _, item := range propertylist.Items {
 		if item.Title == propertyTitle {
-			if item.Type == "Freetext" {
+			if item.Type == PropertyTypeFreetext {
 				result = false
 				break
 			}
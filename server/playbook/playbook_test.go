@@ -32,7 +32,7 @@ func TestPlaybook_MarshalJSON(t *testing.T) {
 				ReminderMessageTemplate:     "This is a message",
 				ReminderTimerDefaultSeconds: 0,
 			},
-			expected: []byte(`{"id":"playbookid","title":"the playbook title","description":"the playbook's description","team_id":"theteamid","create_public_incident":true,"create_at":4503134,"delete_at":0,"num_stages":0,"num_steps":0,"checklists":[],"member_ids":[],"broadcast_channel_id":"channelid","reminder_message_template":"This is a message","reminder_timer_default_seconds":0}`),
+			expected: []byte(`{"id":"playbookid","title":"the playbook title","description":"the playbook's description","team_id":"theteamid","create_public_incident":true,"create_at":4503134,"delete_at":0,"num_stages":0,"num_steps":0,"checklists":[],"propertylist":{"id":"","title":"","items":[]},"exit_criteria":{},"member_ids":[],"broadcast_channel_id":"channelid","reminder_message_template":"This is a message","reminder_timer_default_seconds":0,"is_global":false,"version":0,"forked_from_id":"","forked_from_version":0,"oncall_schedule_id":"","external_id":""}`),
 			wantErr:  false,
 		},
 		{
@@ -59,7 +59,7 @@ func TestPlaybook_MarshalJSON(t *testing.T) {
 				ReminderMessageTemplate:     "This is a message",
 				ReminderTimerDefaultSeconds: 0,
 			},
-			expected: []byte(`{"id":"playbookid","title":"the playbook title","description":"the playbook's description","team_id":"theteamid","create_public_incident":true,"create_at":4503134,"delete_at":0,"num_stages":0,"num_steps":0,"checklists":[{"id":"checklist1","title":"checklist 1","items":[]}],"member_ids":["bob","divyani"],"broadcast_channel_id":"","reminder_message_template":"This is a message","reminder_timer_default_seconds":0}`),
+			expected: []byte(`{"id":"playbookid","title":"the playbook title","description":"the playbook's description","team_id":"theteamid","create_public_incident":true,"create_at":4503134,"delete_at":0,"num_stages":0,"num_steps":0,"checklists":[{"id":"checklist1","title":"checklist 1","items":[]}],"propertylist":{"id":"","title":"","items":[]},"exit_criteria":{},"member_ids":["bob","divyani"],"broadcast_channel_id":"","reminder_message_template":"This is a message","reminder_timer_default_seconds":0,"is_global":false,"version":0,"forked_from_id":"","forked_from_version":0,"oncall_schedule_id":"","external_id":""}`),
 			wantErr:  false,
 		},
 	}
@@ -74,3 +74,52 @@ func TestPlaybook_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaybook_IsSharedWith(t *testing.T) {
+	require.False(t, Playbook{TeamID: "team1"}.IsShared())
+	require.False(t, Playbook{TeamID: "team1"}.IsSharedWith("team2"))
+
+	shared := Playbook{TeamID: "team1", SharedTeamIDs: []string{"team2", "team3"}}
+	require.True(t, shared.IsShared())
+	require.True(t, shared.IsSharedWith("team3"))
+	require.False(t, shared.IsSharedWith("team4"))
+
+	global := Playbook{TeamID: "team1", IsGlobal: true}
+	require.True(t, global.IsShared())
+	require.True(t, global.IsSharedWith("team4"))
+}
+
+func TestPlaybook_RegenerateIDs(t *testing.T) {
+	original := Playbook{
+		Checklists: []Checklist{
+			{ID: "checklist1", Items: []ChecklistItem{{ID: "item1"}, {ID: "item2"}}},
+		},
+		Propertylist: Propertylist{
+			ID: "propertylist1",
+			Items: []PropertylistItem{{
+				ID:   "property1",
+				Type: PropertyTypeSelection,
+				Selection: Selectionlist{
+					Items:       []SelectionlistItem{{ID: "option1"}, {ID: "option2"}},
+					SelectedIDs: []string{"option2"},
+				},
+			}},
+		},
+	}
+
+	duplicate := original.Clone()
+	duplicate.RegenerateIDs()
+
+	require.Equal(t, "checklist1", original.Checklists[0].ID)
+	require.Equal(t, "option2", original.Propertylist.Items[0].Selection.SelectedIDs[0])
+
+	require.NotEqual(t, "checklist1", duplicate.Checklists[0].ID)
+	require.NotEqual(t, "item1", duplicate.Checklists[0].Items[0].ID)
+	require.NotEqual(t, duplicate.Checklists[0].Items[0].ID, duplicate.Checklists[0].Items[1].ID)
+	require.NotEqual(t, "propertylist1", duplicate.Propertylist.ID)
+	require.NotEqual(t, "property1", duplicate.Propertylist.Items[0].ID)
+
+	selection := duplicate.Propertylist.Items[0].Selection
+	require.NotEqual(t, "option2", selection.Items[1].ID)
+	require.Equal(t, []string{selection.Items[1].ID}, selection.SelectedIDs)
+}
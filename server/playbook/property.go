@@ -0,0 +1,381 @@
+package playbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// The types a PropertylistItem can have.
+const (
+	PropertyTypeFreetext  = "Freetext"
+	PropertyTypeSelection = "Selection"
+	PropertyTypeNumber    = "Number"
+	PropertyTypeDatetime  = "Datetime"
+	PropertyTypeUser      = "User"
+	PropertyTypeChannel   = "Channel"
+	PropertyTypeURL       = "URL"
+)
+
+// ErrInvalidPropertyValue is used to indicate a value cannot be given to a property.
+var ErrInvalidPropertyValue = errors.New("invalid property value")
+
+// datetimeLayouts are the layouts accepted when parsing a datetime property value, on top of
+// milliseconds since the epoch.
+var datetimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"}
+
+// NumberOption holds the value of a number property, with its unit and optional range.
+type NumberOption struct {
+	Value *float64 `json:"value"`
+	Unit  string   `json:"unit"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+}
+
+func (c NumberOption) Clone() NumberOption {
+	newNumberOption := c
+	if c.Value != nil {
+		value := *c.Value
+		newNumberOption.Value = &value
+	}
+	if c.Min != nil {
+		min := *c.Min
+		newNumberOption.Min = &min
+	}
+	if c.Max != nil {
+		max := *c.Max
+		newNumberOption.Max = &max
+	}
+	return newNumberOption
+}
+
+// DatetimeOption holds the value of a datetime property, in milliseconds since the epoch.
+// A zero value means the property is not set.
+type DatetimeOption struct {
+	Value int64 `json:"value"`
+}
+
+// UserOption holds the IDs of the users selected in a user property.
+type UserOption struct {
+	UserIDs       []string `json:"user_ids"`
+	IsMultiselect bool     `json:"is_multiselect"`
+}
+
+// ChannelOption holds the ID of the channel selected in a channel property.
+type ChannelOption struct {
+	ChannelID string `json:"channel_id"`
+}
+
+// URLOption holds the value of a URL property.
+type URLOption struct {
+	Value string `json:"value"`
+}
+
+// IsValidPropertyType returns true if propertyType is one of the supported property types.
+func IsValidPropertyType(propertyType string) bool {
+	switch propertyType {
+	case PropertyTypeFreetext, PropertyTypeSelection, PropertyTypeNumber, PropertyTypeDatetime,
+		PropertyTypeUser, PropertyTypeChannel, PropertyTypeURL:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON reads the selected IDs, accepting the legacy comma-joined selected_id field.
+func (c *Selectionlist) UnmarshalJSON(data []byte) error {
+	type Alias Selectionlist
+
+	aux := struct {
+		*Alias
+		SelectedID string `json:"selected_id"`
+	}{
+		Alias: (*Alias)(c),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if c.SelectedIDs == nil && aux.SelectedID != "" {
+		c.SelectedIDs = splitLegacyValues(aux.SelectedID)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON reads the text values, accepting multiselect values stored comma-joined in Value.
+func (c *TextOption) UnmarshalJSON(data []byte) error {
+	type Alias TextOption
+
+	if err := json.Unmarshal(data, (*Alias)(c)); err != nil {
+		return err
+	}
+
+	if c.IsMultiselect && c.Values == nil && c.Value != "" {
+		c.Values = splitLegacyValues(c.Value)
+		c.Value = ""
+	}
+
+	return nil
+}
+
+func splitLegacyValues(joined string) []string {
+	var values []string
+	for _, value := range strings.Split(joined, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Validate checks that the property is well defined.
+func (p PropertylistItem) Validate() error {
+	if strings.TrimSpace(p.Title) == "" {
+		return errors.New("property title must not be blank")
+	}
+
+	if !IsValidPropertyType(p.Type) {
+		return errors.Errorf("property '%s' has an unknown type '%s'", p.Title, p.Type)
+	}
+
+	switch p.Type {
+	case PropertyTypeSelection:
+		if len(p.Selection.Items) == 0 {
+			return errors.Errorf("selection property '%s' must have at least one option", p.Title)
+		}
+	case PropertyTypeNumber:
+		if p.Number != nil && p.Number.Min != nil && p.Number.Max != nil && *p.Number.Min > *p.Number.Max {
+			return errors.Errorf("number property '%s' has a minimum greater than its maximum", p.Title)
+		}
+	}
+
+	return nil
+}
+
+// Values returns the raw values of the property: the selected IDs for selections, users and
+// channels, and the textual representation of the value for the other types.
+func (p PropertylistItem) Values() []string {
+	switch p.Type {
+	case PropertyTypeSelection:
+		return p.Selection.SelectedIDs
+	case PropertyTypeNumber:
+		if p.Number != nil && p.Number.Value != nil {
+			return []string{strconv.FormatFloat(*p.Number.Value, 'f', -1, 64)}
+		}
+	case PropertyTypeDatetime:
+		if p.Datetime != nil && p.Datetime.Value != 0 {
+			return []string{strconv.FormatInt(p.Datetime.Value, 10)}
+		}
+	case PropertyTypeUser:
+		if p.User != nil {
+			return p.User.UserIDs
+		}
+	case PropertyTypeChannel:
+		if p.Channel != nil && p.Channel.ChannelID != "" {
+			return []string{p.Channel.ChannelID}
+		}
+	case PropertyTypeURL:
+		if p.URL != nil && p.URL.Value != "" {
+			return []string{p.URL.Value}
+		}
+	default:
+		if p.Treetext.IsMultiselect {
+			return p.Treetext.Values
+		}
+		if p.Treetext.Value != "" {
+			return []string{p.Treetext.Value}
+		}
+	}
+
+	return nil
+}
+
+// SetValues parses and validates values according to the type of the property, and sets them as
+// its new value. An empty values clears the property. Users and channels are only checked to be
+// well formed here; checking that they exist is left to the caller.
+func (p *PropertylistItem) SetValues(values []string) error {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return errors.Wrapf(ErrInvalidPropertyValue, "property '%s': %s", p.Title, fmt.Sprintf(format, args...))
+	}
+
+	multiselect := p.Type == PropertyTypeSelection && p.Selection.IsMultiselect ||
+		p.Type == PropertyTypeUser && p.User != nil && p.User.IsMultiselect ||
+		(p.Type == PropertyTypeFreetext || p.Type == "") && p.Treetext.IsMultiselect
+	if len(cleaned) > 1 && !multiselect {
+		return invalid("only one value is allowed")
+	}
+
+	switch p.Type {
+	case PropertyTypeSelection:
+		for _, id := range cleaned {
+			if !p.hasSelectionItem(id) {
+				return invalid("unknown option '%s'", id)
+			}
+		}
+		p.Selection.SelectedIDs = cleaned
+
+	case PropertyTypeNumber:
+		if p.Number == nil {
+			p.Number = &NumberOption{}
+		}
+		if len(cleaned) == 0 {
+			p.Number.Value = nil
+			return nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(cleaned[0], p.Number.Unit)), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return invalid("'%s' is not a number", cleaned[0])
+		}
+		if p.Number.Min != nil && value < *p.Number.Min {
+			return invalid("%v is below the minimum of %v", value, *p.Number.Min)
+		}
+		if p.Number.Max != nil && value > *p.Number.Max {
+			return invalid("%v is above the maximum of %v", value, *p.Number.Max)
+		}
+		p.Number.Value = &value
+
+	case PropertyTypeDatetime:
+		if p.Datetime == nil {
+			p.Datetime = &DatetimeOption{}
+		}
+		if len(cleaned) == 0 {
+			p.Datetime.Value = 0
+			return nil
+		}
+		value, err := parseDatetime(cleaned[0])
+		if err != nil {
+			return invalid("'%s' is not a valid date", cleaned[0])
+		}
+		p.Datetime.Value = value
+
+	case PropertyTypeUser:
+		if p.User == nil {
+			p.User = &UserOption{}
+		}
+		for _, id := range cleaned {
+			if !model.IsValidId(id) {
+				return invalid("'%s' is not a valid user id", id)
+			}
+		}
+		p.User.UserIDs = cleaned
+
+	case PropertyTypeChannel:
+		if p.Channel == nil {
+			p.Channel = &ChannelOption{}
+		}
+		if len(cleaned) == 0 {
+			p.Channel.ChannelID = ""
+			return nil
+		}
+		if !model.IsValidId(cleaned[0]) {
+			return invalid("'%s' is not a valid channel id", cleaned[0])
+		}
+		p.Channel.ChannelID = cleaned[0]
+
+	case PropertyTypeURL:
+		if p.URL == nil {
+			p.URL = &URLOption{}
+		}
+		if len(cleaned) == 0 {
+			p.URL.Value = ""
+			return nil
+		}
+		parsed, err := url.ParseRequestURI(cleaned[0])
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return invalid("'%s' is not a valid http or https URL", cleaned[0])
+		}
+		p.URL.Value = cleaned[0]
+
+	default:
+		if p.Treetext.IsMultiselect {
+			p.Treetext.Values = cleaned
+			return nil
+		}
+		p.Treetext.Value = ""
+		if len(cleaned) == 1 {
+			p.Treetext.Value = cleaned[0]
+		}
+	}
+
+	return nil
+}
+
+// FormatValue renders the value of the property for humans. displayName is used to render the
+// IDs of user and channel properties.
+func (p PropertylistItem) FormatValue(displayName func(propertyType, id string) string) string {
+	values := p.Values()
+
+	switch p.Type {
+	case PropertyTypeSelection:
+		var formatted []string
+		for _, id := range values {
+			for _, item := range p.Selection.Items {
+				if item.ID == id {
+					formatted = append(formatted, item.Value)
+				}
+			}
+		}
+		return strings.Join(formatted, ", ")
+
+	case PropertyTypeNumber:
+		if len(values) == 0 {
+			return ""
+		}
+		if p.Number.Unit != "" {
+			return values[0] + " " + p.Number.Unit
+		}
+		return values[0]
+
+	case PropertyTypeDatetime:
+		if len(values) == 0 {
+			return ""
+		}
+		return time.Unix(0, p.Datetime.Value*int64(time.Millisecond)).UTC().Format("2006-01-02 15:04 MST")
+
+	case PropertyTypeUser, PropertyTypeChannel:
+		formatted := make([]string, 0, len(values))
+		for _, id := range values {
+			formatted = append(formatted, displayName(p.Type, id))
+		}
+		return strings.Join(formatted, ", ")
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func (p PropertylistItem) hasSelectionItem(id string) bool {
+	for _, item := range p.Selection.Items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDatetime(value string) (int64, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return millis, nil
+	}
+
+	for _, layout := range datetimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UnixNano() / int64(time.Millisecond), nil
+		}
+	}
+
+	return 0, errors.Errorf("unable to parse '%s'", value)
+}
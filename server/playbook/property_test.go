@@ -0,0 +1,218 @@
+package playbook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertylistItem_SetValues(t *testing.T) {
+	min, max := 0.0, 10.0
+	userID1 := "aaaaaaaaaaaaaaaaaaaaaaaaaa"
+	userID2 := "bbbbbbbbbbbbbbbbbbbbbbbbbb"
+
+	tests := []struct {
+		name     string
+		property PropertylistItem
+		values   []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "freetext",
+			property: PropertylistItem{Title: "Summary", Type: PropertyTypeFreetext},
+			values:   []string{" some text "},
+			expected: []string{"some text"},
+		},
+		{
+			name:     "freetext with several values",
+			property: PropertylistItem{Title: "Summary", Type: PropertyTypeFreetext},
+			values:   []string{"one", "two"},
+			wantErr:  true,
+		},
+		{
+			name:     "multiselect freetext",
+			property: PropertylistItem{Title: "Tags", Type: PropertyTypeFreetext, Treetext: TextOption{IsMultiselect: true}},
+			values:   []string{"one", "", "two"},
+			expected: []string{"one", "two"},
+		},
+		{
+			name: "selection",
+			property: PropertylistItem{Title: "Severity", Type: PropertyTypeSelection, Selection: Selectionlist{
+				Items: []SelectionlistItem{{ID: "1", Value: "Low"}, {ID: "2", Value: "High"}},
+			}},
+			values:   []string{"2"},
+			expected: []string{"2"},
+		},
+		{
+			name: "selection with an unknown option",
+			property: PropertylistItem{Title: "Severity", Type: PropertyTypeSelection, Selection: Selectionlist{
+				Items: []SelectionlistItem{{ID: "1", Value: "Low"}},
+			}},
+			values:  []string{"3"},
+			wantErr: true,
+		},
+		{
+			name:     "number with unit",
+			property: PropertylistItem{Title: "Impact", Type: PropertyTypeNumber, Number: &NumberOption{Unit: "%", Min: &min, Max: &max}},
+			values:   []string{"7.5 %"},
+			expected: []string{"7.5"},
+		},
+		{
+			name:     "number out of range",
+			property: PropertylistItem{Title: "Impact", Type: PropertyTypeNumber, Number: &NumberOption{Min: &min, Max: &max}},
+			values:   []string{"11"},
+			wantErr:  true,
+		},
+		{
+			name:     "not a number",
+			property: PropertylistItem{Title: "Impact", Type: PropertyTypeNumber},
+			values:   []string{"many"},
+			wantErr:  true,
+		},
+		{
+			name:     "datetime as a date",
+			property: PropertylistItem{Title: "Detected", Type: PropertyTypeDatetime},
+			values:   []string{"2020-01-02"},
+			expected: []string{"1577923200000"},
+		},
+		{
+			name:     "datetime as RFC3339",
+			property: PropertylistItem{Title: "Detected", Type: PropertyTypeDatetime},
+			values:   []string{"2020-01-02T00:00:00Z"},
+			expected: []string{"1577923200000"},
+		},
+		{
+			name:     "invalid datetime",
+			property: PropertylistItem{Title: "Detected", Type: PropertyTypeDatetime},
+			values:   []string{"yesterday"},
+			wantErr:  true,
+		},
+		{
+			name:     "multiselect users",
+			property: PropertylistItem{Title: "Responders", Type: PropertyTypeUser, User: &UserOption{IsMultiselect: true}},
+			values:   []string{userID1, userID2},
+			expected: []string{userID1, userID2},
+		},
+		{
+			name:     "several users on a single user property",
+			property: PropertylistItem{Title: "Owner", Type: PropertyTypeUser},
+			values:   []string{userID1, userID2},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid channel id",
+			property: PropertylistItem{Title: "War room", Type: PropertyTypeChannel},
+			values:   []string{"town-square"},
+			wantErr:  true,
+		},
+		{
+			name:     "url",
+			property: PropertylistItem{Title: "Dashboard", Type: PropertyTypeURL},
+			values:   []string{"https://example.com/dashboard"},
+			expected: []string{"https://example.com/dashboard"},
+		},
+		{
+			name:     "url without an http scheme",
+			property: PropertylistItem{Title: "Dashboard", Type: PropertyTypeURL},
+			values:   []string{"ftp://example.com"},
+			wantErr:  true,
+		},
+		{
+			name:     "clearing a number",
+			property: PropertylistItem{Title: "Impact", Type: PropertyTypeNumber},
+			values:   nil,
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.property.SetValues(tt.values)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, ErrInvalidPropertyValue))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.property.Values())
+		})
+	}
+}
+
+func TestPropertylistItem_FormatValue(t *testing.T) {
+	value := 42.0
+	displayName := func(propertyType, id string) string {
+		return propertyType + ":" + id
+	}
+
+	tests := []struct {
+		name     string
+		property PropertylistItem
+		expected string
+	}{
+		{
+			name: "multiselect selection",
+			property: PropertylistItem{Type: PropertyTypeSelection, Selection: Selectionlist{
+				Items:       []SelectionlistItem{{ID: "1", Value: "Web"}, {ID: "2", Value: "Mobile"}},
+				SelectedIDs: []string{"1", "2"},
+			}},
+			expected: "Web, Mobile",
+		},
+		{
+			name:     "number with unit",
+			property: PropertylistItem{Type: PropertyTypeNumber, Number: &NumberOption{Value: &value, Unit: "users"}},
+			expected: "42 users",
+		},
+		{
+			name:     "datetime",
+			property: PropertylistItem{Type: PropertyTypeDatetime, Datetime: &DatetimeOption{Value: 1577923200000}},
+			expected: "2020-01-02 00:00 UTC",
+		},
+		{
+			name:     "users",
+			property: PropertylistItem{Type: PropertyTypeUser, User: &UserOption{UserIDs: []string{"a", "b"}}},
+			expected: "User:a, User:b",
+		},
+		{
+			name:     "unset channel",
+			property: PropertylistItem{Type: PropertyTypeChannel},
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.property.FormatValue(displayName))
+		})
+	}
+}
+
+func TestPropertylist_UnmarshalLegacyJSON(t *testing.T) {
+	legacy := `{
+		"id": "list",
+		"title": "Properties",
+		"items": [
+			{"id": "1", "title": "Platforms", "type": "Selection", "selection": {"items": [{"id": "1", "value": "Web"}, {"id": "2", "value": "Mobile"}], "is_multiselect": true, "selected_id": "1,2"}},
+			{"id": "2", "title": "Tags", "type": "Freetext", "freetext": {"value": "db, network", "is_multiselect": true}},
+			{"id": "3", "title": "Summary", "type": "Freetext", "freetext": {"value": "a, b", "is_multiselect": false}}
+		]
+	}`
+
+	var propertylist Propertylist
+	require.NoError(t, json.Unmarshal([]byte(legacy), &propertylist))
+
+	require.Equal(t, []string{"1", "2"}, propertylist.Items[0].Selection.SelectedIDs)
+	require.Equal(t, []string{"db", "network"}, propertylist.Items[1].Treetext.Values)
+	require.Empty(t, propertylist.Items[1].Treetext.Value)
+	require.Equal(t, "a, b", propertylist.Items[2].Treetext.Value)
+
+	// Once converted, the legacy fields are not written back.
+	data, err := json.Marshal(propertylist)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "selected_id\"")
+
+	var roundTripped Propertylist
+	require.NoError(t, json.Unmarshal(data, &roundTripped))
+	require.Equal(t, propertylist, roundTripped)
+}
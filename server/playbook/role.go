@@ -0,0 +1,231 @@
+package playbook
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// Role is the role of a user, or of the users of a group, in a playbook.
+type Role string
+
+const (
+	// RoleOwner can do everything an editor can, manage the members and delete the playbook.
+	RoleOwner Role = "owner"
+
+	// RoleEditor can do everything a runner can and edit the playbook.
+	RoleEditor Role = "editor"
+
+	// RoleRunner can do everything a viewer can and start incidents from the playbook.
+	RoleRunner Role = "runner"
+
+	// RoleViewer can view the playbook.
+	RoleViewer Role = "viewer"
+)
+
+// roleRanks orders the roles, each one granting everything the lower ones do.
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleRunner: 2,
+	RoleEditor: 3,
+	RoleOwner:  4,
+}
+
+// IsValid returns true if the role is one of the Role constants.
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast returns true if the role grants everything the other one does. No role, the empty
+// string, grants nothing.
+func (r Role) AtLeast(other Role) bool {
+	return roleRanks[r] > 0 && roleRanks[r] >= roleRanks[other]
+}
+
+// RolesAtLeast returns the roles granting everything the given one does.
+func RolesAtLeast(role Role) []string {
+	var roles []string
+	for _, r := range []Role{RoleOwner, RoleEditor, RoleRunner, RoleViewer} {
+		if r.AtLeast(role) {
+			roles = append(roles, string(r))
+		}
+	}
+	return roles
+}
+
+// Member is a user with a role in a playbook.
+type Member struct {
+	UserID string `json:"user_id"`
+	Role   Role   `json:"role"`
+}
+
+// GroupMember is a group whose users all have a role in a playbook.
+type GroupMember struct {
+	GroupID string `json:"group_id"`
+	Role    Role   `json:"role"`
+}
+
+// AllMembers returns the members of the playbook with their roles. When MemberIDs is not empty,
+// it lists the members, whose roles are given by Members; the ones missing from Members are
+// editors, as every member was before roles existed. Otherwise Members lists them.
+func (p Playbook) AllMembers() []Member {
+	roles := make(map[string]Role, len(p.Members))
+	members := make([]Member, 0, len(p.Members))
+	for _, m := range p.Members {
+		if _, ok := roles[m.UserID]; ok {
+			continue
+		}
+		roles[m.UserID] = m.Role
+		members = append(members, m)
+	}
+
+	if len(p.MemberIDs) == 0 {
+		return members
+	}
+
+	members = make([]Member, 0, len(p.MemberIDs))
+	seen := make(map[string]bool, len(p.MemberIDs))
+	for _, userID := range p.MemberIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		role, ok := roles[userID]
+		if !ok {
+			role = RoleEditor
+		}
+		members = append(members, Member{UserID: userID, Role: role})
+	}
+	return members
+}
+
+// SetMembers sets both the members and their roles.
+func (p *Playbook) SetMembers(members []Member) {
+	p.Members = nil
+	p.MemberIDs = nil
+	for _, m := range members {
+		p.Members = append(p.Members, m)
+		p.MemberIDs = append(p.MemberIDs, m.UserID)
+	}
+}
+
+// RoleOf returns the highest role the user has in the playbook, directly or through one of the
+// given groups the user is in, or the empty string if the user has none.
+func (p Playbook) RoleOf(userID string, groupIDs []string) Role {
+	var role Role
+	for _, m := range p.AllMembers() {
+		if m.UserID == userID && !role.AtLeast(m.Role) {
+			role = m.Role
+		}
+	}
+	for _, g := range p.Groups {
+		for _, groupID := range groupIDs {
+			if g.GroupID == groupID && !role.AtLeast(g.Role) {
+				role = g.Role
+			}
+		}
+	}
+	return role
+}
+
+// HasOwner returns true if a user or a group owns the playbook. Playbooks created before roles
+// existed have none, and are then managed by their editors.
+func (p Playbook) HasOwner() bool {
+	for _, m := range p.AllMembers() {
+		if m.Role == RoleOwner {
+			return true
+		}
+	}
+	for _, g := range p.Groups {
+		if g.Role == RoleOwner {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRoles checks that every member and group of the playbook has a valid role.
+func (p Playbook) ValidateRoles() error {
+	for _, m := range p.Members {
+		if !m.Role.IsValid() {
+			return errors.Errorf("invalid role '%s' for member '%s'", m.Role, m.UserID)
+		}
+	}
+	for _, g := range p.Groups {
+		if !g.Role.IsValid() {
+			return errors.Errorf("invalid role '%s' for group '%s'", g.Role, g.GroupID)
+		}
+	}
+	return nil
+}
+
+// RoleChange is a change of the role of a user or a group in a playbook. The old or the new role
+// is empty when the user or group is added or removed.
+type RoleChange struct {
+	UserID  string `json:"user_id,omitempty"`
+	GroupID string `json:"group_id,omitempty"`
+	OldRole Role   `json:"old_role"`
+	NewRole Role   `json:"new_role"`
+}
+
+// RoleChanges returns the changes of roles between the old and the new version of a playbook.
+func RoleChanges(oldPlaybook, newPlaybook Playbook) []RoleChange {
+	var changes []RoleChange
+
+	oldMembers := make(map[string]Role)
+	for _, m := range oldPlaybook.AllMembers() {
+		oldMembers[m.UserID] = m.Role
+	}
+	newMembers := make(map[string]Role)
+	for _, m := range newPlaybook.AllMembers() {
+		newMembers[m.UserID] = m.Role
+		if oldMembers[m.UserID] != m.Role {
+			changes = append(changes, RoleChange{UserID: m.UserID, OldRole: oldMembers[m.UserID], NewRole: m.Role})
+		}
+	}
+	for _, m := range oldPlaybook.AllMembers() {
+		if _, ok := newMembers[m.UserID]; !ok {
+			changes = append(changes, RoleChange{UserID: m.UserID, OldRole: m.Role})
+		}
+	}
+
+	oldGroups := make(map[string]Role)
+	for _, g := range oldPlaybook.Groups {
+		oldGroups[g.GroupID] = g.Role
+	}
+	newGroups := make(map[string]Role)
+	for _, g := range newPlaybook.Groups {
+		newGroups[g.GroupID] = g.Role
+		if oldGroups[g.GroupID] != g.Role {
+			changes = append(changes, RoleChange{GroupID: g.GroupID, OldRole: oldGroups[g.GroupID], NewRole: g.Role})
+		}
+	}
+	for _, g := range oldPlaybook.Groups {
+		if _, ok := newGroups[g.GroupID]; !ok {
+			changes = append(changes, RoleChange{GroupID: g.GroupID, OldRole: g.Role})
+		}
+	}
+
+	return changes
+}
+
+// RoleOfUser returns the highest role of the user in the playbook, directly or through the user's
+// groups. The groups are only looked up if the playbook has some.
+func RoleOfUser(thePlaybook Playbook, userID string, pluginAPI *pluginapi.Client, log bot.Logger) Role {
+	var groupIDs []string
+	if len(thePlaybook.Groups) > 0 {
+		groups, err := pluginAPI.Group.ListForUser(userID)
+		if err != nil {
+			log.Warnf("failed to get the groups of user %s: %v", userID, err)
+		}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.Id)
+		}
+	}
+
+	return thePlaybook.RoleOf(userID, groupIDs)
+}
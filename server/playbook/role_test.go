@@ -0,0 +1,72 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoles(t *testing.T) {
+	t.Run("at least", func(t *testing.T) {
+		require.True(t, RoleOwner.AtLeast(RoleEditor))
+		require.True(t, RoleRunner.AtLeast(RoleRunner))
+		require.False(t, RoleViewer.AtLeast(RoleRunner))
+		require.False(t, Role("").AtLeast(RoleViewer))
+		require.False(t, Role("admin").AtLeast(RoleViewer))
+		require.Equal(t, []string{"owner", "editor", "runner"}, RolesAtLeast(RoleRunner))
+	})
+
+	t.Run("all members", func(t *testing.T) {
+		legacy := Playbook{MemberIDs: []string{"alice", "bob"}}
+		require.Equal(t, []Member{{"alice", RoleEditor}, {"bob", RoleEditor}}, legacy.AllMembers())
+
+		withRoles := Playbook{
+			MemberIDs: []string{"alice", "bob"},
+			Members:   []Member{{"bob", RoleViewer}, {"charlie", RoleOwner}},
+		}
+		require.Equal(t, []Member{{"alice", RoleEditor}, {"bob", RoleViewer}}, withRoles.AllMembers())
+
+		membersOnly := Playbook{Members: []Member{{"charlie", RoleOwner}}}
+		require.Equal(t, []Member{{"charlie", RoleOwner}}, membersOnly.AllMembers())
+	})
+
+	t.Run("role of", func(t *testing.T) {
+		p := Playbook{
+			Members: []Member{{"alice", RoleViewer}, {"bob", RoleOwner}},
+			Groups:  []GroupMember{{"group1", RoleRunner}, {"group2", RoleEditor}},
+		}
+		require.Equal(t, RoleViewer, p.RoleOf("alice", nil))
+		require.Equal(t, RoleEditor, p.RoleOf("alice", []string{"group1", "group2"}))
+		require.Equal(t, RoleOwner, p.RoleOf("bob", []string{"group1"}))
+		require.Equal(t, RoleRunner, p.RoleOf("charlie", []string{"group1"}))
+		require.Equal(t, Role(""), p.RoleOf("dave", []string{"group3"}))
+		require.True(t, p.HasOwner())
+		require.False(t, Playbook{MemberIDs: []string{"alice"}}.HasOwner())
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		require.NoError(t, Playbook{Members: []Member{{"alice", RoleRunner}}}.ValidateRoles())
+		require.Error(t, Playbook{Members: []Member{{"alice", "admin"}}}.ValidateRoles())
+		require.Error(t, Playbook{Groups: []GroupMember{{"group1", ""}}}.ValidateRoles())
+	})
+
+	t.Run("role changes", func(t *testing.T) {
+		oldPlaybook := Playbook{
+			MemberIDs: []string{"alice", "bob"},
+			Groups:    []GroupMember{{"group1", RoleRunner}},
+		}
+		newPlaybook := Playbook{
+			Members: []Member{{"alice", RoleOwner}, {"charlie", RoleViewer}},
+			Groups:  []GroupMember{{"group2", RoleViewer}},
+		}
+
+		require.Empty(t, RoleChanges(oldPlaybook, oldPlaybook))
+		require.Equal(t, []RoleChange{
+			{UserID: "alice", OldRole: RoleEditor, NewRole: RoleOwner},
+			{UserID: "charlie", NewRole: RoleViewer},
+			{UserID: "bob", OldRole: RoleEditor},
+			{GroupID: "group2", NewRole: RoleViewer},
+			{GroupID: "group1", OldRole: RoleRunner},
+		}, RoleChanges(oldPlaybook, newPlaybook))
+	})
+}
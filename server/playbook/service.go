@@ -1,35 +1,93 @@
 package playbook
 
 import (
+	"encoding/json"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
 
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 )
 
 type service struct {
 	store     Store
 	poster    bot.Poster
+	log       bot.Logger
+	validator *validator
 	telemetry Telemetry
+	auditor   audit.Service
+
+	// source is where the changes recorded in the audit log come from.
+	source audit.Source
 }
 
-// NewService returns a new playbook service
-func NewService(store Store, poster bot.Poster, telemetry Telemetry) Service {
+// NewService returns a new playbook service. The bot must be able to post in the broadcast
+// channels of the playbooks.
+func NewService(store Store, poster bot.Poster, log bot.Logger, pluginAPI *pluginapi.Client, botUserID string, telemetry Telemetry, auditor audit.Service) Service {
 	return &service{
-		store:     store,
-		poster:    poster,
+		store:  store,
+		poster: poster,
+		log:    log,
+		validator: &validator{
+			pluginAPI: pluginAPI,
+			botUserID: botUserID,
+		},
 		telemetry: telemetry,
+		auditor:   auditor,
+		source:    audit.SourceSystem,
 	}
 }
 
+func (s *service) WithAuditSource(source audit.Source) Service {
+	scoped := *s
+	scoped.source = source
+	return &scoped
+}
+
+// recordChange records in the audit log the change made by userID to a playbook, given its
+// snapshots before and after the change, nil before its creation or after its deletion.
+func (s *service) recordChange(userID, action string, playbook Playbook, before, after json.RawMessage) {
+	s.auditor.Record(audit.Record{
+		ActorUserID: userID,
+		Source:      s.source,
+		TargetType:  audit.TargetPlaybook,
+		TargetID:    playbook.ID,
+		TeamID:      playbook.TeamID,
+		Action:      action,
+		Changes:     audit.Diff(before, after),
+	})
+}
+
 func (s *service) Create(playbook Playbook, userID string) (string, error) {
+	return s.create(playbook, userID, "create_playbook")
+}
+
+// create creates a playbook, recording it in the audit log under the given action.
+func (s *service) create(playbook Playbook, userID, action string) (string, error) {
 	playbook.CreateAt = model.GetMillis()
+	playbook.Version = 1
+
+	// The user creating a playbook owns it, unless an owner is given.
+	if !playbook.HasOwner() {
+		makeOwner(&playbook, userID)
+	}
+
+	if result := s.Validate(playbook); !result.IsValid() {
+		return "", &ValidationError{Result: result}
+	}
+
+	if err := s.checkExternalID(playbook); err != nil {
+		return "", err
+	}
 
 	newID, err := s.store.Create(playbook)
 	if err != nil {
 		return "", err
 	}
 	playbook.ID = newID
+	s.recordChange(userID, action, playbook, nil, audit.Snapshot(playbook))
 
 	s.telemetry.CreatePlaybook(playbook, userID)
 
@@ -49,15 +107,140 @@ func (s *service) GetPlaybooksForTeam(requesterInfo RequesterInfo, teamID string
 }
 
 func (s *service) Update(playbook Playbook, userID string) error {
+	oldPlaybook, err := s.store.Get(playbook.ID)
+	if err != nil {
+		return err
+	}
+	playbook.Version = oldPlaybook.Version + 1
+	playbook.ForkedFromID = oldPlaybook.ForkedFromID
+	playbook.ForkedFromVersion = oldPlaybook.ForkedFromVersion
+
+	if result := s.Validate(playbook); !result.IsValid() {
+		return &ValidationError{Result: result}
+	}
+
+	if err := s.checkExternalID(playbook); err != nil {
+		return err
+	}
+
 	if err := s.store.Update(playbook); err != nil {
 		return err
 	}
+	s.recordChange(userID, "update_playbook", playbook, audit.Snapshot(oldPlaybook), audit.Snapshot(playbook))
 
 	s.telemetry.UpdatePlaybook(playbook, userID)
 
 	return nil
 }
 
+// checkExternalID returns ErrDuplicateExternalID if another playbook of the team has the external
+// ID of the playbook.
+func (s *service) checkExternalID(playbook Playbook) error {
+	if playbook.ExternalID == "" {
+		return nil
+	}
+
+	id, err := s.store.GetPlaybookIDForExternalID(playbook.TeamID, playbook.ExternalID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if id != playbook.ID {
+		return errors.Wrapf(ErrDuplicateExternalID, "playbook %s of the team already has the external ID '%s'", id, playbook.ExternalID)
+	}
+
+	return nil
+}
+
+func (s *service) Validate(playbook Playbook) ValidationResult {
+	return s.validator.validate(playbook)
+}
+
+func (s *service) Fork(playbook Playbook, teamID, userID string) (string, error) {
+	if playbook.ID == "" {
+		return "", errors.New("can't fork a playbook without an ID")
+	}
+
+	fork := playbook.Clone()
+	fork.ID = ""
+	fork.TeamID = teamID
+	fork.SetMembers([]Member{{UserID: userID, Role: RoleOwner}})
+	fork.Groups = nil
+	fork.IsGlobal = false
+	fork.SharedTeamIDs = nil
+	fork.ForkedFromID = playbook.ID
+	fork.ForkedFromVersion = playbook.Version
+	fork.ExternalID = ""
+	fork.RegenerateIDs()
+	if teamID != playbook.TeamID {
+		fork.OnCallScheduleID = ""
+	}
+
+	return s.create(fork, userID, "fork_playbook")
+}
+
+func (s *service) Duplicate(playbook Playbook, teamID, title, userID string) (string, error) {
+	if playbook.ID == "" {
+		return "", errors.New("can't duplicate a playbook without an ID")
+	}
+
+	duplicate := playbook.Clone()
+	duplicate.ID = ""
+	duplicate.Title = title
+	duplicate.DeleteAt = 0
+	duplicate.IsGlobal = false
+	duplicate.SharedTeamIDs = nil
+	duplicate.ForkedFromID = ""
+	duplicate.ForkedFromVersion = 0
+	duplicate.ExternalID = ""
+	duplicate.RegenerateIDs()
+
+	// The members, the broadcast channel and the on-call schedule of a playbook only make sense
+	// in its own team.
+	if teamID != playbook.TeamID {
+		duplicate.TeamID = teamID
+		duplicate.SetMembers(nil)
+		duplicate.Groups = nil
+		duplicate.BroadcastChannelID = ""
+		duplicate.OnCallScheduleID = ""
+	}
+	makeOwner(&duplicate, userID)
+
+	return s.create(duplicate, userID, "duplicate_playbook")
+}
+
+func (s *service) InstallTemplate(name, teamID, userID string) (string, error) {
+	template, err := GetTemplate(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "no template named '%s'", name)
+	}
+
+	pbook := template.Playbook
+	pbook.TeamID = teamID
+	pbook.RegenerateIDs()
+	makeOwner(&pbook, userID)
+
+	return s.create(pbook, userID, "install_template")
+}
+
+// makeOwner makes the user an owner of the playbook, adding them as a member if needed.
+func makeOwner(playbook *Playbook, userID string) {
+	members := playbook.AllMembers()
+	found := false
+	for i := range members {
+		if members[i].UserID == userID {
+			members[i].Role = RoleOwner
+			found = true
+		}
+	}
+	if !found {
+		members = append(members, Member{UserID: userID, Role: RoleOwner})
+	}
+	playbook.SetMembers(members)
+}
+
 func (s *service) Delete(playbook Playbook, userID string) error {
 	if playbook.ID == "" {
 		return errors.New("can't delete a playbook without an ID")
@@ -66,6 +249,7 @@ func (s *service) Delete(playbook Playbook, userID string) error {
 	if err := s.store.Delete(playbook.ID); err != nil {
 		return err
 	}
+	s.recordChange(userID, "delete_playbook", playbook, audit.Snapshot(playbook), nil)
 
 	s.telemetry.DeletePlaybook(playbook, userID)
 
@@ -0,0 +1,67 @@
+package playbook_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	mock_audit "github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit/mocks"
+	mock_playbook "github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestFork(t *testing.T) {
+	controller := gomock.NewController(t)
+	store := mock_playbook.NewMockStore(controller)
+	auditService := mock_audit.NewMockService(controller)
+	auditService.EXPECT().Record(gomock.Any())
+
+	pluginAPI := &plugintest.API{}
+	pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "alice"}, nil)
+	pluginAPI.On("GetTeamMember", "team_id", "user_id").Return(&model.TeamMember{TeamId: "team_id", UserId: "user_id"}, nil)
+
+	s := playbook.NewService(store, nil, nil, pluginapi.NewClient(pluginAPI), "bot_id", &telemetry.NoopTelemetry{}, auditService)
+
+	source := playbook.Playbook{
+		ID:      "source_id",
+		Title:   "Playbook",
+		TeamID:  "team_id",
+		Version: 3,
+		Checklists: []playbook.Checklist{{
+			ID:    "checklist_id",
+			Title: "Triage",
+			Items: []playbook.ChecklistItem{{ID: "item_id", Title: "Page the team"}},
+		}},
+		Propertylist: playbook.Propertylist{
+			ID:    "propertylist_id",
+			Items: []playbook.PropertylistItem{{ID: "property_id", Title: "Impact"}},
+		},
+	}
+
+	var fork playbook.Playbook
+	store.EXPECT().Create(gomock.Any()).DoAndReturn(func(created playbook.Playbook) (string, error) {
+		fork = created
+		return "fork_id", nil
+	})
+
+	forkID, err := s.Fork(source, "team_id", "user_id")
+	require.NoError(t, err)
+	require.Equal(t, "fork_id", forkID)
+
+	require.Equal(t, "source_id", fork.ForkedFromID)
+	require.Equal(t, int64(3), fork.ForkedFromVersion)
+	require.Equal(t, "Page the team", fork.Checklists[0].Items[0].Title)
+	require.NotEqual(t, "checklist_id", fork.Checklists[0].ID)
+	require.NotEqual(t, "item_id", fork.Checklists[0].Items[0].ID)
+	require.NotEqual(t, "propertylist_id", fork.Propertylist.ID)
+	require.NotEqual(t, "property_id", fork.Propertylist.Items[0].ID)
+
+	require.Equal(t, "checklist_id", source.Checklists[0].ID)
+	require.Equal(t, "item_id", source.Checklists[0].Items[0].ID)
+}
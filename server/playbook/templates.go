@@ -0,0 +1,245 @@
+package playbook
+
+// Template is a starter playbook, ready to be installed in a team and adapted to it.
+type Template struct {
+	// Name identifies the template, e.g. in the /incident template command.
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Playbook    Playbook `json:"playbook"`
+}
+
+// templates is the library of starter playbooks. Their checklists and properties have no IDs,
+// these are given when a template is installed.
+var templates = []Template{
+	{
+		Name:        "outage",
+		Title:       "Service Outage",
+		Description: "Restore a degraded or unavailable service, keep customers informed and learn from it.",
+		Playbook: Playbook{
+			Title:                "Service Outage",
+			Description:          "Restore a degraded or unavailable service, keep customers informed and learn from it.",
+			CreatePublicIncident: true,
+			Checklists: []Checklist{
+				checklist("Triage",
+					"Acknowledge the alert",
+					"Assess the impact on customers",
+					"Set the severity",
+					"Page the on-call engineers of the affected services",
+				),
+				checklist("Mitigation",
+					"Check recent deployments and configuration changes",
+					"Roll back or fail over if possible",
+					"Post a notice on the status page",
+					"Confirm the service is restored",
+				),
+				checklist("Follow-up",
+					"Update the status page",
+					"Schedule a post-mortem",
+					"Record the post-mortem action items",
+					"Update this playbook with learnings",
+				),
+			},
+			Propertylist: Propertylist{
+				Title: "Outage",
+				Items: []PropertylistItem{
+					severityProperty(),
+					{Title: "Affected services", Type: PropertyTypeFreetext},
+					{Title: "Status page", Type: PropertyTypeURL, URL: &URLOption{}},
+				},
+			},
+			ExitCriteria: ExitCriteria{
+				RequiredProperties: []string{"Severity"},
+				RequiredChecklistItems: []ChecklistItemReference{
+					{Checklist: "Mitigation", Item: "Confirm the service is restored"},
+				},
+			},
+			ReminderTimerDefaultSeconds: 30 * 60,
+		},
+	},
+	{
+		Name:        "security-breach",
+		Title:       "Security Breach",
+		Description: "Contain a security incident, preserve the evidence and meet the disclosure obligations.",
+		Playbook: Playbook{
+			Title:                "Security Breach",
+			Description:          "Contain a security incident, preserve the evidence and meet the disclosure obligations.",
+			CreatePublicIncident: false,
+			Checklists: []Checklist{
+				checklist("Triage",
+					"Confirm the breach",
+					"Set the severity",
+					"Involve the security team",
+					"Restrict the incident to the people who need to know",
+				),
+				checklist("Containment",
+					"Isolate the affected systems",
+					"Rotate the exposed credentials and keys",
+					"Preserve logs and evidence",
+					"Block the attack vector",
+				),
+				checklist("Recovery",
+					"Identify the compromised data",
+					"Involve legal and compliance",
+					"Notify the affected customers and authorities as required",
+					"Restore the affected systems from trusted sources",
+				),
+				checklist("Follow-up",
+					"Schedule a post-mortem",
+					"Record the post-mortem action items",
+					"Update this playbook with learnings",
+				),
+			},
+			Propertylist: Propertylist{
+				Title: "Breach",
+				Items: []PropertylistItem{
+					severityProperty(),
+					{Title: "Attack vector", Type: PropertyTypeFreetext},
+					{Title: "Detected at", Type: PropertyTypeDatetime, Datetime: &DatetimeOption{}},
+				},
+			},
+			ExitCriteria: ExitCriteria{
+				RequiredProperties: []string{"Severity", "Attack vector"},
+				RequiredChecklistItems: []ChecklistItemReference{
+					{Checklist: "Containment", Item: "Rotate the exposed credentials and keys"},
+					{Checklist: "Recovery", Item: "Notify the affected customers and authorities as required"},
+				},
+			},
+			ReminderTimerDefaultSeconds: 60 * 60,
+		},
+	},
+	{
+		Name:        "data-loss",
+		Title:       "Data Loss",
+		Description: "Stop further loss, recover the data from backups and verify its integrity.",
+		Playbook: Playbook{
+			Title:                "Data Loss",
+			Description:          "Stop further loss, recover the data from backups and verify its integrity.",
+			CreatePublicIncident: true,
+			Checklists: []Checklist{
+				checklist("Triage",
+					"Stop the process causing the loss",
+					"Identify the affected data and customers",
+					"Set the severity",
+				),
+				checklist("Recovery",
+					"Find the latest usable backup",
+					"Restore the data to a separate environment",
+					"Verify the integrity of the restored data",
+					"Restore the data to production",
+				),
+				checklist("Follow-up",
+					"Inform the affected customers",
+					"Check the backup policies",
+					"Schedule a post-mortem",
+					"Update this playbook with learnings",
+				),
+			},
+			Propertylist: Propertylist{
+				Title: "Data Loss",
+				Items: []PropertylistItem{
+					severityProperty(),
+					{Title: "Affected customers", Type: PropertyTypeNumber, Number: &NumberOption{}},
+					{Title: "Data loss window", Type: PropertyTypeFreetext},
+				},
+			},
+			ExitCriteria: ExitCriteria{
+				RequiredProperties: []string{"Severity"},
+				RequiredChecklistItems: []ChecklistItemReference{
+					{Checklist: "Recovery", Item: "Verify the integrity of the restored data"},
+				},
+			},
+			ReminderTimerDefaultSeconds: 60 * 60,
+		},
+	},
+	{
+		Name:        "customer-escalation",
+		Title:       "Customer Escalation",
+		Description: "Handle an escalated customer issue across support, engineering and account management.",
+		Playbook: Playbook{
+			Title:                "Customer Escalation",
+			Description:          "Handle an escalated customer issue across support, engineering and account management.",
+			CreatePublicIncident: true,
+			Checklists: []Checklist{
+				checklist("Intake",
+					"Gather the customer's report and logs",
+					"Set the priority",
+					"Invite the account manager",
+					"Acknowledge the escalation to the customer",
+				),
+				checklist("Resolution",
+					"Reproduce the issue",
+					"Involve the engineering team",
+					"Share a workaround with the customer",
+					"Deliver the fix",
+				),
+				checklist("Closure",
+					"Confirm the resolution with the customer",
+					"Update the support ticket",
+					"Record the follow-up actions",
+				),
+			},
+			Propertylist: Propertylist{
+				Title: "Escalation",
+				Items: []PropertylistItem{
+					{
+						Title: "Priority",
+						Type:  PropertyTypeSelection,
+						Selection: Selectionlist{Items: []SelectionlistItem{
+							{Value: "Urgent"}, {Value: "High"}, {Value: "Normal"},
+						}},
+					},
+					{Title: "Customer", Type: PropertyTypeFreetext},
+					{Title: "Support ticket", Type: PropertyTypeURL, URL: &URLOption{}},
+				},
+			},
+			ExitCriteria: ExitCriteria{
+				RequiredProperties: []string{"Customer"},
+				RequiredChecklistItems: []ChecklistItemReference{
+					{Checklist: "Closure", Item: "Confirm the resolution with the customer"},
+				},
+			},
+			ReminderTimerDefaultSeconds: 24 * 60 * 60,
+		},
+	},
+}
+
+// Templates returns the library of starter playbooks.
+func Templates() []Template {
+	result := make([]Template, 0, len(templates))
+	for _, t := range templates {
+		t.Playbook = t.Playbook.Clone()
+		result = append(result, t)
+	}
+	return result
+}
+
+// GetTemplate returns the starter playbook with the given name. Returns ErrNotFound if there is
+// none.
+func GetTemplate(name string) (Template, error) {
+	for _, t := range templates {
+		if t.Name == name {
+			t.Playbook = t.Playbook.Clone()
+			return t, nil
+		}
+	}
+	return Template{}, ErrNotFound
+}
+
+func checklist(title string, items ...string) Checklist {
+	c := Checklist{Title: title}
+	for _, item := range items {
+		c.Items = append(c.Items, ChecklistItem{Title: item, State: ChecklistItemStateOpen})
+	}
+	return c
+}
+
+func severityProperty() PropertylistItem {
+	return PropertylistItem{
+		Title: "Severity",
+		Type:  PropertyTypeSelection,
+		Selection: Selectionlist{Items: []SelectionlistItem{
+			{Value: "SEV1"}, {Value: "SEV2"}, {Value: "SEV3"}, {Value: "SEV4"},
+		}},
+	}
+}
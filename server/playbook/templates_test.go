@@ -0,0 +1,31 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	names := make(map[string]bool)
+	for _, template := range Templates() {
+		require.False(t, names[template.Name], "duplicate template name %s", template.Name)
+		names[template.Name] = true
+
+		require.NotEmpty(t, template.Playbook.Checklists)
+		require.NoError(t, template.Playbook.ExitCriteria.Validate(template.Playbook.Checklists, template.Playbook.Propertylist), template.Name)
+	}
+
+	template, err := GetTemplate("outage")
+	require.NoError(t, err)
+	require.Equal(t, "Service Outage", template.Title)
+
+	// The library can't be changed through the templates it returns.
+	template.Playbook.Checklists[0].Title = "Changed"
+	template, err = GetTemplate("outage")
+	require.NoError(t, err)
+	require.Equal(t, "Triage", template.Playbook.Checklists[0].Title)
+
+	_, err = GetTemplate("unknown")
+	require.Equal(t, ErrNotFound, err)
+}
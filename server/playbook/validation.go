@@ -0,0 +1,217 @@
+package playbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// externalIDPattern is what external IDs look like: they end up in file names and URLs.
+var externalIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,127}$`)
+
+// ErrInvalidPlaybook is used to indicate a playbook cannot be saved because of validation errors.
+var ErrInvalidPlaybook = errors.New("invalid playbook")
+
+// ValidationIssue is a problem found in a playbook. Field is the JSON path of the offending field,
+// e.g. checklists[0].items[2].command.
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationResult lists the problems found in a playbook. Errors prevent the playbook from being
+// saved, warnings don't.
+type ValidationResult struct {
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// IsValid returns true if there is no error.
+func (r ValidationResult) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *ValidationResult) addError(field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationResult) addWarning(field, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// MarshalJSON customizes the JSON marshalling for ValidationResult by rendering nil slices as
+// empty ones instead.
+func (r ValidationResult) MarshalJSON() ([]byte, error) {
+	type Alias ValidationResult
+
+	if r.Errors == nil {
+		r.Errors = []ValidationIssue{}
+	}
+	if r.Warnings == nil {
+		r.Warnings = []ValidationIssue{}
+	}
+
+	return json.Marshal(Alias(r))
+}
+
+// ValidationError is returned when saving a playbook that has validation errors.
+type ValidationError struct {
+	Result ValidationResult
+}
+
+func (e *ValidationError) Error() string {
+	var issues []string
+	for _, issue := range e.Result.Errors {
+		issues = append(issues, issue.Field+": "+issue.Message)
+	}
+	return ErrInvalidPlaybook.Error() + ": " + strings.Join(issues, "; ")
+}
+
+// Unwrap allows errors.Is to match ErrInvalidPlaybook.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidPlaybook
+}
+
+// validator checks playbooks, both on their own and against the state of the server: the slash
+// commands, the broadcast channel and the members they reference.
+type validator struct {
+	pluginAPI *pluginapi.Client
+	botUserID string
+}
+
+func (v *validator) validate(playbook Playbook) ValidationResult {
+	result := validateContent(playbook)
+	if playbook.TeamID == "" {
+		return result
+	}
+
+	v.validateCommands(playbook, &result)
+	v.validateBroadcastChannel(playbook, &result)
+	v.validateMembers(playbook, &result)
+
+	return result
+}
+
+// validateContent runs the checks that don't depend on the state of the server.
+func validateContent(playbook Playbook) ValidationResult {
+	var result ValidationResult
+
+	if strings.TrimSpace(playbook.Title) == "" {
+		result.addError("title", "a title is required")
+	}
+	if playbook.TeamID == "" {
+		result.addError("team_id", "a team is required")
+	}
+	if playbook.ExternalID != "" && !externalIDPattern.MatchString(playbook.ExternalID) {
+		result.addError("external_id", "'%s' is not a valid external ID: use up to 128 lowercase letters, digits, '.', '_' and '-', starting with a letter or a digit", playbook.ExternalID)
+	}
+
+	for i, property := range playbook.Propertylist.Items {
+		seen := make(map[string]bool, len(property.Selection.Items))
+		for j, option := range property.Selection.Items {
+			value := strings.ToLower(strings.TrimSpace(option.Value))
+			if seen[value] {
+				result.addError(fmt.Sprintf("propertylist.items[%d].selection.items[%d].value", i, j),
+					"property '%s' has several options '%s'", property.Title, option.Value)
+			}
+			seen[value] = true
+		}
+	}
+
+	if err := playbook.ExitCriteria.Validate(playbook.Checklists, playbook.Propertylist); err != nil {
+		result.addError("exit_criteria", err.Error())
+	}
+
+	if err := playbook.ValidateRoles(); err != nil {
+		result.addError("members", err.Error())
+	}
+
+	return result
+}
+
+func (v *validator) validateCommands(playbook Playbook, result *ValidationResult) {
+	var commands []*model.Command
+	listed := false
+
+	for i, checklist := range playbook.Checklists {
+		for j, item := range checklist.Items {
+			command := strings.TrimSpace(item.Command)
+			if command == "" {
+				continue
+			}
+
+			field := fmt.Sprintf("checklists[%d].items[%d].command", i, j)
+			if !strings.HasPrefix(command, "/") {
+				result.addError(field, "'%s' is not a slash command", item.Command)
+				continue
+			}
+
+			if !listed {
+				var err error
+				commands, err = v.pluginAPI.SlashCommand.List(playbook.TeamID)
+				if err != nil {
+					result.addWarning("checklists", "the slash commands could not be checked: %s", err.Error())
+					return
+				}
+				listed = true
+			}
+
+			trigger := strings.TrimPrefix(strings.Fields(command)[0], "/")
+			if !hasTrigger(commands, trigger) {
+				result.addError(field, "there is no slash command /%s in the team", trigger)
+			}
+		}
+	}
+}
+
+func hasTrigger(commands []*model.Command, trigger string) bool {
+	for _, command := range commands {
+		if command != nil && strings.EqualFold(command.Trigger, trigger) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateBroadcastChannel(playbook Playbook, result *ValidationResult) {
+	if playbook.BroadcastChannelID == "" {
+		return
+	}
+
+	channel, err := v.pluginAPI.Channel.Get(playbook.BroadcastChannelID)
+	if err != nil || channel.DeleteAt != 0 {
+		result.addError("broadcast_channel_id", "the broadcast channel does not exist")
+		return
+	}
+
+	if !v.pluginAPI.User.HasPermissionToChannel(v.botUserID, channel.Id, model.PERMISSION_CREATE_POST) {
+		result.addError("broadcast_channel_id", "the bot can't post in the broadcast channel ~%s", channel.Name)
+	}
+}
+
+func (v *validator) validateMembers(playbook Playbook, result *ValidationResult) {
+	for i, member := range playbook.AllMembers() {
+		field := fmt.Sprintf("members[%d]", i)
+
+		user, err := v.pluginAPI.User.Get(member.UserID)
+		if err != nil {
+			result.addError(field, "unknown user %s", member.UserID)
+			continue
+		}
+
+		if user.DeleteAt != 0 {
+			result.addWarning(field, "@%s is deactivated", user.Username)
+			continue
+		}
+
+		teamMember, err := v.pluginAPI.Team.GetMember(playbook.TeamID, member.UserID)
+		if err != nil || teamMember.DeleteAt != 0 {
+			result.addWarning(field, "@%s is not in the team of the playbook", user.Username)
+		}
+	}
+}
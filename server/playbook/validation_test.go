@@ -0,0 +1,79 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("content", func(t *testing.T) {
+		result := validateContent(Playbook{
+			Propertylist: Propertylist{Items: []PropertylistItem{{
+				Title: "Severity",
+				Type:  PropertyTypeSelection,
+				Selection: Selectionlist{Items: []SelectionlistItem{
+					{Value: "SEV1"}, {Value: "SEV2"}, {Value: "sev1 "},
+				}},
+			}}},
+			ExitCriteria: ExitCriteria{RequiredProperties: []string{"Impact"}},
+			Members:      []Member{{UserID: "user1", Role: "admin"}},
+			ExternalID:   "Incident Response",
+		})
+
+		require.False(t, result.IsValid())
+		require.Equal(t, []ValidationIssue{
+			{Field: "title", Message: "a title is required"},
+			{Field: "team_id", Message: "a team is required"},
+			{Field: "external_id", Message: "'Incident Response' is not a valid external ID: use up to 128 lowercase letters, digits, '.', '_' and '-', starting with a letter or a digit"},
+			{Field: "propertylist.items[0].selection.items[2].value", Message: "property 'Severity' has several options 'sev1 '"},
+			{Field: "exit_criteria", Message: "exit criteria: unknown property 'Impact'"},
+			{Field: "members", Message: "invalid role 'admin' for member 'user1'"},
+		}, result.Errors)
+	})
+
+	t.Run("server state", func(t *testing.T) {
+		pluginAPI := &plugintest.API{}
+		v := &validator{pluginAPI: pluginapi.NewClient(pluginAPI), botUserID: "botID"}
+
+		pluginAPI.On("ListCommands", "teamID").Return([]*model.Command{{Trigger: "jira"}, {Trigger: "echo"}}, nil)
+		pluginAPI.On("GetChannel", "channelID").Return(&model.Channel{Id: "channelID", Name: "town-square"}, nil)
+		pluginAPI.On("HasPermissionToChannel", "botID", "channelID", model.PERMISSION_CREATE_POST).Return(false)
+		pluginAPI.On("GetUser", "active").Return(&model.User{Id: "active", Username: "alice"}, nil)
+		pluginAPI.On("GetUser", "departed").Return(&model.User{Id: "departed", Username: "bob", DeleteAt: 1}, nil)
+		pluginAPI.On("GetUser", "elsewhere").Return(&model.User{Id: "elsewhere", Username: "charlie"}, nil)
+		pluginAPI.On("GetUser", "unknown").Return(nil, model.NewAppError("GetUser", "not_found", nil, "", 404))
+		pluginAPI.On("GetTeamMember", "teamID", "active").Return(&model.TeamMember{TeamId: "teamID", UserId: "active"}, nil)
+		pluginAPI.On("GetTeamMember", "teamID", "elsewhere").Return(&model.TeamMember{TeamId: "teamID", UserId: "elsewhere", DeleteAt: 1}, nil)
+
+		result := v.validate(Playbook{
+			Title:  "Playbook",
+			TeamID: "teamID",
+			Checklists: []Checklist{{
+				Title: "Triage",
+				Items: []ChecklistItem{
+					{Title: "Ticket", Command: "/jira create"},
+					{Title: "Page", Command: "/genie whoisoncall"},
+					{Title: "Echo", Command: "echo"},
+				},
+			}},
+			BroadcastChannelID: "channelID",
+			MemberIDs:          []string{"active", "departed", "elsewhere", "unknown"},
+		})
+
+		require.Equal(t, []ValidationIssue{
+			{Field: "checklists[0].items[1].command", Message: "there is no slash command /genie in the team"},
+			{Field: "checklists[0].items[2].command", Message: "'echo' is not a slash command"},
+			{Field: "broadcast_channel_id", Message: "the bot can't post in the broadcast channel ~town-square"},
+			{Field: "members[3]", Message: "unknown user unknown"},
+		}, result.Errors)
+		require.Equal(t, []ValidationIssue{
+			{Field: "members[1]", Message: "@bob is deactivated"},
+			{Field: "members[2]", Message: "@charlie is not in the team of the playbook"},
+		}, result.Warnings)
+	})
+}
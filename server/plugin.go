@@ -2,12 +2,16 @@ package main
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/api"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/command"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/metrics"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
@@ -37,7 +41,12 @@ type Plugin struct {
 	config          *config.ServiceImpl
 	incidentService incident.Service
 	playbookService playbook.Service
+	onCallService   oncall.Service
+	auditService    audit.Service
 	bot             *bot.Bot
+
+	auditRetentionJob *cluster.Job
+	outboxJob         *cluster.Job
 }
 
 // ServeHTTP demonstrates a plugin that handles HTTP requests by greeting the world.
@@ -82,6 +91,7 @@ func (p *Plugin) OnActivate() error {
 		playbook.Telemetry
 		Enable() error
 		Disable() error
+		QueueSize() int64
 	}
 
 	if rudderDataplaneURL == "" || rudderWriteKey == "" {
@@ -115,8 +125,10 @@ func (p *Plugin) OnActivate() error {
 	toggleTelemetry()
 	p.config.RegisterConfigChangeListener(toggleTelemetry)
 
+	pluginMetrics := metrics.New()
+
 	apiClient := sqlstore.NewClient(pluginAPIClient)
-	sqlStore, err := sqlstore.New(apiClient, p.bot)
+	sqlStore, err := sqlstore.New(apiClient, p.bot, pluginMetrics)
 	if err != nil {
 		return errors.Wrapf(err, "failed creating the SQL store")
 	}
@@ -133,22 +145,62 @@ func (p *Plugin) OnActivate() error {
 	}
 	mutex.Unlock()
 
-	incidentStore := sqlstore.NewIncidentStore(apiClient, p.bot, sqlStore)
+	incidentCache := sqlstore.NewIncidentCache(sqlstore.NewIncidentStore(apiClient, p.bot, sqlStore), apiClient, p.bot)
+	toggleIncidentCache := func() {
+		incidentCache.SetEnabled(p.config.GetConfiguration().EnableIncidentCache)
+	}
+	toggleIncidentCache()
+	p.config.RegisterConfigChangeListener(toggleIncidentCache)
+
 	playbookStore := sqlstore.NewPlaybookStore(apiClient, p.bot, sqlStore)
+	onCallStore := sqlstore.NewOnCallStore(apiClient, p.bot, sqlStore)
+	auditStore := sqlstore.NewAuditStore(apiClient, p.bot, sqlStore)
+
+	pluginMetrics.AddGaugeFunc("incidents", "Number of incidents, by status.", "status", func() (map[string]float64, error) {
+		counts, countErr := incidentCache.GetIncidentCountsByStatus()
+		if countErr != nil {
+			return nil, countErr
+		}
+
+		values := map[string]float64{
+			incident.StatusReported: 0,
+			incident.StatusActive:   0,
+			incident.StatusResolved: 0,
+			incident.StatusArchived: 0,
+		}
+		for status, count := range counts {
+			values[status] = float64(count)
+		}
+		return values, nil
+	})
+	pluginMetrics.AddGaugeFunc("telemetry_queue_size", "Number of telemetry events waiting to be sent.", "", func() (map[string]float64, error) {
+		return map[string]float64{"": float64(telemetryClient.QueueSize())}, nil
+	})
+	pluginMetrics.AddCounterFunc("incident_cache_hits_total", "Number of incident lookups served from the cache.", "", func() (map[string]float64, error) {
+		return map[string]float64{"": float64(incidentCache.Stats().Hits)}, nil
+	})
+	pluginMetrics.AddCounterFunc("incident_cache_misses_total", "Number of incident lookups served from the database.", "", func() (map[string]float64, error) {
+		return map[string]float64{"": float64(incidentCache.Stats().Misses)}, nil
+	})
 
 	p.handler = api.NewHandler()
+	p.handler.ObserveRequests(pluginMetrics)
 	p.bot = bot.New(pluginAPIClient, p.config.GetConfiguration().BotUserID, p.config)
 
 	scheduler := cluster.GetJobOnceScheduler(p.API)
 
+	p.auditService = audit.NewService(auditStore, p.bot, p.config)
+
 	p.incidentService = incident.NewService(
 		pluginAPIClient,
-		incidentStore,
+		incidentCache,
 		p.bot,
 		p.bot,
 		p.config,
 		scheduler,
 		telemetryClient,
+		p.auditService,
+		pluginMetrics,
 	)
 
 	if err = scheduler.SetCallback(p.incidentService.HandleReminder); err != nil {
@@ -158,18 +210,35 @@ func (p *Plugin) OnActivate() error {
 		pluginAPIClient.Log.Error("JobOnceScheduler could not start", "error", err.Error())
 	}
 
-	p.playbookService = playbook.NewService(playbookStore, p.bot, telemetryClient)
+	p.playbookService = playbook.NewService(playbookStore, p.bot, p.bot, pluginAPIClient, p.config.GetConfiguration().BotUserID, telemetryClient, p.auditService)
+
+	p.onCallService = oncall.NewService(onCallStore)
 
-	api.NewPlaybookHandler(p.handler.APIRouter, p.playbookService, pluginAPIClient, p.bot)
+	api.NewPlaybookHandler(p.handler.APIRouter, p.playbookService, p.onCallService, p.auditService, pluginAPIClient, p.bot)
+	api.NewOnCallHandler(p.handler.APIRouter, p.onCallService, pluginAPIClient, p.bot)
 	api.NewIncidentHandler(
 		p.handler.APIRouter,
 		p.incidentService,
 		p.playbookService,
+		p.onCallService,
 		pluginAPIClient,
 		p.bot,
 		p.bot,
 		telemetryClient,
+		p.auditService,
 	)
+	api.NewAuditHandler(p.handler.APIRouter, p.auditService, pluginAPIClient, p.bot)
+	api.NewMetricsHandler(p.handler.APIRouter, pluginMetrics, pluginAPIClient, p.bot)
+
+	p.auditRetentionJob, err = cluster.Schedule(p.API, "IR_AuditRetention", cluster.MakeWaitForInterval(24*time.Hour), p.deleteExpiredAuditRecords)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule the audit log retention job")
+	}
+
+	p.outboxJob, err = cluster.Schedule(p.API, "IR_Outbox", cluster.MakeWaitForInterval(time.Minute), p.processOutbox)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule the outbox job")
+	}
 
 	isTestingEnabled := false
 	flag := p.API.GetConfig().ServiceSettings.EnableTesting
@@ -191,6 +260,43 @@ func (p *Plugin) OnActivate() error {
 	return nil
 }
 
+// OnDeactivate Called when this plugin is deactivated.
+func (p *Plugin) OnDeactivate() error {
+	if p.auditRetentionJob != nil {
+		if err := p.auditRetentionJob.Close(); err != nil {
+			p.API.LogError("failed to close the audit log retention job", "error", err.Error())
+		}
+	}
+
+	if p.outboxJob != nil {
+		if err := p.outboxJob.Close(); err != nil {
+			p.API.LogError("failed to close the outbox job", "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// deleteExpiredAuditRecords deletes the audit records older than the configured retention period.
+func (p *Plugin) deleteExpiredAuditRecords() {
+	deleted, err := p.auditService.DeleteExpired()
+	if err != nil {
+		p.API.LogError("failed to delete the expired audit records", "error", err.Error())
+		return
+	}
+
+	if deleted > 0 {
+		p.API.LogInfo("Deleted the expired audit records", "count", deleted)
+	}
+}
+
+// processOutbox retries the side effects of incident changes that failed or were left behind.
+func (p *Plugin) processOutbox() {
+	if err := p.incidentService.ProcessOutbox(); err != nil {
+		p.API.LogError("failed to process the outbox", "error", err.Error())
+	}
+}
+
 // OnConfigurationChange handles any change in the configuration.
 func (p *Plugin) OnConfigurationChange() error {
 	if p.config == nil {
@@ -202,7 +308,10 @@ func (p *Plugin) OnConfigurationChange() error {
 
 // ExecuteCommand executes a command that has been previously registered via the RegisterCommand.
 func (p *Plugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
-	runner := command.NewCommandRunner(c, args, pluginapi.NewClient(p.API), p.bot, p.bot, p.incidentService, p.playbookService)
+	runner := command.NewCommandRunner(c, args, pluginapi.NewClient(p.API), p.bot, p.bot,
+		p.incidentService.WithAuditSource(audit.SourceSlashCommand),
+		p.playbookService.WithAuditSource(audit.SourceSlashCommand),
+		p.onCallService)
 
 	if err := runner.Execute(); err != nil {
 		return nil, model.NewAppError("IncidentCollaborationPlugin.ExecuteCommand", "Unable to execute command.", nil, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,173 @@
+package sqlstore
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/pkg/errors"
+)
+
+type sqlAuditRecord struct {
+	audit.Record
+	ChangesJSON json.RawMessage
+}
+
+// auditStore is a sql store for the audit log. Use NewAuditStore to create it.
+type auditStore struct {
+	pluginAPI    PluginAPIClient
+	log          bot.Logger
+	store        *SQLStore
+	recordSelect sq.SelectBuilder
+}
+
+// Ensure auditStore implements the audit.Store interface.
+var _ audit.Store = (*auditStore)(nil)
+
+// auditKeyset sorts the records from the most recent to the oldest.
+var auditKeyset = keyset{column: "CreateAt", idColumn: "ID", numeric: true, desc: true}
+
+// NewAuditStore creates a new store for the audit service.
+func NewAuditStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLStore) audit.Store {
+	recordSelect := sqlStore.builder.
+		Select("ID", "CreateAt", "ActorUserID", "Source", "TargetType", "TargetID", "TeamID",
+			"Action", "ChangesJSON", "Denied").
+		From("IR_AuditRecord")
+
+	return &auditStore{
+		pluginAPI:    pluginAPI,
+		log:          log,
+		store:        sqlStore,
+		recordSelect: recordSelect,
+	}
+}
+
+// Create stores a new record.
+func (s *auditStore) Create(record audit.Record) error {
+	if record.ID == "" {
+		return errors.New("ID should not be empty")
+	}
+
+	changesJSON, err := json.Marshal(record.Changes)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal changes json for audit record id: '%s'", record.ID)
+	}
+
+	_, err = s.store.execBuilder(s.store.db, sq.
+		Insert("IR_AuditRecord").
+		SetMap(map[string]interface{}{
+			"ID":          record.ID,
+			"CreateAt":    record.CreateAt,
+			"ActorUserID": record.ActorUserID,
+			"Source":      record.Source,
+			"TargetType":  record.TargetType,
+			"TargetID":    record.TargetID,
+			"TeamID":      record.TeamID,
+			"Action":      record.Action,
+			"ChangesJSON": changesJSON,
+			"Denied":      record.Denied,
+		}))
+	if err != nil {
+		return errors.Wrap(err, "failed to store new audit record")
+	}
+
+	return nil
+}
+
+// GetRecords gets a page of the records matching the validated options, from the most recent to
+// the oldest.
+func (s *auditStore) GetRecords(options audit.FilterOptions) (audit.GetRecordsResults, error) {
+	query := s.recordSelect.
+		OrderBy(auditKeyset.orderBy()...).
+		Limit(uint64(options.PerPage + 1))
+
+	filters := map[string]string{
+		"ActorUserID": options.ActorUserID,
+		"Source":      string(options.Source),
+		"TargetType":  string(options.TargetType),
+		"TargetID":    options.TargetID,
+		"TeamID":      options.TeamID,
+		"Action":      options.Action,
+	}
+	eq := sq.Eq{}
+	for column, value := range filters {
+		if value != "" {
+			eq[column] = value
+		}
+	}
+	if len(eq) > 0 {
+		query = query.Where(eq)
+	}
+	if options.DeniedOnly {
+		query = query.Where(sq.Eq{"Denied": true})
+	}
+	if options.Since > 0 {
+		query = query.Where(sq.GtOrEq{"CreateAt": options.Since})
+	}
+	if options.Until > 0 {
+		query = query.Where(sq.LtOrEq{"CreateAt": options.Until})
+	}
+
+	if options.Cursor != "" {
+		c, err := cursor.Decode(options.Cursor)
+		if err != nil {
+			return audit.GetRecordsResults{}, err
+		}
+		after, err := auditKeyset.after(c)
+		if err != nil {
+			return audit.GetRecordsResults{}, err
+		}
+		query = query.Where(after)
+	}
+
+	var rawRecords []sqlAuditRecord
+	if err := s.store.selectBuilder(s.store.db, &rawRecords, query); err != nil {
+		return audit.GetRecordsResults{}, errors.Wrap(err, "failed to query for audit records")
+	}
+
+	hasMore := len(rawRecords) > options.PerPage
+	if hasMore {
+		rawRecords = rawRecords[:options.PerPage]
+	}
+
+	records := make([]audit.Record, 0, len(rawRecords))
+	for _, rawRecord := range rawRecords {
+		record := rawRecord.Record
+		if len(rawRecord.ChangesJSON) > 0 {
+			if err := json.Unmarshal(rawRecord.ChangesJSON, &record.Changes); err != nil {
+				return audit.GetRecordsResults{}, errors.Wrapf(err, "failed to unmarshal changes json for audit record id: '%s'", record.ID)
+			}
+		}
+		records = append(records, record)
+	}
+
+	var nextCursor string
+	if hasMore {
+		nextCursor = audit.NextCursor(records[len(records)-1])
+	}
+
+	return audit.GetRecordsResults{
+		Items:      records,
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// DeleteBefore deletes the records created before createAt, and returns how many were deleted.
+func (s *auditStore) DeleteBefore(createAt int64) (int64, error) {
+	result, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_AuditRecord").
+		Where(sq.Lt{"CreateAt": createAt}))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete expired audit records")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count the deleted audit records")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/audit"
+	mock_sqlstore "github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore/mocks"
+)
+
+func TestAuditStore(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		auditStore := setupAuditStore(t, db)
+
+		incidentID := model.NewId()
+		playbookID := model.NewId()
+		userID := model.NewId()
+
+		records := []audit.Record{
+			{
+				ID:          model.NewId(),
+				CreateAt:    1000,
+				ActorUserID: userID,
+				Source:      audit.SourceAPI,
+				TargetType:  audit.TargetIncident,
+				TargetID:    incidentID,
+				Action:      "create_incident",
+				Changes:     []audit.Change{{Field: "name", New: []byte(`"incident"`)}},
+			},
+			{
+				ID:          model.NewId(),
+				CreateAt:    2000,
+				ActorUserID: userID,
+				Source:      audit.SourceSlashCommand,
+				TargetType:  audit.TargetIncident,
+				TargetID:    incidentID,
+				Action:      "update_status",
+				Changes:     []audit.Change{{Field: "name", Old: []byte(`"incident"`), New: []byte(`"renamed"`)}},
+			},
+			{
+				ID:          model.NewId(),
+				CreateAt:    3000,
+				ActorUserID: model.NewId(),
+				Source:      audit.SourceAPI,
+				TargetType:  audit.TargetPlaybook,
+				TargetID:    playbookID,
+				Action:      "update_playbook",
+				Denied:      true,
+			},
+		}
+
+		for _, record := range records {
+			require.NoError(t, auditStore.Create(record))
+		}
+
+		t.Run(driverName+" - get all records, most recent first", func(t *testing.T) {
+			results, err := auditStore.GetRecords(audit.FilterOptions{PerPage: 10})
+			require.NoError(t, err)
+			require.False(t, results.HasMore)
+			require.Equal(t, []audit.Record{records[2], records[1], records[0]}, results.Items)
+		})
+
+		t.Run(driverName+" - filter records", func(t *testing.T) {
+			results, err := auditStore.GetRecords(audit.FilterOptions{TargetID: incidentID, Since: 1500, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []audit.Record{records[1]}, results.Items)
+
+			results, err = auditStore.GetRecords(audit.FilterOptions{DeniedOnly: true, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []audit.Record{records[2]}, results.Items)
+		})
+
+		t.Run(driverName+" - page through records", func(t *testing.T) {
+			results, err := auditStore.GetRecords(audit.FilterOptions{PerPage: 2})
+			require.NoError(t, err)
+			require.True(t, results.HasMore)
+			require.Equal(t, []audit.Record{records[2], records[1]}, results.Items)
+
+			results, err = auditStore.GetRecords(audit.FilterOptions{PerPage: 2, Cursor: results.NextCursor})
+			require.NoError(t, err)
+			require.False(t, results.HasMore)
+			require.Equal(t, []audit.Record{records[0]}, results.Items)
+		})
+
+		t.Run(driverName+" - delete old records", func(t *testing.T) {
+			deleted, err := auditStore.DeleteBefore(2500)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), deleted)
+
+			results, err := auditStore.GetRecords(audit.FilterOptions{PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []audit.Record{records[2]}, results.Items)
+		})
+	}
+}
+
+func setupAuditStore(t testing.TB, db *sqlx.DB) audit.Store {
+	mockCtrl := gomock.NewController(t)
+
+	kvAPI := mock_sqlstore.NewMockKVAPI(mockCtrl)
+	configAPI := mock_sqlstore.NewMockConfigurationAPI(mockCtrl)
+	pluginAPIClient := PluginAPIClient{
+		KV:            kvAPI,
+		Configuration: configAPI,
+	}
+
+	logger, sqlStore := setupSQLStore(t, db)
+
+	return NewAuditStore(pluginAPIClient, logger, sqlStore)
+}
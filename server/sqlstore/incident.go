@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -20,6 +22,21 @@ type sqlIncident struct {
 	incident.Incident
 	ChecklistsJSON   json.RawMessage
 	PropertylistJSON json.RawMessage
+	ExitCriteriaJSON json.RawMessage
+
+	CommanderHandoffJSON json.RawMessage
+}
+
+// sqlIncidentWithSortValue is an incident listed with the value of the column it is sorted by,
+// to build the cursor of the next page.
+type sqlIncidentWithSortValue struct {
+	sqlIncident
+	SortValue string
+
+	// Only selected for the summary of the incidents.
+	CurrentStatus string
+	TasksTotal    int
+	TasksDone     int
 }
 
 // incidentStore holds the information needed to fulfill the methods in the store interface.
@@ -29,6 +46,7 @@ type incidentStore struct {
 	store                *SQLStore
 	queryBuilder         sq.StatementBuilderType
 	incidentSelect       sq.SelectBuilder
+	summarySelect        sq.SelectBuilder
 	statusPostsSelect    sq.SelectBuilder
 	timelineEventsSelect sq.SelectBuilder
 }
@@ -46,9 +64,18 @@ func NewIncidentStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 	// When adding an Incident column #1: add to this select
 	incidentSelect := sqlStore.builder.
 		Select("i.ID", "c.DisplayName AS Name", "i.Description", "i.CommanderUserID", "i.TeamID", "i.ChannelID",
-			"c.CreateAt", "i.EndAt", "c.DeleteAt", "i.PostID", "i.PlaybookID",
-			"i.ChecklistsJSON", "i.PropertylistJSON", "COALESCE(i.ReminderPostID, '') ReminderPostID", "i.PreviousReminder", "i.BroadcastChannelID",
-			"COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate").
+			"c.CreateAt", "i.EndAt", "c.DeleteAt", "i.PostID", "i.PlaybookID", "i.PlaybookVersion",
+			"i.ChecklistsJSON", "i.PropertylistJSON", "COALESCE(i.ExitCriteriaJSON, '') ExitCriteriaJSON", "COALESCE(i.ReminderPostID, '') ReminderPostID", "i.PreviousReminder", "i.BroadcastChannelID",
+			"COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "COALESCE(i.CommanderHandoffJSON, '') CommanderHandoffJSON",
+			"i.Version").
+		From("IR_Incident AS i").
+		Join("Channels AS c ON (c.Id = i.ChannelId)")
+
+	// The columns shown by list views, leaving out the JSON columns and the child tables.
+	summarySelect := sqlStore.builder.
+		Select("i.ID", "c.DisplayName AS Name", "i.CommanderUserID", "i.TeamID", "i.ChannelID",
+			"c.CreateAt", "i.EndAt", "c.DeleteAt", "i.PlaybookID", "i.Version",
+			"i.CurrentStatus", "i.TasksTotal", "i.TasksDone").
 		From("IR_Incident AS i").
 		Join("Channels AS c ON (c.Id = i.ChannelId)")
 
@@ -69,96 +96,150 @@ func NewIncidentStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 		store:                sqlStore,
 		queryBuilder:         sqlStore.builder,
 		incidentSelect:       incidentSelect,
+		summarySelect:        summarySelect,
 		statusPostsSelect:    statusPostsSelect,
 		timelineEventsSelect: timelineEventsSelect,
 	}
 }
 
-// GetIncidents returns filtered incidents and the total count before paging.
+// GetIncidents returns filtered incidents and, unless paging with a cursor, the total count before paging.
 func (s *incidentStore) GetIncidents(requesterInfo incident.RequesterInfo, options incident.FilterOptions) (*incident.GetIncidentsResults, error) {
 	if err := incident.ValidateOptions(&options); err != nil {
 		return nil, err
 	}
 
 	permissionsExpr := s.buildPermissionsExpr(requesterInfo)
+	teamsPermissionsExpr := s.buildTeamsPermissionsExpr(requesterInfo, options)
+	filtersExpr := s.buildFiltersExpr(options)
 
-	queryForResults := s.incidentSelect.
-		Where(permissionsExpr).
-		Where(sq.Eq{"i.TeamID": options.TeamID}).
-		Offset(uint64(options.Page * options.PerPage)).
-		Limit(uint64(options.PerPage))
-
-	queryForTotal := s.store.builder.
-		Select("COUNT(*)").
-		From("IR_Incident AS i").
-		Join("Channels AS c ON (c.Id = i.ChannelId)").
-		Where(permissionsExpr).
-		Where(sq.Eq{"i.TeamID": options.TeamID})
-
-	if options.Status != "" {
-		queryForResults = queryForResults.Where(sq.Eq{"i.CurrentStatus": options.Status})
-		queryForTotal = queryForTotal.Where(sq.Eq{"i.CurrentStatus": options.Status})
+	summaryOnly := options.Fields == incident.FieldsSummary
+	queryForResults := s.incidentSelect
+	if summaryOnly {
+		queryForResults = s.summarySelect
 	}
 
-	if options.CommanderID != "" {
-		queryForResults = queryForResults.Where(sq.Eq{"i.CommanderUserID": options.CommanderID})
-		queryForTotal = queryForTotal.Where(sq.Eq{"i.CommanderUserID": options.CommanderID})
-	}
-
-	if options.MemberID != "" {
-		membershipClause := s.queryBuilder.
-			Select("1").
-			Prefix("EXISTS(").
-			From("ChannelMembers AS cm").
-			Where("cm.ChannelId = i.ChannelID").
-			Where(sq.Eq{"cm.UserId": strings.ToLower(options.MemberID)}).
-			Suffix(")")
-
-		queryForResults = queryForResults.Where(membershipClause)
-		queryForTotal = queryForTotal.Where(membershipClause)
-	}
-
-	// TODO: do we need to sanitize (replace any '%'s in the search term)?
-	if options.SearchTerm != "" {
-		column := "c.DisplayName"
-		searchString := options.SearchTerm
-
-		// Postgres performs a case-sensitive search, so we need to lowercase
-		// both the column contents and the search string
-		if s.store.db.DriverName() == model.DATABASE_DRIVER_POSTGRES {
-			column = "LOWER(c.DisplayName)"
-			searchString = strings.ToLower(options.SearchTerm)
+	queryForResults = queryForResults.
+		Where(permissionsExpr).
+		Where(teamsPermissionsExpr).
+		Where(filtersExpr)
+
+	// With a cursor, the page starts right after the last incident of the previous page, which is
+	// faster than an offset and stable when incidents are created in the meantime. One more
+	// incident than needed is asked for to know whether there are more, instead of counting them.
+	paginateWithCursor := options.Cursor != ""
+	if paginateWithCursor {
+		queryForResults = queryForResults.Limit(uint64(options.PerPage + 1))
+	} else {
+		queryForResults = queryForResults.
+			Offset(uint64(options.Page * options.PerPage)).
+			Limit(uint64(options.PerPage))
+	}
+
+	var keys keyset
+	if options.Sort == incident.SortByProperty {
+		// Incidents without a value for the property are sorted last, whatever the direction.
+		queryForResults = queryForResults.
+			LeftJoin(`(
+				SELECT IncidentID, MIN(NumberValue) AS NumberValue, MIN(Value) AS Value
+				FROM IR_IncidentProperty
+				WHERE Name = ?
+				GROUP BY IncidentID
+			) AS ip ON ip.IncidentID = i.ID`, normalizePropertyText(options.SortProperty)).
+			OrderBy(
+				"CASE WHEN ip.IncidentID IS NULL THEN 1 ELSE 0 END",
+				fmt.Sprintf("ip.NumberValue %s", options.Direction),
+				fmt.Sprintf("ip.Value %s", options.Direction),
+				"i.ID",
+			)
+	} else {
+		keys = incidentKeyset(options.Sort, options.Direction)
+		queryForResults = queryForResults.
+			Column(fmt.Sprintf("%s AS SortValue", keys.column)).
+			OrderBy(keys.orderBy()...)
+
+		if paginateWithCursor {
+			c, err := cursor.Decode(options.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			after, err := keys.after(c)
+			if err != nil {
+				return nil, err
+			}
+			queryForResults = queryForResults.Where(after)
 		}
-
-		queryForResults = queryForResults.Where(sq.Like{column: fmt.Sprint("%", searchString, "%")})
-		queryForTotal = queryForTotal.Where(sq.Like{column: fmt.Sprint("%", searchString, "%")})
 	}
 
-	queryForResults = queryForResults.OrderBy(fmt.Sprintf("%s %s", options.Sort, options.Direction))
-
 	tx, err := s.store.db.Beginx()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not begin transaction")
 	}
 	defer s.store.finalizeTransaction(tx)
 
-	var rawIncidents []sqlIncident
+	var rawIncidents []sqlIncidentWithSortValue
 	if err = s.store.selectBuilder(tx, &rawIncidents, queryForResults); err != nil {
 		return nil, errors.Wrap(err, "failed to query for incidents")
 	}
 
-	var total int
-	if err = s.store.getBuilder(tx, &total, queryForTotal); err != nil {
-		return nil, errors.Wrap(err, "failed to get total count")
+	var total, pageCount int
+	var hasMore bool
+	if paginateWithCursor {
+		hasMore = len(rawIncidents) > options.PerPage
+		if hasMore {
+			rawIncidents = rawIncidents[:options.PerPage]
+		}
+	} else {
+		queryForTotal := s.store.builder.
+			Select("COUNT(*)").
+			From("IR_Incident AS i").
+			Join("Channels AS c ON (c.Id = i.ChannelId)").
+			Where(permissionsExpr).
+			Where(teamsPermissionsExpr).
+			Where(filtersExpr)
+
+		if err = s.store.getBuilder(tx, &total, queryForTotal); err != nil {
+			return nil, errors.Wrap(err, "failed to get total count")
+		}
+		pageCount = int(math.Ceil(float64(total) / float64(options.PerPage)))
+		hasMore = options.Page+1 < pageCount
+	}
+
+	var nextCursor string
+	if hasMore && options.Sort != incident.SortByProperty && len(rawIncidents) > 0 {
+		last := rawIncidents[len(rawIncidents)-1]
+		nextCursor = keys.nextCursor(options.Sort, options.Direction, last.SortValue, last.ID)
+	}
+
+	if summaryOnly {
+		if err = tx.Commit(); err != nil {
+			return nil, errors.Wrap(err, "could not commit transaction")
+		}
+
+		incidents := make([]incident.Incident, 0, len(rawIncidents))
+		for _, rawIncident := range rawIncidents {
+			summary := rawIncident.Incident
+			summary.Summary = &incident.Summary{
+				Status:     rawIncident.CurrentStatus,
+				TasksTotal: rawIncident.TasksTotal,
+				TasksDone:  rawIncident.TasksDone,
+			}
+			incidents = append(incidents, summary)
+		}
+
+		return &incident.GetIncidentsResults{
+			TotalCount: total,
+			PageCount:  pageCount,
+			HasMore:    hasMore,
+			NextCursor: nextCursor,
+			Items:      incidents,
+		}, nil
 	}
-	pageCount := int(math.Ceil(float64(total) / float64(options.PerPage)))
-	hasMore := options.Page+1 < pageCount
 
 	incidents := make([]incident.Incident, 0, len(rawIncidents))
 	incidentIDs := make([]string, 0, len(rawIncidents))
 	for _, rawIncident := range rawIncidents {
 		var asIncident *incident.Incident
-		asIncident, err = s.toIncident(rawIncident)
+		asIncident, err = s.toIncident(rawIncident.sqlIncident)
 		if err != nil {
 			return nil, err
 		}
@@ -199,6 +280,7 @@ func (s *incidentStore) GetIncidents(requesterInfo incident.RequesterInfo, optio
 		TotalCount: total,
 		PageCount:  pageCount,
 		HasMore:    hasMore,
+		NextCursor: nextCursor,
 		Items:      incidents,
 	}, nil
 }
@@ -213,14 +295,22 @@ func (s *incidentStore) CreateIncident(newIncident *incident.Incident) (out *inc
 	}
 	incidentCopy := newIncident.Clone()
 	incidentCopy.ID = model.NewId()
+	incidentCopy.Version = 0
 
 	rawIncident, err := toSQLIncident(*incidentCopy)
 	if err != nil {
 		return nil, err
 	}
+	tasksTotal, tasksDone := countTasks(rawIncident.Checklists)
+
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
 
 	// When adding an Incident column #2: add to the SetMap
-	_, err = s.store.execBuilder(s.store.db, sq.
+	_, err = s.store.execBuilder(tx, sq.
 		Insert("IR_Incident").
 		SetMap(map[string]interface{}{
 			"ID":                      rawIncident.ID,
@@ -231,13 +321,19 @@ func (s *incidentStore) CreateIncident(newIncident *incident.Incident) (out *inc
 			"ChannelID":               rawIncident.ChannelID,
 			"PostID":                  rawIncident.PostID,
 			"PlaybookID":              rawIncident.PlaybookID,
+			"PlaybookVersion":         rawIncident.PlaybookVersion,
 			"ChecklistsJSON":          rawIncident.ChecklistsJSON,
 			"PropertylistJSON":        rawIncident.PropertylistJSON,
+			"ExitCriteriaJSON":        rawIncident.ExitCriteriaJSON,
 			"ReminderPostID":          rawIncident.ReminderPostID,
 			"PreviousReminder":        rawIncident.PreviousReminder,
 			"BroadcastChannelID":      rawIncident.BroadcastChannelID,
 			"ReminderMessageTemplate": rawIncident.ReminderMessageTemplate,
+			"CommanderHandoffJSON":    rawIncident.CommanderHandoffJSON,
 			"CurrentStatus":           rawIncident.CurrentStatus(), // Added to make querying easier
+			"TasksTotal":              tasksTotal,
+			"TasksDone":               tasksDone,
+			"Version":                 0,
 			// Preserved for backwards compatibility with v1.2
 			"ActiveStage":      0,
 			"ActiveStageTitle": "",
@@ -251,11 +347,89 @@ func (s *incidentStore) CreateIncident(newIncident *incident.Incident) (out *inc
 		return nil, errors.Wrapf(err, "failed to store new incident")
 	}
 
+	if err = s.store.replaceIncidentProperties(tx, rawIncident.ID, rawIncident.Propertylist); err != nil {
+		return nil, err
+	}
+
+	if err = s.store.updateIncidentSearchContent(tx, rawIncident.ID, rawIncident.Description,
+		rawIncident.Checklists, rawIncident.Propertylist); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
 	return incidentCopy, nil
 }
 
-// UpdateIncident updates an incident.
+// UpdateIncident updates an incident, and sets newIncident.Version to its new version.
 func (s *incidentStore) UpdateIncident(newIncident *incident.Incident) error {
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	if err = s.updateIncident(tx, newIncident); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+// Commit writes the incident, status post and timeline events of work in a single transaction,
+// along with the outbox messages of work, and returns the messages once committed.
+func (s *incidentStore) Commit(work *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	if work.Incident != nil {
+		if err = s.updateIncident(tx, work.Incident); err != nil {
+			return nil, err
+		}
+	}
+
+	if work.StatusPost != nil {
+		if err = s.updateStatus(tx, work.StatusPost); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, event := range work.TimelineEvents {
+		if _, err = s.createTimelineEvent(tx, event); err != nil {
+			return nil, err
+		}
+	}
+
+	var messages []*incident.OutboxMessage
+	if work.Outbox != nil {
+		if messages, err = work.Outbox(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, message := range messages {
+		if err = s.createOutboxMessage(tx, message); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
+	return messages, nil
+}
+
+func (s *incidentStore) updateIncident(e queryExecer, newIncident *incident.Incident) error {
 	if newIncident == nil {
 		return errors.New("incident is nil")
 	}
@@ -267,20 +441,26 @@ func (s *incidentStore) UpdateIncident(newIncident *incident.Incident) error {
 	if err != nil {
 		return err
 	}
+	tasksTotal, tasksDone := countTasks(rawIncident.Checklists)
 
 	// When adding an Incident column #3: add to this SetMap (if it is a column that can be updated)
-	_, err = s.store.execBuilder(s.store.db, sq.
+	_, err = s.store.execBuilder(e, sq.
 		Update("IR_Incident").
 		SetMap(map[string]interface{}{
-			"Name":               "",
-			"Description":        rawIncident.Description,
-			"CommanderUserID":    rawIncident.CommanderUserID,
-			"ChecklistsJSON":     rawIncident.ChecklistsJSON,
-			"PropertylistJSON":   rawIncident.PropertylistJSON,
-			"ReminderPostID":     rawIncident.ReminderPostID,
-			"PreviousReminder":   rawIncident.PreviousReminder,
-			"BroadcastChannelID": rawIncident.BroadcastChannelID,
-			"EndAt":              rawIncident.ResolvedAt(),
+			"Name":                 "",
+			"Description":          rawIncident.Description,
+			"CommanderUserID":      rawIncident.CommanderUserID,
+			"ChecklistsJSON":       rawIncident.ChecklistsJSON,
+			"PropertylistJSON":     rawIncident.PropertylistJSON,
+			"ExitCriteriaJSON":     rawIncident.ExitCriteriaJSON,
+			"ReminderPostID":       rawIncident.ReminderPostID,
+			"PreviousReminder":     rawIncident.PreviousReminder,
+			"BroadcastChannelID":   rawIncident.BroadcastChannelID,
+			"CommanderHandoffJSON": rawIncident.CommanderHandoffJSON,
+			"EndAt":                rawIncident.ResolvedAt(),
+			"TasksTotal":           tasksTotal,
+			"TasksDone":            tasksDone,
+			"Version":              sq.Expr("Version + 1"),
 		}).
 		Where(sq.Eq{"ID": rawIncident.ID}))
 
@@ -288,10 +468,28 @@ func (s *incidentStore) UpdateIncident(newIncident *incident.Incident) error {
 		return errors.Wrapf(err, "failed to update incident with id '%s'", rawIncident.ID)
 	}
 
-	return nil
+	// The updated row stays locked until the end of the transaction, so this is the version
+	// written by this update.
+	if err = s.store.getBuilder(e, &newIncident.Version, sq.
+		Select("Version").
+		From("IR_Incident").
+		Where(sq.Eq{"ID": rawIncident.ID})); err != nil {
+		return errors.Wrapf(err, "failed to get the version of incident with id '%s'", rawIncident.ID)
+	}
+
+	if err = s.store.replaceIncidentProperties(e, rawIncident.ID, rawIncident.Propertylist); err != nil {
+		return err
+	}
+
+	return s.store.updateIncidentSearchContent(e, rawIncident.ID, rawIncident.Description,
+		rawIncident.Checklists, rawIncident.Propertylist)
 }
 
 func (s *incidentStore) UpdateStatus(statusPost *incident.SQLStatusPost) error {
+	return s.updateStatus(s.store.db, statusPost)
+}
+
+func (s *incidentStore) updateStatus(e queryExecer, statusPost *incident.SQLStatusPost) error {
 	if statusPost == nil {
 		return errors.New("status post is nil")
 	}
@@ -305,7 +503,7 @@ func (s *incidentStore) UpdateStatus(statusPost *incident.SQLStatusPost) error {
 		return errors.New("needs status")
 	}
 
-	if _, err := s.store.execBuilder(s.store.db, sq.
+	if _, err := s.store.execBuilder(e, sq.
 		Insert("IR_StatusPosts").
 		SetMap(map[string]interface{}{
 			"IncidentID": statusPost.IncidentID,
@@ -315,7 +513,7 @@ func (s *incidentStore) UpdateStatus(statusPost *incident.SQLStatusPost) error {
 		return errors.Wrap(err, "failed to add new status post")
 	}
 
-	if _, err := s.store.execBuilder(s.store.db, sq.
+	if _, err := s.store.execBuilder(e, sq.
 		Update("IR_Incident").
 		SetMap(map[string]interface{}{
 			"CurrentStatus": statusPost.Status,
@@ -325,11 +523,15 @@ func (s *incidentStore) UpdateStatus(statusPost *incident.SQLStatusPost) error {
 		return errors.Wrap(err, "failed to update current status")
 	}
 
-	return nil
+	return s.store.addIncidentSearchStatusUpdate(e, statusPost.IncidentID, statusPost.Message)
 }
 
-// UpdateTimelineEvent updates (or inserts) the timeline event
+// CreateTimelineEvent inserts the timeline event
 func (s *incidentStore) CreateTimelineEvent(event *incident.TimelineEvent) (*incident.TimelineEvent, error) {
+	return s.createTimelineEvent(s.store.db, event)
+}
+
+func (s *incidentStore) createTimelineEvent(e queryExecer, event *incident.TimelineEvent) (*incident.TimelineEvent, error) {
 	if event.IncidentID == "" {
 		return nil, errors.New("needs incident ID")
 	}
@@ -341,7 +543,7 @@ func (s *incidentStore) CreateTimelineEvent(event *incident.TimelineEvent) (*inc
 	}
 	event.ID = model.NewId()
 
-	_, err := s.store.execBuilder(s.store.db, sq.
+	_, err := s.store.execBuilder(e, sq.
 		Insert("IR_TimelineEvent").
 		SetMap(map[string]interface{}{
 			"ID":            event.ID,
@@ -361,6 +563,10 @@ func (s *incidentStore) CreateTimelineEvent(event *incident.TimelineEvent) (*inc
 		return nil, errors.Wrap(err, "failed to insert timeline event")
 	}
 
+	if err = s.store.updateIncidentSearchTimeline(e, event.IncidentID); err != nil {
+		return nil, err
+	}
+
 	return event, nil
 }
 
@@ -395,7 +601,7 @@ func (s *incidentStore) UpdateTimelineEvent(event *incident.TimelineEvent) error
 		return errors.Wrap(err, "failed to update timeline event")
 	}
 
-	return nil
+	return s.store.updateIncidentSearchTimeline(s.store.db, event.IncidentID)
 }
 
 // GetIncident gets an incident by ID.
@@ -493,6 +699,29 @@ func (s *incidentStore) GetAllIncidentMembersCount(channelID string) (int64, err
 	return numMembers, nil
 }
 
+// GetIncidentCountsByStatus returns the number of incidents in each status, across all teams.
+func (s *incidentStore) GetIncidentCountsByStatus() (map[string]int64, error) {
+	query := s.queryBuilder.
+		Select("i.CurrentStatus AS Status", "COUNT(*) AS Count").
+		From("IR_Incident i").
+		GroupBy("i.CurrentStatus")
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := s.store.selectBuilder(s.store.db, &rows, query); err != nil {
+		return nil, errors.Wrap(err, "failed to count incidents by status")
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
 // GetCommanders returns the commanders of the incidents selected by options
 func (s *incidentStore) GetCommanders(requesterInfo incident.RequesterInfo, options incident.FilterOptions) ([]incident.CommanderInfo, error) {
 	if err := incident.ValidateOptions(&options); err != nil {
@@ -501,13 +730,14 @@ func (s *incidentStore) GetCommanders(requesterInfo incident.RequesterInfo, opti
 
 	permissionsExpr := s.buildPermissionsExpr(requesterInfo)
 
-	// At the moment, the options only includes teamID
 	query := s.queryBuilder.
 		Select("DISTINCT u.Id AS UserID", "u.Username").
 		From("IR_Incident AS i").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
 		Join("Users AS u ON i.CommanderUserID = u.Id").
-		Where(sq.Eq{"i.TeamID": options.TeamID}).
-		Where(permissionsExpr)
+		Where(permissionsExpr).
+		Where(s.buildTeamsPermissionsExpr(requesterInfo, options)).
+		Where(s.buildFiltersExpr(options))
 
 	var commanders []incident.CommanderInfo
 	err := s.store.selectBuilder(s.store.db, &commanders, query)
@@ -526,7 +756,7 @@ func (s *incidentStore) NukeDB() (err error) {
 	}
 	defer s.store.finalizeTransaction(tx)
 
-	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember,  IR_StatusPosts, IR_Incident, IR_Playbook, IR_System, IR_TimelineEvent"); err != nil {
+	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember,  IR_StatusPosts, IR_IncidentProperty, IR_IncidentSearch, IR_Incident, IR_Playbook, IR_System, IR_TimelineEvent, IR_Outbox"); err != nil {
 		return errors.Wrap(err, "could not delete all IR tables")
 	}
 
@@ -559,6 +789,103 @@ func (s *incidentStore) ChangeCreationDate(incidentID string, creationTimestamp
 	return nil
 }
 
+// buildFiltersExpr returns the conditions an incident must meet to be selected by options, which
+// must have been validated already. It expects the incidents and their channels to be aliased as
+// i and c.
+func (s *incidentStore) buildFiltersExpr(options incident.FilterOptions) sq.And {
+	filters := sq.And{}
+
+	if len(options.TeamIDs) > 0 {
+		filters = append(filters, sq.Eq{"i.TeamID": options.TeamIDs})
+	}
+
+	if len(options.Statuses) > 0 {
+		filters = append(filters, sq.Eq{"i.CurrentStatus": options.Statuses})
+	}
+
+	if options.PlaybookID != "" {
+		filters = append(filters, sq.Eq{"i.PlaybookID": options.PlaybookID})
+	}
+
+	if options.CommanderID != "" {
+		filters = append(filters, sq.Eq{"i.CommanderUserID": options.CommanderID})
+	}
+
+	if options.CreatedAfter > 0 {
+		filters = append(filters, sq.GtOrEq{"c.CreateAt": options.CreatedAfter})
+	}
+	if options.CreatedBefore > 0 {
+		filters = append(filters, sq.LtOrEq{"c.CreateAt": options.CreatedBefore})
+	}
+
+	if options.EndedAfter > 0 || options.EndedBefore > 0 {
+		filters = append(filters, sq.Gt{"i.EndAt": 0})
+	}
+	if options.EndedAfter > 0 {
+		filters = append(filters, sq.GtOrEq{"i.EndAt": options.EndedAfter})
+	}
+	if options.EndedBefore > 0 {
+		filters = append(filters, sq.LtOrEq{"i.EndAt": options.EndedBefore})
+	}
+
+	if options.MemberID != "" {
+		// Nested queries must use ? placeholders: the outer query numbers them for Postgres.
+		membershipClause := sq.
+			Select("1").
+			Prefix("EXISTS(").
+			From("ChannelMembers AS cm").
+			Where("cm.ChannelId = i.ChannelID").
+			Where(sq.Eq{"cm.UserId": strings.ToLower(options.MemberID)}).
+			Suffix(")")
+
+		filters = append(filters, membershipClause)
+	}
+
+	// Sort the titles so that the same options always build the same query.
+	titles := make([]string, 0, len(options.PropertyValues))
+	for title := range options.PropertyValues {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+
+	for _, title := range titles {
+		propertyClause := sq.
+			Select("1").
+			Prefix("EXISTS(").
+			From("IR_IncidentProperty AS prop").
+			Where("prop.IncidentID = i.ID").
+			Where(sq.Eq{"prop.Name": normalizePropertyText(title)}).
+			Where(sq.Eq{"prop.Value": normalizePropertyFilterValues(options.PropertyValues[title])}).
+			Suffix(")")
+
+		filters = append(filters, propertyClause)
+	}
+
+	if options.SearchTerm != "" {
+		column := "c.DisplayName"
+		searchString := escapeLike(options.SearchTerm)
+
+		// Postgres performs a case-sensitive search, so we need to lowercase
+		// both the column contents and the search string
+		if s.store.db.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			column = "LOWER(c.DisplayName)"
+			searchString = strings.ToLower(searchString)
+		}
+
+		filters = append(filters, sq.Like{column: fmt.Sprint("%", searchString, "%")})
+	}
+
+	return filters
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, using the default escape character of both
+// MySQL and Postgres.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
 func (s *incidentStore) buildPermissionsExpr(info incident.RequesterInfo) sq.Sqlizer {
 	if info.UserIDtoIsAdmin[info.UserID] {
 		return nil
@@ -580,6 +907,22 @@ func (s *incidentStore) buildPermissionsExpr(info incident.RequesterInfo) sq.Sql
 		  )`, info.UserID)
 }
 
+// buildTeamsPermissionsExpr restricts the queries that are not limited to some teams to the teams
+// of the requester, on top of buildPermissionsExpr. Queries limited to some teams expect the
+// requester to have been checked to be able to view them already.
+func (s *incidentStore) buildTeamsPermissionsExpr(info incident.RequesterInfo, options incident.FilterOptions) sq.Sqlizer {
+	if info.UserIDtoIsAdmin[info.UserID] || len(options.TeamIDs) > 0 {
+		return nil
+	}
+
+	return sq.Expr(`
+		EXISTS(SELECT 1
+				 FROM TeamMembers as tm
+				 WHERE tm.TeamId = i.TeamID
+				   AND tm.UserId = ?
+				   AND tm.DeleteAt = 0)`, info.UserID)
+}
+
 func (s *incidentStore) toIncident(rawIncident sqlIncident) (*incident.Incident, error) {
 	i := rawIncident.Incident
 	if err := json.Unmarshal(rawIncident.ChecklistsJSON, &i.Checklists); err != nil {
@@ -590,6 +933,18 @@ func (s *incidentStore) toIncident(rawIncident sqlIncident) (*incident.Incident,
 		return nil, errors.Wrapf(err, "failed to unmarshal propertylist json for incident id: %s", rawIncident.ID)
 	}
 
+	if len(rawIncident.ExitCriteriaJSON) > 0 {
+		if err := json.Unmarshal(rawIncident.ExitCriteriaJSON, &i.ExitCriteria); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal exit criteria json for incident id: %s", rawIncident.ID)
+		}
+	}
+
+	if len(rawIncident.CommanderHandoffJSON) > 0 {
+		if err := json.Unmarshal(rawIncident.CommanderHandoffJSON, &i.CommanderHandoff); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal commander handoff json for incident id: %s", rawIncident.ID)
+		}
+	}
+
 	return &i, nil
 }
 
@@ -608,13 +963,43 @@ func toSQLIncident(origIncident incident.Incident) (*sqlIncident, error) {
 		return nil, errors.Wrapf(err, "failed to marshal checklist json for incident id: '%s'", origIncident.ID)
 	}
 
+	exitCriteriaJSON, err := json.Marshal(origIncident.ExitCriteria)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal exit criteria json for incident id: '%s'", origIncident.ID)
+	}
+
+	var commanderHandoffJSON json.RawMessage
+	if origIncident.CommanderHandoff != nil {
+		commanderHandoffJSON, err = json.Marshal(origIncident.CommanderHandoff)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal commander handoff json for incident id: '%s'", origIncident.ID)
+		}
+	}
+
 	return &sqlIncident{
-		Incident:         origIncident,
-		ChecklistsJSON:   checklistsJSON,
-		PropertylistJSON: propertylistJSON,
+		Incident:             origIncident,
+		ChecklistsJSON:       checklistsJSON,
+		PropertylistJSON:     propertylistJSON,
+		ExitCriteriaJSON:     exitCriteriaJSON,
+		CommanderHandoffJSON: commanderHandoffJSON,
 	}, nil
 }
 
+// countTasks returns the number of checklist items, and of those closed or skipped, stored with
+// the incident for list views not to load its checklists.
+func countTasks(checklists []playbook.Checklist) (total, done int) {
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			total++
+			if item.State == playbook.ChecklistItemStateClosed || item.State == playbook.ChecklistItemStateSkipped {
+				done++
+			}
+		}
+	}
+
+	return total, done
+}
+
 // populateChecklistIDs returns a cloned slice with ids entered for checklists and checklist items.
 func populateChecklistIDs(checklists []playbook.Checklist) []playbook.Checklist {
 	if len(checklists) == 0 {
@@ -0,0 +1,373 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+)
+
+// incidentCacheSize is the number of incidents, and of channel to incident ID lookups, kept in
+// memory.
+const incidentCacheSize = 1000
+
+// incidentCacheExpiry bounds how long a cached incident is used. The name, creation and deletion
+// dates of an incident come from its channel and its status posts can be deleted, all without
+// going through the store, so these changes take up to this long to show.
+const incidentCacheExpiry = time.Minute
+
+// incidentCacheEventID identifies the cluster events telling the other app nodes which incidents
+// to drop from their cache.
+const incidentCacheEventID = "incident_cache_invalidate"
+
+// incidentCacheEvent is the payload of an invalidation sent to the other app nodes. All drops
+// everything they hold.
+type incidentCacheEvent struct {
+	IncidentIDs []string `json:"incident_ids,omitempty"`
+	All         bool     `json:"all,omitempty"`
+}
+
+// IncidentCacheStats counts the lookups served from the cache and from the database.
+type IncidentCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+type cachedIncident struct {
+	incident *incident.Incident
+	expires  time.Time
+}
+
+// IncidentCache is a read-through cache in front of an incident.Store for GetIncident and
+// GetIncidentIDForChannel. Every write through the cache drops the incidents it changes.
+//
+// When the server runs in a cluster, each write also sends a cluster event for the other app
+// nodes to drop the incidents from their cache, see OnPluginClusterEvent. Without a way to send
+// cluster events, the cache stays disabled in a cluster.
+type IncidentCache struct {
+	incident.Store
+	cluster   ClusterAPI
+	log       bot.Logger
+	clustered bool
+
+	lock          sync.Mutex
+	enabled       bool
+	incidents     map[string]cachedIncident
+	incidentIDs   map[string]string
+	invalidations uint64
+
+	hits   int64
+	misses int64
+}
+
+// Ensure IncidentCache implements the incident.Store interface.
+var _ incident.Store = (*IncidentCache)(nil)
+
+// NewIncidentCache creates a disabled cache in front of store. Use SetEnabled to enable it.
+func NewIncidentCache(store incident.Store, pluginAPI PluginAPIClient, log bot.Logger) *IncidentCache {
+	clusterEnabled := pluginAPI.Configuration.GetConfig().ClusterSettings.Enable
+
+	return &IncidentCache{
+		Store:       store,
+		cluster:     pluginAPI.Cluster,
+		log:         log,
+		clustered:   clusterEnabled != nil && *clusterEnabled,
+		incidents:   make(map[string]cachedIncident),
+		incidentIDs: make(map[string]string),
+	}
+}
+
+// SetEnabled enables or disables the cache, and has the other app nodes of a cluster drop
+// everything they hold. Disabling it drops everything it holds.
+func (c *IncidentCache) SetEnabled(enabled bool) {
+	if enabled && c.clustered && c.cluster == nil {
+		c.log.Warnf("the incident cache stays disabled: the server runs in a cluster and cannot send cluster events")
+		enabled = false
+	}
+
+	c.lock.Lock()
+	c.enabled = enabled
+	if !enabled {
+		c.purge()
+	}
+	c.lock.Unlock()
+
+	if err := c.publish(incidentCacheEvent{All: true}); err != nil {
+		c.log.Warnf("failed to purge the incident cache of the other app nodes: %v", err)
+	}
+}
+
+// OnPluginClusterEvent drops from the cache the incidents written on another app node of the
+// cluster. Events for anything but the cache are ignored.
+func (c *IncidentCache) OnPluginClusterEvent(id string, data []byte) {
+	if id != incidentCacheEventID {
+		return
+	}
+
+	var event incidentCacheEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		c.log.Warnf("failed to read incident cache event: %v", err)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if event.All {
+		c.purge()
+		return
+	}
+	for _, incidentID := range event.IncidentIDs {
+		delete(c.incidents, incidentID)
+	}
+	c.invalidations++
+}
+
+// Stats returns the number of lookups served from the cache and from the database since the
+// plugin was activated.
+func (c *IncidentCache) Stats() IncidentCacheStats {
+	return IncidentCacheStats{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+}
+
+// GetIncident gets an incident by ID, from the cache if it holds a current copy.
+func (c *IncidentCache) GetIncident(incidentID string) (*incident.Incident, error) {
+	if !c.isEnabled() {
+		return c.Store.GetIncident(incidentID)
+	}
+
+	c.lock.Lock()
+	cached, ok := c.incidents[incidentID]
+	invalidations := c.invalidations
+	c.lock.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		atomic.AddInt64(&c.hits, 1)
+		return cached.incident.Clone(), nil
+	}
+	atomic.AddInt64(&c.misses, 1)
+
+	theIncident, err := c.Store.GetIncident(incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Don't cache what was read while an incident was being written: it may be out of date.
+	if c.enabled && c.invalidations == invalidations {
+		c.makeRoom()
+		c.incidents[incidentID] = cachedIncident{
+			incident: theIncident.Clone(),
+			expires:  time.Now().Add(incidentCacheExpiry),
+		}
+	}
+
+	return theIncident, nil
+}
+
+// GetIncidentIDForChannel gets the ID of the incident of the given channel, from the cache if
+// it holds it. The incident of a channel never changes once created.
+func (c *IncidentCache) GetIncidentIDForChannel(channelID string) (string, error) {
+	if !c.isEnabled() {
+		return c.Store.GetIncidentIDForChannel(channelID)
+	}
+
+	c.lock.Lock()
+	incidentID, ok := c.incidentIDs[channelID]
+	c.lock.Unlock()
+
+	if ok {
+		atomic.AddInt64(&c.hits, 1)
+		return incidentID, nil
+	}
+	atomic.AddInt64(&c.misses, 1)
+
+	incidentID, err := c.Store.GetIncidentIDForChannel(channelID)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.enabled {
+		if len(c.incidentIDs) >= incidentCacheSize {
+			c.incidentIDs = make(map[string]string)
+		}
+		c.incidentIDs[channelID] = incidentID
+	}
+
+	return incidentID, nil
+}
+
+// CreateIncident creates a new incident.
+func (c *IncidentCache) CreateIncident(incdnt *incident.Incident) (*incident.Incident, error) {
+	newIncident, err := c.Store.CreateIncident(incdnt)
+	if err != nil {
+		return nil, err
+	}
+
+	return newIncident, c.invalidate(newIncident.ID)
+}
+
+// UpdateIncident updates an incident.
+func (c *IncidentCache) UpdateIncident(incdnt *incident.Incident) error {
+	err := c.Store.UpdateIncident(incdnt)
+
+	return c.invalidateAfter(err, incdnt.ID)
+}
+
+// Commit writes a unit of work, and invalidates the incidents it wrote.
+func (c *IncidentCache) Commit(work *incident.UnitOfWork) ([]*incident.OutboxMessage, error) {
+	messages, err := c.Store.Commit(work)
+
+	written := make(map[string]bool)
+	if work.Incident != nil {
+		written[work.Incident.ID] = true
+	}
+	if work.StatusPost != nil {
+		written[work.StatusPost.IncidentID] = true
+	}
+	for _, event := range work.TimelineEvents {
+		written[event.IncidentID] = true
+	}
+
+	incidentIDs := make([]string, 0, len(written))
+	for incidentID := range written {
+		incidentIDs = append(incidentIDs, incidentID)
+	}
+
+	return messages, c.invalidateAfter(err, incidentIDs...)
+}
+
+// UpdateStatus updates the status of an incident.
+func (c *IncidentCache) UpdateStatus(statusPost *incident.SQLStatusPost) error {
+	err := c.Store.UpdateStatus(statusPost)
+	if statusPost == nil {
+		return err
+	}
+
+	return c.invalidateAfter(err, statusPost.IncidentID)
+}
+
+// CreateTimelineEvent inserts the timeline event.
+func (c *IncidentCache) CreateTimelineEvent(event *incident.TimelineEvent) (*incident.TimelineEvent, error) {
+	newEvent, err := c.Store.CreateTimelineEvent(event)
+
+	return newEvent, c.invalidateAfter(err, event.IncidentID)
+}
+
+// UpdateTimelineEvent updates an existing timeline event.
+func (c *IncidentCache) UpdateTimelineEvent(event *incident.TimelineEvent) error {
+	err := c.Store.UpdateTimelineEvent(event)
+
+	return c.invalidateAfter(err, event.IncidentID)
+}
+
+// ChangeCreationDate changes the creation date of the specified incident.
+func (c *IncidentCache) ChangeCreationDate(incidentID string, creationTimestamp time.Time) error {
+	err := c.Store.ChangeCreationDate(incidentID, creationTimestamp)
+
+	return c.invalidateAfter(err, incidentID)
+}
+
+// NukeDB removes all incident related data, and drops everything the caches of all the app
+// nodes hold.
+func (c *IncidentCache) NukeDB() error {
+	err := c.Store.NukeDB()
+
+	c.lock.Lock()
+	c.purge()
+	c.lock.Unlock()
+
+	if publishErr := c.publish(incidentCacheEvent{All: true}); publishErr != nil && err == nil {
+		return errors.Wrap(publishErr, "failed to purge the incident cache of the other app nodes")
+	}
+
+	return err
+}
+
+func (c *IncidentCache) isEnabled() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.enabled
+}
+
+// invalidateAfter drops the incidents from the cache after a write, even one that failed since
+// it may have been partially applied, and returns writeErr if set.
+func (c *IncidentCache) invalidateAfter(writeErr error, incidentIDs ...string) error {
+	if err := c.invalidate(incidentIDs...); err != nil && writeErr == nil {
+		return err
+	}
+
+	return writeErr
+}
+
+// invalidate drops the incidents from the cache of this node and, in a cluster, of the others.
+func (c *IncidentCache) invalidate(incidentIDs ...string) error {
+	c.lock.Lock()
+	for _, incidentID := range incidentIDs {
+		delete(c.incidents, incidentID)
+	}
+	c.invalidations++
+	c.lock.Unlock()
+
+	if err := c.publish(incidentCacheEvent{IncidentIDs: incidentIDs}); err != nil {
+		return errors.Wrapf(err, "failed to invalidate incidents %v on the other app nodes", incidentIDs)
+	}
+
+	return nil
+}
+
+// publish sends the invalidation to the other app nodes, if the server runs in a cluster.
+func (c *IncidentCache) publish(event incidentCacheEvent) error {
+	if !c.clustered || c.cluster == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal incident cache event")
+	}
+
+	return c.cluster.PublishPluginClusterEvent(incidentCacheEventID, data)
+}
+
+// makeRoom drops the expired incidents once the cache is full, and arbitrary ones if none has
+// expired. The lock must be held.
+func (c *IncidentCache) makeRoom() {
+	if len(c.incidents) < incidentCacheSize {
+		return
+	}
+
+	now := time.Now()
+	for id, cached := range c.incidents {
+		if now.After(cached.expires) {
+			delete(c.incidents, id)
+		}
+	}
+
+	for id := range c.incidents {
+		if len(c.incidents) < incidentCacheSize {
+			return
+		}
+		delete(c.incidents, id)
+	}
+}
+
+// purge drops everything the cache holds. The lock must be held.
+func (c *IncidentCache) purge() {
+	c.incidents = make(map[string]cachedIncident)
+	c.incidentIDs = make(map[string]string)
+	c.invalidations++
+}
@@ -0,0 +1,200 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+
+	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	mock_incident "github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	mock_sqlstore "github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore/mocks"
+)
+
+func TestIncidentCache(t *testing.T) {
+	setup := func(t *testing.T, clustered bool) (*IncidentCache, *mock_incident.MockStore, *mock_sqlstore.MockClusterAPI) {
+		mockCtrl := gomock.NewController(t)
+		store := mock_incident.NewMockStore(mockCtrl)
+		clusterAPI := mock_sqlstore.NewMockClusterAPI(mockCtrl)
+		configAPI := mock_sqlstore.NewMockConfigurationAPI(mockCtrl)
+		logger := mock_poster.NewMockLogger(mockCtrl)
+
+		config := &model.Config{}
+		config.ClusterSettings.Enable = model.NewBool(clustered)
+		configAPI.EXPECT().GetConfig().Return(config)
+
+		if clustered {
+			clusterAPI.EXPECT().PublishPluginClusterEvent(incidentCacheEventID, []byte(`{"all":true}`)).Return(nil)
+		}
+
+		cache := NewIncidentCache(store, PluginAPIClient{Configuration: configAPI, Cluster: clusterAPI}, logger)
+		cache.SetEnabled(true)
+
+		return cache, store, clusterAPI
+	}
+
+	theIncident := &incident.Incident{
+		ID:         model.NewId(),
+		Name:       "incident",
+		ChannelID:  model.NewId(),
+		Checklists: []playbook.Checklist{{Title: "checklist"}},
+	}
+
+	getIncident := func(string) (*incident.Incident, error) {
+		return theIncident.Clone(), nil
+	}
+
+	t.Run("reads are served from the cache until the incident is written", func(t *testing.T) {
+		cache, store, _ := setup(t, false)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(2)
+		store.EXPECT().UpdateIncident(gomock.Any()).Return(nil)
+
+		for i := 0; i < 3; i++ {
+			got, err := cache.GetIncident(theIncident.ID)
+			require.NoError(t, err)
+			require.Equal(t, theIncident.Name, got.Name)
+
+			// Changing the returned incident must not change the cached one.
+			got.Name = "changed"
+			got.Checklists[0].Title = "changed"
+		}
+
+		require.NoError(t, cache.UpdateIncident(theIncident))
+
+		got, err := cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+		require.Equal(t, "checklist", got.Checklists[0].Title)
+		require.Equal(t, IncidentCacheStats{Hits: 2, Misses: 2}, cache.Stats())
+	})
+
+	t.Run("a unit of work invalidates the incidents it writes", func(t *testing.T) {
+		cache, store, _ := setup(t, false)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(2)
+		store.EXPECT().Commit(gomock.Any()).Return(nil, nil)
+
+		_, err := cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		_, err = cache.Commit(&incident.UnitOfWork{
+			StatusPost: &incident.SQLStatusPost{IncidentID: theIncident.ID},
+		})
+		require.NoError(t, err)
+
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+		require.Equal(t, IncidentCacheStats{Hits: 0, Misses: 2}, cache.Stats())
+	})
+
+	t.Run("channel lookups are cached", func(t *testing.T) {
+		cache, store, _ := setup(t, false)
+
+		store.EXPECT().GetIncidentIDForChannel(theIncident.ChannelID).Return(theIncident.ID, nil)
+		store.EXPECT().GetIncidentIDForChannel("otherchannel").Return("", incident.ErrNotFound).Times(2)
+
+		for i := 0; i < 2; i++ {
+			incidentID, err := cache.GetIncidentIDForChannel(theIncident.ChannelID)
+			require.NoError(t, err)
+			require.Equal(t, theIncident.ID, incidentID)
+
+			_, err = cache.GetIncidentIDForChannel("otherchannel")
+			require.Equal(t, incident.ErrNotFound, err)
+		}
+	})
+
+	t.Run("disabling the cache drops what it holds", func(t *testing.T) {
+		cache, store, _ := setup(t, false)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(3)
+
+		_, err := cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		cache.SetEnabled(false)
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		cache.SetEnabled(true)
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("writes are sent to the other app nodes", func(t *testing.T) {
+		cache, store, clusterAPI := setup(t, true)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(2)
+		store.EXPECT().UpdateStatus(gomock.Any()).Return(nil)
+		clusterAPI.EXPECT().PublishPluginClusterEvent(incidentCacheEventID, gomock.Any()).DoAndReturn(func(id string, data []byte) error {
+			var event incidentCacheEvent
+			require.NoError(t, json.Unmarshal(data, &event))
+			require.Equal(t, incidentCacheEvent{IncidentIDs: []string{theIncident.ID}}, event)
+			return nil
+		})
+
+		_, err := cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, cache.UpdateStatus(&incident.SQLStatusPost{IncidentID: theIncident.ID}))
+
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+		require.Equal(t, IncidentCacheStats{Hits: 1, Misses: 2}, cache.Stats())
+	})
+
+	t.Run("writes on other app nodes evict the incidents", func(t *testing.T) {
+		cache, store, clusterAPI := setup(t, true)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(3)
+
+		_, err := cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		// Events for anything else are ignored.
+		cache.OnPluginClusterEvent("other_event", []byte(`{"all":true}`))
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		cache.OnPluginClusterEvent(incidentCacheEventID, []byte(`{"incident_ids":["`+theIncident.ID+`"]}`))
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+
+		cache.OnPluginClusterEvent(incidentCacheEventID, []byte(`{"all":true}`))
+		_, err = cache.GetIncident(theIncident.ID)
+		require.NoError(t, err)
+		require.Equal(t, IncidentCacheStats{Hits: 1, Misses: 3}, cache.Stats())
+
+		// Nuking the database purges the caches of the other app nodes too.
+		store.EXPECT().NukeDB().Return(nil)
+		clusterAPI.EXPECT().PublishPluginClusterEvent(incidentCacheEventID, []byte(`{"all":true}`)).Return(nil)
+		require.NoError(t, cache.NukeDB())
+	})
+
+	t.Run("the cache stays disabled in a cluster without cluster events", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		store := mock_incident.NewMockStore(mockCtrl)
+		configAPI := mock_sqlstore.NewMockConfigurationAPI(mockCtrl)
+		logger := mock_poster.NewMockLogger(mockCtrl)
+
+		config := &model.Config{}
+		config.ClusterSettings.Enable = model.NewBool(true)
+		configAPI.EXPECT().GetConfig().Return(config)
+		logger.EXPECT().Warnf(gomock.Any())
+
+		cache := NewIncidentCache(store, PluginAPIClient{Configuration: configAPI}, logger)
+		cache.SetEnabled(true)
+
+		store.EXPECT().GetIncident(theIncident.ID).DoAndReturn(getIncident).Times(2)
+		for i := 0; i < 2; i++ {
+			_, err := cache.GetIncident(theIncident.ID)
+			require.NoError(t, err)
+		}
+		require.Equal(t, IncidentCacheStats{}, cache.Stats())
+	})
+}
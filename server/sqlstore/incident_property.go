@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/pkg/errors"
+)
+
+// maxIncidentPropertyLength is the length of the Name and Value columns of IR_IncidentProperty.
+const maxIncidentPropertyLength = 255
+
+// incidentProperty is a row of IR_IncidentProperty, which denormalizes the property values of
+// the incidents so that they can be filtered and sorted on. Names and values are lowercased,
+// since filtering ignores case.
+type incidentProperty struct {
+	IncidentID  string
+	Name        string
+	Value       string
+	NumberValue *float64
+}
+
+// toIncidentProperties returns one row per value of each property in propertylist. Selection
+// properties store the names of their selected options, and number and datetime properties also
+// store their value as a number so that they sort numerically.
+func toIncidentProperties(incidentID string, propertylist playbook.Propertylist) []incidentProperty {
+	var rows []incidentProperty
+	for _, item := range propertylist.Items {
+		name := normalizePropertyText(item.Title)
+		if name == "" {
+			continue
+		}
+
+		var values []string
+		switch item.Type {
+		case playbook.PropertyTypeSelection:
+			for _, id := range item.Selection.SelectedIDs {
+				for _, option := range item.Selection.Items {
+					if option.ID == id {
+						values = append(values, option.Value)
+					}
+				}
+			}
+		default:
+			values = item.Values()
+		}
+
+		for _, value := range values {
+			row := incidentProperty{
+				IncidentID: incidentID,
+				Name:       name,
+				Value:      normalizePropertyText(value),
+			}
+			if item.Type == playbook.PropertyTypeNumber || item.Type == playbook.PropertyTypeDatetime {
+				if number, err := strconv.ParseFloat(value, 64); err == nil {
+					row.NumberValue = &number
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// normalizePropertyFilterValues returns the values to look for in IR_IncidentProperty.Value when
+// filtering by values: numbers are also looked for in the format they are stored with, so that
+// filtering by 3.0 finds the incidents with 3.
+func normalizePropertyFilterValues(values []string) []string {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		text := normalizePropertyText(value)
+		normalized = append(normalized, text)
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			if formatted := strconv.FormatFloat(number, 'f', -1, 64); formatted != text {
+				normalized = append(normalized, formatted)
+			}
+		}
+	}
+
+	return normalized
+}
+
+func normalizePropertyText(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if runes := []rune(text); len(runes) > maxIncidentPropertyLength {
+		text = string(runes[:maxIncidentPropertyLength])
+	}
+
+	return text
+}
+
+// replaceIncidentProperties replaces the IR_IncidentProperty rows of the incident with the
+// values of propertylist.
+func (sqlStore *SQLStore) replaceIncidentProperties(e execer, incidentID string, propertylist playbook.Propertylist) error {
+	if _, err := sqlStore.execBuilder(e, sq.
+		Delete("IR_IncidentProperty").
+		Where(sq.Eq{"IncidentID": incidentID})); err != nil {
+		return errors.Wrapf(err, "failed to delete the properties of incident with id '%s'", incidentID)
+	}
+
+	rows := toIncidentProperties(incidentID, propertylist)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	insert := sq.
+		Insert("IR_IncidentProperty").
+		Columns("IncidentID", "Name", "Value", "NumberValue")
+	for _, row := range rows {
+		insert = insert.Values(row.IncidentID, row.Name, row.Value, row.NumberValue)
+	}
+
+	if _, err := sqlStore.execBuilder(e, insert); err != nil {
+		return errors.Wrapf(err, "failed to store the properties of incident with id '%s'", incidentID)
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// IR_IncidentSearch holds a copy of the searchable text of each incident, with a native full-text
+// index: a FULLTEXT index over its columns on MySQL, and a weighted tsvector on Postgres. Its Name
+// column is left empty: the name of an incident is the display name of its channel, which can be
+// renamed at any time, so searches match it through the Channels table instead.
+
+// searchColumns are the columns of IR_IncidentSearch covered by the MySQL FULLTEXT index, which
+// MATCH must list in full.
+const searchColumns = "s.Name, s.Description, s.StatusUpdates, s.TimelineSummaries, s.ChecklistItems, s.Properties"
+
+// searchDocumentPG computes the tsvector of a row of IR_IncidentSearch on Postgres, from the most
+// relevant (B) to the least relevant (D) columns. The channel name is weighted A at search time.
+const searchDocumentPG = `
+	setweight(to_tsvector('english', Description), 'B') ||
+	setweight(to_tsvector('english', ChecklistItems), 'C') ||
+	setweight(to_tsvector('english', Properties), 'C') ||
+	setweight(to_tsvector('english', StatusUpdates), 'D') ||
+	setweight(to_tsvector('english', TimelineSummaries), 'D')`
+
+type sqlSearchResult struct {
+	sqlIncident
+	Score             float64
+	SearchName        string
+	SearchDescription string
+	StatusUpdates     string
+	TimelineSummaries string
+	ChecklistItems    string
+	Properties        string
+}
+
+// SearchIncidents returns the incidents matching the search terms, sorted by relevance, along
+// with the content they were matched on.
+func (s *incidentStore) SearchIncidents(requesterInfo incident.RequesterInfo, options incident.SearchOptions) (*incident.SearchResults, error) {
+	if err := incident.ValidateSearchOptions(&options); err != nil {
+		return nil, err
+	}
+
+	// The terms are only ever bound as parameters, and given to functions that treat them as
+	// plain words, so they need no escaping.
+	terms := strings.Join(incident.SearchTerms(options.Terms), " ")
+
+	var scoreExpr, matchExpr sq.Sqlizer
+	if s.store.db.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		document := "(s.Document || setweight(to_tsvector('english', c.DisplayName), 'A'))"
+		scoreExpr = sq.Expr(fmt.Sprintf("ts_rank(%s, plainto_tsquery('english', ?)) AS Score", document), terms)
+		matchExpr = sq.Expr(fmt.Sprintf("%s @@ plainto_tsquery('english', ?)", document), terms)
+	} else {
+		// Each term found in the channel name adds one to the score of the FULLTEXT index.
+		var nameMatches []string
+		var nameArgs []interface{}
+		for _, term := range incident.SearchTerms(options.Terms) {
+			nameMatches = append(nameMatches, "(c.DisplayName LIKE ?)")
+			nameArgs = append(nameArgs, fmt.Sprint("%", escapeLike(term), "%"))
+		}
+		fullText := fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", searchColumns)
+		args := append([]interface{}{terms}, nameArgs...)
+
+		scoreExpr = sq.Expr(fmt.Sprintf("%s + %s AS Score", fullText, strings.Join(nameMatches, " + ")), args...)
+		matchExpr = sq.Expr(fmt.Sprintf("(%s OR %s)", fullText, strings.Join(nameMatches, " OR ")), args...)
+	}
+
+	// Ask for one more result than needed to know whether there are more.
+	query := s.incidentSelect.
+		Column(scoreExpr).
+		Columns("c.DisplayName AS SearchName", "s.Description AS SearchDescription", "s.StatusUpdates",
+			"s.TimelineSummaries", "s.ChecklistItems", "s.Properties").
+		Join("IR_IncidentSearch AS s ON (s.IncidentID = i.ID)").
+		Where(s.buildPermissionsExpr(requesterInfo)).
+		Where(sq.Eq{"i.TeamID": options.TeamID}).
+		Where(matchExpr).
+		OrderBy("Score DESC", "c.CreateAt DESC").
+		Offset(uint64(options.Page * options.PerPage)).
+		Limit(uint64(options.PerPage + 1))
+
+	var rawResults []sqlSearchResult
+	if err := s.store.selectBuilder(s.store.db, &rawResults, query); err != nil {
+		return nil, errors.Wrap(err, "failed to search for incidents")
+	}
+
+	results := &incident.SearchResults{Items: make([]incident.SearchResult, 0, len(rawResults))}
+	if len(rawResults) > options.PerPage {
+		results.HasMore = true
+		rawResults = rawResults[:options.PerPage]
+	}
+
+	for _, rawResult := range rawResults {
+		asIncident, err := s.toIncident(rawResult.sqlIncident)
+		if err != nil {
+			return nil, err
+		}
+
+		results.Items = append(results.Items, incident.SearchResult{
+			Incident: *asIncident,
+			Score:    rawResult.Score,
+			Content: incident.SearchContent{
+				Name:              rawResult.SearchName,
+				Description:       rawResult.SearchDescription,
+				StatusUpdates:     rawResult.StatusUpdates,
+				TimelineSummaries: rawResult.TimelineSummaries,
+				ChecklistItems:    rawResult.ChecklistItems,
+				Properties:        rawResult.Properties,
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// updateIncidentSearch sets the given columns of the IR_IncidentSearch row of the incident,
+// creating the row if needed, and recomputes its full-text document on Postgres.
+func (sqlStore *SQLStore) updateIncidentSearch(q queryExecer, incidentID string, columns map[string]interface{}) error {
+	var count int
+	if err := sqlStore.getBuilder(q, &count, sqlStore.builder.
+		Select("COUNT(*)").
+		From("IR_IncidentSearch").
+		Where(sq.Eq{"IncidentID": incidentID})); err != nil {
+		return errors.Wrapf(err, "failed to check the search content of incident with id '%s'", incidentID)
+	}
+
+	if count == 0 {
+		if _, err := sqlStore.execBuilder(q, sq.
+			Insert("IR_IncidentSearch").
+			SetMap(map[string]interface{}{
+				"IncidentID":        incidentID,
+				"Name":              "",
+				"Description":       "",
+				"StatusUpdates":     "",
+				"TimelineSummaries": "",
+				"ChecklistItems":    "",
+				"Properties":        "",
+			})); err != nil {
+			return errors.Wrapf(err, "failed to create the search content of incident with id '%s'", incidentID)
+		}
+	}
+
+	if _, err := sqlStore.execBuilder(q, sq.
+		Update("IR_IncidentSearch").
+		SetMap(columns).
+		Where(sq.Eq{"IncidentID": incidentID})); err != nil {
+		return errors.Wrapf(err, "failed to update the search content of incident with id '%s'", incidentID)
+	}
+
+	// The document is computed from the columns as just updated, so in a separate statement.
+	if q.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		if _, err := sqlStore.execBuilder(q, sq.
+			Update("IR_IncidentSearch").
+			Set("Document", sq.Expr(searchDocumentPG)).
+			Where(sq.Eq{"IncidentID": incidentID})); err != nil {
+			return errors.Wrapf(err, "failed to index the search content of incident with id '%s'", incidentID)
+		}
+	}
+
+	return nil
+}
+
+// updateIncidentSearchContent indexes the description, checklist items and properties of the
+// incident, and its timeline summaries.
+func (sqlStore *SQLStore) updateIncidentSearchContent(q queryExecer, incidentID, description string, checklists []playbook.Checklist, propertylist playbook.Propertylist) error {
+	columns := map[string]interface{}{
+		"Description":    description,
+		"ChecklistItems": checklistItemsSearchContent(checklists),
+		"Properties":     propertiesSearchContent(propertylist),
+	}
+
+	timelineSummaries, err := sqlStore.timelineSearchContent(q, incidentID)
+	if err != nil {
+		return err
+	}
+	columns["TimelineSummaries"] = timelineSummaries
+
+	return sqlStore.updateIncidentSearch(q, incidentID, columns)
+}
+
+// updateIncidentSearchTimeline indexes the timeline summaries of the incident, after an event
+// was added, changed or deleted.
+func (sqlStore *SQLStore) updateIncidentSearchTimeline(q queryExecer, incidentID string) error {
+	timelineSummaries, err := sqlStore.timelineSearchContent(q, incidentID)
+	if err != nil {
+		return err
+	}
+
+	return sqlStore.updateIncidentSearch(q, incidentID, map[string]interface{}{
+		"TimelineSummaries": timelineSummaries,
+	})
+}
+
+// addIncidentSearchStatusUpdate indexes a new status update message of the incident.
+func (sqlStore *SQLStore) addIncidentSearchStatusUpdate(q queryExecer, incidentID, message string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+
+	return sqlStore.updateIncidentSearch(q, incidentID, map[string]interface{}{
+		"StatusUpdates": sq.Expr("CONCAT(StatusUpdates, ?)", "\n"+message),
+	})
+}
+
+func (sqlStore *SQLStore) timelineSearchContent(q queryer, incidentID string) (string, error) {
+	var summaries []string
+	if err := sqlStore.selectBuilder(q, &summaries, sqlStore.builder.
+		Select("Summary").
+		From("IR_TimelineEvent").
+		Where(sq.Eq{"IncidentID": incidentID}).
+		Where(sq.Eq{"DeleteAt": 0}).
+		OrderBy("EventAt")); err != nil {
+		return "", errors.Wrapf(err, "failed to get the timeline summaries of incident with id '%s'", incidentID)
+	}
+
+	return strings.Join(summaries, "\n"), nil
+}
+
+func checklistItemsSearchContent(checklists []playbook.Checklist) string {
+	var titles []string
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			titles = append(titles, item.Title)
+		}
+	}
+
+	return strings.Join(titles, "\n")
+}
+
+// propertiesSearchContent returns the freetext properties, one "Title: value" per line.
+func propertiesSearchContent(propertylist playbook.Propertylist) string {
+	var lines []string
+	for _, item := range propertylist.Items {
+		if item.Type != playbook.PropertyTypeFreetext && item.Type != "" {
+			continue
+		}
+		if values := item.Values(); len(values) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", item.Title, strings.Join(values, ", ")))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// searchContentFromJSON is used to index the incidents that were created before search existed.
+func searchContentFromJSON(checklistsJSON, propertylistJSON json.RawMessage) ([]playbook.Checklist, playbook.Propertylist, error) {
+	var checklists []playbook.Checklist
+	if len(checklistsJSON) > 0 {
+		if err := json.Unmarshal(checklistsJSON, &checklists); err != nil {
+			return nil, playbook.Propertylist{}, errors.Wrap(err, "failed to unmarshal checklists json")
+		}
+	}
+
+	var propertylist playbook.Propertylist
+	if len(propertylistJSON) > 0 {
+		if err := json.Unmarshal(propertylistJSON, &propertylist); err != nil {
+			return nil, playbook.Propertylist{}, errors.Wrap(err, "failed to unmarshal propertylist json")
+		}
+	}
+
+	return checklists, propertylist, nil
+}
@@ -10,6 +10,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	mock_sqlstore "github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore/mocks"
@@ -667,6 +668,51 @@ func TestGetIncidents(t *testing.T) {
 			},
 			ExpectedErr: nil,
 		},
+		{
+			Name: "team1 and team2 - Admin",
+			RequesterInfo: incident.RequesterInfo{
+				UserID:          lucy.ID,
+				UserIDtoIsAdmin: map[string]bool{lucy.ID: true},
+			},
+			Options: incident.FilterOptions{
+				TeamIDs: []string{team1id, team2id},
+			},
+			Want: incident.GetIncidentsResults{
+				TotalCount: 7,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []incident.Incident{inc01, inc02, inc03, inc04, inc05, inc06, inc07},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "all teams - Charlotte (in no channels but member of team1 and team2, can see their public incidents)",
+			RequesterInfo: incident.RequesterInfo{
+				UserID: charlotte.ID,
+			},
+			Options: incident.FilterOptions{},
+			Want: incident.GetIncidentsResults{
+				TotalCount: 4,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []incident.Incident{inc01, inc02, inc03, inc06},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "all teams - Alice (in no channels but member of team1, can see its public incidents)",
+			RequesterInfo: incident.RequesterInfo{
+				UserID: alice.ID,
+			},
+			Options: incident.FilterOptions{},
+			Want: incident.GetIncidentsResults{
+				TotalCount: 3,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []incident.Incident{inc01, inc02, inc03},
+			},
+			ExpectedErr: nil,
+		},
 		{
 			Name: "team1 - Admin gets incidents with John as member",
 			RequesterInfo: incident.RequesterInfo{
@@ -797,6 +843,10 @@ func TestGetIncidents(t *testing.T) {
 					result.Items[i] = item
 				}
 
+				// The cursor of the next page depends on the IDs of the incidents.
+				require.Equal(t, result.HasMore, result.NextCursor != "")
+				result.NextCursor = ""
+
 				require.Equal(t, testCase.Want, *result)
 			})
 		}
@@ -1046,6 +1096,7 @@ func TestUpdateIncident(t *testing.T) {
 				}
 
 				require.NoError(t, err)
+				require.Equal(t, returned.Version+1, expected.Version)
 
 				actual, err := incidentStore.GetIncident(expected.ID)
 				require.NoError(t, err)
@@ -1055,6 +1106,245 @@ func TestUpdateIncident(t *testing.T) {
 	}
 }
 
+func TestCommit(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		incidentStore := setupIncidentStore(t, db)
+		_, store := setupSQLStore(t, db)
+
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		t.Run(driverName+" - incident, status post, timeline events and outbox are saved together", func(t *testing.T) {
+			returned, err := incidentStore.CreateIncident(NewBuilder(t).WithChecklists([]int{2}).ToIncident())
+			require.NoError(t, err)
+			createIncidentChannel(t, store, returned)
+
+			post := newPost(false)
+			savePosts(t, store, []*model.Post{post})
+
+			returned.Checklists[0].Items[0].State = playbook.ChecklistItemStateClosed
+			returned.Checklists[0].Items[1].State = playbook.ChecklistItemStateClosed
+			events := []*incident.TimelineEvent{
+				{
+					IncidentID: returned.ID,
+					EventAt:    model.GetMillis(),
+					EventType:  incident.TaskStateModified,
+					Summary:    "modified the state of 2 checklist items",
+				},
+			}
+
+			var outboxVersion int64
+			messages, err := incidentStore.Commit(&incident.UnitOfWork{
+				Incident: returned,
+				StatusPost: &incident.SQLStatusPost{
+					IncidentID: returned.ID,
+					PostID:     post.Id,
+					Status:     incident.StatusResolved,
+				},
+				TimelineEvents: events,
+				Outbox: func() ([]*incident.OutboxMessage, error) {
+					outboxVersion = returned.Version
+					return []*incident.OutboxMessage{
+						{ID: model.NewId(), CreateAt: 1, Type: "test", Payload: []byte(`{"version":1}`), NextAttemptAt: 10},
+					}, nil
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+
+			actual, err := incidentStore.GetIncident(returned.ID)
+			require.NoError(t, err)
+			require.Equal(t, playbook.ChecklistItemStateClosed, actual.Checklists[0].Items[0].State)
+			require.Equal(t, playbook.ChecklistItemStateClosed, actual.Checklists[0].Items[1].State)
+			require.Equal(t, incident.StatusResolved, actual.CurrentStatus())
+			require.Len(t, actual.TimelineEvents, 1)
+			require.Equal(t, events[0].ID, actual.TimelineEvents[0].ID)
+			require.Equal(t, int64(1), actual.Version)
+			require.Equal(t, actual.Version, returned.Version)
+			require.Equal(t, actual.Version, outboxVersion)
+
+			due, err := incidentStore.GetDueOutboxMessages(10, 100)
+			require.NoError(t, err)
+			require.Len(t, due, 1)
+			require.Equal(t, messages[0].ID, due[0].ID)
+			require.Equal(t, "test", due[0].Type)
+			require.JSONEq(t, `{"version":1}`, string(due[0].Payload))
+
+			require.NoError(t, incidentStore.DeleteOutboxMessages([]string{due[0].ID}))
+		})
+
+		t.Run(driverName+" - nothing is saved if a timeline event is invalid", func(t *testing.T) {
+			returned, err := incidentStore.CreateIncident(NewBuilder(t).WithChecklists([]int{1}).ToIncident())
+			require.NoError(t, err)
+			createIncidentChannel(t, store, returned)
+
+			updated := returned.Clone()
+			updated.Checklists[0].Items[0].State = playbook.ChecklistItemStateClosed
+
+			_, err = incidentStore.Commit(&incident.UnitOfWork{
+				Incident: updated,
+				StatusPost: &incident.SQLStatusPost{
+					IncidentID: returned.ID,
+					PostID:     model.NewId(),
+					Status:     incident.StatusResolved,
+				},
+				TimelineEvents: []*incident.TimelineEvent{{IncidentID: returned.ID}},
+				Outbox: func() ([]*incident.OutboxMessage, error) {
+					return []*incident.OutboxMessage{{ID: model.NewId(), Type: "test", Payload: []byte(`{}`)}}, nil
+				},
+			})
+			require.Error(t, err)
+
+			actual, err := incidentStore.GetIncident(returned.ID)
+			require.NoError(t, err)
+			require.Equal(t, returned.Checklists[0].Items[0].State, actual.Checklists[0].Items[0].State)
+			require.Equal(t, returned.CurrentStatus(), actual.CurrentStatus())
+			require.Empty(t, actual.StatusPosts)
+			require.Empty(t, actual.TimelineEvents)
+			require.Equal(t, returned.Version, actual.Version)
+
+			due, err := incidentStore.GetDueOutboxMessages(model.GetMillis(), 100)
+			require.NoError(t, err)
+			require.Empty(t, due)
+		})
+
+		t.Run(driverName+" - nothing is saved if the outbox fails", func(t *testing.T) {
+			returned, err := incidentStore.CreateIncident(NewBuilder(t).ToIncident())
+			require.NoError(t, err)
+			createIncidentChannel(t, store, returned)
+
+			updated := returned.Clone()
+			updated.CommanderUserID = model.NewId()
+
+			_, err = incidentStore.Commit(&incident.UnitOfWork{
+				Incident: updated,
+				Outbox: func() ([]*incident.OutboxMessage, error) {
+					return nil, errors.New("failed to build the outbox")
+				},
+			})
+			require.Error(t, err)
+
+			actual, err := incidentStore.GetIncident(returned.ID)
+			require.NoError(t, err)
+			require.Equal(t, returned.CommanderUserID, actual.CommanderUserID)
+		})
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		incidentStore := setupIncidentStore(t, db)
+
+		t.Run(driverName+" - due messages are returned in order, and can be rescheduled", func(t *testing.T) {
+			first := &incident.OutboxMessage{ID: model.NewId(), CreateAt: 1, Type: "test", Payload: []byte(`{}`), NextAttemptAt: 100}
+			second := &incident.OutboxMessage{ID: model.NewId(), CreateAt: 2, Type: "test", Payload: []byte(`{}`), NextAttemptAt: 50}
+			later := &incident.OutboxMessage{ID: model.NewId(), CreateAt: 3, Type: "test", Payload: []byte(`{}`), NextAttemptAt: 500}
+			_, err := incidentStore.Commit(&incident.UnitOfWork{
+				Outbox: func() ([]*incident.OutboxMessage, error) {
+					return []*incident.OutboxMessage{first, second, later}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			due, err := incidentStore.GetDueOutboxMessages(100, 10)
+			require.NoError(t, err)
+			require.Len(t, due, 2)
+			require.Equal(t, first.ID, due[0].ID)
+			require.Equal(t, second.ID, due[1].ID)
+
+			due, err = incidentStore.GetDueOutboxMessages(100, 1)
+			require.NoError(t, err)
+			require.Len(t, due, 1)
+
+			first.Attempts = 1
+			first.LastError = "failed"
+			first.NextAttemptAt = 1000
+			require.NoError(t, incidentStore.UpdateOutboxMessage(first))
+
+			due, err = incidentStore.GetDueOutboxMessages(1000, 10)
+			require.NoError(t, err)
+			require.Len(t, due, 3)
+			require.Equal(t, first.ID, due[0].ID)
+			require.Equal(t, 1, due[0].Attempts)
+			require.Equal(t, "failed", due[0].LastError)
+
+			require.NoError(t, incidentStore.DeleteOutboxMessages([]string{first.ID, second.ID, later.ID}))
+
+			due, err = incidentStore.GetDueOutboxMessages(1000, 10)
+			require.NoError(t, err)
+			require.Empty(t, due)
+		})
+	}
+}
+
+func TestGetIncidentsSummary(t *testing.T) {
+	requesterInfo := incident.RequesterInfo{
+		UserID:          "testID",
+		UserIDtoIsAdmin: map[string]bool{"testID": true},
+	}
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		incidentStore := setupIncidentStore(t, db)
+		_, store := setupSQLStore(t, db)
+
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		teamID := model.NewId()
+		returned, err := incidentStore.CreateIncident(NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("summarized incident").
+			WithChecklists([]int{2, 1}).
+			ToIncident())
+		require.NoError(t, err)
+		createIncidentChannel(t, store, returned)
+
+		returned.Checklists[0].Items[0].State = playbook.ChecklistItemStateClosed
+		returned.Checklists[1].Items[0].State = playbook.ChecklistItemStateSkipped
+		require.NoError(t, incidentStore.UpdateIncident(returned))
+
+		post := newPost(false)
+		savePosts(t, store, []*model.Post{post})
+		require.NoError(t, incidentStore.UpdateStatus(&incident.SQLStatusPost{
+			IncidentID: returned.ID,
+			PostID:     post.Id,
+			Status:     incident.StatusActive,
+		}))
+
+		t.Run(driverName+" - summary leaves out the heavy fields", func(t *testing.T) {
+			results, err := incidentStore.GetIncidents(requesterInfo, incident.FilterOptions{
+				TeamID: teamID,
+				Fields: incident.FieldsSummary,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, results.TotalCount)
+			require.Len(t, results.Items, 1)
+
+			summary := results.Items[0]
+			require.Equal(t, returned.ID, summary.ID)
+			require.Equal(t, "summarized incident", summary.Name)
+			require.Equal(t, returned.CommanderUserID, summary.CommanderUserID)
+			require.Equal(t, returned.Version, summary.Version)
+			require.Equal(t, &incident.Summary{Status: incident.StatusActive, TasksTotal: 3, TasksDone: 2}, summary.Summary)
+			require.Equal(t, incident.StatusActive, summary.CurrentStatus())
+			require.Empty(t, summary.Checklists)
+			require.Empty(t, summary.StatusPosts)
+			require.Empty(t, summary.TimelineEvents)
+		})
+
+		t.Run(driverName+" - full incidents have no summary", func(t *testing.T) {
+			results, err := incidentStore.GetIncidents(requesterInfo, incident.FilterOptions{TeamID: teamID})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+			require.Nil(t, results.Items[0].Summary)
+			require.Len(t, results.Items[0].Checklists, 2)
+		})
+	}
+}
+
 // intended to catch problems with the code assembling StatusPosts
 func TestStressTestGetIncidents(t *testing.T) {
 	rand.Seed(time.Now().UTC().UnixNano())
@@ -1100,6 +1390,156 @@ func TestStressTestGetIncidents(t *testing.T) {
 				}
 			}
 		})
+
+		t.Run("stress test summary retrieval", func(t *testing.T) {
+			for _, p := range verifyPages {
+				returned, err := incidentStore.GetIncidents(incident.RequesterInfo{
+					UserID:          "testID",
+					UserIDtoIsAdmin: map[string]bool{"testID": true},
+				}, incident.FilterOptions{
+					TeamID:  teamID,
+					Page:    p,
+					PerPage: perPage,
+					Sort:    "create_at",
+					Fields:  incident.FieldsSummary,
+				})
+				require.NoError(t, err)
+				numRet := min(perPage, len(withPosts))
+				require.Equal(t, numRet, len(returned.Items))
+				for i := 0; i < numRet; i++ {
+					idx := p*perPage + i
+					assert.Equal(t, withPosts[idx].ID, returned.Items[i].ID)
+					assert.Equal(t, withPosts[idx].Name, returned.Items[i].Name)
+					assert.Equal(t, 1, returned.Items[i].Summary.TasksTotal)
+				}
+			}
+		})
+	}
+}
+
+func TestStressTestGetIncidentsWithCursor(t *testing.T) {
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	// Change these to larger numbers to stress test. Keep them low for CI.
+	numIncidents := 100
+	postsPerIncident := 3
+	perPage := 7
+
+	requesterInfo := incident.RequesterInfo{
+		UserID:          "testID",
+		UserIDtoIsAdmin: map[string]bool{"testID": true},
+	}
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		incidentStore := setupIncidentStore(t, db)
+		_, store := setupSQLStore(t, db)
+
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+		teamID := model.NewId()
+		withPosts := createIncidentsAndPosts(t, store, incidentStore, numIncidents, postsPerIncident, teamID)
+
+		t.Run("cursor pages match offset pages", func(t *testing.T) {
+			for _, sort := range []string{incident.SortByCreateAt, incident.SortByName, incident.SortByID, incident.SortByStatus} {
+				for _, direction := range []string{incident.DirectionAsc, incident.DirectionDesc} {
+					options := incident.FilterOptions{
+						TeamID:    teamID,
+						PerPage:   perPage,
+						Sort:      sort,
+						Direction: direction,
+					}
+
+					var offsetIDs []string
+					for page := 0; ; page++ {
+						options.Page = page
+						returned, err := incidentStore.GetIncidents(requesterInfo, options)
+						require.NoError(t, err)
+						for _, i := range returned.Items {
+							offsetIDs = append(offsetIDs, i.ID)
+						}
+						if !returned.HasMore {
+							break
+						}
+					}
+
+					options.Page = 0
+					var cursorIDs []string
+					for {
+						returned, err := incidentStore.GetIncidents(requesterInfo, options)
+						require.NoError(t, err)
+						require.LessOrEqual(t, len(returned.Items), perPage)
+						for _, i := range returned.Items {
+							cursorIDs = append(cursorIDs, i.ID)
+						}
+						if !returned.HasMore {
+							require.Empty(t, returned.NextCursor)
+							break
+						}
+						require.NotEmpty(t, returned.NextCursor)
+						options.Cursor = returned.NextCursor
+					}
+
+					require.Len(t, cursorIDs, numIncidents, "%s %s", sort, direction)
+					require.Equal(t, offsetIDs, cursorIDs, "%s %s", sort, direction)
+				}
+			}
+		})
+
+		t.Run("incidents created while paging do not shift the pages", func(t *testing.T) {
+			options := incident.FilterOptions{
+				TeamID:    teamID,
+				PerPage:   perPage,
+				Sort:      incident.SortByCreateAt,
+				Direction: incident.DirectionDesc,
+			}
+
+			seen := make(map[string]bool)
+			for page := 0; ; page++ {
+				returned, err := incidentStore.GetIncidents(requesterInfo, options)
+				require.NoError(t, err)
+				for _, i := range returned.Items {
+					require.False(t, seen[i.ID], "incident %s returned twice", i.ID)
+					seen[i.ID] = true
+				}
+				if !returned.HasMore {
+					break
+				}
+				options.Cursor = returned.NextCursor
+
+				// A newer incident sorts before all the pages already returned.
+				inc := NewBuilder(t).
+					WithTeamID(teamID).
+					WithCreateAt(int64(200000 + page)).
+					WithName(fmt.Sprintf("new incident %d", page)).
+					ToIncident()
+				created, err := incidentStore.CreateIncident(inc)
+				require.NoError(t, err)
+				createIncidentChannel(t, store, created)
+			}
+
+			for _, i := range withPosts {
+				require.True(t, seen[i.ID], "incident %s not returned", i.ID)
+			}
+		})
+
+		t.Run("cursor with another sort", func(t *testing.T) {
+			returned, err := incidentStore.GetIncidents(requesterInfo, incident.FilterOptions{
+				TeamID:  teamID,
+				PerPage: perPage,
+				Sort:    incident.SortByCreateAt,
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, returned.NextCursor)
+
+			_, err = incidentStore.GetIncidents(requesterInfo, incident.FilterOptions{
+				TeamID:  teamID,
+				PerPage: perPage,
+				Sort:    incident.SortByName,
+				Cursor:  returned.NextCursor,
+			})
+			require.True(t, errors.Is(err, cursor.ErrInvalid))
+		})
 	}
 }
 
@@ -1150,6 +1590,90 @@ func TestStressTestGetIncidentsStats(t *testing.T) {
 			fmt.Printf("Mean: %.2f\tStdErr: %.2f\t95%% CI: (%.2f, %.2f)\n",
 				mean(intervals), stdErr(intervals), cil, ciu)
 		})
+
+		t.Run("stress test summary retrieval", func(t *testing.T) {
+			intervals := make([]int64, 0, numReps)
+			for i := 0; i < numReps; i++ {
+				start := time.Now()
+				_, err := incidentStore.GetIncidents(incident.RequesterInfo{
+					UserID:          "testID",
+					UserIDtoIsAdmin: map[string]bool{"testID": true},
+				}, incident.FilterOptions{
+					TeamID:  teamID,
+					Page:    i,
+					PerPage: perPage,
+					Sort:    "create_at",
+					Fields:  incident.FieldsSummary,
+				})
+				intervals = append(intervals, time.Since(start).Milliseconds())
+				require.NoError(t, err)
+			}
+			cil, ciu := ciForN30(intervals)
+			fmt.Printf("Summary mean: %.2f\tStdErr: %.2f\t95%% CI: (%.2f, %.2f)\n",
+				mean(intervals), stdErr(intervals), cil, ciu)
+		})
+	}
+}
+
+// BenchmarkGetIncidentsOffset and BenchmarkGetIncidentsCursor page through all the incidents of a
+// team, by offset and by cursor. Change numIncidents to a larger number to compare them.
+func BenchmarkGetIncidentsOffset(b *testing.B) {
+	benchmarkGetIncidents(b, false, "")
+}
+
+func BenchmarkGetIncidentsCursor(b *testing.B) {
+	benchmarkGetIncidents(b, true, "")
+}
+
+// BenchmarkGetIncidentsSummary pages through the summaries of the incidents, to compare with
+// BenchmarkGetIncidentsCursor.
+func BenchmarkGetIncidentsSummary(b *testing.B) {
+	benchmarkGetIncidents(b, true, incident.FieldsSummary)
+}
+
+func benchmarkGetIncidents(b *testing.B, withCursor bool, fields string) {
+	numIncidents := 1000
+	postsPerIncident := 3
+	perPage := 50
+
+	requesterInfo := incident.RequesterInfo{
+		UserID:          "testID",
+		UserIDtoIsAdmin: map[string]bool{"testID": true},
+	}
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(b, driverName)
+		incidentStore := setupIncidentStore(b, db)
+		_, store := setupSQLStore(b, db)
+
+		setupChannelsTable(b, db)
+		setupPostsTable(b, db)
+		teamID := model.NewId()
+		_ = createIncidentsAndPosts(b, store, incidentStore, numIncidents, postsPerIncident, teamID)
+
+		b.Run(driverName, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				options := incident.FilterOptions{
+					TeamID:    teamID,
+					PerPage:   perPage,
+					Sort:      incident.SortByCreateAt,
+					Direction: incident.DirectionDesc,
+					Fields:    fields,
+				}
+				for {
+					returned, err := incidentStore.GetIncidents(requesterInfo, options)
+					require.NoError(b, err)
+					if !returned.HasMore {
+						break
+					}
+					if withCursor {
+						options.Cursor = returned.NextCursor
+					} else {
+						options.Page++
+					}
+				}
+			}
+		})
 	}
 }
 
@@ -1228,6 +1752,49 @@ func TestGetIncidentIDForChannel(t *testing.T) {
 	}
 }
 
+func TestGetIncidentCountsByStatus(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		incidentStore := setupIncidentStore(t, db)
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		t.Run(driverName+" - no incidents", func(t *testing.T) {
+			counts, err := incidentStore.GetIncidentCountsByStatus()
+			require.NoError(t, err)
+			require.Empty(t, counts)
+		})
+
+		t.Run(driverName+" - incidents by status", func(t *testing.T) {
+			var incidentIDs []string
+			for i := 0; i < 3; i++ {
+				returned, err := incidentStore.CreateIncident(NewBuilder(t).ToIncident())
+				require.NoError(t, err)
+				createIncidentChannel(t, store, returned)
+				incidentIDs = append(incidentIDs, returned.ID)
+			}
+
+			for _, incidentID := range incidentIDs[1:] {
+				post := newPost(false)
+				savePosts(t, store, []*model.Post{post})
+				require.NoError(t, incidentStore.UpdateStatus(&incident.SQLStatusPost{
+					IncidentID: incidentID,
+					PostID:     post.Id,
+					Status:     incident.StatusResolved,
+				}))
+			}
+
+			counts, err := incidentStore.GetIncidentCountsByStatus()
+			require.NoError(t, err)
+			require.Equal(t, map[string]int64{
+				incident.StatusReported: 1,
+				incident.StatusResolved: 2,
+			}, counts)
+		})
+	}
+}
+
 func TestGetCommanders(t *testing.T) {
 	team1id := model.NewId()
 	team2id := model.NewId()
@@ -1440,8 +2007,38 @@ func TestGetCommanders(t *testing.T) {
 				UserIDtoIsAdmin: map[string]bool{lucy.ID: true},
 			},
 			Options:     incident.FilterOptions{},
-			Expected:    nil,
-			ExpectedErr: errors.New("bad parameter 'team_id': must be 26 characters"),
+			Expected:    commanders,
+			ExpectedErr: nil,
+		},
+		{
+			Name: "no team - Alice (member of team1 only, can see its public incidents)",
+			RequesterInfo: incident.RequesterInfo{
+				UserID: alice.ID,
+			},
+			Options:     incident.FilterOptions{},
+			Expected:    []incident.CommanderInfo{commander1, commander2},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "no team - Charlotte (member of team1 and team2, can see their public incidents)",
+			RequesterInfo: incident.RequesterInfo{
+				UserID: charlotte.ID,
+			},
+			Options:     incident.FilterOptions{},
+			Expected:    []incident.CommanderInfo{commander1, commander2, commander3},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "team1 and team3 - admin",
+			RequesterInfo: incident.RequesterInfo{
+				UserID:          lucy.ID,
+				UserIDtoIsAdmin: map[string]bool{lucy.ID: true},
+			},
+			Options: incident.FilterOptions{
+				TeamIDs: []string{team1id, team3id},
+			},
+			Expected:    []incident.CommanderInfo{commander1, commander2, commander3, commander4},
+			ExpectedErr: nil,
 		},
 	}
 
@@ -1503,6 +2100,213 @@ func TestGetCommanders(t *testing.T) {
 	}
 }
 
+func TestGetIncidentsWithPropertyFilters(t *testing.T) {
+	teamID := model.NewId()
+	playbookID := model.NewId()
+	admin := incident.RequesterInfo{
+		UserID:          model.NewId(),
+		UserIDtoIsAdmin: map[string]bool{},
+	}
+	admin.UserIDtoIsAdmin[admin.UserID] = true
+
+	withProperties := func(name string, createAt int64, impact string, severity float64) *incident.Incident {
+		i := NewBuilder(t).
+			WithName(name).
+			WithTeamID(teamID).
+			WithCreateAt(createAt).
+			ToIncident()
+		i.PlaybookID = playbookID
+		i.Propertylist = playbook.Propertylist{
+			Items: []playbook.PropertylistItem{
+				{
+					Title: "Impact",
+					Type:  playbook.PropertyTypeSelection,
+					Selection: playbook.Selectionlist{
+						Items:       []playbook.SelectionlistItem{{ID: "low", Value: "Low"}, {ID: "high", Value: "High"}},
+						SelectedIDs: []string{impact},
+					},
+				},
+				{
+					Title:  "Severity",
+					Type:   playbook.PropertyTypeNumber,
+					Number: &playbook.NumberOption{Value: &severity},
+				},
+			},
+		}
+		return i
+	}
+
+	incident1 := withProperties("incident 1", 1000, "high", 10)
+	incident2 := withProperties("incident 2", 2000, "low", 2)
+	incident3 := withProperties("incident 3", 3000, "high", 3)
+	incident4 := NewBuilder(t).WithName("incident 4").WithTeamID(teamID).WithCreateAt(4000).ToIncident()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		incidentStore := setupIncidentStore(t, db)
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		names := make(map[string]string)
+		ids := make(map[string]string)
+		for _, i := range []*incident.Incident{incident1, incident2, incident3, incident4} {
+			created, err := incidentStore.CreateIncident(i)
+			require.NoError(t, err)
+			createIncidentChannel(t, store, created)
+			names[created.ID] = created.Name
+			ids[created.Name] = created.ID
+		}
+
+		getNames := func(t *testing.T, options incident.FilterOptions) []string {
+			options.TeamID = teamID
+			result, err := incidentStore.GetIncidents(admin, options)
+			require.NoError(t, err)
+
+			var found []string
+			for _, i := range result.Items {
+				found = append(found, names[i.ID])
+			}
+			return found
+		}
+
+		t.Run("filter by selection property", func(t *testing.T) {
+			found := getNames(t, incident.FilterOptions{
+				PropertyValues: map[string][]string{"impact": {"HIGH"}},
+			})
+			require.Equal(t, []string{"incident 1", "incident 3"}, found)
+		})
+
+		t.Run("filter by number property", func(t *testing.T) {
+			found := getNames(t, incident.FilterOptions{
+				PropertyValues: map[string][]string{"Severity": {"2.0", "3"}},
+			})
+			require.Equal(t, []string{"incident 2", "incident 3"}, found)
+		})
+
+		t.Run("filter by playbook and creation date", func(t *testing.T) {
+			found := getNames(t, incident.FilterOptions{
+				PlaybookID:    playbookID,
+				CreatedAfter:  2000,
+				CreatedBefore: 4000,
+			})
+			require.Equal(t, []string{"incident 2", "incident 3"}, found)
+		})
+
+		t.Run("sort by number property", func(t *testing.T) {
+			found := getNames(t, incident.FilterOptions{
+				Sort:         incident.SortByProperty,
+				SortProperty: "Severity",
+				Direction:    incident.DirectionDesc,
+			})
+			require.Equal(t, []string{"incident 1", "incident 3", "incident 2", "incident 4"}, found)
+		})
+
+		t.Run("properties are replaced on update", func(t *testing.T) {
+			updated, err := incidentStore.GetIncident(ids["incident 2"])
+			require.NoError(t, err)
+			updated.Propertylist.Items[0].Selection.SelectedIDs = []string{"high"}
+			require.NoError(t, incidentStore.UpdateIncident(updated))
+
+			found := getNames(t, incident.FilterOptions{
+				PropertyValues: map[string][]string{"Impact": {"Low"}},
+			})
+			require.Empty(t, found)
+		})
+	}
+}
+
+func TestSearchIncidents(t *testing.T) {
+	teamID := model.NewId()
+	admin := incident.RequesterInfo{
+		UserID:          model.NewId(),
+		UserIDtoIsAdmin: map[string]bool{},
+	}
+	admin.UserIDtoIsAdmin[admin.UserID] = true
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		incidentStore := setupIncidentStore(t, db)
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		create := func(name, description string) *incident.Incident {
+			newIncident := NewBuilder(t).WithName(name).WithDescription(description).WithTeamID(teamID).ToIncident()
+			created, err := incidentStore.CreateIncident(newIncident)
+			require.NoError(t, err)
+			createIncidentChannel(t, store, created)
+			return created
+		}
+
+		databaseIncident := create("Database outage", "The primary database is down")
+		databaseID, databaseChannelID := databaseIncident.ID, databaseIncident.ChannelID
+		networkID := create("Network issues", "Packet loss between regions").ID
+		create("Login errors", "Users see 100% failures_on login")
+
+		t.Run("matches name and description, most relevant first", func(t *testing.T) {
+			results, err := incidentStore.SearchIncidents(admin, incident.SearchOptions{TeamID: teamID, Terms: "database"})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+			require.Equal(t, databaseID, results.Items[0].Incident.ID)
+			require.Equal(t, "The primary database is down", results.Items[0].Content.Description)
+		})
+
+		t.Run("matches status updates and timeline summaries", func(t *testing.T) {
+			err := incidentStore.UpdateStatus(&incident.SQLStatusPost{
+				IncidentID: networkID,
+				PostID:     model.NewId(),
+				Status:     incident.StatusActive,
+				Message:    "Rerouting traffic through the backup carrier",
+			})
+			require.NoError(t, err)
+
+			_, err = incidentStore.CreateTimelineEvent(&incident.TimelineEvent{
+				IncidentID: networkID,
+				EventType:  incident.IncidentCreated,
+				Summary:    "Escalated to the carrier",
+			})
+			require.NoError(t, err)
+
+			results, err := incidentStore.SearchIncidents(admin, incident.SearchOptions{TeamID: teamID, Terms: "carrier"})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+			require.Equal(t, networkID, results.Items[0].Incident.ID)
+			require.Contains(t, results.Items[0].Content.StatusUpdates, "backup carrier")
+			require.Contains(t, results.Items[0].Content.TimelineSummaries, "Escalated")
+		})
+
+		t.Run("matches the current name of a renamed channel", func(t *testing.T) {
+			_, err := store.execBuilder(store.db, store.builder.
+				Update("Channels").
+				Set("DisplayName", "Storage outage").
+				Where(sq.Eq{"Id": databaseChannelID}))
+			require.NoError(t, err)
+
+			results, err := incidentStore.SearchIncidents(admin, incident.SearchOptions{TeamID: teamID, Terms: "storage"})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+			require.Equal(t, databaseID, results.Items[0].Incident.ID)
+			require.Equal(t, "Storage outage", results.Items[0].Content.Name)
+
+			results, err = incidentStore.SearchIncidents(admin, incident.SearchOptions{TeamID: teamID, Terms: "outage"})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+
+			results, err = incidentStore.SearchIncidents(admin, incident.SearchOptions{TeamID: teamID, Terms: "regions"})
+			require.NoError(t, err)
+			require.Len(t, results.Items, 1)
+			require.Equal(t, networkID, results.Items[0].Incident.ID)
+		})
+
+		t.Run("name search escapes wildcards", func(t *testing.T) {
+			result, err := incidentStore.GetIncidents(admin, incident.FilterOptions{TeamID: teamID, SearchTerm: "%"})
+			require.NoError(t, err)
+			require.Empty(t, result.Items)
+		})
+	}
+}
+
 func TestNukeDB(t *testing.T) {
 	team1id := model.NewId()
 
@@ -425,6 +425,671 @@ var migrations = []Migration{
 				}
 			}
 
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.7.0"),
+		toVersion:   semver.MustParse("0.8.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			// Multiselect property values used to be stored comma-joined: reading the
+			// propertylists converts them, so write them back in the new format.
+			for _, table := range []string{"IR_Incident", "IR_Playbook"} {
+				var rows []struct {
+					ID               string
+					PropertylistJSON json.RawMessage
+				}
+				if err := sqlStore.selectBuilder(e, &rows, sqlStore.builder.
+					Select("ID", "PropertylistJSON").
+					From(table)); err != nil {
+					return errors.Wrapf(err, "failed getting propertylists from table %s", table)
+				}
+
+				for _, row := range rows {
+					if len(row.PropertylistJSON) == 0 {
+						continue
+					}
+
+					var propertylist playbook.Propertylist
+					if err := json.Unmarshal(row.PropertylistJSON, &propertylist); err != nil {
+						sqlStore.log.Warnf("failed to unmarshal propertylist json for id '%s' in table %s: %v", row.ID, table, err)
+						continue
+					}
+
+					propertylistJSON, err := json.Marshal(propertylist)
+					if err != nil {
+						return errors.Wrapf(err, "failed to marshal propertylist json for id '%s' in table %s", row.ID, table)
+					}
+
+					if _, err := sqlStore.execBuilder(e, sqlStore.builder.
+						Update(table).
+						Set("PropertylistJSON", propertylistJSON).
+						Where(sq.Eq{"ID": row.ID})); err != nil {
+						return errors.Wrapf(err, "failed updating the propertylist of id '%s' in table %s", row.ID, table)
+					}
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.8.0"),
+		toVersion:   semver.MustParse("0.9.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ExitCriteriaJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExitCriteriaJSON to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "ExitCriteriaJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExitCriteriaJSON to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "ExitCriteriaJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExitCriteriaJSON to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "ExitCriteriaJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExitCriteriaJSON to table IR_Incident")
+				}
+			}
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.9.0"),
+		toVersion:   semver.MustParse("0.10.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_IncidentProperty (
+						IncidentID  VARCHAR(26)  NOT NULL REFERENCES IR_Incident(ID),
+						Name        VARCHAR(255) NOT NULL,
+						Value       VARCHAR(255) NOT NULL,
+						NumberValue DOUBLE       NULL,
+						INDEX IR_IncidentProperty_IncidentID (IncidentID),
+						INDEX IR_IncidentProperty_Name_Value (Name, Value),
+						INDEX IR_IncidentProperty_Name_NumberValue (Name, NumberValue)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_IncidentProperty")
+				}
+
+				if err := createMySQLIndex(e, "IR_Incident_TeamID_PlaybookID", "IR_Incident", "TeamID, PlaybookID"); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Incident_TeamID_PlaybookID")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_IncidentProperty (
+						IncidentID  TEXT             NOT NULL REFERENCES IR_Incident(ID),
+						Name        TEXT             NOT NULL,
+						Value       TEXT             NOT NULL,
+						NumberValue DOUBLE PRECISION NULL
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_IncidentProperty")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_IncidentProperty_IncidentID", "IR_IncidentProperty", "IncidentID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_IncidentProperty_IncidentID")
+				}
+				if _, err := e.Exec(createPGIndex("IR_IncidentProperty_Name_Value", "IR_IncidentProperty", "Name, Value")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_IncidentProperty_Name_Value")
+				}
+				if _, err := e.Exec(createPGIndex("IR_IncidentProperty_Name_NumberValue", "IR_IncidentProperty", "Name, NumberValue")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_IncidentProperty_Name_NumberValue")
+				}
+				if _, err := e.Exec(createPGIndex("IR_Incident_TeamID_PlaybookID", "IR_Incident", "TeamID, PlaybookID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Incident_TeamID_PlaybookID")
+				}
+			}
+
+			// Fill IR_IncidentProperty in with the properties of the existing incidents.
+			var incidents []struct {
+				ID               string
+				PropertylistJSON json.RawMessage
+			}
+			if err := sqlStore.selectBuilder(e, &incidents, sqlStore.builder.
+				Select("ID", "PropertylistJSON").
+				From("IR_Incident")); err != nil {
+				return errors.Wrapf(err, "failed getting incidents to fill IR_IncidentProperty in")
+			}
+
+			for _, theIncident := range incidents {
+				if len(theIncident.PropertylistJSON) == 0 {
+					continue
+				}
+
+				var propertylist playbook.Propertylist
+				if err := json.Unmarshal(theIncident.PropertylistJSON, &propertylist); err != nil {
+					sqlStore.log.Warnf("failed to unmarshal propertylist json for incident id '%s': %v", theIncident.ID, err)
+					continue
+				}
+
+				if err := sqlStore.replaceIncidentProperties(e, theIncident.ID, propertylist); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.10.0"),
+		toVersion:   semver.MustParse("0.11.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_IncidentSearch (
+						IncidentID        VARCHAR(26) PRIMARY KEY REFERENCES IR_Incident(ID),
+						Name              TEXT        NOT NULL,
+						Description       TEXT        NOT NULL,
+						StatusUpdates     MEDIUMTEXT  NOT NULL,
+						TimelineSummaries MEDIUMTEXT  NOT NULL,
+						ChecklistItems    TEXT        NOT NULL,
+						Properties        TEXT        NOT NULL,
+						FULLTEXT INDEX IR_IncidentSearch_Content (Name, Description, StatusUpdates, TimelineSummaries, ChecklistItems, Properties)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_IncidentSearch")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_IncidentSearch (
+						IncidentID        TEXT     PRIMARY KEY REFERENCES IR_Incident(ID),
+						Name              TEXT     NOT NULL,
+						Description       TEXT     NOT NULL,
+						StatusUpdates     TEXT     NOT NULL,
+						TimelineSummaries TEXT     NOT NULL,
+						ChecklistItems    TEXT     NOT NULL,
+						Properties        TEXT     NOT NULL,
+						Document          TSVECTOR
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_IncidentSearch")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_IncidentSearch_Document", "IR_IncidentSearch USING GIN", "Document")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_IncidentSearch_Document")
+				}
+			}
+
+			// Index the existing incidents, with the status updates posted so far.
+			var incidents []struct {
+				ID               string
+				Name             string
+				Description      string
+				ChecklistsJSON   json.RawMessage
+				PropertylistJSON json.RawMessage
+			}
+			if err := sqlStore.selectBuilder(e, &incidents, sqlStore.builder.
+				Select("i.ID", "COALESCE(c.DisplayName, i.Name) AS Name", "i.Description", "i.ChecklistsJSON", "i.PropertylistJSON").
+				From("IR_Incident AS i").
+				LeftJoin("Channels AS c ON (c.Id = i.ChannelId)")); err != nil {
+				return errors.Wrapf(err, "failed getting incidents to fill IR_IncidentSearch in")
+			}
+
+			for _, theIncident := range incidents {
+				checklists, propertylist, err := searchContentFromJSON(theIncident.ChecklistsJSON, theIncident.PropertylistJSON)
+				if err != nil {
+					sqlStore.log.Warnf("failed to index incident id '%s' for search: %v", theIncident.ID, err)
+					continue
+				}
+
+				if err = sqlStore.updateIncidentSearchContent(e, theIncident.ID, theIncident.Description, checklists, propertylist); err != nil {
+					return err
+				}
+
+				var messages []string
+				if err = sqlStore.selectBuilder(e, &messages, sqlStore.builder.
+					Select("p.Message").
+					From("IR_StatusPosts AS sp").
+					Join("Posts AS p ON (p.Id = sp.PostID)").
+					Where(sq.Eq{"sp.IncidentID": theIncident.ID}).
+					Where(sq.Eq{"p.DeleteAt": 0}).
+					OrderBy("p.CreateAt")); err != nil {
+					return errors.Wrapf(err, "failed getting the status updates of incident id '%s'", theIncident.ID)
+				}
+
+				for _, message := range messages {
+					if err = sqlStore.addIncidentSearchStatusUpdate(e, theIncident.ID, message); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.11.0"),
+		toVersion:   semver.MustParse("0.12.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookTeam (
+						PlaybookID VARCHAR(26) NOT NULL REFERENCES IR_Playbook(ID),
+						TeamID VARCHAR(26) NOT NULL,
+						UNIQUE INDEX IR_PlaybookTeam_PlaybookID_TeamID (PlaybookID, TeamID),
+						INDEX IR_PlaybookTeam_TeamID (TeamID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookTeam")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "IsGlobal", "BOOLEAN NOT NULL DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column IsGlobal to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "Version", "BIGINT NOT NULL DEFAULT 1"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ForkedFromID", "VARCHAR(26) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ForkedFromID to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ForkedFromVersion", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column ForkedFromVersion to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "PlaybookVersion", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column PlaybookVersion to table IR_Incident")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookTeam (
+						PlaybookID TEXT NOT NULL REFERENCES IR_Playbook(ID),
+						TeamID TEXT NOT NULL,
+						UNIQUE (PlaybookID, TeamID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookTeam")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_PlaybookTeam_TeamID", "IR_PlaybookTeam", "TeamID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_PlaybookTeam_TeamID")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Playbook", "IsGlobal", "BOOLEAN NOT NULL DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column IsGlobal to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Playbook", "Version", "BIGINT NOT NULL DEFAULT 1"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Playbook", "ForkedFromID", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ForkedFromID to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Playbook", "ForkedFromVersion", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column ForkedFromVersion to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "PlaybookVersion", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column PlaybookVersion to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.12.0"),
+		toVersion:   semver.MustParse("0.13.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_PlaybookMember", "Role", "VARCHAR(26) NOT NULL DEFAULT 'editor'"); err != nil {
+					return errors.Wrapf(err, "failed adding column Role to table IR_PlaybookMember")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookGroup (
+						PlaybookID VARCHAR(26) NOT NULL REFERENCES IR_Playbook(ID),
+						GroupID VARCHAR(26) NOT NULL,
+						Role VARCHAR(26) NOT NULL,
+						UNIQUE INDEX IR_PlaybookGroup_PlaybookID_GroupID (PlaybookID, GroupID),
+						INDEX IR_PlaybookGroup_GroupID (GroupID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookGroup")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_PlaybookMember", "Role", "TEXT NOT NULL DEFAULT 'editor'"); err != nil {
+					return errors.Wrapf(err, "failed adding column Role to table IR_PlaybookMember")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookGroup (
+						PlaybookID TEXT NOT NULL REFERENCES IR_Playbook(ID),
+						GroupID TEXT NOT NULL,
+						Role TEXT NOT NULL,
+						UNIQUE (PlaybookID, GroupID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookGroup")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_PlaybookGroup_GroupID", "IR_PlaybookGroup", "GroupID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_PlaybookGroup_GroupID")
+				}
+			}
+
+			// Every member could edit the playbook before roles existed, so they all become editors.
+			if _, err := e.Exec("UPDATE IR_PlaybookMember SET Role = 'editor' WHERE Role = ''"); err != nil {
+				return errors.Wrapf(err, "failed setting the role of the existing playbook members")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.13.0"),
+		toVersion:   semver.MustParse("0.14.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_OnCallSchedule (
+						ID VARCHAR(26) PRIMARY KEY,
+						TeamID VARCHAR(26) NOT NULL,
+						Name VARCHAR(1024) NOT NULL,
+						TimeZone VARCHAR(64) NOT NULL,
+						LayersJSON JSON NOT NULL,
+						CreateAt BIGINT NOT NULL,
+						UpdateAt BIGINT NOT NULL,
+						DeleteAt BIGINT NOT NULL DEFAULT 0,
+						INDEX IR_OnCallSchedule_TeamID (TeamID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_OnCallSchedule")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_OnCallOverride (
+						ID VARCHAR(26) PRIMARY KEY,
+						ScheduleID VARCHAR(26) NOT NULL REFERENCES IR_OnCallSchedule(ID),
+						UserID VARCHAR(26) NOT NULL,
+						StartAt BIGINT NOT NULL,
+						EndAt BIGINT NOT NULL,
+						CreatorUserID VARCHAR(26) NOT NULL,
+						CreateAt BIGINT NOT NULL,
+						INDEX IR_OnCallOverride_ScheduleID_EndAt (ScheduleID, EndAt)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_OnCallOverride")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "OnCallScheduleID", "VARCHAR(26) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column OnCallScheduleID to table IR_Playbook")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_OnCallSchedule (
+						ID TEXT PRIMARY KEY,
+						TeamID TEXT NOT NULL,
+						Name TEXT NOT NULL,
+						TimeZone TEXT NOT NULL,
+						LayersJSON JSON NOT NULL,
+						CreateAt BIGINT NOT NULL,
+						UpdateAt BIGINT NOT NULL,
+						DeleteAt BIGINT NOT NULL DEFAULT 0
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_OnCallSchedule")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_OnCallSchedule_TeamID", "IR_OnCallSchedule", "TeamID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_OnCallSchedule_TeamID")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_OnCallOverride (
+						ID TEXT PRIMARY KEY,
+						ScheduleID TEXT NOT NULL REFERENCES IR_OnCallSchedule(ID),
+						UserID TEXT NOT NULL,
+						StartAt BIGINT NOT NULL,
+						EndAt BIGINT NOT NULL,
+						CreatorUserID TEXT NOT NULL,
+						CreateAt BIGINT NOT NULL
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_OnCallOverride")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_OnCallOverride_ScheduleID_EndAt", "IR_OnCallOverride", "ScheduleID, EndAt")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_OnCallOverride_ScheduleID_EndAt")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Playbook", "OnCallScheduleID", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column OnCallScheduleID to table IR_Playbook")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.14.0"),
+		toVersion:   semver.MustParse("0.15.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CommanderHandoffJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column CommanderHandoffJSON to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Incident", "CommanderHandoffJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column CommanderHandoffJSON to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.15.0"),
+		toVersion:   semver.MustParse("0.16.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ExternalID", "VARCHAR(128) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExternalID to table IR_Playbook")
+				}
+
+				if err := createMySQLIndex(e, "IR_Playbook_TeamID_ExternalID", "IR_Playbook", "TeamID, ExternalID"); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Playbook_TeamID_ExternalID")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "ExternalID", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ExternalID to table IR_Playbook")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_Playbook_TeamID_ExternalID", "IR_Playbook", "TeamID, ExternalID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Playbook_TeamID_ExternalID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.16.0"),
+		toVersion:   semver.MustParse("0.17.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_AuditRecord (
+						ID VARCHAR(26) PRIMARY KEY,
+						CreateAt BIGINT NOT NULL,
+						ActorUserID VARCHAR(26) NOT NULL,
+						Source VARCHAR(32) NOT NULL,
+						TargetType VARCHAR(32) NOT NULL,
+						TargetID VARCHAR(26) NOT NULL,
+						TeamID VARCHAR(26) NOT NULL DEFAULT '',
+						Action VARCHAR(64) NOT NULL,
+						ChangesJSON JSON NOT NULL,
+						Denied BOOLEAN NOT NULL DEFAULT FALSE,
+						INDEX IR_AuditRecord_CreateAt_ID (CreateAt, ID),
+						INDEX IR_AuditRecord_TargetID (TargetID),
+						INDEX IR_AuditRecord_ActorUserID (ActorUserID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_AuditRecord")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_AuditRecord (
+						ID TEXT PRIMARY KEY,
+						CreateAt BIGINT NOT NULL,
+						ActorUserID TEXT NOT NULL,
+						Source TEXT NOT NULL,
+						TargetType TEXT NOT NULL,
+						TargetID TEXT NOT NULL,
+						TeamID TEXT NOT NULL DEFAULT '',
+						Action TEXT NOT NULL,
+						ChangesJSON JSON NOT NULL,
+						Denied BOOLEAN NOT NULL DEFAULT FALSE
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_AuditRecord")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_AuditRecord_CreateAt_ID", "IR_AuditRecord", "CreateAt, ID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditRecord_CreateAt_ID")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_AuditRecord_TargetID", "IR_AuditRecord", "TargetID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditRecord_TargetID")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_AuditRecord_ActorUserID", "IR_AuditRecord", "ActorUserID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditRecord_ActorUserID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.17.0"),
+		toVersion:   semver.MustParse("0.18.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Incident", "Version", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Incident", "Version", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.18.0"),
+		toVersion:   semver.MustParse("0.19.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			for _, column := range []string{"TasksTotal", "TasksDone"} {
+				if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+					if err := addColumnToMySQLTable(e, "IR_Incident", column, "INT NOT NULL DEFAULT 0"); err != nil {
+						return errors.Wrapf(err, "failed adding column %s to table IR_Incident", column)
+					}
+				} else {
+					if err := addColumnToPGTable(e, "IR_Incident", column, "INT NOT NULL DEFAULT 0"); err != nil {
+						return errors.Wrapf(err, "failed adding column %s to table IR_Incident", column)
+					}
+				}
+			}
+
+			getIncidentsQuery := sqlStore.builder.
+				Select("ID", "ChecklistsJSON").
+				From("IR_Incident")
+
+			var incidents []struct {
+				ID             string
+				ChecklistsJSON json.RawMessage
+			}
+			if err := sqlStore.selectBuilder(e, &incidents, getIncidentsQuery); err != nil {
+				return errors.Wrapf(err, "failed getting incidents to count their tasks")
+			}
+
+			for _, theIncident := range incidents {
+				var checklists []playbook.Checklist
+				if err := json.Unmarshal(theIncident.ChecklistsJSON, &checklists); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal checklists json for incident id: '%s'", theIncident.ID)
+				}
+
+				tasksTotal, tasksDone := countTasks(checklists)
+				if tasksTotal == 0 {
+					continue
+				}
+
+				incidentUpdate := sqlStore.builder.
+					Update("IR_Incident").
+					SetMap(map[string]interface{}{
+						"TasksTotal": tasksTotal,
+						"TasksDone":  tasksDone,
+					}).
+					Where(sq.Eq{"ID": theIncident.ID})
+
+				if _, err := sqlStore.execBuilder(e, incidentUpdate); err != nil {
+					return errors.Wrapf(err, "failed updating the task counts of incident '%s'", theIncident.ID)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.19.0"),
+		toVersion:   semver.MustParse("0.20.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_Outbox (
+						ID VARCHAR(26) PRIMARY KEY,
+						CreateAt BIGINT NOT NULL,
+						Type VARCHAR(64) NOT NULL,
+						Payload JSON NOT NULL,
+						Attempts INT NOT NULL DEFAULT 0,
+						NextAttemptAt BIGINT NOT NULL,
+						LastError TEXT NOT NULL,
+						INDEX IR_Outbox_NextAttemptAt (NextAttemptAt)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_Outbox")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_Outbox (
+						ID TEXT PRIMARY KEY,
+						CreateAt BIGINT NOT NULL,
+						Type TEXT NOT NULL,
+						Payload JSON NOT NULL,
+						Attempts INT NOT NULL DEFAULT 0,
+						NextAttemptAt BIGINT NOT NULL,
+						LastError TEXT NOT NULL DEFAULT ''
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_Outbox")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_Outbox_NextAttemptAt", "IR_Outbox", "NextAttemptAt")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Outbox_NextAttemptAt")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.20.0"),
+		toVersion:   semver.MustParse("0.21.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			// Searches match the current channel name, so drop the copies indexed so far, which
+			// would keep matching the incidents under their former names.
+			if _, err := e.Exec("UPDATE IR_IncidentSearch SET Name = ''"); err != nil {
+				return errors.Wrapf(err, "failed clearing IR_IncidentSearch.Name")
+			}
+
+			if e.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+				if _, err := e.Exec("UPDATE IR_IncidentSearch SET Document = " + searchDocumentPG); err != nil {
+					return errors.Wrapf(err, "failed reindexing IR_IncidentSearch")
+				}
+			}
+
 			return nil
 		},
 	},
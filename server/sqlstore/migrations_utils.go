@@ -53,3 +53,19 @@ var addColumnToMySQLTable = func(e sqlx.Ext, tableName, columnName, columnType s
 
 	return err
 }
+
+var createMySQLIndex = func(e sqlx.Ext, indexName, tableName, columns string) error {
+	var result int
+	err := e.QueryRowx(
+		"SELECT 1 FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ? LIMIT 1",
+		tableName,
+		indexName,
+	).Scan(&result)
+
+	// Only create the index if we don't find it
+	if err == sql.ErrNoRows {
+		_, err = e.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, columns))
+	}
+
+	return err
+}
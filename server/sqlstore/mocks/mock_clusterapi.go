@@ -0,0 +1,47 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore (interfaces: ClusterAPI)
+
+// Package mock_sqlstore is a generated GoMock package.
+package mock_sqlstore
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockClusterAPI is a mock of ClusterAPI interface
+type MockClusterAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterAPIMockRecorder
+}
+
+// MockClusterAPIMockRecorder is the mock recorder for MockClusterAPI
+type MockClusterAPIMockRecorder struct {
+	mock *MockClusterAPI
+}
+
+// NewMockClusterAPI creates a new mock instance
+func NewMockClusterAPI(ctrl *gomock.Controller) *MockClusterAPI {
+	mock := &MockClusterAPI{ctrl: ctrl}
+	mock.recorder = &MockClusterAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockClusterAPI) EXPECT() *MockClusterAPIMockRecorder {
+	return m.recorder
+}
+
+// PublishPluginClusterEvent mocks base method
+func (m *MockClusterAPI) PublishPluginClusterEvent(arg0 string, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPluginClusterEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPluginClusterEvent indicates an expected call of PublishPluginClusterEvent
+func (mr *MockClusterAPIMockRecorder) PublishPluginClusterEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPluginClusterEvent", reflect.TypeOf((*MockClusterAPI)(nil).PublishPluginClusterEvent), arg0, arg1)
+}
@@ -0,0 +1,241 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/oncall"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+type sqlSchedule struct {
+	oncall.Schedule
+	LayersJSON json.RawMessage
+}
+
+// onCallStore is a sql store for on-call schedules. Use NewOnCallStore to create it.
+type onCallStore struct {
+	pluginAPI      PluginAPIClient
+	log            bot.Logger
+	store          *SQLStore
+	scheduleSelect sq.SelectBuilder
+	overrideSelect sq.SelectBuilder
+}
+
+// Ensure onCallStore implements the oncall.Store interface.
+var _ oncall.Store = (*onCallStore)(nil)
+
+// NewOnCallStore creates a new store for the on-call service.
+func NewOnCallStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLStore) oncall.Store {
+	scheduleSelect := sqlStore.builder.
+		Select("ID", "TeamID", "Name", "TimeZone", "LayersJSON", "CreateAt", "UpdateAt", "DeleteAt").
+		From("IR_OnCallSchedule")
+
+	overrideSelect := sqlStore.builder.
+		Select("ID", "ScheduleID", "UserID", "StartAt", "EndAt", "CreatorUserID", "CreateAt").
+		From("IR_OnCallOverride")
+
+	return &onCallStore{
+		pluginAPI:      pluginAPI,
+		log:            log,
+		store:          sqlStore,
+		scheduleSelect: scheduleSelect,
+		overrideSelect: overrideSelect,
+	}
+}
+
+// CreateSchedule creates a new schedule.
+func (s *onCallStore) CreateSchedule(schedule oncall.Schedule) (string, error) {
+	if schedule.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	schedule.ID = model.NewId()
+
+	layersJSON, err := json.Marshal(schedule.Layers)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal layers json for schedule id: '%s'", schedule.ID)
+	}
+
+	_, err = s.store.execBuilder(s.store.db, sq.
+		Insert("IR_OnCallSchedule").
+		SetMap(map[string]interface{}{
+			"ID":         schedule.ID,
+			"TeamID":     schedule.TeamID,
+			"Name":       schedule.Name,
+			"TimeZone":   schedule.TimeZone,
+			"LayersJSON": layersJSON,
+			"CreateAt":   schedule.CreateAt,
+			"UpdateAt":   schedule.UpdateAt,
+			"DeleteAt":   0,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new schedule")
+	}
+
+	return schedule.ID, nil
+}
+
+// GetSchedule retrieves a schedule, with the overrides that haven't ended yet.
+func (s *onCallStore) GetSchedule(id string) (oncall.Schedule, error) {
+	if id == "" {
+		return oncall.Schedule{}, errors.New("ID cannot be empty")
+	}
+
+	schedules, err := s.getSchedules(sq.Eq{"ID": id})
+	if err != nil {
+		return oncall.Schedule{}, errors.Wrapf(err, "failed to get schedule by id '%s'", id)
+	}
+	if len(schedules) == 0 {
+		return oncall.Schedule{}, errors.Wrapf(oncall.ErrNotFound, "schedule does not exist for id '%s'", id)
+	}
+
+	return schedules[0], nil
+}
+
+// GetSchedulesForTeam retrieves the schedules of a team, sorted by name.
+func (s *onCallStore) GetSchedulesForTeam(teamID string) ([]oncall.Schedule, error) {
+	schedules, err := s.getSchedules(sq.Eq{"TeamID": teamID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get schedules for team '%s'", teamID)
+	}
+
+	return schedules, nil
+}
+
+func (s *onCallStore) getSchedules(where sq.Sqlizer) ([]oncall.Schedule, error) {
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	var rawSchedules []sqlSchedule
+	err = s.store.selectBuilder(tx, &rawSchedules, s.scheduleSelect.
+		Where(where).
+		Where(sq.Eq{"DeleteAt": 0}).
+		OrderBy("Name", "ID"))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "failed to get schedules")
+	}
+	if len(rawSchedules) == 0 {
+		return []oncall.Schedule{}, nil
+	}
+
+	ids := make([]string, 0, len(rawSchedules))
+	for _, raw := range rawSchedules {
+		ids = append(ids, raw.ID)
+	}
+
+	var overrides []oncall.Override
+	err = s.store.selectBuilder(tx, &overrides, s.overrideSelect.
+		Where(sq.Eq{"ScheduleID": ids}).
+		Where(sq.Gt{"EndAt": model.GetMillis()}).
+		OrderBy("StartAt", "ID"))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "failed to get overrides")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
+	schedules := make([]oncall.Schedule, 0, len(rawSchedules))
+	for _, raw := range rawSchedules {
+		schedule := raw.Schedule
+		if err = json.Unmarshal(raw.LayersJSON, &schedule.Layers); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal layers json for schedule id: '%s'", schedule.ID)
+		}
+		for _, o := range overrides {
+			if o.ScheduleID == schedule.ID {
+				schedule.Overrides = append(schedule.Overrides, o)
+			}
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// UpdateSchedule updates a schedule, leaving its overrides aside.
+func (s *onCallStore) UpdateSchedule(schedule oncall.Schedule) error {
+	if schedule.ID == "" {
+		return errors.New("id should not be empty")
+	}
+
+	layersJSON, err := json.Marshal(schedule.Layers)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal layers json for schedule id: '%s'", schedule.ID)
+	}
+
+	_, err = s.store.execBuilder(s.store.db, sq.
+		Update("IR_OnCallSchedule").
+		SetMap(map[string]interface{}{
+			"Name":       schedule.Name,
+			"TimeZone":   schedule.TimeZone,
+			"LayersJSON": layersJSON,
+			"UpdateAt":   schedule.UpdateAt,
+		}).
+		Where(sq.Eq{"ID": schedule.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update schedule with id '%s'", schedule.ID)
+	}
+
+	return nil
+}
+
+// DeleteSchedule marks a schedule as deleted.
+func (s *onCallStore) DeleteSchedule(id string) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Update("IR_OnCallSchedule").
+		Set("DeleteAt", model.GetMillis()).
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete schedule with id '%s'", id)
+	}
+
+	return nil
+}
+
+// CreateOverride creates a new override.
+func (s *onCallStore) CreateOverride(override oncall.Override) (string, error) {
+	if override.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	override.ID = model.NewId()
+
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Insert("IR_OnCallOverride").
+		SetMap(map[string]interface{}{
+			"ID":            override.ID,
+			"ScheduleID":    override.ScheduleID,
+			"UserID":        override.UserID,
+			"StartAt":       override.StartAt,
+			"EndAt":         override.EndAt,
+			"CreatorUserID": override.CreatorUserID,
+			"CreateAt":      override.CreateAt,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new override")
+	}
+
+	return override.ID, nil
+}
+
+// DeleteOverride deletes an override of a schedule.
+func (s *onCallStore) DeleteOverride(scheduleID, overrideID string) error {
+	result, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_OnCallOverride").
+		Where(sq.Eq{"ID": overrideID, "ScheduleID": scheduleID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete override with id '%s'", overrideID)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errors.Wrapf(oncall.ErrNotFound, "override does not exist for id '%s'", overrideID)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
+	"github.com/pkg/errors"
+)
+
+func (s *incidentStore) createOutboxMessage(e queryExecer, message *incident.OutboxMessage) error {
+	if message.ID == "" {
+		return errors.New("ID should not be empty")
+	}
+	if message.Type == "" {
+		return errors.New("needs message type")
+	}
+
+	_, err := s.store.execBuilder(e, sq.
+		Insert("IR_Outbox").
+		SetMap(map[string]interface{}{
+			"ID":            message.ID,
+			"CreateAt":      message.CreateAt,
+			"Type":          message.Type,
+			"Payload":       message.Payload,
+			"Attempts":      message.Attempts,
+			"NextAttemptAt": message.NextAttemptAt,
+			"LastError":     message.LastError,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert outbox message of type %s", message.Type)
+	}
+
+	return nil
+}
+
+// GetDueOutboxMessages returns up to limit outbox messages to attempt at or before now, in the
+// order they were created.
+func (s *incidentStore) GetDueOutboxMessages(now int64, limit int) ([]*incident.OutboxMessage, error) {
+	var messages []*incident.OutboxMessage
+	err := s.store.selectBuilder(s.store.db, &messages, s.store.builder.
+		Select("ID", "CreateAt", "Type", "Payload", "Attempts", "NextAttemptAt", "LastError").
+		From("IR_Outbox").
+		Where(sq.LtOrEq{"NextAttemptAt": now}).
+		OrderBy("CreateAt ASC", "ID ASC").
+		Limit(uint64(limit)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for due outbox messages")
+	}
+
+	return messages, nil
+}
+
+// UpdateOutboxMessage records a failed attempt of message, and when to attempt it next.
+func (s *incidentStore) UpdateOutboxMessage(message *incident.OutboxMessage) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Update("IR_Outbox").
+		SetMap(map[string]interface{}{
+			"Attempts":      message.Attempts,
+			"NextAttemptAt": message.NextAttemptAt,
+			"LastError":     message.LastError,
+		}).
+		Where(sq.Eq{"ID": message.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update outbox message %s", message.ID)
+	}
+
+	return nil
+}
+
+// DeleteOutboxMessages deletes the processed outbox messages.
+func (s *incidentStore) DeleteOutboxMessages(messageIDs []string) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	if _, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_Outbox").
+		Where(sq.Eq{"ID": messageIDs})); err != nil {
+		return errors.Wrap(err, "failed to delete outbox messages")
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/pkg/errors"
+)
+
+// keyset describes how a list is sorted for keyset pagination: by a column, then by ID to break
+// ties, so that each row has a unique position in the list.
+type keyset struct {
+	// column and idColumn are the sort and ID columns, as they appear in the query.
+	column   string
+	idColumn string
+
+	// numeric is true when the sort column holds integers, to compare the cursor value as such.
+	numeric bool
+
+	desc bool
+}
+
+// orderBy returns the ORDER BY clauses of the list.
+func (k keyset) orderBy() []string {
+	direction := "ASC"
+	if k.desc {
+		direction = "DESC"
+	}
+
+	if k.column == k.idColumn {
+		return []string{fmt.Sprintf("%s %s", k.idColumn, direction)}
+	}
+
+	return []string{
+		fmt.Sprintf("%s %s", k.column, direction),
+		fmt.Sprintf("%s %s", k.idColumn, direction),
+	}
+}
+
+// after returns the condition on the rows coming after the cursor in the list.
+func (k keyset) after(c cursor.Cursor) (sq.Sqlizer, error) {
+	operator := ">"
+	if k.desc {
+		operator = "<"
+	}
+
+	if k.column == k.idColumn {
+		return sq.Expr(fmt.Sprintf("%s %s ?", k.idColumn, operator), c.ID), nil
+	}
+
+	var value interface{} = c.Value
+	if k.numeric {
+		number, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(cursor.ErrInvalid, "cursor value is not a number")
+		}
+		value = number
+	}
+
+	return sq.Or{
+		sq.Expr(fmt.Sprintf("%s %s ?", k.column, operator), value),
+		sq.And{
+			sq.Eq{k.column: value},
+			sq.Expr(fmt.Sprintf("%s %s ?", k.idColumn, operator), c.ID),
+		},
+	}, nil
+}
+
+// nextCursor returns the cursor of the last row of a page of the list.
+func (k keyset) nextCursor(sort, direction, value, id string) string {
+	return cursor.Cursor{
+		Sort:      sort,
+		Direction: direction,
+		Value:     value,
+		ID:        id,
+	}.Encode()
+}
+
+// incidentKeyset returns the keyset of the incidents sorted by the given validated sort and
+// direction of incident.FilterOptions.
+func incidentKeyset(sort, direction string) keyset {
+	k := keyset{idColumn: "i.ID", desc: strings.EqualFold(direction, "desc")}
+
+	switch sort {
+	case "ID":
+		k.column = "i.ID"
+	case "Name":
+		k.column = "c.DisplayName"
+	case "CommanderUserID":
+		k.column = "i.CommanderUserID"
+	case "TeamID":
+		k.column = "i.TeamID"
+	case "EndAt":
+		k.column, k.numeric = "i.EndAt", true
+	case "CurrentStatus":
+		k.column = "i.CurrentStatus"
+	default:
+		k.column, k.numeric = "c.CreateAt", true
+	}
+
+	return k
+}
+
+// playbookKeyset returns the keyset of the playbooks sorted by the given SQL sort column and
+// direction, as returned by sortOptionToSQL and directionOptionToSQL.
+func playbookKeyset(column, direction string) keyset {
+	return keyset{
+		column:   column,
+		idColumn: "ID",
+		numeric:  column == "NumStages" || column == "NumSteps",
+		desc:     strings.EqualFold(direction, "desc"),
+	}
+}
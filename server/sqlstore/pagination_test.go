@@ -0,0 +1,50 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyset(t *testing.T) {
+	t.Run("numeric column, descending", func(t *testing.T) {
+		keys := incidentKeyset("CreateAt", "desc")
+		require.Equal(t, []string{"c.CreateAt DESC", "i.ID DESC"}, keys.orderBy())
+
+		after, err := keys.after(cursor.Cursor{Value: "1000", ID: "id1"})
+		require.NoError(t, err)
+		sql, args, err := after.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "(c.CreateAt < ? OR (c.CreateAt = ? AND i.ID < ?))", sql)
+		require.Equal(t, []interface{}{int64(1000), int64(1000), "id1"}, args)
+
+		_, err = keys.after(cursor.Cursor{Value: "yesterday", ID: "id1"})
+		require.True(t, errors.Is(err, cursor.ErrInvalid))
+	})
+
+	t.Run("text column, ascending", func(t *testing.T) {
+		keys := playbookKeyset("Title", "ASC")
+		require.Equal(t, []string{"Title ASC", "ID ASC"}, keys.orderBy())
+
+		after, err := keys.after(cursor.Cursor{Value: "Outage", ID: "id1"})
+		require.NoError(t, err)
+		sql, args, err := after.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "(Title > ? OR (Title = ? AND ID > ?))", sql)
+		require.Equal(t, []interface{}{"Outage", "Outage", "id1"}, args)
+	})
+
+	t.Run("sorted by id", func(t *testing.T) {
+		keys := incidentKeyset("ID", "asc")
+		require.Equal(t, []string{"i.ID ASC"}, keys.orderBy())
+
+		after, err := keys.after(cursor.Cursor{Value: "id1", ID: "id1"})
+		require.NoError(t, err)
+		sql, args, err := after.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "i.ID > ?", sql)
+		require.Equal(t, []interface{}{"id1"}, args)
+	})
+}
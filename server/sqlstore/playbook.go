@@ -3,11 +3,12 @@ package sqlstore
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"math"
+	"strconv"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
@@ -17,6 +18,7 @@ type sqlPlaybook struct {
 	playbook.Playbook
 	ChecklistsJSON   json.RawMessage
 	PropertylistJSON json.RawMessage
+	ExitCriteriaJSON json.RawMessage
 }
 
 // playbookStore is a sql store for playbooks. Use NewPlaybookStore to create it.
@@ -27,6 +29,8 @@ type playbookStore struct {
 	queryBuilder    sq.StatementBuilderType
 	playbookSelect  sq.SelectBuilder
 	memberIDsSelect sq.SelectBuilder
+	groupsSelect    sq.SelectBuilder
+	teamIDsSelect   sq.SelectBuilder
 }
 
 // Ensure playbookStore implements the playbook.Store interface.
@@ -35,19 +39,40 @@ var _ playbook.Store = (*playbookStore)(nil)
 type playbookMembers []struct {
 	PlaybookID string
 	MemberID   string
+	Role       playbook.Role
+}
+
+type playbookGroups []struct {
+	PlaybookID string
+	GroupID    string
+	Role       playbook.Role
+}
+
+type playbookTeams []struct {
+	PlaybookID string
+	TeamID     string
 }
 
 // NewPlaybookStore creates a new store for playbook service.
 func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLStore) playbook.Store {
 	playbookSelect := sqlStore.builder.
 		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident", "CreateAt",
-			"DeleteAt", "NumStages", "NumSteps", "BroadcastChannelID", "COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "ReminderTimerDefaultSeconds").
+			"DeleteAt", "NumStages", "NumSteps", "BroadcastChannelID", "COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "ReminderTimerDefaultSeconds",
+			"IsGlobal", "Version", "ForkedFromID", "ForkedFromVersion", "OnCallScheduleID", "ExternalID").
 		From("IR_Playbook")
 
 	memberIDsSelect := sqlStore.builder.
-		Select("PlaybookID", "MemberID").
+		Select("PlaybookID", "MemberID", "Role").
 		From("IR_PlaybookMember")
 
+	groupsSelect := sqlStore.builder.
+		Select("PlaybookID", "GroupID", "Role").
+		From("IR_PlaybookGroup")
+
+	teamIDsSelect := sqlStore.builder.
+		Select("PlaybookID", "TeamID").
+		From("IR_PlaybookTeam")
+
 	newStore := &playbookStore{
 		pluginAPI:       pluginAPI,
 		log:             log,
@@ -55,6 +80,8 @@ func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 		queryBuilder:    sqlStore.builder,
 		playbookSelect:  playbookSelect,
 		memberIDsSelect: memberIDsSelect,
+		groupsSelect:    groupsSelect,
+		teamIDsSelect:   teamIDsSelect,
 	}
 	return newStore
 }
@@ -89,11 +116,18 @@ func (p *playbookStore) Create(pbook playbook.Playbook) (id string, err error) {
 			"DeleteAt":                    rawPlaybook.DeleteAt,
 			"ChecklistsJSON":              rawPlaybook.ChecklistsJSON,
 			"PropertylistJSON":            rawPlaybook.PropertylistJSON,
+			"ExitCriteriaJSON":            rawPlaybook.ExitCriteriaJSON,
 			"NumStages":                   len(rawPlaybook.Checklists),
 			"NumSteps":                    getSteps(rawPlaybook.Playbook),
 			"BroadcastChannelID":          rawPlaybook.BroadcastChannelID,
 			"ReminderMessageTemplate":     rawPlaybook.ReminderMessageTemplate,
 			"ReminderTimerDefaultSeconds": rawPlaybook.ReminderTimerDefaultSeconds,
+			"IsGlobal":                    rawPlaybook.IsGlobal,
+			"Version":                     rawPlaybook.Version,
+			"ForkedFromID":                rawPlaybook.ForkedFromID,
+			"ForkedFromVersion":           rawPlaybook.ForkedFromVersion,
+			"OnCallScheduleID":            rawPlaybook.OnCallScheduleID,
+			"ExternalID":                  rawPlaybook.ExternalID,
 		}))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to store new playbook")
@@ -103,6 +137,14 @@ func (p *playbookStore) Create(pbook playbook.Playbook) (id string, err error) {
 		return "", errors.Wrap(err, "failed to replace playbook members")
 	}
 
+	if err = p.replacePlaybookGroups(tx, rawPlaybook.Playbook); err != nil {
+		return "", errors.Wrap(err, "failed to replace playbook groups")
+	}
+
+	if err = p.replacePlaybookTeams(tx, rawPlaybook.Playbook); err != nil {
+		return "", errors.Wrap(err, "failed to replace playbook shared teams")
+	}
+
 	if err = tx.Commit(); err != nil {
 		return "", errors.Wrap(err, "could not commit transaction")
 	}
@@ -123,7 +165,7 @@ func (p *playbookStore) Get(id string) (out playbook.Playbook, err error) {
 	defer p.store.finalizeTransaction(tx)
 
 	withChecklistsSelect := p.playbookSelect.
-		Columns("ChecklistsJSON, PropertylistJSON").
+		Columns("ChecklistsJSON, PropertylistJSON, COALESCE(ExitCriteriaJSON, '') ExitCriteriaJSON").
 		From("IR_Playbook")
 
 	var rawPlaybook sqlPlaybook
@@ -144,12 +186,31 @@ func (p *playbookStore) Get(id string) (out playbook.Playbook, err error) {
 		return out, errors.Wrapf(err, "failed to get memberIDs for playbook with id '%s'", id)
 	}
 
+	var groups playbookGroups
+	err = p.store.selectBuilder(tx, &groups, p.groupsSelect.Where(sq.Eq{"PlaybookID": id}))
+	if err != nil && err != sql.ErrNoRows {
+		return out, errors.Wrapf(err, "failed to get groups for playbook with id '%s'", id)
+	}
+
+	var teamIDs playbookTeams
+	err = p.store.selectBuilder(tx, &teamIDs, p.teamIDsSelect.Where(sq.Eq{"PlaybookID": id}))
+	if err != nil && err != sql.ErrNoRows {
+		return out, errors.Wrapf(err, "failed to get shared teamIDs for playbook with id '%s'", id)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return out, errors.Wrap(err, "could not commit transaction")
 	}
 
 	for _, m := range memberIDs {
 		out.MemberIDs = append(out.MemberIDs, m.MemberID)
+		out.Members = append(out.Members, playbook.Member{UserID: m.MemberID, Role: m.Role})
+	}
+	for _, g := range groups {
+		out.Groups = append(out.Groups, playbook.GroupMember{GroupID: g.GroupID, Role: g.Role})
+	}
+	for _, t := range teamIDs {
+		out.SharedTeamIDs = append(out.SharedTeamIDs, t.TeamID)
 	}
 
 	return out, nil
@@ -166,7 +227,7 @@ func (p *playbookStore) GetPlaybooks() ([]playbook.Playbook, error) {
 	var out []playbook.Playbook
 	err = p.store.selectBuilder(tx, &out, p.store.builder.
 		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident", "CreateAt",
-			"DeleteAt", "NumStages", "NumSteps").
+			"DeleteAt", "NumStages", "NumSteps", "IsGlobal", "Version", "ForkedFromID", "ForkedFromVersion", "ExternalID").
 		From("IR_Playbook AS p").
 		Where(sq.Eq{"DeleteAt": 0}))
 
@@ -191,37 +252,94 @@ func (p *playbookStore) GetPlaybooks() ([]playbook.Playbook, error) {
 	return out, nil
 }
 
-// GetPlaybooksForTeam retrieves all playbooks on the specified team given the provided options.
+// GetPlaybooksForTeam retrieves all playbooks on the specified team, and the ones published to it,
+// given the provided options.
 func (p *playbookStore) GetPlaybooksForTeam(requesterInfo playbook.RequesterInfo, teamID string, opts playbook.Options) (playbook.GetPlaybooksResults, error) {
 	correctPaginationOpts(&opts)
 
+	// The playbooks published to the team are available to everyone in it.
+	sharedWithTeam := sq.Or{
+		sq.Eq{"IsGlobal": true},
+		sq.Expr(`
+			EXISTS(SELECT 1
+					 FROM IR_PlaybookTeam as pt
+					 WHERE pt.PlaybookID = p.ID
+					   AND pt.TeamID = ?)`, teamID),
+	}
+	teamFilter := sq.Or{sq.Eq{"TeamID": teamID}, sharedWithTeam}
+
 	var permissionsAndFilter sq.Sqlizer
 	isAdmin := requesterInfo.UserIDtoIsAdmin[requesterInfo.UserID]
 
 	if isAdmin && requesterInfo.MemberOnly || !isAdmin {
-		permissionsAndFilter = p.store.builder.
-			Select("1").
-			Prefix("EXISTS(").
-			From("IR_PlaybookMember as pm").
-			Where("pm.PlaybookID = p.ID").
-			Where(sq.Eq{"pm.MemberID": requesterInfo.UserID}).
-			Suffix(")")
+		minRole := requesterInfo.MinRole
+		if minRole == "" {
+			minRole = playbook.RoleViewer
+		}
+		roles := playbook.RolesAtLeast(minRole)
+
+		permissionsAndFilter = sq.Or{
+			sq.Select("1").
+				Prefix("EXISTS(").
+				From("IR_PlaybookMember as pm").
+				Where("pm.PlaybookID = p.ID").
+				Where(sq.Eq{"pm.MemberID": requesterInfo.UserID}).
+				Where(sq.Eq{"pm.Role": roles}).
+				Suffix(")"),
+			sq.Select("1").
+				Prefix("EXISTS(").
+				From("IR_PlaybookGroup as pg").
+				Join("GroupMembers as gm ON (gm.GroupId = pg.GroupID)").
+				Where("pg.PlaybookID = p.ID").
+				Where(sq.Eq{"gm.UserId": requesterInfo.UserID}).
+				Where(sq.Eq{"gm.DeleteAt": 0}).
+				Where(sq.Eq{"pg.Role": roles}).
+				Suffix(")"),
+			sharedWithTeam,
+		}
 	}
 
 	queryForResults := p.store.builder.
 		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident", "CreateAt",
-			"DeleteAt", "NumStages", "NumSteps").
+			"DeleteAt", "NumStages", "NumSteps", "IsGlobal", "Version", "ForkedFromID", "ForkedFromVersion", "ExternalID").
 		From("IR_Playbook AS p").
 		Where(sq.Eq{"DeleteAt": 0}).
-		Where(sq.Eq{"TeamID": teamID}).
-		Where(permissionsAndFilter).
-		Offset(uint64(opts.Page * opts.PerPage)).
-		Limit(uint64(opts.PerPage))
+		Where(teamFilter).
+		Where(permissionsAndFilter)
 
-	if playbook.IsValidSort(opts.Sort) && playbook.IsValidDirection(opts.Direction) {
-		queryForResults = queryForResults.OrderBy(fmt.Sprintf("%s %s", sortOptionToSQL(opts.Sort), directionOptionToSQL(opts.Direction)))
-	} else if playbook.IsValidSort(opts.Sort) {
-		queryForResults = queryForResults.OrderBy(sortOptionToSQL(opts.Sort))
+	sort := sortOptionToSQL(opts.Sort)
+	direction := directionOptionToSQL(opts.Direction)
+	if sort == "" || direction == "" {
+		return playbook.GetPlaybooksResults{}, errors.Errorf("invalid sort '%s %s'", opts.Sort, opts.Direction)
+	}
+	keys := playbookKeyset(sort, direction)
+	queryForResults = queryForResults.OrderBy(keys.orderBy()...)
+
+	// With a cursor, the page starts right after the last playbook of the previous page, and one
+	// more playbook than needed is asked for to know whether there are more.
+	paginateWithCursor := opts.Cursor != ""
+	if paginateWithCursor {
+		if opts.Page != 0 {
+			return playbook.GetPlaybooksResults{}, errors.Wrap(cursor.ErrInvalid, "cursor cannot be used with a page")
+		}
+		c, err := cursor.Decode(opts.Cursor)
+		if err != nil {
+			return playbook.GetPlaybooksResults{}, err
+		}
+		if err = c.Check(sort, direction); err != nil {
+			return playbook.GetPlaybooksResults{}, err
+		}
+		after, err := keys.after(c)
+		if err != nil {
+			return playbook.GetPlaybooksResults{}, err
+		}
+		queryForResults = queryForResults.
+			Where(after).
+			Limit(uint64(opts.PerPage + 1))
+	} else {
+		queryForResults = queryForResults.
+			Offset(uint64(opts.Page * opts.PerPage)).
+			Limit(uint64(opts.PerPage))
 	}
 
 	var playbooks []playbook.Playbook
@@ -232,28 +350,55 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo playbook.RequesterInfo
 		return playbook.GetPlaybooksResults{}, errors.Wrap(err, "failed to get playbooks")
 	}
 
-	queryForTotal := p.store.builder.
-		Select("COUNT(*)").
-		From("IR_Playbook AS p").
-		Where(sq.Eq{"DeleteAt": 0}).
-		Where(sq.Eq{"TeamID": teamID}).
-		Where(permissionsAndFilter)
+	var total, pageCount int
+	var hasMore bool
+	if paginateWithCursor {
+		hasMore = len(playbooks) > opts.PerPage
+		if hasMore {
+			playbooks = playbooks[:opts.PerPage]
+		}
+	} else {
+		queryForTotal := p.store.builder.
+			Select("COUNT(*)").
+			From("IR_Playbook AS p").
+			Where(sq.Eq{"DeleteAt": 0}).
+			Where(teamFilter).
+			Where(permissionsAndFilter)
+
+		if err = p.store.getBuilder(p.store.db, &total, queryForTotal); err != nil {
+			return playbook.GetPlaybooksResults{}, errors.Wrap(err, "failed to get total count")
+		}
+		pageCount = int(math.Ceil(float64(total) / float64(opts.PerPage)))
+		hasMore = opts.Page+1 < pageCount
+	}
 
-	var total int
-	if err = p.store.getBuilder(p.store.db, &total, queryForTotal); err != nil {
-		return playbook.GetPlaybooksResults{}, errors.Wrap(err, "failed to get total count")
+	var nextCursor string
+	if hasMore && len(playbooks) > 0 {
+		last := playbooks[len(playbooks)-1]
+		nextCursor = keys.nextCursor(sort, direction, playbookSortValue(last, sort), last.ID)
 	}
-	pageCount := int(math.Ceil(float64(total) / float64(opts.PerPage)))
-	hasMore := opts.Page+1 < pageCount
 
 	return playbook.GetPlaybooksResults{
 		TotalCount: total,
 		PageCount:  pageCount,
 		HasMore:    hasMore,
+		NextCursor: nextCursor,
 		Items:      playbooks,
 	}, nil
 }
 
+// playbookSortValue returns the value of the given sort column of the playbook.
+func playbookSortValue(pb playbook.Playbook, column string) string {
+	switch column {
+	case "NumStages":
+		return strconv.FormatInt(pb.NumStages, 10)
+	case "NumSteps":
+		return strconv.FormatInt(pb.NumSteps, 10)
+	default:
+		return pb.Title
+	}
+}
+
 // Update updates a playbook
 func (p *playbookStore) Update(updated playbook.Playbook) (err error) {
 	if updated.ID == "" {
@@ -281,11 +426,18 @@ func (p *playbookStore) Update(updated playbook.Playbook) (err error) {
 			"DeleteAt":                    rawPlaybook.DeleteAt,
 			"ChecklistsJSON":              rawPlaybook.ChecklistsJSON,
 			"PropertylistJSON":            rawPlaybook.PropertylistJSON,
+			"ExitCriteriaJSON":            rawPlaybook.ExitCriteriaJSON,
 			"NumStages":                   len(rawPlaybook.Checklists),
 			"NumSteps":                    getSteps(rawPlaybook.Playbook),
 			"BroadcastChannelID":          rawPlaybook.BroadcastChannelID,
 			"ReminderMessageTemplate":     rawPlaybook.ReminderMessageTemplate,
 			"ReminderTimerDefaultSeconds": rawPlaybook.ReminderTimerDefaultSeconds,
+			"IsGlobal":                    rawPlaybook.IsGlobal,
+			"Version":                     rawPlaybook.Version,
+			"ForkedFromID":                rawPlaybook.ForkedFromID,
+			"ForkedFromVersion":           rawPlaybook.ForkedFromVersion,
+			"OnCallScheduleID":            rawPlaybook.OnCallScheduleID,
+			"ExternalID":                  rawPlaybook.ExternalID,
 		}).
 		Where(sq.Eq{"ID": rawPlaybook.ID}))
 
@@ -297,6 +449,14 @@ func (p *playbookStore) Update(updated playbook.Playbook) (err error) {
 		return errors.Wrapf(err, "failed to replace playbook members for playbook with id '%s'", rawPlaybook.ID)
 	}
 
+	if err = p.replacePlaybookGroups(tx, rawPlaybook.Playbook); err != nil {
+		return errors.Wrapf(err, "failed to replace playbook groups for playbook with id '%s'", rawPlaybook.ID)
+	}
+
+	if err = p.replacePlaybookTeams(tx, rawPlaybook.Playbook); err != nil {
+		return errors.Wrapf(err, "failed to replace playbook shared teams for playbook with id '%s'", rawPlaybook.ID)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "could not commit transaction")
 	}
@@ -322,40 +482,76 @@ func (p *playbookStore) Delete(id string) error {
 	return nil
 }
 
-// replacePlaybookMembers replaces the members of a playbook
+// GetPlaybookIDForExternalID returns the ID of the playbook of the team with the given external
+// ID, ignoring the deleted playbooks.
+func (p *playbookStore) GetPlaybookIDForExternalID(teamID, externalID string) (string, error) {
+	query := p.queryBuilder.
+		Select("ID").
+		From("IR_Playbook").
+		Where(sq.Eq{"TeamID": teamID}).
+		Where(sq.Eq{"ExternalID": externalID}).
+		Where(sq.Eq{"DeleteAt": 0})
+
+	var id string
+	err := p.store.getBuilder(p.store.db, &id, query)
+	if err == sql.ErrNoRows {
+		return "", errors.Wrapf(playbook.ErrNotFound, "no playbook of team '%s' has the external ID '%s'", teamID, externalID)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to get playbook with external ID '%s'", externalID)
+	}
+
+	return id, nil
+}
+
+// replacePlaybookMembers replaces the members of a playbook, and their roles
 func (p *playbookStore) replacePlaybookMembers(q queryExecer, pbook playbook.Playbook) error {
-	// Delete existing members who are not in the new pbook.MemberIDs list
+	members := pbook.AllMembers()
+	memberIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+
+	// Delete existing members who are not in the new members list
 	delBuilder := sq.Delete("IR_PlaybookMember").
 		Where(sq.Eq{"PlaybookID": pbook.ID}).
-		Where(sq.NotEq{"MemberID": pbook.MemberIDs})
+		Where(sq.NotEq{"MemberID": memberIDs})
 	if _, err := p.store.execBuilder(q, delBuilder); err != nil {
 		return err
 	}
 
-	if len(pbook.MemberIDs) == 0 {
+	if len(members) == 0 {
 		return nil
 	}
 
 	insertExpr := `
-INSERT INTO IR_PlaybookMember(PlaybookID, MemberID)
-    SELECT ?, ?
+INSERT INTO IR_PlaybookMember(PlaybookID, MemberID, Role)
+    SELECT ?, ?, ?
     WHERE NOT EXISTS (
         SELECT 1 FROM IR_PlaybookMember
             WHERE PlaybookID = ? AND MemberID = ?
     );`
 	if p.store.db.DriverName() == model.DATABASE_DRIVER_MYSQL {
 		insertExpr = `
-INSERT INTO IR_PlaybookMember(PlaybookID, MemberID)
-    SELECT ?, ? FROM DUAL
+INSERT INTO IR_PlaybookMember(PlaybookID, MemberID, Role)
+    SELECT ?, ?, ? FROM DUAL
     WHERE NOT EXISTS (
         SELECT 1 FROM IR_PlaybookMember
             WHERE PlaybookID = ? AND MemberID = ?
     );`
 	}
 
-	for _, m := range pbook.MemberIDs {
+	for _, m := range members {
+		// Update the role of an existing member, or add the member.
+		if _, err := p.store.execBuilder(q, sq.
+			Update("IR_PlaybookMember").
+			Set("Role", m.Role).
+			Where(sq.Eq{"PlaybookID": pbook.ID}).
+			Where(sq.Eq{"MemberID": m.UserID})); err != nil {
+			return err
+		}
+
 		rawInsert := sq.Expr(insertExpr,
-			pbook.ID, m, pbook.ID, m)
+			pbook.ID, m.UserID, m.Role, pbook.ID, m.UserID)
 
 		if _, err := p.store.execBuilder(q, rawInsert); err != nil {
 			return err
@@ -365,13 +561,57 @@ INSERT INTO IR_PlaybookMember(PlaybookID, MemberID)
 	return nil
 }
 
+// replacePlaybookGroups replaces the groups of a playbook, and their roles
+func (p *playbookStore) replacePlaybookGroups(q queryExecer, pbook playbook.Playbook) error {
+	if _, err := p.store.execBuilder(q, sq.
+		Delete("IR_PlaybookGroup").
+		Where(sq.Eq{"PlaybookID": pbook.ID})); err != nil {
+		return err
+	}
+
+	if len(pbook.Groups) == 0 {
+		return nil
+	}
+
+	insert := sq.Insert("IR_PlaybookGroup").Columns("PlaybookID", "GroupID", "Role")
+	for _, g := range pbook.Groups {
+		insert = insert.Values(pbook.ID, g.GroupID, g.Role)
+	}
+
+	_, err := p.store.execBuilder(q, insert)
+	return err
+}
+
+// replacePlaybookTeams replaces the teams a playbook is shared with
+func (p *playbookStore) replacePlaybookTeams(q queryExecer, pbook playbook.Playbook) error {
+	if _, err := p.store.execBuilder(q, sq.
+		Delete("IR_PlaybookTeam").
+		Where(sq.Eq{"PlaybookID": pbook.ID})); err != nil {
+		return err
+	}
+
+	if len(pbook.SharedTeamIDs) == 0 {
+		return nil
+	}
+
+	insert := sq.Insert("IR_PlaybookTeam").Columns("PlaybookID", "TeamID")
+	for _, teamID := range pbook.SharedTeamIDs {
+		insert = insert.Values(pbook.ID, teamID)
+	}
+
+	_, err := p.store.execBuilder(q, insert)
+	return err
+}
+
 func addMembersToPlaybooks(memberIDs playbookMembers, out []playbook.Playbook) {
-	pToM := make(map[string][]string)
+	pToM := make(map[string][]playbook.Member)
 	for _, m := range memberIDs {
-		pToM[m.PlaybookID] = append(pToM[m.PlaybookID], m.MemberID)
+		pToM[m.PlaybookID] = append(pToM[m.PlaybookID], playbook.Member{UserID: m.MemberID, Role: m.Role})
 	}
 	for i, p := range out {
-		out[i].MemberIDs = pToM[p.ID]
+		if members, ok := pToM[p.ID]; ok {
+			out[i].SetMembers(members)
+		}
 	}
 }
 
@@ -395,10 +635,16 @@ func toSQLPlaybook(origPlaybook playbook.Playbook) (*sqlPlaybook, error) {
 		return nil, errors.Wrapf(err, "failed to marshal propertylist json for incident id: '%s'", origPlaybook.ID)
 	}
 
+	exitCriteriaJSON, err := json.Marshal(origPlaybook.ExitCriteria)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal exit criteria json for playbook id: '%s'", origPlaybook.ID)
+	}
+
 	return &sqlPlaybook{
 		Playbook:         origPlaybook,
 		ChecklistsJSON:   checklistsJSON,
 		PropertylistJSON: propertylistJSON,
+		ExitCriteriaJSON: exitCriteriaJSON,
 	}, nil
 }
 
@@ -412,6 +658,12 @@ func toPlaybook(rawPlaybook sqlPlaybook) (playbook.Playbook, error) {
 		return playbook.Playbook{}, errors.Wrapf(err, "failed to unmarshal propertylist json for playbook id: '%s'", p.ID)
 	}
 
+	if len(rawPlaybook.ExitCriteriaJSON) > 0 {
+		if err := json.Unmarshal(rawPlaybook.ExitCriteriaJSON, &p.ExitCriteria); err != nil {
+			return playbook.Playbook{}, errors.Wrapf(err, "failed to unmarshal exit criteria json for playbook id: '%s'", p.ID)
+		}
+	}
+
 	return p, nil
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/cursor"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
 	mock_sqlstore "github.com/mattermost/mattermost-plugin-incident-collaboration/server/sqlstore/mocks"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -867,6 +868,7 @@ func TestGetPlaybooksForTeam(t *testing.T) {
 		_, store := setupSQLStore(t, db)
 		setupUsersTable(t, db)
 		setupTeamMembersTable(t, db)
+		setupGroupMembersTable(t, db)
 		addUsers(t, store, users)
 		addUsersToTeam(t, store, users, team1id)
 		addUsersToTeam(t, store, users, team2id)
@@ -897,11 +899,138 @@ func TestGetPlaybooksForTeam(t *testing.T) {
 				for i := range testCase.expected.Items {
 					testCase.expected.Items[i].Checklists = nil
 					testCase.expected.Items[i].MemberIDs = nil
+					testCase.expected.Items[i].Members = nil
 				}
 
 				require.Equal(t, testCase.expected, actual)
 			})
 		}
+
+		t.Run(driverName+" - team1 from Admin, paged with a cursor", func(t *testing.T) {
+			requesterInfo := playbook.RequesterInfo{
+				UserID:          lucia.ID,
+				UserIDtoIsAdmin: map[string]bool{lucia.ID: true},
+			}
+			options := playbook.Options{
+				Sort:      playbook.SortBySteps,
+				Direction: playbook.DirectionDesc,
+				PerPage:   1,
+			}
+
+			var titles []string
+			for {
+				actual, err := playbookStore.GetPlaybooksForTeam(requesterInfo, team1id, options)
+				require.NoError(t, err)
+				require.Len(t, actual.Items, 1)
+				titles = append(titles, actual.Items[0].Title)
+				if !actual.HasMore {
+					require.Empty(t, actual.NextCursor)
+					break
+				}
+				options.Cursor = actual.NextCursor
+			}
+
+			require.Equal(t, []string{pb04.Title, pb02.Title, pb03.Title, pb01.Title}, titles)
+
+			options.Sort = playbook.SortByTitle
+			_, err := playbookStore.GetPlaybooksForTeam(requesterInfo, team1id, options)
+			require.True(t, errors.Is(err, cursor.ErrInvalid))
+		})
+
+		t.Run(driverName+" - playbooks with roles and groups", func(t *testing.T) {
+			groupID := model.NewId()
+			addUsersToGroup(t, store, []userInfo{desmond}, groupID)
+
+			viewed := NewPBBuilder().
+				WithTitle("viewed playbook").
+				WithTeamID(team2id).
+				ToPlaybook()
+			viewed.SetMembers([]playbook.Member{{UserID: jen.ID, Role: playbook.RoleViewer}})
+			viewed.Groups = []playbook.GroupMember{{GroupID: groupID, Role: playbook.RoleRunner}}
+			viewedID, err := playbookStore.Create(viewed)
+			require.NoError(t, err)
+
+			actual, err := playbookStore.Get(viewedID)
+			require.NoError(t, err)
+			require.Equal(t, viewed.Members, actual.Members)
+			require.Equal(t, viewed.Groups, actual.Groups)
+
+			getIDs := func(userID string, minRole playbook.Role) []string {
+				requesterInfo := playbook.RequesterInfo{
+					UserID:          userID,
+					UserIDtoIsAdmin: map[string]bool{},
+					MemberOnly:      true,
+					MinRole:         minRole,
+				}
+				result, err := playbookStore.GetPlaybooksForTeam(requesterInfo, team2id, playbook.Options{})
+				require.NoError(t, err)
+
+				var ids []string
+				for _, p := range result.Items {
+					if p.ID == viewedID {
+						ids = append(ids, p.ID)
+					}
+				}
+				return ids
+			}
+
+			// Jen can view the playbook, but not start incidents from it.
+			require.Equal(t, []string{viewedID}, getIDs(jen.ID, ""))
+			require.Empty(t, getIDs(jen.ID, playbook.RoleRunner))
+
+			// Desmond can start incidents from it through the group.
+			require.Equal(t, []string{viewedID}, getIDs(desmond.ID, playbook.RoleRunner))
+			require.Empty(t, getIDs(desmond.ID, playbook.RoleEditor))
+
+			require.Empty(t, getIDs(bill.ID, ""))
+		})
+
+		t.Run(driverName+" - playbooks published to other teams", func(t *testing.T) {
+			shared := NewPBBuilder().
+				WithTitle("shared playbook").
+				WithTeamID(team3id).
+				WithChecklists([]int{1}).
+				ToPlaybook()
+			shared.SharedTeamIDs = []string{team2id}
+			shared.Version = 2
+			sharedID, err := playbookStore.Create(shared)
+			require.NoError(t, err)
+
+			global := NewPBBuilder().
+				WithTitle("global playbook").
+				WithTeamID(team3id).
+				ToPlaybook()
+			global.IsGlobal = true
+			globalID, err := playbookStore.Create(global)
+			require.NoError(t, err)
+
+			actual, err := playbookStore.Get(sharedID)
+			require.NoError(t, err)
+			require.Equal(t, []string{team2id}, actual.SharedTeamIDs)
+			require.Equal(t, int64(2), actual.Version)
+
+			// Bill is a member of no playbook, but can use the ones published to their team.
+			requesterInfo := playbook.RequesterInfo{
+				UserID:          bill.ID,
+				UserIDtoIsAdmin: map[string]bool{},
+			}
+
+			result, err := playbookStore.GetPlaybooksForTeam(requesterInfo, team2id, playbook.Options{})
+			require.NoError(t, err)
+			var ids []string
+			for _, p := range result.Items {
+				ids = append(ids, p.ID)
+			}
+			require.ElementsMatch(t, []string{sharedID, globalID}, ids)
+
+			result, err = playbookStore.GetPlaybooksForTeam(requesterInfo, team1id, playbook.Options{})
+			require.NoError(t, err)
+			ids = nil
+			for _, p := range result.Items {
+				ids = append(ids, p.ID)
+			}
+			require.Equal(t, []string{globalID}, ids)
+		})
 	}
 }
 
@@ -1032,7 +1161,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
 					WithMembers([]userInfo{jon, andrew}).ToPlaybook(),
 				update: func(old playbook.Playbook) playbook.Playbook {
-					old.MemberIDs = []string{andrew.ID}
+					old.SetMembers(editors(andrew.ID))
 					return old
 				},
 				expectedErr: nil,
@@ -1042,7 +1171,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
 					WithMembers([]userInfo{jon, andrew, bob}).ToPlaybook(),
 				update: func(old playbook.Playbook) playbook.Playbook {
-					old.MemberIDs = []string{matt.ID, bill.ID, alice.ID, jen.ID}
+					old.SetMembers(editors(matt.ID, bill.ID, alice.ID, jen.ID))
 					return old
 				},
 				expectedErr: nil,
@@ -1052,7 +1181,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
 					WithMembers([]userInfo{jon, andrew, bob}).ToPlaybook(),
 				update: func(old playbook.Playbook) playbook.Playbook {
-					old.MemberIDs = []string{jon.ID, andrew.ID, bob.ID, alice.ID}
+					old.SetMembers(editors(jon.ID, andrew.ID, bob.ID, alice.ID))
 					return old
 				},
 				expectedErr: nil,
@@ -1061,7 +1190,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				name:     "Incident with 0 members, go to 2",
 				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).ToPlaybook(),
 				update: func(old playbook.Playbook) playbook.Playbook {
-					old.MemberIDs = []string{alice.ID, jen.ID}
+					old.SetMembers(editors(alice.ID, jen.ID))
 					return old
 				},
 				expectedErr: nil,
@@ -1079,7 +1208,7 @@ func TestUpdatePlaybook(t *testing.T) {
 					}).
 					ToPlaybook(),
 				update: func(old playbook.Playbook) playbook.Playbook {
-					old.MemberIDs = nil
+					old.SetMembers(nil)
 					return old
 				},
 				expectedErr: nil,
@@ -1168,6 +1297,43 @@ func TestDeletePlaybook(t *testing.T) {
 	}
 }
 
+func TestGetPlaybookIDForExternalID(t *testing.T) {
+	team1id := model.NewId()
+	team2id := model.NewId()
+
+	pb := NewPBBuilder().
+		WithTitle("playbook 1").
+		WithTeamID(team1id).
+		ToPlaybook()
+	pb.ExternalID = "sev1-response"
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		id, err := playbookStore.Create(pb)
+		require.NoError(t, err)
+
+		t.Run(driverName+" - found in the team", func(t *testing.T) {
+			actual, err := playbookStore.GetPlaybookIDForExternalID(team1id, "sev1-response")
+			require.NoError(t, err)
+			require.Equal(t, id, actual)
+		})
+
+		t.Run(driverName+" - not in another team", func(t *testing.T) {
+			_, err := playbookStore.GetPlaybookIDForExternalID(team2id, "sev1-response")
+			require.True(t, errors.Is(err, playbook.ErrNotFound))
+		})
+
+		t.Run(driverName+" - not once deleted", func(t *testing.T) {
+			require.NoError(t, playbookStore.Delete(id))
+
+			_, err := playbookStore.GetPlaybookIDForExternalID(team1id, "sev1-response")
+			require.True(t, errors.Is(err, playbook.ErrNotFound))
+		})
+	}
+}
+
 // PlaybookBuilder is a utility to build playbooks with a default base.
 // Use it as:
 // NewBuilder.WithName("name").WithXYZ(xyz)....ToPlaybook()
@@ -1265,15 +1431,24 @@ func sum(nums []int) int64 {
 }
 
 func (p *PlaybookBuilder) WithMembers(members []userInfo) *PlaybookBuilder {
-	p.MemberIDs = make([]string, len(members))
-
-	for i, member := range members {
-		p.MemberIDs[i] = member.ID
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.ID)
 	}
+	p.SetMembers(editors(ids...))
 
 	return p
 }
 
+// editors returns the given users as editors of a playbook.
+func editors(userIDs ...string) []playbook.Member {
+	var members []playbook.Member
+	for _, userID := range userIDs {
+		members = append(members, playbook.Member{UserID: userID, Role: playbook.RoleEditor})
+	}
+	return members
+}
+
 func (p *PlaybookBuilder) ToPlaybook() playbook.Playbook {
 	return *p.Playbook
 }
@@ -19,22 +19,31 @@ type StoreAPI interface {
 // It is implemented by mattermost-plugin-api/Client.KV, or by the mock KVAPI.
 type KVAPI interface {
 	Get(key string, out interface{}) error
+	Set(key string, value interface{}, options ...pluginapi.KVSetOption) (bool, error)
 }
 
 type ConfigurationAPI interface {
 	GetConfig() *model.Config
 }
 
+// ClusterAPI sends events to the plugin on the other app nodes of a cluster, which receive them
+// in their OnPluginClusterEvent hook.
+type ClusterAPI interface {
+	PublishPluginClusterEvent(id string, data []byte) error
+}
+
 // PluginAPIClient is the struct combining the interfaces defined above, which is everything
 // from pluginapi that the store currently uses.
 type PluginAPIClient struct {
 	Store         StoreAPI
 	KV            KVAPI
 	Configuration ConfigurationAPI
+	Cluster       ClusterAPI
 }
 
 // NewClient receives a pluginapi.Client and returns the PluginAPIClient, which is what the
-// store will use to access pluginapi.Client.
+// store will use to access pluginapi.Client. The servers the plugin supports cannot send cluster
+// events yet, so Cluster is left nil.
 func NewClient(api *pluginapi.Client) PluginAPIClient {
 	return PluginAPIClient{
 		Store:         api.Store,
@@ -2,6 +2,9 @@ package sqlstore
 
 import (
 	"database/sql"
+	"regexp"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -10,15 +13,25 @@ import (
 	"github.com/pkg/errors"
 )
 
+// slowQueryThreshold is the duration from which a query is logged as slow.
+const slowQueryThreshold = time.Second
+
+// QueryMetrics records the duration of the queries run by the store.
+type QueryMetrics interface {
+	ObserveStoreQuery(query string, elapsed time.Duration)
+}
+
 type SQLStore struct {
 	log                 bot.Logger
 	db                  *sqlx.DB
 	builder             sq.StatementBuilderType
 	cachedUnaccentCheck *bool
+	metrics             QueryMetrics
 }
 
-// New constructs a new instance of SQLStore.
-func New(pluginAPI PluginAPIClient, log bot.Logger) (*SQLStore, error) {
+// New constructs a new instance of SQLStore, recording the duration of its queries in metrics
+// if not nil.
+func New(pluginAPI PluginAPIClient, log bot.Logger, metrics QueryMetrics) (*SQLStore, error) {
 	var db *sqlx.DB
 
 	origDB, err := pluginAPI.Store.GetMasterDB()
@@ -41,6 +54,7 @@ func New(pluginAPI PluginAPIClient, log bot.Logger) (*SQLStore, error) {
 		db,
 		builder,
 		nil,
+		metrics,
 	}, nil
 }
 
@@ -70,7 +84,9 @@ func (sqlStore *SQLStore) getBuilder(q sqlx.Queryer, dest interface{}, b builder
 
 	sqlString = sqlStore.db.Rebind(sqlString)
 
-	return sqlx.Get(q, dest, sqlString, args...)
+	return sqlStore.timed(sqlString, func() error {
+		return sqlx.Get(q, dest, sqlString, args...)
+	})
 }
 
 // selectBuilder queries for one or more rows, building the sql, and writing the result into dest.
@@ -85,7 +101,9 @@ func (sqlStore *SQLStore) selectBuilder(q sqlx.Queryer, dest interface{}, b buil
 
 	sqlString = sqlStore.db.Rebind(sqlString)
 
-	return sqlx.Select(q, dest, sqlString, args...)
+	return sqlStore.timed(sqlString, func() error {
+		return sqlx.Select(q, dest, sqlString, args...)
+	})
 }
 
 // execer is an interface describing a resource that can execute write queries.
@@ -104,7 +122,15 @@ type queryExecer interface {
 // exec executes the given query using positional arguments, automatically rebinding for the db.
 func (sqlStore *SQLStore) exec(e execer, sqlString string, args ...interface{}) (sql.Result, error) {
 	sqlString = sqlStore.db.Rebind(sqlString)
-	return e.Exec(sqlString, args...)
+
+	var result sql.Result
+	err := sqlStore.timed(sqlString, func() error {
+		var err error
+		result, err = e.Exec(sqlString, args...)
+		return err
+	})
+
+	return result, err
 }
 
 // exec executes the given query, building the necessary sql.
@@ -117,6 +143,46 @@ func (sqlStore *SQLStore) execBuilder(e execer, b builder) (sql.Result, error) {
 	return sqlStore.exec(e, sqlString, args...)
 }
 
+// timed runs query, recording its duration under the name of the statement and logging it if
+// slow. It only runs query when the store has no metrics.
+func (sqlStore *SQLStore) timed(sqlString string, query func() error) error {
+	if sqlStore.metrics == nil {
+		return query()
+	}
+
+	logger := sqlStore.log.Timed()
+	start := time.Now()
+	err := query()
+	elapsed := time.Since(start)
+
+	name := queryName(sqlString)
+	sqlStore.metrics.ObserveStoreQuery(name, elapsed)
+	if elapsed >= slowQueryThreshold {
+		logger.With(bot.LogContext{"query": name}).Warnf("slow store query")
+	}
+
+	return err
+}
+
+// queryTableRegexp matches the first table a statement reads or writes.
+var queryTableRegexp = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|TABLE(?: IF (?:NOT )?EXISTS)?)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// queryName names a statement by its kind and the first table it reads or writes, e.g.
+// "select IR_Incident", to record its duration without a series per distinct query.
+func queryName(sqlString string) string {
+	fields := strings.Fields(sqlString)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+
+	name := strings.ToLower(fields[0])
+	if match := queryTableRegexp.FindStringSubmatch(sqlString); match != nil {
+		name += " " + match[1]
+	}
+
+	return name
+}
+
 // finalizeTransaction ensures a transaction is closed after use, rolling back if not already committed.
 func (sqlStore *SQLStore) finalizeTransaction(tx *sqlx.Tx) {
 	// Rollback returns sql.ErrTxDone if the transaction was already closed.
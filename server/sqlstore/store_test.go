@@ -30,6 +30,7 @@ func TestMigrationIdempotency(t *testing.T) {
 				setupTestDB(t, driver),
 				builder,
 				nil,
+				nil,
 			}
 
 			// Make sure we start from scratch
@@ -56,6 +57,7 @@ func TestMigrationIdempotency(t *testing.T) {
 				setupTestDB(t, driver),
 				builder,
 				nil,
+				nil,
 			}
 
 			// Make sure we start from scratch
@@ -77,3 +79,20 @@ func TestMigrationIdempotency(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryName(t *testing.T) {
+	tests := map[string]string{
+		"SELECT i.ID, i.Name FROM IR_Incident AS i WHERE i.ID = ?":                 "select IR_Incident",
+		"SELECT COUNT(*) FROM (SELECT ID FROM IR_Playbook WHERE TeamID = $1) AS p": "select IR_Playbook",
+		"INSERT INTO IR_TimelineEvent (ID, IncidentID) VALUES (?, ?)":              "insert IR_TimelineEvent",
+		"UPDATE IR_Incident SET Name = ? WHERE ID = ?":                             "update IR_Incident",
+		"DELETE FROM IR_PlaybookMember WHERE PlaybookID = ?":                       "delete IR_PlaybookMember",
+		"CREATE TABLE IF NOT EXISTS IR_System (SKey VARCHAR(64) PRIMARY KEY)":      "create IR_System",
+		"\n\tselect 1": "select",
+		"":             "unknown",
+	}
+
+	for sqlString, expected := range tests {
+		require.Equal(t, expected, queryName(sqlString), sqlString)
+	}
+}
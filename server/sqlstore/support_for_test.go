@@ -57,6 +57,7 @@ func setupSQLStore(t testing.TB, db *sqlx.DB) (bot.Logger, *SQLStore) {
 		db,
 		builder,
 		nil,
+		nil,
 	}
 
 	logger.EXPECT().Debugf(gomock.AssignableToTypeOf("string")).Times(2)
@@ -228,6 +229,50 @@ func setupTeamMembersTable(t *testing.T, db *sqlx.DB) {
 	require.NoError(t, err)
 }
 
+func setupGroupMembersTable(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	// Statements copied from mattermost-server/scripts/mattermost-postgresql-5.0.sql
+	if db.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		_, err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS public.groupmembers (
+				groupid character varying(26) NOT NULL,
+				userid character varying(26) NOT NULL,
+				createat bigint,
+				deleteat bigint NOT NULL
+			);
+		`)
+		require.NoError(t, err)
+
+		return
+	}
+
+	// Statements copied from mattermost-server/scripts/mattermost-mysql-5.0.sql
+	_, err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS GroupMembers (
+			  GroupId varchar(26) NOT NULL,
+			  UserId varchar(26) NOT NULL,
+			  CreateAt bigint(20) DEFAULT NULL,
+			  DeleteAt bigint(20) NOT NULL,
+			  PRIMARY KEY (GroupId,UserId),
+			  KEY idx_groupmembers_create_at (CreateAt)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+		`)
+	require.NoError(t, err)
+}
+
+func addUsersToGroup(t *testing.T, store *SQLStore, users []userInfo, groupID string) {
+	t.Helper()
+
+	insertBuilder := store.builder.Insert("GroupMembers").Columns("GroupId", "UserId", "CreateAt", "DeleteAt")
+	for _, u := range users {
+		insertBuilder = insertBuilder.Values(groupID, u.ID, model.GetMillis(), 0)
+	}
+
+	_, err := store.execBuilder(store.db, insertBuilder)
+	require.NoError(t, err)
+}
+
 func setupChannelMembersTable(t *testing.T, db *sqlx.DB) {
 	t.Helper()
 
@@ -273,7 +318,7 @@ func setupChannelMembersTable(t *testing.T, db *sqlx.DB) {
 	require.NoError(t, err)
 }
 
-func setupChannelsTable(t *testing.T, db *sqlx.DB) {
+func setupChannelsTable(t testing.TB, db *sqlx.DB) {
 	t.Helper()
 
 	// Statements copied from mattermost-server/scripts/mattermost-postgresql-5.0.sql
@@ -424,10 +469,10 @@ func addUsers(t *testing.T, store *SQLStore, users []userInfo) {
 func addUsersToTeam(t *testing.T, store *SQLStore, users []userInfo, teamID string) {
 	t.Helper()
 
-	insertBuilder := store.builder.Insert("TeamMembers").Columns("TeamId", "UserId")
+	insertBuilder := store.builder.Insert("TeamMembers").Columns("TeamId", "UserId", "DeleteAt")
 
 	for _, u := range users {
-		insertBuilder = insertBuilder.Values(teamID, u.ID)
+		insertBuilder = insertBuilder.Values(teamID, u.ID, 0)
 	}
 
 	_, err := store.execBuilder(store.db, insertBuilder)
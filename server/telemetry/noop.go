@@ -18,6 +18,11 @@ func (t *NoopTelemetry) Disable() error {
 	return nil
 }
 
+// QueueSize returns always 0, since no event is ever queued.
+func (t *NoopTelemetry) QueueSize() int64 {
+	return 0
+}
+
 // CreateIncident does nothing
 func (t *NoopTelemetry) CreateIncident(*incident.Incident, string, bool) {
 }
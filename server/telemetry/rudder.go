@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/incident"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/playbook"
@@ -19,6 +20,22 @@ type RudderTelemetry struct {
 	dataPlaneURL  string
 	enabled       bool
 	mutex         sync.RWMutex
+	queue         *queueCounter
+}
+
+// queueCounter counts the events queued in the Rudder client and not yet sent or dropped.
+type queueCounter struct {
+	pending int64
+}
+
+// Success is called by the Rudder client for every event sent.
+func (q *queueCounter) Success(rudder.Message) {
+	atomic.AddInt64(&q.pending, -1)
+}
+
+// Failure is called by the Rudder client for every event it failed to send and dropped.
+func (q *queueCounter) Failure(rudder.Message, error) {
+	atomic.AddInt64(&q.pending, -1)
 }
 
 // Unique strings that identify each of the tracked events
@@ -63,7 +80,8 @@ func NewRudder(dataPlaneURL, writeKey, diagnosticID, pluginVersion, serverVersio
 		return nil, errors.New("serverVersion should not be empty")
 	}
 
-	client, err := rudder.NewWithConfig(writeKey, dataPlaneURL, rudder.Config{})
+	queue := &queueCounter{}
+	client, err := rudder.NewWithConfig(writeKey, dataPlaneURL, rudder.Config{Callback: queue})
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +94,7 @@ func NewRudder(dataPlaneURL, writeKey, diagnosticID, pluginVersion, serverVersio
 		writeKey:      writeKey,
 		dataPlaneURL:  dataPlaneURL,
 		enabled:       true,
+		queue:         queue,
 	}, nil
 }
 
@@ -90,11 +109,19 @@ func (t *RudderTelemetry) track(event string, properties map[string]interface{})
 	properties["PluginVersion"] = t.pluginVersion
 	properties["ServerVersion"] = t.serverVersion
 
-	_ = t.client.Enqueue(rudder.Track{
+	err := t.client.Enqueue(rudder.Track{
 		UserId:     t.diagnosticID,
 		Event:      event,
 		Properties: properties,
 	})
+	if err == nil {
+		atomic.AddInt64(&t.queue.pending, 1)
+	}
+}
+
+// QueueSize returns the number of events waiting to be sent.
+func (t *RudderTelemetry) QueueSize() int64 {
+	return atomic.LoadInt64(&t.queue.pending)
 }
 
 func incidentProperties(incdnt *incident.Incident, userID string) map[string]interface{} {
@@ -289,7 +316,7 @@ func (t *RudderTelemetry) Enable() error {
 		return nil
 	}
 
-	newClient, err := rudder.NewWithConfig(t.writeKey, t.dataPlaneURL, rudder.Config{})
+	newClient, err := rudder.NewWithConfig(t.writeKey, t.dataPlaneURL, rudder.Config{Callback: t.queue})
 	if err != nil {
 		return errors.Wrap(err, "creating a new Rudder client in Enable failed")
 	}
@@ -62,10 +62,12 @@ func setupRudder(t *testing.T, data chan<- rudderPayload) (*RudderTelemetry, *ht
 	}))
 
 	writeKey := "dummy_key"
+	queue := &queueCounter{}
 	client, err := rudder.NewWithConfig(writeKey, server.URL, rudder.Config{
 		BatchSize: 1,
 		Interval:  1 * time.Millisecond,
 		Verbose:   true,
+		Callback:  queue,
 	})
 	require.NoError(t, err)
 
@@ -77,6 +79,7 @@ func setupRudder(t *testing.T, data chan<- rudderPayload) (*RudderTelemetry, *ht
 		writeKey:      writeKey,
 		dataPlaneURL:  server.URL,
 		enabled:       true,
+		queue:         queue,
 	}, server
 }
 
@@ -196,6 +199,27 @@ func TestRudderTelemetry(t *testing.T) {
 	}
 }
 
+func TestQueueSize(t *testing.T) {
+	data := make(chan rudderPayload)
+	rudderClient, rudderServer := setupRudder(t, data)
+	defer rudderServer.Close()
+
+	require.Equal(t, int64(0), rudderClient.QueueSize())
+
+	rudderClient.CreateIncident(dummyIncident, dummyUserID, true)
+	require.Equal(t, int64(1), rudderClient.QueueSize())
+
+	select {
+	case <-data:
+	case <-time.After(time.Second * 1):
+		require.Fail(t, "Did not receive Event message")
+	}
+
+	require.Eventually(t, func() bool {
+		return rudderClient.QueueSize() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
 func TestDisableTelemetry(t *testing.T) {
 	t.Run("disable client", func(t *testing.T) {
 		data := make(chan rudderPayload)
@@ -18,6 +18,7 @@ import {
     FetchIncidentsParams,
     FetchPlaybooksParams,
     FetchIncidentsReturn,
+    SearchIncidentsReturn,
     Incident,
     isIncident,
     isMetadata,
@@ -41,7 +42,7 @@ import { generatePropertyList } from './mock_property_list';
 const apiUrl = `/plugins/${pluginId}/api/v0`;
 
 export async function fetchIncidents(params: FetchIncidentsParams) {
-    const queryParams = qs.stringify(params, {addQueryPrefix: true});
+    const queryParams = qs.stringify(params, {addQueryPrefix: true, arrayFormat: 'repeat'});
 
     let data = await doGet(`${apiUrl}/incidents${queryParams}`);
     if (!data) {
@@ -58,6 +59,18 @@ export async function fetchIncidents(params: FetchIncidentsParams) {
     return data as FetchIncidentsReturn;
 }
 
+export async function searchIncidents(teamId: string, terms: string, page = 0, perPage = 20) {
+    const queryParams = qs.stringify({
+        team_id: teamId,
+        terms,
+        page,
+        per_page: perPage,
+    }, {addQueryPrefix: true});
+
+    const data = await doGet(`${apiUrl}/incidents/search${queryParams}`);
+    return data as SearchIncidentsReturn;
+}
+
 export async function fetchIncident(id: string) {
     const data = await doGet(`${apiUrl}/incidents/${id}`);
     // eslint-disable-next-line no-process-env
@@ -203,6 +216,16 @@ export async function setCommander(incidentId: string, commanderId: string) {
     }
 }
 
+export async function requestCommanderHandoff(incidentId: string, commanderId: string, notes: string) {
+    const body = JSON.stringify({commander_id: commanderId, notes});
+    try {
+        const data = await doPost(`${apiUrl}/incidents/${incidentId}/commander/handoff`, body);
+        return data;
+    } catch (error) {
+        return {error};
+    }
+}
+
 export async function setPropertySelectionValue(incidentId: string, propertylistItemId: string, selectedOptionId: string) {
     const body = `{"property_id": "${propertylistItemId}" , "selection_id": "${selectedOptionId}"}`;
     try {
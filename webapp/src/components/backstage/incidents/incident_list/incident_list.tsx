@@ -160,6 +160,7 @@ const BackstageIncidentList: FC = () => {
             per_page: BACKSTAGE_LIST_PER_PAGE,
             sort: 'create_at',
             direction: 'desc',
+            fields: 'summary',
         },
     );
 
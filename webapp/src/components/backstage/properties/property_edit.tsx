@@ -354,7 +354,7 @@ const PropertyEditor: FC<PropertyEditorProps> = (props: PropertyEditorProps) =>
                                 <PropertySelectionlistEditor
                                     key={props.property.id}
                                     selectionlist={props.property.selection}
-                                    selectionlistIndex={props.property.selection.selected_id}
+                                    selectionlistIndex={(props.property.selection.selected_ids || []).join(',')}
                                     setSelectionlist={(selection: any) => submit({ ...props.property, selection })}
                                 />
                             }
@@ -126,9 +126,9 @@ export default function PropertySelector(props: Props) {
     };
 
     let target;
-    if (props.property.selection && props.property.selection.selected_id) {
+    if (props.property.selection && props.property.selection.selected_ids && props.property.selection.selected_ids.length > 0) {
 
-        const idx = props.property.selection.items.findIndex(x => x.id == props.property.selection?.selected_id)
+        const idx = props.property.selection.items.findIndex(x => x.id == props.property.selection?.selected_ids[0])
         const val = props.property.selection.items[idx];
         target = (
             <PropertyButton
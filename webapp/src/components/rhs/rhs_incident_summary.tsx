@@ -118,7 +118,7 @@ const RHSIncidentSummary: FC<Props> = (props: Props) => {
                             { property.freetext && property.freetext.is_multiselect &&
                                 property.type === PropertyType.Freetext &&
                                 <PropertyFreeTextMultiselectItem
-                                    value={property.freetext.value}
+                                    value={(property.freetext.values || []).join(',')}
                                     propertyId={property.id}
                                     onSelectedChange={onSelectedFreetextChange}
                                 />
@@ -138,7 +138,7 @@ const RHSIncidentSummary: FC<Props> = (props: Props) => {
                               property.type === PropertyType.Selection &&
                               !property.selection.is_multiselect &&
                                 <PropertySelector
-                                    selectedValueId={property.selection.selected_id}
+                                    selectedValueId={(property.selection.selected_ids || []).join(',')}
                                     property={property}
                                     placeholder={''}
                                     placeholderButtonClass={'NoAssignee-button'}
@@ -151,7 +151,7 @@ const RHSIncidentSummary: FC<Props> = (props: Props) => {
                                 property.type === PropertyType.Selection &&
                                 property.selection.is_multiselect &&
                                 <PropertyMultiSelector
-                                    selectedValueId={property.selection.selected_id}
+                                    selectedValueId={(property.selection.selected_ids || []).join(',')}
                                     property={property}
                                     onMultiSelectedChange={onMultiSelectedPropertyChange}
                                 />
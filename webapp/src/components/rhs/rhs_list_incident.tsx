@@ -140,18 +140,18 @@ const RHSListIncident = (props: Props) => {
                                 {item.type == PropertyType.Selection && item.selection && !item.selection.is_multiselect &&
                                     <InfoSelectionBadge
                                         items={item.selection.items}
-                                        value={item.selection.selected_id}
+                                        value={(item.selection.selected_ids || []).join(',')}
                                     />
                                 }
                                 {item.type == PropertyType.Selection && item.selection && item.selection.is_multiselect &&
                                     <InfoMultiSelectionBadge
                                         items={item.selection.items}
-                                        value={item.selection.selected_id}
+                                        value={(item.selection.selected_ids || []).join(',')}
                                     />
                                 }
                                 {item.type == PropertyType.Freetext && item.freetext && item.freetext.is_multiselect &&
                                     <InfoMultiTextBadge
-                                        value={item.freetext.value}
+                                        value={(item.freetext.values || []).join(',')}
                                     />
                                 }
                                 {item.type == PropertyType.Freetext && item.freetext && !item.freetext.is_multiselect &&
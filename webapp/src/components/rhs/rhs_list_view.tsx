@@ -98,7 +98,7 @@ const RHSListView = () => {
     const selectionValueContainsSearch = (i: PropertylistItem) => {
         if (!i.selection)
             return false;
-        var idx = i.selection.selected_id
+        var idx = (i.selection.selected_ids || []).join(',')
 
         if (i.selection.is_multiselect) {
             var inds = idx.split(',')
@@ -133,7 +133,7 @@ const RHSListView = () => {
             i.propertylist.items.filter(x =>
                 x.type === PropertyType.Freetext &&
                 x.freetext &&
-                x.freetext.value.toUpperCase().includes(searchTerm.toUpperCase())
+                [x.freetext.value, ...(x.freetext.values || [])].join(',').toUpperCase().includes(searchTerm.toUpperCase())
             ).length > 0 ||
             i.propertylist.items.filter(x =>
                 x.type === PropertyType.Selection &&
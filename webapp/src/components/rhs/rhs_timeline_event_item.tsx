@@ -137,6 +137,12 @@ const RHSTimelineEventItem = (props: Props) => {
             summaryTitle = 'Property ' + props.event.summary;
             testid = TimelineEventType.PropertyValueChanged;
             break;
+        case TimelineEventType.ExitCriteriaOverridden:
+            iconClass = 'icon icon-alert-outline';
+            summaryTitle = 'Exit Criteria Overridden';
+            summary = props.event.subject_display_name + ' ' + props.event.summary;
+            testid = TimelineEventType.ExitCriteriaOverridden;
+            break;
         case TimelineEventType.TaskStateModified:
             iconClass = 'icon icon-format-list-bulleted';
             summaryTitle = 'Task Modified';
@@ -31,6 +31,7 @@ import reducer from './reducer';
 import {
     handleReconnect,
     handleWebsocketIncidentUpdated,
+    handleWebsocketIncidentChanged,
     handleWebsocketIncidentCreated,
     handleWebsocketUserAdded,
     handleWebsocketUserRemoved,
@@ -39,6 +40,7 @@ import {
 } from './websocket_events';
 import {
     WEBSOCKET_INCIDENT_UPDATED,
+    WEBSOCKET_INCIDENT_CHANGED,
     WEBSOCKET_INCIDENT_CREATED,
 } from './types/websocket_events';
 
@@ -78,6 +80,7 @@ export default class Plugin {
 
         registry.registerReconnectHandler(handleReconnect(store.getState, store.dispatch));
         registry.registerWebSocketEventHandler(WEBSOCKET_INCIDENT_UPDATED, handleWebsocketIncidentUpdated(store.getState, store.dispatch));
+        registry.registerWebSocketEventHandler(WEBSOCKET_INCIDENT_CHANGED, handleWebsocketIncidentChanged(store.getState, store.dispatch));
         registry.registerWebSocketEventHandler(WEBSOCKET_INCIDENT_CREATED, handleWebsocketIncidentCreated(store.getState, store.dispatch));
         registry.registerWebSocketEventHandler(WebsocketEvents.USER_ADDED, handleWebsocketUserAdded(store.getState, store.dispatch));
         registry.registerWebSocketEventHandler(WebsocketEvents.USER_REMOVED, handleWebsocketUserRemoved(store.getState, store.dispatch));
@@ -23,7 +23,7 @@ export function generatePropertyList(): Propertylist {
                             value: 'China'
                         },
                     ],
-                   selected_id: '1,2',
+                   selected_ids: ['1', '2'],
                    is_multiselect: true,
                 },
                 freetext: emptyFreetextOption()
@@ -56,7 +56,7 @@ export function generatePropertyList(): Propertylist {
                             value: 'P3'
                         },
                     ],
-                   selected_id: '2',
+                   selected_ids: ['2'],
                    is_multiselect: false,
                 },
                 freetext: emptyFreetextOption()
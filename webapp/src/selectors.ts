@@ -66,6 +66,18 @@ export const myIncidentsMap = (state: GlobalState) => {
     return myIncidentsByTeam(state)[getCurrentTeamId(state)] || {};
 };
 
+// myIncidentByChannel returns the incident of channelId, in any team, if the current user is a member.
+export const myIncidentByChannel = (state: GlobalState, channelId: string): Incident | undefined => {
+    const incidentMapByTeam = myIncidentsByTeam(state) || {};
+    for (const incidentMap of Object.values(incidentMapByTeam)) {
+        if (incidentMap[channelId]) {
+            return incidentMap[channelId];
+        }
+    }
+
+    return undefined;
+};
+
 export const isExportLicensed = (state: GlobalState): boolean => {
     const license = getLicense(state);
 
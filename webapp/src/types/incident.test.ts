@@ -0,0 +1,73 @@
+import {applyIncidentChanges, incidentCurrentStatus, IncidentStatus} from 'src/types/incident';
+
+describe('applyIncidentChanges', () => {
+    const makeIncident = (): any => ({
+        id: 'incidentId',
+        commander_user_id: 'userId1',
+        checklists: [
+            {title: 'Checklist 1', items: [{title: 'Item 1', state: ''}, {title: 'Item 2', state: ''}]},
+            {title: 'Checklist 2', items: [{title: 'Item 3', state: ''}]},
+        ],
+        propertylist: {items: [{id: 'propertyId', title: 'Impact'}]},
+        timeline_events: [{id: 'eventId1'}],
+        version: 3,
+    });
+
+    it('should replace the values at the changed paths', () => {
+        const incident = makeIncident();
+        const changed = applyIncidentChanges(incident, {
+            incident_id: 'incidentId',
+            previous_version: 3,
+            version: 4,
+            changes: [
+                {path: 'checklists[0].items[1]', value: {title: 'Item 2', state: 'closed'}},
+                {path: 'commander_user_id', value: 'userId2'},
+            ],
+            timeline_events: [{id: 'eventId2'} as any],
+        });
+
+        expect(changed.version).toEqual(4);
+        expect(changed.commander_user_id).toEqual('userId2');
+        expect(changed.checklists[0].items).toEqual([{title: 'Item 1', state: ''}, {title: 'Item 2', state: 'closed'}]);
+        expect(changed.timeline_events).toEqual([{id: 'eventId1'}, {id: 'eventId2'}]);
+    });
+
+    it('should leave the original incident and the unchanged values alone', () => {
+        const incident = makeIncident();
+        const changed = applyIncidentChanges(incident, {
+            incident_id: 'incidentId',
+            previous_version: 3,
+            version: 4,
+            changes: [{path: 'propertylist.items[0]', value: {id: 'propertyId', title: 'Severity'}}],
+            timeline_events: [],
+        });
+
+        expect(incident).toEqual(makeIncident());
+        expect(changed.propertylist.items[0].title).toEqual('Severity');
+        expect(changed.checklists).toBe(incident.checklists);
+    });
+});
+
+describe('incidentCurrentStatus', () => {
+    it('should use the status of the summary of listed incidents', () => {
+        const incident: any = {
+            end_at: 0,
+            status_posts: [],
+            summary: {status: IncidentStatus.Resolved, tasks_total: 3, tasks_done: 3},
+        };
+
+        expect(incidentCurrentStatus(incident)).toEqual(IncidentStatus.Resolved);
+    });
+
+    it('should use the newest status post otherwise', () => {
+        const incident: any = {
+            end_at: 0,
+            status_posts: [
+                {id: 'post1', status: IncidentStatus.Active, create_at: 1, delete_at: 0},
+                {id: 'post2', status: IncidentStatus.Resolved, create_at: 2, delete_at: 0},
+            ],
+        };
+
+        expect(incidentCurrentStatus(incident)).toEqual(IncidentStatus.Resolved);
+    });
+});
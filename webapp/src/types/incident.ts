@@ -2,13 +2,14 @@
 // See LICENSE.txt for license information.
 
 import {TimelineEvent, TimelineEventType} from 'src/types/rhs';
-import {Checklist, isChecklist, Propertylist, PropertylistItem} from './playbook';
+import {Checklist, ExitCriteria, isChecklist, Propertylist, PropertylistItem} from './playbook';
 
 export interface Incident {
     id: string;
     name: string;
     description: string;
     commander_user_id: string;
+    commander_handoff?: CommanderHandoff;
     team_id: string;
     channel_id: string;
     create_at: number;
@@ -18,13 +19,60 @@ export interface Incident {
     active_stage_title: string;
     post_id: string;
     playbook_id: string;
+    playbook_version?: number;
     checklists: Checklist[];
     status_posts: StatusPost[];
     reminder_post_id: string;
     broadcast_channel_id: string;
     timeline_events: TimelineEvent[];
     propertylist: Propertylist;
+    exit_criteria?: ExitCriteria;
     links?: Linklist[];
+    team?: TeamInfo;
+    version?: number;
+
+    // summary is only set when listing incidents with fields: 'summary', in place of the
+    // checklists, properties, status posts and timeline events.
+    summary?: IncidentSummary;
+}
+
+export interface IncidentSummary {
+    status: IncidentStatus;
+    tasks_total: number;
+    tasks_done: number;
+}
+
+// IncidentChangeEvent describes the changes made to an incident between two of its versions.
+export interface IncidentChangeEvent {
+    incident_id: string;
+    previous_version: number;
+    version: number;
+    changes: IncidentChange[];
+    timeline_events: TimelineEvent[];
+}
+
+// IncidentChange replaces the value at path, such as 'checklists[0].items[2]' or
+// 'commander_user_id', as a whole.
+export interface IncidentChange {
+    path: string;
+
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    value: any;
+}
+
+export interface CommanderHandoff {
+    from_user_id: string;
+    to_user_id: string;
+    notes: string;
+    requested_at: number;
+    expires_at: number;
+    post_id: string;
+}
+
+export interface TeamInfo {
+    id: string;
+    name: string;
+    display_name: string;
 }
 
 export interface Linklist {
@@ -51,9 +99,25 @@ export interface FetchIncidentsReturn {
     total_count: number;
     page_count: number;
     has_more: boolean;
+    next_cursor?: string;
     items: Incident[];
 }
 
+export interface SearchSnippet {
+    field: 'name' | 'description' | 'status_updates' | 'timeline_summaries' | 'checklist_items' | 'properties';
+    text: string;
+    html: string;
+}
+
+export interface SearchIncidentsReturn {
+    has_more: boolean;
+    items: {
+        incident: Incident;
+        score: number;
+        snippets: SearchSnippet[];
+    }[];
+}
+
 export enum IncidentStatus {
     Reported = 'Reported',
     Active = 'Active',
@@ -127,6 +191,10 @@ export function incidentCurrentStatusPost(incident: Incident): StatusPost | unde
 }
 
 export function incidentCurrentStatus(incident: Incident): IncidentStatus {
+    if (incident.summary && incident.status_posts.length === 0) {
+        return incident.summary.status;
+    }
+
     let status = IncidentStatus.Reported;
 
     const currentPost = incidentCurrentStatusPost(incident);
@@ -154,22 +222,63 @@ export function incidentIsActive(incident: Incident): boolean {
     return currentStatus !== IncidentStatus.Archived && currentStatus !== IncidentStatus.Resolved;
 }
 
+// applyIncidentChanges returns a copy of the incident with the changes of the event applied. The
+// event must have been made on top of the version of the incident.
+export function applyIncidentChanges(incident: Incident, event: IncidentChangeEvent): Incident {
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    let changed: any = incident;
+    for (const change of event.changes) {
+        changed = setPath(changed, change.path.match(/[^.[\]]+/g) || [], change.value);
+    }
+
+    return {
+        ...changed,
+        timeline_events: [...incident.timeline_events, ...event.timeline_events],
+        version: event.version,
+    };
+}
+
+// setPath returns a copy of obj with value at the given path. Only the objects and arrays along
+// the path are copied.
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+function setPath(obj: any, keys: string[], value: any): any {
+    if (keys.length === 0) {
+        return value;
+    }
+
+    const [key, ...rest] = keys;
+    const copy = Array.isArray(obj) ? [...obj] : {...obj};
+    copy[key] = setPath(obj?.[key], rest, value);
+
+    return copy;
+}
+
 export interface FetchIncidentsParams {
-    team_id?: string;
+    team_id?: string | string[];
     page?: number;
     per_page?: number;
+    cursor?: string;
     sort?: string;
     direction?: string;
-    status?: string;
+    sort_property?: string;
+    status?: string | string[];
+    playbook_id?: string;
+    created_after?: number;
+    created_before?: number;
+    ended_after?: number;
+    ended_before?: number;
+    property?: Record<string, string[]>;
     commander_user_id?: string;
     search_term?: string;
     member_id?: string;
+    fields?: 'summary';
 }
 
 export interface FetchPlaybooksParams {
     team_id?: string;
     page?: number;
     per_page?: number;
+    cursor?: string;
     sort?: string;
     direction?: string;
     member_only?: boolean;
@@ -11,10 +11,43 @@ export interface Playbook {
     create_public_incident: boolean;
     checklists: Checklist[];
     propertylist: Propertylist;
+    exit_criteria?: ExitCriteria;
     member_ids: string[];
+    members?: PlaybookMember[];
+    groups?: PlaybookGroup[];
     broadcast_channel_id: string;
     reminder_message_template: string;
     reminder_timer_default_seconds: number;
+    is_global?: boolean;
+    shared_team_ids?: string[];
+    version?: number;
+    forked_from_id?: string;
+    forked_from_version?: number;
+    oncall_schedule_id?: string;
+    external_id?: string;
+}
+
+export type PlaybookRole = 'owner' | 'editor' | 'runner' | 'viewer';
+
+export interface PlaybookMember {
+    user_id: string;
+    role: PlaybookRole;
+}
+
+export interface PlaybookGroup {
+    group_id: string;
+    role: PlaybookRole;
+}
+
+export interface ExitCriteria {
+    required_properties: string[];
+    required_checklist_items: ChecklistItemReference[];
+    min_status_updates: number;
+}
+
+export interface ChecklistItemReference {
+    checklist: string;
+    item: string;
 }
 
 export interface PlaybookNoChecklist {
@@ -32,6 +65,7 @@ export interface FetchPlaybooksNoChecklistReturn {
     total_count: number;
     page_count: number;
     has_more: boolean;
+    next_cursor?: string;
     items: PlaybookNoChecklist[];
 }
 
@@ -54,6 +88,7 @@ export enum ChecklistItemState {
     Open = '',
     InProgress = 'in_progress',
     Closed = 'closed',
+    Skipped = 'skipped',
 }
 
 export interface PropertylistItem {
@@ -62,10 +97,16 @@ export interface PropertylistItem {
     type: PropertyType
     selection?: Selectionlist
     freetext?: TextOption
+    number?: NumberOption
+    datetime?: DatetimeOption
+    user?: UserOption
+    channel?: ChannelOption
+    url?: URLOption
 }
 
 export interface TextOption {
     value: string
+    values?: string[]
     is_multiselect: boolean
     badge_style?: BadgeStyle
 }
@@ -73,7 +114,31 @@ export interface TextOption {
 export interface Selectionlist {
     items: SelectionlistItem[]
     is_multiselect: boolean;
-    selected_id: string;
+    selected_ids: string[];
+}
+
+export interface NumberOption {
+    value: number | null;
+    unit: string;
+    min: number | null;
+    max: number | null;
+}
+
+export interface DatetimeOption {
+    value: number;
+}
+
+export interface UserOption {
+    user_ids: string[];
+    is_multiselect: boolean;
+}
+
+export interface ChannelOption {
+    channel_id: string;
+}
+
+export interface URLOption {
+    value: string;
 }
 
 export interface SelectionlistItem {
@@ -90,6 +155,11 @@ export interface BadgeStyle {
 export enum PropertyType {
     Freetext = 'Freetext',
     Selection = 'Selection',
+    Number = 'Number',
+    Datetime = 'Datetime',
+    User = 'User',
+    Channel = 'Channel',
+    URL = 'URL',
 }
 
 export interface ChecklistItem {
@@ -149,7 +219,7 @@ export function emptyPropertylistItem(): PropertylistItem {
 export function emptySelectionlist(): Selectionlist {
     return {
         items:  [emptySelectionlistItem()],
-        selected_id: '',
+        selected_ids: [],
         is_multiselect: false,
     };
 }
@@ -209,9 +279,9 @@ export const newPropertylistItem = (id = '', title = '', optional = false, type
     freetext,
 });
 
-export const newSelectionlist = (items = [newSelectionlistItem()], selected_id = '', is_multiselect = false): Selectionlist => ({
+export const newSelectionlist = (items = [newSelectionlistItem()], selected_ids: string[] = [], is_multiselect = false): Selectionlist => ({
     items,
-    selected_id,
+    selected_ids,
     is_multiselect
 });
 
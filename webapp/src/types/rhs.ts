@@ -20,6 +20,7 @@ export enum TimelineEventType {
     TaskStateModified = 'task_state_modified',
     RanSlashCommand = 'ran_slash_command',
     PropertyValueChanged = 'property_value_changed',
+    ExitCriteriaOverridden = 'exit_criteria_overridden',
 }
 
 export interface TimelineEvent {
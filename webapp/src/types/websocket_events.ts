@@ -5,3 +5,4 @@ import {pluginId} from 'src/manifest';
 
 export const WEBSOCKET_INCIDENT_UPDATED = `custom_${pluginId}_incident_updated`;
 export const WEBSOCKET_INCIDENT_CREATED = `custom_${pluginId}_incident_created`;
+export const WEBSOCKET_INCIDENT_CHANGED = `custom_${pluginId}_incident_changed`;
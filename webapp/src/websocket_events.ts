@@ -15,9 +15,15 @@ import {
     removedFromIncidentChannel,
     receivedTeamIncidents,
 } from 'src/actions';
-import {fetchIncidentByChannel, fetchIncidents} from 'src/client';
-import {clientId, myIncidentsMap} from 'src/selectors';
-import {Incident, isIncident, StatusPost} from 'src/types/incident';
+import {fetchIncident, fetchIncidentByChannel, fetchIncidents} from 'src/client';
+import {clientId, myIncidentByChannel, myIncidentsMap} from 'src/selectors';
+import {
+    applyIncidentChanges,
+    Incident,
+    IncidentChangeEvent,
+    isIncident,
+    StatusPost,
+} from 'src/types/incident';
 
 export const websocketSubscribersToIncidentUpdate = new Set<(incident: Incident) => void>();
 
@@ -55,6 +61,28 @@ export function handleWebsocketIncidentUpdated(getState: GetStateFunc, dispatch:
     };
 }
 
+export function handleWebsocketIncidentChanged(getState: GetStateFunc, dispatch: Dispatch) {
+    return async (msg: WebSocketMessage<{payload: string}>): Promise<void> => {
+        if (!msg.data.payload) {
+            return;
+        }
+        const event = JSON.parse(msg.data.payload) as IncidentChangeEvent;
+
+        let incident: Incident;
+        const current = myIncidentByChannel(getState(), msg.broadcast.channel_id);
+        if (current?.id === event.incident_id && current.version === event.previous_version) {
+            incident = applyIncidentChanges(current, event);
+        } else {
+            // A change was missed, or the incident is not known yet: fetch it whole.
+            incident = await fetchIncident(event.incident_id);
+        }
+
+        dispatch(incidentUpdated(incident));
+
+        websocketSubscribersToIncidentUpdate.forEach((fn) => fn(incident));
+    };
+}
+
 export function handleWebsocketIncidentCreated(getState: GetStateFunc, dispatch: Dispatch) {
     return (msg: WebSocketMessage<{payload:string}>): void => {
         if (!msg.data.payload) {